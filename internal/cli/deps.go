@@ -9,16 +9,20 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/modu-ai/moai-adk/internal/config"
 	"github.com/modu-ai/moai-adk/internal/core/git"
+	"github.com/modu-ai/moai-adk/internal/defs"
 	"github.com/modu-ai/moai-adk/internal/hook"
 	"github.com/modu-ai/moai-adk/internal/hook/security"
+	"github.com/modu-ai/moai-adk/internal/lsp"
 	lsphook "github.com/modu-ai/moai-adk/internal/lsp/hook"
 	"github.com/modu-ai/moai-adk/internal/rank"
 	"github.com/modu-ai/moai-adk/internal/update"
+	"github.com/modu-ai/moai-adk/pkg/models"
 	"github.com/modu-ai/moai-adk/pkg/version"
 )
 
@@ -39,6 +43,9 @@ type Dependencies struct {
 	RankCredStore rank.CredentialStore
 	RankBrowser   rank.BrowserOpener
 	Logger        *slog.Logger
+
+	// hooksPending is set until EnsureHooks registers the hook handlers.
+	hooksPending bool
 }
 
 // deps is the global dependencies instance, initialized by InitDependencies.
@@ -47,8 +54,9 @@ var deps *Dependencies
 
 // InitDependencies creates and wires all domain dependencies.
 // It should be called once during application startup.
-// Dependencies that require a project root (Config, Git) are
-// initialized lazily on first use or when the project root is available.
+// Git, Update, Rank and the hook handlers are initialized lazily on first
+// use through the Ensure methods, so commands such as "moai version" do not
+// load the project configuration.
 func InitDependencies() {
 	// Disable JSON logging for CLI commands by using a no-op logger
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
@@ -58,46 +66,139 @@ func InitDependencies() {
 		HookProtocol:  hook.NewProtocol(),
 		RankCredStore: rank.NewFileCredentialStore(""),
 		Logger:        logger,
+		hooksPending:  true,
 	}
 
 	// Hook registry requires a ConfigProvider; use ConfigManager
 	deps.HookRegistry = hook.NewRegistry(deps.Config)
+}
+
+// EnsureHooks registers the hook handlers on first use. It loads the
+// configuration of the project in CLAUDE_PROJECT_DIR or the working
+// directory, which the security policy shared by the PreToolUse,
+// PostToolUse and UserPromptSubmit handlers is built from. When that
+// configuration is invalid the built-in policy is used and the error is
+// returned, so that the hook commands can report it.
+// Subsequent calls are no-ops; dependencies not created by InitDependencies
+// keep their HookRegistry as is.
+func (d *Dependencies) EnsureHooks() error {
+	if !d.hooksPending {
+		return nil
+	}
+	d.hooksPending = false
+	return d.registerHookHandlers()
+}
+
+// registerHookHandlers registers the default hook handlers with
+// d.HookRegistry and returns the error that made it fall back to the
+// built-in security policy, if any.
+func (d *Dependencies) registerHookHandlers() error {
+	logger := d.Logger
 
 	// Create security scanner for AST-based scanning
 	securityScanner := security.NewSecurityScanner()
 
 	// Create LSP diagnostics collector with fallback tools
-	// LSP client is nil (not yet integrated), but fallback CLI tools will work
-	fallbackDiags := lsphook.NewFallbackDiagnostics()
-	diagnosticsCollector := lsphook.NewDiagnosticsCollector(nil, fallbackDiags)
+	// Diagnostics come from the project's LSP daemon (moai lsp serve) when
+	// it is running; otherwise the fallback CLI tools are used, including
+	// those declared in quality.yaml once the configuration is loaded
+	fallbackDiags := lsphook.NewFallbackDiagnostics(lsphook.WithConfiguredTools(func() []models.FallbackDiagnosticsTool {
+		if cfg := d.Config.Get(); cfg != nil {
+			return cfg.Quality.FallbackDiagnostics.Tools
+		}
+		return nil
+	}))
+	daemonDiags := lsp.NewDaemonDiagnosticsProvider(lspProjectRoot)
+	diagnosticsCollector := lsphook.NewDiagnosticsCollector(daemonDiags, fallbackDiags)
+
+	// Diagnostics regressions are measured against baselines refreshed on
+	// SessionStart and summarized in .moai/reports on SessionEnd
+	regressionGate := hook.NewRegressionGate(d.Config, diagnosticsCollector)
 
 	// Register default hook handlers
-	deps.HookRegistry.Register(hook.NewSessionStartHandler(deps.Config))
-	deps.HookRegistry.Register(hook.NewSessionEndHandler())
+	d.HookRegistry.Register(hook.NewSessionStartHandler(d.Config))
+	d.HookRegistry.Register(hook.NewRegressionSessionStartHandler(regressionGate))
+	d.HookRegistry.Register(hook.NewSessionEndHandler())
+	d.HookRegistry.Register(hook.NewRegressionSessionEndHandler(regressionGate))
 
 	// Register rank session handler if credentials exist
 	rankHandler, err := hook.EnsureRankSessionHandler()
 	if err != nil {
 		logger.Warn("failed to initialize rank session handler", "error", err)
 	} else if rankHandler != nil {
-		deps.HookRegistry.Register(rankHandler)
+		d.HookRegistry.Register(rankHandler)
 		logger.Info("rank session handler registered")
+
+		// Sessions left in the rank outbox are submitted on the next SessionStart
+		if outbox, err := rank.NewOutbox(""); err == nil {
+			d.HookRegistry.Register(hook.NewRankOutboxFlushHandler(d.RankCredStore, outbox))
+		}
 	}
 
 	// Register auto-update handler for SessionStart
-	deps.HookRegistry.Register(hook.NewAutoUpdateHandler(buildAutoUpdateFunc()))
-
-	deps.HookRegistry.Register(hook.NewStopHandler())
-	deps.HookRegistry.Register(hook.NewPreToolHandlerWithScanner(deps.Config, hook.DefaultSecurityPolicy(), securityScanner))
-	deps.HookRegistry.Register(hook.NewPostToolHandlerWithDiagnostics(diagnosticsCollector))
-	deps.HookRegistry.Register(hook.NewCompactHandler())
-	deps.HookRegistry.Register(hook.NewPostToolUseFailureHandler())
-	deps.HookRegistry.Register(hook.NewNotificationHandler())
-	deps.HookRegistry.Register(hook.NewSubagentStartHandler())
-	deps.HookRegistry.Register(hook.NewUserPromptSubmitHandler())
-	deps.HookRegistry.Register(hook.NewPermissionRequestHandler())
-	deps.HookRegistry.Register(hook.NewTeammateIdleHandler())
-	deps.HookRegistry.Register(hook.NewTaskCompletedHandler())
+	d.HookRegistry.Register(hook.NewAutoUpdateHandler(buildAutoUpdateFunc()))
+
+	// PreToolUse, PostToolUse (read redaction) and UserPromptSubmit (pasted
+	// secrets) share one security policy
+	securityPolicy, policyErr := loadSecurityPolicy(d.Config, logger)
+
+	// TDD cycle enforcement runs after the security checks
+	tddTracker := hook.NewTDDCycleTracker(d.Config)
+
+	// The Stop quality gate checks the files recorded on PostToolUse
+	stopGate := hook.NewStopQualityGate(d.Config)
+	d.HookRegistry.Register(hook.NewStopHandlerWithQualityGate(tddTracker, stopGate))
+	d.HookRegistry.Register(hook.NewPreToolHandlerWithScanner(d.Config, securityPolicy, securityScanner))
+	d.HookRegistry.Register(hook.NewTDDPreToolHandler(tddTracker))
+	d.HookRegistry.Register(hook.NewRegressionPreToolHandler(regressionGate))
+	// Auto-format runs first so diagnostics see the formatted file; the
+	// diagnostics handler runs last because a regression block ends the chain
+	d.HookRegistry.Register(hook.NewAutoFormatHandler(d.Config, nil))
+	d.HookRegistry.Register(hook.NewTDDPostToolHandler(tddTracker))
+	d.HookRegistry.Register(hook.NewStopGatePostToolHandler(stopGate))
+	d.HookRegistry.Register(hook.NewPostToolHandlerWithRegressionGate(diagnosticsCollector, securityPolicy, regressionGate))
+	d.HookRegistry.Register(hook.NewCompactHandler())
+	d.HookRegistry.Register(hook.NewPostToolUseFailureHandler())
+	d.HookRegistry.Register(hook.NewTDDPostToolFailureHandler(tddTracker))
+	d.HookRegistry.Register(hook.NewNotificationHandler())
+	d.HookRegistry.Register(hook.NewSubagentStartHandler())
+	d.HookRegistry.Register(hook.NewUserPromptSubmitHandlerWithContext(d.Config, securityPolicy))
+	d.HookRegistry.Register(hook.NewPermissionRequestHandler())
+	d.HookRegistry.Register(hook.NewTeammateIdleHandler())
+	d.HookRegistry.Register(hook.NewTaskCompletedHandler())
+
+	return policyErr
+}
+
+// loadSecurityPolicy builds the PreToolUse security policy from the project's
+// .moai/config/sections/security.yaml. It falls back to the built-in policy
+// when no MoAI project is found or the configuration is invalid, so a broken
+// security.yaml never disables the default protections; the error explaining
+// why is returned along with it.
+func loadSecurityPolicy(mgr *config.ConfigManager, logger *slog.Logger) (*hook.SecurityPolicy, error) {
+	projectRoot := os.Getenv("CLAUDE_PROJECT_DIR")
+	if projectRoot == "" {
+		projectRoot, _ = os.Getwd()
+	}
+	if projectRoot == "" {
+		return hook.DefaultSecurityPolicy(), nil
+	}
+	if _, err := os.Stat(filepath.Join(projectRoot, defs.MoAIDir)); err != nil {
+		return hook.DefaultSecurityPolicy(), nil
+	}
+
+	cfg, err := mgr.Load(projectRoot)
+	if err != nil {
+		logger.Warn("failed to load config for security policy, using defaults", "error", err)
+		return hook.DefaultSecurityPolicy(), fmt.Errorf("load configuration: %w", err)
+	}
+
+	policy, err := hook.NewSecurityPolicy(cfg.Security)
+	if err != nil {
+		logger.Warn("invalid security policy configuration, using defaults", "error", err)
+		return hook.DefaultSecurityPolicy(), fmt.Errorf("invalid security.yaml: %w", err)
+	}
+	return policy, nil
 }
 
 // GetDeps returns the current Dependencies instance.
@@ -1,9 +1,16 @@
 package cli
 
 import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/modu-ai/moai-adk/internal/config"
 	"github.com/modu-ai/moai-adk/internal/core/git"
+	"github.com/modu-ai/moai-adk/internal/hook"
 )
 
 func TestInitDependencies(t *testing.T) {
@@ -164,3 +171,106 @@ func (m *mockGitRepository) Log(_ int) ([]git.Commit, error)  { return nil, nil
 func (m *mockGitRepository) Diff(_, _ string) (string, error) { return "", nil }
 func (m *mockGitRepository) IsClean() (bool, error)           { return true, nil }
 func (m *mockGitRepository) Root() string                     { return "/mock/root" }
+
+func TestLoadSecurityPolicy_FromProjectConfig(t *testing.T) {
+	projectRoot := t.TempDir()
+	sectionsDir := filepath.Join(projectRoot, ".moai", "config", "sections")
+	if err := os.MkdirAll(sectionsDir, 0o755); err != nil {
+		t.Fatalf("create sections dir: %v", err)
+	}
+	securityYAML := "security:\n  rules:\n    - id: bash.docker-volume-prune\n      disabled: true\n"
+	if err := os.WriteFile(filepath.Join(sectionsDir, "security.yaml"), []byte(securityYAML), 0o644); err != nil {
+		t.Fatalf("write security.yaml: %v", err)
+	}
+	t.Setenv("CLAUDE_PROJECT_DIR", projectRoot)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	policy, err := loadSecurityPolicy(config.NewConfigManager(), logger)
+	if err != nil {
+		t.Fatalf("loadSecurityPolicy() error: %v", err)
+	}
+
+	for _, r := range policy.BashRules {
+		if r.ID == "bash.docker-volume-prune" {
+			t.Error("rule disabled in security.yaml should not be in the policy")
+		}
+	}
+	if len(policy.BashRules) == 0 {
+		t.Error("remaining built-in Bash rules should still apply")
+	}
+}
+
+func TestLoadSecurityPolicy_InvalidConfigFallsBackToDefaults(t *testing.T) {
+	projectRoot := t.TempDir()
+	sectionsDir := filepath.Join(projectRoot, ".moai", "config", "sections")
+	if err := os.MkdirAll(sectionsDir, 0o755); err != nil {
+		t.Fatalf("create sections dir: %v", err)
+	}
+	securityYAML := "security:\n  rules:\n    - id: custom.bad\n      kind: bash\n      severity: explode\n      pattern: x\n"
+	if err := os.WriteFile(filepath.Join(sectionsDir, "security.yaml"), []byte(securityYAML), 0o644); err != nil {
+		t.Fatalf("write security.yaml: %v", err)
+	}
+	t.Setenv("CLAUDE_PROJECT_DIR", projectRoot)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	policy, err := loadSecurityPolicy(config.NewConfigManager(), logger)
+	if err == nil {
+		t.Error("loadSecurityPolicy() should report the invalid configuration")
+	}
+
+	if got, want := len(policy.BashRules), len(hook.DefaultSecurityPolicy().BashRules); got != want {
+		t.Errorf("BashRules count = %d, want default %d", got, want)
+	}
+}
+
+func TestEnsureHooks_RegistersHandlersOnFirstUse(t *testing.T) {
+	origDeps := deps
+	defer func() { deps = origDeps }()
+	t.Setenv("CLAUDE_PROJECT_DIR", t.TempDir())
+
+	InitDependencies()
+	if n := len(deps.HookRegistry.Handlers(hook.EventPreToolUse)); n != 0 {
+		t.Fatalf("InitDependencies registered %d PreToolUse handlers, want none before EnsureHooks", n)
+	}
+
+	if err := deps.EnsureHooks(); err != nil {
+		t.Fatalf("EnsureHooks() error: %v", err)
+	}
+	n := len(deps.HookRegistry.Handlers(hook.EventPreToolUse))
+	if n == 0 {
+		t.Fatal("EnsureHooks should register the PreToolUse handlers")
+	}
+	if err := deps.EnsureHooks(); err != nil || len(deps.HookRegistry.Handlers(hook.EventPreToolUse)) != n {
+		t.Errorf("second EnsureHooks() should be a no-op, got error %v", err)
+	}
+}
+
+func TestEnsureHooks_ReportsInvalidSecurityPolicy(t *testing.T) {
+	origDeps := deps
+	defer func() { deps = origDeps }()
+
+	projectRoot := t.TempDir()
+	sectionsDir := filepath.Join(projectRoot, ".moai", "config", "sections")
+	if err := os.MkdirAll(sectionsDir, 0o755); err != nil {
+		t.Fatalf("create sections dir: %v", err)
+	}
+	securityYAML := "security:\n  rules:\n    - id: custom.bad\n      kind: bash\n      pattern: '('\n"
+	if err := os.WriteFile(filepath.Join(sectionsDir, "security.yaml"), []byte(securityYAML), 0o644); err != nil {
+		t.Fatalf("write security.yaml: %v", err)
+	}
+	t.Setenv("CLAUDE_PROJECT_DIR", projectRoot)
+
+	InitDependencies()
+	err := deps.EnsureHooks()
+	if err == nil {
+		t.Fatal("EnsureHooks() should report the invalid security.yaml")
+	}
+	if len(deps.HookRegistry.Handlers(hook.EventPreToolUse)) == 0 {
+		t.Error("handlers should still be registered with the built-in policy")
+	}
+
+	out := withPolicyWarning(hook.NewAllowOutput(), err)
+	if !strings.Contains(out.SystemMessage, "security policy") || !strings.Contains(out.SystemMessage, "security.rules[0]") {
+		t.Errorf("SystemMessage = %q, want the security.yaml error", out.SystemMessage)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/modu-ai/moai-adk/internal/config"
 	"github.com/modu-ai/moai-adk/internal/github"
 	"github.com/modu-ai/moai-adk/internal/workflow"
 )
@@ -64,30 +65,35 @@ func ghInfoCard(title, content string) string {
 var githubCmd = &cobra.Command{
 	Use:   "github",
 	Short: "GitHub integration commands",
-	Long:  "Commands for GitHub issue parsing, SPEC linking, and workflow automation.",
+	Long: `Commands for GitHub issue parsing, SPEC linking, and workflow automation.
+
+Projects with git_strategy.provider set to gitlab use merge requests,
+pipelines and issues of git_strategy.gitlab.instance_url through the
+GitLab REST API, authenticated with the GITLAB_TOKEN environment variable.`,
 }
 
 func init() {
 	rootCmd.AddCommand(githubCmd)
-	githubCmd.AddCommand(newParseIssueCmd())
+	githubCmd.AddCommand(newParseIssueCmd(newGithubRunner()))
 	githubCmd.AddCommand(newLinkSpecCmd())
 }
 
-func newParseIssueCmd() *cobra.Command {
+func newParseIssueCmd(r *githubRunner) *cobra.Command {
 	return &cobra.Command{
 		Use:   "parse-issue <number>",
 		Short: "Parse a GitHub issue",
 		Long: `Parse a GitHub issue and display its contents.
-Uses the gh CLI to fetch issue data from the current repository.
+Uses the gh CLI, or the GitLab API for GitLab projects, to fetch issue data
+from the current repository.
 
 Example:
   moai github parse-issue 123`,
 		Args: cobra.ExactArgs(1),
-		RunE: runParseIssue,
+		RunE: r.runParseIssue,
 	}
 }
 
-func runParseIssue(cmd *cobra.Command, args []string) error {
+func (r *githubRunner) runParseIssue(cmd *cobra.Command, args []string) error {
 	out := cmd.OutOrStdout()
 
 	number, err := strconv.Atoi(args[0])
@@ -95,13 +101,26 @@ func runParseIssue(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid issue number %q: %w", args[0], err)
 	}
 
-	parser := GithubIssueParser
+	parser, title := GithubIssueParser, "GitHub Issue"
 	if parser == nil {
 		cwd, cwdErr := os.Getwd()
 		if cwdErr != nil {
 			return fmt.Errorf("get working directory: %w", cwdErr)
 		}
-		parser = github.NewIssueParser(cwd)
+		gitStrategy := config.NewDefaultGitStrategyConfig()
+		if root, rootErr := findProjectRoot(); rootErr == nil {
+			if cfg, cfgErr := config.NewConfigManager().Load(root); cfgErr == nil {
+				gitStrategy = cfg.GitStrategy
+			}
+		}
+		provider, providerErr := github.NewProvider(cwd, gitStrategy, github.WithProviderExecFunc(r.exec))
+		if providerErr != nil {
+			return providerErr
+		}
+		parser = provider.Issues
+		if provider.Name == github.ProviderGitLab {
+			title = "GitLab Issue"
+		}
 	}
 
 	ctx := cmd.Context()
@@ -144,7 +163,7 @@ func runParseIssue(cmd *cobra.Command, args []string) error {
 	}
 
 	_, _ = fmt.Fprintln(out, ghInfoCard(
-		fmt.Sprintf("GitHub Issue #%d", issue.Number),
+		fmt.Sprintf("%s #%d", title, issue.Number),
 		strings.Join(details, "\n"),
 	))
 	return nil
@@ -0,0 +1,558 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/core/quality"
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/github"
+	"github.com/modu-ai/moai-adk/internal/hook"
+	"github.com/modu-ai/moai-adk/internal/i18n"
+	"github.com/modu-ai/moai-adk/internal/workflow"
+)
+
+// githubRunner runs the github subcommands that talk to the Git hosting
+// provider.
+type githubRunner struct {
+	// exec runs gh for GitHub projects. Nil uses the gh CLI.
+	exec github.ExecFunc
+
+	// checksInterval is how often "merge --wait-checks" polls the CI
+	// checks of a pull request.
+	checksInterval time.Duration
+
+	// noChecksGrace is how long "merge --wait-checks" waits for checks to
+	// be reported before treating a pull request as having none configured.
+	noChecksGrace time.Duration
+}
+
+// newGithubRunner returns a githubRunner using the gh CLI.
+func newGithubRunner() *githubRunner {
+	return &githubRunner{checksInterval: 15 * time.Second, noChecksGrace: time.Minute}
+}
+
+func init() {
+	r := newGithubRunner()
+	githubCmd.AddCommand(newReviewCmd(r))
+	githubCmd.AddCommand(newMergeCmd(r))
+	githubCmd.AddCommand(newCloseIssueCmd(r))
+}
+
+func newReviewCmd(r *githubRunner) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "review <pr-number>",
+		Short: "Review a pull request against the TRUST 5 quality gates",
+		Long: `Review a pull request: run the TRUST 5 quality gates on its head commit
+and combine them with its CI checks into an APPROVE, COMMENT or
+REQUEST_CHANGES decision. Exits non-zero when changes are requested.
+
+The gates run in the worktree of its SPEC (.moai/worktrees/<SPEC-ID>) or
+the project when it is checked out at the head commit, otherwise in a
+temporary worktree of the head fetched from origin.
+
+The SPEC is taken from --spec or from a feature/<SPEC-ID> head branch.
+
+Example:
+  moai github review 42 --spec SPEC-ISSUE-123`,
+		Args: cobra.ExactArgs(1),
+		RunE: r.runReview,
+	}
+	cmd.Flags().String("spec", "", "SPEC ID of the pull request (default: from the head branch)")
+	return cmd
+}
+
+func newMergeCmd(r *githubRunner) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge <pr-number>",
+		Short: "Merge a reviewed pull request and close its SPEC issue",
+		Long: `Merge a pull request once it is mergeable, its CI checks pass and the
+TRUST 5 review approves it, then close the issue linked to its SPEC
+(see 'moai github link-spec', or SPEC-ISSUE-<number>) with a summary
+comment in the conversation language.
+
+Examples:
+  moai github merge 42 --wait-checks
+  moai github merge 42 --method rebase --delete-branch`,
+		Args: cobra.ExactArgs(1),
+		RunE: r.runMerge,
+	}
+	cmd.Flags().String("method", string(github.MergeMethodSquash), "Merge method: merge, squash or rebase")
+	cmd.Flags().Bool("wait-checks", false, "Wait for pending CI checks before merging")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time to wait for CI checks")
+	cmd.Flags().Bool("delete-branch", false, "Delete the head branch after merging")
+	cmd.Flags().Bool("skip-checks", false, "Merge without requiring passing CI checks")
+	cmd.Flags().Bool("skip-review", false, "Merge without the TRUST 5 review")
+	cmd.Flags().String("spec", "", "SPEC ID of the pull request (default: from the head branch)")
+	cmd.Flags().String("lang", "", "Language of the closing comment (default: language.conversation_language)")
+	return cmd
+}
+
+func newCloseIssueCmd(r *githubRunner) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "close-issue <issue-number>",
+		Short: "Close a resolved issue with a summary comment",
+		Long: `Post a summary comment in the conversation language, add the
+"resolved" label and close an issue resolved by a pull request.
+
+Example:
+  moai github close-issue 123 --pr 42 --summary "Added OAuth login"`,
+		Args: cobra.ExactArgs(1),
+		RunE: r.runCloseIssue,
+	}
+	cmd.Flags().Int("pr", 0, "Pull request that resolved the issue")
+	cmd.Flags().String("summary", "", "Implementation summary (default: the pull request title)")
+	cmd.Flags().String("lang", "", "Language of the comment (default: language.conversation_language)")
+	_ = cmd.MarkFlagRequired("pr")
+	return cmd
+}
+
+// parseGithubNumber parses a positive issue or pull request number.
+func parseGithubNumber(kind, arg string) (int, error) {
+	number, err := strconv.Atoi(arg)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid %s number %q", kind, arg)
+	}
+	return number, nil
+}
+
+// project loads the project root, its configuration and the Git hosting
+// provider selected by git_strategy.provider.
+func (r *githubRunner) project() (string, *config.ConfigManager, *config.Config, *github.Provider, error) {
+	root, err := findProjectRoot()
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	mgr := config.NewConfigManager()
+	cfg, err := mgr.Load(root)
+	if err != nil {
+		return "", nil, nil, nil, fmt.Errorf("load config: %w", err)
+	}
+	provider, err := github.NewProvider(root, cfg.GitStrategy, github.WithProviderExecFunc(r.exec))
+	if err != nil {
+		return "", nil, nil, nil, err
+	}
+	return root, mgr, cfg, provider, nil
+}
+
+// githubLogger returns the CLI logger, discarding output when none is set.
+func githubLogger() *slog.Logger {
+	if deps != nil && deps.Logger != nil {
+		return deps.Logger
+	}
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// prSpecID returns the SPEC of a pull request: flag when set, otherwise
+// the SPEC ID of a feature/<SPEC-ID> head branch.
+func prSpecID(flag string, details *github.PRDetails) string {
+	if flag != "" {
+		return flag
+	}
+	if specID, ok := strings.CutPrefix(details.HeadBranch, "feature/"); ok && strings.HasPrefix(specID, "SPEC-") {
+		return specID
+	}
+	return ""
+}
+
+// reviewDir returns the preferred directory to review for specID: its
+// worktree when it exists, the project root otherwise.
+func reviewDir(root, specID string) string {
+	if specID != "" && specID == filepath.Base(specID) {
+		wtPath := filepath.Join(root, defs.MoAIDir, "worktrees", specID)
+		if info, err := os.Stat(wtPath); err == nil && info.IsDir() {
+			return wtPath
+		}
+	}
+	return root
+}
+
+// checkoutReviewDir returns a checkout of the head commit of the pull
+// request to run the TRUST 5 gates on. The SPEC worktree or project root
+// is used when its HEAD is the pull request head, a temporary worktree
+// otherwise, so the gates never validate a different commit.
+func checkoutReviewDir(ctx context.Context, provider *github.Provider, root, specID string, details *github.PRDetails) (*github.PRHeadCheckout, error) {
+	co, err := provider.CheckoutPRHead(ctx, root, details, reviewDir(root, specID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot review %s: %w", provider.RequestRef(details.Number), err)
+	}
+	return co, nil
+}
+
+// newPRReviewer returns a reviewer validating dir with the TRUST 5 gates.
+func newPRReviewer(gh github.GHClient, mgr hook.ConfigProvider, cfg *config.Config, dir string) (github.PRReviewer, error) {
+	gate := &dirQualityGate{cfg: mgr, qc: quality.ConfigFromModel(cfg.Quality), dir: dir}
+	return github.NewPRReviewer(gh, gate, githubLogger())
+}
+
+func (r *githubRunner) runReview(cmd *cobra.Command, args []string) error {
+	number, err := parseGithubNumber("pull request", args[0])
+	if err != nil {
+		return err
+	}
+	specFlag, _ := cmd.Flags().GetString("spec")
+	cmd.SilenceUsage = true
+
+	root, mgr, cfg, provider, err := r.project()
+	if err != nil {
+		return err
+	}
+	gh := provider.Client
+	ctx := cmd.Context()
+	details, err := gh.PRView(ctx, number)
+	if err != nil {
+		return err
+	}
+	specID := prSpecID(specFlag, details)
+	co, err := checkoutReviewDir(ctx, provider, root, specID, details)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = co.Close() }()
+	reviewer, err := newPRReviewer(gh, mgr, cfg, co.Dir)
+	if err != nil {
+		return err
+	}
+	report, err := reviewer.Review(ctx, number, specID, &github.ReviewInput{PRDetails: details})
+	if err != nil {
+		return err
+	}
+
+	writeReviewReport(cmd.OutOrStdout(), provider.RequestRef(number), root, co, details, specID, report)
+	if report.Decision == github.ReviewRequestChanges {
+		return fmt.Errorf("PR #%d: changes requested (%d issue(s))", number, len(report.Issues))
+	}
+	return nil
+}
+
+// writeReviewReport renders the decision, quality score, CI status and
+// issues of a review.
+func writeReviewReport(w io.Writer, ref, root string, co *github.PRHeadCheckout, details *github.PRDetails, specID string, report *github.ReviewReport) {
+	pairs := []kvPair{
+		{"PR", fmt.Sprintf("#%d %s", details.Number, details.Title)},
+		{"Decision", string(report.Decision)},
+	}
+	if specID != "" {
+		pairs = append(pairs, kvPair{"SPEC", specID})
+	}
+	reviewed := hook.DisplayPath(root, co.Dir)
+	switch {
+	case co.Temporary:
+		reviewed = "temporary worktree"
+	case co.Dir == root:
+		reviewed = "project root"
+	}
+	if sha := details.HeadSHA; len(sha) > 12 {
+		reviewed += " @ " + sha[:12]
+	} else if sha != "" {
+		reviewed += " @ " + sha
+	}
+	pairs = append(pairs, kvPair{"Reviewed", reviewed})
+	if report.QualityReport != nil {
+		verdict := "PASSED"
+		if !report.QualityReport.Passed {
+			verdict = "FAILED"
+		}
+		pairs = append(pairs, kvPair{"Quality", fmt.Sprintf("%s (score %.2f)", verdict, report.QualityReport.Score)})
+	}
+	switch {
+	case report.CheckStatus == nil:
+	case len(report.CheckStatus.Checks) == 0:
+		pairs = append(pairs, kvPair{"CI checks", "none reported"})
+	default:
+		pairs = append(pairs, kvPair{"CI checks", fmt.Sprintf("%s (%d)", report.CheckStatus.Overall, len(report.CheckStatus.Checks))})
+	}
+
+	content := renderKeyValueLines(pairs)
+	if len(report.Issues) > 0 {
+		lines := make([]string, 0, len(report.Issues)+2)
+		lines = append(lines, "", "Issues:")
+		for _, issue := range report.Issues {
+			lines = append(lines, "  - "+issue)
+		}
+		content += "\n" + strings.Join(lines, "\n")
+	}
+	_, _ = fmt.Fprintln(w, renderCard("Review of "+ref, content))
+}
+
+func (r *githubRunner) runMerge(cmd *cobra.Command, args []string) error {
+	number, err := parseGithubNumber("pull request", args[0])
+	if err != nil {
+		return err
+	}
+	methodFlag, _ := cmd.Flags().GetString("method")
+	waitChecks, _ := cmd.Flags().GetBool("wait-checks")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	deleteBranch, _ := cmd.Flags().GetBool("delete-branch")
+	skipChecks, _ := cmd.Flags().GetBool("skip-checks")
+	skipReview, _ := cmd.Flags().GetBool("skip-review")
+	specFlag, _ := cmd.Flags().GetString("spec")
+	lang, _ := cmd.Flags().GetString("lang")
+
+	method := github.MergeMethod(methodFlag)
+	switch method {
+	case github.MergeMethodMerge, github.MergeMethodSquash, github.MergeMethodRebase:
+	default:
+		return fmt.Errorf("invalid merge method %q: use merge, squash or rebase", methodFlag)
+	}
+	if timeout < 0 {
+		return fmt.Errorf("invalid timeout %s: must not be negative", timeout)
+	}
+	cmd.SilenceUsage = true
+
+	root, mgr, cfg, provider, err := r.project()
+	if err != nil {
+		return err
+	}
+	gh := provider.Client
+	ctx := cmd.Context()
+	out := cmd.OutOrStdout()
+	details, err := gh.PRView(ctx, number)
+	if err != nil {
+		return err
+	}
+	specID := prSpecID(specFlag, details)
+
+	requireChecks := !skipChecks
+	if waitChecks && !skipChecks {
+		reported, err := r.waitForChecks(ctx, provider, number, timeout, out)
+		if err != nil {
+			return err
+		}
+		requireChecks = reported
+	}
+
+	var gateDir string
+	if !skipReview {
+		co, err := checkoutReviewDir(ctx, provider, root, specID, details)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = co.Close() }()
+		gateDir = co.Dir
+	}
+	reviewer, err := newPRReviewer(gh, mgr, cfg, gateDir)
+	if err != nil {
+		return err
+	}
+	merger, err := github.NewPRMerger(gh, reviewer, githubLogger())
+	if err != nil {
+		return err
+	}
+	result, err := merger.Merge(ctx, number, github.MergeOptions{
+		AutoMerge:     true,
+		Method:        method,
+		DeleteBranch:  deleteBranch,
+		RequireReview: !skipReview,
+		RequireChecks: requireChecks,
+		SpecID:        specID,
+	})
+	if errors.Is(err, github.ErrMergeBlocked) && !waitChecks && !skipChecks {
+		return fmt.Errorf("%w (use --wait-checks to wait for pending CI checks)", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	lines := []string{fmt.Sprintf("Method:  %s", result.Method)}
+	if result.BranchDeleted {
+		lines = append(lines, fmt.Sprintf("Branch:  %s deleted", details.HeadBranch))
+	}
+	_, _ = fmt.Fprintln(out, ghSuccessCard(fmt.Sprintf("Merged %s %s", provider.RequestRef(number), details.Title), lines...))
+
+	issue := specIssue(root, specID)
+	if issue == 0 {
+		_, _ = fmt.Fprintln(out, ghMuted.Render("No issue linked to the pull request's SPEC; nothing to close."))
+		return nil
+	}
+	if lang == "" {
+		lang = cfg.Language.ConversationLanguage
+	}
+	closed, err := closeResolvedIssue(ctx, provider, issue, number, details.Title, lang, result.MergedAt)
+	if err != nil {
+		return fmt.Errorf("PR #%d merged, but %w", number, err)
+	}
+	writeCloseResult(out, closed)
+	return nil
+}
+
+// waitForChecks polls the CI checks of a pull request until none is
+// pending, failing when one of them fails or timeout expires. Checks of a
+// fresh push take a while to be registered, so a pull request without any
+// is only merged once none have been reported for noChecksGrace; it then
+// returns false.
+func (r *githubRunner) waitForChecks(ctx context.Context, provider *github.Provider, number int, timeout time.Duration, out io.Writer) (bool, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	announced := false
+	start := time.Now()
+	for {
+		status, err := provider.Client.PRChecks(ctx, number)
+		if err != nil {
+			return false, err
+		}
+		switch status.Overall {
+		case github.CheckPass:
+			return true, nil
+		case github.CheckFail:
+			var failed []string
+			for _, check := range status.Checks {
+				if check.Conclusion == "failure" || check.Conclusion == "cancelled" || check.Conclusion == "timed_out" {
+					failed = append(failed, check.Name)
+				}
+			}
+			return false, fmt.Errorf("PR #%d: %w: %s", number, github.ErrCIFailed, strings.Join(failed, ", "))
+		}
+		if len(status.Checks) == 0 && time.Since(start) >= r.noChecksGrace {
+			_, _ = fmt.Fprintf(out, "No CI checks reported for %s after %s; not waiting.\n", provider.RequestRef(number), r.noChecksGrace)
+			return false, nil
+		}
+		if !announced {
+			_, _ = fmt.Fprintf(out, "Waiting for CI checks of %s...\n", provider.RequestRef(number))
+			announced = true
+		}
+		select {
+		case <-ctx.Done():
+			return false, fmt.Errorf("PR #%d: CI checks still pending: %w", number, ctx.Err())
+		case <-time.After(r.checksInterval):
+		}
+	}
+}
+
+// specIssue returns the issue linked to specID in the SPEC registry, or
+// the number of a SPEC-ISSUE-<number> ID, or zero.
+func specIssue(root, specID string) int {
+	if specID == "" {
+		return 0
+	}
+	if linker, err := GithubSpecLinkerFactory(root); err == nil {
+		if issue, err := linker.GetLinkedIssue(specID); err == nil {
+			return issue
+		}
+	}
+	if workflow.ValidateSpecID(specID) == nil {
+		issue, _ := strconv.Atoi(strings.TrimPrefix(specID, "SPEC-ISSUE-"))
+		return issue
+	}
+	return 0
+}
+
+// closeResolvedIssue closes issue with a comment in lang summarizing the
+// pull request that resolved it.
+func closeResolvedIssue(ctx context.Context, provider *github.Provider, issue, pr int, summary, lang string, mergedAt time.Time) (*github.CloseResult, error) {
+	mergedAt = mergedAt.Local()
+	comment, err := i18n.NewCommentGenerator().Generate(lang, &i18n.CommentData{
+		Summary:     summary,
+		PRNumber:    pr,
+		IssueNumber: issue,
+		MergedAt:    mergedAt,
+		TimeZone:    mergedAt.Format("MST"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	closer := provider.IssueCloser(github.WithCloserLogger(githubLogger()))
+	return closer.Close(ctx, issue, comment)
+}
+
+// writeCloseResult renders the steps of an issue closure.
+func writeCloseResult(w io.Writer, result *github.CloseResult) {
+	label := "resolved label added"
+	if !result.LabelAdded {
+		label = "resolved label not added"
+	}
+	_, _ = fmt.Fprintln(w, ghSuccessCard(
+		fmt.Sprintf("Closed Issue #%d", result.IssueNumber),
+		"Comment: posted",
+		"Label:   "+label,
+	))
+}
+
+func (r *githubRunner) runCloseIssue(cmd *cobra.Command, args []string) error {
+	issue, err := parseGithubNumber("issue", args[0])
+	if err != nil {
+		return err
+	}
+	pr, _ := cmd.Flags().GetInt("pr")
+	summary, _ := cmd.Flags().GetString("summary")
+	lang, _ := cmd.Flags().GetString("lang")
+	if pr <= 0 {
+		return fmt.Errorf("invalid pull request number %d", pr)
+	}
+	cmd.SilenceUsage = true
+
+	_, _, cfg, provider, err := r.project()
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	mergedAt := time.Now()
+	if summary == "" {
+		details, err := provider.Client.PRView(ctx, pr)
+		if err != nil {
+			return err
+		}
+		summary = details.Title
+	}
+	if lang == "" {
+		lang = cfg.Language.ConversationLanguage
+	}
+	result, err := closeResolvedIssue(ctx, provider, issue, pr, summary, lang, mergedAt)
+	if err != nil {
+		return err
+	}
+	writeCloseResult(cmd.OutOrStdout(), result)
+	return nil
+}
+
+// dirQualityGate runs the TRUST 5 gates fed by the build, test and lint
+// commands run in dir.
+type dirQualityGate struct {
+	cfg hook.ConfigProvider
+	qc  quality.QualityConfig
+	dir string
+}
+
+// Compile-time interface compliance check.
+var _ quality.Gate = (*dirQualityGate)(nil)
+
+// Validate runs all principles.
+func (g *dirQualityGate) Validate(ctx context.Context) (*quality.Report, error) {
+	gate, err := g.gate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return gate.Validate(ctx)
+}
+
+// ValidatePrinciple runs a single principle.
+func (g *dirQualityGate) ValidatePrinciple(ctx context.Context, principle string) (*quality.PrincipleResult, error) {
+	gate, err := g.gate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return gate.ValidatePrinciple(ctx, principle)
+}
+
+// gate collects the quality input of dir and builds the gates.
+func (g *dirQualityGate) gate(ctx context.Context) (quality.Gate, error) {
+	input, err := collectQualityInput(ctx, g.dir, g.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return worktreeGateFactory(g.dir, input)(g.qc), nil
+}
@@ -0,0 +1,424 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/github"
+)
+
+// fakePRView is the gh pr view output; %s is the head commit.
+const fakePRView = `{"number":42,"title":"Add OAuth login","state":"OPEN","mergeable":"MERGEABLE",` +
+	`"headRefName":"feature/SPEC-ISSUE-7","headRefOid":"%s","baseRefName":"main","url":"https://github.com/o/r/pull/42","createdAt":"2026-01-01T00:00:00Z"}`
+
+const (
+	fakeChecksPass    = `[{"name":"test","state":"SUCCESS","bucket":"pass"}]`
+	fakeChecksPending = `[{"name":"test","state":"IN_PROGRESS","bucket":"pending"}]`
+	fakeChecksFail    = `[{"name":"test","state":"FAILURE","bucket":"fail"}]`
+
+	// fakeChecksNone makes gh report that no checks exist yet.
+	fakeChecksNone = ""
+)
+
+// fakeGH answers gh commands: pr view with fakePRView at head and pr
+// checks with the next of checks, exiting non-zero like gh for pending or
+// failing checks. It records every command.
+type fakeGH struct {
+	mu     sync.Mutex
+	head   string
+	checks []string
+	calls  []string
+}
+
+func (f *fakeGH) exec(_ context.Context, _ string, args ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, strings.Join(args, " "))
+	switch {
+	case len(args) > 1 && args[0] == "pr" && args[1] == "view":
+		return fmt.Sprintf(fakePRView, f.head), nil
+	case len(args) > 1 && args[0] == "pr" && args[1] == "checks":
+		out := f.checks[0]
+		if len(f.checks) > 1 {
+			f.checks = f.checks[1:]
+		}
+		switch out {
+		case fakeChecksNone:
+			return "", errors.New("gh pr: no checks reported on the 'feature/SPEC-ISSUE-7' branch")
+		case fakeChecksPass:
+		default:
+			return out, errors.New("gh pr: exit status 8")
+		}
+		return out, nil
+	}
+	return "", nil
+}
+
+// called returns the recorded command starting with prefix.
+func (f *fakeGH) called(prefix string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, call := range f.calls {
+		if strings.HasPrefix(call, prefix) {
+			return call, true
+		}
+	}
+	return "", false
+}
+
+// setupFakeGH creates a quality project and a fakeGH whose pull request
+// head is the project's HEAD, reporting checks in turn.
+func setupFakeGH(t *testing.T, checks ...string) *fakeGH {
+	t.Helper()
+	root := chdirQualityProject(t, "true")
+	return &fakeGH{head: gitHead(t, root), checks: checks}
+}
+
+// runner returns a githubRunner that routes gh through f and polls checks
+// without delay.
+func (f *fakeGH) runner() *githubRunner {
+	return testGithubRunner(f.exec)
+}
+
+// testGithubRunner returns a githubRunner running gh with exec (nil for the
+// gh CLI) that polls checks without delay.
+func testGithubRunner(exec github.ExecFunc) *githubRunner {
+	return &githubRunner{exec: exec, checksInterval: time.Millisecond, noChecksGrace: 20 * time.Millisecond}
+}
+
+// gitHead returns the HEAD commit of the repository at root.
+func gitHead(t *testing.T, root string) string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", root, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git rev-parse HEAD: %v", err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// executeGithub runs the github subcommand cmd with args and returns its
+// output and error.
+func executeGithub(t *testing.T, cmd *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func TestGithubCmd_PRSubcommands(t *testing.T) {
+	for _, name := range []string{"review", "merge", "close-issue"} {
+		found := false
+		for _, cmd := range githubCmd.Commands() {
+			if cmd.Name() == name {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("github command missing %q subcommand", name)
+		}
+	}
+}
+
+func TestGithubReview_Approve(t *testing.T) {
+	gh := setupFakeGH(t, fakeChecksPass)
+
+	out, err := executeGithub(t, newReviewCmd(gh.runner()), "42")
+	if err != nil {
+		t.Fatalf("review error = %v\n%s", err, out)
+	}
+	for _, want := range []string{"Review of PR #42", "APPROVE", "SPEC-ISSUE-7", "project root", "PASSED", "pass (1)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGithubReview_PRHeadWorktree(t *testing.T) {
+	gh := setupFakeGH(t, fakeChecksPass)
+	root, err := findProjectRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The local checkout moves past the pull request head
+	writeQualityFile(t, root, "calc/extra.go", "package calc\n")
+	for _, args := range [][]string{
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "feat(calc): add extra"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	out, err := executeGithub(t, newReviewCmd(gh.runner()), "42")
+	if err != nil {
+		t.Fatalf("review error = %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "temporary worktree @ "+gh.head[:12]) {
+		t.Errorf("output missing the temporary worktree at the PR head:\n%s", out)
+	}
+	worktrees, err := exec.Command("git", "-C", root, "worktree", "list").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(strings.TrimSpace(string(worktrees)), "\n"); n != 0 {
+		t.Errorf("temporary worktree not removed:\n%s", worktrees)
+	}
+
+	gh.head = strings.Repeat("0", 40)
+	if _, err := executeGithub(t, newReviewCmd(gh.runner()), "42"); !errors.Is(err, github.ErrPRHeadUnavailable) {
+		t.Errorf("review of an unknown head error = %v, want ErrPRHeadUnavailable", err)
+	}
+}
+
+func TestGithubReview_RequestChanges(t *testing.T) {
+	gh := setupFakeGH(t, fakeChecksFail)
+
+	out, err := executeGithub(t, newReviewCmd(gh.runner()), "42")
+	if err == nil || !strings.Contains(err.Error(), "changes requested") {
+		t.Fatalf("review error = %v, want changes requested", err)
+	}
+	for _, want := range []string{"REQUEST_CHANGES", `check "test": failure`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGithubMerge_WaitsAndClosesIssue(t *testing.T) {
+	gh := setupFakeGH(t, fakeChecksPending, fakeChecksPending, fakeChecksPass)
+
+	out, err := executeGithub(t, newMergeCmd(gh.runner()), "42", "--wait-checks", "--lang", "ko", "--delete-branch")
+	if err != nil {
+		t.Fatalf("merge error = %v\n%s", err, out)
+	}
+	if call, ok := gh.called("pr merge"); !ok || call != "pr merge 42 --squash --delete-branch" {
+		t.Errorf("merge command = %q, want a squash merge deleting the branch", call)
+	}
+	comment, ok := gh.called("issue comment 7 --body")
+	if !ok || !strings.Contains(comment, "이슈가 성공적으로 해결되었습니다") || !strings.Contains(comment, "Add OAuth login") || !strings.Contains(comment, "#42") {
+		t.Errorf("issue comment = %q, want a Korean summary of PR #42", comment)
+	}
+	if _, ok := gh.called("issue close 7"); !ok {
+		t.Error("issue 7 not closed")
+	}
+	for _, want := range []string{"Waiting for CI checks of PR #42", "Merged PR #42", "Closed Issue #7"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGithubMerge_NoChecksConfigured(t *testing.T) {
+	gh := setupFakeGH(t, fakeChecksNone)
+
+	out, err := executeGithub(t, newMergeCmd(gh.runner()), "42", "--wait-checks")
+	if err != nil {
+		t.Fatalf("merge error = %v\n%s", err, out)
+	}
+	if _, ok := gh.called("pr merge 42 --squash"); !ok {
+		t.Errorf("calls = %v, want a merge without configured checks", gh.calls)
+	}
+	for _, want := range []string{"Waiting for CI checks of PR #42", "No CI checks reported for PR #42", "Merged PR #42"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestGithubMerge_LinkedIssue(t *testing.T) {
+	gh := setupFakeGH(t, fakeChecksPass)
+	origFactory := GithubSpecLinkerFactory
+	t.Cleanup(func() { GithubSpecLinkerFactory = origFactory })
+	GithubSpecLinkerFactory = func(string) (github.SpecLinker, error) {
+		return &mockGHSpecLinker{getIssueFunc: func(specID string) (int, error) {
+			if specID == "SPEC-AUTH-001" {
+				return 12, nil
+			}
+			return 0, github.ErrMappingNotFound
+		}}, nil
+	}
+
+	if out, err := executeGithub(t, newMergeCmd(gh.runner()), "42", "--spec", "SPEC-AUTH-001", "--skip-review", "--method", "rebase"); err != nil {
+		t.Fatalf("merge error = %v\n%s", err, out)
+	}
+	if _, ok := gh.called("pr merge 42 --rebase"); !ok {
+		t.Errorf("calls = %v, want a rebase merge", gh.calls)
+	}
+	if comment, ok := gh.called("issue comment 12"); !ok || !strings.Contains(comment, "This issue has been resolved") {
+		t.Errorf("issue comment = %q, want an English comment on the linked issue", comment)
+	}
+}
+
+func TestGithubMerge_Blocked(t *testing.T) {
+	gh := setupFakeGH(t, fakeChecksPending)
+
+	_, err := executeGithub(t, newMergeCmd(gh.runner()), "42")
+	if !errors.Is(err, github.ErrMergeBlocked) || !strings.Contains(err.Error(), "--wait-checks") {
+		t.Errorf("error = %v, want a blocked merge with a --wait-checks hint", err)
+	}
+	if _, ok := gh.called("pr merge"); ok {
+		t.Error("blocked PR was merged")
+	}
+
+	gh.checks = []string{fakeChecksFail}
+	if _, err := executeGithub(t, newMergeCmd(gh.runner()), "42", "--wait-checks"); !errors.Is(err, github.ErrCIFailed) {
+		t.Errorf("error = %v, want ErrCIFailed", err)
+	}
+}
+
+func TestGithubMerge_InvalidArgs(t *testing.T) {
+	gh := setupFakeGH(t, fakeChecksPass)
+
+	for _, args := range [][]string{{"abc"}, {"0"}, {"42", "--method", "ff"}, {"42", "--timeout", "-1s"}} {
+		if _, err := executeGithub(t, newMergeCmd(gh.runner()), args...); err == nil {
+			t.Errorf("args %v: want error", args)
+		}
+	}
+}
+
+func TestGithubCloseIssue(t *testing.T) {
+	gh := setupFakeGH(t, fakeChecksPass)
+
+	out, err := executeGithub(t, newCloseIssueCmd(gh.runner()), "9", "--pr", "42", "--lang", "xx")
+	if err != nil {
+		t.Fatalf("close-issue error = %v\n%s", err, out)
+	}
+	comment, ok := gh.called("issue comment 9 --body")
+	if !ok || !strings.Contains(comment, "Add OAuth login") || !strings.Contains(comment, "**Related PR:** #42") {
+		t.Errorf("issue comment = %q, want the PR title in the English fallback", comment)
+	}
+	for _, prefix := range []string{"issue edit 9 --add-label resolved", "issue close 9"} {
+		if _, ok := gh.called(prefix); !ok {
+			t.Errorf("missing %q", prefix)
+		}
+	}
+
+	if _, err := executeGithub(t, newCloseIssueCmd(gh.runner()), "9"); err == nil {
+		t.Error("close-issue without --pr: want error")
+	}
+}
+
+// setupFakeGitLab creates a quality project configured for the GitLab
+// provider on a stand-in API serving responses keyed by "METHOD path",
+// with $HEAD replaced by the project's HEAD, and returns the requests it
+// receives.
+func setupFakeGitLab(t *testing.T, responses map[string]string) *[]string {
+	t.Helper()
+	root := chdirQualityProject(t, "true")
+	head := gitHead(t, root)
+	for key, resp := range responses {
+		responses[key] = strings.ReplaceAll(resp, "$HEAD", head)
+	}
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Method + " " + strings.TrimPrefix(r.URL.EscapedPath(), "/api/v4/projects/group%2Fapp")
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, strings.TrimSpace(key+" "+string(body)))
+		mu.Unlock()
+		resp, ok := responses[key]
+		if !ok || r.Header.Get("PRIVATE-TOKEN") != "glpat-test" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, resp)
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("GITLAB_TOKEN", "glpat-test")
+
+	writeQualityFile(t, root, ".moai/config/sections/git-strategy.yaml",
+		"git_strategy:\n  provider: \"gitlab\"\n  gitlab:\n    instance_url: \""+srv.URL+"\"\n")
+	if out, err := exec.Command("git", "-C", root, "remote", "add", "origin", srv.URL+"/group/app.git").CombinedOutput(); err != nil {
+		t.Fatalf("git remote add: %v\n%s", err, out)
+	}
+	return &requests
+}
+
+func TestGithubMerge_GitLab(t *testing.T) {
+	requests := setupFakeGitLab(t, map[string]string{
+		"GET /merge_requests/7": `{"iid":7,"title":"Add OAuth login","state":"opened","merge_status":"can_be_merged",` +
+			`"source_branch":"feature/SPEC-ISSUE-3","sha":"$HEAD","target_branch":"main"}`,
+		"GET /merge_requests/7/pipelines": `[{"id":21}]`,
+		"GET /pipelines/21/jobs":          `[{"name":"test","status":"success"}]`,
+		"PUT /merge_requests/7/merge":     `{"iid":7,"state":"merged"}`,
+		"POST /issues/3/notes":            `{}`,
+		"PUT /issues/3":                   `{}`,
+	})
+
+	out, err := executeGithub(t, newMergeCmd(testGithubRunner(nil)), "7", "--wait-checks")
+	if err != nil {
+		t.Fatalf("merge error = %v\n%s", err, out)
+	}
+	for _, want := range []string{"Merged MR !7 Add OAuth login", "Closed Issue #3"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	log := strings.Join(*requests, "\n")
+	for _, want := range []string{
+		`PUT /merge_requests/7/merge {"should_remove_source_branch":false,"squash":true}`,
+		`POST /issues/3/notes {"body":`,
+		`PUT /issues/3 {"add_labels":"resolved"}`,
+		`PUT /issues/3 {"state_event":"close"}`,
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("requests missing %q:\n%s", want, log)
+		}
+	}
+}
+
+func TestGithubReview_GitLab(t *testing.T) {
+	setupFakeGitLab(t, map[string]string{
+		"GET /merge_requests/7":           `{"iid":7,"title":"Add OAuth login","state":"opened","source_branch":"feature/SPEC-ISSUE-3","sha":"$HEAD"}`,
+		"GET /merge_requests/7/pipelines": `[]`,
+	})
+
+	out, err := executeGithub(t, newReviewCmd(testGithubRunner(nil)), "7")
+	if err != nil {
+		t.Fatalf("review error = %v\n%s", err, out)
+	}
+	for _, want := range []string{"Review of MR !7", "none reported"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := executeGithub(t, newMergeCmd(testGithubRunner(nil)), "7", "--method", "rebase", "--skip-checks", "--skip-review"); err == nil ||
+		!strings.Contains(err.Error(), "unsupported merge method") {
+		t.Errorf("rebase merge error = %v, want unsupported merge method", err)
+	}
+}
+
+func TestGithubMerge_ChecksReportedLate(t *testing.T) {
+	gh := setupFakeGH(t, fakeChecksNone, fakeChecksPending, fakeChecksPass)
+
+	out, err := executeGithub(t, newMergeCmd(gh.runner()), "42", "--wait-checks")
+	if err != nil {
+		t.Fatalf("merge error = %v\n%s", err, out)
+	}
+	if strings.Contains(out, "No CI checks reported") {
+		t.Errorf("checks reported after the first poll were ignored:\n%s", out)
+	}
+	if !strings.Contains(out, "Merged PR #42") {
+		t.Errorf("output missing the merge:\n%s", out)
+	}
+}
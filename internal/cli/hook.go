@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/modu-ai/moai-adk/internal/hook"
+	"github.com/modu-ai/moai-adk/internal/hook/agents"
 )
 
 var hookCmd = &cobra.Command{
@@ -75,19 +76,27 @@ func runHookEvent(cmd *cobra.Command, event hook.EventType) error {
 	if deps == nil || deps.HookProtocol == nil || deps.HookRegistry == nil {
 		return fmt.Errorf("hook system not initialized")
 	}
+	policyErr := deps.EnsureHooks()
 
 	input, err := deps.HookProtocol.ReadInput(os.Stdin)
 	if err != nil {
 		return fmt.Errorf("read hook input: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+	// Stop may run the quality gate and PostToolUse the auto-format tools,
+	// which get their own time limits
+	timeout := 30 * time.Second
+	if deps.Config != nil {
+		timeout = hook.EventTimeout(deps.Config, event, timeout)
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
 	defer cancel()
 
 	output, err := deps.HookRegistry.Dispatch(ctx, event, input)
 	if err != nil {
 		return fmt.Errorf("dispatch hook: %w", err)
 	}
+	output = withPolicyWarning(output, policyErr)
 
 	if writeErr := deps.HookProtocol.WriteOutput(os.Stdout, output); writeErr != nil {
 		return fmt.Errorf("write hook output: %w", writeErr)
@@ -114,6 +123,7 @@ func runHookList(cmd *cobra.Command, _ []string) error {
 		_, _ = fmt.Fprintln(out, renderInfoCard("Registered Hook Handlers", "Hook system not initialized."))
 		return nil
 	}
+	_ = deps.EnsureHooks()
 
 	events := hook.ValidEventTypes()
 	totalHandlers := 0
@@ -146,6 +156,7 @@ func runAgentHook(cmd *cobra.Command, args []string) error {
 	if deps == nil || deps.HookProtocol == nil || deps.HookRegistry == nil {
 		return fmt.Errorf("hook system not initialized")
 	}
+	policyErr := deps.EnsureHooks()
 
 	action := args[0]
 
@@ -155,33 +166,14 @@ func runAgentHook(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("read hook input: %w", err)
 	}
 
-	// Determine the event type based on the action suffix
-	// PreToolUse: *-validation, *-pre-transformation, *-pre-implementation
-	// PostToolUse: *-verification, *-post-transformation, *-post-implementation
-	// SubagentStop: *-completion
-	var event hook.EventType
-	switch {
-	case endsWithAny(action, "-validation", "-pre-transformation", "-pre-implementation"):
-		event = hook.EventPreToolUse
-	case endsWithAny(action, "-verification", "-post-transformation", "-post-implementation"):
-		event = hook.EventPostToolUse
-	case endsWith(action, "-completion"):
-		event = hook.EventSubagentStop
-	default:
-		// Default to PreToolUse for unknown actions
-		event = hook.EventPreToolUse
-	}
-
-	// Add action to input for handler identification
-	input.Data = []byte(fmt.Sprintf(`{"action":"%s"}`, action))
-
 	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
 	defer cancel()
 
-	output, err := deps.HookRegistry.Dispatch(ctx, event, input)
+	output, err := dispatchAgentHook(ctx, deps.HookRegistry, deps.Config, action, input)
 	if err != nil {
 		return fmt.Errorf("dispatch agent hook: %w", err)
 	}
+	output = withPolicyWarning(output, policyErr)
 
 	if writeErr := deps.HookProtocol.WriteOutput(os.Stdout, output); writeErr != nil {
 		return fmt.Errorf("write hook output: %w", writeErr)
@@ -200,17 +192,46 @@ func runAgentHook(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// endsWith checks if a string ends with any of the given suffixes.
-func endsWith(s string, suffixes ...string) bool {
-	for _, suffix := range suffixes {
-		if len(s) >= len(suffix) && s[len(s)-len(suffix):] == suffix {
-			return true
-		}
+// withPolicyWarning adds policyErr, the reason the hooks fell back to the
+// built-in security policy, to the system message of output so that a
+// broken security.yaml does not go unnoticed.
+func withPolicyWarning(output *hook.HookOutput, policyErr error) *hook.HookOutput {
+	if policyErr == nil {
+		return output
+	}
+	if output == nil {
+		output = &hook.HookOutput{}
 	}
-	return false
+	msg := fmt.Sprintf("MoAI security policy not applied, using the built-in rules: %v", policyErr)
+	if output.SystemMessage != "" {
+		msg = output.SystemMessage + "\n" + msg
+	}
+	output.SystemMessage = msg
+	return output
 }
 
-// endsWithAny is an alias for endsWith for readability.
-func endsWithAny(s string, suffixes ...string) bool {
-	return endsWith(s, suffixes...)
+// dispatchAgentHook runs the agents.Factory handler for action. The event
+// type is the one the handler declares for its action (PreToolUse for
+// *-validation and *-pre-*, PostToolUse for *-verification and *-post-*,
+// SubagentStop for *-completion); actions without an agent prefix run the
+// default handler as PreToolUse. The handlers registered in registry for
+// that event (security policy, quality gates) run first and short-circuit
+// on deny or block.
+func dispatchAgentHook(ctx context.Context, registry hook.Registry, cfg hook.ConfigProvider, action string, input *hook.HookInput) (*hook.HookOutput, error) {
+	handler, err := agents.NewFactory(cfg).CreateHandler(action)
+	if err != nil {
+		handler = agents.NewDefaultHandler(action)
+	}
+	event := handler.EventType()
+
+	chain := hook.NewRegistry(cfg)
+	for _, h := range registry.Handlers(event) {
+		chain.Register(h)
+	}
+	chain.Register(handler)
+
+	// Add action to input for handler identification
+	input.Data = []byte(fmt.Sprintf(`{"action":%q}`, action))
+
+	return chain.Dispatch(ctx, event, input)
 }
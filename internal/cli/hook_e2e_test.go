@@ -128,6 +128,7 @@ func TestHookDepsWiring_AllHandlersRegistered(t *testing.T) {
 	defer func() { deps = origDeps }()
 
 	InitDependencies()
+	_ = deps.EnsureHooks()
 
 	allEvents := []hook.EventType{
 		hook.EventSessionStart,
@@ -339,10 +340,10 @@ func TestHookDepsWiring_HandlerCounts(t *testing.T) {
 	defer func() { deps = origDeps }()
 
 	InitDependencies()
+	_ = deps.EnsureHooks()
 
 	// Each new event should have exactly 1 handler registered via InitDependencies.
 	singleHandlerEvents := []hook.EventType{
-		hook.EventPostToolUseFailure,
 		hook.EventNotification,
 		hook.EventSubagentStart,
 		hook.EventUserPromptSubmit,
@@ -365,16 +366,19 @@ func TestHookDepsWiring_HandlerCounts(t *testing.T) {
 			hook.EventSessionStart, len(sessionStartHandlers))
 	}
 
-	// PreToolUse should have exactly 1 handler.
-	preToolHandlers := deps.HookRegistry.Handlers(hook.EventPreToolUse)
-	if len(preToolHandlers) != 1 {
-		t.Errorf("event %q: got %d handlers, want 1", hook.EventPreToolUse, len(preToolHandlers))
+	// Tool events have the generic handler plus the TDD cycle tracker;
+	// PreToolUse also captures diagnostics baselines, and PostToolUse
+	// auto-formats and records changed files for the Stop quality gate.
+	wantToolHandlers := map[hook.EventType]int{
+		hook.EventPreToolUse:         3,
+		hook.EventPostToolUse:        4,
+		hook.EventPostToolUseFailure: 2,
 	}
-
-	// PostToolUse should have exactly 1 handler.
-	postToolHandlers := deps.HookRegistry.Handlers(hook.EventPostToolUse)
-	if len(postToolHandlers) != 1 {
-		t.Errorf("event %q: got %d handlers, want 1", hook.EventPostToolUse, len(postToolHandlers))
+	for event, want := range wantToolHandlers {
+		handlers := deps.HookRegistry.Handlers(event)
+		if len(handlers) != want {
+			t.Errorf("event %q: got %d handlers, want %d", event, len(handlers), want)
+		}
 	}
 }
 
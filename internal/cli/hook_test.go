@@ -2,8 +2,14 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/hook"
 )
 
 func TestHookCmd_Exists(t *testing.T) {
@@ -95,3 +101,100 @@ func TestHookCmd_ListWithNilDeps(t *testing.T) {
 		t.Errorf("output should indicate not initialized, got %q", output)
 	}
 }
+
+func TestDispatchAgentHook(t *testing.T) {
+	// The directory names the active SPEC, whose TDD cycle starts in RED.
+	projectDir := filepath.Join(t.TempDir(), "SPEC-CALC-001")
+	if err := os.MkdirAll(filepath.Join(projectDir, ".moai"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeInput := func(file string) *hook.HookInput {
+		raw, _ := json.Marshal(map[string]string{"file_path": filepath.Join(projectDir, file)})
+		return &hook.HookInput{ToolName: "Write", ToolInput: raw, CWD: projectDir, ProjectDir: projectDir}
+	}
+
+	t.Run("agent handler denies production edit before a failing test", func(t *testing.T) {
+		registry := hook.NewRegistry(nil)
+		security := &mockHandler{eventType: hook.EventPreToolUse}
+		registry.Register(security)
+
+		out, err := dispatchAgentHook(context.Background(), registry, nil, "tdd-pre-implementation", writeInput("calc.go"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if security.calls != 1 {
+			t.Errorf("generic PreToolUse handler calls = %d, want 1", security.calls)
+		}
+		if out.HookSpecificOutput == nil || out.HookSpecificOutput.PermissionDecision != hook.DecisionDeny {
+			t.Fatalf("expected deny, got %+v", out)
+		}
+		if !strings.Contains(out.HookSpecificOutput.PermissionDecisionReason, "calc_test.go") {
+			t.Errorf("reason should name the expected test file: %s", out.HookSpecificOutput.PermissionDecisionReason)
+		}
+	})
+
+	t.Run("security deny short-circuits agent handler", func(t *testing.T) {
+		registry := hook.NewRegistry(nil)
+		registry.Register(&mockHandler{eventType: hook.EventPreToolUse, output: hook.NewDenyOutput("blocked by policy")})
+
+		out, err := dispatchAgentHook(context.Background(), registry, nil, "tdd-pre-implementation", writeInput("calc_test.go"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.HookSpecificOutput == nil || out.HookSpecificOutput.PermissionDecisionReason != "blocked by policy" {
+			t.Errorf("expected security deny, got %+v", out)
+		}
+	})
+
+	t.Run("post-tool actions run PostToolUse handlers only", func(t *testing.T) {
+		registry := hook.NewRegistry(nil)
+		security := &mockHandler{eventType: hook.EventPreToolUse, output: hook.NewDenyOutput("blocked by policy")}
+		postTool := &mockHandler{eventType: hook.EventPostToolUse}
+		registry.Register(security)
+		registry.Register(postTool)
+
+		out, err := dispatchAgentHook(context.Background(), registry, nil, "docs-verification", writeInput("README.md"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if security.calls != 0 {
+			t.Errorf("PreToolUse handler should not run for a PostToolUse action")
+		}
+		if postTool.calls != 1 {
+			t.Errorf("PostToolUse handler calls = %d, want 1", postTool.calls)
+		}
+		if out.Decision == hook.DecisionBlock {
+			t.Errorf("unexpected block: %s", out.Reason)
+		}
+	})
+
+	t.Run("completion actions run SubagentStop handlers", func(t *testing.T) {
+		registry := hook.NewRegistry(nil)
+		registry.Register(&mockHandler{eventType: hook.EventSubagentStop, output: hook.NewStopBlockOutput("tests are failing")})
+
+		out, err := dispatchAgentHook(context.Background(), registry, nil, "tdd-completion", &hook.HookInput{CWD: projectDir, ProjectDir: projectDir})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.Decision != hook.DecisionBlock || out.Reason != "tests are failing" {
+			t.Errorf("expected the SubagentStop block, got %+v", out)
+		}
+	})
+
+	t.Run("action without agent prefix defaults to PreToolUse", func(t *testing.T) {
+		registry := hook.NewRegistry(nil)
+		security := &mockHandler{eventType: hook.EventPreToolUse}
+		registry.Register(security)
+
+		out, err := dispatchAgentHook(context.Background(), registry, nil, "nodash", &hook.HookInput{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if security.calls != 1 {
+			t.Errorf("PreToolUse handler calls = %d, want 1", security.calls)
+		}
+		if out.HookSpecificOutput != nil && out.HookSpecificOutput.PermissionDecision == hook.DecisionDeny {
+			t.Errorf("unexpected deny: %+v", out.HookSpecificOutput)
+		}
+	})
+}
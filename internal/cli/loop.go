@@ -0,0 +1,479 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/loop"
+	"github.com/modu-ai/moai-adk/internal/ralph"
+)
+
+const (
+	// loopHeartbeat is how often a running loop refreshes its marker and
+	// reads control requests.
+	loopHeartbeat = 500 * time.Millisecond
+
+	// loopStaleAfter is the marker age after which a loop process is
+	// considered gone.
+	loopStaleAfter = 5 * time.Second
+
+	// loopStopTimeout bounds how long pause and cancel wait for a running
+	// loop to stop.
+	loopStopTimeout = time.Minute
+)
+
+// Control requests written by "moai loop pause" and "moai loop cancel"
+// for the process running the loop.
+const (
+	loopRequestPause  = "pause"
+	loopRequestCancel = "cancel"
+)
+
+var loopCmd = &cobra.Command{
+	Use:   "loop",
+	Short: "Ralph feedback loop",
+	Long: `Run the Ralph feedback loop for a SPEC: build, test, lint and measure
+coverage on every iteration, and let the Ralph engine decide whether to
+continue, converge, request a human review or abort.
+
+The commands are detected from the project languages and can be
+overridden in .moai/config/sections/ralph.yaml (ralph.commands). Loop
+state is kept in .moai/loop/<SPEC>.json.`,
+}
+
+func init() {
+	rootCmd.AddCommand(loopCmd)
+	loopCmd.AddCommand(
+		newLoopStartCmd(),
+		newLoopStatusCmd(),
+		newLoopPauseCmd(),
+		newLoopResumeCmd(),
+		newLoopCancelCmd(),
+	)
+}
+
+func newLoopStartCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "start <SPEC-ID>",
+		Short: "Start a feedback loop and run it until it stops",
+		Long: `Start a feedback loop for a SPEC and run it in the foreground until it
+converges, aborts or requests a review. Interrupting it keeps the state
+for 'moai loop resume'.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return runLoop(cmd, args[0], false)
+		},
+	}
+}
+
+func newLoopResumeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "resume <SPEC-ID>",
+		Short: "Resume a paused or reviewed feedback loop",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return runLoop(cmd, args[0], true)
+		},
+	}
+}
+
+func newLoopStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status <SPEC-ID>",
+		Short: "Show the state of a feedback loop",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runLoopStatus,
+	}
+}
+
+func newLoopPauseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pause <SPEC-ID>",
+		Short: "Pause a running feedback loop",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return requestLoopStop(cmd, args[0], loopRequestPause)
+		},
+	}
+}
+
+func newLoopCancelCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel <SPEC-ID>",
+		Short: "Cancel a feedback loop and remove its state",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return requestLoopStop(cmd, args[0], loopRequestCancel)
+		},
+	}
+}
+
+// loopFiles locates the state, running marker and control request files
+// of the loop of a SPEC.
+type loopFiles struct {
+	dir    string
+	specID string
+}
+
+// newLoopFiles returns the loop files of specID in the project at root.
+func newLoopFiles(root, specID string) loopFiles {
+	return loopFiles{dir: filepath.Join(root, defs.MoAIDir, defs.LoopSubdir), specID: specID}
+}
+
+func (f loopFiles) running() string { return filepath.Join(f.dir, f.specID+".running") }
+func (f loopFiles) control() string { return filepath.Join(f.dir, f.specID+".control") }
+
+// isRunning reports whether a process is running the loop, judged by the
+// age of its heartbeat marker.
+func (f loopFiles) isRunning() bool {
+	info, err := os.Stat(f.running())
+	return err == nil && time.Since(info.ModTime()) < loopStaleAfter
+}
+
+// heartbeat creates or refreshes the running marker.
+func (f loopFiles) heartbeat() error {
+	if err := os.MkdirAll(f.dir, defs.DirPerm); err != nil {
+		return fmt.Errorf("create loop directory: %w", err)
+	}
+	return os.WriteFile(f.running(), []byte(strconv.Itoa(os.Getpid())), defs.FilePerm)
+}
+
+// takeRequest returns and removes the pending control request, if any.
+func (f loopFiles) takeRequest() string {
+	data, err := os.ReadFile(f.control())
+	if err != nil {
+		return ""
+	}
+	_ = os.Remove(f.control())
+	return strings.TrimSpace(string(data))
+}
+
+// release removes the running marker and any unread request.
+func (f loopFiles) release() {
+	_ = os.Remove(f.running())
+	_ = os.Remove(f.control())
+}
+
+// validateLoopSpecID rejects SPEC IDs that cannot name a state file.
+func validateLoopSpecID(specID string) error {
+	if specID == "" || specID != filepath.Base(specID) || strings.HasPrefix(specID, ".") {
+		return fmt.Errorf("invalid SPEC ID %q", specID)
+	}
+	return nil
+}
+
+// runLoop starts or resumes the loop of specID and waits for it to stop,
+// serving pause and cancel requests from other processes.
+func runLoop(cmd *cobra.Command, specID string, resume bool) error {
+	if err := validateLoopSpecID(specID); err != nil {
+		return err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.NewConfigManager().Load(root)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	files := newLoopFiles(root, specID)
+	if files.isRunning() {
+		return fmt.Errorf("loop for %s: %w", specID, loop.ErrLoopAlreadyRunning)
+	}
+	storage := loop.NewFileStorage(files.dir)
+	_, stateErr := storage.LoadState(specID)
+	switch {
+	case resume && stateErr != nil:
+		return fmt.Errorf("no loop to resume for %s: %w", specID, stateErr)
+	case !resume && stateErr == nil:
+		return fmt.Errorf("a loop for %s already exists: resume it with 'moai loop resume %s' or remove it with 'moai loop cancel %s'", specID, specID, specID)
+	}
+
+	commands := ralph.DetectCommands(root, nil, cfg.Ralph.Commands)
+	if commands == (config.RalphCommands{}) {
+		return fmt.Errorf("%w: set ralph.commands in .moai/config/sections/ralph.yaml", ralph.ErrNoCommands)
+	}
+
+	out := cmd.OutOrStdout()
+	coverageTarget := float64(cfg.Quality.TestCoverageTarget)
+	engine := &reportingEngine{inner: ralph.NewRalphEngine(cfg.Ralph, ralph.WithCoverageTarget(coverageTarget)), out: out}
+	generator := ralph.NewCommandFeedbackGenerator(root, commands)
+	ctrl := loop.NewLoopController(storage, engine, generator, cfg.Ralph.MaxIterations)
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := files.heartbeat(); err != nil {
+		return err
+	}
+	defer files.release()
+	_ = os.Remove(files.control())
+
+	writeLoopCommands(out, specID, commands, resume)
+	if resume {
+		err = ctrl.ResumeFromStorage(ctx, specID)
+	} else {
+		err = ctrl.Start(ctx, specID)
+	}
+	if err != nil {
+		return err
+	}
+
+	request, err := waitLoop(ctx, ctrl, files)
+	if err != nil {
+		return err
+	}
+
+	status := ctrl.Status()
+	decision, feedback := engine.last()
+	switch {
+	case request == loopRequestPause:
+		_, _ = fmt.Fprintf(out, "Paused %s at iteration %d. Resume with 'moai loop resume %s'.\n", specID, status.Iteration, specID)
+		return nil
+	case request == loopRequestCancel:
+		_, _ = fmt.Fprintf(out, "Cancelled the loop for %s.\n", specID)
+		return nil
+	case ctx.Err() != nil:
+		_, _ = fmt.Fprintf(out, "Interrupted %s at iteration %d. Resume with 'moai loop resume %s'.\n", specID, status.Iteration, specID)
+		return nil
+	case decision == nil:
+		return fmt.Errorf("loop for %s stopped without a decision", specID)
+	}
+
+	switch decision.Action {
+	case loop.ActionConverge:
+		_, _ = fmt.Fprintf(out, "Converged %s after %d iteration(s): %s.\n", specID, status.Iteration, decision.Reason)
+		return nil
+	case loop.ActionRequestReview:
+		writeReviewPrompt(out, specID, status, feedback, coverageTarget)
+		return nil
+	default:
+		return fmt.Errorf("loop for %s aborted at iteration %d: %s", specID, status.Iteration, decision.Reason)
+	}
+}
+
+// waitLoop waits for the loop goroutine to stop, refreshing the running
+// marker and serving control requests. It returns the request served.
+func waitLoop(ctx context.Context, ctrl *loop.LoopController, files loopFiles) (string, error) {
+	ticker := time.NewTicker(loopHeartbeat)
+	defer ticker.Stop()
+	done := ctrl.Done()
+	for {
+		select {
+		case <-done:
+			return "", nil
+		case <-ctx.Done():
+			<-done
+			return "", nil
+		case <-ticker.C:
+			_ = files.heartbeat()
+			switch files.takeRequest() {
+			case loopRequestPause:
+				if err := ctrl.Pause(); err != nil && !errors.Is(err, loop.ErrLoopNotRunning) {
+					return "", err
+				}
+				return loopRequestPause, nil
+			case loopRequestCancel:
+				if err := ctrl.Cancel(); err != nil {
+					return "", err
+				}
+				return loopRequestCancel, nil
+			}
+		}
+	}
+}
+
+// requestLoopStop asks the process running the loop of specID to pause or
+// cancel it and waits for it to stop. A cancel without a running process
+// removes the saved state.
+func requestLoopStop(cmd *cobra.Command, specID, request string) error {
+	if err := validateLoopSpecID(specID); err != nil {
+		return err
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	files := newLoopFiles(root, specID)
+	out := cmd.OutOrStdout()
+
+	if !files.isRunning() {
+		storage := loop.NewFileStorage(files.dir)
+		if _, err := storage.LoadState(specID); err != nil {
+			return fmt.Errorf("no loop for %s: %w", specID, loop.ErrLoopNotRunning)
+		}
+		if request == loopRequestPause {
+			return fmt.Errorf("loop for %s is stopped, its state is saved: %w", specID, loop.ErrLoopNotRunning)
+		}
+		if err := storage.DeleteState(specID); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "Cancelled the loop for %s.\n", specID)
+		return nil
+	}
+
+	if err := os.WriteFile(files.control(), []byte(request), defs.FilePerm); err != nil {
+		return fmt.Errorf("write loop request: %w", err)
+	}
+	deadline := time.Now().Add(loopStopTimeout)
+	for files.isRunning() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("loop for %s did not stop within %s", specID, loopStopTimeout)
+		}
+		select {
+		case <-cmd.Context().Done():
+			return cmd.Context().Err()
+		case <-time.After(loopHeartbeat / 2):
+		}
+	}
+	verb := "Paused"
+	if request == loopRequestCancel {
+		verb = "Cancelled"
+	}
+	_, _ = fmt.Fprintf(out, "%s the loop for %s.\n", verb, specID)
+	return nil
+}
+
+func runLoopStatus(cmd *cobra.Command, args []string) error {
+	specID := args[0]
+	if err := validateLoopSpecID(specID); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	root, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	files := newLoopFiles(root, specID)
+	state, err := loop.NewFileStorage(files.dir).LoadState(specID)
+	if errors.Is(err, fs.ErrNotExist) {
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "No loop for %s.\n", specID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	run := "stopped (resume with 'moai loop resume " + specID + "')"
+	if files.isRunning() {
+		run = "running"
+	}
+	pairs := []kvPair{
+		{"SPEC", state.SpecID},
+		{"State", run},
+		{"Phase", string(state.Phase)},
+		{"Iteration", fmt.Sprintf("%d of %d", state.Iteration, state.MaxIter)},
+		{"Started", state.StartedAt.Local().Format("2006-01-02 15:04")},
+		{"Updated", state.UpdatedAt.Local().Format("2006-01-02 15:04")},
+	}
+	if n := len(state.Feedback); n > 0 {
+		cfg, err := config.NewConfigManager().Load(root)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		pairs = append(pairs, feedbackPairs(&state.Feedback[n-1], float64(cfg.Quality.TestCoverageTarget))...)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), renderCard("Feedback Loop", renderKeyValueLines(pairs)))
+	return nil
+}
+
+// feedbackPairs renders the results of fb against coverageTarget.
+func feedbackPairs(fb *loop.Feedback, coverageTarget float64) []kvPair {
+	build := "ok"
+	if !fb.BuildSuccess {
+		build = "failed"
+	}
+	return []kvPair{
+		{"Build", build},
+		{"Tests", fmt.Sprintf("%d passed, %d failed", fb.TestsPassed, fb.TestsFailed)},
+		{"Lint errors", strconv.Itoa(fb.LintErrors)},
+		{"Coverage", fmt.Sprintf("%.1f%% (target %.0f%%)", fb.Coverage, coverageTarget)},
+	}
+}
+
+// writeLoopCommands prints the commands the loop runs.
+func writeLoopCommands(w io.Writer, specID string, c config.RalphCommands, resume bool) {
+	verb := "Starting"
+	if resume {
+		verb = "Resuming"
+	}
+	_, _ = fmt.Fprintf(w, "%s the feedback loop for %s\n", verb, specID)
+	for _, kv := range []kvPair{{"build", c.Build}, {"test", c.Test}, {"lint", c.Lint}, {"coverage", c.Coverage}} {
+		if kv.value != "" {
+			_, _ = fmt.Fprintf(w, "  %-8s %s\n", kv.key, kv.value)
+		}
+	}
+}
+
+// writeReviewPrompt prints the review request of the loop of specID.
+func writeReviewPrompt(w io.Writer, specID string, status *loop.LoopStatus, fb *loop.Feedback, coverageTarget float64) {
+	pairs := []kvPair{
+		{"SPEC", specID},
+		{"Iteration", fmt.Sprintf("%d of %d", status.Iteration, status.MaxIter)},
+	}
+	if fb != nil {
+		pairs = append(pairs, feedbackPairs(fb, coverageTarget)...)
+	}
+	_, _ = fmt.Fprintln(w, renderCard("Review Requested", renderKeyValueLines(pairs)))
+	_, _ = fmt.Fprintf(w, `
+Review the changes and fix the remaining issues, then continue with:
+  moai loop resume %s
+or stop the loop with:
+  moai loop cancel %s
+`, specID, specID)
+}
+
+// reportingEngine prints every decision of the wrapped engine and keeps
+// the last one.
+type reportingEngine struct {
+	inner loop.DecisionEngine
+	out   io.Writer
+
+	mu       sync.Mutex
+	decision *loop.Decision
+	feedback *loop.Feedback
+}
+
+// Decide implements loop.DecisionEngine.
+func (e *reportingEngine) Decide(ctx context.Context, state *loop.LoopState, fb *loop.Feedback) (*loop.Decision, error) {
+	decision, err := e.inner.Decide(ctx, state, fb)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = fmt.Fprintf(e.out, "Iteration %d/%d: %s -> %s (%s)\n",
+		state.Iteration, state.MaxIter, fb.Notes, decision.Action, decision.Reason)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.decision = decision
+	copied := *fb
+	e.feedback = &copied
+	return decision, nil
+}
+
+// last returns the last decision and the feedback it was made on.
+func (e *reportingEngine) last() (*loop.Decision, *loop.Feedback) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.decision, e.feedback
+}
@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/loop"
+)
+
+// chdirLoopProject creates a project whose ralph commands report the
+// given coverage within maxIterations and returns its root.
+func chdirLoopProject(t *testing.T, coverage string, maxIterations int) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("loop commands are shell commands")
+	}
+	root := chdirLSPProject(t)
+	writeQualityFile(t, root, ".moai/config/sections/ralph.yaml", `ralph:
+  max_iterations: `+strconv.Itoa(maxIterations)+`
+  auto_converge: true
+  human_review: true
+  commands:
+    build: "true"
+    test: "echo coverage: `+coverage+`% of statements"
+    lint: "true"
+`)
+	return root
+}
+
+// executeLoop runs the loop subcommand built by newCmd with args and
+// returns its output and error.
+func executeLoop(t *testing.T, newCmd func() *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	cmd := newCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func TestLoopCmd_Subcommands(t *testing.T) {
+	want := map[string]bool{"start": false, "status": false, "pause": false, "resume": false, "cancel": false}
+	for _, sub := range loopCmd.Commands() {
+		if _, ok := want[sub.Name()]; ok {
+			want[sub.Name()] = true
+		}
+	}
+	for name, found := range want {
+		if !found {
+			t.Errorf("loop command missing %q subcommand", name)
+		}
+	}
+}
+
+func TestLoopStart_Converges(t *testing.T) {
+	root := chdirLoopProject(t, "90.0", 5)
+
+	out, err := executeLoop(t, newLoopStartCmd, "SPEC-LOOP-001")
+	if err != nil {
+		t.Fatalf("loop start error = %v\n%s", err, out)
+	}
+	for _, want := range []string{"test     echo coverage: 90.0% of statements", "-> converge", "Converged SPEC-LOOP-001 after 1 iteration(s)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, ".moai", "loop", "SPEC-LOOP-001.json")); !os.IsNotExist(err) {
+		t.Errorf("state after convergence: %v, want removed", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".moai", "loop", "SPEC-LOOP-001.running")); !os.IsNotExist(err) {
+		t.Errorf("running marker after exit: %v, want removed", err)
+	}
+}
+
+func TestLoopStart_ConfiguredCoverageTarget(t *testing.T) {
+	root := chdirLoopProject(t, "50.0", 5)
+	writeQualityFile(t, root, ".moai/config/sections/quality.yaml", `constitution:
+  test_coverage_target: 60
+`)
+
+	out, err := executeLoop(t, newLoopStartCmd, "SPEC-LOOP-001")
+	if err != nil {
+		t.Fatalf("loop start error = %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "50.0% (target 60%)") {
+		t.Errorf("review output missing the configured target:\n%s", out)
+	}
+	out, err = executeLoop(t, newLoopStatusCmd, "SPEC-LOOP-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "50.0% (target 60%)") {
+		t.Errorf("status missing the configured target:\n%s", out)
+	}
+
+	writeQualityFile(t, root, ".moai/config/sections/quality.yaml", `constitution:
+  test_coverage_target: 50
+`)
+	out, err = executeLoop(t, newLoopResumeCmd, "SPEC-LOOP-001")
+	if err != nil {
+		t.Fatalf("loop resume error = %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "quality gate satisfied") {
+		t.Errorf("resume output = %s, want the 50%% target met", out)
+	}
+}
+
+func TestLoopStart_ReviewThenResume(t *testing.T) {
+	chdirLoopProject(t, "50.0", 5)
+
+	out, err := executeLoop(t, newLoopStartCmd, "SPEC-LOOP-001")
+	if err != nil {
+		t.Fatalf("loop start error = %v\n%s", err, out)
+	}
+	for _, want := range []string{"Review Requested", "50.0% (target 85%)", "moai loop resume SPEC-LOOP-001", "moai loop cancel SPEC-LOOP-001"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := executeLoop(t, newLoopStartCmd, "SPEC-LOOP-001"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("second start error = %v, want an existing loop error", err)
+	}
+
+	out, err = executeLoop(t, newLoopStatusCmd, "SPEC-LOOP-001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Feedback Loop", "review", "1 of 5", "stopped", "0 passed, 0 failed", "50.0%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("status missing %q:\n%s", want, out)
+		}
+	}
+	if _, err := executeLoop(t, newLoopPauseCmd, "SPEC-LOOP-001"); !errors.Is(err, loop.ErrLoopNotRunning) {
+		t.Errorf("pause of a stopped loop error = %v, want ErrLoopNotRunning", err)
+	}
+
+	// Unchanged results on the next iteration converge as stagnant.
+	out, err = executeLoop(t, newLoopResumeCmd, "SPEC-LOOP-001")
+	if err != nil {
+		t.Fatalf("loop resume error = %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Converged SPEC-LOOP-001 after 2 iteration(s): no improvement detected (stagnant)") {
+		t.Errorf("resume output:\n%s", out)
+	}
+	out, _ = executeLoop(t, newLoopStatusCmd, "SPEC-LOOP-001")
+	if !strings.Contains(out, "No loop for SPEC-LOOP-001") {
+		t.Errorf("status after convergence:\n%s", out)
+	}
+}
+
+func TestLoopCancel_Stopped(t *testing.T) {
+	root := chdirLoopProject(t, "50.0", 5)
+
+	if _, err := executeLoop(t, newLoopStartCmd, "SPEC-LOOP-001"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := executeLoop(t, newLoopCancelCmd, "SPEC-LOOP-001")
+	if err != nil || !strings.Contains(out, "Cancelled the loop for SPEC-LOOP-001") {
+		t.Fatalf("cancel = %q, %v", out, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".moai", "loop", "SPEC-LOOP-001.json")); !os.IsNotExist(err) {
+		t.Errorf("state after cancel: %v, want removed", err)
+	}
+	if _, err := executeLoop(t, newLoopCancelCmd, "SPEC-LOOP-001"); !errors.Is(err, loop.ErrLoopNotRunning) {
+		t.Errorf("second cancel error = %v, want ErrLoopNotRunning", err)
+	}
+	if _, err := executeLoop(t, newLoopResumeCmd, "SPEC-LOOP-001"); err == nil {
+		t.Error("resume of a cancelled loop: want error")
+	}
+}
+
+func TestLoopStart_Aborts(t *testing.T) {
+	chdirLoopProject(t, "50.0", 1)
+
+	_, err := executeLoop(t, newLoopStartCmd, "SPEC-LOOP-001")
+	if err == nil || !strings.Contains(err.Error(), "aborted at iteration 1: max iterations reached") {
+		t.Errorf("error = %v, want an abort at the iteration limit", err)
+	}
+}
+
+func TestLoopStart_InvalidSpecID(t *testing.T) {
+	chdirLoopProject(t, "90.0", 5)
+
+	for _, specID := range []string{"../escape", ".hidden"} {
+		if _, err := executeLoop(t, newLoopStartCmd, specID); err == nil {
+			t.Errorf("start %q: want error", specID)
+		}
+	}
+}
+
+func TestLoopPause_Running(t *testing.T) {
+	root := chdirLoopProject(t, "50.0", 5)
+	writeQualityFile(t, root, ".moai/config/sections/ralph.yaml", `ralph:
+  commands:
+    test: "sleep 30"
+`)
+
+	type result struct {
+		out string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		out, err := executeLoop(t, newLoopStartCmd, "SPEC-LOOP-001")
+		done <- result{out, err}
+	}()
+
+	files := newLoopFiles(root, "SPEC-LOOP-001")
+	deadline := time.Now().Add(10 * time.Second)
+	for !files.isRunning() {
+		if time.Now().After(deadline) {
+			t.Fatal("loop did not start")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	out, err := executeLoop(t, newLoopPauseCmd, "SPEC-LOOP-001")
+	if err != nil || !strings.Contains(out, "Paused the loop for SPEC-LOOP-001") {
+		t.Fatalf("pause = %q, %v", out, err)
+	}
+	select {
+	case r := <-done:
+		if r.err != nil || !strings.Contains(r.out, "Paused SPEC-LOOP-001 at iteration 1") {
+			t.Errorf("start = %q, %v", r.out, r.err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("loop start did not return after pause")
+	}
+	if _, err := os.Stat(filepath.Join(root, ".moai", "loop", "SPEC-LOOP-001.json")); err != nil {
+		t.Errorf("state after pause: %v, want kept", err)
+	}
+}
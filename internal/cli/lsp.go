@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/hook"
+	"github.com/modu-ai/moai-adk/internal/lsp"
+)
+
+// lspDaemonStartTimeout bounds how long "moai lsp serve --detach" waits
+// for the background daemon to accept connections.
+const lspDaemonStartTimeout = 10 * time.Second
+
+// lspDaemonLogName is the log file of a background daemon in .moai/logs.
+const lspDaemonLogName = "lsp-daemon.log"
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Language server daemon and code intelligence",
+	Long: `Manage the per-project LSP daemon. The daemon keeps language servers
+(gopls, pyright, typescript-language-server, rust-analyzer) running so the
+PostToolUse hook gets diagnostics from real language servers in milliseconds
+instead of falling back to CLI tools.
+
+The def, refs, hover, symbols, workspace-symbols, rename, code-actions and
+format commands query the same servers for precise, project-wide navigation
+and refactoring. They use the daemon when it runs and otherwise start a
+language server for the command.`,
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+
+	lspCmd.AddCommand(
+		newLSPServeCmd(),
+		newLSPStatusCmd(),
+		newLSPStopCmd(),
+		newLSPDefCmd(),
+		newLSPRefsCmd(),
+		newLSPHoverCmd(),
+		newLSPSymbolsCmd(),
+		newLSPWorkspaceSymbolsCmd(),
+		newLSPRenameCmd(),
+		newLSPCodeActionsCmd(),
+		newLSPFormatCmd(),
+	)
+}
+
+func newLSPServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the LSP daemon for the current project",
+		Long: `Run the LSP daemon for the current project on a per-project unix socket.
+Language servers start on the first request for a file of their language,
+or at startup with --languages. The daemon exits after --idle-timeout
+without requests, on 'moai lsp stop', or on interrupt.
+
+Example:
+  moai lsp serve --detach --languages go,typescript`,
+		Args: cobra.NoArgs,
+		RunE: runLSPServe,
+	}
+	cmd.Flags().Bool("detach", false, "Run the daemon in the background and return")
+	cmd.Flags().Duration("idle-timeout", lsp.DefaultDaemonIdleTimeout, "Stop after this long without requests (0 disables)")
+	cmd.Flags().StringSlice("languages", nil, "Languages whose servers start immediately (go, python, typescript, rust)")
+	return cmd
+}
+
+func runLSPServe(cmd *cobra.Command, _ []string) error {
+	out := cmd.OutOrStdout()
+	root, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	detach, _ := cmd.Flags().GetBool("detach")
+	idleTimeout, _ := cmd.Flags().GetDuration("idle-timeout")
+	languages, _ := cmd.Flags().GetStringSlice("languages")
+
+	if detach {
+		return startLSPDaemon(cmd.Context(), root, idleTimeout, languages, out)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	daemon := lsp.NewDaemon(root, lsp.NewStdioLauncher(root, nil), lsp.WithIdleTimeout(idleTimeout))
+	_, _ = fmt.Fprintf(out, "LSP daemon for %s listening on %s\n", root, daemon.Socket())
+	if err := daemon.Serve(ctx, languages...); err != nil {
+		return fmt.Errorf("lsp daemon: %w", err)
+	}
+	return nil
+}
+
+// startLSPDaemon runs "moai lsp serve" in the background for root,
+// detached from the terminal with its output appended to
+// .moai/logs/lsp-daemon.log, and waits until it accepts connections.
+func startLSPDaemon(ctx context.Context, root string, idleTimeout time.Duration, languages []string, out io.Writer) error {
+	if client, err := lsp.DialDaemon(ctx, root); err == nil {
+		_ = client.Close()
+		_, _ = fmt.Fprintln(out, "LSP daemon is already running.")
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate moai executable: %w", err)
+	}
+	args := []string{"lsp", "serve", "--idle-timeout", idleTimeout.String()}
+	if len(languages) > 0 {
+		args = append(args, "--languages", strings.Join(languages, ","))
+	}
+	logPath := filepath.Join(root, defs.MoAIDir, defs.LogsSubdir, lspDaemonLogName)
+	if err := os.MkdirAll(filepath.Dir(logPath), defs.DirPerm); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, defs.FilePerm)
+	if err != nil {
+		return fmt.Errorf("open lsp daemon log: %w", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	daemon := exec.Command(exe, args...)
+	daemon.Dir = root
+	daemon.Stdout = logFile
+	daemon.Stderr = logFile
+	detachProcess(daemon)
+	if err := daemon.Start(); err != nil {
+		return fmt.Errorf("start lsp daemon: %w", err)
+	}
+	pid := daemon.Process.Pid
+	_ = daemon.Process.Release()
+
+	deadline := time.Now().Add(lspDaemonStartTimeout)
+	for time.Now().Before(deadline) {
+		if client, err := lsp.DialDaemon(ctx, root); err == nil {
+			_ = client.Close()
+			_, _ = fmt.Fprintln(out, renderSuccessCard("LSP daemon started",
+				fmt.Sprintf("PID:    %d", pid),
+				fmt.Sprintf("Socket: %s", lsp.DaemonSocketPath(root)),
+				fmt.Sprintf("Log:    %s", hook.DisplayPath(root, logPath)),
+			))
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("lsp daemon (pid %d) did not start within %s; see %s", pid, lspDaemonStartTimeout, logPath)
+}
+
+func newLSPStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the LSP daemon and its language servers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			root, err := findProjectRoot()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Second)
+			defer cancel()
+			client, err := lsp.DialDaemon(ctx, root)
+			if errors.Is(err, lsp.ErrDaemonNotRunning) {
+				_, _ = fmt.Fprintln(out, "LSP daemon is not running. Start it with 'moai lsp serve --detach'.")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			defer func() { _ = client.Close() }()
+
+			status, err := client.Status(ctx)
+			if err != nil {
+				return err
+			}
+
+			servers := "none"
+			if len(status.Servers) > 0 {
+				var names []string
+				for _, s := range status.Servers {
+					name := s.Language
+					if !s.Healthy {
+						name += " (unhealthy)"
+					}
+					names = append(names, name)
+				}
+				servers = strings.Join(names, ", ")
+			}
+			pairs := []kvPair{
+				{"PID", fmt.Sprintf("%d", status.PID)},
+				{"Project", status.Root},
+				{"Socket", status.Socket},
+				{"Uptime", time.Since(status.StartedAt).Round(time.Second).String()},
+				{"Servers", servers},
+			}
+			_, _ = fmt.Fprintln(out, renderCard("LSP Daemon", renderKeyValueLines(pairs)))
+			return nil
+		},
+	}
+}
+
+func newLSPStopCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the LSP daemon and its language servers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			root, err := findProjectRoot()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+			defer cancel()
+			client, err := lsp.DialDaemon(ctx, root)
+			if errors.Is(err, lsp.ErrDaemonNotRunning) {
+				_, _ = fmt.Fprintln(out, "LSP daemon is not running.")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			defer func() { _ = client.Close() }()
+
+			if err := client.Shutdown(ctx); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(out, "LSP daemon stopped.")
+			return nil
+		},
+	}
+}
+
+// lspProjectRoot returns the MoAI project containing path, or "" if
+// there is none. It maps edited files to their LSP daemon.
+func lspProjectRoot(path string) string {
+	dir := filepath.Dir(path)
+	for {
+		if info, err := os.Stat(filepath.Join(dir, defs.MoAIDir)); err == nil && info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,728 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/hook"
+	"github.com/modu-ai/moai-adk/internal/lsp"
+	"github.com/modu-ai/moai-adk/internal/merge"
+)
+
+// lspCodeTimeout bounds a code intelligence command, including the start
+// of a language server when no daemon is running.
+const lspCodeTimeout = 2 * time.Minute
+
+// lspLanguageMarkers maps project files to the language of the project,
+// for workspace symbol searches without --lang.
+var lspLanguageMarkers = []struct {
+	file string
+	lang string
+}{
+	{"go.mod", "go"},
+	{"Cargo.toml", "rust"},
+	{"tsconfig.json", "typescript"},
+	{"package.json", "typescript"},
+	{"pyproject.toml", "python"},
+	{"setup.py", "python"},
+	{"requirements.txt", "python"},
+}
+
+// lspCodeIntelligence connects to the code intelligence of lang for the
+// project at root: the running LSP daemon when there is one, otherwise a
+// language server started for this command. The returned function
+// releases it. Tests replace it.
+var lspCodeIntelligence = func(ctx context.Context, root, lang string) (lsp.CodeIntelligence, func(), error) {
+	if client, err := lsp.DialDaemon(ctx, root); err == nil {
+		return client.Intelligence(lang), func() { _ = client.Close() }, nil
+	}
+
+	client, process, err := lsp.NewStdioLauncher(root, nil).Launch(ctx, lang)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := client.Shutdown(stopCtx); err != nil {
+			_ = process.Kill()
+		}
+	}, nil
+}
+
+// lspCodeSession is the state of one code intelligence command.
+type lspCodeSession struct {
+	ctx    context.Context
+	root   string
+	out    io.Writer
+	format string
+	intel  lsp.CodeIntelligence
+	files  map[string][][]byte
+}
+
+// newLSPCodeSession validates the --format flag and connects to the code
+// intelligence of lang. Callers must call release.
+func newLSPCodeSession(cmd *cobra.Command, lang string) (*lspCodeSession, func(), error) {
+	format, _ := cmd.Flags().GetString("format")
+	if !slices.Contains([]string{qualityFormatText, qualityFormatJSON}, format) {
+		return nil, nil, fmt.Errorf("invalid format %q: use text or json", format)
+	}
+	root, err := findProjectRoot()
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd.SilenceUsage = true
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), lspCodeTimeout)
+	intel, closeIntel, err := lspCodeIntelligence(ctx, root, lang)
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("connect to the %s language server: %w", lang, err)
+	}
+	s := &lspCodeSession{
+		ctx:    ctx,
+		root:   root,
+		out:    cmd.OutOrStdout(),
+		format: format,
+		intel:  intel,
+		files:  make(map[string][][]byte),
+	}
+	return s, func() {
+		closeIntel()
+		cancel()
+	}, nil
+}
+
+// addFormatFlag adds the --format flag of the code intelligence commands.
+func addFormatFlag(cmd *cobra.Command) {
+	cmd.Flags().String("format", qualityFormatText, "Output format: text or json (compact)")
+}
+
+// lspTarget is a file, and optionally a position in it, given on the
+// command line.
+type lspTarget struct {
+	path string
+	uri  string
+	lang string
+	pos  lsp.Position
+}
+
+// parseLSPTarget parses "<file>" or, with position, "<file>:<line>:<col>"
+// where line and column are 1-based and the column counts bytes, as in
+// compiler and grep output. The column may be omitted to target the start
+// of the line.
+func parseLSPTarget(arg string, position bool) (*lspTarget, error) {
+	path := arg
+	line, col := 0, 1
+	if position {
+		parts := strings.Split(arg, ":")
+		var nums []int
+		for len(parts) > 1 && len(nums) < 2 {
+			n, err := strconv.Atoi(parts[len(parts)-1])
+			if err != nil {
+				break
+			}
+			nums = append([]int{n}, nums...)
+			parts = parts[:len(parts)-1]
+		}
+		if len(nums) == 0 || nums[0] < 1 || (len(nums) == 2 && nums[1] < 1) {
+			return nil, fmt.Errorf("invalid location %q: use <file>:<line>:<col>", arg)
+		}
+		path, line = strings.Join(parts, ":"), nums[0]
+		if len(nums) == 2 {
+			col = nums[1]
+		}
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+	lang := lsp.LanguageForPath(abs)
+	if lang == "" {
+		return nil, fmt.Errorf("no language server for %s", path)
+	}
+
+	t := &lspTarget{path: abs, uri: lsp.FileURI(abs), lang: lang}
+	if position {
+		lines := bytes.Split(content, []byte("\n"))
+		if line > len(lines) {
+			return nil, fmt.Errorf("invalid location %q: %s has %d lines", arg, path, len(lines))
+		}
+		text := bytes.TrimSuffix(lines[line-1], []byte("\r"))
+		t.pos = lsp.Position{Line: line - 1, Character: lsp.ByteToUTF16Column(text, col-1)}
+	}
+	return t, nil
+}
+
+// lspCodeLocation is a location printed by the code intelligence commands,
+// with a 1-based line and byte column.
+type lspCodeLocation struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Text   string `json:"text,omitempty"`
+}
+
+// location converts an LSP position in the file at uri for printing.
+func (s *lspCodeSession) location(uri string, pos lsp.Position) lspCodeLocation {
+	path := lsp.URIToPath(uri)
+	loc := lspCodeLocation{Path: hook.DisplayPath(s.root, path), Line: pos.Line + 1, Column: pos.Character + 1}
+
+	lines, ok := s.files[path]
+	if !ok {
+		if content, err := os.ReadFile(path); err == nil {
+			lines = bytes.Split(content, []byte("\n"))
+		}
+		s.files[path] = lines
+	}
+	if pos.Line < len(lines) {
+		text := bytes.TrimSuffix(lines[pos.Line], []byte("\r"))
+		loc.Column = lsp.UTF16ToByteColumn(text, pos.Character) + 1
+		loc.Text = strings.TrimSpace(string(text))
+	}
+	return loc
+}
+
+// writeJSON prints v as compact JSON.
+func (s *lspCodeSession) writeJSON(v any) error {
+	return json.NewEncoder(s.out).Encode(v)
+}
+
+// writeLocations prints locations, one "<path>:<line>:<col>: <text>" per line.
+func (s *lspCodeSession) writeLocations(locations []lsp.Location, none string) error {
+	out := make([]lspCodeLocation, 0, len(locations))
+	for _, l := range locations {
+		out = append(out, s.location(l.URI, l.Range.Start))
+	}
+	if s.format == qualityFormatJSON {
+		return s.writeJSON(out)
+	}
+	if len(out) == 0 {
+		_, _ = fmt.Fprintln(s.out, none)
+		return nil
+	}
+	for _, l := range out {
+		_, _ = fmt.Fprintf(s.out, "%s:%d:%d: %s\n", l.Path, l.Line, l.Column, l.Text)
+	}
+	return nil
+}
+
+func newLSPDefCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "def <file>:<line>:<col>",
+		Aliases: []string{"definition"},
+		Short:   "Print the definition of the symbol at a position",
+		Long: `Print where the symbol at a position is defined, using the project's
+LSP daemon when it runs or a language server started for the command.
+Lines and columns are 1-based; columns count bytes as in compiler output.
+
+Example:
+  moai lsp def internal/cli/lsp.go:42:15`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLSPLocations(cmd, args[0], "No definition found.", lsp.CodeIntelligence.Definition)
+		},
+	}
+	addFormatFlag(cmd)
+	return cmd
+}
+
+func newLSPRefsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "refs <file>:<line>:<col>",
+		Aliases: []string{"references"},
+		Short:   "Print the references to the symbol at a position",
+		Long: `Print every reference to the symbol at a position across the project,
+including its declaration.
+
+Example:
+  moai lsp refs internal/lsp/client.go:120:6 --format json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runLSPLocations(cmd, args[0], "No references found.", lsp.CodeIntelligence.References)
+		},
+	}
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// runLSPLocations prints the locations returned by query for the position arg.
+func runLSPLocations(cmd *cobra.Command, arg, none string,
+	query func(lsp.CodeIntelligence, context.Context, string, lsp.Position) ([]lsp.Location, error)) error {
+	target, err := parseLSPTarget(arg, true)
+	if err != nil {
+		return err
+	}
+	s, release, err := newLSPCodeSession(cmd, target.lang)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	locations, err := query(s.intel, s.ctx, target.uri, target.pos)
+	if err != nil {
+		return err
+	}
+	return s.writeLocations(locations, none)
+}
+
+func newLSPHoverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "hover <file>:<line>:<col>",
+		Short: "Print the type and documentation of the symbol at a position",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := parseLSPTarget(args[0], true)
+			if err != nil {
+				return err
+			}
+			s, release, err := newLSPCodeSession(cmd, target.lang)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			hover, err := s.intel.Hover(s.ctx, target.uri, target.pos)
+			if err != nil {
+				return err
+			}
+			if s.format == qualityFormatJSON {
+				if hover == nil {
+					return s.writeJSON(nil)
+				}
+				return s.writeJSON(map[string]string{"contents": hover.Contents})
+			}
+			if hover == nil || strings.TrimSpace(hover.Contents) == "" {
+				_, _ = fmt.Fprintln(s.out, "No hover information.")
+				return nil
+			}
+			_, _ = fmt.Fprintln(s.out, strings.TrimSpace(hover.Contents))
+			return nil
+		},
+	}
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// lspCodeSymbol is a symbol printed by the symbol commands.
+type lspCodeSymbol struct {
+	Name      string          `json:"name"`
+	Kind      string          `json:"kind"`
+	Container string          `json:"container,omitempty"`
+	Path      string          `json:"path,omitempty"`
+	Line      int             `json:"line"`
+	Column    int             `json:"column"`
+	Children  []lspCodeSymbol `json:"children,omitempty"`
+}
+
+// documentSymbols converts the document symbols of uri for printing.
+func (s *lspCodeSession) documentSymbols(uri string, symbols []lsp.DocumentSymbol) []lspCodeSymbol {
+	out := make([]lspCodeSymbol, 0, len(symbols))
+	for _, sym := range symbols {
+		start := sym.SelectionRange.Start
+		if sym.SelectionRange == (lsp.Range{}) {
+			start = sym.Range.Start
+		}
+		loc := s.location(uri, start)
+		out = append(out, lspCodeSymbol{
+			Name:     sym.Name,
+			Kind:     sym.Kind.String(),
+			Line:     loc.Line,
+			Column:   loc.Column,
+			Children: s.documentSymbols(uri, sym.Children),
+		})
+	}
+	return out
+}
+
+// writeSymbolTree prints symbols as an indented "<line>:<col> <kind> <name>" tree.
+func writeSymbolTree(w io.Writer, symbols []lspCodeSymbol, depth int) {
+	for _, sym := range symbols {
+		_, _ = fmt.Fprintf(w, "%s%d:%d %s %s\n", strings.Repeat("  ", depth), sym.Line, sym.Column, sym.Kind, sym.Name)
+		writeSymbolTree(w, sym.Children, depth+1)
+	}
+}
+
+func newLSPSymbolsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "symbols <file>",
+		Short: "Print the symbols declared in a file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := parseLSPTarget(args[0], false)
+			if err != nil {
+				return err
+			}
+			s, release, err := newLSPCodeSession(cmd, target.lang)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			symbols, err := s.intel.Symbols(s.ctx, target.uri)
+			if err != nil {
+				return err
+			}
+			out := s.documentSymbols(target.uri, symbols)
+			if s.format == qualityFormatJSON {
+				return s.writeJSON(out)
+			}
+			if len(out) == 0 {
+				_, _ = fmt.Fprintln(s.out, "No symbols found.")
+				return nil
+			}
+			writeSymbolTree(s.out, out, 0)
+			return nil
+		},
+	}
+	addFormatFlag(cmd)
+	return cmd
+}
+
+func newLSPWorkspaceSymbolsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "workspace-symbols <query>",
+		Short: "Search the symbols of the project",
+		Long: `Search the symbols of the project whose name matches query, as the
+language server matches it (usually a fuzzy match). The language is
+detected from the project files unless --lang is given.
+
+Example:
+  moai lsp workspace-symbols NewClient --lang go`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lang, _ := cmd.Flags().GetString("lang")
+			if lang == "" {
+				root, err := findProjectRoot()
+				if err != nil {
+					return err
+				}
+				if lang = detectLSPLanguage(root); lang == "" {
+					return errors.New("cannot detect the project language: use --lang")
+				}
+			}
+			if _, ok := lsp.DefaultServerSpecs[lang]; !ok {
+				return fmt.Errorf("unsupported language %q: use go, python, typescript or rust", lang)
+			}
+			s, release, err := newLSPCodeSession(cmd, lang)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			symbols, err := s.intel.WorkspaceSymbols(s.ctx, args[0])
+			if err != nil {
+				return err
+			}
+			out := make([]lspCodeSymbol, 0, len(symbols))
+			for _, sym := range symbols {
+				loc := s.location(sym.Location.URI, sym.Location.Range.Start)
+				out = append(out, lspCodeSymbol{
+					Name:      sym.Name,
+					Kind:      sym.Kind.String(),
+					Container: sym.ContainerName,
+					Path:      loc.Path,
+					Line:      loc.Line,
+					Column:    loc.Column,
+				})
+			}
+			if s.format == qualityFormatJSON {
+				return s.writeJSON(out)
+			}
+			if len(out) == 0 {
+				_, _ = fmt.Fprintln(s.out, "No symbols found.")
+				return nil
+			}
+			for _, sym := range out {
+				name := sym.Name
+				if sym.Container != "" {
+					name = sym.Container + "." + name
+				}
+				_, _ = fmt.Fprintf(s.out, "%s:%d:%d: %s %s\n", sym.Path, sym.Line, sym.Column, sym.Kind, name)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().String("lang", "", "Language whose server is searched (go, python, typescript, rust)")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// detectLSPLanguage returns the language of the project at root from its
+// project files, or "" if none is found.
+func detectLSPLanguage(root string) string {
+	for _, m := range lspLanguageMarkers {
+		if _, err := os.Stat(filepath.Join(root, m.file)); err == nil {
+			return m.lang
+		}
+	}
+	return ""
+}
+
+// lspFileEdit summarizes the change of one file for printing.
+type lspFileEdit struct {
+	Path    string `json:"path"`
+	Changed bool   `json:"changed"`
+}
+
+// lspEditResult is the JSON output of the editing commands.
+type lspEditResult struct {
+	Applied bool          `json:"applied"`
+	Files   []lspFileEdit `json:"files"`
+	Diff    string        `json:"diff,omitempty"`
+}
+
+// writeFileChanges prints the unified diff of changes with dryRun, and
+// otherwise writes the changed files and lists them under title.
+func (s *lspCodeSession) writeFileChanges(changes []lsp.FileChange, dryRun bool, title string) error {
+	result := lspEditResult{Applied: !dryRun, Files: []lspFileEdit{}}
+	var diff strings.Builder
+	for _, c := range changes {
+		rel := hook.DisplayPath(s.root, c.Path)
+		changed := !bytes.Equal(c.Before, c.After)
+		result.Files = append(result.Files, lspFileEdit{Path: rel, Changed: changed})
+		if !changed {
+			continue
+		}
+		if dryRun {
+			diff.WriteString(merge.UnifiedDiff(rel, c.Before, c.After))
+			continue
+		}
+		if err := writeFilePreservingMode(c.Path, c.After); err != nil {
+			return err
+		}
+	}
+	result.Diff = diff.String()
+
+	if s.format == qualityFormatJSON {
+		return s.writeJSON(result)
+	}
+	if dryRun {
+		if result.Diff == "" {
+			_, _ = fmt.Fprintln(s.out, "No changes.")
+		}
+		_, _ = fmt.Fprint(s.out, result.Diff)
+		return nil
+	}
+	var lines []string
+	for _, f := range result.Files {
+		if f.Changed {
+			lines = append(lines, f.Path)
+		}
+	}
+	if len(lines) == 0 {
+		_, _ = fmt.Fprintln(s.out, "No changes.")
+		return nil
+	}
+	_, _ = fmt.Fprintln(s.out, renderSuccessCard(title, lines...))
+	return nil
+}
+
+// writeFilePreservingMode replaces the content of the file at path,
+// keeping its permissions.
+func writeFilePreservingMode(path string, content []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+func newLSPRenameCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <file>:<line>:<col> <new-name>",
+		Short: "Rename the symbol at a position across the project",
+		Long: `Rename the symbol at a position in every file that refers to it, as
+computed by the language server. With --dry-run the edits are printed as a
+unified diff and no file is written.
+
+Example:
+  moai lsp rename internal/lsp/client.go:120:6 newClient --dry-run`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := parseLSPTarget(args[0], true)
+			if err != nil {
+				return err
+			}
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			s, release, err := newLSPCodeSession(cmd, target.lang)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			edit, err := s.intel.Rename(s.ctx, target.uri, target.pos, args[1])
+			if err != nil {
+				return err
+			}
+			changes, err := lsp.PreviewWorkspaceEdit(edit)
+			if err != nil {
+				return err
+			}
+			return s.writeFileChanges(changes, dryRun, "Renamed to "+args[1])
+		},
+	}
+	cmd.Flags().Bool("dry-run", false, "Print the edits as a unified diff without writing files")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// lspCodeAction is a code action printed by "moai lsp code-actions".
+type lspCodeAction struct {
+	Index     int    `json:"index"`
+	Title     string `json:"title"`
+	Kind      string `json:"kind,omitempty"`
+	Preferred bool   `json:"preferred,omitempty"`
+	Command   string `json:"command,omitempty"`
+}
+
+func newLSPCodeActionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "code-actions <file>:<line>:<col>",
+		Short: "List or apply the fixes and refactorings offered at a position",
+		Long: `List the code actions (quick fixes for the diagnostics at the position,
+refactorings, import organization) the language server offers at a
+position. --apply N applies the Nth action of the list; actions that only
+run a server command cannot be applied.
+
+Example:
+  moai lsp code-actions main.go:12:3
+  moai lsp code-actions main.go:12:3 --apply 1 --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := parseLSPTarget(args[0], true)
+			if err != nil {
+				return err
+			}
+			apply, _ := cmd.Flags().GetInt("apply")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			s, release, err := newLSPCodeSession(cmd, target.lang)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			actions, err := s.intel.CodeActions(s.ctx, target.uri, lsp.Range{Start: target.pos, End: target.pos})
+			if err != nil {
+				return err
+			}
+			if apply > 0 {
+				if apply > len(actions) {
+					return fmt.Errorf("no code action %d: %d offered", apply, len(actions))
+				}
+				return s.applyCodeAction(target.uri, actions[apply-1], dryRun)
+			}
+
+			out := make([]lspCodeAction, 0, len(actions))
+			for i, a := range actions {
+				action := lspCodeAction{Index: i + 1, Title: a.Title, Kind: a.Kind, Preferred: a.IsPreferred}
+				if a.Edit == nil && a.Command != nil {
+					action.Command = a.Command.Command
+				}
+				out = append(out, action)
+			}
+			if s.format == qualityFormatJSON {
+				return s.writeJSON(out)
+			}
+			if len(out) == 0 {
+				_, _ = fmt.Fprintln(s.out, "No code actions.")
+				return nil
+			}
+			for _, a := range out {
+				line := fmt.Sprintf("%d. %s", a.Index, a.Title)
+				if a.Kind != "" {
+					line = fmt.Sprintf("%d. [%s] %s", a.Index, a.Kind, a.Title)
+				}
+				if a.Preferred {
+					line += " (preferred)"
+				}
+				if a.Command != "" {
+					line += " (command: " + a.Command + ")"
+				}
+				_, _ = fmt.Fprintln(s.out, line)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Int("apply", 0, "Apply the code action with this number from the list")
+	cmd.Flags().Bool("dry-run", false, "With --apply, print the edits as a unified diff without writing files")
+	addFormatFlag(cmd)
+	return cmd
+}
+
+// applyCodeAction resolves action and applies its edit.
+func (s *lspCodeSession) applyCodeAction(uri string, action lsp.CodeAction, dryRun bool) error {
+	resolved, err := s.intel.ResolveCodeAction(s.ctx, uri, action)
+	if err != nil {
+		return err
+	}
+	if resolved.Edit == nil {
+		if resolved.Command != nil {
+			return fmt.Errorf("code action %q only runs the server command %s, which cannot be applied from the CLI",
+				resolved.Title, resolved.Command.Command)
+		}
+		return fmt.Errorf("code action %q has no edit", resolved.Title)
+	}
+	changes, err := lsp.PreviewWorkspaceEdit(resolved.Edit)
+	if err != nil {
+		return err
+	}
+	return s.writeFileChanges(changes, dryRun, "Applied: "+resolved.Title)
+}
+
+func newLSPFormatCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "format <file>",
+		Short: "Format a file with its language server",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := parseLSPTarget(args[0], false)
+			if err != nil {
+				return err
+			}
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			tabSize, _ := cmd.Flags().GetInt("tab-size")
+			spaces, _ := cmd.Flags().GetBool("spaces")
+			s, release, err := newLSPCodeSession(cmd, target.lang)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			edits, err := s.intel.Format(s.ctx, target.uri, lsp.FormattingOptions{TabSize: tabSize, InsertSpaces: spaces})
+			if err != nil {
+				return err
+			}
+			changes, err := lsp.PreviewWorkspaceEdit(&lsp.WorkspaceEdit{Changes: map[string][]lsp.TextEdit{target.uri: edits}})
+			if err != nil {
+				return err
+			}
+			return s.writeFileChanges(changes, dryRun, "Formatted")
+		},
+	}
+	cmd.Flags().Bool("dry-run", false, "Print the edits as a unified diff without writing the file")
+	cmd.Flags().Int("tab-size", 4, "Tab size passed to the formatter")
+	cmd.Flags().Bool("spaces", false, "Indent with spaces instead of tabs")
+	addFormatFlag(cmd)
+	return cmd
+}
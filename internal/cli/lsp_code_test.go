@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/lsp"
+)
+
+// fakeCodeIntelligence answers the code intelligence commands from fields.
+type fakeCodeIntelligence struct {
+	lsp.CodeIntelligence
+	uri       string
+	pos       lsp.Position
+	locations []lsp.Location
+	edit      *lsp.WorkspaceEdit
+	actions   []lsp.CodeAction
+	resolved  *lsp.CodeAction
+}
+
+func (f *fakeCodeIntelligence) Definition(_ context.Context, uri string, pos lsp.Position) ([]lsp.Location, error) {
+	f.uri, f.pos = uri, pos
+	return f.locations, nil
+}
+
+func (f *fakeCodeIntelligence) Symbols(_ context.Context, _ string) ([]lsp.DocumentSymbol, error) {
+	return []lsp.DocumentSymbol{{
+		Name:           "Server",
+		Kind:           lsp.SymbolKindStruct,
+		SelectionRange: lsp.Range{Start: lsp.Position{Line: 2, Character: 5}},
+		Children:       []lsp.DocumentSymbol{{Name: "Addr", Kind: lsp.SymbolKindField, Range: lsp.Range{Start: lsp.Position{Line: 3, Character: 1}}}},
+	}}, nil
+}
+
+func (f *fakeCodeIntelligence) Rename(_ context.Context, uri string, pos lsp.Position, _ string) (*lsp.WorkspaceEdit, error) {
+	f.uri, f.pos = uri, pos
+	return f.edit, nil
+}
+
+func (f *fakeCodeIntelligence) CodeActions(_ context.Context, _ string, _ lsp.Range) ([]lsp.CodeAction, error) {
+	return f.actions, nil
+}
+
+func (f *fakeCodeIntelligence) ResolveCodeAction(_ context.Context, _ string, action lsp.CodeAction) (*lsp.CodeAction, error) {
+	if f.resolved != nil && action.Data != nil {
+		return f.resolved, nil
+	}
+	return &action, nil
+}
+
+// setupCodeIntelligence creates a MoAI project with a Go file, replaces
+// the code intelligence connection with intel and returns the file path.
+func setupCodeIntelligence(t *testing.T, intel *fakeCodeIntelligence) string {
+	t.Helper()
+	root := chdirLSPProject(t)
+	path := filepath.Join(root, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\n// é\ttype Server struct {\n\tAddr string\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := lspCodeIntelligence
+	t.Cleanup(func() { lspCodeIntelligence = orig })
+	lspCodeIntelligence = func(_ context.Context, _, lang string) (lsp.CodeIntelligence, func(), error) {
+		if lang != "go" {
+			t.Errorf("language = %q, want go", lang)
+		}
+		return intel, func() {}, nil
+	}
+	return path
+}
+
+// runLSPCodeCmd runs cmd with args and returns its output.
+func runLSPCodeCmd(t *testing.T, cmd *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func TestParseLSPTarget(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\nvar s = \"😀\" + x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := parseLSPTarget(path+":2:16", true)
+	if err != nil {
+		t.Fatalf("parseLSPTarget() error = %v", err)
+	}
+	// The emoji takes 4 bytes but 2 UTF-16 code units.
+	if target.lang != "go" || target.uri != lsp.FileURI(path) || target.pos != (lsp.Position{Line: 1, Character: 13}) {
+		t.Errorf("parseLSPTarget() = %+v", target)
+	}
+	if target, err := parseLSPTarget(path+":2", true); err != nil || target.pos != (lsp.Position{Line: 1}) {
+		t.Errorf("parseLSPTarget() without column = %+v, %v", target, err)
+	}
+
+	for _, arg := range []string{path, path + ":0:1", path + ":9:1", filepath.Join(dir, "notes.txt") + ":1:1"} {
+		if _, err := parseLSPTarget(arg, true); err == nil {
+			t.Errorf("parseLSPTarget(%q): want error", arg)
+		}
+	}
+}
+
+func TestLSPDef(t *testing.T) {
+	intel := &fakeCodeIntelligence{}
+	path := setupCodeIntelligence(t, intel)
+	intel.locations = []lsp.Location{{URI: lsp.FileURI(path), Range: lsp.Range{Start: lsp.Position{Line: 2, Character: 11}}}}
+
+	out, err := runLSPCodeCmd(t, newLSPDefCmd(), "main.go:4:2")
+	if err != nil {
+		t.Fatalf("def error = %v", err)
+	}
+	if intel.uri != lsp.FileURI(path) || intel.pos != (lsp.Position{Line: 3, Character: 1}) {
+		t.Errorf("queried %s %+v", intel.uri, intel.pos)
+	}
+	// Column 11 in UTF-16 units is byte column 13 after the two-byte é.
+	if want := "main.go:3:13: // é\ttype Server struct {\n"; out != want {
+		t.Errorf("def output = %q, want %q", out, want)
+	}
+
+	out, err = runLSPCodeCmd(t, newLSPDefCmd(), "main.go:4:2", "--format", "json")
+	if err != nil {
+		t.Fatalf("def --format json error = %v", err)
+	}
+	var locations []lspCodeLocation
+	if err := json.Unmarshal([]byte(out), &locations); err != nil || len(locations) != 1 || locations[0].Column != 13 {
+		t.Errorf("def JSON = %s (%v)", out, err)
+	}
+
+	intel.locations = nil
+	if out, _ := runLSPCodeCmd(t, newLSPDefCmd(), "main.go:4:2"); !strings.Contains(out, "No definition found.") {
+		t.Errorf("def output without result = %q", out)
+	}
+	if _, err := runLSPCodeCmd(t, newLSPDefCmd(), "main.go:4:2", "--format", "yaml"); err == nil {
+		t.Error("def --format yaml: want error")
+	}
+}
+
+func TestLSPSymbols(t *testing.T) {
+	setupCodeIntelligence(t, &fakeCodeIntelligence{})
+
+	out, err := runLSPCodeCmd(t, newLSPSymbolsCmd(), "main.go")
+	if err != nil {
+		t.Fatalf("symbols error = %v", err)
+	}
+	if want := "3:7 struct Server\n  4:2 field Addr\n"; out != want {
+		t.Errorf("symbols output = %q, want %q", out, want)
+	}
+}
+
+func TestLSPRename(t *testing.T) {
+	intel := &fakeCodeIntelligence{}
+	path := setupCodeIntelligence(t, intel)
+	intel.edit = &lsp.WorkspaceEdit{Changes: map[string][]lsp.TextEdit{lsp.FileURI(path): {{
+		Range:   lsp.Range{Start: lsp.Position{Line: 3, Character: 1}, End: lsp.Position{Line: 3, Character: 5}},
+		NewText: "Address",
+	}}}}
+
+	out, err := runLSPCodeCmd(t, newLSPRenameCmd(), "main.go:4:2", "Address", "--dry-run")
+	if err != nil {
+		t.Fatalf("rename --dry-run error = %v", err)
+	}
+	if !strings.Contains(out, "--- a/main.go") || !strings.Contains(out, "-\tAddr string") || !strings.Contains(out, "+\tAddress string") {
+		t.Errorf("rename diff = %q", out)
+	}
+	if data, _ := os.ReadFile(path); strings.Contains(string(data), "Address") {
+		t.Error("rename --dry-run wrote the file")
+	}
+
+	out, err = runLSPCodeCmd(t, newLSPRenameCmd(), "main.go:4:2", "Address", "--format", "json")
+	if err != nil {
+		t.Fatalf("rename error = %v", err)
+	}
+	var result lspEditResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil || !result.Applied || len(result.Files) != 1 || result.Files[0].Path != "main.go" {
+		t.Errorf("rename JSON = %s (%v)", out, err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "\tAddress string") {
+		t.Errorf("renamed file = %q", data)
+	}
+}
+
+func TestLSPCodeActions(t *testing.T) {
+	intel := &fakeCodeIntelligence{}
+	path := setupCodeIntelligence(t, intel)
+	intel.actions = []lsp.CodeAction{
+		{Title: "Organize imports", Command: &lsp.Command{Title: "Organize imports", Command: "source.organizeImports"}},
+		{Title: "Fill struct", Kind: "refactor.rewrite", IsPreferred: true, Data: json.RawMessage(`{}`)},
+	}
+	intel.resolved = &lsp.CodeAction{Title: "Fill struct", Edit: &lsp.WorkspaceEdit{Changes: map[string][]lsp.TextEdit{
+		lsp.FileURI(path): {{Range: lsp.Range{Start: lsp.Position{Line: 0, Character: 8}, End: lsp.Position{Line: 0, Character: 12}}, NewText: "server"}},
+	}}}
+
+	out, err := runLSPCodeCmd(t, newLSPCodeActionsCmd(), "main.go:4:2")
+	if err != nil {
+		t.Fatalf("code-actions error = %v", err)
+	}
+	for _, want := range []string{"1. Organize imports (command: source.organizeImports)", "2. [refactor.rewrite] Fill struct (preferred)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("code-actions output missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := runLSPCodeCmd(t, newLSPCodeActionsCmd(), "main.go:4:2", "--apply", "1"); err == nil || !strings.Contains(err.Error(), "source.organizeImports") {
+		t.Errorf("applying a command-only action: error = %v", err)
+	}
+	if _, err := runLSPCodeCmd(t, newLSPCodeActionsCmd(), "main.go:4:2", "--apply", "3"); err == nil {
+		t.Error("applying a missing action: want error")
+	}
+	if _, err := runLSPCodeCmd(t, newLSPCodeActionsCmd(), "main.go:4:2", "--apply", "2"); err != nil {
+		t.Fatalf("code-actions --apply 2 error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.HasPrefix(string(data), "package server\n") {
+		t.Errorf("file after the code action = %q", data)
+	}
+}
+
+func TestDetectLSPLanguage(t *testing.T) {
+	root := t.TempDir()
+	if got := detectLSPLanguage(root); got != "" {
+		t.Errorf("detectLSPLanguage() without project files = %q", got)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pyproject.toml"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectLSPLanguage(root); got != "python" {
+		t.Errorf("detectLSPLanguage() = %q, want python", got)
+	}
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/lsp"
+)
+
+// chdirLSPProject changes into a temporary MoAI project and returns its root.
+func chdirLSPProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".moai"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+
+	// Use the path as findProjectRoot sees it.
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cwd
+}
+
+// executeLSPCmd runs cmd without arguments and returns its output.
+func executeLSPCmd(t *testing.T, cmd *cobra.Command) string {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs([]string{})
+	if err := cmd.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("%s error = %v", cmd.Name(), err)
+	}
+	return buf.String()
+}
+
+func TestLSPCmd_Subcommands(t *testing.T) {
+	for _, name := range []string{"serve", "status", "stop", "def", "refs", "hover", "symbols", "workspace-symbols", "rename", "code-actions", "format"} {
+		found := false
+		for _, sub := range lspCmd.Commands() {
+			if sub.Name() == name {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("lsp command missing %q subcommand", name)
+		}
+	}
+}
+
+func TestLSPStatusAndStop_NotRunning(t *testing.T) {
+	chdirLSPProject(t)
+
+	if out := executeLSPCmd(t, newLSPStatusCmd()); !strings.Contains(out, "not running") {
+		t.Errorf("status output = %q, want not running", out)
+	}
+	if out := executeLSPCmd(t, newLSPStopCmd()); !strings.Contains(out, "not running") {
+		t.Errorf("stop output = %q, want not running", out)
+	}
+}
+
+func TestLSPStatusAndStop_Running(t *testing.T) {
+	root := chdirLSPProject(t)
+
+	daemon := lsp.NewDaemon(root, lsp.NewStdioLauncher(root, map[string]lsp.ServerSpec{}))
+	done := make(chan error, 1)
+	go func() { done <- daemon.Serve(context.Background()) }()
+	t.Cleanup(daemon.Stop)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		client, err := lsp.DialDaemon(context.Background(), root)
+		if err == nil {
+			_ = client.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daemon did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	out := executeLSPCmd(t, newLSPStatusCmd())
+	for _, want := range []string{"LSP Daemon", root, lsp.DaemonSocketPath(root), "none"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("status output missing %q:\n%s", want, out)
+		}
+	}
+
+	if out := executeLSPCmd(t, newLSPStopCmd()); !strings.Contains(out, "stopped") {
+		t.Errorf("stop output = %q, want stopped", out)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+}
+
+func TestLSPStatus_OutsideProject(t *testing.T) {
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+
+	cmd := newLSPStatusCmd()
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetArgs([]string{})
+	if err := cmd.ExecuteContext(context.Background()); err == nil {
+		t.Error("status outside a MoAI project: want error")
+	}
+}
+
+func TestLSPProjectRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".moai"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "internal", "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := lspProjectRoot(filepath.Join(root, "internal", "pkg", "file.go")); got != root {
+		t.Errorf("lspProjectRoot(nested) = %q, want %q", got, root)
+	}
+	if got := lspProjectRoot(filepath.Join(root, "main.go")); got != root {
+		t.Errorf("lspProjectRoot(top-level) = %q, want %q", got, root)
+	}
+	if got := lspProjectRoot(filepath.Join(t.TempDir(), "main.go")); got != "" {
+		t.Errorf("lspProjectRoot(outside) = %q, want empty", got)
+	}
+}
@@ -202,9 +202,15 @@ func (m *mockBrowser) Open(url string) error {
 // mockHandler implements hook.Handler for testing.
 type mockHandler struct {
 	eventType hook.EventType
+	output    *hook.HookOutput
+	calls     int
 }
 
 func (m *mockHandler) Handle(_ context.Context, _ *hook.HookInput) (*hook.HookOutput, error) {
+	m.calls++
+	if m.output != nil {
+		return m.output, nil
+	}
 	return hook.NewAllowOutput(), nil
 }
 
@@ -0,0 +1,599 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/core/coverage"
+	"github.com/modu-ai/moai-adk/internal/core/git"
+	"github.com/modu-ai/moai-adk/internal/core/mutation"
+	"github.com/modu-ai/moai-adk/internal/core/quality"
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/foundation"
+	"github.com/modu-ai/moai-adk/internal/hook"
+	"github.com/modu-ai/moai-adk/internal/hook/security"
+	"github.com/modu-ai/moai-adk/internal/lsp"
+	lsphook "github.com/modu-ai/moai-adk/internal/lsp/hook"
+	"github.com/modu-ai/moai-adk/pkg/models"
+)
+
+// trustBaselineFile is the diagnostic baseline written by the plan phase,
+// kept in .moai/memory unless --baseline names another file.
+const trustBaselineFile = "trust-baseline.json"
+
+// securityScanBatch bounds the ast-grep processes run at once.
+const securityScanBatch = 16
+
+// Report formats of "moai quality check".
+const (
+	qualityFormatText  = "text"
+	qualityFormatJSON  = "json"
+	qualityFormatSARIF = "sarif"
+	qualityFormatJUnit = "junit"
+)
+
+// lintTools are diagnostic sources whose findings count as lint rather
+// than type errors.
+var lintTools = []string{"go vet", "ruff", "eslint", "clippy", "golangci-lint", "staticcheck", "pylint", "flake8"}
+
+var qualityCmd = &cobra.Command{
+	Use:   "quality",
+	Short: "TRUST 5 quality gates",
+	Long: `Run the TRUST 5 quality gates (Tested, Readable, Understandable,
+Secured, Trackable) configured in .moai/config/sections/quality.yaml.`,
+}
+
+func init() {
+	rootCmd.AddCommand(qualityCmd)
+	qualityCmd.AddCommand(newQualityCheckCmd())
+	qualityCmd.AddCommand(newQualityMutateCmd())
+}
+
+func newQualityCheckCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Evaluate the project against the TRUST 5 quality gates",
+		Long: `Evaluate the project against the TRUST 5 quality gates and exit non-zero
+when the report fails.
+
+The gate is fed with:
+  - the build, test and lint commands of quality.stop_gate, or the
+    per-language defaults, run over all source files (diagnostics and
+    test coverage)
+  - coverage reports (Go coverprofile, LCOV, Cobertura XML or coverage.py
+    JSON) given with --coverage or found at their default locations,
+    which replace the coverage printed by the test command
+  - diagnostics from the LSP daemon, when 'moai lsp serve' is running
+  - ast-grep security findings, when ast-grep is installed
+  - the mutation score saved by 'moai quality mutate', when mutation
+    testing is enabled in quality.yaml
+  - the last commit message and the diagnostic baseline
+
+The plan phase writes the diagnostic baseline to --baseline
+(default .moai/memory/trust-baseline.json); run and sync compare
+against it to detect regressions.
+
+With --base-ref, the lines changed since the merge base with that ref
+are measured as new code, feeding the TDD and hybrid coverage rules.
+Lines marked with a justified coverage:ignore comment are exempt when
+quality.coverage_exemptions allows it.
+
+Examples:
+  moai quality check --phase sync --format sarif > trust5.sarif
+  moai quality check --coverage coverage.out --base-ref origin/main`,
+		Args: cobra.NoArgs,
+		RunE: runQualityCheck,
+	}
+	cmd.Flags().String("phase", string(quality.PhaseRun), "Workflow phase thresholds: plan, run or sync")
+	cmd.Flags().String("format", qualityFormatText, "Report format: text, json, sarif or junit")
+	cmd.Flags().String("baseline", "", "Diagnostic baseline file (default .moai/memory/trust-baseline.json)")
+	cmd.Flags().Duration("timeout", 10*time.Minute, "Maximum time for collecting diagnostics")
+	cmd.Flags().StringSlice("coverage", nil, "Coverage report files (default: detected in the project root)")
+	cmd.Flags().String("base-ref", "", "Git ref to measure new-code coverage against")
+	return cmd
+}
+
+// qualityInput is the project data collected for the TRUST 5 validators.
+type qualityInput struct {
+	files       []string
+	diagnostics []quality.Diagnostic
+	coverage    *float64
+	analysis    *quality.SourceAnalysis
+	sources     []string
+}
+
+func runQualityCheck(cmd *cobra.Command, _ []string) error {
+	phaseFlag, _ := cmd.Flags().GetString("phase")
+	format, _ := cmd.Flags().GetString("format")
+	baselinePath, _ := cmd.Flags().GetString("baseline")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	reports, _ := cmd.Flags().GetStringSlice("coverage")
+	baseRef, _ := cmd.Flags().GetString("base-ref")
+
+	phase := quality.WorkflowPhase(phaseFlag)
+	if !slices.Contains([]quality.WorkflowPhase{quality.PhasePlan, quality.PhaseRun, quality.PhaseSync}, phase) {
+		return fmt.Errorf("invalid phase %q: use plan, run or sync", phaseFlag)
+	}
+	if !slices.Contains([]string{qualityFormatText, qualityFormatJSON, qualityFormatSARIF, qualityFormatJUnit}, format) {
+		return fmt.Errorf("invalid format %q: use text, json, sarif or junit", format)
+	}
+	cmd.SilenceUsage = true
+
+	root, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	mgr := config.NewConfigManager()
+	cfg, err := mgr.Load(root)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	explicitBaseline := baselinePath != ""
+	if !explicitBaseline {
+		baselinePath = filepath.Join(root, defs.MoAIDir, defs.MemorySubdir, trustBaselineFile)
+	}
+	var baseline *quality.DiagnosticSnapshot
+	if phase != quality.PhasePlan {
+		baseline, err = loadTrustBaseline(baselinePath)
+		if err != nil && (explicitBaseline || !errors.Is(err, fs.ErrNotExist)) {
+			return err
+		}
+	}
+
+	ctx := cmd.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	input, err := collectQualityInput(ctx, root, mgr)
+	if err != nil {
+		return err
+	}
+	measured, err := measureCoverage(ctx, root, cfg.Quality.CoverageExemptions, reports, baseRef)
+	if err != nil {
+		return err
+	}
+	if measured != nil {
+		percent := measured.Project.Percent()
+		input.coverage = &percent
+		input.sources = append(input.sources, fmt.Sprintf("coverage report (%s)", measured.Format))
+	}
+
+	var mutationReport *mutation.Report
+	var mutationStale bool
+	qualityCfg := quality.ConfigFromModel(cfg.Quality)
+	if qualityCfg.TDDSettings.MutationTestingEnabled {
+		if mutationReport, mutationStale, err = storedMutationReport(root); err != nil {
+			return err
+		}
+		if mutationReport != nil && !mutationStale {
+			input.sources = append(input.sources, "mutation report")
+		}
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if deps != nil && deps.Logger != nil {
+		logger = deps.Logger
+	}
+	diagTracked := baseline != nil || phase == quality.PhasePlan
+	if _, err := os.Stat(filepath.Join(root, defs.MoAIDir, defs.MemorySubdir, lsphook.BaselineFileName)); err == nil {
+		diagTracked = true
+	}
+	gitManager := &qualityGit{root: root, baseline: baseline}
+	client := gateClient(input.diagnostics)
+
+	var gate *quality.TrustGate
+	factory := func(qc quality.QualityConfig) quality.Gate {
+		coverageTarget, coverage := qc.TestCoverageTarget, 0
+		if input.coverage == nil {
+			coverageTarget = 0
+		} else {
+			coverage = int(*input.coverage)
+		}
+		var testedOpts []quality.TestedOption
+		if mutationReport != nil && !mutationStale {
+			testedOpts = append(testedOpts, quality.WithMutationScore(mutationReport.Score, qc.TDDSettings.MutationScoreThreshold))
+		}
+		opts := []quality.TrustGateOption{
+			quality.WithPhase(phase),
+			quality.WithLSPClient(client),
+			quality.WithLogger(logger),
+		}
+		if baseline != nil {
+			opts = append(opts, quality.WithBaseline(baseline))
+		}
+		if mCtx := coverageMethodology(qc, measured); mCtx != nil {
+			opts = append(opts, quality.WithMethodologyContext(mCtx))
+		}
+		gate = quality.NewTrustGate(qc, []quality.Validator{
+			quality.NewTestedValidator(client, coverageTarget, coverage, testedOpts...),
+			quality.NewReadableValidator(client),
+			quality.NewUnderstandableValidator(client, qc.LSPGates.Sync.MaxWarnings,
+				input.analysis.DocComplete(), input.analysis.ComplexityOK()),
+			quality.NewSecuredValidator(client),
+			quality.NewTrackableValidator(gitManager, input.analysis.StructuredLogging, diagTracked),
+		}, opts...)
+		return gate
+	}
+	validator, err := quality.NewWorktreeValidator(factory, qualityCfg, logger)
+	if err != nil {
+		return err
+	}
+	report, err := validator.Validate(ctx, root)
+	if err != nil {
+		return fmt.Errorf("quality check: %w", err)
+	}
+
+	if phase == quality.PhasePlan {
+		if err := saveTrustBaseline(baselinePath, gate.Baseline()); err != nil {
+			return err
+		}
+	}
+
+	summary := qualitySummary{
+		Files:          len(input.files),
+		Coverage:       input.coverage,
+		CoverageTarget: cfg.Quality.TestCoverageTarget,
+		Measured:       measured,
+		Mutation:       mutationReport,
+		MutationStale:  mutationStale,
+		Sources:        input.sources,
+		Analysis:       input.analysis,
+	}
+	if phase == quality.PhasePlan {
+		summary.BaselineWritten = hook.DisplayPath(root, baselinePath)
+	}
+	if err := writeQualityReport(cmd.OutOrStdout(), format, report, summary); err != nil {
+		return err
+	}
+	if !report.Passed {
+		return fmt.Errorf("TRUST 5 quality gate failed (score %.2f)", report.Score)
+	}
+	return nil
+}
+
+// collectQualityInput gathers diagnostics, coverage and source analysis
+// for the source files of root.
+func collectQualityInput(ctx context.Context, root string, cfg hook.ConfigProvider) (*qualityInput, error) {
+	files, err := qualitySourceFiles(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	input := &qualityInput{files: files}
+
+	diagnostics, coverage := hook.NewStopQualityGate(cfg).RunChecks(ctx, root, files)
+	input.diagnostics = diagnostics
+	input.coverage = coverage
+	input.sources = append(input.sources, "build/test/lint commands")
+
+	if lspDiags, ok := daemonQualityDiagnostics(ctx, root, files); ok {
+		input.diagnostics = appendUniqueDiagnostics(input.diagnostics, lspDiags)
+		input.sources = append(input.sources, "LSP daemon")
+	}
+	if findings, ok := securityQualityDiagnostics(ctx, root, files); ok {
+		input.diagnostics = append(input.diagnostics, findings...)
+		input.sources = append(input.sources, "ast-grep")
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("collect diagnostics: %w", ctx.Err())
+	}
+
+	input.analysis = quality.AnalyzeSource(root, files, quality.DefaultMaxComplexity)
+	return input, nil
+}
+
+// measureCoverage parses the coverage reports, or those found at their
+// default locations when none are given, and analyzes them against
+// baseRef. It returns nil when no report is found and none was required.
+func measureCoverage(ctx context.Context, root string, exemptions models.CoverageExemptions, reports []string, baseRef string) (*coverage.Result, error) {
+	if len(reports) == 0 {
+		reports = coverage.FindReports(root)
+		if len(reports) == 0 {
+			if baseRef != "" {
+				return nil, errors.New("--base-ref needs a coverage report: none found, pass --coverage")
+			}
+			return nil, nil
+		}
+	}
+
+	var profile *coverage.Profile
+	for _, report := range reports {
+		if !filepath.IsAbs(report) {
+			report = filepath.Join(root, report)
+		}
+		p, err := coverage.ParseFile(report, root)
+		if err != nil {
+			return nil, err
+		}
+		if profile == nil {
+			profile = p
+		} else {
+			profile.Merge(p)
+		}
+	}
+	result, err := coverage.Analyze(ctx, profile, coverage.Options{
+		Root:    root,
+		BaseRef: baseRef,
+		Exemptions: coverage.Policy{
+			Enabled:              exemptions.Enabled,
+			RequireJustification: exemptions.RequireJustification,
+			MaxExemptPercentage:  exemptions.MaxExemptPercentage,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("analyze coverage: %w", err)
+	}
+	return result, nil
+}
+
+// coverageMethodology returns the methodology context of the measured
+// coverage for the modes whose rules it answers: hybrid, and TDD unless
+// test-first evidence is required, which a coverage report cannot give.
+// DDD rules need characterization and snapshot evidence and get none.
+func coverageMethodology(qc quality.QualityConfig, measured *coverage.Result) *quality.MethodologyContext {
+	if measured == nil {
+		return nil
+	}
+	switch {
+	case qc.DevelopmentMode == quality.ModeHybrid:
+		return measured.MethodologyContext()
+	case qc.DevelopmentMode == quality.ModeTDD && !qc.TDDSettings.RequireTestFirst:
+		return measured.MethodologyContext()
+	}
+	return nil
+}
+
+// qualitySourceFiles returns the source files of root, relative and
+// slash-separated: the files git tracks or does not ignore, or all files
+// outside hidden and vendored directories when root is not a repository.
+// Only files of a known language are returned.
+func qualitySourceFiles(ctx context.Context, root string) ([]string, error) {
+	var candidates []string
+	out, err := exec.CommandContext(ctx, "git", "-C", root, "ls-files", "--cached", "--others", "--exclude-standard", "-z").Output()
+	if err == nil {
+		for _, f := range strings.Split(string(out), "\x00") {
+			if f != "" {
+				candidates = append(candidates, f)
+			}
+		}
+	} else {
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				name := d.Name()
+				if path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			candidates = append(candidates, hook.DisplayPath(root, path))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list source files: %w", err)
+		}
+	}
+
+	var files []string
+	for _, f := range candidates {
+		if strings.HasPrefix(f, defs.MoAIDir+"/") {
+			continue
+		}
+		if _, err := foundation.DefaultRegistry.ByExtension(filepath.Ext(f)); err != nil {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(root, filepath.FromSlash(f))); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// daemonQualityDiagnostics returns the diagnostics of the LSP daemon for
+// files, or false when no daemon serves root.
+func daemonQualityDiagnostics(ctx context.Context, root string, files []string) ([]quality.Diagnostic, bool) {
+	client, err := lsp.DialDaemon(ctx, root)
+	if err != nil {
+		return nil, false
+	}
+	_ = client.Close()
+
+	collector := lsphook.NewDiagnosticsCollector(lsp.NewDaemonDiagnosticsProvider(func(string) string { return root }), nil)
+	var diagnostics []quality.Diagnostic
+	for _, rel := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		diags, err := collector.GetDiagnostics(ctx, filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			continue
+		}
+		for _, d := range diags {
+			diagnostics = append(diagnostics, convertQualityDiagnostic(rel, d))
+		}
+	}
+	return diagnostics, true
+}
+
+// convertQualityDiagnostic maps an LSP diagnostic of file rel onto the
+// sources the TRUST 5 validators understand: findings of linters are
+// lint, other errors are type errors, other warnings are general.
+func convertQualityDiagnostic(rel string, d lsphook.Diagnostic) quality.Diagnostic {
+	qd := quality.Diagnostic{
+		File:    rel,
+		Line:    d.Range.Start.Line + 1,
+		Message: d.Message,
+		Code:    d.Code,
+	}
+	switch d.Severity {
+	case lsphook.SeverityError:
+		qd.Severity = quality.SeverityError
+	case lsphook.SeverityWarning:
+		qd.Severity = quality.SeverityWarning
+	case lsphook.SeverityHint:
+		qd.Severity = quality.SeverityHint
+	default:
+		qd.Severity = quality.SeverityInfo
+	}
+	switch {
+	case slices.Contains(lintTools, d.Source):
+		qd.Source = "lint"
+	case qd.Severity == quality.SeverityError:
+		qd.Source = "typecheck"
+	}
+	return qd
+}
+
+// appendUniqueDiagnostics appends the diagnostics of extra that do not
+// repeat one of diagnostics at the same location.
+func appendUniqueDiagnostics(diagnostics, extra []quality.Diagnostic) []quality.Diagnostic {
+	seen := make(map[string]bool, len(diagnostics))
+	key := func(d quality.Diagnostic) string {
+		return fmt.Sprintf("%s:%d:%s", d.File, d.Line, d.Message)
+	}
+	for _, d := range diagnostics {
+		seen[key(d)] = true
+	}
+	for _, d := range extra {
+		if !seen[key(d)] {
+			seen[key(d)] = true
+			diagnostics = append(diagnostics, d)
+		}
+	}
+	return diagnostics
+}
+
+// securityQualityDiagnostics scans files with ast-grep and returns the
+// error and warning findings, or false when ast-grep is not installed.
+func securityQualityDiagnostics(ctx context.Context, root string, files []string) ([]quality.Diagnostic, bool) {
+	scanner := security.NewSecurityScannerWithConfig(&security.ScannerConfig{ProjectDir: root})
+	if !scanner.IsAvailable() {
+		return nil, false
+	}
+
+	var diagnostics []quality.Diagnostic
+	for start := 0; start < len(files); start += securityScanBatch {
+		batch := files[start:min(start+securityScanBatch, len(files))]
+		paths := make([]string, len(batch))
+		for i, rel := range batch {
+			paths[i] = filepath.Join(root, filepath.FromSlash(rel))
+		}
+		results, err := scanner.ScanFiles(ctx, paths, root)
+		if err != nil {
+			slog.Debug("quality check: security scan failed", "error", err)
+		}
+		for i, result := range results {
+			if result == nil {
+				continue
+			}
+			for _, f := range result.Findings {
+				if f.Severity != security.SeverityError && f.Severity != security.SeverityWarning {
+					continue
+				}
+				file := batch[i]
+				if f.File != "" {
+					path := f.File
+					if !filepath.IsAbs(path) {
+						path = filepath.Join(root, path)
+					}
+					file = hook.DisplayPath(root, path)
+				}
+				diagnostics = append(diagnostics, quality.Diagnostic{
+					File:     file,
+					Line:     f.Line,
+					Severity: string(f.Severity),
+					Message:  f.Message,
+					Source:   "security",
+					Code:     f.RuleID,
+				})
+			}
+		}
+	}
+	return diagnostics, true
+}
+
+// gateClient serves collected diagnostics to the TRUST 5 validators.
+type gateClient []quality.Diagnostic
+
+// CollectDiagnostics returns the collected diagnostics.
+func (c gateClient) CollectDiagnostics(context.Context) ([]quality.Diagnostic, error) {
+	return c, nil
+}
+
+// qualityGit answers the Trackable principle from the repository at root.
+type qualityGit struct {
+	root     string
+	baseline *quality.DiagnosticSnapshot
+}
+
+// LastCommitMessage returns the subject of HEAD, or "" outside a
+// repository or before the first commit.
+func (g *qualityGit) LastCommitMessage(context.Context) (string, error) {
+	repo, err := git.NewRepository(g.root)
+	if err != nil {
+		return "", nil
+	}
+	commits, err := repo.Log(1)
+	if err != nil || len(commits) == 0 {
+		return "", nil
+	}
+	return commits[0].Message, nil
+}
+
+// DiagnosticHistory returns the stored baseline, if any.
+func (g *qualityGit) DiagnosticHistory(context.Context) ([]quality.DiagnosticSnapshot, error) {
+	if g.baseline == nil {
+		return nil, nil
+	}
+	return []quality.DiagnosticSnapshot{*g.baseline}, nil
+}
+
+// loadTrustBaseline reads a diagnostic baseline written by the plan phase.
+func loadTrustBaseline(path string) (*quality.DiagnosticSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+	var snapshot quality.DiagnosticSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("parse baseline %s: %w", path, err)
+	}
+	return &snapshot, nil
+}
+
+// saveTrustBaseline writes snapshot to path, creating its directory.
+func saveTrustBaseline(path string, snapshot *quality.DiagnosticSnapshot) error {
+	if snapshot == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal baseline: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), defs.DirPerm); err != nil {
+		return fmt.Errorf("create baseline directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, defs.FilePerm); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/core/mutation"
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/hook"
+)
+
+// mutationReportFile is the last mutation testing report, kept in
+// .moai/memory for "moai quality check".
+const mutationReportFile = "mutation-report.json"
+
+func newQualityMutateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "mutate [packages]",
+		Short: "Run mutation testing on Go packages",
+		Long: `Run mutation testing on the Go packages of the project (default ./...).
+
+Each mutant flips a condition, moves a comparison boundary, negates a
+boolean return or drops a statement. Only the tests whose coverage
+reaches the mutated line run against it, in parallel, with the mutated
+file supplied through a go build overlay; the working tree is never
+modified.
+
+The mutation score is the share of mutants that made a test fail. It is
+saved to .moai/memory/mutation-report.json with a hash of the Go sources,
+where 'moai quality check' reads it for the Tested principle when mutation
+testing is enabled in quality.yaml. A report whose sources have changed
+since is stale and ignored. The command fails when the score is below
+tdd_settings.mutation_score_threshold or --threshold.
+
+Example:
+  moai quality mutate ./internal/... --workers 4`,
+		RunE: runQualityMutate,
+	}
+	cmd.Flags().Int("workers", mutation.NewRunner("").Workers, "Mutants tested in parallel")
+	cmd.Flags().String("format", qualityFormatText, "Report format: text or json")
+	cmd.Flags().Int("threshold", -1, "Minimum mutation score (default from quality.yaml)")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "Maximum time for the whole run")
+	cmd.Flags().Duration("mutant-timeout", 0, "Maximum test time per mutant (default derived from test durations)")
+	return cmd
+}
+
+func runQualityMutate(cmd *cobra.Command, args []string) error {
+	workers, _ := cmd.Flags().GetInt("workers")
+	format, _ := cmd.Flags().GetString("format")
+	threshold, _ := cmd.Flags().GetInt("threshold")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	mutantTimeout, _ := cmd.Flags().GetDuration("mutant-timeout")
+
+	if !slices.Contains([]string{qualityFormatText, qualityFormatJSON}, format) {
+		return fmt.Errorf("invalid format %q: use text or json", format)
+	}
+	if workers < 1 {
+		return fmt.Errorf("invalid workers %d: must be at least 1", workers)
+	}
+	cmd.SilenceUsage = true
+
+	root, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	if threshold < 0 {
+		cfg, err := config.NewConfigManager().Load(root)
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		threshold = cfg.Quality.TDDSettings.MutationScoreThreshold
+	}
+
+	ctx := cmd.Context()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	runner := mutation.NewRunner(root)
+	runner.Workers = workers
+	runner.MutantTimeout = mutantTimeout
+	if deps != nil && deps.Logger != nil {
+		runner.Logger = deps.Logger
+	}
+	report, err := runner.Run(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	reportPath := filepath.Join(root, defs.MoAIDir, defs.MemorySubdir, mutationReportFile)
+	if err := saveMutationReport(reportPath, report); err != nil {
+		return err
+	}
+	if format == qualityFormatJSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		writeMutationText(cmd.OutOrStdout(), report, threshold, hook.DisplayPath(root, reportPath))
+	}
+	if report.Score < float64(threshold) {
+		return fmt.Errorf("mutation score %.1f%% is below threshold %d%%", report.Score, threshold)
+	}
+	return nil
+}
+
+// writeMutationText renders report as a card followed by the surviving
+// mutants and the tests that fail without mutation.
+func writeMutationText(w io.Writer, report *mutation.Report, threshold int, reportPath string) {
+	verdict := "PASSED"
+	if report.Score < float64(threshold) {
+		verdict = "FAILED"
+	}
+	pairs := []kvPair{
+		{"Verdict", verdict},
+		{"Score", fmt.Sprintf("%.1f%% (threshold %d%%)", report.Score, threshold)},
+		{"Mutants", fmt.Sprintf("%d", len(report.Mutants))},
+		{"Killed", fmt.Sprintf("%d (+%d timed out)", report.Killed, report.TimedOut)},
+		{"Survived", fmt.Sprintf("%d (+%d not covered)", report.Survived, report.NoCoverage)},
+		{"Not viable", fmt.Sprintf("%d", report.CompileErrors)},
+		{"Duration", report.Duration.Round(time.Second).String()},
+		{"Report", reportPath},
+	}
+
+	var b strings.Builder
+	b.WriteString(renderCard("Mutation Testing", renderKeyValueLines(pairs)))
+	b.WriteString("\n\n")
+	var surviving []string
+	for _, m := range report.Surviving() {
+		line := fmt.Sprintf("%s [%s]", m, m.Operator)
+		if m.Status == mutation.StatusNoCoverage {
+			line += " (not covered)"
+		}
+		surviving = append(surviving, line)
+	}
+	writeQualityDetails(&b, "Surviving mutants", surviving)
+	writeQualityDetails(&b, "Tests failing without mutation (not used)", report.FailingTests)
+	_, _ = fmt.Fprint(w, b.String())
+}
+
+// loadMutationReport reads the report saved by "moai quality mutate".
+func loadMutationReport(path string) (*mutation.Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read mutation report: %w", err)
+	}
+	var report mutation.Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse mutation report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// saveMutationReport writes report to path, creating its directory.
+func saveMutationReport(path string, report *mutation.Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal mutation report: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), defs.DirPerm); err != nil {
+		return fmt.Errorf("create report directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, defs.FilePerm); err != nil {
+		return fmt.Errorf("write mutation report: %w", err)
+	}
+	return nil
+}
+
+// storedMutationReport returns the saved mutation report of root, or nil
+// when mutation testing has not been run. stale reports whether the Go
+// sources of root changed since the report was generated; a report saved
+// without a source hash is stale.
+func storedMutationReport(root string) (report *mutation.Report, stale bool, err error) {
+	report, err = loadMutationReport(filepath.Join(root, defs.MoAIDir, defs.MemorySubdir, mutationReportFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	hash, err := mutation.SourceHash(root)
+	if err != nil {
+		return nil, false, err
+	}
+	return report, report.SourceHash != hash, nil
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/core/mutation"
+)
+
+// executeQualityMutate runs "moai quality mutate" with args and returns
+// its output and error.
+func executeQualityMutate(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newQualityMutateCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func TestQualityCmd_MutateSubcommand(t *testing.T) {
+	for _, sub := range qualityCmd.Commands() {
+		if sub.Name() == "mutate" {
+			return
+		}
+	}
+	t.Error("quality command missing \"mutate\" subcommand")
+}
+
+func TestQualityMutate_InvalidFlags(t *testing.T) {
+	chdirLSPProject(t)
+
+	for _, args := range [][]string{{"--format", "sarif"}, {"--workers", "0"}} {
+		if _, err := executeQualityMutate(t, args...); err == nil {
+			t.Errorf("args %v: want error", args)
+		}
+	}
+}
+
+func TestQualityMutate_Run(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test on a temporary module")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	root := chdirLSPProject(t)
+	writeQualityFile(t, root, "go.mod", "module example.com/calc\n\ngo 1.22\n")
+	writeQualityFile(t, root, "calc.go", "package calc\n\nfunc Max(a, b int) int {\n\tif a > b {\n\t\treturn a\n\t}\n\treturn b\n}\n")
+	writeQualityFile(t, root, "calc_test.go", `package calc
+
+import "testing"
+
+func TestMax(t *testing.T) {
+	if Max(3, 1) != 3 {
+		t.Fatal("wrong max")
+	}
+}
+`)
+
+	out, err := executeQualityMutate(t, "--threshold", "40")
+	if err != nil {
+		t.Fatalf("mutate error = %v\n%s", err, out)
+	}
+	for _, want := range []string{"Mutation Testing", "PASSED", "50.0% (threshold 40%)", "Surviving mutants (1):", "calc.go:4:7: > -> >="} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	report, stale, err := storedMutationReport(root)
+	if err != nil || report == nil || len(report.Mutants) != 2 || stale {
+		t.Fatalf("stored report = %+v, stale %v, %v; want 2 mutants, current", report, stale, err)
+	}
+	writeQualityFile(t, root, "calc.go", "package calc\n\nfunc Max(a, b int) int {\n\tif a >= b {\n\t\treturn a\n\t}\n\treturn b\n}\n")
+	if _, stale, err := storedMutationReport(root); err != nil || !stale {
+		t.Errorf("stale = %v, %v; want stale after a source edit", stale, err)
+	}
+	writeQualityFile(t, root, "calc.go", "package calc\n\nfunc Max(a, b int) int {\n\tif a > b {\n\t\treturn a\n\t}\n\treturn b\n}\n")
+
+	if _, err := executeQualityMutate(t, "--threshold", "60", "--format", "json"); err == nil ||
+		!strings.Contains(err.Error(), "below threshold 60%") {
+		t.Errorf("error = %v, want the score below the threshold", err)
+	}
+}
+
+func TestQualityCheck_MutationScore(t *testing.T) {
+	root := chdirQualityProject(t, "true")
+	writeQualityFile(t, root, ".moai/config/sections/quality.yaml", `constitution:
+  test_coverage_target: 85
+  tdd_settings:
+    mutation_score_threshold: 70
+  test_quality:
+    mutation_testing_enabled: true
+  stop_gate:
+    build_command: "true"
+    test_command: "echo coverage: 90.0% of statements"
+    lint_command: "true"
+`)
+	hash, err := mutation.SourceHash(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &mutation.Report{
+		Mutants:     make([]mutation.Mutant, 5),
+		Score:       40,
+		GeneratedAt: time.Date(2026, 10, 1, 9, 30, 0, 0, time.UTC),
+		SourceHash:  hash,
+	}
+	reportPath := filepath.Join(root, ".moai", "memory", mutationReportFile)
+	if err := saveMutationReport(reportPath, report); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := executeQualityCheck(t)
+	if err == nil {
+		t.Fatalf("want the mutation score to fail the gate\n%s", out)
+	}
+	for _, want := range []string{"mutation report", "40.0% of 5 mutants killed (2026-10-01 09:30)", "✗ tested", "[mutation-score]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// A report of other sources is ignored by the gate.
+	report.SourceHash = "old"
+	if err := saveMutationReport(reportPath, report); err != nil {
+		t.Fatal(err)
+	}
+	out, err = executeQualityCheck(t)
+	if err != nil {
+		t.Fatalf("stale mutation report should not fail the gate: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "stale, sources changed since 2026-10-01 09:30") || strings.Contains(out, "[mutation-score]") {
+		t.Errorf("output should flag the stale report without judging it:\n%s", out)
+	}
+}
@@ -0,0 +1,423 @@
+package cli
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/modu-ai/moai-adk/internal/core/coverage"
+	"github.com/modu-ai/moai-adk/internal/core/mutation"
+	"github.com/modu-ai/moai-adk/internal/core/quality"
+	"github.com/modu-ai/moai-adk/pkg/version"
+)
+
+// maxQualityDetails limits the source analysis findings listed in the
+// text report.
+const maxQualityDetails = 10
+
+// qualitySummary describes the data a report was computed from.
+type qualitySummary struct {
+	Files           int
+	Coverage        *float64
+	CoverageTarget  int
+	Measured        *coverage.Result
+	Mutation        *mutation.Report
+	MutationStale   bool
+	Sources         []string
+	Analysis        *quality.SourceAnalysis
+	BaselineWritten string
+}
+
+// writeQualityReport renders report in format to w.
+func writeQualityReport(w io.Writer, format string, report *quality.Report, summary qualitySummary) error {
+	switch format {
+	case qualityFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case qualityFormatSARIF:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(newSARIFLog(report))
+	case qualityFormatJUnit:
+		data, err := xml.MarshalIndent(newJUnitSuites(report), "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal junit report: %w", err)
+		}
+		_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+		return err
+	default:
+		_, err := fmt.Fprint(w, renderQualityText(report, summary))
+		return err
+	}
+}
+
+// renderQualityText renders report as a card followed by the principles
+// and their issues.
+func renderQualityText(report *quality.Report, summary qualitySummary) string {
+	verdict := "PASSED"
+	if !report.Passed {
+		verdict = "FAILED"
+	}
+	coverage := "not measured"
+	if summary.Coverage != nil {
+		coverage = fmt.Sprintf("%.1f%%", *summary.Coverage)
+	}
+	pairs := []kvPair{
+		{"Verdict", verdict},
+		{"Score", fmt.Sprintf("%.2f", report.Score)},
+		{"Phase", report.Phase},
+		{"Mode", report.DevelopmentMode},
+		{"Files", fmt.Sprintf("%d", summary.Files)},
+		{"Coverage", coverage},
+		{"Sources", strings.Join(summary.Sources, ", ")},
+	}
+	if m := summary.Measured; m != nil {
+		pairs = append(pairs, measuredCoveragePairs(m)...)
+	}
+	if m := summary.Mutation; m != nil && summary.MutationStale {
+		pairs = append(pairs, kvPair{"Mutation", fmt.Sprintf("stale, sources changed since %s (run 'moai quality mutate')",
+			m.GeneratedAt.Format("2006-01-02 15:04"))})
+	} else if m != nil {
+		pairs = append(pairs, kvPair{"Mutation", fmt.Sprintf("%.1f%% of %d mutants killed (%s)",
+			m.Score, len(m.Mutants), m.GeneratedAt.Format("2006-01-02 15:04"))})
+	}
+	if summary.BaselineWritten != "" {
+		pairs = append(pairs, kvPair{"Baseline", summary.BaselineWritten})
+	}
+
+	var b strings.Builder
+	b.WriteString(renderCard("TRUST 5 Quality Gate", renderKeyValueLines(pairs)))
+	b.WriteString("\n\n")
+	for _, name := range quality.ValidPrinciples {
+		pr := report.Principles[name]
+		mark := "✓"
+		if !pr.Passed {
+			mark = "✗"
+		}
+		fmt.Fprintf(&b, "%s %-15s %.2f\n", mark, name, pr.Score)
+		for _, issue := range pr.Issues {
+			fmt.Fprintf(&b, "    %s\n", formatQualityIssue(issue))
+		}
+	}
+	for _, section := range []struct {
+		title  string
+		issues []quality.Issue
+	}{
+		{"Phase", report.PhaseIssues},
+		{"Regression", report.RegressionIssues},
+		{"Methodology", report.MethodologyIssues},
+	} {
+		if len(section.issues) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:\n", section.title)
+		for _, issue := range section.issues {
+			fmt.Fprintf(&b, "    %s\n", formatQualityIssue(issue))
+		}
+	}
+	if summary.Analysis != nil {
+		writeQualityDetails(&b, "Undocumented exports", summary.Analysis.Undocumented)
+		writeQualityDetails(&b, fmt.Sprintf("Functions above complexity %d", quality.DefaultMaxComplexity), summary.Analysis.Complex)
+	}
+	if m := summary.Measured; m != nil {
+		var below []string
+		for _, pkg := range m.Packages {
+			if pkg.WholePercent() < summary.CoverageTarget {
+				below = append(below, fmt.Sprintf("%s: %.1f%% (%d/%d lines)", pkg.Name, pkg.Percent(), pkg.Covered, pkg.Total))
+			}
+		}
+		writeQualityDetails(&b, fmt.Sprintf("Packages below %d%% coverage", summary.CoverageTarget), below)
+		writeQualityDetails(&b, "Unjustified coverage exemptions", m.Exemptions.Unjustified)
+	}
+	return b.String()
+}
+
+// measuredCoveragePairs describes the new-code, legacy and exempt lines
+// of a measured coverage report.
+func measuredCoveragePairs(m *coverage.Result) []kvPair {
+	var pairs []kvPair
+	if m.NewCode != nil {
+		pairs = append(pairs,
+			kvPair{"New code", fmt.Sprintf("%.1f%% of %d changed lines since %s", m.NewCode.Percent(), m.NewCode.Total, m.Base)},
+			kvPair{"Legacy", fmt.Sprintf("%.1f%% of %d modified files", m.Legacy.Percent(), len(m.Changes.ModifiedFiles))},
+		)
+	}
+	switch {
+	case m.Exemptions.Rejected:
+		pairs = append(pairs, kvPair{"Exempt", fmt.Sprintf("%.1f%% of lines, above the maximum: not applied", m.Exemptions.Percent)})
+	case m.Exemptions.Lines > 0:
+		pairs = append(pairs, kvPair{"Exempt", fmt.Sprintf("%d lines (%.1f%%)", m.Exemptions.Lines, m.Exemptions.Percent)})
+	}
+	return pairs
+}
+
+// writeQualityDetails lists up to maxQualityDetails source analysis findings.
+func writeQualityDetails(b *strings.Builder, title string, details []string) {
+	if len(details) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s (%d):\n", title, len(details))
+	for i, d := range details {
+		if i == maxQualityDetails {
+			fmt.Fprintf(b, "    ... and %d more\n", len(details)-maxQualityDetails)
+			break
+		}
+		fmt.Fprintf(b, "    %s\n", d)
+	}
+}
+
+// formatQualityIssue renders an issue as "severity file:line: message [rule]".
+func formatQualityIssue(issue quality.Issue) string {
+	var b strings.Builder
+	b.WriteString(issue.Severity)
+	switch {
+	case issue.File != "" && issue.Line > 0:
+		fmt.Fprintf(&b, " %s:%d:", issue.File, issue.Line)
+	case issue.File != "":
+		fmt.Fprintf(&b, " %s:", issue.File)
+	}
+	b.WriteString(" " + issue.Message)
+	if issue.Rule != "" {
+		fmt.Fprintf(&b, " [%s]", issue.Rule)
+	}
+	return b.String()
+}
+
+// qualityIssue is an issue with the principle or report section it
+// belongs to.
+type qualityIssue struct {
+	category string
+	issue    quality.Issue
+}
+
+// qualityIssues returns the issues of report in a stable order: the
+// principles, then the phase, regression and methodology issues.
+func qualityIssues(report *quality.Report) []qualityIssue {
+	var issues []qualityIssue
+	for _, name := range quality.ValidPrinciples {
+		for _, issue := range report.Principles[name].Issues {
+			issues = append(issues, qualityIssue{name, issue})
+		}
+	}
+	for _, issue := range report.PhaseIssues {
+		issues = append(issues, qualityIssue{"phase", issue})
+	}
+	for _, issue := range report.RegressionIssues {
+		issues = append(issues, qualityIssue{"regression", issue})
+	}
+	for _, issue := range report.MethodologyIssues {
+		issues = append(issues, qualityIssue{"methodology", issue})
+	}
+	return issues
+}
+
+// ruleID returns the SARIF rule of an issue: its rule, or its category
+// when the validator did not name one.
+func (q qualityIssue) ruleID() string {
+	if q.issue.Rule != "" {
+		return q.issue.Rule
+	}
+	return q.category
+}
+
+// SARIF 2.1.0 subset written by "moai quality check --format sarif".
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool       sarifTool      `json:"tool"`
+		Results    []sarifResult  `json:"results"`
+		Properties map[string]any `json:"properties,omitempty"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		Version        string      `json:"version,omitempty"`
+		InformationURI string      `json:"informationUri,omitempty"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+	sarifResult struct {
+		RuleID     string          `json:"ruleId"`
+		Level      string          `json:"level"`
+		Message    sarifMessage    `json:"message"`
+		Locations  []sarifLocation `json:"locations,omitempty"`
+		Properties map[string]any  `json:"properties,omitempty"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+	}
+)
+
+// newSARIFLog converts report into a SARIF log with one result per issue.
+func newSARIFLog(report *quality.Report) sarifLog {
+	driver := sarifDriver{
+		Name:           "moai-quality",
+		Version:        version.GetVersion(),
+		InformationURI: "https://github.com/modu-ai/moai-adk",
+		Rules:          []sarifRule{},
+	}
+	results := []sarifResult{}
+	for _, q := range qualityIssues(report) {
+		id := q.ruleID()
+		if !slices.ContainsFunc(driver.Rules, func(r sarifRule) bool { return r.ID == id }) {
+			driver.Rules = append(driver.Rules, sarifRule{
+				ID:               id,
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("TRUST 5 %s check", q.category)},
+			})
+		}
+		result := sarifResult{
+			RuleID:     id,
+			Level:      sarifLevel(q.issue.Severity),
+			Message:    sarifMessage{Text: q.issue.Message},
+			Properties: map[string]any{"category": q.category},
+		}
+		if q.issue.File != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: q.issue.File},
+			}}
+			if q.issue.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: q.issue.Line}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		results = append(results, result)
+	}
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: driver},
+			Results: results,
+			Properties: map[string]any{
+				"passed": report.Passed,
+				"score":  report.Score,
+				"phase":  report.Phase,
+			},
+		}},
+	}
+}
+
+// sarifLevel maps an issue severity to a SARIF result level.
+func sarifLevel(severity string) string {
+	switch severity {
+	case quality.SeverityError:
+		return "error"
+	case quality.SeverityWarning:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// JUnit XML written by "moai quality check --format junit": one test case
+// per principle and report section, failed by error issues, and one for
+// the overall verdict.
+type (
+	junitSuites struct {
+		XMLName  xml.Name     `xml:"testsuites"`
+		Name     string       `xml:"name,attr"`
+		Tests    int          `xml:"tests,attr"`
+		Failures int          `xml:"failures,attr"`
+		Suites   []junitSuite `xml:"testsuite"`
+	}
+	junitSuite struct {
+		Name     string      `xml:"name,attr"`
+		Tests    int         `xml:"tests,attr"`
+		Failures int         `xml:"failures,attr"`
+		Cases    []junitCase `xml:"testcase"`
+	}
+	junitCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+		SystemOut string        `xml:"system-out,omitempty"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+// newJUnitSuites converts report into JUnit test suites.
+func newJUnitSuites(report *quality.Report) junitSuites {
+	byCategory := make(map[string][]quality.Issue)
+	categories := slices.Clone(quality.ValidPrinciples)
+	for _, q := range qualityIssues(report) {
+		if !slices.Contains(categories, q.category) {
+			categories = append(categories, q.category)
+		}
+		byCategory[q.category] = append(byCategory[q.category], q.issue)
+	}
+
+	suite := junitSuite{Name: "trust5." + report.Phase}
+	for _, category := range categories {
+		tc := junitCase{Name: category, ClassName: "trust5"}
+		var errs, others []string
+		for _, issue := range byCategory[category] {
+			if issue.Severity == quality.SeverityError {
+				errs = append(errs, formatQualityIssue(issue))
+			} else {
+				others = append(others, formatQualityIssue(issue))
+			}
+		}
+		if len(errs) > 0 {
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d error(s)", len(errs)),
+				Type:    category,
+				Text:    strings.Join(errs, "\n"),
+			}
+		}
+		tc.SystemOut = strings.Join(others, "\n")
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	verdict := junitCase{Name: "verdict", ClassName: "trust5"}
+	if !report.Passed {
+		verdict.Failure = &junitFailure{
+			Message: fmt.Sprintf("score %.2f", report.Score),
+			Type:    "verdict",
+			Text:    "TRUST 5 quality gate failed",
+		}
+	}
+	suite.Cases = append(suite.Cases, verdict)
+
+	suite.Tests = len(suite.Cases)
+	for _, tc := range suite.Cases {
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+	}
+	return junitSuites{
+		Name:     "TRUST 5",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitSuite{suite},
+	}
+}
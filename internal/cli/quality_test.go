@@ -0,0 +1,319 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/core/quality"
+	lsphook "github.com/modu-ai/moai-adk/internal/lsp/hook"
+)
+
+// qualityCalcSource is a documented Go file using structured logging.
+const qualityCalcSource = `package calc
+
+import "log/slog"
+
+// Add returns a + b.
+func Add(a, b int) int {
+	slog.Debug("add", "a", a, "b", b)
+	return a + b
+}
+`
+
+// chdirQualityProject changes into a git-tracked MoAI project whose
+// quality.stop_gate commands are stand-ins: the test command reports 90%
+// coverage and the lint command is lintCommand. It returns the root.
+func chdirQualityProject(t *testing.T, lintCommand string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := chdirLSPProject(t)
+	writeQualityFile(t, root, "calc/calc.go", qualityCalcSource)
+	writeQualityFile(t, root, ".moai/config/sections/quality.yaml", `constitution:
+  test_coverage_target: 85
+  stop_gate:
+    build_command: "true"
+    test_command: "echo coverage: 90.0% of statements"
+    lint_command: "`+lintCommand+`"
+`)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "feat(calc): add calculator"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return root
+}
+
+func writeQualityFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// executeQualityCheck runs "moai quality check" with args and returns its
+// output and error.
+func executeQualityCheck(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newQualityCheckCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func TestQualityCmd_Subcommands(t *testing.T) {
+	found := false
+	for _, sub := range qualityCmd.Commands() {
+		if sub.Name() == "check" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("quality command missing \"check\" subcommand")
+	}
+}
+
+func TestQualityCheck_PlanThenRun(t *testing.T) {
+	root := chdirQualityProject(t, "true")
+
+	out, err := executeQualityCheck(t, "--phase", "plan")
+	if err != nil {
+		t.Fatalf("plan error = %v\n%s", err, out)
+	}
+	for _, want := range []string{"PASSED", "90.0%", "build/test/lint commands", ".moai/memory/trust-baseline.json"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("plan output missing %q:\n%s", want, out)
+		}
+	}
+	baseline, err := loadTrustBaseline(filepath.Join(root, ".moai", "memory", trustBaselineFile))
+	if err != nil {
+		t.Fatalf("plan did not write the baseline: %v", err)
+	}
+	if baseline.Errors != 0 || baseline.Timestamp.IsZero() {
+		t.Errorf("baseline = %+v, want a clean snapshot", baseline)
+	}
+
+	out, err = executeQualityCheck(t, "--format", "json")
+	if err != nil {
+		t.Fatalf("run error = %v\n%s", err, out)
+	}
+	var report quality.Report
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("json output: %v\n%s", err, out)
+	}
+	if !report.Passed || report.Phase != "run" || len(report.Principles) != 5 {
+		t.Errorf("report = %+v, want a passing run report with 5 principles", report)
+	}
+	if pr := report.Principles[quality.PrincipleTrackable]; !pr.Passed {
+		t.Errorf("trackable = %+v, want passed with a conventional commit, slog and a baseline", pr)
+	}
+}
+
+func TestQualityCheck_FailsOnLintErrors(t *testing.T) {
+	chdirQualityProject(t, "false")
+
+	out, err := executeQualityCheck(t, "--phase", "run")
+	if err == nil || !strings.Contains(err.Error(), "quality gate failed") {
+		t.Fatalf("error = %v, want a failed gate\n%s", err, out)
+	}
+	for _, want := range []string{"FAILED", "✗ readable", "phase-run-lint-errors"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestQualityCheck_MissingExplicitBaseline(t *testing.T) {
+	chdirQualityProject(t, "true")
+
+	if _, err := executeQualityCheck(t, "--baseline", "missing.json"); err == nil {
+		t.Error("want error for a missing --baseline file")
+	}
+}
+
+func TestQualityCheck_InvalidFlags(t *testing.T) {
+	chdirLSPProject(t)
+
+	for _, args := range [][]string{{"--phase", "deploy"}, {"--format", "html"}} {
+		if _, err := executeQualityCheck(t, args...); err == nil {
+			t.Errorf("args %v: want error", args)
+		}
+	}
+}
+
+func TestQualityCheck_CoverageReport(t *testing.T) {
+	root := chdirQualityProject(t, "true")
+	writeQualityFile(t, root, ".moai/config/sections/quality.yaml", `constitution:
+  development_mode: hybrid
+  test_coverage_target: 60
+  hybrid_settings:
+    min_coverage_new: 90
+  stop_gate:
+    build_command: "true"
+    test_command: "true"
+    lint_command: "true"
+`)
+	writeQualityFile(t, root, "calc/sub.go", `package calc
+
+// Sub returns a - b.
+func Sub(a, b int) int {
+	return a - b
+}
+`)
+	writeQualityFile(t, root, "coverage.out", `mode: set
+calc/calc.go:6.24,9.2 2 1
+calc/sub.go:4.24,6.2 1 0
+`)
+
+	out, err := executeQualityCheck(t, "--base-ref", "HEAD")
+	if err == nil {
+		t.Fatalf("want the hybrid new-code rule to fail the gate\n%s", out)
+	}
+	for _, want := range []string{
+		"57.1%", "coverage report (go)", "0.0% of 3 changed lines since HEAD",
+		"hybrid-new-coverage", "Packages below 60% coverage (1):", "calc: 57.1% (4/7 lines)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := executeQualityCheck(t, "--coverage", "missing.out"); err == nil {
+		t.Error("want error for a missing --coverage report")
+	}
+}
+
+// testQualityReport returns a failing report with a located lint issue,
+// an unlocated coverage issue and a phase issue.
+func testQualityReport() *quality.Report {
+	report := &quality.Report{
+		Passed:     false,
+		Score:      0.62,
+		Phase:      "run",
+		Principles: map[string]quality.PrincipleResult{},
+		PhaseIssues: []quality.Issue{
+			{Severity: quality.SeverityError, Message: "run phase requires zero lint errors, found 1", Rule: "phase-run-lint-errors"},
+		},
+	}
+	for _, name := range quality.ValidPrinciples {
+		report.Principles[name] = quality.PrincipleResult{Name: name, Passed: true, Score: 1}
+	}
+	report.Principles[quality.PrincipleReadable] = quality.PrincipleResult{
+		Name: quality.PrincipleReadable, Score: 0.9,
+		Issues: []quality.Issue{{File: "calc/calc.go", Line: 3, Severity: quality.SeverityError, Message: "unused variable", Rule: "lint"}},
+	}
+	report.Principles[quality.PrincipleTested] = quality.PrincipleResult{
+		Name: quality.PrincipleTested, Score: 0.9,
+		Issues: []quality.Issue{{Severity: quality.SeverityWarning, Message: "test coverage 70% is below target 85%"}},
+	}
+	return report
+}
+
+func TestWriteQualityReport_SARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeQualityReport(&buf, qualityFormatSARIF, testQualityReport(), qualitySummary{}); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("sarif output: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("log = %+v, want one SARIF 2.1.0 run", log)
+	}
+	run := log.Runs[0]
+	if len(run.Results) != 3 || len(run.Tool.Driver.Rules) != 3 {
+		t.Fatalf("results/rules = %d/%d, want 3/3", len(run.Results), len(run.Tool.Driver.Rules))
+	}
+	tested, lint := run.Results[0], run.Results[1]
+	if tested.RuleID != quality.PrincipleTested || tested.Level != "warning" || tested.Locations != nil {
+		t.Errorf("tested result = %+v, want an unlocated warning named after the principle", tested)
+	}
+	loc := lint.Locations[0].PhysicalLocation
+	if lint.Level != "error" || loc.ArtifactLocation.URI != "calc/calc.go" || loc.Region.StartLine != 3 {
+		t.Errorf("lint result = %+v, want an error at calc/calc.go:3", lint)
+	}
+}
+
+func TestWriteQualityReport_JUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeQualityReport(&buf, qualityFormatJUnit, testQualityReport(), qualitySummary{}); err != nil {
+		t.Fatal(err)
+	}
+	var suites junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("junit output: %v\n%s", err, buf.String())
+	}
+	// 5 principles, the phase section and the verdict; readable, phase
+	// and the verdict fail, the coverage warning does not.
+	if suites.Tests != 7 || suites.Failures != 3 {
+		t.Errorf("tests/failures = %d/%d, want 7/3\n%s", suites.Tests, suites.Failures, buf.String())
+	}
+	for _, tc := range suites.Suites[0].Cases {
+		if tc.Name == quality.PrincipleTested && (tc.Failure != nil || !strings.Contains(tc.SystemOut, "coverage 70%")) {
+			t.Errorf("tested case = %+v, want the warning as output", tc)
+		}
+	}
+}
+
+func TestWriteQualityReport_Text(t *testing.T) {
+	var buf bytes.Buffer
+	summary := qualitySummary{
+		Files:    2,
+		Sources:  []string{"build/test/lint commands"},
+		Analysis: &quality.SourceAnalysis{Undocumented: []string{"calc/calc.go:8: Sub"}},
+	}
+	if err := writeQualityReport(&buf, qualityFormatText, testQualityReport(), summary); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"FAILED", "not measured", "✓ secured", "✗ readable",
+		"error calc/calc.go:3: unused variable [lint]",
+		"Phase:", "Undocumented exports (1):", "calc/calc.go:8: Sub",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestConvertQualityDiagnostic(t *testing.T) {
+	tests := []struct {
+		diag         lsphook.Diagnostic
+		wantSource   string
+		wantSeverity string
+	}{
+		{lsphook.Diagnostic{Severity: lsphook.SeverityError, Source: "gopls"}, "typecheck", quality.SeverityError},
+		{lsphook.Diagnostic{Severity: lsphook.SeverityWarning, Source: "ruff"}, "lint", quality.SeverityWarning},
+		{lsphook.Diagnostic{Severity: lsphook.SeverityWarning, Source: "pyright"}, "", quality.SeverityWarning},
+		{lsphook.Diagnostic{Severity: lsphook.SeverityInformation, Source: "gopls"}, "", quality.SeverityInfo},
+	}
+	for _, tt := range tests {
+		tt.diag.Range.Start.Line = 4
+		got := convertQualityDiagnostic("a.go", tt.diag)
+		if got.Source != tt.wantSource || got.Severity != tt.wantSeverity || got.Line != 5 || got.File != "a.go" {
+			t.Errorf("convert(%s %s) = %+v, want source %q severity %q at a.go:5",
+				tt.diag.Source, tt.diag.Severity, got, tt.wantSource, tt.wantSeverity)
+		}
+	}
+}
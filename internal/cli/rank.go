@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -35,6 +36,7 @@ func init() {
 		newRankStatusCmd(),
 		newRankLogoutCmd(),
 		newRankSyncCmd(),
+		newRankQueueCmd(),
 		newRankExcludeCmd(),
 		newRankIncludeCmd(),
 		newRankRegisterCmd(),
@@ -207,6 +209,12 @@ func newRankSyncCmd() *cobra.Command {
 			_, _ = fmt.Fprintln(out, "Syncing metrics to MoAI Cloud...")
 			_, _ = fmt.Fprintf(out, "Device: %s (%s)\n", deviceInfo.HostName, deviceInfo.DeviceID)
 
+			// Submit the sessions queued by SessionEnd hooks first; their
+			// transcripts are then skipped as synced
+			if outbox, err := rank.NewOutbox(""); err == nil && outbox.Len() > 0 {
+				flushRankOutbox(cmd.Context(), out, outbox, syncState)
+			}
+
 			// Find all transcript files
 			transcripts, err := rank.FindTranscripts()
 			if err != nil {
@@ -339,6 +347,85 @@ func newRankSyncCmd() *cobra.Command {
 	return cmd
 }
 
+// flushRankOutbox submits the sessions queued in outbox and reports the
+// outcome. Submitted transcripts are marked in syncState when it is set.
+func flushRankOutbox(ctx context.Context, out io.Writer, outbox *rank.Outbox, syncState *rank.SyncState) {
+	ctx, cancel := context.WithTimeout(ctx, 120*time.Second)
+	defer cancel()
+
+	var opts []rank.FlushOption
+	if syncState != nil {
+		opts = append(opts, rank.WithSyncState(syncState))
+	}
+	result, err := outbox.Flush(ctx, deps.RankClient, opts...)
+	if result != nil {
+		_, _ = fmt.Fprintf(out, "Submitted %d queued session(s), %d still pending\n", result.Submitted, result.Pending)
+		if result.Rejected > 0 {
+			_, _ = fmt.Fprintf(out, "  Rejected: %d invalid session(s) removed from the queue\n", result.Rejected)
+		}
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(out, "Warning: queued sessions not submitted: %v\n", err)
+	}
+}
+
+func newRankQueueCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Show or purge sessions waiting for submission",
+		Long: `Show the sessions queued in ~/.moai/rank/outbox. SessionEnd hooks queue
+every session before submitting it, and sessions that could not be submitted
+stay queued until the next SessionStart or 'moai rank sync'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := cmd.OutOrStdout()
+			purge, _ := cmd.Flags().GetBool("purge")
+
+			outbox, err := rank.NewOutbox("")
+			if err != nil {
+				return fmt.Errorf("open rank outbox: %w", err)
+			}
+
+			if purge {
+				removed, err := outbox.Purge()
+				if err != nil {
+					return fmt.Errorf("purge rank outbox: %w", err)
+				}
+				_, _ = fmt.Fprintln(out, renderSuccessCard(fmt.Sprintf("Removed %d queued session(s)", removed)))
+				return nil
+			}
+
+			items, err := outbox.List()
+			if err != nil {
+				return fmt.Errorf("read rank outbox: %w", err)
+			}
+			if len(items) == 0 {
+				_, _ = fmt.Fprintln(out, "No sessions waiting for submission.")
+				return nil
+			}
+
+			lines := make([]string, 0, len(items))
+			for _, item := range items {
+				s := item.Session
+				line := fmt.Sprintf("%s  %s  %d in / %d out  attempts: %d",
+					s.SessionHash[:min(12, len(s.SessionHash))], s.EndedAt, s.InputTokens, s.OutputTokens, item.Attempts)
+				if s.ModelName != "" {
+					line += "  " + s.ModelName
+				}
+				if item.LastError != "" {
+					line += "\n  last error: " + item.LastError
+				}
+				lines = append(lines, line)
+			}
+			_, _ = fmt.Fprintln(out, renderCard(fmt.Sprintf("Rank Queue (%d)", len(items)), strings.Join(lines, "\n")))
+			_, _ = fmt.Fprintln(out, "Submit them with 'moai rank sync' or remove them with 'moai rank queue --purge'.")
+			return nil
+		},
+	}
+	cmd.Flags().Bool("purge", false, "Remove all queued sessions without submitting them")
+	return cmd
+}
+
 func newRankExcludeCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:    "exclude [pattern]",
@@ -1,8 +1,13 @@
 package cli
 
 import (
+	"bytes"
+	"context"
 	"strings"
 	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/rank"
+	"github.com/spf13/cobra"
 )
 
 func TestRankCmd_Exists(t *testing.T) {
@@ -37,7 +42,7 @@ func TestRankCmd_IsSubcommandOfRoot(t *testing.T) {
 }
 
 func TestRankCmd_HasSubcommands(t *testing.T) {
-	expected := []string{"login", "status", "logout", "sync", "exclude", "include", "register"}
+	expected := []string{"login", "status", "logout", "sync", "queue", "exclude", "include", "register"}
 	for _, name := range expected {
 		found := false
 		for _, cmd := range rankCmd.Commands() {
@@ -54,8 +59,8 @@ func TestRankCmd_HasSubcommands(t *testing.T) {
 
 func TestRankCmd_SubcommandCount(t *testing.T) {
 	count := len(rankCmd.Commands())
-	if count != 7 {
-		t.Errorf("rank should have 7 subcommands, got %d", count)
+	if count != 8 {
+		t.Errorf("rank should have 8 subcommands, got %d", count)
 	}
 }
 
@@ -79,3 +84,94 @@ func TestRankCmd_HelpOutput(t *testing.T) {
 		t.Errorf("rank subcommands should include login and logout, got: %s", joined)
 	}
 }
+
+// rankSubcommand returns the rank subcommand called name.
+func rankSubcommand(t *testing.T, name string) *cobra.Command {
+	t.Helper()
+	for _, cmd := range rankCmd.Commands() {
+		if cmd.Name() == name {
+			return cmd
+		}
+	}
+	t.Fatalf("rank %s subcommand not found", name)
+	return nil
+}
+
+func TestRankQueue_ListAndPurge(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	outbox, err := rank.NewOutbox("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := outbox.Enqueue(&rank.SessionSubmission{EndedAt: "2026-01-01T10:00:00Z", InputTokens: 1200, OutputTokens: 300, ModelName: "claude-sonnet-4"}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := rankSubcommand(t, "queue")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("rank queue: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"Rank Queue (1)", "2026-01-01T10:00:00Z", "1200 in / 300 out", "claude-sonnet-4"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q, got %q", want, output)
+		}
+	}
+
+	buf.Reset()
+	if err := cmd.Flags().Set("purge", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = cmd.Flags().Set("purge", "false") }()
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("rank queue --purge: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Removed 1 queued session") {
+		t.Errorf("unexpected purge output %q", buf.String())
+	}
+	if outbox.Len() != 0 {
+		t.Error("purge should empty the outbox")
+	}
+}
+
+func TestRankSync_FlushesOutbox(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	origDeps := deps
+	defer func() { deps = origDeps }()
+
+	var submitted []*rank.SessionSubmission
+	deps = &Dependencies{
+		RankClient: &mockRankClient{
+			submitBatchFunc: func(_ context.Context, sessions []*rank.SessionSubmission) (*rank.BatchResult, error) {
+				submitted = append(submitted, sessions...)
+				return &rank.BatchResult{Success: true, Processed: len(sessions), Succeeded: len(sessions)}, nil
+			},
+		},
+	}
+
+	outbox, err := rank.NewOutbox("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := outbox.Enqueue(&rank.SessionSubmission{EndedAt: "2026-01-01T10:00:00Z", InputTokens: 10}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := rankSubcommand(t, "sync")
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetContext(context.Background())
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("rank sync: %v", err)
+	}
+
+	if len(submitted) != 1 || outbox.Len() != 0 {
+		t.Errorf("submitted %d sessions, %d left queued", len(submitted), outbox.Len())
+	}
+	if !strings.Contains(buf.String(), "Submitted 1 queued session(s), 0 still pending") {
+		t.Errorf("unexpected sync output %q", buf.String())
+	}
+}
@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/rank"
+)
+
+// Output formats of "moai usage".
+const (
+	usageFormatTable = "table"
+	usageFormatJSON  = "json"
+	usageFormatCSV   = "csv"
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Report local token usage and estimated cost",
+	Long: `Report token usage and estimated cost from the Claude Code transcripts on
+this machine. Nothing is sent anywhere and no MoAI Rank account is needed.
+
+Sessions are grouped by project, day, model or session. Projects excluded
+with 'moai rank exclude', or not matched by 'moai rank include' patterns
+when there are any, are left out. Costs are estimates from the built-in
+model pricing; sessions of models without pricing count as $0.
+
+Examples:
+  moai usage
+  moai usage --since 30d --by day
+  moai usage --since 2026-01-01 --by model --format csv`,
+	Args: cobra.NoArgs,
+	RunE: runUsage,
+}
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+
+	usageCmd.Flags().String("since", "7d", "Only sessions ended within this period (e.g. 7d, 2w, 12h) or since a date (YYYY-MM-DD); 'all' for every session")
+	usageCmd.Flags().String("by", string(rank.UsageByProject), "Group by project, day, model or session")
+	usageCmd.Flags().String("format", usageFormatTable, "Output format: table, json or csv")
+}
+
+func runUsage(cmd *cobra.Command, _ []string) error {
+	out := cmd.OutOrStdout()
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	byFlag, _ := cmd.Flags().GetString("by")
+	format, _ := cmd.Flags().GetString("format")
+
+	by := rank.UsageGrouping(byFlag)
+	if !slices.Contains(rank.UsageGroupings, by) {
+		return fmt.Errorf("invalid --by %q: use project, day, model or session", byFlag)
+	}
+	if format != usageFormatTable && format != usageFormatJSON && format != usageFormatCSV {
+		return fmt.Errorf("invalid --format %q: use table, json or csv", format)
+	}
+	since, err := parseUsageSince(sinceFlag, time.Now())
+	if err != nil {
+		return err
+	}
+
+	store, err := rank.NewPatternStore("")
+	if err != nil {
+		return fmt.Errorf("create pattern store: %w", err)
+	}
+	sessions, err := collectSessionUsage(since, store)
+	if err != nil {
+		return err
+	}
+
+	report := rank.AggregateUsage(sessions, by)
+	switch format {
+	case usageFormatJSON:
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case usageFormatCSV:
+		return writeUsageCSV(out, report)
+	default:
+		_, _ = fmt.Fprintln(out, renderUsageTable(report, since))
+		return nil
+	}
+}
+
+// parseUsageSince returns the start of the reporting period described by
+// value relative to now. The zero time means no lower bound.
+func parseUsageSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "all" {
+		return time.Time{}, nil
+	}
+	if date, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return date, nil
+	}
+
+	days := map[byte]int{'d': 1, 'w': 7}
+	if n := len(value); n > 1 {
+		if perUnit, ok := days[value[n-1]]; ok {
+			count, err := strconv.Atoi(value[:n-1])
+			if err == nil && count > 0 {
+				return now.AddDate(0, 0, -count*perUnit), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a period like 7d, 2w or 12h, a date like 2026-01-02, or all", value)
+}
+
+// collectSessionUsage parses the transcripts of sessions ended since the
+// given time whose project the rank patterns allow.
+func collectSessionUsage(since time.Time, store *rank.PatternStore) ([]*rank.SessionUsage, error) {
+	transcripts, err := rank.FindTranscripts()
+	if err != nil {
+		return nil, fmt.Errorf("find transcripts: %w", err)
+	}
+
+	var sessions []*rank.SessionUsage
+	for _, transcriptPath := range transcripts {
+		// A transcript is written up to its last message, so one last
+		// modified before the period cannot contain sessions in it
+		info, err := os.Stat(transcriptPath)
+		if err != nil || info.ModTime().Before(since) {
+			continue
+		}
+
+		usage, err := rank.ParseTranscript(transcriptPath)
+		if err != nil || (usage.InputTokens == 0 && usage.OutputTokens == 0) {
+			continue
+		}
+
+		session := rank.NewSessionUsage(transcriptPath, usage)
+		if session.EndedAt.IsZero() {
+			session.EndedAt = info.ModTime()
+		}
+		if session.EndedAt.Before(since) || !store.Allows(session.ProjectPath) {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// renderUsageTable renders report as a table inside a card.
+func renderUsageTable(report *rank.UsageReport, since time.Time) string {
+	period := "all sessions"
+	if !since.IsZero() {
+		period = "since " + since.Format("2006-01-02 15:04")
+	}
+	title := fmt.Sprintf("Usage by %s (%s)", report.By, period)
+	if len(report.Groups) == 0 {
+		return renderCard(title, "No sessions found.")
+	}
+
+	header := []string{strings.ToUpper(string(report.By)), "SESSIONS", "INPUT", "OUTPUT", "CACHE W", "CACHE R", "HIT", "COST", "AVG TIME"}
+	rows := [][]string{header}
+	for _, g := range report.Groups {
+		rows = append(rows, usageRow(g))
+	}
+	rows = append(rows, usageRow(report.Total))
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	var lines []string
+	for i, row := range rows {
+		if i == len(rows)-1 {
+			lines = append(lines, cliMuted.Render(strings.Repeat("─", sumWidths(widths))))
+		}
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			if j == 0 {
+				cells[j] = fmt.Sprintf("%-*s", widths[j], cell)
+			} else {
+				cells[j] = fmt.Sprintf("%*s", widths[j], cell)
+			}
+		}
+		line := strings.Join(cells, "  ")
+		if i == 0 {
+			line = cliMuted.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if unpriced := report.Total.UnpricedSessions; unpriced > 0 {
+		lines = append(lines, "", cliMuted.Render(fmt.Sprintf("%d session(s) use models without pricing and count as $0.", unpriced)))
+	}
+	return renderCard(title, strings.Join(lines, "\n"))
+}
+
+// sumWidths returns the width of a table row with the given column widths.
+func sumWidths(widths []int) int {
+	total := 2 * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+	return total
+}
+
+// usageRow returns the table cells of a usage group.
+func usageRow(g *rank.UsageGroup) []string {
+	return []string{
+		g.Key,
+		strconv.Itoa(g.Sessions),
+		formatUsageTokens(g.InputTokens),
+		formatUsageTokens(g.OutputTokens),
+		formatUsageTokens(g.CacheCreationTokens),
+		formatUsageTokens(g.CacheReadTokens),
+		fmt.Sprintf("%.0f%%", g.CacheHitRatio*100),
+		fmt.Sprintf("$%.2f", g.CostUSD),
+		(time.Duration(g.AvgDurationSeconds) * time.Second).String(),
+	}
+}
+
+// formatUsageTokens formats a token count with a K or M suffix.
+func formatUsageTokens(tokens int64) string {
+	switch {
+	case tokens >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(tokens)/1_000_000)
+	case tokens >= 1_000:
+		return fmt.Sprintf("%.1fK", float64(tokens)/1_000)
+	default:
+		return strconv.FormatInt(tokens, 10)
+	}
+}
+
+// writeUsageCSV writes report as CSV with exact token counts, one row per
+// group followed by the total.
+func writeUsageCSV(w io.Writer, report *rank.UsageReport) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{
+		string(report.By), "sessions", "input_tokens", "output_tokens", "cache_creation_tokens",
+		"cache_read_tokens", "cache_hit_ratio", "cost_usd", "duration_seconds", "avg_duration_seconds",
+	})
+	for _, g := range slices.Concat(report.Groups, []*rank.UsageGroup{report.Total}) {
+		_ = cw.Write([]string{
+			g.Key,
+			strconv.Itoa(g.Sessions),
+			strconv.FormatInt(g.InputTokens, 10),
+			strconv.FormatInt(g.OutputTokens, 10),
+			strconv.FormatInt(g.CacheCreationTokens, 10),
+			strconv.FormatInt(g.CacheReadTokens, 10),
+			strconv.FormatFloat(g.CacheHitRatio, 'f', 4, 64),
+			strconv.FormatFloat(g.CostUSD, 'f', 4, 64),
+			strconv.FormatInt(g.DurationSeconds, 10),
+			strconv.FormatInt(g.AvgDurationSeconds, 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
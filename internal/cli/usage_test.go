@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseUsageSince(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"7d", now.AddDate(0, 0, -7), false},
+		{"2w", now.AddDate(0, 0, -14), false},
+		{"12h", now.Add(-12 * time.Hour), false},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), false},
+		{"all", time.Time{}, false},
+		{"", time.Time{}, false},
+		{"0d", time.Time{}, true},
+		{"-1h", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseUsageSince(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseUsageSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseUsageSince(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeUsageTranscript writes a Claude Code transcript of a session in
+// project that ended at end.
+func writeUsageTranscript(t *testing.T, home, name, project string, end time.Time, input int) {
+	t.Helper()
+	dir := filepath.Join(home, ".claude", "projects", strings.ReplaceAll(project, "/", "-"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	start := end.Add(-10 * time.Minute)
+	content := fmt.Sprintf(`{"timestamp":%q,"type":"user","cwd":%q,"message":{}}
+{"timestamp":%q,"type":"assistant","message":{"model":"claude-sonnet-4-20250514","usage":{"input_tokens":%d,"output_tokens":100,"cache_read_input_tokens":%d}}}
+`, start.UTC().Format(time.RFC3339), project, end.UTC().Format(time.RFC3339), input, input)
+	path := filepath.Join(dir, name+".jsonl")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, end, end); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUsageCmd_ReportsFilteredProjects(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	now := time.Now()
+	writeUsageTranscript(t, home, "s1", "/work/app", now.Add(-time.Hour), 1000)
+	writeUsageTranscript(t, home, "s2", "/work/app", now.Add(-48*time.Hour), 3000)
+	writeUsageTranscript(t, home, "s3", "/work/secret", now.Add(-time.Hour), 500)
+	writeUsageTranscript(t, home, "s4", "/work/old", now.AddDate(0, 0, -30), 700)
+
+	configDir := filepath.Join(home, ".moai", "config")
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "rank.yaml"), []byte("exclude_patterns:\n  - /work/secret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	usageCmd.SetOut(buf)
+	defer usageCmd.SetOut(nil)
+	if err := usageCmd.Flags().Set("format", "csv"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = usageCmd.Flags().Set("format", usageFormatTable) }()
+
+	if err := usageCmd.RunE(usageCmd, nil); err != nil {
+		t.Fatalf("usage: %v", err)
+	}
+
+	records, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v\n%s", err, buf.String())
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected header, one project and total, got %v", records)
+	}
+	if got := records[1][:3]; got[0] != "/work/app" || got[1] != "2" || got[2] != "4000" {
+		t.Errorf("project row = %v", records[1])
+	}
+	if records[2][0] != "total" || records[2][6] != "0.5000" {
+		t.Errorf("total row = %v", records[2])
+	}
+}
+
+func TestUsageCmd_RendersTable(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	writeUsageTranscript(t, home, "s1", "/work/app", time.Now().Add(-time.Hour), 1500)
+
+	buf := new(bytes.Buffer)
+	usageCmd.SetOut(buf)
+	defer usageCmd.SetOut(nil)
+	if err := usageCmd.Flags().Set("by", "model"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = usageCmd.Flags().Set("by", "project") }()
+
+	if err := usageCmd.RunE(usageCmd, nil); err != nil {
+		t.Fatalf("usage: %v", err)
+	}
+	output := buf.String()
+	for _, want := range []string{"Usage by model", "claude-sonnet-4-20250514", "1.5K", "50%", "10m0s", "total"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestUsageCmd_RejectsInvalidFlags(t *testing.T) {
+	for flag, value := range map[string]string{"by": "week", "format": "xml", "since": "soon"} {
+		t.Run(flag, func(t *testing.T) {
+			orig, _ := usageCmd.Flags().GetString(flag)
+			if err := usageCmd.Flags().Set(flag, value); err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = usageCmd.Flags().Set(flag, orig) }()
+			if err := usageCmd.RunE(usageCmd, nil); err == nil {
+				t.Errorf("--%s %s should fail", flag, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/core/git"
+	"github.com/modu-ai/moai-adk/internal/core/quality"
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/hook"
+	"github.com/modu-ai/moai-adk/internal/workflow"
+)
+
+var workflowCmd = &cobra.Command{
+	Use:   "workflow",
+	Short: "Run the Plan-Run-Sync workflow",
+	Long: `Run the MoAI Plan-Run-Sync workflow headless with Claude Code inside
+the worktree of a SPEC.`,
+}
+
+func init() {
+	rootCmd.AddCommand(workflowCmd)
+	workflowCmd.AddCommand(newWorkflowRunCmd())
+}
+
+func newWorkflowRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run <SPEC-ID>",
+		Short: "Run plan, run and sync for a SPEC in its worktree",
+		Long: `Run "/moai plan", "/moai run" and "/moai sync" for a SPEC with
+'claude -p' inside its worktree (.moai/worktrees/<SPEC-ID>, created with
+'moai worktree new <SPEC-ID>'), validating the TRUST 5 quality gates
+after the run phase.
+
+The stream-json output of each phase is written to
+.moai/logs/workflow/<SPEC-ID>-<phase>.log. A phase is stopped when it
+exceeds its token budget (workflow.plan_tokens, run_tokens and
+sync_tokens) or --timeout, and the following phases are skipped.
+
+Example:
+  moai workflow run SPEC-ISSUE-123 --claude-arg=--permission-mode --claude-arg=acceptEdits`,
+		Args: cobra.ExactArgs(1),
+		RunE: runWorkflowRun,
+	}
+	cmd.Flags().Duration("timeout", time.Hour, "Maximum time for each phase (0 for no limit)")
+	cmd.Flags().StringArray("claude-arg", nil, "Extra argument passed to claude (repeatable)")
+	return cmd
+}
+
+func runWorkflowRun(cmd *cobra.Command, args []string) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	claudeArgs, _ := cmd.Flags().GetStringArray("claude-arg")
+	specID := args[0]
+
+	if timeout < 0 {
+		return fmt.Errorf("invalid timeout %s: must not be negative", timeout)
+	}
+	if err := workflow.ValidateSpecID(specID); err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	root, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	mgr := config.NewConfigManager()
+	cfg, err := mgr.Load(root)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if deps != nil && deps.Logger != nil {
+		logger = deps.Logger
+	}
+	executor := workflow.NewClaudeExecutor(
+		filepath.Join(root, defs.MoAIDir, defs.LogsSubdir, workflow.LogSubdir),
+		workflow.TokenBudget{
+			Plan: cfg.Workflow.PlanTokens,
+			Run:  cfg.Workflow.RunTokens,
+			Sync: cfg.Workflow.SyncTokens,
+		},
+		workflow.WithPhaseTimeout(timeout),
+		workflow.WithClaudeArgs(claudeArgs...),
+		workflow.WithExecutorLogger(logger),
+	)
+	validator := &worktreeQuality{
+		cfg:    mgr,
+		qc:     quality.ConfigFromModel(cfg.Quality),
+		logger: logger,
+	}
+	orchestrator, err := workflow.NewWorktreeOrchestrator(git.NewWorktreeManager(root), validator, executor, logger)
+	if err != nil {
+		return err
+	}
+
+	result, runErr := orchestrator.ExecuteWorkflow(cmd.Context(), specID)
+	if result != nil {
+		writeWorkflowResult(cmd.OutOrStdout(), root, result, executor.Runs())
+	}
+	if errors.Is(runErr, workflow.ErrNotInWorktree) {
+		return fmt.Errorf("%w (create it with 'moai worktree new %s')", runErr, specID)
+	}
+	return runErr
+}
+
+// writeWorkflowResult renders the phase statuses of result, with the
+// tokens and log of each claude run, and the quality verdict.
+func writeWorkflowResult(w io.Writer, root string, result *workflow.WorkflowResult, runs []workflow.PhaseResult) {
+	byPhase := make(map[workflow.Phase]workflow.PhaseResult, len(runs))
+	for _, run := range runs {
+		byPhase[run.Phase] = run
+	}
+	phaseLine := func(phase workflow.Phase, status workflow.WorkflowPhaseStatus) string {
+		run, ok := byPhase[phase]
+		if !ok {
+			return string(status)
+		}
+		tokens := fmt.Sprintf("%d tokens", run.Tokens)
+		if run.Budget > 0 {
+			tokens = fmt.Sprintf("%d/%d tokens", run.Tokens, run.Budget)
+		}
+		return fmt.Sprintf("%s  %s  %s  %s", status, tokens,
+			run.CompletedAt.Sub(run.StartedAt).Round(time.Second), hook.DisplayPath(root, run.LogPath))
+	}
+
+	pairs := []kvPair{
+		{"SPEC", result.SpecID},
+		{"Plan", phaseLine(workflow.PhasePlan, result.PlanStatus)},
+		{"Run", phaseLine(workflow.PhaseRun, result.RunStatus)},
+		{"Sync", phaseLine(workflow.PhaseSync, result.SyncStatus)},
+	}
+	if report := result.QualityReport; report != nil {
+		verdict := "PASSED"
+		if !report.Passed {
+			verdict = "FAILED"
+		}
+		pairs = append(pairs, kvPair{"Quality", fmt.Sprintf("%s (score %.2f)", verdict, report.Score)})
+	}
+	if !result.CompletedAt.IsZero() {
+		pairs = append(pairs, kvPair{"Duration", result.CompletedAt.Sub(result.StartedAt).Round(time.Second).String()})
+	}
+	_, _ = fmt.Fprintln(w, renderCard("Workflow", renderKeyValueLines(pairs)))
+}
+
+// worktreeQuality validates a worktree with the TRUST 5 gates fed by the
+// build, test and lint commands run in that worktree.
+type worktreeQuality struct {
+	cfg    hook.ConfigProvider
+	qc     quality.QualityConfig
+	logger *slog.Logger
+}
+
+// Compile-time interface compliance check.
+var _ quality.WorktreeValidator = (*worktreeQuality)(nil)
+
+// Validate runs the gates with the project quality configuration.
+func (v *worktreeQuality) Validate(ctx context.Context, wtPath string) (*quality.Report, error) {
+	return v.ValidateWithConfig(ctx, wtPath, v.qc)
+}
+
+// ValidateWithConfig collects the quality input of wtPath and runs the
+// gates with qc.
+func (v *worktreeQuality) ValidateWithConfig(ctx context.Context, wtPath string, qc quality.QualityConfig) (*quality.Report, error) {
+	input, err := collectQualityInput(ctx, wtPath, v.cfg)
+	if err != nil {
+		return nil, err
+	}
+	validator, err := quality.NewWorktreeValidator(worktreeGateFactory(wtPath, input), qc, v.logger)
+	if err != nil {
+		return nil, err
+	}
+	return validator.ValidateWithConfig(ctx, wtPath, qc)
+}
+
+// worktreeGateFactory builds run-phase TRUST 5 gates from the quality
+// input collected in wtPath.
+func worktreeGateFactory(wtPath string, input *qualityInput) quality.GateFactory {
+	return func(qc quality.QualityConfig) quality.Gate {
+		coverageTarget, coverage := qc.TestCoverageTarget, 0
+		if input.coverage == nil {
+			coverageTarget = 0
+		} else {
+			coverage = int(*input.coverage)
+		}
+		client := gateClient(input.diagnostics)
+		return quality.NewTrustGate(qc, []quality.Validator{
+			quality.NewTestedValidator(client, coverageTarget, coverage),
+			quality.NewReadableValidator(client),
+			quality.NewUnderstandableValidator(client, qc.LSPGates.Sync.MaxWarnings,
+				input.analysis.DocComplete(), input.analysis.ComplexityOK()),
+			quality.NewSecuredValidator(client),
+			quality.NewTrackableValidator(&qualityGit{root: wtPath}, input.analysis.StructuredLogging, false),
+		}, quality.WithPhase(quality.PhaseRun), quality.WithLSPClient(client))
+	}
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/workflow"
+)
+
+// fakeWorkflowClaude stands in for claude: it prints one assistant event
+// and a result, or fails when the prompt names FAKE_CLAUDE_FAIL.
+const fakeWorkflowClaude = `#!/bin/sh
+echo '{"type":"assistant","message":{"id":"m1","usage":{"input_tokens":1200,"output_tokens":300}}}'
+case "$2" in
+"/moai $FAKE_CLAUDE_FAIL "*)
+	echo "phase failed" >&2
+	exit 1 ;;
+esac
+echo '{"type":"result","subtype":"success","is_error":false}'
+`
+
+// chdirWorkflowProject creates a quality project with a worktree for
+// specID and a fake claude on PATH failing the phase named fail.
+func chdirWorkflowProject(t *testing.T, specID, fail string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake claude is a shell script")
+	}
+	root := chdirQualityProject(t, "true")
+	writeQualityFile(t, root, ".moai/config/sections/workflow.yaml", "workflow:\n  plan_tokens: 2000\n  run_tokens: 5000\n")
+	if specID != "" {
+		wtPath := filepath.Join(root, ".moai", "worktrees", specID)
+		if out, err := exec.Command("git", "-C", root, "worktree", "add", "-q", "-b", "feature/"+specID, wtPath).CombinedOutput(); err != nil {
+			t.Fatalf("git worktree add: %v\n%s", err, out)
+		}
+	}
+
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte(fakeWorkflowClaude), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_CLAUDE_FAIL", fail)
+	return root
+}
+
+// executeWorkflowRun runs "moai workflow run" with args and returns its
+// output and error.
+func executeWorkflowRun(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newWorkflowRunCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func TestWorkflowCmd_RunSubcommand(t *testing.T) {
+	for _, sub := range workflowCmd.Commands() {
+		if sub.Name() == "run" {
+			return
+		}
+	}
+	t.Error("workflow command missing \"run\" subcommand")
+}
+
+func TestWorkflowRun_InvalidArgs(t *testing.T) {
+	chdirLSPProject(t)
+
+	for _, args := range [][]string{{"SPEC-AUTH-001"}, {"SPEC-ISSUE-1", "--timeout", "-1s"}} {
+		if _, err := executeWorkflowRun(t, args...); err == nil {
+			t.Errorf("args %v: want error", args)
+		}
+	}
+}
+
+func TestWorkflowRun_AllPhases(t *testing.T) {
+	root := chdirWorkflowProject(t, "SPEC-ISSUE-7", "")
+
+	out, err := executeWorkflowRun(t, "SPEC-ISSUE-7")
+	if err != nil {
+		t.Fatalf("workflow run error = %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"Workflow", "SPEC-ISSUE-7",
+		"completed  1500/2000 tokens",
+		"completed  1500/5000 tokens",
+		"completed  1500/40000 tokens",
+		".moai/logs/workflow/SPEC-ISSUE-7-sync.log",
+		"Quality",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	for _, phase := range []string{"plan", "run", "sync"} {
+		if _, err := os.Stat(filepath.Join(root, ".moai", "logs", "workflow", "SPEC-ISSUE-7-"+phase+".log")); err != nil {
+			t.Errorf("%s log: %v", phase, err)
+		}
+	}
+}
+
+func TestWorkflowRun_PhaseFails(t *testing.T) {
+	chdirWorkflowProject(t, "SPEC-ISSUE-7", "run")
+
+	out, err := executeWorkflowRun(t, "SPEC-ISSUE-7")
+	if !errors.Is(err, workflow.ErrRunPhaseFailed) || !errors.Is(err, workflow.ErrClaudeFailed) ||
+		!strings.Contains(err.Error(), "phase failed") {
+		t.Fatalf("error = %v, want the run phase failure with claude's stderr", err)
+	}
+	for _, want := range []string{"failed  1500/5000 tokens", "skipped"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWorkflowRun_NoWorktree(t *testing.T) {
+	chdirWorkflowProject(t, "", "")
+
+	_, err := executeWorkflowRun(t, "SPEC-ISSUE-7")
+	if !errors.Is(err, workflow.ErrNotInWorktree) || !strings.Contains(err.Error(), "moai worktree new SPEC-ISSUE-7") {
+		t.Errorf("error = %v, want a missing worktree hint", err)
+	}
+}
@@ -1,11 +1,23 @@
 package worktree
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/github"
 )
 
+// GitProviderFactory creates the Git hosting provider that "done --pr"
+// opens pull requests with. Tests replace it.
+var GitProviderFactory = func(root string, gs config.GitStrategyConfig) (*github.Provider, error) {
+	return github.NewProvider(root, gs)
+}
+
 func newDoneCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "done [branch-name]",
@@ -13,15 +25,22 @@ func newDoneCmd() *cobra.Command {
 		Long: `Complete a worktree by removing it and optionally deleting the branch.
 
 This command performs the completion workflow:
-1. Remove the worktree at the specified branch
-2. Optionally delete the feature branch (with --delete-branch)
-
-Note: Merging to base branch should be done separately via git merge or PR.`,
+1. Optionally push the branch and open a pull request (with --pr)
+2. Remove the worktree at the specified branch
+3. Optionally delete the feature branch (with --delete-branch)
+
+--pr uses the provider of git_strategy.provider: a GitHub pull request
+through gh, or a GitLab merge request through the GitLab API. Merge it
+with 'moai github merge'; otherwise merge to the base branch separately
+via git merge.`,
 		Args: cobra.ExactArgs(1),
 		RunE: runDone,
 	}
 	cmd.Flags().Bool("force", false, "Force removal even with uncommitted changes")
 	cmd.Flags().Bool("delete-branch", false, "Delete the branch after removing worktree")
+	cmd.Flags().Bool("pr", false, "Push the branch and open a pull request before removing the worktree")
+	cmd.Flags().String("base", "", "Base branch of the pull request (default: the repository default branch)")
+	cmd.Flags().String("title", "", "Title of the pull request (default: the SPEC ID or branch name)")
 	return cmd
 }
 
@@ -61,15 +80,28 @@ func runDone(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no worktree found for branch %q", branchName)
 	}
 
+	details := []string{fmt.Sprintf("Path: %s", targetPath)}
+
+	// Open the pull request while the worktree still exists to push from.
+	if openPR, _ := cmd.Flags().GetBool("pr"); openPR {
+		base, _ := cmd.Flags().GetString("base")
+		title, _ := cmd.Flags().GetString("title")
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		lines, err := openPullRequest(ctx, targetPath, branchName, base, title)
+		if err != nil {
+			return err
+		}
+		details = append(details, lines...)
+	}
+
 	// Remove the worktree.
 	if err := WorktreeProvider.Remove(targetPath, force); err != nil {
 		return fmt.Errorf("remove worktree: %w", err)
 	}
-
-	details := []string{
-		fmt.Sprintf("Path: %s", targetPath),
-		"Worktree removed.",
-	}
+	details = append(details, "Worktree removed.")
 
 	if deleteBranch {
 		if err := WorktreeProvider.DeleteBranch(branchName); err != nil {
@@ -88,3 +120,52 @@ func runDone(cmd *cobra.Command, args []string) error {
 	))
 	return nil
 }
+
+// openPullRequest pushes the branch of the worktree at wtPath and opens a
+// pull request for it with the configured provider, returning the lines
+// reporting it.
+func openPullRequest(ctx context.Context, wtPath, branch, base, title string) ([]string, error) {
+	root := WorktreeProvider.Root()
+	cfg, err := config.NewConfigManager().Load(root)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	provider, err := GitProviderFactory(root, cfg.GitStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := provider.Client.Push(ctx, wtPath); err != nil {
+		return nil, fmt.Errorf("push branch %s: %w", branch, err)
+	}
+
+	specID, isSpec := strings.CutPrefix(branch, "feature/")
+	isSpec = isSpec && isSpecID(specID)
+	if title == "" {
+		title = branch
+		if isSpec {
+			title = specID
+		}
+	}
+	var body string
+	if isSpec {
+		body = fmt.Sprintf("Implements %s.", specID)
+	}
+
+	number, err := provider.Client.PRCreate(ctx, github.PRCreateOptions{
+		Title:      title,
+		Body:       body,
+		BaseBranch: base,
+		HeadBranch: branch,
+	})
+	if errors.Is(err, github.ErrPRAlreadyExists) {
+		return []string{fmt.Sprintf("Branch pushed; a pull request for %s is already open.", branch)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open pull request for %s: %w", branch, err)
+	}
+	return []string{
+		fmt.Sprintf("Opened %s: %s", provider.RequestRef(number), title),
+		fmt.Sprintf("Merge with: moai github merge %d", number),
+	}, nil
+}
@@ -0,0 +1,128 @@
+package worktree
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/core/git"
+	"github.com/modu-ai/moai-adk/internal/github"
+)
+
+// fakePRClient records the push and pull request of "done --pr".
+type fakePRClient struct {
+	github.GHClient
+	pushDir   string
+	created   *github.PRCreateOptions
+	createErr error
+}
+
+func (c *fakePRClient) Push(_ context.Context, dir string) error {
+	c.pushDir = dir
+	return nil
+}
+
+func (c *fakePRClient) PRCreate(_ context.Context, opts github.PRCreateOptions) (int, error) {
+	c.created = &opts
+	return 7, c.createErr
+}
+
+// setupDonePR replaces the worktree manager and the provider factory,
+// recording the provider configuration and whether the worktree was
+// removed.
+func setupDonePR(t *testing.T, client *fakePRClient) (*config.GitStrategyConfig, *bool) {
+	t.Helper()
+	root := t.TempDir()
+	sections := filepath.Join(root, ".moai", "config", "sections")
+	if err := os.MkdirAll(sections, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	gitStrategy := "git_strategy:\n  provider: gitlab\n  gitlab:\n    instance_url: https://gitlab.example.com\n"
+	if err := os.WriteFile(filepath.Join(sections, "git-strategy.yaml"), []byte(gitStrategy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	origProvider, origFactory := WorktreeProvider, GitProviderFactory
+	t.Cleanup(func() { WorktreeProvider, GitProviderFactory = origProvider, origFactory })
+
+	removed := false
+	WorktreeProvider = &mockWorktreeManager{
+		rootPath: root,
+		listFunc: func() ([]git.Worktree, error) {
+			return []git.Worktree{{Path: "/wt/auth", Branch: "feature/SPEC-AUTH-001"}}, nil
+		},
+		removeFunc: func(string, bool) error {
+			removed = true
+			return nil
+		},
+	}
+	var gs config.GitStrategyConfig
+	GitProviderFactory = func(_ string, cfg config.GitStrategyConfig) (*github.Provider, error) {
+		gs = cfg
+		return &github.Provider{Name: cfg.Provider, Client: client}, nil
+	}
+	return &gs, &removed
+}
+
+func executeDone(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newDoneCmd()
+	var buf strings.Builder
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func TestRunDone_OpensPullRequest(t *testing.T) {
+	client := &fakePRClient{}
+	gs, removed := setupDonePR(t, client)
+
+	out, err := executeDone(t, "SPEC-AUTH-001", "--pr", "--base", "develop")
+	if err != nil {
+		t.Fatalf("done error = %v", err)
+	}
+	if gs.Provider != "gitlab" || gs.GitLabInstanceURL != "https://gitlab.example.com" {
+		t.Errorf("provider config = %+v, want the configured GitLab instance", gs)
+	}
+	if client.pushDir != "/wt/auth" {
+		t.Errorf("pushed from %q, want the worktree", client.pushDir)
+	}
+	want := github.PRCreateOptions{Title: "SPEC-AUTH-001", Body: "Implements SPEC-AUTH-001.", BaseBranch: "develop", HeadBranch: "feature/SPEC-AUTH-001"}
+	if client.created == nil || client.created.Title != want.Title || client.created.Body != want.Body ||
+		client.created.BaseBranch != want.BaseBranch || client.created.HeadBranch != want.HeadBranch {
+		t.Errorf("pull request = %+v, want %+v", client.created, want)
+	}
+	if !*removed {
+		t.Error("worktree not removed")
+	}
+	for _, want := range []string{"Opened MR !7: SPEC-AUTH-001", "moai github merge 7", "Worktree removed."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunDone_PullRequestErrors(t *testing.T) {
+	client := &fakePRClient{createErr: github.ErrPRAlreadyExists}
+	_, removed := setupDonePR(t, client)
+
+	out, err := executeDone(t, "SPEC-AUTH-001", "--pr", "--title", "Auth")
+	if err != nil || !strings.Contains(out, "already open") || !*removed {
+		t.Errorf("done with an open pull request = %v, removed %v:\n%s", err, *removed, out)
+	}
+
+	*removed = false
+	client.createErr = errors.New("gitlab API: 403 Forbidden")
+	if _, err := executeDone(t, "SPEC-AUTH-001", "--pr"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("error = %v, want the API error", err)
+	}
+	if *removed {
+		t.Error("worktree removed although the pull request failed")
+	}
+}
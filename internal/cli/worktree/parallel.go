@@ -0,0 +1,268 @@
+package worktree
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/core/git"
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/loop"
+	"github.com/modu-ai/moai-adk/internal/tmux"
+	"github.com/modu-ai/moai-adk/internal/workflow"
+)
+
+// specPlaceholder in --cmd is replaced with the SPEC ID of each pane.
+const specPlaceholder = "{spec}"
+
+// Tmux dependencies of the parallel command, replaced in tests.
+var (
+	tmuxDetector   tmux.Detector       = tmux.NewDetector()
+	sessionManager tmux.SessionManager = tmux.NewSessionManager()
+)
+
+func newParallelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "parallel <SPEC-ID>...",
+		Short: "Work on several SPECs in parallel tmux panes",
+		Long: `Create or reuse a worktree per SPEC (.moai/worktrees/<SPEC-ID>) and open
+a tmux session with one pane per worktree running --cmd. "{spec}" in the
+command is replaced with the SPEC ID of the pane. "moai workflow run"
+only accepts issue SPECs (SPEC-ISSUE-<number>).
+
+The number of SPECs is limited by
+quality.principles.simplicity.max_parallel_tasks.
+
+With --status, show the loop and workflow state of each SPEC worktree
+instead (all SPEC worktrees when no SPEC is given).
+
+Examples:
+  moai worktree parallel SPEC-AUTH-001 SPEC-UI-002
+  moai worktree parallel SPEC-ISSUE-12 SPEC-ISSUE-15 --cmd "moai workflow run {spec}"
+  moai worktree parallel --status`,
+		RunE: runParallel,
+	}
+	cmd.Flags().String("cmd", "claude", "Command to run in each pane")
+	cmd.Flags().String("session", "", "tmux session name (default: moai-parallel-<timestamp>)")
+	cmd.Flags().Bool("status", false, "Show the loop and workflow state of each SPEC worktree")
+	return cmd
+}
+
+func runParallel(cmd *cobra.Command, args []string) error {
+	out := cmd.OutOrStdout()
+
+	if WorktreeProvider == nil {
+		return fmt.Errorf("worktree manager not initialized (git module not available)")
+	}
+
+	showStatus, _ := cmd.Flags().GetBool("status")
+	command, _ := cmd.Flags().GetString("cmd")
+	sessionName, _ := cmd.Flags().GetString("session")
+
+	specIDs, err := uniqueSpecIDs(args)
+	if err != nil {
+		return err
+	}
+	if showStatus {
+		return runParallelStatus(out, specIDs)
+	}
+	if len(specIDs) == 0 {
+		return fmt.Errorf("at least one SPEC ID is required")
+	}
+	if strings.TrimSpace(command) == "" {
+		return fmt.Errorf("--cmd must not be empty")
+	}
+
+	root := WorktreeProvider.Root()
+	cfg, err := config.NewConfigManager().Load(root)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if limit := cfg.Quality.Principles.Simplicity.MaxParallelTasks; limit > 0 && len(specIDs) > limit {
+		return fmt.Errorf("%d SPECs exceed quality.principles.simplicity.max_parallel_tasks (%d)", len(specIDs), limit)
+	}
+	if !tmuxDetector.IsAvailable() {
+		return tmux.ErrTmuxNotFound
+	}
+
+	existing, err := WorktreeProvider.List()
+	if err != nil {
+		return fmt.Errorf("list worktrees: %w", err)
+	}
+	panes := make([]tmux.PaneConfig, 0, len(specIDs))
+	var lines []string
+	for _, specID := range specIDs {
+		wtPath, created, err := ensureSpecWorktree(root, specID, existing)
+		if err != nil {
+			return err
+		}
+		action := "reused"
+		if created {
+			action = "created"
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s (%s)", specID, wtPath, action))
+		panes = append(panes, tmux.PaneConfig{
+			SpecID:  specID,
+			Command: fmt.Sprintf("cd %s && %s", shellQuote(wtPath), strings.ReplaceAll(command, specPlaceholder, specID)),
+		})
+	}
+
+	if sessionName == "" {
+		sessionName = "moai-parallel-" + time.Now().Format("2006-01-02-15-04")
+	}
+	result, err := sessionManager.Create(cmd.Context(), &tmux.SessionConfig{Name: sessionName, Panes: panes})
+	if err != nil {
+		return err
+	}
+
+	attach := "tmux attach -t " + result.SessionName
+	if os.Getenv("TMUX") != "" {
+		attach = "tmux switch-client -t " + result.SessionName
+	}
+	lines = append(lines, "", fmt.Sprintf("Attach with: %s", attach))
+	_, _ = fmt.Fprintln(out, wtSuccessCard(
+		fmt.Sprintf("Started %d pane(s) in tmux session %s", result.PaneCount, result.SessionName),
+		lines...,
+	))
+	return nil
+}
+
+// uniqueSpecIDs validates args as SPEC IDs and drops duplicates.
+func uniqueSpecIDs(args []string) ([]string, error) {
+	seen := make(map[string]bool, len(args))
+	specIDs := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !isSpecID(arg) || arg != filepath.Base(arg) {
+			return nil, fmt.Errorf("invalid SPEC ID %q (expected SPEC-<CATEGORY>-<NUMBER>)", arg)
+		}
+		if !seen[arg] {
+			seen[arg] = true
+			specIDs = append(specIDs, arg)
+		}
+	}
+	return specIDs, nil
+}
+
+// ensureSpecWorktree returns the worktree of specID, adding it under
+// .moai/worktrees when none of existing is on its branch or path.
+func ensureSpecWorktree(root, specID string, existing []git.Worktree) (string, bool, error) {
+	if wt, ok := findSpecWorktree(root, specID, existing); ok {
+		return wt.Path, false, nil
+	}
+	wtPath := specWorktreePath(root, specID)
+	if err := WorktreeProvider.Add(wtPath, resolveSpecBranch(specID)); err != nil {
+		return "", false, fmt.Errorf("create worktree for %s: %w", specID, err)
+	}
+	return wtPath, true, nil
+}
+
+// findSpecWorktree returns the worktree of specID among worktrees.
+func findSpecWorktree(root, specID string, worktrees []git.Worktree) (git.Worktree, bool) {
+	branch := resolveSpecBranch(specID)
+	wtPath := specWorktreePath(root, specID)
+	for _, wt := range worktrees {
+		if wt.Branch == branch || filepath.Clean(wt.Path) == wtPath {
+			return wt, true
+		}
+	}
+	return git.Worktree{}, false
+}
+
+// specWorktreePath returns the default worktree path of specID.
+func specWorktreePath(root, specID string) string {
+	return filepath.Join(root, defs.MoAIDir, "worktrees", specID)
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runParallelStatus renders the loop and workflow state of the worktree
+// of each SPEC, or of every SPEC worktree when specIDs is empty.
+func runParallelStatus(out io.Writer, specIDs []string) error {
+	root := WorktreeProvider.Root()
+	worktrees, err := WorktreeProvider.List()
+	if err != nil {
+		return fmt.Errorf("list worktrees: %w", err)
+	}
+	if len(specIDs) == 0 {
+		for _, wt := range worktrees {
+			if specID := strings.TrimPrefix(wt.Branch, "feature/"); specID != wt.Branch && isSpecID(specID) {
+				specIDs = append(specIDs, specID)
+			}
+		}
+	}
+	if len(specIDs) == 0 {
+		_, _ = fmt.Fprintln(out, wtCard("Parallel Status", "No SPEC worktrees found."))
+		return nil
+	}
+
+	var lines []string
+	for i, specID := range specIDs {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, specID)
+		wt, ok := findSpecWorktree(root, specID, worktrees)
+		if !ok {
+			lines = append(lines, "  Worktree: none (create it with 'moai worktree new "+specID+"')")
+			continue
+		}
+		// Loop and workflow commands keep their state in the project they
+		// run in: the worktree, or the main repository.
+		dirs := []string{wt.Path, root}
+		lines = append(lines,
+			fmt.Sprintf("  Path: %s", wt.Path),
+			fmt.Sprintf("  Loop: %s", loopStatusLine(dirs, specID)),
+			fmt.Sprintf("  Workflow: %s", workflowStatusLine(dirs, specID)),
+		)
+	}
+	_, _ = fmt.Fprintln(out, wtCard("Parallel Status", strings.Join(lines, "\n")))
+	return nil
+}
+
+// loopStatusLine summarizes the saved loop state of specID found first
+// in dirs.
+func loopStatusLine(dirs []string, specID string) string {
+	for _, dir := range dirs {
+		state, err := loop.NewFileStorage(filepath.Join(dir, defs.MoAIDir, defs.LoopSubdir)).LoadState(specID)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "unreadable: " + err.Error()
+		}
+		return fmt.Sprintf("%s, iteration %d of %d (updated %s)",
+			state.Phase, state.Iteration, state.MaxIter, state.UpdatedAt.Local().Format("2006-01-02 15:04"))
+	}
+	return "none"
+}
+
+// workflowStatusLine summarizes the phase logs of specID found first in
+// dirs.
+func workflowStatusLine(dirs []string, specID string) string {
+	for _, dir := range dirs {
+		runs, err := workflow.ReadPhaseLogs(filepath.Join(dir, defs.MoAIDir, defs.LogsSubdir, workflow.LogSubdir), specID)
+		if err != nil {
+			return "unreadable: " + err.Error()
+		}
+		if len(runs) == 0 {
+			continue
+		}
+		parts := make([]string, 0, len(runs))
+		for _, run := range runs {
+			parts = append(parts, fmt.Sprintf("%s %s (%d tokens)", run.Phase, run.Status, run.Tokens))
+		}
+		return strings.Join(parts, ", ")
+	}
+	return "none"
+}
@@ -0,0 +1,173 @@
+package worktree
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/core/git"
+	"github.com/modu-ai/moai-adk/internal/loop"
+	"github.com/modu-ai/moai-adk/internal/tmux"
+)
+
+type mockDetector struct{ available bool }
+
+func (d *mockDetector) IsAvailable() bool        { return d.available }
+func (d *mockDetector) Version() (string, error) { return "3.4", nil }
+
+type mockSessionManager struct {
+	cfg *tmux.SessionConfig
+}
+
+func (m *mockSessionManager) Create(_ context.Context, cfg *tmux.SessionConfig) (*tmux.SessionResult, error) {
+	m.cfg = cfg
+	return &tmux.SessionResult{SessionName: cfg.Name, PaneCount: len(cfg.Panes)}, nil
+}
+
+// setupParallel replaces the worktree and tmux dependencies and returns
+// the repository root, the session manager and the added worktree paths.
+func setupParallel(t *testing.T, worktrees []git.Worktree) (string, *mockSessionManager, *[]string) {
+	t.Helper()
+	root := t.TempDir()
+	origProvider, origDetector, origSessions := WorktreeProvider, tmuxDetector, sessionManager
+	t.Cleanup(func() {
+		WorktreeProvider, tmuxDetector, sessionManager = origProvider, origDetector, origSessions
+	})
+	t.Setenv("TMUX", "")
+
+	var added []string
+	WorktreeProvider = &mockWorktreeManager{
+		rootPath: root,
+		listFunc: func() ([]git.Worktree, error) { return worktrees, nil },
+		addFunc: func(path, branch string) error {
+			added = append(added, path+" "+branch)
+			return nil
+		},
+	}
+	sessions := &mockSessionManager{}
+	tmuxDetector, sessionManager = &mockDetector{available: true}, sessions
+	return root, sessions, &added
+}
+
+func executeParallel(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	cmd := newParallelCmd()
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	cmd.SetErr(buf)
+	cmd.SetArgs(args)
+	err := cmd.ExecuteContext(context.Background())
+	return buf.String(), err
+}
+
+func TestRunParallel_CreatesPanes(t *testing.T) {
+	root, sessions, added := setupParallel(t, []git.Worktree{
+		{Path: "/elsewhere/auth", Branch: "feature/SPEC-AUTH-001"},
+	})
+
+	out, err := executeParallel(t, "SPEC-AUTH-001", "SPEC-UI-002", "SPEC-AUTH-001",
+		"--cmd", "moai loop start {spec}", "--session", "sprint")
+	if err != nil {
+		t.Fatalf("parallel error = %v", err)
+	}
+
+	uiPath := filepath.Join(root, ".moai", "worktrees", "SPEC-UI-002")
+	if len(*added) != 1 || (*added)[0] != uiPath+" feature/SPEC-UI-002" {
+		t.Errorf("added worktrees = %v, want only SPEC-UI-002", *added)
+	}
+	if sessions.cfg == nil || sessions.cfg.Name != "sprint" || len(sessions.cfg.Panes) != 2 {
+		t.Fatalf("session = %+v, want 2 panes in sprint", sessions.cfg)
+	}
+	if got, want := sessions.cfg.Panes[0].Command, "cd '/elsewhere/auth' && moai loop start SPEC-AUTH-001"; got != want {
+		t.Errorf("pane 0 command = %q, want %q", got, want)
+	}
+	if got, want := sessions.cfg.Panes[1].Command, "cd '"+uiPath+"' && moai loop start SPEC-UI-002"; got != want {
+		t.Errorf("pane 1 command = %q, want %q", got, want)
+	}
+	for _, want := range []string{"Started 2 pane(s) in tmux session sprint", "(reused)", "(created)", "tmux attach -t sprint"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunParallel_MaxParallelTasks(t *testing.T) {
+	root, sessions, added := setupParallel(t, nil)
+	sections := filepath.Join(root, ".moai", "config", "sections")
+	if err := os.MkdirAll(sections, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	quality := "constitution:\n  principles:\n    simplicity:\n      max_parallel_tasks: 2\n"
+	if err := os.WriteFile(filepath.Join(sections, "quality.yaml"), []byte(quality), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := executeParallel(t, "SPEC-A-1", "SPEC-B-2", "SPEC-C-3")
+	if err == nil || !strings.Contains(err.Error(), "max_parallel_tasks (2)") {
+		t.Errorf("error = %v, want the max_parallel_tasks limit", err)
+	}
+	if len(*added) != 0 || sessions.cfg != nil {
+		t.Error("worktrees or session created beyond the limit")
+	}
+}
+
+func TestRunParallel_Errors(t *testing.T) {
+	setupParallel(t, nil)
+
+	for _, args := range [][]string{{}, {"feature-x"}, {"SPEC-A-1", "--cmd", " "}} {
+		if _, err := executeParallel(t, args...); err == nil {
+			t.Errorf("args %v: want error", args)
+		}
+	}
+
+	tmuxDetector = &mockDetector{}
+	if _, err := executeParallel(t, "SPEC-A-1"); !errors.Is(err, tmux.ErrTmuxNotFound) {
+		t.Errorf("error = %v, want ErrTmuxNotFound", err)
+	}
+}
+
+func TestRunParallel_Status(t *testing.T) {
+	wtDir := t.TempDir()
+	root, _, _ := setupParallel(t, []git.Worktree{
+		{Path: "/repo", Branch: "main"},
+		{Path: wtDir, Branch: "feature/SPEC-AUTH-001"},
+	})
+
+	storage := loop.NewFileStorage(filepath.Join(wtDir, ".moai", "loop"))
+	if err := storage.SaveState(&loop.LoopState{
+		SpecID: "SPEC-AUTH-001", Phase: loop.PhaseReview, Iteration: 2, MaxIter: 5, UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	logDir := filepath.Join(root, ".moai", "logs", "workflow")
+	if err := os.MkdirAll(logDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	planLog := `{"type":"result","subtype":"success","is_error":false,"usage":{"input_tokens":900,"output_tokens":100}}` + "\n"
+	if err := os.WriteFile(filepath.Join(logDir, "SPEC-AUTH-001-plan.log"), []byte(planLog), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := executeParallel(t, "--status")
+	if err != nil {
+		t.Fatalf("status error = %v", err)
+	}
+	for _, want := range []string{"Parallel Status", "SPEC-AUTH-001", "Loop: review, iteration 2 of 5", "Workflow: plan completed (1000 tokens)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	out, err = executeParallel(t, "--status", "SPEC-UI-002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "moai worktree new SPEC-UI-002") {
+		t.Errorf("status of a SPEC without worktree:\n%s", out)
+	}
+}
@@ -16,7 +16,7 @@ var WorktreeCmd = &cobra.Command{
 	Use:     "worktree",
 	Aliases: []string{"wt"},
 	Short:   "Git worktree management",
-	Long:    "Manage Git worktrees for parallel SPEC development. Supports creating, listing, switching, syncing, removing, and cleaning worktrees.",
+	Long:    "Manage Git worktrees for parallel SPEC development. Supports creating, listing, switching, syncing, removing, and cleaning worktrees, and running SPECs in parallel tmux panes.",
 }
 
 func init() {
@@ -32,5 +32,6 @@ func init() {
 		newDoneCmd(),
 		newConfigCmd(),
 		newStatusCmd(),
+		newParallelCmd(),
 	)
 }
@@ -39,7 +39,7 @@ func TestWorktreeCmd_Short(t *testing.T) {
 }
 
 func TestWorktreeCmd_HasSubcommands(t *testing.T) {
-	expected := []string{"new", "list", "switch", "go", "sync", "remove", "clean", "recover", "done", "config", "status"}
+	expected := []string{"new", "list", "switch", "go", "sync", "remove", "clean", "recover", "done", "config", "status", "parallel"}
 	for _, name := range expected {
 		found := false
 		for _, cmd := range WorktreeCmd.Commands() {
@@ -56,8 +56,8 @@ func TestWorktreeCmd_HasSubcommands(t *testing.T) {
 
 func TestWorktreeCmd_SubcommandCount(t *testing.T) {
 	count := len(WorktreeCmd.Commands())
-	if count != 11 {
-		t.Errorf("worktree should have 11 subcommands, got %d", count)
+	if count != 12 {
+		t.Errorf("worktree should have 12 subcommands, got %d", count)
 	}
 }
 
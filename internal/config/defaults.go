@@ -14,12 +14,19 @@ const (
 	DefaultDocumentation            = "en"
 	DefaultErrorMessages            = "en"
 
-	DefaultTestCoverageTarget    = 85
-	DefaultMaxTransformationSize = "small"
-	DefaultMinCoveragePerCommit  = 80
-	DefaultMinCoverageNew        = 90
-	DefaultMinCoverageLegacy     = 85
-	DefaultMaxExemptPercentage   = 5
+	DefaultTestCoverageTarget     = 85
+	DefaultMaxTransformationSize  = "small"
+	DefaultMinCoveragePerCommit   = 80
+	DefaultMinCoverageNew         = 90
+	DefaultMinCoverageLegacy      = 85
+	DefaultMaxExemptPercentage    = 5
+	DefaultMutationScoreThreshold = 70
+	DefaultMaxParallelTasks       = 10
+
+	DefaultStopGateMaxBlocks      = 3
+	DefaultStopGateTimeoutSeconds = 300
+
+	DefaultAutoFormatTimeoutSeconds = 10
 
 	DefaultLogLevel  = "info"
 	DefaultLogFormat = "text"
@@ -36,6 +43,8 @@ const (
 	DefaultRunTokens  = 180000
 	DefaultSyncTokens = 40000
 
+	DefaultPromptTokenBudget = 2000
+
 	DefaultBranchPrefix = "moai/"
 	DefaultCommitStyle  = "conventional"
 
@@ -64,6 +73,8 @@ func NewDefaultConfig() *Config {
 		Pricing:       NewDefaultPricingConfig(),
 		Ralph:         NewDefaultRalphConfig(),
 		Workflow:      NewDefaultWorkflowConfig(),
+		Security:      NewDefaultSecurityConfig(),
+		Prompt:        NewDefaultPromptConfig(),
 	}
 }
 
@@ -96,6 +107,9 @@ func NewDefaultQualityConfig() models.QualityConfig {
 		TDDSettings:        NewDefaultTDDSettings(),
 		HybridSettings:     NewDefaultHybridSettings(),
 		CoverageExemptions: NewDefaultCoverageExemptions(),
+		Principles:         models.Principles{Simplicity: models.SimplicityPrinciple{MaxParallelTasks: DefaultMaxParallelTasks}},
+		StopGate:           NewDefaultStopGate(),
+		AutoFormat:         NewDefaultAutoFormat(),
 	}
 }
 
@@ -117,6 +131,7 @@ func NewDefaultTDDSettings() models.TDDSettings {
 		TestFirstRequired:      true,
 		MinCoveragePerCommit:   DefaultMinCoveragePerCommit,
 		MutationTestingEnabled: false,
+		MutationScoreThreshold: DefaultMutationScoreThreshold,
 	}
 }
 
@@ -140,6 +155,27 @@ func NewDefaultCoverageExemptions() models.CoverageExemptions {
 	}
 }
 
+// NewDefaultStopGate returns StopGate with default values.
+// Commands are empty so the per-language defaults apply. The gate runs
+// project commands, so it stays off until quality.yaml enables it.
+func NewDefaultStopGate() models.StopGate {
+	return models.StopGate{
+		Enabled:        false,
+		MaxBlocks:      DefaultStopGateMaxBlocks,
+		TimeoutSeconds: DefaultStopGateTimeoutSeconds,
+	}
+}
+
+// NewDefaultAutoFormat returns AutoFormat with default values. Formatting
+// rewrites project files, so it stays off until quality.yaml enables it.
+func NewDefaultAutoFormat() models.AutoFormat {
+	return models.AutoFormat{
+		Enabled:        false,
+		LintFix:        true,
+		TimeoutSeconds: DefaultAutoFormatTimeoutSeconds,
+	}
+}
+
 // NewDefaultProjectConfig returns a ProjectConfig with default values.
 func NewDefaultProjectConfig() models.ProjectConfig {
 	return models.ProjectConfig{}
@@ -198,6 +234,21 @@ func NewDefaultWorkflowConfig() WorkflowConfig {
 	}
 }
 
+// NewDefaultSecurityConfig returns a SecurityConfig with default values.
+// The zero value keeps the built-in security policy unchanged.
+func NewDefaultSecurityConfig() SecurityConfig {
+	return SecurityConfig{}
+}
+
+// NewDefaultPromptConfig returns a PromptConfig with default values.
+func NewDefaultPromptConfig() PromptConfig {
+	return PromptConfig{
+		ContextInjection: true,
+		TokenBudget:      DefaultPromptTokenBudget,
+		BlockSecrets:     true,
+	}
+}
+
 // NewDefaultGitConventionConfig returns a GitConventionConfig with default values.
 func NewDefaultGitConventionConfig() models.GitConventionConfig {
 	return models.GitConventionConfig{
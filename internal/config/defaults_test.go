@@ -137,6 +137,10 @@ func TestNewDefaultQualityConfig(t *testing.T) {
 		t.Errorf("TestCoverageTarget: got %d, want %d",
 			cfg.TestCoverageTarget, DefaultTestCoverageTarget)
 	}
+	if cfg.Principles.Simplicity.MaxParallelTasks != DefaultMaxParallelTasks {
+		t.Errorf("Principles.Simplicity.MaxParallelTasks: got %d, want %d",
+			cfg.Principles.Simplicity.MaxParallelTasks, DefaultMaxParallelTasks)
+	}
 
 	// Verify nested settings are populated
 	if !cfg.DDDSettings.RequireExistingTests {
@@ -195,6 +199,10 @@ func TestNewDefaultTDDSettings(t *testing.T) {
 	if s.MutationTestingEnabled {
 		t.Error("MutationTestingEnabled: expected false")
 	}
+	if s.MutationScoreThreshold != DefaultMutationScoreThreshold {
+		t.Errorf("MutationScoreThreshold: got %d, want %d",
+			s.MutationScoreThreshold, DefaultMutationScoreThreshold)
+	}
 }
 
 func TestNewDefaultHybridSettings(t *testing.T) {
@@ -236,6 +244,60 @@ func TestNewDefaultCoverageExemptions(t *testing.T) {
 	}
 }
 
+func TestNewDefaultStopGate(t *testing.T) {
+	t.Parallel()
+
+	s := NewDefaultStopGate()
+
+	if s.Enabled {
+		t.Error("Enabled: expected false until quality.yaml enables the gate")
+	}
+	if s.MaxBlocks != DefaultStopGateMaxBlocks {
+		t.Errorf("MaxBlocks: got %d, want %d", s.MaxBlocks, DefaultStopGateMaxBlocks)
+	}
+	if s.TimeoutSeconds != DefaultStopGateTimeoutSeconds {
+		t.Errorf("TimeoutSeconds: got %d, want %d", s.TimeoutSeconds, DefaultStopGateTimeoutSeconds)
+	}
+	if s.BuildCommand != "" || s.TestCommand != "" || s.LintCommand != "" {
+		t.Error("commands: expected empty so language defaults apply")
+	}
+}
+
+func TestNewDefaultPromptConfig(t *testing.T) {
+	t.Parallel()
+
+	p := NewDefaultPromptConfig()
+
+	if !p.ContextInjection {
+		t.Error("ContextInjection: expected true")
+	}
+	if !p.BlockSecrets {
+		t.Error("BlockSecrets: expected true")
+	}
+	if p.TokenBudget != DefaultPromptTokenBudget {
+		t.Errorf("TokenBudget: got %d, want %d", p.TokenBudget, DefaultPromptTokenBudget)
+	}
+	if len(p.Blocklist) != 0 {
+		t.Errorf("Blocklist: got %v, want empty", p.Blocklist)
+	}
+}
+
+func TestNewDefaultAutoFormat(t *testing.T) {
+	t.Parallel()
+
+	a := NewDefaultAutoFormat()
+
+	if a.Enabled || !a.LintFix {
+		t.Errorf("Enabled/LintFix: got %v/%v, want false/true", a.Enabled, a.LintFix)
+	}
+	if a.TimeoutSeconds != DefaultAutoFormatTimeoutSeconds {
+		t.Errorf("TimeoutSeconds: got %d, want %d", a.TimeoutSeconds, DefaultAutoFormatTimeoutSeconds)
+	}
+	if len(a.Languages) != 0 {
+		t.Errorf("Languages: got %v, want no overrides", a.Languages)
+	}
+}
+
 func TestNewDefaultProjectConfig(t *testing.T) {
 	t.Parallel()
 
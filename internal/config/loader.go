@@ -52,6 +52,21 @@ func (l *Loader) Load(configDir string) (*Config, error) {
 	// Load git convention section
 	l.loadGitConventionSection(sectionsDir, cfg)
 
+	// Load security section
+	l.loadSecuritySection(sectionsDir, cfg)
+
+	// Load prompt section
+	l.loadPromptSection(sectionsDir, cfg)
+
+	// Load ralph section
+	l.loadRalphSection(sectionsDir, cfg)
+
+	// Load workflow section
+	l.loadWorkflowSection(sectionsDir, cfg)
+
+	// Load git strategy section
+	l.loadGitStrategySection(sectionsDir, cfg)
+
 	return cfg, nil
 }
 
@@ -126,6 +141,130 @@ func (l *Loader) loadGitConventionSection(dir string, cfg *Config) {
 	}
 }
 
+// loadSecuritySection loads the security policy configuration from security.yaml.
+func (l *Loader) loadSecuritySection(dir string, cfg *Config) {
+	wrapper := &securityFileWrapper{Security: cfg.Security}
+	loaded, err := loadYAMLFile(dir, "security.yaml", wrapper)
+	if err != nil {
+		slog.Warn("failed to load security config, using defaults", "error", err)
+		return
+	}
+	if loaded {
+		cfg.Security = wrapper.Security
+		l.loadedSections["security"] = true
+	}
+}
+
+// loadPromptSection loads the UserPromptSubmit hook configuration from prompt.yaml.
+func (l *Loader) loadPromptSection(dir string, cfg *Config) {
+	wrapper := &promptFileWrapper{Prompt: cfg.Prompt}
+	loaded, err := loadYAMLFile(dir, "prompt.yaml", wrapper)
+	if err != nil {
+		slog.Warn("failed to load prompt config, using defaults", "error", err)
+		return
+	}
+	if loaded {
+		cfg.Prompt = wrapper.Prompt
+		l.loadedSections["prompt"] = true
+	}
+}
+
+// loadRalphSection loads the Ralph feedback loop configuration from
+// ralph.yaml. A top-level max_iterations takes precedence over
+// loop.max_iterations.
+func (l *Loader) loadRalphSection(dir string, cfg *Config) {
+	wrapper := &ralphFileWrapper{}
+	wrapper.Ralph.RalphConfig = cfg.Ralph
+	wrapper.Ralph.MaxIterations = 0
+	loaded, err := loadYAMLFile(dir, "ralph.yaml", wrapper)
+	if err != nil {
+		slog.Warn("failed to load ralph config, using defaults", "error", err)
+		return
+	}
+	if !loaded {
+		return
+	}
+	ralph := wrapper.Ralph.RalphConfig
+	switch {
+	case ralph.MaxIterations > 0:
+	case wrapper.Ralph.Loop.MaxIterations > 0:
+		ralph.MaxIterations = wrapper.Ralph.Loop.MaxIterations
+	default:
+		ralph.MaxIterations = cfg.Ralph.MaxIterations
+	}
+	cfg.Ralph = ralph
+	l.loadedSections["ralph"] = true
+}
+
+// loadWorkflowSection loads the workflow configuration from workflow.yaml.
+// Token budgets may be given as token_budget.plan or plan_tokens, the latter
+// taking precedence, and auto_clear as a boolean or a map with "enabled".
+func (l *Loader) loadWorkflowSection(dir string, cfg *Config) {
+	wrapper := &workflowFileWrapper{}
+	loaded, err := loadYAMLFile(dir, "workflow.yaml", wrapper)
+	if err != nil {
+		slog.Warn("failed to load workflow config, using defaults", "error", err)
+		return
+	}
+	if !loaded {
+		return
+	}
+
+	w := wrapper.Workflow
+	switch w.AutoClear.Kind {
+	case yaml.ScalarNode:
+		_ = w.AutoClear.Decode(&cfg.Workflow.AutoClear)
+	case yaml.MappingNode:
+		var autoClear struct {
+			Enabled bool `yaml:"enabled"`
+		}
+		autoClear.Enabled = cfg.Workflow.AutoClear
+		_ = w.AutoClear.Decode(&autoClear)
+		cfg.Workflow.AutoClear = autoClear.Enabled
+	}
+	for _, budget := range []struct {
+		target       *int
+		nested, flat *int
+	}{
+		{&cfg.Workflow.PlanTokens, w.TokenBudget.Plan, w.PlanTokens},
+		{&cfg.Workflow.RunTokens, w.TokenBudget.Run, w.RunTokens},
+		{&cfg.Workflow.SyncTokens, w.TokenBudget.Sync, w.SyncTokens},
+	} {
+		if budget.nested != nil {
+			*budget.target = *budget.nested
+		}
+		if budget.flat != nil {
+			*budget.target = *budget.flat
+		}
+	}
+	l.loadedSections["workflow"] = true
+}
+
+// loadGitStrategySection loads the git strategy configuration from
+// git-strategy.yaml. A flat gitlab_instance_url takes precedence over
+// gitlab.instance_url, and an empty provider keeps the default.
+func (l *Loader) loadGitStrategySection(dir string, cfg *Config) {
+	wrapper := &gitStrategyFileWrapper{}
+	wrapper.GitStrategy.GitStrategyConfig = cfg.GitStrategy
+	loaded, err := loadYAMLFile(dir, "git-strategy.yaml", wrapper)
+	if err != nil {
+		slog.Warn("failed to load git strategy config, using defaults", "error", err)
+		return
+	}
+	if !loaded {
+		return
+	}
+	gs := wrapper.GitStrategy.GitStrategyConfig
+	if gs.GitLabInstanceURL == "" {
+		gs.GitLabInstanceURL = wrapper.GitStrategy.GitLab.InstanceURL
+	}
+	if gs.Provider == "" {
+		gs.Provider = cfg.GitStrategy.Provider
+	}
+	cfg.GitStrategy = gs
+	l.loadedSections["git_strategy"] = true
+}
+
 // loadYAMLFile reads a YAML file from the given directory and unmarshals it
 // into the target struct. Returns (true, nil) if the file was found and parsed,
 // (false, nil) if the file does not exist, or (false, error) on failure.
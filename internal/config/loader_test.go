@@ -431,3 +431,186 @@ func TestLoaderGitConventionDefaults(t *testing.T) {
 		t.Error("expected git_convention section to NOT be loaded")
 	}
 }
+
+func TestLoaderLoadSecuritySection(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	root := setupTestdataDir(t, tempDir, []string{"security.yaml"})
+
+	loader := NewLoader()
+	cfg, err := loader.Load(filepath.Join(root, ".moai"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if !loader.LoadedSections()["security"] {
+		t.Error("expected section \"security\" to be loaded")
+	}
+
+	sec := cfg.Security
+	if len(sec.BlockedTools) != 1 || sec.BlockedTools[0] != "WebFetch" {
+		t.Errorf("Security.BlockedTools: got %v, want [WebFetch]", sec.BlockedTools)
+	}
+	if len(sec.Rules) != 3 {
+		t.Fatalf("Security.Rules: got %d rules, want 3", len(sec.Rules))
+	}
+	if !sec.Rules[0].Disabled {
+		t.Errorf("Security.Rules[0].Disabled: got false, want true")
+	}
+	if sec.Rules[2].Kind != "bash" || sec.Rules[2].Severity != "deny" {
+		t.Errorf("Security.Rules[2]: got kind %q severity %q, want bash/deny",
+			sec.Rules[2].Kind, sec.Rules[2].Severity)
+	}
+	if len(sec.Allowlist) != 1 || sec.Allowlist[0].Paths[0] != "^sandbox/" {
+		t.Errorf("Security.Allowlist: got %+v", sec.Allowlist)
+	}
+}
+
+func TestLoaderLoadPromptSection(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	root := setupTestdataDir(t, tempDir, []string{"prompt.yaml"})
+
+	loader := NewLoader()
+	cfg, err := loader.Load(filepath.Join(root, ".moai"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if !loader.LoadedSections()["prompt"] {
+		t.Error("expected section \"prompt\" to be loaded")
+	}
+
+	p := cfg.Prompt
+	if p.ContextInjection {
+		t.Error("Prompt.ContextInjection: got true, want false")
+	}
+	if p.TokenBudget != 800 {
+		t.Errorf("Prompt.TokenBudget: got %d, want 800", p.TokenBudget)
+	}
+	if len(p.Blocklist) != 1 || p.Blocklist[0] != "internal-only" {
+		t.Errorf("Prompt.Blocklist: got %v, want [internal-only]", p.Blocklist)
+	}
+}
+
+func TestLoaderLoadWorkflowSection(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	root := setupTestdataDir(t, tempDir, []string{"workflow.yaml"})
+
+	loader := NewLoader()
+	cfg, err := loader.Load(filepath.Join(root, ".moai"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if !loader.LoadedSections()["workflow"] {
+		t.Error("expected section \"workflow\" to be loaded")
+	}
+
+	w := cfg.Workflow
+	if w.AutoClear {
+		t.Error("Workflow.AutoClear: got true, want false from auto_clear.enabled")
+	}
+	if w.PlanTokens != 25000 || w.RunTokens != 150000 {
+		t.Errorf("Workflow plan/run tokens: got %d/%d, want the token_budget values 25000/150000", w.PlanTokens, w.RunTokens)
+	}
+	if w.SyncTokens != 20000 {
+		t.Errorf("Workflow.SyncTokens: got %d, want sync_tokens to take precedence (20000)", w.SyncTokens)
+	}
+}
+
+func TestLoaderLoadWorkflowSectionFlat(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	sectionsDir := filepath.Join(root, ".moai", "config", "sections")
+	if err := os.MkdirAll(sectionsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "workflow:\n  auto_clear: false\n  plan_tokens: 1000\n"
+	if err := os.WriteFile(filepath.Join(sectionsDir, "workflow.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewLoader().Load(filepath.Join(root, ".moai"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.Workflow.AutoClear || cfg.Workflow.PlanTokens != 1000 || cfg.Workflow.RunTokens != DefaultRunTokens {
+		t.Errorf("Workflow: got %+v, want auto_clear off, 1000 plan tokens and the default run tokens", cfg.Workflow)
+	}
+}
+
+func TestLoaderLoadRalphSection(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	root := setupTestdataDir(t, tempDir, []string{"ralph.yaml"})
+
+	loader := NewLoader()
+	cfg, err := loader.Load(filepath.Join(root, ".moai"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	if !loader.LoadedSections()["ralph"] {
+		t.Error("expected section \"ralph\" to be loaded")
+	}
+
+	r := cfg.Ralph
+	if r.MaxIterations != 10 {
+		t.Errorf("Ralph.MaxIterations: got %d, want 10 from loop.max_iterations", r.MaxIterations)
+	}
+	if r.HumanReview || !r.AutoConverge {
+		t.Errorf("Ralph: got human_review %v, auto_converge %v; want false and the default true", r.HumanReview, r.AutoConverge)
+	}
+	if r.Commands.Test != "go test -json -cover ./..." || r.Commands.Lint != "golangci-lint run" || r.Commands.Build != "" {
+		t.Errorf("Ralph.Commands: got %+v", r.Commands)
+	}
+}
+
+func TestLoaderLoadGitStrategySection(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	sectionsDir := filepath.Join(root, ".moai", "config", "sections")
+	if err := os.MkdirAll(sectionsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "git_strategy:\n  mode: \"team\"\n  provider: \"gitlab\"\n  gitlab:\n    instance_url: \"https://gitlab.example.com\"\n"
+	if err := os.WriteFile(filepath.Join(sectionsDir, "git-strategy.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader()
+	cfg, err := loader.Load(filepath.Join(root, ".moai"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !loader.LoadedSections()["git_strategy"] {
+		t.Error("expected section \"git_strategy\" to be loaded")
+	}
+	gs := cfg.GitStrategy
+	if gs.Provider != "gitlab" || gs.GitLabInstanceURL != "https://gitlab.example.com" {
+		t.Errorf("GitStrategy: got provider %q, instance %q; want gitlab from gitlab.instance_url", gs.Provider, gs.GitLabInstanceURL)
+	}
+	if gs.BranchPrefix != DefaultBranchPrefix {
+		t.Errorf("GitStrategy.BranchPrefix: got %q, want the default %q", gs.BranchPrefix, DefaultBranchPrefix)
+	}
+
+	content = "git_strategy:\n  provider: \"\"\n  gitlab_instance_url: \"https://flat.example.com\"\n  gitlab:\n    instance_url: \"https://nested.example.com\"\n"
+	if err := os.WriteFile(filepath.Join(sectionsDir, "git-strategy.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = NewLoader().Load(filepath.Join(root, ".moai"))
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if cfg.GitStrategy.Provider != "github" || cfg.GitStrategy.GitLabInstanceURL != "https://flat.example.com" {
+		t.Errorf("GitStrategy: got %+v, want the default provider and the flat instance URL", cfg.GitStrategy)
+	}
+}
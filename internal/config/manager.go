@@ -153,6 +153,20 @@ func (m *ConfigManager) Save() error {
 		return fmt.Errorf("save git convention config: %w", err)
 	}
 
+	// Save security section only when the project opted in with security.yaml
+	if m.loadedSections["security"] {
+		if err := saveSection(sectionsDir, "security.yaml", securityFileWrapper{Security: m.config.Security}); err != nil {
+			return fmt.Errorf("save security config: %w", err)
+		}
+	}
+
+	// Save prompt section only when the project opted in with prompt.yaml
+	if m.loadedSections["prompt"] {
+		if err := saveSection(sectionsDir, "prompt.yaml", promptFileWrapper{Prompt: m.config.Prompt}); err != nil {
+			return fmt.Errorf("save prompt config: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -233,6 +247,10 @@ func (m *ConfigManager) getSectionLocked(name string) (any, error) {
 		return m.config.Ralph, nil
 	case "workflow":
 		return m.config.Workflow, nil
+	case "security":
+		return m.config.Security, nil
+	case "prompt":
+		return m.config.Prompt, nil
 	default:
 		return nil, ErrSectionNotFound
 	}
@@ -307,6 +325,18 @@ func (m *ConfigManager) setSectionLocked(name string, value any) error {
 			return fmt.Errorf("%w: expected WorkflowConfig for section %q", ErrSectionTypeMismatch, name)
 		}
 		m.config.Workflow = v
+	case "security":
+		v, ok := value.(SecurityConfig)
+		if !ok {
+			return fmt.Errorf("%w: expected SecurityConfig for section %q", ErrSectionTypeMismatch, name)
+		}
+		m.config.Security = v
+	case "prompt":
+		v, ok := value.(PromptConfig)
+		if !ok {
+			return fmt.Errorf("%w: expected PromptConfig for section %q", ErrSectionTypeMismatch, name)
+		}
+		m.config.Prompt = v
 	default:
 		return ErrSectionNotFound
 	}
@@ -207,6 +207,10 @@ func TestConfigManagerGetSection(t *testing.T) {
 			_, ok := v.(WorkflowConfig)
 			return ok
 		}},
+		{"security", "security", func(v any) bool {
+			_, ok := v.(SecurityConfig)
+			return ok
+		}},
 	}
 
 	for _, tt := range tests {
@@ -301,6 +305,7 @@ func TestConfigManagerSetSectionAllTypes(t *testing.T) {
 		{"pricing", PricingConfig{TokenBudget: 100}},
 		{"ralph", RalphConfig{MaxIterations: 1}},
 		{"workflow", WorkflowConfig{PlanTokens: 1000}},
+		{"security", SecurityConfig{BlockedTools: []string{"WebFetch"}}},
 	}
 
 	for _, tt := range tests {
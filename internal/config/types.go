@@ -1,6 +1,8 @@
 package config
 
 import (
+	"gopkg.in/yaml.v3"
+
 	"github.com/modu-ai/moai-adk/pkg/models"
 )
 
@@ -19,6 +21,8 @@ type Config struct {
 	Pricing       PricingConfig              `yaml:"pricing"`
 	Ralph         RalphConfig                `yaml:"ralph"`
 	Workflow      WorkflowConfig             `yaml:"workflow"`
+	Security      SecurityConfig             `yaml:"security"`
+	Prompt        PromptConfig               `yaml:"prompt"`
 }
 
 // GitStrategyConfig represents the git strategy configuration section.
@@ -75,9 +79,19 @@ type PricingConfig struct {
 
 // RalphConfig represents the Ralph engine configuration section.
 type RalphConfig struct {
-	MaxIterations int  `yaml:"max_iterations"`
-	AutoConverge  bool `yaml:"auto_converge"`
-	HumanReview   bool `yaml:"human_review"`
+	MaxIterations int           `yaml:"max_iterations"`
+	AutoConverge  bool          `yaml:"auto_converge"`
+	HumanReview   bool          `yaml:"human_review"`
+	Commands      RalphCommands `yaml:"commands"`
+}
+
+// RalphCommands overrides the build, test, lint and coverage commands the
+// feedback loop runs. Empty commands are detected from the project languages.
+type RalphCommands struct {
+	Build    string `yaml:"build"`
+	Test     string `yaml:"test"`
+	Lint     string `yaml:"lint"`
+	Coverage string `yaml:"coverage"`
 }
 
 // WorkflowConfig represents the workflow configuration section.
@@ -88,6 +102,63 @@ type WorkflowConfig struct {
 	SyncTokens int  `yaml:"sync_tokens"`
 }
 
+// SecurityConfig represents the security policy configuration section.
+// It customizes the built-in PreToolUse security policy: rules are matched
+// against the built-in rule set by ID and can be overridden, disabled or
+// added, and allowlist entries exempt specific paths or commands.
+type SecurityConfig struct {
+	// DisableDefaults drops the built-in rule set so only Rules apply.
+	DisableDefaults bool `yaml:"disable_defaults"`
+	// BlockedTools lists tool names that are always denied.
+	BlockedTools []string `yaml:"blocked_tools"`
+	// Rules adds, overrides or disables policy rules by ID.
+	Rules []SecurityRuleConfig `yaml:"rules"`
+	// Allowlist exempts matching paths or commands from selected rules.
+	Allowlist []SecurityAllowConfig `yaml:"allowlist"`
+}
+
+// SecurityRuleConfig represents a single security rule entry.
+// Entries whose ID matches a built-in rule override only the fields that
+// are set; entries with a new ID must provide Kind and Pattern.
+type SecurityRuleConfig struct {
+	ID       string `yaml:"id"`
+	Kind     string `yaml:"kind,omitempty"`     // "path", "bash", "content", "read"
+	Pattern  string `yaml:"pattern,omitempty"`  // case-insensitive regex
+	Severity string `yaml:"severity,omitempty"` // "deny", "ask", "log", "redact" (read only)
+	Message  string `yaml:"message,omitempty"`
+	Disabled bool   `yaml:"disabled,omitempty"`
+	// MinEntropy, when set, only counts content matches whose Shannon
+	// entropy (bits per character) is at least this value.
+	MinEntropy float64 `yaml:"min_entropy,omitempty"`
+}
+
+// SecurityAllowConfig represents an allowlist exception.
+// Paths are regexes matched against the project-relative file path (or the
+// working directory for Bash commands). Commands are regexes matched against
+// the Bash command. An entry matches when every non-empty list has a match.
+type SecurityAllowConfig struct {
+	Rules    []string `yaml:"rules"` // rule IDs; empty applies to all rules
+	Paths    []string `yaml:"paths"`
+	Commands []string `yaml:"commands"`
+	Reason   string   `yaml:"reason,omitempty"`
+}
+
+// PromptConfig represents the UserPromptSubmit hook configuration section.
+// It controls the context injected for SPEC IDs, issue numbers and file
+// paths mentioned in a prompt, and which prompts are rejected outright.
+type PromptConfig struct {
+	// ContextInjection enables resolving prompt mentions into additional context.
+	ContextInjection bool `yaml:"context_injection"`
+	// TokenBudget caps the injected context, estimated at 4 characters per
+	// token. Zero uses DefaultPromptTokenBudget.
+	TokenBudget int `yaml:"token_budget"`
+	// BlockSecrets rejects prompts matching a deny security content rule and
+	// warns about prompts matching an ask rule.
+	BlockSecrets bool `yaml:"block_secrets"`
+	// Blocklist holds additional case-insensitive regexes that reject a prompt.
+	Blocklist []string `yaml:"blocklist"`
+}
+
 // LSPQualityGates represents LSP quality gate configuration.
 type LSPQualityGates struct {
 	Enabled         bool     `yaml:"enabled"`
@@ -122,7 +193,7 @@ type SyncGate struct {
 var sectionNames = []string{
 	"user", "language", "quality", "project",
 	"git_strategy", "git_convention", "system", "llm",
-	"pricing", "ralph", "workflow",
+	"pricing", "ralph", "workflow", "security",
 }
 
 // IsValidSectionName checks if the given name is a valid section name.
@@ -163,3 +234,53 @@ type qualityFileWrapper struct {
 type gitConventionFileWrapper struct {
 	GitConvention models.GitConventionConfig `yaml:"git_convention"`
 }
+
+// securityFileWrapper handles the security.yaml section file.
+type securityFileWrapper struct {
+	Security SecurityConfig `yaml:"security"`
+}
+
+// ralphFileWrapper handles the ralph.yaml section file. The loop iteration
+// limit may also be given as loop.max_iterations, as in the full template.
+type ralphFileWrapper struct {
+	Ralph struct {
+		RalphConfig `yaml:",inline"`
+		Loop        struct {
+			MaxIterations int `yaml:"max_iterations"`
+		} `yaml:"loop"`
+	} `yaml:"ralph"`
+}
+
+// workflowFileWrapper handles the workflow.yaml section file. Besides the
+// flat keys written by "moai init", it reads the token_budget map and the
+// auto_clear map of the full workflow template.
+type workflowFileWrapper struct {
+	Workflow struct {
+		AutoClear   yaml.Node `yaml:"auto_clear"`
+		PlanTokens  *int      `yaml:"plan_tokens"`
+		RunTokens   *int      `yaml:"run_tokens"`
+		SyncTokens  *int      `yaml:"sync_tokens"`
+		TokenBudget struct {
+			Plan *int `yaml:"plan"`
+			Run  *int `yaml:"run"`
+			Sync *int `yaml:"sync"`
+		} `yaml:"token_budget"`
+	} `yaml:"workflow"`
+}
+
+// gitStrategyFileWrapper handles the git-strategy.yaml section file. The
+// GitLab instance URL may also be given as gitlab.instance_url, as written
+// by "moai init".
+type gitStrategyFileWrapper struct {
+	GitStrategy struct {
+		GitStrategyConfig `yaml:",inline"`
+		GitLab            struct {
+			InstanceURL string `yaml:"instance_url"`
+		} `yaml:"gitlab"`
+	} `yaml:"git_strategy"`
+}
+
+// promptFileWrapper handles the prompt.yaml section file.
+type promptFileWrapper struct {
+	Prompt PromptConfig `yaml:"prompt"`
+}
@@ -35,6 +35,15 @@ func Validate(cfg *Config, loadedSections map[string]bool) error {
 	// Check git convention config
 	errs = append(errs, validateGitConventionConfig(&cfg.GitConvention)...)
 
+	// Check fallback diagnostics tools
+	errs = append(errs, validateFallbackDiagnostics(&cfg.Quality.FallbackDiagnostics)...)
+
+	// Check security policy config
+	errs = append(errs, validateSecurityConfig(&cfg.Security)...)
+
+	// Check prompt hook config
+	errs = append(errs, validatePromptConfig(&cfg.Prompt)...)
+
 	// Check for unexpanded dynamic tokens
 	errs = append(errs, validateDynamicTokens(cfg)...)
 
@@ -100,6 +109,15 @@ func validateQualityConfig(q *models.QualityConfig) []ValidationError {
 		})
 	}
 
+	if q.TDDSettings.MutationScoreThreshold < 0 || q.TDDSettings.MutationScoreThreshold > 100 {
+		errs = append(errs, ValidationError{
+			Field:   "quality.tdd_settings.mutation_score_threshold",
+			Message: "must be between 0 and 100",
+			Value:   q.TDDSettings.MutationScoreThreshold,
+			Wrapped: ErrInvalidConfig,
+		})
+	}
+
 	if q.HybridSettings.MinCoverageNew < 0 || q.HybridSettings.MinCoverageNew > 100 {
 		errs = append(errs, ValidationError{
 			Field:   "quality.hybrid_settings.min_coverage_new",
@@ -127,6 +145,134 @@ func validateQualityConfig(q *models.QualityConfig) []ValidationError {
 		})
 	}
 
+	if q.StopGate.MaxBlocks < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "quality.stop_gate.max_blocks",
+			Message: "must not be negative",
+			Value:   q.StopGate.MaxBlocks,
+			Wrapped: ErrInvalidConfig,
+		})
+	}
+
+	if q.StopGate.TimeoutSeconds < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "quality.stop_gate.timeout_seconds",
+			Message: "must not be negative",
+			Value:   q.StopGate.TimeoutSeconds,
+			Wrapped: ErrInvalidConfig,
+		})
+	}
+
+	if q.AutoFormat.TimeoutSeconds < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "quality.auto_format.timeout_seconds",
+			Message: "must not be negative",
+			Value:   q.AutoFormat.TimeoutSeconds,
+			Wrapped: ErrInvalidConfig,
+		})
+	}
+
+	for lang, l := range q.AutoFormat.Languages {
+		if l.TimeoutSeconds < 0 {
+			errs = append(errs, ValidationError{
+				Field:   "quality.auto_format.languages." + lang + ".timeout_seconds",
+				Message: "must not be negative",
+				Value:   l.TimeoutSeconds,
+				Wrapped: ErrInvalidConfig,
+			})
+		}
+	}
+
+	return errs
+}
+
+// validFallbackFormats lists recognized fallback tool output formats.
+var validFallbackFormats = map[string]bool{
+	"sarif": true,
+	"regex": true,
+}
+
+// validFallbackSeverities lists the diagnostic severities tool severities
+// may map to.
+var validFallbackSeverities = map[string]bool{
+	"error":       true,
+	"warning":     true,
+	"information": true,
+	"hint":        true,
+}
+
+// validateFallbackDiagnostics checks the declared fallback diagnostics tools.
+func validateFallbackDiagnostics(fd *models.FallbackDiagnostics) []ValidationError {
+	var errs []ValidationError
+
+	for i, tool := range fd.Tools {
+		field := fmt.Sprintf("quality.fallback_diagnostics.tools[%d]", i)
+
+		required := []struct{ name, value string }{
+			{"name", tool.Name},
+			{"language", tool.Language},
+			{"command", tool.Command},
+		}
+		for _, r := range required {
+			if r.value == "" {
+				errs = append(errs, ValidationError{
+					Field:   field + "." + r.name,
+					Message: "required field is empty",
+					Wrapped: ErrInvalidConfig,
+				})
+			}
+		}
+
+		if tool.Format != "" && !validFallbackFormats[tool.Format] {
+			errs = append(errs, ValidationError{
+				Field:   field + ".format",
+				Message: "must be one of: sarif, regex",
+				Value:   tool.Format,
+				Wrapped: ErrInvalidConfig,
+			})
+		}
+
+		if tool.Pattern != "" {
+			if re, err := regexp.Compile(tool.Pattern); err != nil {
+				errs = append(errs, ValidationError{
+					Field:   field + ".pattern",
+					Message: fmt.Sprintf("invalid regular expression: %v", err),
+					Value:   tool.Pattern,
+					Wrapped: ErrInvalidConfig,
+				})
+			} else if re.SubexpIndex("line") < 0 || re.SubexpIndex("message") < 0 {
+				errs = append(errs, ValidationError{
+					Field:   field + ".pattern",
+					Message: "must capture the named groups line and message",
+					Value:   tool.Pattern,
+					Wrapped: ErrInvalidConfig,
+				})
+			}
+		}
+
+		for name, severity := range tool.Severity {
+			if !validFallbackSeverities[severity] {
+				errs = append(errs, ValidationError{
+					Field:   field + ".severity." + name,
+					Message: "must be one of: error, warning, information, hint",
+					Value:   severity,
+					Wrapped: ErrInvalidConfig,
+				})
+			}
+		}
+
+		for j, ext := range tool.Extensions {
+			if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+				errs = append(errs, ValidationError{
+					Field:   fmt.Sprintf("%s.extensions[%d]", field, j),
+					Message: "must start with a dot",
+					Value:   ext,
+					Wrapped: ErrInvalidConfig,
+				})
+			}
+		}
+	}
+
 	return errs
 }
 
@@ -191,6 +337,132 @@ func validateGitConventionConfig(gc *models.GitConventionConfig) []ValidationErr
 	return errs
 }
 
+// validSecurityRuleKinds lists recognized security rule kinds.
+var validSecurityRuleKinds = map[string]bool{
+	"path":    true,
+	"bash":    true,
+	"content": true,
+	"read":    true,
+}
+
+// validSecuritySeverities lists recognized security rule severities.
+var validSecuritySeverities = map[string]bool{
+	"deny":   true,
+	"ask":    true,
+	"log":    true,
+	"redact": true,
+}
+
+// validateSecurityConfig checks the security policy configuration.
+// Rule IDs must be unique, kinds and severities must be recognized, and
+// every regex must compile. Whether an ID refers to a built-in rule is
+// resolved by the hook package when the policy is built.
+func validateSecurityConfig(sc *SecurityConfig) []ValidationError {
+	var errs []ValidationError
+
+	seen := make(map[string]bool, len(sc.Rules))
+	for i, rule := range sc.Rules {
+		field := fmt.Sprintf("security.rules[%d]", i)
+
+		if rule.ID == "" {
+			errs = append(errs, ValidationError{
+				Field:   field + ".id",
+				Message: "required field is empty",
+				Wrapped: ErrInvalidConfig,
+			})
+		} else if seen[rule.ID] {
+			errs = append(errs, ValidationError{
+				Field:   field + ".id",
+				Message: "duplicate rule id",
+				Value:   rule.ID,
+				Wrapped: ErrInvalidConfig,
+			})
+		}
+		seen[rule.ID] = true
+
+		if rule.Kind != "" && !validSecurityRuleKinds[rule.Kind] {
+			errs = append(errs, ValidationError{
+				Field:   field + ".kind",
+				Message: "must be one of: path, bash, content, read",
+				Value:   rule.Kind,
+				Wrapped: ErrInvalidConfig,
+			})
+		}
+
+		if rule.Severity != "" && !validSecuritySeverities[rule.Severity] {
+			errs = append(errs, ValidationError{
+				Field:   field + ".severity",
+				Message: "must be one of: deny, ask, log, redact",
+				Value:   rule.Severity,
+				Wrapped: ErrInvalidConfig,
+			})
+		} else if rule.Severity == "redact" && rule.Kind != "" && rule.Kind != "read" {
+			errs = append(errs, ValidationError{
+				Field:   field + ".severity",
+				Message: "redact is only supported for read rules",
+				Value:   rule.Severity,
+				Wrapped: ErrInvalidConfig,
+			})
+		}
+
+		if rule.MinEntropy < 0 || rule.MinEntropy > 8 {
+			errs = append(errs, ValidationError{
+				Field:   field + ".min_entropy",
+				Message: "must be between 0 and 8",
+				Value:   rule.MinEntropy,
+				Wrapped: ErrInvalidConfig,
+			})
+		}
+
+		if rule.Pattern != "" {
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				errs = append(errs, ValidationError{
+					Field:   field + ".pattern",
+					Message: fmt.Sprintf("invalid regular expression: %v", err),
+					Value:   rule.Pattern,
+					Wrapped: ErrInvalidConfig,
+				})
+			}
+		}
+	}
+
+	for i, entry := range sc.Allowlist {
+		field := fmt.Sprintf("security.allowlist[%d]", i)
+
+		if len(entry.Paths) == 0 && len(entry.Commands) == 0 {
+			errs = append(errs, ValidationError{
+				Field:   field,
+				Message: "at least one of paths or commands is required",
+				Wrapped: ErrInvalidConfig,
+			})
+		}
+
+		for j, p := range entry.Paths {
+			if _, err := regexp.Compile(p); err != nil {
+				errs = append(errs, ValidationError{
+					Field:   fmt.Sprintf("%s.paths[%d]", field, j),
+					Message: fmt.Sprintf("invalid regular expression: %v", err),
+					Value:   p,
+					Wrapped: ErrInvalidConfig,
+				})
+			}
+		}
+
+		for j, c := range entry.Commands {
+			if _, err := regexp.Compile(c); err != nil {
+				errs = append(errs, ValidationError{
+					Field:   fmt.Sprintf("%s.commands[%d]", field, j),
+					Message: fmt.Sprintf("invalid regular expression: %v", err),
+					Value:   c,
+					Wrapped: ErrInvalidConfig,
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
 // validateDynamicTokens checks all string fields for unexpanded dynamic tokens.
 func validateDynamicTokens(cfg *Config) []ValidationError {
 	var errs []ValidationError
@@ -265,3 +537,30 @@ func developmentModeStrings() []string {
 	}
 	return strs
 }
+
+// validatePromptConfig checks the UserPromptSubmit hook configuration.
+func validatePromptConfig(pc *PromptConfig) []ValidationError {
+	var errs []ValidationError
+
+	if pc.TokenBudget < 0 {
+		errs = append(errs, ValidationError{
+			Field:   "prompt.token_budget",
+			Message: "must be non-negative",
+			Value:   pc.TokenBudget,
+			Wrapped: ErrInvalidConfig,
+		})
+	}
+
+	for i, pattern := range pc.Blocklist {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, ValidationError{
+				Field:   fmt.Sprintf("prompt.blocklist[%d]", i),
+				Message: fmt.Sprintf("invalid regular expression: %v", err),
+				Value:   pattern,
+				Wrapped: ErrInvalidConfig,
+			})
+		}
+	}
+
+	return errs
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/modu-ai/moai-adk/pkg/models"
@@ -190,6 +191,39 @@ func TestValidateTDDMinCoveragePerCommit(t *testing.T) {
 	}
 }
 
+func TestValidateTDDMutationScoreThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		value   int
+		wantErr bool
+	}{
+		{"0 is valid", 0, false},
+		{"70 is valid", 70, false},
+		{"-1 is invalid", -1, true},
+		{"101 is invalid", 101, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewDefaultConfig()
+			cfg.Quality.TDDSettings.MutationScoreThreshold = tt.value
+			loaded := map[string]bool{}
+
+			err := Validate(cfg, loaded)
+			if tt.wantErr && err == nil {
+				t.Errorf("expected error for MutationScoreThreshold %d", tt.value)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("expected no error for MutationScoreThreshold %d, got: %v", tt.value, err)
+			}
+		})
+	}
+}
+
 func TestValidateHybridMinCoverageNew(t *testing.T) {
 	t.Parallel()
 
@@ -292,6 +326,168 @@ func TestValidateMaxExemptPercentage(t *testing.T) {
 	}
 }
 
+func TestValidateStopGate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		maxBlocks int
+		timeout   int
+		wantErr   bool
+	}{
+		{"defaults are valid", 3, 300, false},
+		{"zero is valid", 0, 0, false},
+		{"negative max_blocks is invalid", -1, 300, true},
+		{"negative timeout is invalid", 3, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewDefaultConfig()
+			cfg.Quality.StopGate.MaxBlocks = tt.maxBlocks
+			cfg.Quality.StopGate.TimeoutSeconds = tt.timeout
+			loaded := map[string]bool{}
+
+			err := Validate(cfg, loaded)
+			if tt.wantErr && err == nil {
+				t.Errorf("expected error for stop gate %d/%d", tt.maxBlocks, tt.timeout)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("expected no error for stop gate %d/%d, got: %v", tt.maxBlocks, tt.timeout, err)
+			}
+		})
+	}
+}
+
+func TestValidatePromptConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		budget    int
+		blocklist []string
+		wantErr   bool
+	}{
+		{"defaults are valid", DefaultPromptTokenBudget, nil, false},
+		{"valid blocklist", 500, []string{`(?i)internal-only`}, false},
+		{"negative token_budget is invalid", -1, nil, true},
+		{"invalid blocklist regex", 500, []string{`([`}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewDefaultConfig()
+			cfg.Prompt.TokenBudget = tt.budget
+			cfg.Prompt.Blocklist = tt.blocklist
+
+			err := Validate(cfg, map[string]bool{})
+			if tt.wantErr && err == nil {
+				t.Errorf("expected error for prompt config %d/%v", tt.budget, tt.blocklist)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("expected no error for prompt config %d/%v, got: %v", tt.budget, tt.blocklist, err)
+			}
+		})
+	}
+}
+
+func TestValidateAutoFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		timeout     int
+		langTimeout int
+		wantErr     bool
+	}{
+		{"defaults are valid", 10, 0, false},
+		{"language override is valid", 10, 60, false},
+		{"negative timeout is invalid", -1, 0, true},
+		{"negative language timeout is invalid", 10, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewDefaultConfig()
+			cfg.Quality.AutoFormat.TimeoutSeconds = tt.timeout
+			cfg.Quality.AutoFormat.Languages = map[string]models.AutoFormatLanguage{
+				"go": {TimeoutSeconds: tt.langTimeout},
+			}
+			loaded := map[string]bool{}
+
+			err := Validate(cfg, loaded)
+			if tt.wantErr && err == nil {
+				t.Errorf("expected error for auto format %d/%d", tt.timeout, tt.langTimeout)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("expected no error for auto format %d/%d, got: %v", tt.timeout, tt.langTimeout, err)
+			}
+		})
+	}
+}
+
+func TestValidateFallbackDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	valid := models.FallbackDiagnosticsTool{
+		Name:       "luacheck",
+		Language:   "lua",
+		Extensions: []string{".lua"},
+		Command:    "luacheck",
+		Args:       []string{"--formatter", "plain", "{file}"},
+		Format:     "regex",
+		Pattern:    `^(?P<file>[^:]+):(?P<line>\d+):(?P<col>\d+): (?P<message>.+)$`,
+		Severity:   map[string]string{"default": "warning"},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*models.FallbackDiagnosticsTool)
+		wantErr string
+	}{
+		{"valid tool", func(*models.FallbackDiagnosticsTool) {}, ""},
+		{"sarif without pattern", func(tool *models.FallbackDiagnosticsTool) { tool.Format, tool.Pattern = "sarif", "" }, ""},
+		{"missing command", func(tool *models.FallbackDiagnosticsTool) { tool.Command = "" }, "tools[0].command"},
+		{"missing language", func(tool *models.FallbackDiagnosticsTool) { tool.Language = "" }, "tools[0].language"},
+		{"unknown format", func(tool *models.FallbackDiagnosticsTool) { tool.Format = "xml" }, "tools[0].format"},
+		{"invalid pattern", func(tool *models.FallbackDiagnosticsTool) { tool.Pattern = "(" }, "tools[0].pattern"},
+		{"pattern without message group", func(tool *models.FallbackDiagnosticsTool) { tool.Pattern = `^(?P<line>\d+)` }, "tools[0].pattern"},
+		{"unknown severity", func(tool *models.FallbackDiagnosticsTool) { tool.Severity = map[string]string{"e": "fatal"} }, "tools[0].severity.e"},
+		{"extension without dot", func(tool *models.FallbackDiagnosticsTool) { tool.Extensions = []string{"lua"} }, "tools[0].extensions[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tool := valid
+			tt.modify(&tool)
+			cfg := NewDefaultConfig()
+			cfg.Quality.FallbackDiagnostics.Tools = []models.FallbackDiagnosticsTool{tool}
+
+			err := Validate(cfg, map[string]bool{})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error for %s", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), "quality.fallback_diagnostics."+tt.wantErr) {
+				t.Errorf("error %q does not name %s", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateMultipleErrors(t *testing.T) {
 	t.Parallel()
 
@@ -681,3 +877,105 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestValidateSecurityConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		sec     SecurityConfig
+		wantErr bool
+	}{
+		{"empty is valid", SecurityConfig{}, false},
+		{
+			"override by id is valid",
+			SecurityConfig{Rules: []SecurityRuleConfig{{ID: "bash.terraform-destroy", Severity: "ask"}}},
+			false,
+		},
+		{
+			"custom rule is valid",
+			SecurityConfig{Rules: []SecurityRuleConfig{{ID: "custom.x", Kind: "bash", Pattern: `foo\s+bar`, Severity: "log"}}},
+			false,
+		},
+		{
+			"missing id",
+			SecurityConfig{Rules: []SecurityRuleConfig{{Kind: "bash", Pattern: "foo"}}},
+			true,
+		},
+		{
+			"duplicate id",
+			SecurityConfig{Rules: []SecurityRuleConfig{{ID: "a", Disabled: true}, {ID: "a", Disabled: true}}},
+			true,
+		},
+		{
+			"invalid kind",
+			SecurityConfig{Rules: []SecurityRuleConfig{{ID: "a", Kind: "network", Pattern: "x"}}},
+			true,
+		},
+		{
+			"invalid severity",
+			SecurityConfig{Rules: []SecurityRuleConfig{{ID: "a", Severity: "block"}}},
+			true,
+		},
+		{
+			"invalid pattern",
+			SecurityConfig{Rules: []SecurityRuleConfig{{ID: "a", Kind: "bash", Pattern: "(unclosed"}}},
+			true,
+		},
+		{
+			"read rule with redact is valid",
+			SecurityConfig{Rules: []SecurityRuleConfig{{ID: "custom.env", Kind: "read", Pattern: `\.env$`, Severity: "redact"}}},
+			false,
+		},
+		{
+			"redact on non-read rule",
+			SecurityConfig{Rules: []SecurityRuleConfig{{ID: "custom.x", Kind: "bash", Pattern: "x", Severity: "redact"}}},
+			true,
+		},
+		{
+			"entropy threshold is valid",
+			SecurityConfig{Rules: []SecurityRuleConfig{{ID: "content.high-entropy-string", MinEntropy: 4}}},
+			false,
+		},
+		{
+			"entropy threshold out of range",
+			SecurityConfig{Rules: []SecurityRuleConfig{{ID: "a", Kind: "content", Pattern: "x", MinEntropy: 9}}},
+			true,
+		},
+		{
+			"allowlist without paths or commands",
+			SecurityConfig{Allowlist: []SecurityAllowConfig{{Rules: []string{"a"}}}},
+			true,
+		},
+		{
+			"allowlist with invalid path regex",
+			SecurityConfig{Allowlist: []SecurityAllowConfig{{Paths: []string{"[z-a]"}}}},
+			true,
+		},
+		{
+			"allowlist with paths is valid",
+			SecurityConfig{Allowlist: []SecurityAllowConfig{{Rules: []string{"bash.terraform-destroy"}, Paths: []string{"^sandbox/"}}}},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := NewDefaultConfig()
+			cfg.Security = tt.sec
+
+			err := Validate(cfg, map[string]bool{})
+			if tt.wantErr && err == nil {
+				t.Error("Validate() expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Validate() expected no error, got: %v", err)
+			}
+			if tt.wantErr && err != nil && !errors.Is(err, ErrInvalidConfig) {
+				t.Errorf("Validate() error should wrap ErrInvalidConfig, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Package checks runs the build, test and lint commands of a project and
+// reads their output. The command templates of each language come from
+// foundation.LanguageRegistry; the Stop quality gate expands them for the
+// changed files and the Ralph feedback loop for the whole project.
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/modu-ai/moai-adk/internal/foundation"
+	"github.com/modu-ai/moai-adk/internal/shell"
+)
+
+// ErrEmptyCommand is returned by Argv for a blank command.
+var ErrEmptyCommand = errors.New("checks: empty command")
+
+// Kind is the kind of a check command.
+type Kind string
+
+// Kinds of check commands.
+const (
+	Build Kind = "build"
+	Test  Kind = "test"
+	Lint  Kind = "lint"
+)
+
+// Kinds lists the check kinds in the order they run.
+var Kinds = []Kind{Build, Test, Lint}
+
+// Placeholders of command templates, replaced with one argument per
+// checked entry.
+const (
+	FilesPlaceholder    = "{files}"
+	PackagesPlaceholder = "{packages}"
+	TestsPlaceholder    = "{tests}"
+)
+
+// Template returns the command template of kind for lang in registry, or
+// "" when the language has none. A nil registry uses
+// foundation.DefaultRegistry.
+func Template(registry *foundation.LanguageRegistry, lang foundation.SupportedLanguage, kind Kind) string {
+	if registry == nil {
+		registry = foundation.DefaultRegistry
+	}
+	info, err := registry.Get(lang)
+	if err != nil {
+		return ""
+	}
+	switch kind {
+	case Build:
+		return info.BuildCommand
+	case Test:
+		return info.TestCommand
+	default:
+		return info.LintCommand
+	}
+}
+
+// ProjectCommand expands template for the whole project: {packages} runs
+// every Go package, {files} the project directory, and {tests} is dropped
+// so that the test runner finds the tests itself.
+func ProjectCommand(template string) string {
+	fields := strings.Fields(template)
+	expanded := fields[:0]
+	for _, field := range fields {
+		switch field {
+		case PackagesPlaceholder:
+			expanded = append(expanded, "./...")
+		case FilesPlaceholder:
+			expanded = append(expanded, ".")
+		case TestsPlaceholder:
+		default:
+			expanded = append(expanded, field)
+		}
+	}
+	return strings.Join(expanded, " ")
+}
+
+// Argv returns the arguments to run command with: its words when it is a
+// simple command, otherwise "sh -c command" so that operators,
+// redirections and expansions keep working.
+func Argv(command string) ([]string, error) {
+	argv, err := shell.Split(command)
+	switch {
+	case errors.Is(err, shell.ErrNotSimple):
+		return []string{"sh", "-c", command}, nil
+	case err != nil:
+		return nil, fmt.Errorf("parse command %q: %w", command, err)
+	case len(argv) == 0:
+		return nil, ErrEmptyCommand
+	}
+	return argv, nil
+}
+
+// Run runs argv in dir and returns its combined output and exit code. The
+// error is set only when the command could not be run.
+func Run(ctx context.Context, dir string, argv []string) (string, int, error) {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return string(out), exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return string(out), -1, err
+	}
+	return string(out), 0, nil
+}
+
+// TestEvent is the part of a "go test -json" event the parsers read.
+type TestEvent struct {
+	Action  string `json:"Action"`
+	Package string `json:"Package"`
+	Test    string `json:"Test"`
+	Output  string `json:"Output"`
+}
+
+// TestOutput returns the text of "go test -json" output: the output of
+// its events and the lines that are not events, such as build errors.
+// Other output is returned unchanged.
+func TestOutput(output string) string {
+	if !strings.Contains(output, `"Action"`) {
+		return output
+	}
+	var text strings.Builder
+	for _, line := range strings.Split(output, "\n") {
+		var ev TestEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &ev); err == nil && ev.Action != "" {
+			text.WriteString(ev.Output)
+			continue
+		}
+		if line != "" {
+			text.WriteString(line + "\n")
+		}
+	}
+	return text.String()
+}
+
+// goCoverage matches the per-package coverage of "go test -cover".
+var goCoverage = regexp.MustCompile(`coverage: (\d+(?:\.\d+)?)% of statements`)
+
+// coveragePatterns match the total coverage printed by other tools, in
+// order: coverage.py, istanbul text, tarpaulin and PHPUnit.
+var coveragePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^TOTAL\s.*?(\d+(?:\.\d+)?)%\s*$`),
+	regexp.MustCompile(`(?m)^All files\s*\|\s*(\d+(?:\.\d+)?)`),
+	regexp.MustCompile(`(\d+(?:\.\d+)?)% coverage`),
+	regexp.MustCompile(`Lines:\s*(\d+(?:\.\d+)?)%`),
+}
+
+// ParseCoverage returns the coverage percentage printed in the output of
+// a test command. Go package coverages are averaged.
+func ParseCoverage(output string) (float64, bool) {
+	output = TestOutput(output)
+	if matches := goCoverage.FindAllStringSubmatch(output, -1); len(matches) > 0 {
+		var sum float64
+		for _, m := range matches {
+			v, _ := strconv.ParseFloat(m[1], 64)
+			sum += v
+		}
+		return sum / float64(len(matches)), true
+	}
+	for _, re := range coveragePatterns {
+		if m := re.FindStringSubmatch(output); m != nil {
+			if v, err := strconv.ParseFloat(m[1], 64); err == nil {
+				return v, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,119 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/foundation"
+)
+
+func TestTemplate(t *testing.T) {
+	t.Parallel()
+
+	if got := Template(nil, foundation.LangGo, Test); got != "go test -json -cover {packages}" {
+		t.Errorf("Go test template = %q", got)
+	}
+	if got := Template(nil, foundation.LangPython, Build); got != "" {
+		t.Errorf("Python build template = %q, want none", got)
+	}
+	if got := Template(nil, foundation.SupportedLanguage("cobol"), Lint); got != "" {
+		t.Errorf("unknown language template = %q, want none", got)
+	}
+}
+
+func TestProjectCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"go test -json -cover {packages}", "go test -json -cover ./..."},
+		{"ruff check {files}", "ruff check ."},
+		{"pytest -q {tests}", "pytest -q"},
+		{"cargo build --quiet", "cargo build --quiet"},
+	}
+	for _, tt := range tests {
+		if got := ProjectCommand(tt.template); got != tt.want {
+			t.Errorf("ProjectCommand(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestArgv(t *testing.T) {
+	t.Parallel()
+
+	got, err := Argv(`pytest -q -k "not slow"`)
+	if err != nil || !slices.Equal(got, []string{"pytest", "-q", "-k", "not slow"}) {
+		t.Errorf("Argv(simple) = %q, %v", got, err)
+	}
+	got, err = Argv("npm ci && npm test")
+	if err != nil || !slices.Equal(got, []string{"sh", "-c", "npm ci && npm test"}) {
+		t.Errorf("Argv(chain) = %q, %v; want it run by sh", got, err)
+	}
+	if _, err := Argv(`go test "./...`); err == nil {
+		t.Error("Argv(unterminated quote) error = nil")
+	}
+	if _, err := Argv("  "); !errors.Is(err, ErrEmptyCommand) {
+		t.Errorf("Argv(blank) error = %v, want ErrEmptyCommand", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	out, code, err := Run(context.Background(), t.TempDir(), []string{"sh", "-c", "echo out; exit 3"})
+	if err != nil || code != 3 || strings.TrimSpace(out) != "out" {
+		t.Errorf("Run() = %q, %d, %v; want out, 3", out, code, err)
+	}
+	if _, _, err := Run(context.Background(), t.TempDir(), []string{"moai-no-such-command"}); err == nil {
+		t.Error("Run(missing binary) error = nil")
+	}
+}
+
+func TestTestOutput(t *testing.T) {
+	t.Parallel()
+
+	output := `{"Action":"output","Test":"TestA","Output":"--- FAIL: TestA (0.00s)\n"}
+# example.com/calc
+calc.go:3:1: syntax error
+{"Action":"fail","Test":"TestA"}`
+	got := TestOutput(output)
+	want := "--- FAIL: TestA (0.00s)\n# example.com/calc\ncalc.go:3:1: syntax error\n"
+	if got != want {
+		t.Errorf("TestOutput() = %q, want %q", got, want)
+	}
+	if got := TestOutput("4 passed"); got != "4 passed" {
+		t.Errorf("TestOutput(text) = %q, want it unchanged", got)
+	}
+}
+
+func TestParseCoverage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		output string
+		want   float64
+		wantOK bool
+	}{
+		{"go packages averaged", "ok  a 0.1s coverage: 80.0% of statements\nok  b 0.1s coverage: 60.0% of statements", 70, true},
+		{"go json", `{"Action":"output","Output":"coverage: 75.5% of statements\n"}`, 75.5, true},
+		{"coverage.py", "Name    Stmts   Miss  Cover\nTOTAL      40      4    90%\n", 90, true},
+		{"istanbul", "File      | % Stmts\nAll files |   82.35 |", 82.35, true},
+		{"tarpaulin", "85.00% coverage, 34/40 lines covered", 85, true},
+		{"none", "all tests passed", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := ParseCoverage(tt.output)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("ParseCoverage() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
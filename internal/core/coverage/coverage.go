@@ -0,0 +1,235 @@
+// Package coverage measures test coverage from the reports of language
+// test runners: Go coverprofiles, LCOV, Cobertura XML and coverage.py
+// JSON. Reports are reduced to line coverage, from which it computes
+// project, per-package and changed-lines-only ("new code") coverage
+// against a git base ref, honoring justified coverage exemptions.
+package coverage
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path"
+	"sort"
+
+	"github.com/modu-ai/moai-adk/internal/core/quality"
+	"github.com/modu-ai/moai-adk/internal/loop"
+)
+
+// Sentinel errors for coverage operations.
+var (
+	ErrUnknownFormat = errors.New("coverage: unrecognized report format")
+	ErrNoData        = errors.New("coverage: report contains no coverage data")
+)
+
+// LineHits maps the instrumented lines of a file to their hit counts.
+type LineHits map[int]int
+
+// Profile is the line coverage of a project, keyed by slash-separated
+// path relative to the project root.
+type Profile struct {
+	Format Format
+	Files  map[string]LineHits
+}
+
+// newProfile creates an empty profile of format.
+func newProfile(format Format) *Profile {
+	return &Profile{Format: format, Files: make(map[string]LineHits)}
+}
+
+// add records hits for line of file. A line reported more than once keeps
+// its highest count, so a line is covered when any report covers it.
+func (p *Profile) add(file string, line, hits int) {
+	if line <= 0 {
+		return
+	}
+	lines := p.Files[file]
+	if lines == nil {
+		lines = make(LineHits)
+		p.Files[file] = lines
+	}
+	if prev, ok := lines[line]; !ok || hits > prev {
+		lines[line] = hits
+	}
+}
+
+// Merge adds the lines of other to p, e.g. to combine the reports of
+// several languages.
+func (p *Profile) Merge(other *Profile) {
+	for file, lines := range other.Files {
+		for line, hits := range lines {
+			p.add(file, line, hits)
+		}
+	}
+	if p.Format != other.Format {
+		p.Format = FormatMixed
+	}
+}
+
+// Summary counts covered and instrumented lines.
+type Summary struct {
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+}
+
+// Percent returns the covered share of lines as a percentage; a summary
+// without instrumented lines has nothing to cover and returns 100.
+func (s Summary) Percent() float64 {
+	if s.Total == 0 {
+		return 100
+	}
+	return float64(s.Covered) * 100 / float64(s.Total)
+}
+
+// WholePercent returns Percent rounded down, for the integer thresholds
+// of the quality configuration: 84.9% does not meet an 85% target.
+func (s Summary) WholePercent() int {
+	return int(math.Floor(s.Percent()))
+}
+
+// count adds the lines of hits selected by keep to s.
+func (s *Summary) count(hits LineHits, keep func(line int) bool) {
+	for line, n := range hits {
+		if keep != nil && !keep(line) {
+			continue
+		}
+		s.Total++
+		if n > 0 {
+			s.Covered++
+		}
+	}
+}
+
+// PackageSummary is the coverage of one package (directory).
+type PackageSummary struct {
+	Name string `json:"name"`
+	Summary
+}
+
+// Policy holds the coverage exemption rules of quality.yaml.
+type Policy struct {
+	Enabled              bool
+	RequireJustification bool
+	MaxExemptPercentage  int
+}
+
+// Options configures Analyze.
+type Options struct {
+	// Root is the project root the profile paths are relative to.
+	Root string
+
+	// BaseRef is the git ref new code is measured against; empty skips
+	// new-code coverage.
+	BaseRef string
+
+	// Exemptions controls coverage:ignore markers in the source files.
+	Exemptions Policy
+}
+
+// ExemptionReport describes the coverage exemptions found in the source.
+type ExemptionReport struct {
+	// Lines is the number of instrumented lines exempted.
+	Lines int `json:"lines"`
+
+	// Percent is Lines as a share of all instrumented lines.
+	Percent float64 `json:"percent"`
+
+	// Rejected is set when Percent exceeds the configured maximum; the
+	// exemptions are then not applied.
+	Rejected bool `json:"rejected,omitempty"`
+
+	// Unjustified lists markers ignored for lacking a justification as
+	// "file:line".
+	Unjustified []string `json:"unjustified,omitempty"`
+}
+
+// Result is the measured coverage of a project.
+type Result struct {
+	Format   Format           `json:"format"`
+	Project  Summary          `json:"project"`
+	Packages []PackageSummary `json:"packages"`
+
+	// NewCode covers the lines added or changed since Base, and Legacy
+	// the whole of the pre-existing files that were modified. Both are
+	// nil without a base ref.
+	NewCode *Summary `json:"new_code,omitempty"`
+	Legacy  *Summary `json:"legacy,omitempty"`
+	Base    string   `json:"base,omitempty"`
+	Changes *Changes `json:"-"`
+
+	Exemptions ExemptionReport `json:"exemptions"`
+}
+
+// Analyze computes the coverage of profile. Exempted lines are removed
+// before any summary is computed.
+func Analyze(ctx context.Context, profile *Profile, opts Options) (*Result, error) {
+	if len(profile.Files) == 0 {
+		return nil, ErrNoData
+	}
+	result := &Result{Format: profile.Format}
+
+	files := profile.Files
+	if opts.Exemptions.Enabled {
+		files = applyExemptions(opts.Root, profile.Files, opts.Exemptions, &result.Exemptions)
+	}
+
+	packages := make(map[string]*Summary)
+	for file, hits := range files {
+		result.Project.count(hits, nil)
+		dir := path.Dir(file)
+		if packages[dir] == nil {
+			packages[dir] = &Summary{}
+		}
+		packages[dir].count(hits, nil)
+	}
+	for name, s := range packages {
+		result.Packages = append(result.Packages, PackageSummary{Name: name, Summary: *s})
+	}
+	sort.Slice(result.Packages, func(i, j int) bool { return result.Packages[i].Name < result.Packages[j].Name })
+
+	if opts.BaseRef == "" {
+		return result, nil
+	}
+	changes, err := ChangedLines(ctx, opts.Root, opts.BaseRef)
+	if err != nil {
+		return nil, err
+	}
+	result.Base = opts.BaseRef
+	result.Changes = changes
+	result.NewCode = &Summary{}
+	result.Legacy = &Summary{}
+	for file, lines := range changes.Lines {
+		result.NewCode.count(files[file], func(line int) bool { return lines[line] })
+	}
+	for _, file := range changes.ModifiedFiles {
+		result.Legacy.count(files[file], nil)
+	}
+	return result, nil
+}
+
+// MethodologyContext returns the coverage inputs of the TDD and hybrid
+// quality rules: commit and new-code coverage are the changed-lines
+// coverage, legacy coverage that of the modified files. Without a base
+// ref only the commit coverage is set, from the project coverage.
+func (r *Result) MethodologyContext() *quality.MethodologyContext {
+	mCtx := &quality.MethodologyContext{
+		CommitCoverage:             r.Project.WholePercent(),
+		CoverageExemptionRequested: r.Exemptions.Lines > 0 && !r.Exemptions.Rejected,
+	}
+	if r.NewCode == nil {
+		return mCtx
+	}
+	mCtx.CommitCoverage = r.NewCode.WholePercent()
+	mCtx.NewCodeCoverage = r.NewCode.WholePercent()
+	mCtx.LegacyCodeCoverage = r.Legacy.WholePercent()
+	mCtx.Changes = &quality.ChangeClassification{
+		NewFiles:      r.Changes.NewFiles,
+		ModifiedFiles: r.Changes.ModifiedFiles,
+	}
+	return mCtx
+}
+
+// ApplyToFeedback records the project coverage in fb.
+func (r *Result) ApplyToFeedback(fb *loop.Feedback) {
+	fb.Coverage = r.Project.Percent()
+}
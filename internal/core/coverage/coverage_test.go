@@ -0,0 +1,126 @@
+package coverage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/loop"
+)
+
+func TestSummary_Percent(t *testing.T) {
+	tests := []struct {
+		s     Summary
+		want  float64
+		whole int
+	}{
+		{Summary{Covered: 3, Total: 4}, 75, 75},
+		{Summary{Covered: 849, Total: 1000}, 84.9, 84},
+		{Summary{}, 100, 100},
+	}
+	for _, tt := range tests {
+		if got := tt.s.Percent(); got != tt.want {
+			t.Errorf("%+v.Percent() = %v, want %v", tt.s, got, tt.want)
+		}
+		if got := tt.s.WholePercent(); got != tt.whole {
+			t.Errorf("%+v.WholePercent() = %d, want %d", tt.s, got, tt.whole)
+		}
+	}
+}
+
+func TestProfile_Merge(t *testing.T) {
+	p := newProfile(FormatGo)
+	p.add("a.go", 1, 0)
+	other := newProfile(FormatLCOV)
+	other.add("a.go", 1, 2)
+	other.add("b.js", 1, 0)
+	p.Merge(other)
+	if p.Format != FormatMixed || p.Files["a.go"][1] != 2 || len(p.Files) != 2 {
+		t.Errorf("merged = %+v", p)
+	}
+}
+
+func TestAnalyze_Packages(t *testing.T) {
+	p := newProfile(FormatGo)
+	for l, hits := range map[int]int{1: 1, 2: 1, 3: 0} {
+		p.add("calc/calc.go", l, hits)
+	}
+	p.add("calc/util.go", 1, 1)
+	p.add("main.go", 1, 0)
+
+	result, err := Analyze(context.Background(), p, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Project != (Summary{Covered: 3, Total: 5}) {
+		t.Errorf("Project = %+v, want 3/5", result.Project)
+	}
+	want := []PackageSummary{{".", Summary{0, 1}}, {"calc", Summary{3, 4}}}
+	if len(result.Packages) != 2 || result.Packages[0] != want[0] || result.Packages[1] != want[1] {
+		t.Errorf("Packages = %+v, want %+v", result.Packages, want)
+	}
+	if result.NewCode != nil || result.MethodologyContext().Changes != nil {
+		t.Error("want no new-code coverage without a base ref")
+	}
+	if mCtx := result.MethodologyContext(); mCtx.CommitCoverage != 60 {
+		t.Errorf("CommitCoverage = %d, want the project coverage 60", mCtx.CommitCoverage)
+	}
+
+	fb := &loop.Feedback{}
+	result.ApplyToFeedback(fb)
+	if fb.Coverage != 60 {
+		t.Errorf("Feedback.Coverage = %v, want 60", fb.Coverage)
+	}
+
+	if _, err := Analyze(context.Background(), newProfile(FormatGo), Options{}); !errors.Is(err, ErrNoData) {
+		t.Errorf("empty profile error = %v", err)
+	}
+}
+
+func TestAnalyze_NewCode(t *testing.T) {
+	root := initRepo(t, map[string]string{
+		"calc/calc.go": "package calc\n\nfunc A() int {\n\treturn 1\n}\n",
+	})
+	writeFiles(t, root, map[string]string{
+		"calc/calc.go": "package calc\n\nfunc A() int {\n\treturn 1\n}\n\nfunc B() int {\n\treturn 2\n}\n",
+		"calc/new.go":  "package calc\n\nfunc C() int {\n\treturn 3 // coverage:ignore exercised by the e2e suite\n}\n",
+	})
+	p := newProfile(FormatGo)
+	for l, hits := range map[int]int{3: 1, 4: 1, 5: 1, 7: 0, 8: 0, 9: 0} {
+		p.add("calc/calc.go", l, hits)
+	}
+	for l, hits := range map[int]int{3: 1, 4: 0, 5: 1} {
+		p.add("calc/new.go", l, hits)
+	}
+
+	result, err := Analyze(context.Background(), p, Options{
+		Root:       root,
+		BaseRef:    "main",
+		Exemptions: Policy{Enabled: true, RequireJustification: true, MaxExemptPercentage: 20},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// new.go line 4 is exempt: 11.1% of 9 lines, within the 20% maximum.
+	if result.Exemptions.Lines != 1 || result.Exemptions.Rejected {
+		t.Errorf("Exemptions = %+v, want one applied line", result.Exemptions)
+	}
+	if result.Project != (Summary{Covered: 5, Total: 8}) {
+		t.Errorf("Project = %+v, want 5/8", result.Project)
+	}
+	// Changed lines: calc.go 6-9 (7-9 instrumented, uncovered) and new.go.
+	if *result.NewCode != (Summary{Covered: 2, Total: 5}) {
+		t.Errorf("NewCode = %+v, want 2/5", *result.NewCode)
+	}
+	if *result.Legacy != (Summary{Covered: 3, Total: 6}) {
+		t.Errorf("Legacy = %+v, want 3/6", *result.Legacy)
+	}
+
+	mCtx := result.MethodologyContext()
+	if mCtx.NewCodeCoverage != 40 || mCtx.LegacyCodeCoverage != 50 || mCtx.CommitCoverage != 40 || !mCtx.CoverageExemptionRequested {
+		t.Errorf("MethodologyContext = %+v", mCtx)
+	}
+	if len(mCtx.Changes.NewFiles) != 1 || len(mCtx.Changes.ModifiedFiles) != 1 {
+		t.Errorf("Changes = %+v", mCtx.Changes)
+	}
+}
@@ -0,0 +1,180 @@
+package coverage
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Changes lists the lines of the working tree added or changed since a
+// base ref. Deleted files and removed lines are not included.
+type Changes struct {
+	// Lines maps each changed file to its added or changed lines.
+	Lines map[string]map[int]bool
+
+	// NewFiles are files that did not exist at the base, including
+	// untracked files; ModifiedFiles existed and were changed.
+	NewFiles      []string
+	ModifiedFiles []string
+}
+
+// ChangedLines compares the working tree of root with the merge base of
+// base and HEAD, or with base itself when they share no history.
+// Untracked files that are not ignored count as new. When root is a
+// subdirectory of the repository, as in a monorepo, only its changes are
+// listed and paths are relative to root.
+func ChangedLines(ctx context.Context, root, base string) (*Changes, error) {
+	if _, err := runGit(ctx, root, "rev-parse", "--verify", "--quiet", base+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("coverage: unknown base ref %q", base)
+	}
+	target := base
+	if out, err := runGit(ctx, root, "merge-base", base, "HEAD"); err == nil && strings.TrimSpace(out) != "" {
+		target = strings.TrimSpace(out)
+	}
+	diff, err := runGit(ctx, root, "diff", "--relative", "--no-color", "--no-ext-diff", "--find-renames", "--src-prefix=a/", "--dst-prefix=b/", "-U0", target, "--")
+	if err != nil {
+		return nil, fmt.Errorf("coverage: diff against %s: %w", base, err)
+	}
+	changes := parseUnifiedDiff(diff)
+
+	untracked, err := runGit(ctx, root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("coverage: list untracked files: %w", err)
+	}
+	for _, file := range strings.Split(untracked, "\x00") {
+		if file == "" {
+			continue
+		}
+		n, err := countLines(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil {
+			continue
+		}
+		lines := make(map[int]bool, n)
+		for l := 1; l <= n; l++ {
+			lines[l] = true
+		}
+		changes.Lines[file] = lines
+		changes.NewFiles = append(changes.NewFiles, file)
+	}
+	sort.Strings(changes.NewFiles)
+	return changes, nil
+}
+
+// parseUnifiedDiff collects the added lines of a zero-context unified
+// diff. Renamed and copied files count as modified.
+func parseUnifiedDiff(diff string) *Changes {
+	changes := &Changes{Lines: make(map[string]map[int]bool)}
+	var (
+		file    string
+		newFile bool
+	)
+	flush := func() {
+		if file == "" {
+			return
+		}
+		if newFile {
+			changes.NewFiles = append(changes.NewFiles, file)
+		} else {
+			changes.ModifiedFiles = append(changes.ModifiedFiles, file)
+		}
+		file = ""
+	}
+	scanner := bufio.NewScanner(strings.NewReader(diff))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			newFile = false
+		case strings.HasPrefix(line, "new file mode"):
+			newFile = true
+		case strings.HasPrefix(line, "+++ "):
+			name := strings.TrimPrefix(line, "+++ ")
+			if name == "/dev/null" {
+				continue
+			}
+			file = strings.TrimPrefix(unquoteGitPath(name), "b/")
+		case strings.HasPrefix(line, "@@ ") && file != "":
+			start, count, ok := parseHunkHeader(line)
+			if !ok || count == 0 {
+				continue
+			}
+			if changes.Lines[file] == nil {
+				changes.Lines[file] = make(map[int]bool)
+			}
+			for l := start; l < start+count; l++ {
+				changes.Lines[file][l] = true
+			}
+		}
+	}
+	flush()
+	return changes
+}
+
+// parseHunkHeader returns the new-file range of "@@ -a,b +c,d @@".
+func parseHunkHeader(line string) (start, count int, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0, false
+	}
+	from, n, hasCount := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+	start, err := strconv.Atoi(from)
+	if err != nil {
+		return 0, 0, false
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(n); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, count, true
+}
+
+// unquoteGitPath undoes the C-style quoting git applies to unusual paths.
+func unquoteGitPath(name string) string {
+	if strings.HasPrefix(name, `"`) {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			return unquoted
+		}
+	}
+	return name
+}
+
+// countLines returns the number of lines of the file at path.
+func countLines(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+	n := strings.Count(string(data), "\n")
+	if data[len(data)-1] != '\n' {
+		n++
+	}
+	return n, nil
+}
+
+// runGit runs git in root and returns its standard output.
+func runGit(ctx context.Context, root string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", root}, args...)...)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,161 @@
+package coverage
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseUnifiedDiff(t *testing.T) {
+	diff := `diff --git a/calc.go b/calc.go
+index 1111111..2222222 100644
+--- a/calc.go
++++ b/calc.go
+@@ -3,0 +4,2 @@ func Add(a, b int) int {
++	// added
++	x := 1
+@@ -10 +12 @@ func Sub(a, b int) int {
+-	return a - b
++	return b - a
+@@ -20,3 +21,0 @@ func Mul(a, b int) int {
+-	gone
+-	gone
+-	gone
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,3 @@
++package calc
++
++func New() {}
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package calc
+-
+`
+	changes := parseUnifiedDiff(diff)
+	calc := changes.Lines["calc.go"]
+	if len(calc) != 3 || !calc[4] || !calc[5] || !calc[12] {
+		t.Errorf("calc.go lines = %v, want 4, 5 and 12", calc)
+	}
+	if len(changes.Lines["new.go"]) != 3 {
+		t.Errorf("new.go lines = %v, want 1-3", changes.Lines["new.go"])
+	}
+	if !slices.Equal(changes.NewFiles, []string{"new.go"}) || !slices.Equal(changes.ModifiedFiles, []string{"calc.go"}) {
+		t.Errorf("new = %v, modified = %v", changes.NewFiles, changes.ModifiedFiles)
+	}
+}
+
+func TestParseHunkHeader(t *testing.T) {
+	tests := []struct {
+		line         string
+		start, count int
+		ok           bool
+	}{
+		{"@@ -1,2 +3,4 @@", 3, 4, true},
+		{"@@ -1 +7 @@ func f()", 7, 1, true},
+		{"@@ -5,2 +4,0 @@", 4, 0, true},
+		{"@@ garbage", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, count, ok := parseHunkHeader(tt.line)
+		if start != tt.start || count != tt.count || ok != tt.ok {
+			t.Errorf("parseHunkHeader(%q) = %d, %d, %v", tt.line, start, count, ok)
+		}
+	}
+}
+
+// initRepo creates a git repository in a temp dir with files committed on
+// its main branch and returns its root.
+func initRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	writeFiles(t, root, files)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return root
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestChangedLines(t *testing.T) {
+	root := initRepo(t, map[string]string{"calc/calc.go": "package calc\n\nfunc A() {}\n"})
+	writeFiles(t, root, map[string]string{
+		"calc/calc.go": "package calc\n\nfunc A() {}\n\nfunc B() {}\n",
+		"calc/new.go":  "package calc\n\nfunc C() {}",
+	})
+
+	changes, err := ChangedLines(context.Background(), root, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := changes.Lines["calc/calc.go"]; len(got) != 2 || !got[4] || !got[5] {
+		t.Errorf("calc.go lines = %v, want 4 and 5", got)
+	}
+	if got := changes.Lines["calc/new.go"]; len(got) != 3 {
+		t.Errorf("untracked new.go lines = %v, want 1-3", got)
+	}
+	if !slices.Equal(changes.NewFiles, []string{"calc/new.go"}) || !slices.Equal(changes.ModifiedFiles, []string{"calc/calc.go"}) {
+		t.Errorf("new = %v, modified = %v", changes.NewFiles, changes.ModifiedFiles)
+	}
+
+	if _, err := ChangedLines(context.Background(), root, "no-such-ref"); err == nil {
+		t.Error("want error for an unknown base ref")
+	}
+}
+
+func TestChangedLines_ProjectInSubdirectory(t *testing.T) {
+	repo := initRepo(t, map[string]string{
+		"services/api/calc/calc.go": "package calc\n\nfunc A() {}\n",
+		"web/app.ts":                "export {}\n",
+	})
+	writeFiles(t, repo, map[string]string{
+		"services/api/calc/calc.go": "package calc\n\nfunc A() {}\n\nfunc B() {}\n",
+		"services/api/calc/new.go":  "package calc\n",
+		"web/app.ts":                "export {}\nexport const x = 1\n",
+		"web/new.ts":                "export {}\n",
+	})
+
+	changes, err := ChangedLines(context.Background(), filepath.Join(repo, "services", "api"), "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(changes.ModifiedFiles, []string{"calc/calc.go"}) || !slices.Equal(changes.NewFiles, []string{"calc/new.go"}) {
+		t.Errorf("new = %v, modified = %v; want paths relative to the project", changes.NewFiles, changes.ModifiedFiles)
+	}
+	if got := changes.Lines["calc/calc.go"]; len(got) != 2 || !got[4] || !got[5] {
+		t.Errorf("calc.go lines = %v, want 4 and 5", got)
+	}
+	if len(changes.Lines) != 2 {
+		t.Errorf("lines = %v, want only the project's files", changes.Lines)
+	}
+}
@@ -0,0 +1,169 @@
+package coverage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Coverage exemption markers, written in a comment and followed by the
+// justification when one is required. The -file marker exempts its file,
+// -start and -end exempt the lines between them, and the plain marker
+// exempts its own line, or the next line when the comment stands alone.
+const (
+	markerIgnore      = "coverage:ignore"
+	markerIgnoreFile  = "coverage:ignore-file"
+	markerIgnoreStart = "coverage:ignore-start"
+	markerIgnoreEnd   = "coverage:ignore-end"
+)
+
+// commentTokens are the comment openers a marker may follow.
+var commentTokens = []string{"//", "#", "/*", "*", "--", ";", "<!--"}
+
+// applyExemptions returns files without the lines exempted in the source
+// files under root, filling report. Markers lacking a required
+// justification are ignored; when the exempted share of instrumented lines
+// exceeds the policy maximum, no exemption is applied.
+func applyExemptions(root string, files map[string]LineHits, policy Policy, report *ExemptionReport) map[string]LineHits {
+	exempt := make(map[string]map[int]bool)
+	var total int
+	names := make([]string, 0, len(files))
+	for file, hits := range files {
+		total += len(hits)
+		names = append(names, file)
+	}
+	sort.Strings(names)
+
+	for _, file := range names {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+		if err != nil {
+			continue
+		}
+		lines, unjustified := scanExemptions(string(data), policy.RequireJustification)
+		for _, l := range unjustified {
+			report.Unjustified = append(report.Unjustified, fmt.Sprintf("%s:%d", file, l))
+		}
+		for l := range lines {
+			if _, ok := files[file][l]; ok {
+				if exempt[file] == nil {
+					exempt[file] = make(map[int]bool)
+				}
+				exempt[file][l] = true
+				report.Lines++
+			}
+		}
+	}
+	if report.Lines == 0 || total == 0 {
+		return files
+	}
+	report.Percent = float64(report.Lines) * 100 / float64(total)
+	if report.Percent > float64(policy.MaxExemptPercentage) {
+		report.Rejected = true
+		return files
+	}
+
+	kept := make(map[string]LineHits, len(files))
+	for file, hits := range files {
+		if exempt[file] == nil {
+			kept[file] = hits
+			continue
+		}
+		remaining := make(LineHits, len(hits))
+		for l, n := range hits {
+			if !exempt[file][l] {
+				remaining[l] = n
+			}
+		}
+		if len(remaining) > 0 {
+			kept[file] = remaining
+		}
+	}
+	return kept
+}
+
+// scanExemptions returns the lines of src exempted by markers and the
+// lines of markers ignored for lacking a justification. An unterminated
+// ignore-start block runs to the end of the file.
+func scanExemptions(src string, requireJustification bool) (map[int]bool, []int) {
+	lines := strings.Split(src, "\n")
+	exempt := make(map[int]bool)
+	var unjustified []int
+	blockStart := 0
+	for i, line := range lines {
+		n := i + 1
+		marker, reason, commentOnly, ok := findMarker(line)
+		if !ok {
+			if blockStart > 0 {
+				exempt[n] = true
+			}
+			continue
+		}
+		if marker == markerIgnoreEnd {
+			blockStart = 0
+			continue
+		}
+		if requireJustification && reason == "" {
+			unjustified = append(unjustified, n)
+			if blockStart > 0 {
+				exempt[n] = true
+			}
+			continue
+		}
+		switch marker {
+		case markerIgnoreFile:
+			for l := 1; l <= len(lines); l++ {
+				exempt[l] = true
+			}
+			return exempt, unjustified
+		case markerIgnoreStart:
+			blockStart = n
+			exempt[n] = true
+		default:
+			exempt[n] = true
+			if commentOnly {
+				exempt[n+1] = true
+			}
+		}
+	}
+	return exempt, unjustified
+}
+
+// findMarker locates a coverage exemption marker written in a comment of
+// line. It returns the marker, its justification and whether the line
+// holds only the comment.
+func findMarker(line string) (marker, reason string, commentOnly, ok bool) {
+	idx := strings.Index(line, markerIgnore)
+	if idx < 0 {
+		return "", "", false, false
+	}
+	before := strings.TrimRight(line[:idx], " \t")
+	token := ""
+	for _, t := range commentTokens {
+		if strings.HasSuffix(before, t) && len(t) > len(token) {
+			token = t
+		}
+	}
+	if token == "" {
+		return "", "", false, false
+	}
+
+	rest := line[idx+len(markerIgnore):]
+	marker = markerIgnore
+	for _, m := range []string{markerIgnoreStart, markerIgnoreEnd, markerIgnoreFile} {
+		if suffix := strings.TrimPrefix(m, markerIgnore); strings.HasPrefix(rest, suffix) {
+			marker = m
+			rest = strings.TrimPrefix(rest, suffix)
+			break
+		}
+	}
+	if rest != "" && !strings.ContainsAny(rest[:1], " \t:") {
+		return "", "", false, false
+	}
+	reason = strings.TrimSpace(rest)
+	reason = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(reason, "-->"), "*/"))
+	reason = strings.TrimSpace(strings.TrimLeft(reason, ":-"))
+	commentOnly = strings.TrimSpace(strings.TrimSuffix(before, token)) == ""
+	return marker, reason, commentOnly, true
+}
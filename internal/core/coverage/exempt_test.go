@@ -0,0 +1,108 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const exemptSource = `package calc
+
+func Div(a, b int) int {
+	if b == 0 {
+		panic("division by zero") // coverage:ignore guarded by callers
+	}
+	// coverage:ignore
+	return a / b
+}
+
+// coverage:ignore-start platform fallback
+func fallback() {
+}
+// coverage:ignore-end
+
+var s = "coverage:ignore not a comment"
+`
+
+func TestScanExemptions(t *testing.T) {
+	exempt, unjustified := scanExemptions(exemptSource, true)
+	for _, line := range []int{5, 11, 12, 13} {
+		if !exempt[line] {
+			t.Errorf("line %d not exempt", line)
+		}
+	}
+	for _, line := range []int{4, 8, 14, 16} {
+		if exempt[line] {
+			t.Errorf("line %d exempt, want covered", line)
+		}
+	}
+	if len(unjustified) != 1 || unjustified[0] != 7 {
+		t.Errorf("unjustified = %v, want [7]", unjustified)
+	}
+
+	exempt, unjustified = scanExemptions(exemptSource, false)
+	if !exempt[7] || !exempt[8] || len(unjustified) != 0 {
+		t.Errorf("without required justification: line 8 exempt = %v, unjustified = %v", exempt[8], unjustified)
+	}
+}
+
+func TestScanExemptions_File(t *testing.T) {
+	exempt, _ := scanExemptions("# coverage:ignore-file: generated by protoc\nx = 1\ny = 2\n", true)
+	if !exempt[2] || !exempt[3] {
+		t.Errorf("exempt = %v, want the whole file", exempt)
+	}
+}
+
+func TestFindMarker(t *testing.T) {
+	tests := []struct {
+		line        string
+		wantMarker  string
+		wantReason  string
+		commentOnly bool
+		ok          bool
+	}{
+		{"x() // coverage:ignore - unreachable", markerIgnore, "unreachable", false, true},
+		{"  # coverage:ignore-start: legacy", markerIgnoreStart, "legacy", true, true},
+		{"/* coverage:ignore-end */", markerIgnoreEnd, "", true, true},
+		{"<!-- coverage:ignore-file vendored -->", markerIgnoreFile, "vendored", true, true},
+		{`s := "coverage:ignore"`, "", "", false, false},
+		{"// coverage:ignored", "", "", false, false},
+	}
+	for _, tt := range tests {
+		marker, reason, commentOnly, ok := findMarker(tt.line)
+		if marker != tt.wantMarker || reason != tt.wantReason || commentOnly != tt.commentOnly || ok != tt.ok {
+			t.Errorf("findMarker(%q) = %q, %q, %v, %v; want %q, %q, %v, %v", tt.line,
+				marker, reason, commentOnly, ok, tt.wantMarker, tt.wantReason, tt.commentOnly, tt.ok)
+		}
+	}
+}
+
+func TestApplyExemptions(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "calc.go"), []byte(exemptSource), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// 20 instrumented lines, of which line 5 is exempt: 5%.
+	files := map[string]LineHits{"calc.go": {4: 1, 5: 0, 8: 1}, "other.go": {}}
+	for l := 1; l <= 17; l++ {
+		files["other.go"][l] = 1
+	}
+
+	var report ExemptionReport
+	kept := applyExemptions(root, files, Policy{Enabled: true, RequireJustification: true, MaxExemptPercentage: 5}, &report)
+	if report.Lines != 1 || report.Percent != 5 || report.Rejected {
+		t.Errorf("report = %+v, want 1 line (5%%) applied", report)
+	}
+	if _, ok := kept["calc.go"][5]; ok || len(kept["calc.go"]) != 2 {
+		t.Errorf("kept calc.go = %v, want line 5 removed", kept["calc.go"])
+	}
+	if len(report.Unjustified) != 1 || report.Unjustified[0] != "calc.go:7" {
+		t.Errorf("unjustified = %v", report.Unjustified)
+	}
+
+	report = ExemptionReport{}
+	kept = applyExemptions(root, files, Policy{Enabled: true, MaxExemptPercentage: 5}, &report)
+	if !report.Rejected || len(kept["calc.go"]) != 3 {
+		t.Errorf("report = %+v, kept = %v; want lines 5 and 8 (10%%) rejected", report, kept["calc.go"])
+	}
+}
@@ -0,0 +1,315 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Format names a coverage report format.
+type Format string
+
+const (
+	FormatGo         Format = "go"
+	FormatLCOV       Format = "lcov"
+	FormatCobertura  Format = "cobertura"
+	FormatCoveragePy Format = "coverage.py"
+
+	// FormatMixed marks a profile merged from reports of several formats.
+	FormatMixed Format = "mixed"
+)
+
+// DefaultReportPaths are the report locations test runners write by
+// default, relative to the project root.
+var DefaultReportPaths = []string{
+	"coverage.out",
+	"cover.out",
+	"lcov.info",
+	"coverage/lcov.info",
+	"coverage.xml",
+	"coverage/cobertura-coverage.xml",
+	"coverage.json",
+}
+
+// FindReports returns the DefaultReportPaths that exist under root.
+func FindReports(root string) []string {
+	var found []string
+	for _, rel := range DefaultReportPaths {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			found = append(found, path)
+		}
+	}
+	return found
+}
+
+// DetectFormat identifies the format of a coverage report from its
+// content, or returns "" when it is not recognized.
+func DetectFormat(data []byte) Format {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return FormatGo
+	case bytes.HasPrefix(trimmed, []byte("<")) && bytes.Contains(trimmed, []byte("<coverage")):
+		return FormatCobertura
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatCoveragePy
+	case bytes.HasPrefix(trimmed, []byte("TN:")) || bytes.HasPrefix(trimmed, []byte("SF:")) ||
+		bytes.Contains(trimmed, []byte("\nSF:")):
+		return FormatLCOV
+	}
+	return ""
+}
+
+// ParseFile reads the coverage report at path. File names are made
+// relative to root.
+func ParseFile(path, root string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("coverage: read report: %w", err)
+	}
+	profile, err := Parse(data, root)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// Parse decodes a coverage report in any supported format. File names are
+// made relative to root; Go import paths are resolved through the module
+// path of root/go.mod.
+func Parse(data []byte, root string) (*Profile, error) {
+	var (
+		profile *Profile
+		err     error
+	)
+	switch DetectFormat(data) {
+	case FormatGo:
+		profile, err = parseGo(data, root)
+	case FormatLCOV:
+		profile, err = parseLCOV(data, root)
+	case FormatCobertura:
+		profile, err = parseCobertura(data, root)
+	case FormatCoveragePy:
+		profile, err = parseCoveragePy(data, root)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(profile.Files) == 0 {
+		return nil, ErrNoData
+	}
+	return profile, nil
+}
+
+// parseGo decodes a Go coverprofile. Each block
+// "name.go:line.col,line.col statements count" marks the lines it spans;
+// blocks without statements are skipped.
+func parseGo(data []byte, root string) (*Profile, error) {
+	profile := newProfile(FormatGo)
+	modulePath := goModulePath(root)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		colon := strings.LastIndex(line, ":")
+		fields := strings.Fields(line[colon+1:])
+		if colon < 0 || len(fields) != 3 {
+			return nil, fmt.Errorf("coverage: go profile line %d: malformed block %q", n, line)
+		}
+		start, end, ok := parseGoBlockRange(fields[0])
+		stmts, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if !ok || err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("coverage: go profile line %d: malformed block %q", n, line)
+		}
+		if stmts == 0 {
+			continue
+		}
+		file := resolveGoPath(root, modulePath, line[:colon])
+		for l := start; l <= end; l++ {
+			profile.add(file, l, count)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("coverage: read go profile: %w", err)
+	}
+	return profile, nil
+}
+
+// parseGoBlockRange parses "startLine.startCol,endLine.endCol" into the
+// start and end lines.
+func parseGoBlockRange(s string) (start, end int, ok bool) {
+	from, to, found := strings.Cut(s, ",")
+	if !found {
+		return 0, 0, false
+	}
+	startLine, _, _ := strings.Cut(from, ".")
+	endLine, _, _ := strings.Cut(to, ".")
+	start, err1 := strconv.Atoi(startLine)
+	end, err2 := strconv.Atoi(endLine)
+	return start, end, err1 == nil && err2 == nil && start <= end
+}
+
+// goModulePath returns the module path declared in root/go.mod, or "".
+func goModulePath(root string) string {
+	data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// resolveGoPath maps a coverprofile file name, an import path or an
+// absolute path, to a path relative to root.
+func resolveGoPath(root, modulePath, name string) string {
+	if modulePath != "" {
+		if rel, ok := strings.CutPrefix(name, modulePath+"/"); ok {
+			return rel
+		}
+	}
+	return relativePath(root, name)
+}
+
+// relativePath returns name relative to root and slash-separated when it
+// is an absolute path inside root, or cleaned otherwise.
+func relativePath(root, name string) string {
+	if filepath.IsAbs(name) && root != "" {
+		if rel, err := filepath.Rel(root, name); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(name))
+}
+
+// parseLCOV decodes an LCOV tracefile: "SF:" opens a file record and
+// "DA:line,hits" reports a line.
+func parseLCOV(data []byte, root string) (*Profile, error) {
+	profile := newProfile(FormatLCOV)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	file := ""
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "SF:"):
+			file = relativePath(root, strings.TrimPrefix(line, "SF:"))
+		case line == "end_of_record":
+			file = ""
+		case strings.HasPrefix(line, "DA:"):
+			if file == "" {
+				return nil, fmt.Errorf("coverage: lcov line %d: DA outside a file record", n)
+			}
+			fields := strings.Split(strings.TrimPrefix(line, "DA:"), ",")
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("coverage: lcov line %d: malformed %q", n, line)
+			}
+			lineNo, err1 := strconv.Atoi(fields[0])
+			hits, err2 := strconv.Atoi(fields[1])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("coverage: lcov line %d: malformed %q", n, line)
+			}
+			profile.add(file, lineNo, hits)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("coverage: read lcov: %w", err)
+	}
+	return profile, nil
+}
+
+// coberturaReport is the part of a Cobertura XML report that carries line
+// coverage. Method line entries repeat the class lines and are ignored.
+type coberturaReport struct {
+	Sources []string `xml:"sources>source"`
+	Classes []struct {
+		Filename string `xml:"filename,attr"`
+		Lines    []struct {
+			Number int `xml:"number,attr"`
+			Hits   int `xml:"hits,attr"`
+		} `xml:"lines>line"`
+	} `xml:"packages>package>classes>class"`
+}
+
+// parseCobertura decodes a Cobertura XML report. Class file names are
+// resolved against the report's source directories.
+func parseCobertura(data []byte, root string) (*Profile, error) {
+	var report coberturaReport
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("coverage: parse cobertura: %w", err)
+	}
+	profile := newProfile(FormatCobertura)
+	for _, class := range report.Classes {
+		file := resolveCoberturaPath(root, report.Sources, class.Filename)
+		for _, l := range class.Lines {
+			profile.add(file, l.Number, l.Hits)
+		}
+	}
+	return profile, nil
+}
+
+// resolveCoberturaPath returns the path of filename relative to root: the
+// first source directory under which it exists, or filename itself.
+func resolveCoberturaPath(root string, sources []string, filename string) string {
+	if filepath.IsAbs(filename) {
+		return relativePath(root, filename)
+	}
+	for _, source := range sources {
+		source = strings.TrimSpace(source)
+		if source == "" {
+			continue
+		}
+		candidate := filepath.Join(source, filepath.FromSlash(filename))
+		if !filepath.IsAbs(candidate) {
+			candidate = filepath.Join(root, candidate)
+		}
+		if _, err := os.Stat(candidate); err == nil {
+			return relativePath(root, candidate)
+		}
+	}
+	return relativePath(root, filename)
+}
+
+// coveragePyReport is the part of a "coverage json" report that carries
+// line coverage.
+type coveragePyReport struct {
+	Files map[string]struct {
+		ExecutedLines []int `json:"executed_lines"`
+		MissingLines  []int `json:"missing_lines"`
+	} `json:"files"`
+}
+
+// parseCoveragePy decodes a coverage.py JSON report. Executed lines count
+// one hit; coverage.py does not record hit counts.
+func parseCoveragePy(data []byte, root string) (*Profile, error) {
+	var report coveragePyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("coverage: parse coverage.py json: %w", err)
+	}
+	profile := newProfile(FormatCoveragePy)
+	for name, f := range report.Files {
+		file := relativePath(root, name)
+		for _, l := range f.MissingLines {
+			profile.add(file, l, 0)
+		}
+		for _, l := range f.ExecutedLines {
+			profile.add(file, l, 1)
+		}
+	}
+	return profile, nil
+}
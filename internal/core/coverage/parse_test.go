@@ -0,0 +1,151 @@
+package coverage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		data string
+		want Format
+	}{
+		{"mode: set\na.go:1.1,2.2 1 1\n", FormatGo},
+		{"TN:\nSF:a.js\nDA:1,1\nend_of_record\n", FormatLCOV},
+		{"SF:a.js\nDA:1,1\n", FormatLCOV},
+		{`<?xml version="1.0" ?><coverage line-rate="1"></coverage>`, FormatCobertura},
+		{`{"meta": {}, "files": {}}`, FormatCoveragePy},
+		{"hello", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat([]byte(tt.data)); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestParse_Go(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data := `mode: count
+example.com/app/calc/calc.go:5.24,7.2 2 3
+example.com/app/calc/calc.go:9.24,10.16 1 0
+example.com/app/calc/calc.go:10.16,12.3 1 0
+example.com/app/calc/calc.go:14.1,14.2 0 0
+` + filepath.Join(root, "main.go") + `:3.13,4.2 1 1
+`
+	profile, err := Parse([]byte(data), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Format != FormatGo {
+		t.Errorf("Format = %q, want go", profile.Format)
+	}
+	calc := profile.Files["calc/calc.go"]
+	want := LineHits{5: 3, 6: 3, 7: 3, 9: 0, 10: 0, 11: 0, 12: 0}
+	if len(calc) != len(want) {
+		t.Fatalf("calc lines = %v, want %v", calc, want)
+	}
+	for line, hits := range want {
+		if calc[line] != hits {
+			t.Errorf("calc line %d hits = %d, want %d", line, calc[line], hits)
+		}
+	}
+	if main := profile.Files["main.go"]; len(main) != 2 || main[3] != 1 {
+		t.Errorf("main.go lines = %v, want the absolute path made relative", main)
+	}
+}
+
+func TestParse_GoMalformed(t *testing.T) {
+	if _, err := Parse([]byte("mode: set\na.go:1.1 1 1\n"), t.TempDir()); err == nil {
+		t.Error("want error for a malformed block")
+	}
+}
+
+func TestParse_LCOV(t *testing.T) {
+	root := t.TempDir()
+	data := "TN:\nSF:" + filepath.Join(root, "src", "app.js") + "\nFN:1,main\nDA:1,4\nDA:2,0\nDA:3,1,abc\nend_of_record\nSF:src/util.js\nDA:1,0\nend_of_record\n"
+	profile, err := Parse([]byte(data), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := profile.Files["src/app.js"]
+	if len(app) != 3 || app[1] != 4 || app[2] != 0 || app[3] != 1 {
+		t.Errorf("app.js lines = %v", app)
+	}
+	if util := profile.Files["src/util.js"]; len(util) != 1 || util[1] != 0 {
+		t.Errorf("util.js lines = %v", util)
+	}
+}
+
+func TestParse_Cobertura(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "src", "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "src", "pkg", "mod.py"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	data := `<?xml version="1.0" ?>
+<coverage line-rate="0.5">
+  <sources><source>` + filepath.Join(root, "src") + `</source></sources>
+  <packages><package name="pkg"><classes>
+    <class name="mod.py" filename="pkg/mod.py">
+      <methods><method name="f"><lines><line number="1" hits="1"/></lines></method></methods>
+      <lines><line number="1" hits="1"/><line number="2" hits="0"/></lines>
+    </class>
+  </classes></package></packages>
+</coverage>`
+	profile, err := Parse([]byte(data), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mod := profile.Files["src/pkg/mod.py"]
+	if len(mod) != 2 || mod[1] != 1 || mod[2] != 0 {
+		t.Errorf("files = %v, want src/pkg/mod.py resolved through the source dir", profile.Files)
+	}
+}
+
+func TestParse_CoveragePy(t *testing.T) {
+	data := `{"meta": {"version": "7.4"}, "files": {"app/views.py": {"executed_lines": [1, 2, 5], "missing_lines": [6], "excluded_lines": [9]}}}`
+	profile, err := Parse([]byte(data), t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	views := profile.Files["app/views.py"]
+	if len(views) != 4 || views[5] != 1 || views[6] != 0 {
+		t.Errorf("views.py lines = %v", views)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	if _, err := Parse([]byte("plain text"), ""); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("unknown format error = %v", err)
+	}
+	if _, err := Parse([]byte(`{"files": {}}`), ""); !errors.Is(err, ErrNoData) {
+		t.Errorf("empty report error = %v", err)
+	}
+	if _, err := ParseFile(filepath.Join(t.TempDir(), "missing.out"), ""); err == nil {
+		t.Error("want error for a missing report")
+	}
+}
+
+func TestFindReports(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "coverage"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, rel := range []string{"coverage.out", "coverage/lcov.info"} {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(rel)), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := FindReports(root)
+	if len(got) != 2 || got[0] != filepath.Join(root, "coverage.out") {
+		t.Errorf("FindReports = %v", got)
+	}
+}
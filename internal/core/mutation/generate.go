@@ -0,0 +1,180 @@
+package mutation
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// flipOps maps the operators OpConditionalFlip inverts.
+var flipOps = map[token.Token]token.Token{
+	token.EQL:  token.NEQ,
+	token.NEQ:  token.EQL,
+	token.LSS:  token.GEQ,
+	token.GEQ:  token.LSS,
+	token.GTR:  token.LEQ,
+	token.LEQ:  token.GTR,
+	token.LAND: token.LOR,
+	token.LOR:  token.LAND,
+}
+
+// boundaryOps maps the operators OpBoundary moves.
+var boundaryOps = map[token.Token]token.Token{
+	token.LSS: token.LEQ,
+	token.LEQ: token.LSS,
+	token.GTR: token.GEQ,
+	token.GEQ: token.GTR,
+}
+
+// Generate returns the mutants of the function bodies of file, parsed
+// from src with fset. Package-level declarations and constants are not
+// mutated. Mutant IDs, packages and file names are left for the caller.
+func Generate(fset *token.FileSet, file *ast.File, src []byte) []Mutant {
+	g := &generator{fset: fset, src: src}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			g.walkFunc(fn.Type, fn.Body)
+		}
+	}
+	return g.mutants
+}
+
+// generator collects the mutants of a file.
+type generator struct {
+	fset    *token.FileSet
+	src     []byte
+	mutants []Mutant
+}
+
+// walkFunc generates the mutants of a function body; function literals are
+// walked with their own signature so returns negate the right results.
+func (g *generator) walkFunc(sig *ast.FuncType, body *ast.BlockStmt) {
+	returnsBool := sig.Results != nil && len(sig.Results.List) == 1 &&
+		len(sig.Results.List[0].Names) <= 1 && isIdent(sig.Results.List[0].Type, "bool")
+	skip := make(map[ast.Stmt]bool)
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			g.walkFunc(n.Type, n.Body)
+			return false
+		case *ast.GenDecl:
+			return n.Tok != token.CONST
+		case *ast.ForStmt:
+			// Dropping the post statement only produces slow timeouts.
+			if n.Post != nil {
+				skip[n.Post] = true
+			}
+		case *ast.BinaryExpr:
+			g.binary(n)
+		case *ast.ReturnStmt:
+			if returnsBool && len(n.Results) == 1 {
+				g.negateReturn(n.Results[0])
+			}
+		case *ast.ExprStmt:
+			if call, ok := n.X.(*ast.CallExpr); ok && !isIdent(call.Fun, "panic") {
+				g.add(OpDropStatement, n.Pos(), n.End(), "")
+			}
+		case *ast.AssignStmt:
+			if !skip[n] && n.Tok != token.DEFINE && !allBlank(n.Lhs) {
+				g.dropAssign(n)
+			}
+		case *ast.IncDecStmt:
+			if !skip[n] {
+				g.add(OpDropStatement, n.Pos(), n.End(), "_ = "+g.text(n.X.Pos(), n.X.End()))
+			}
+		}
+		return true
+	})
+}
+
+// binary generates the operator mutants of a binary expression.
+func (g *generator) binary(e *ast.BinaryExpr) {
+	opEnd := e.OpPos + token.Pos(len(e.Op.String()))
+	if to, ok := flipOps[e.Op]; ok {
+		g.add(OpConditionalFlip, e.OpPos, opEnd, to.String())
+	}
+	if to, ok := boundaryOps[e.Op]; ok {
+		g.add(OpBoundary, e.OpPos, opEnd, to.String())
+	}
+}
+
+// negateReturn generates the mutant negating a returned bool: literals
+// are swapped, other expressions wrapped in !( ).
+func (g *generator) negateReturn(result ast.Expr) {
+	switch {
+	case isIdent(result, "true"):
+		g.add(OpNegateReturn, result.Pos(), result.End(), "false")
+	case isIdent(result, "false"):
+		g.add(OpNegateReturn, result.Pos(), result.End(), "true")
+	default:
+		g.add(OpNegateReturn, result.Pos(), result.End(), "!("+g.text(result.Pos(), result.End())+")")
+	}
+}
+
+// dropAssign generates the mutant discarding an assignment: its values
+// are still evaluated but assigned to the blank identifier.
+func (g *generator) dropAssign(s *ast.AssignStmt) {
+	var values []string
+	for _, rhs := range s.Rhs {
+		values = append(values, g.text(rhs.Pos(), rhs.End()))
+	}
+	blanks := make([]string, len(s.Lhs))
+	if s.Tok != token.ASSIGN {
+		// An operator assignment such as x += y has a single value.
+		blanks = blanks[:1]
+	}
+	for i := range blanks {
+		blanks[i] = "_"
+	}
+	g.add(OpDropStatement, s.Pos(), s.End(), strings.Join(blanks, ", ")+" = "+strings.Join(values, ", "))
+}
+
+// add records a mutant replacing the source between from and to.
+func (g *generator) add(op Operator, from, to token.Pos, replacement string) {
+	pos := g.fset.Position(from)
+	start, end := pos.Offset, g.fset.Position(to).Offset
+	original := g.text(from, to)
+	if replacement == "" {
+		replacement = "/* removed */"
+	}
+	g.mutants = append(g.mutants, Mutant{
+		Line:        pos.Line,
+		Column:      pos.Column,
+		Operator:    op,
+		Original:    firstLine(original),
+		Replacement: replacement,
+		start:       start,
+		end:         end,
+	})
+}
+
+// text returns the source between from and to.
+func (g *generator) text(from, to token.Pos) string {
+	return string(g.src[g.fset.Position(from).Offset:g.fset.Position(to).Offset])
+}
+
+// isIdent reports whether e is the identifier name.
+func isIdent(e ast.Expr, name string) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+// allBlank reports whether every expression of exprs is the blank
+// identifier, so that discarding the assignment changes nothing.
+func allBlank(exprs []ast.Expr) bool {
+	for _, e := range exprs {
+		if !isIdent(e, "_") {
+			return false
+		}
+	}
+	return true
+}
+
+// firstLine shortens multi-line source to its first line.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " …"
+	}
+	return s
+}
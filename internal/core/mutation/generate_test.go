@@ -0,0 +1,138 @@
+package mutation
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+const generateSource = `package calc
+
+const limit = 1 << 3
+
+func Max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func Positive(n int) bool {
+	return n >= 0
+}
+
+func Always() bool {
+	return true
+}
+
+func Sum(xs []int) (total int) {
+	for i := 0; i < len(xs); i++ {
+		total += xs[i]
+	}
+	_ = total
+	record(total)
+	panic("unreachable")
+}
+
+func Closure() func() bool {
+	return func() bool { return false }
+}
+
+func record(int) {}
+`
+
+func generateMutants(t *testing.T) []Mutant {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "calc.go", generateSource, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Generate(fset, file, []byte(generateSource))
+}
+
+func TestGenerate(t *testing.T) {
+	type key struct {
+		line        int
+		op          Operator
+		replacement string
+	}
+	got := make(map[key]bool)
+	for _, m := range generateMutants(t) {
+		got[key{m.Line, m.Operator, m.Replacement}] = true
+	}
+	want := []key{
+		{6, OpConditionalFlip, "<="},
+		{6, OpBoundary, ">="},
+		{13, OpConditionalFlip, "<"},
+		{13, OpBoundary, ">"},
+		{13, OpNegateReturn, "!(n >= 0)"},
+		{17, OpNegateReturn, "false"},
+		{21, OpConditionalFlip, ">="},
+		{21, OpBoundary, "<="},
+		{22, OpDropStatement, "_ = xs[i]"},
+		{25, OpDropStatement, "/* removed */"},
+		{30, OpNegateReturn, "true"},
+	}
+	for _, k := range want {
+		if !got[k] {
+			t.Errorf("missing mutant %+v", k)
+		}
+	}
+	// The constant, the for post statement, the blank assignment and the
+	// panic are not mutated.
+	if len(got) != len(want) {
+		t.Errorf("got %d mutants, want %d: %v", len(got), len(want), got)
+	}
+}
+
+func TestMutant_Apply(t *testing.T) {
+	for _, m := range generateMutants(t) {
+		if m.Line != 13 || m.Operator != OpNegateReturn {
+			continue
+		}
+		mutated := string(m.apply([]byte(generateSource)))
+		fset := token.NewFileSet()
+		if _, err := parser.ParseFile(fset, "calc.go", mutated, 0); err != nil {
+			t.Fatalf("mutated source does not parse: %v", err)
+		}
+		if m.Original != "n >= 0" || m.String() != ":13:9: n >= 0 -> !(n >= 0)" {
+			t.Errorf("mutant = %q (original %q)", m.String(), m.Original)
+		}
+		return
+	}
+	t.Fatal("negate-return mutant on line 13 not generated")
+}
+
+func TestReport_Tally(t *testing.T) {
+	report := &Report{Mutants: []Mutant{
+		{Status: StatusKilled}, {Status: StatusKilled}, {Status: StatusTimedOut},
+		{Status: StatusSurvived}, {Status: StatusNoCoverage}, {Status: StatusCompileError},
+	}}
+	report.tally()
+	if report.Killed != 2 || report.TimedOut != 1 || report.Survived != 1 || report.NoCoverage != 1 || report.CompileErrors != 1 {
+		t.Errorf("counts = %+v", report)
+	}
+	if report.Score != 60 {
+		t.Errorf("Score = %v, want 60 (3 of 5 viable mutants detected)", report.Score)
+	}
+	if len(report.Surviving()) != 2 {
+		t.Errorf("Surviving = %v, want the survived and uncovered mutants", report.Surviving())
+	}
+
+	empty := &Report{}
+	empty.tally()
+	if empty.Score != 100 {
+		t.Errorf("empty Score = %v, want 100", empty.Score)
+	}
+}
+
+func TestIsTestName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"Test": true, "TestAdd": true, "Test_add": true, "TestMain": false, "Testing": false, "Benchmark": false,
+	} {
+		if got := isTestName(name); got != want {
+			t.Errorf("isTestName(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package mutation implements mutation testing for Go packages. Mutants
+// are generated from the syntax tree of each source file, and every mutant
+// runs only the tests whose coverage reaches its line, in parallel, with
+// the mutated file supplied through a "go test -overlay" directory. The
+// mutation score shows whether the tests assert on the code they execute.
+package mutation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sentinel errors for mutation testing.
+var (
+	ErrNoPackages = errors.New("mutation: no Go packages matched")
+	ErrNoGoModule = errors.New("mutation: no go.mod in the project root")
+)
+
+// Operator names a kind of source mutation.
+type Operator string
+
+const (
+	// OpConditionalFlip inverts a comparison or logical operator:
+	// == and !=, < and >=, > and <=, && and ||.
+	OpConditionalFlip Operator = "conditional-flip"
+
+	// OpBoundary moves a comparison boundary: < to <=, > to >= and back.
+	OpBoundary Operator = "boundary"
+
+	// OpNegateReturn negates the result of a function returning bool.
+	OpNegateReturn Operator = "negate-return"
+
+	// OpDropStatement removes a call statement or discards an assignment
+	// or increment.
+	OpDropStatement Operator = "drop-statement"
+)
+
+// Status is the outcome of running the tests against a mutant.
+type Status string
+
+const (
+	// StatusKilled means a covering test failed.
+	StatusKilled Status = "killed"
+
+	// StatusSurvived means every covering test passed.
+	StatusSurvived Status = "survived"
+
+	// StatusTimedOut means the tests did not finish in time, usually an
+	// infinite loop; it counts as killed.
+	StatusTimedOut Status = "timed_out"
+
+	// StatusNoCoverage means no test executes the mutated line; it counts
+	// as survived.
+	StatusNoCoverage Status = "no_coverage"
+
+	// StatusCompileError means the mutant does not build; it is left out
+	// of the score.
+	StatusCompileError Status = "compile_error"
+)
+
+// Mutant is a single source change and its test outcome.
+type Mutant struct {
+	ID          int      `json:"id"`
+	Package     string   `json:"package"`
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	Column      int      `json:"column"`
+	Operator    Operator `json:"operator"`
+	Original    string   `json:"original"`
+	Replacement string   `json:"replacement"`
+	Status      Status   `json:"status,omitempty"`
+	Tests       []string `json:"tests,omitempty"`
+	KilledBy    string   `json:"killed_by,omitempty"`
+
+	// start and end delimit the replaced bytes of the source file.
+	start, end int
+}
+
+// String describes the mutant as "file:line:col: original -> replacement".
+func (m Mutant) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s -> %s", m.File, m.Line, m.Column, m.Original, firstLine(m.Replacement))
+}
+
+// apply returns src with the mutation applied.
+func (m Mutant) apply(src []byte) []byte {
+	out := make([]byte, 0, len(src)+len(m.Replacement))
+	out = append(out, src[:m.start]...)
+	out = append(out, m.Replacement...)
+	return append(out, src[m.end:]...)
+}
+
+// Report is the result of a mutation testing run.
+type Report struct {
+	Mutants       []Mutant      `json:"mutants"`
+	Killed        int           `json:"killed"`
+	Survived      int           `json:"survived"`
+	TimedOut      int           `json:"timed_out"`
+	NoCoverage    int           `json:"no_coverage"`
+	CompileErrors int           `json:"compile_errors"`
+	Score         float64       `json:"score"`
+	FailingTests  []string      `json:"failing_tests,omitempty"`
+	GeneratedAt   time.Time     `json:"generated_at"`
+	Duration      time.Duration `json:"duration"`
+
+	// SourceHash is the SourceHash of the module when the run started.
+	SourceHash string `json:"source_hash,omitempty"`
+}
+
+// tally counts the mutant outcomes and computes the score: the killed and
+// timed-out share of the mutants that built. Without such mutants there
+// is nothing to kill and the score is 100.
+func (r *Report) tally() {
+	r.Killed, r.Survived, r.TimedOut, r.NoCoverage, r.CompileErrors = 0, 0, 0, 0, 0
+	for _, m := range r.Mutants {
+		switch m.Status {
+		case StatusKilled:
+			r.Killed++
+		case StatusSurvived:
+			r.Survived++
+		case StatusTimedOut:
+			r.TimedOut++
+		case StatusNoCoverage:
+			r.NoCoverage++
+		case StatusCompileError:
+			r.CompileErrors++
+		}
+	}
+	detected := r.Killed + r.TimedOut
+	total := detected + r.Survived + r.NoCoverage
+	r.Score = 100
+	if total > 0 {
+		r.Score = float64(detected) * 100 / float64(total)
+	}
+}
+
+// Surviving returns the mutants no test detected, including those no test
+// covers.
+func (r *Report) Surviving() []Mutant {
+	var out []Mutant
+	for _, m := range r.Mutants {
+		if m.Status == StatusSurvived || m.Status == StatusNoCoverage {
+			out = append(out, m)
+		}
+	}
+	return out
+}
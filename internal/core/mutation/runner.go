@@ -0,0 +1,391 @@
+package mutation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/modu-ai/moai-adk/internal/core/coverage"
+)
+
+// failedTestPattern captures the name of the first failing test in go
+// test output.
+var failedTestPattern = regexp.MustCompile(`--- FAIL: (\S+)`)
+
+// Runner runs mutation testing on the packages of the Go module at Root.
+type Runner struct {
+	// Root is the module root, holding go.mod.
+	Root string
+
+	// Workers bounds the mutants tested at once.
+	Workers int
+
+	// MutantTimeout bounds the test run of one mutant. Zero derives it
+	// from the durations of the covering tests.
+	MutantTimeout time.Duration
+
+	// Logger receives per-package and per-mutant progress at debug level.
+	Logger *slog.Logger
+}
+
+// NewRunner creates a runner for the module at root using half of the
+// available CPUs.
+func NewRunner(root string) *Runner {
+	return &Runner{
+		Root:    root,
+		Workers: max(1, runtime.NumCPU()/2),
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+// goPackage is the part of "go list -json" output the runner uses.
+type goPackage struct {
+	ImportPath   string
+	Dir          string
+	GoFiles      []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+}
+
+// packageRun is the mutation testing state of one package.
+type packageRun struct {
+	pkg       goPackage
+	mutants   []*Mutant
+	sources   map[string][]byte
+	durations map[string]time.Duration
+	buildTime time.Duration
+}
+
+// Run generates the mutants of the packages matching patterns (as given
+// to go list) and tests each of them.
+func (r *Runner) Run(ctx context.Context, patterns []string) (*Report, error) {
+	started := time.Now()
+	if _, err := os.Stat(filepath.Join(r.Root, "go.mod")); err != nil {
+		return nil, ErrNoGoModule
+	}
+	pkgs, err := r.listPackages(ctx, patterns)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return nil, ErrNoPackages
+	}
+	hash, err := SourceHash(r.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{SourceHash: hash}
+	var runs []*packageRun
+	nextID := 1
+	for _, pkg := range pkgs {
+		run := &packageRun{pkg: pkg, sources: make(map[string][]byte), durations: make(map[string]time.Duration)}
+		if err := r.generate(run, &nextID); err != nil {
+			return nil, err
+		}
+		if len(run.mutants) == 0 {
+			continue
+		}
+		failing, err := r.selectTests(ctx, run)
+		if err != nil {
+			return nil, err
+		}
+		report.FailingTests = append(report.FailingTests, failing...)
+		runs = append(runs, run)
+	}
+
+	if err := r.execute(ctx, runs); err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		for _, m := range run.mutants {
+			report.Mutants = append(report.Mutants, *m)
+		}
+	}
+	sort.Slice(report.Mutants, func(i, j int) bool { return report.Mutants[i].ID < report.Mutants[j].ID })
+	report.tally()
+	report.GeneratedAt = time.Now()
+	report.Duration = time.Since(started)
+	return report, nil
+}
+
+// listPackages resolves patterns to the packages of the module.
+func (r *Runner) listPackages(ctx context.Context, patterns []string) ([]goPackage, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+	args := append([]string{"list", "-json=ImportPath,Dir,GoFiles,TestGoFiles,XTestGoFiles"}, patterns...)
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = r.Root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("mutation: go list: %s", strings.TrimSpace(stderr.String()))
+	}
+	var pkgs []goPackage
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var pkg goPackage
+		if err := dec.Decode(&pkg); err != nil {
+			return nil, fmt.Errorf("mutation: decode go list output: %w", err)
+		}
+		if len(pkg.GoFiles) > 0 {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs, nil
+}
+
+// generate creates the mutants of the non-generated source files of a
+// package, numbering them from *nextID.
+func (r *Runner) generate(run *packageRun, nextID *int) error {
+	fset := token.NewFileSet()
+	for _, name := range run.pkg.GoFiles {
+		path := filepath.Join(run.pkg.Dir, name)
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("mutation: read source: %w", err)
+		}
+		file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("mutation: parse %s: %w", path, err)
+		}
+		if ast.IsGenerated(file) {
+			continue
+		}
+		run.sources[path] = src
+		rel := relPath(r.Root, path)
+		for _, m := range Generate(fset, file, src) {
+			m.ID = *nextID
+			m.Package = run.pkg.ImportPath
+			m.File = rel
+			*nextID++
+			run.mutants = append(run.mutants, &m)
+		}
+	}
+	return nil
+}
+
+// selectTests runs each test of the package once with coverage and
+// assigns every mutant the tests executing its line. Tests failing without
+// any mutation cannot kill a mutant; they are returned and not used.
+func (r *Runner) selectTests(ctx context.Context, run *packageRun) ([]string, error) {
+	tests, err := testNames(run.pkg)
+	if err != nil {
+		return nil, err
+	}
+	if len(tests) == 0 {
+		return nil, nil
+	}
+
+	tmp, err := os.MkdirTemp("", "moai-mutation-cover-")
+	if err != nil {
+		return nil, fmt.Errorf("mutation: create temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	binary := filepath.Join(tmp, "pkg.test")
+	buildStart := time.Now()
+	build := exec.CommandContext(ctx, "go", "test", "-c", "-covermode=set", "-o", binary, run.pkg.ImportPath)
+	build.Dir = r.Root
+	if out, err := build.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("mutation: build tests of %s: %s", run.pkg.ImportPath, strings.TrimSpace(string(out)))
+	}
+	run.buildTime = time.Since(buildStart)
+
+	var failing []string
+	for _, test := range tests {
+		profile := filepath.Join(tmp, test+".out")
+		cmd := exec.CommandContext(ctx, binary, "-test.run", "^"+test+"$", "-test.count=1", "-test.coverprofile="+profile)
+		cmd.Dir = run.pkg.Dir
+		start := time.Now()
+		out, err := cmd.CombinedOutput()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			r.Logger.Debug("mutation: test fails without mutation", "test", test, "output", string(out))
+			failing = append(failing, run.pkg.ImportPath+"."+test)
+			continue
+		}
+		run.durations[test] = time.Since(start)
+
+		covered, err := coverage.ParseFile(profile, r.Root)
+		if err != nil {
+			// A test that executes none of the package writes no blocks.
+			continue
+		}
+		for _, m := range run.mutants {
+			if covered.Files[m.File][m.Line] > 0 {
+				m.Tests = append(m.Tests, test)
+			}
+		}
+	}
+	return failing, nil
+}
+
+// execute tests the covered mutants of runs on the worker pool and marks
+// the others as not covered.
+func (r *Runner) execute(ctx context.Context, runs []*packageRun) error {
+	type job struct {
+		run    *packageRun
+		mutant *Mutant
+	}
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for range max(1, r.Workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				r.testMutant(ctx, j.run, j.mutant)
+			}
+		}()
+	}
+
+feed:
+	for _, run := range runs {
+		for _, m := range run.mutants {
+			if len(m.Tests) == 0 {
+				m.Status = StatusNoCoverage
+				continue
+			}
+			select {
+			case jobs <- job{run, m}:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return fmt.Errorf("mutation: %w", ctx.Err())
+	}
+	return nil
+}
+
+// testMutant runs the covering tests against a mutant supplied through a
+// go build overlay and records the outcome.
+func (r *Runner) testMutant(ctx context.Context, run *packageRun, m *Mutant) {
+	dir, err := os.MkdirTemp("", "moai-mutant-")
+	if err != nil {
+		m.Status = StatusCompileError
+		return
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	original := filepath.Join(r.Root, filepath.FromSlash(m.File))
+	mutated := filepath.Join(dir, filepath.Base(original))
+	overlay := filepath.Join(dir, "overlay.json")
+	data, _ := json.Marshal(map[string]map[string]string{"Replace": {original: mutated}})
+	if os.WriteFile(mutated, m.apply(run.sources[original]), 0o600) != nil || os.WriteFile(overlay, data, 0o600) != nil {
+		m.Status = StatusCompileError
+		return
+	}
+
+	testTimeout := r.testTimeout(run, m.Tests)
+	// The build is bounded separately: the test binary itself panics
+	// after testTimeout, which also stops infinite loops.
+	runCtx, cancel := context.WithTimeout(ctx, testTimeout+2*run.buildTime+time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, "go", "test", "-count=1", "-vet=off",
+		"-overlay="+overlay, "-timeout="+testTimeout.String(),
+		"-run", "^("+strings.Join(m.Tests, "|")+")$", run.pkg.ImportPath)
+	cmd.Dir = r.Root
+	cmd.WaitDelay = 5 * time.Second
+	out, err := cmd.CombinedOutput()
+	output := string(out)
+
+	switch {
+	case ctx.Err() != nil:
+		return
+	case err == nil:
+		m.Status = StatusSurvived
+	case strings.Contains(output, "[build failed]") || strings.Contains(output, "[setup failed]"):
+		m.Status = StatusCompileError
+	case runCtx.Err() != nil || strings.Contains(output, "panic: test timed out"):
+		m.Status = StatusTimedOut
+	default:
+		m.Status = StatusKilled
+		if match := failedTestPattern.FindStringSubmatch(output); match != nil {
+			m.KilledBy = match[1]
+		}
+	}
+	r.Logger.Debug("mutation: mutant tested", "mutant", m.String(), "status", m.Status)
+}
+
+// testTimeout returns MutantTimeout, or ten times the unmutated duration
+// of tests plus ten seconds.
+func (r *Runner) testTimeout(run *packageRun, tests []string) time.Duration {
+	if r.MutantTimeout > 0 {
+		return r.MutantTimeout
+	}
+	var total time.Duration
+	for _, test := range tests {
+		total += run.durations[test]
+	}
+	return 10*total + 10*time.Second
+}
+
+// testNames returns the top-level Test functions of the test files of a
+// package, as go test would run them.
+func testNames(pkg goPackage) ([]string, error) {
+	fset := token.NewFileSet()
+	var names []string
+	for _, name := range append(append([]string{}, pkg.TestGoFiles...), pkg.XTestGoFiles...) {
+		file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("mutation: parse %s: %w", name, err)
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Recv == nil && isTestName(fn.Name.Name) && len(fn.Type.Params.List) == 1 {
+				names = append(names, fn.Name.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// isTestName reports whether name is a test function name: "Test" not
+// followed by a lower-case letter, and not TestMain.
+func isTestName(name string) bool {
+	rest, ok := strings.CutPrefix(name, "Test")
+	if !ok || name == "TestMain" {
+		return false
+	}
+	if rest == "" {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return !unicode.IsLower(r)
+}
+
+// relPath returns path relative to root, slash-separated.
+func relPath(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
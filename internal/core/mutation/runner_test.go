@@ -0,0 +1,105 @@
+package mutation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// writeModule creates a Go module in a temp dir from files and returns
+// its root.
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("runs go test on a temporary module")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not available")
+	}
+	root := t.TempDir()
+	files["go.mod"] = "module example.com/calc\n\ngo 1.22\n"
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestRunner_Run(t *testing.T) {
+	root := writeModule(t, map[string]string{
+		"calc.go": `package calc
+
+func Max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func Abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+`,
+		"calc_test.go": `package calc
+
+import "testing"
+
+func TestMax(t *testing.T) {
+	if Max(3, 1) != 3 || Max(1, 3) != 3 {
+		t.Fatal("wrong max")
+	}
+}
+
+func TestBroken(t *testing.T) {
+	t.Fatal("always fails")
+}
+`,
+	})
+
+	runner := NewRunner(root)
+	report, err := runner.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Mutants) != 4 {
+		t.Fatalf("mutants = %v, want 4", report.Mutants)
+	}
+	// Max: the flipped condition is killed, the moved boundary survives
+	// (3 vs 1 and 1 vs 3 never compare equal values). Abs is not tested.
+	if report.Killed != 1 || report.Survived != 1 || report.NoCoverage != 2 || report.Score != 25 {
+		t.Errorf("report = %+v, want 1 killed, 1 survived, 2 uncovered, score 25", report)
+	}
+	flip := report.Mutants[0]
+	if flip.Operator != OpConditionalFlip || flip.Status != StatusKilled || flip.KilledBy != "TestMax" ||
+		flip.File != "calc.go" || len(flip.Tests) != 1 {
+		t.Errorf("first mutant = %+v, want the flip killed by TestMax", flip)
+	}
+	if len(report.FailingTests) != 1 || report.FailingTests[0] != "example.com/calc.TestBroken" {
+		t.Errorf("FailingTests = %v", report.FailingTests)
+	}
+	if hash, err := SourceHash(root); err != nil || report.SourceHash != hash {
+		t.Errorf("SourceHash = %q, want the current hash %q (%v)", report.SourceHash, hash, err)
+	}
+}
+
+func TestRunner_Errors(t *testing.T) {
+	if _, err := NewRunner(t.TempDir()).Run(context.Background(), nil); !errors.Is(err, ErrNoGoModule) {
+		t.Errorf("error without go.mod = %v, want ErrNoGoModule", err)
+	}
+
+	root := writeModule(t, map[string]string{"doc.md": "no Go files"})
+	if _, err := NewRunner(root).Run(context.Background(), []string{"."}); err == nil {
+		t.Error("want error for a pattern without Go packages")
+	}
+}
@@ -0,0 +1,35 @@
+package mutation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceHash(t *testing.T) {
+	root := writeModule(t, map[string]string{"calc.go": "package calc\n"})
+	before, err := SourceHash(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("ignored"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".moai"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".moai", "x.go"), []byte("package x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := SourceHash(root); after != before {
+		t.Error("hash changed for files outside the Go sources")
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "calc.go"), []byte("package calc\n\nconst One = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := SourceHash(root); after == before {
+		t.Error("hash unchanged after a source edit")
+	}
+}
@@ -0,0 +1,64 @@
+package quality
+
+import (
+	"time"
+
+	"github.com/modu-ai/moai-adk/pkg/models"
+)
+
+// ConfigFromModel maps the constitution section of quality.yaml onto the
+// gate configuration. Durations left at zero keep their defaults.
+func ConfigFromModel(m models.QualityConfig) QualityConfig {
+	qc := DefaultQualityConfig()
+	if m.DevelopmentMode != "" {
+		qc.DevelopmentMode = DevelopmentMode(m.DevelopmentMode)
+	}
+	qc.EnforceQuality = m.EnforceQuality
+	qc.TestCoverageTarget = m.TestCoverageTarget
+
+	gates := m.LSPQualityGates
+	qc.LSPGates = PhaseGates{
+		Plan: PlanGate{RequireBaseline: gates.Plan.RequireBaseline},
+		Run: RunGate{
+			MaxErrors:       gates.Run.MaxErrors,
+			MaxTypeErrors:   gates.Run.MaxTypeErrors,
+			MaxLintErrors:   gates.Run.MaxLintErrors,
+			AllowRegression: gates.Run.AllowRegression,
+		},
+		Sync: SyncGate{
+			MaxErrors:       gates.Sync.MaxErrors,
+			MaxWarnings:     gates.Sync.MaxWarnings,
+			RequireCleanLSP: gates.Sync.RequireCleanLSP,
+		},
+	}
+	if gates.CacheTTLSeconds > 0 {
+		qc.CacheTTL = time.Duration(gates.CacheTTLSeconds) * time.Second
+	}
+	if gates.TimeoutSeconds > 0 {
+		qc.Timeout = time.Duration(gates.TimeoutSeconds) * time.Second
+	}
+
+	reg := m.LSPIntegration.RegressionDetection
+	qc.RegressionDetection = RegressionConfig{
+		ErrorIncreaseThreshold:     reg.ErrorIncreaseThreshold,
+		WarningIncreaseThreshold:   reg.WarningIncreaseThreshold,
+		TypeErrorIncreaseThreshold: reg.TypeErrorIncreaseThreshold,
+	}
+
+	qc.DDDSettings = DDDSettings{
+		RequireExistingTests:  m.DDDSettings.RequireExistingTests,
+		CharacterizationTests: m.DDDSettings.CharacterizationTests,
+		BehaviorSnapshots:     m.DDDSettings.BehaviorSnapshots,
+		MaxTransformationSize: m.DDDSettings.MaxTransformationSize,
+		PreserveBeforeImprove: m.DDDSettings.PreserveBeforeImprove,
+	}
+	qc.TDDSettings.MinCoveragePerCommit = m.TDDSettings.MinCoveragePerCommit
+	qc.TDDSettings.RequireTestFirst = m.TDDSettings.TestFirstRequired
+	qc.TDDSettings.MutationTestingEnabled = m.TDDSettings.MutationTestingEnabled || m.TestQuality.MutationTestingEnabled
+	qc.TDDSettings.MutationScoreThreshold = m.TDDSettings.MutationScoreThreshold
+	qc.HybridSettings = HybridSettings{
+		MinCoverageNew:    m.HybridSettings.MinCoverageNew,
+		MinCoverageLegacy: m.HybridSettings.MinCoverageLegacy,
+	}
+	return qc
+}
@@ -0,0 +1,57 @@
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/modu-ai/moai-adk/pkg/models"
+)
+
+func TestConfigFromModel(t *testing.T) {
+	m := models.QualityConfig{
+		DevelopmentMode:    models.ModeTDD,
+		EnforceQuality:     true,
+		TestCoverageTarget: 80,
+		TDDSettings:        models.TDDSettings{TestFirstRequired: true, MinCoveragePerCommit: 75, MutationScoreThreshold: 65},
+		TestQuality:        models.TestQuality{MutationTestingEnabled: true},
+		HybridSettings:     models.HybridSettings{MinCoverageNew: 95, MinCoverageLegacy: 60},
+		LSPQualityGates: models.LSPQualityGates{
+			Run:             models.LSPRunGate{MaxErrors: 1, MaxLintErrors: 3, AllowRegression: true},
+			Sync:            models.LSPSyncGate{MaxWarnings: 4, RequireCleanLSP: true},
+			CacheTTLSeconds: 9,
+		},
+		LSPIntegration: models.LSPIntegration{
+			RegressionDetection: models.RegressionDetection{WarningIncreaseThreshold: 2},
+		},
+	}
+
+	qc := ConfigFromModel(m)
+	if qc.DevelopmentMode != ModeTDD || !qc.EnforceQuality || qc.TestCoverageTarget != 80 {
+		t.Errorf("mode/enforce/coverage = %s/%v/%d", qc.DevelopmentMode, qc.EnforceQuality, qc.TestCoverageTarget)
+	}
+	if qc.LSPGates.Run != (RunGate{MaxErrors: 1, MaxLintErrors: 3, AllowRegression: true}) {
+		t.Errorf("Run gate = %+v", qc.LSPGates.Run)
+	}
+	if qc.LSPGates.Sync.MaxWarnings != 4 || !qc.LSPGates.Sync.RequireCleanLSP {
+		t.Errorf("Sync gate = %+v", qc.LSPGates.Sync)
+	}
+	if qc.RegressionDetection.WarningIncreaseThreshold != 2 {
+		t.Errorf("RegressionDetection = %+v", qc.RegressionDetection)
+	}
+	if !qc.TDDSettings.RequireTestFirst || qc.TDDSettings.MinCoveragePerCommit != 75 ||
+		!qc.TDDSettings.MutationTestingEnabled || qc.TDDSettings.MutationScoreThreshold != 65 {
+		t.Errorf("TDDSettings = %+v", qc.TDDSettings)
+	}
+	if qc.HybridSettings != (HybridSettings{MinCoverageNew: 95, MinCoverageLegacy: 60}) {
+		t.Errorf("HybridSettings = %+v", qc.HybridSettings)
+	}
+	if qc.CacheTTL != 9*time.Second || qc.Timeout != DefaultQualityConfig().Timeout {
+		t.Errorf("CacheTTL/Timeout = %v/%v, want 9s and the default timeout", qc.CacheTTL, qc.Timeout)
+	}
+}
+
+func TestConfigFromModel_EmptyModeKeepsDefault(t *testing.T) {
+	if got := ConfigFromModel(models.QualityConfig{}).DevelopmentMode; got != ModeDDD {
+		t.Errorf("DevelopmentMode = %s, want default %s", got, ModeDDD)
+	}
+}
@@ -0,0 +1,214 @@
+package quality
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultMaxComplexity is the cyclomatic complexity above which a function
+// fails the Understandable principle.
+const DefaultMaxComplexity = 15
+
+// Structured logging libraries by import path (Go) and module name
+// (Python, JavaScript/TypeScript, Rust).
+var (
+	goStructuredLoggers    = []string{"log/slog", "go.uber.org/zap", "github.com/rs/zerolog", "github.com/sirupsen/logrus"}
+	otherStructuredLoggers = []string{"structlog", "loguru", "pino", "winston", "bunyan", "tracing"}
+)
+
+// SourceAnalysis holds the static source checks feeding the Understandable
+// and Trackable principles. Documentation and complexity are measured for
+// Go files only.
+type SourceAnalysis struct {
+	// Undocumented lists exported Go declarations without a doc comment
+	// as "file:line: Name".
+	Undocumented []string
+
+	// Complex lists Go functions above the complexity limit as
+	// "file:line: Name (N)".
+	Complex []string
+
+	// StructuredLogging is set when any file imports a structured logging
+	// library.
+	StructuredLogging bool
+}
+
+// DocComplete reports whether every exported declaration is documented.
+func (a *SourceAnalysis) DocComplete() bool { return len(a.Undocumented) == 0 }
+
+// ComplexityOK reports whether no function exceeds the complexity limit.
+func (a *SourceAnalysis) ComplexityOK() bool { return len(a.Complex) == 0 }
+
+// AnalyzeSource inspects files, relative to projectDir. Go test and
+// generated files are not checked for documentation or complexity; files
+// that do not parse are skipped, since the build reports them.
+func AnalyzeSource(projectDir string, files []string, maxComplexity int) *SourceAnalysis {
+	analysis := &SourceAnalysis{}
+	fset := token.NewFileSet()
+	for _, rel := range files {
+		path := filepath.Join(projectDir, filepath.FromSlash(rel))
+		if filepath.Ext(rel) != ".go" {
+			if !analysis.StructuredLogging && importsStructuredLogger(path) {
+				analysis.StructuredLogging = true
+			}
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		for _, imp := range file.Imports {
+			if p, _ := strconv.Unquote(imp.Path.Value); isStructuredLogger(p) {
+				analysis.StructuredLogging = true
+			}
+		}
+		if strings.HasSuffix(rel, "_test.go") || ast.IsGenerated(file) {
+			continue
+		}
+		location := func(pos token.Pos, name string) string {
+			return fmt.Sprintf("%s:%d: %s", rel, fset.Position(pos).Line, name)
+		}
+		for _, decl := range file.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				if d.Name.IsExported() && d.Doc == nil && exportedReceiver(d) {
+					analysis.Undocumented = append(analysis.Undocumented, location(d.Pos(), d.Name.Name))
+				}
+				if n := cyclomaticComplexity(d); maxComplexity > 0 && n > maxComplexity {
+					analysis.Complex = append(analysis.Complex, fmt.Sprintf("%s (%d)", location(d.Pos(), d.Name.Name), n))
+				}
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					for _, name := range exportedSpecNames(spec) {
+						if d.Doc == nil && specDoc(spec) == nil {
+							analysis.Undocumented = append(analysis.Undocumented, location(name.Pos(), name.Name))
+						}
+					}
+				}
+			}
+		}
+	}
+	return analysis
+}
+
+// exportedReceiver reports whether d is a function or a method of an
+// exported type.
+func exportedReceiver(d *ast.FuncDecl) bool {
+	if d.Recv == nil || len(d.Recv.List) == 0 {
+		return true
+	}
+	expr := d.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.IsExported()
+		default:
+			return false
+		}
+	}
+}
+
+// exportedSpecNames returns the exported names declared by a type, const
+// or var spec.
+func exportedSpecNames(spec ast.Spec) []*ast.Ident {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		if s.Name.IsExported() {
+			return []*ast.Ident{s.Name}
+		}
+	case *ast.ValueSpec:
+		var names []*ast.Ident
+		for _, name := range s.Names {
+			if name.IsExported() {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// specDoc returns the doc comment of a spec inside a grouped declaration.
+func specDoc(spec ast.Spec) *ast.CommentGroup {
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		return s.Doc
+	case *ast.ValueSpec:
+		return s.Doc
+	}
+	return nil
+}
+
+// cyclomaticComplexity counts the decision points of a function plus one.
+func cyclomaticComplexity(fn *ast.FuncDecl) int {
+	complexity := 1
+	if fn.Body == nil {
+		return complexity
+	}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if n.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
+// isStructuredLogger reports whether a Go import path is a structured
+// logging library or one of its subpackages.
+func isStructuredLogger(path string) bool {
+	for _, logger := range goStructuredLoggers {
+		if path == logger || strings.HasPrefix(path, logger+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// importsStructuredLogger reports whether a non-Go source file mentions a
+// structured logging library in an import or require line.
+func importsStructuredLogger(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "import ") && !strings.HasPrefix(line, "from ") &&
+			!strings.HasPrefix(line, "use ") && !strings.Contains(line, "require(") {
+			continue
+		}
+		for _, logger := range otherStructuredLoggers {
+			if strings.Contains(line, logger) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package quality
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func writeSourceFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalyzeSource(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFile(t, dir, "calc/calc.go", `package calc
+
+import "log/slog"
+
+// Add returns a + b.
+func Add(a, b int) int { return a + b }
+
+func Sub(a, b int) int { return a - b }
+
+type Calc struct{}
+
+func (Calc) Mul(a, b int) int { return a * b }
+
+type hidden struct{}
+
+func (hidden) Div(a, b int) int { return a / b }
+
+// Limits of the calculator.
+const (
+	Min = 0
+	Max = 100
+)
+
+var Debug = false
+
+func classify(n int) string {
+	if n < 0 && n > -10 || n == -100 {
+		return "small negative"
+	}
+	for i := 0; i < n; i++ {
+		switch {
+		case i == 1:
+			slog.Info("one")
+		case i == 2:
+		default:
+		}
+	}
+	return ""
+}
+`)
+	writeSourceFile(t, dir, "calc/calc_test.go", "package calc\n\nfunc TestHelper() {}\n")
+	writeSourceFile(t, dir, "calc/broken.go", "package calc\nfunc (\n")
+
+	analysis := AnalyzeSource(dir, []string{"calc/calc.go", "calc/calc_test.go", "calc/broken.go"}, 5)
+
+	want := []string{"calc/calc.go:8: Sub", "calc/calc.go:10: Calc", "calc/calc.go:12: Mul", "calc/calc.go:24: Debug"}
+	if !slices.Equal(analysis.Undocumented, want) {
+		t.Errorf("Undocumented = %v, want %v", analysis.Undocumented, want)
+	}
+	if analysis.DocComplete() {
+		t.Error("DocComplete() = true, want false")
+	}
+	if len(analysis.Complex) != 1 || !strings.HasPrefix(analysis.Complex[0], "calc/calc.go:26: classify (7)") {
+		t.Errorf("Complex = %v, want classify with complexity 7", analysis.Complex)
+	}
+	if !analysis.StructuredLogging {
+		t.Error("StructuredLogging = false, want true for log/slog")
+	}
+}
+
+func TestAnalyzeSource_OtherLanguages(t *testing.T) {
+	dir := t.TempDir()
+	writeSourceFile(t, dir, "app.py", "import structlog\n\nlog = structlog.get_logger()\n")
+	writeSourceFile(t, dir, "util.py", "def undocumented():\n    return 1\n")
+
+	analysis := AnalyzeSource(dir, []string{"util.py", "app.py"}, DefaultMaxComplexity)
+	if !analysis.DocComplete() || !analysis.ComplexityOK() {
+		t.Errorf("analysis = %+v, want documentation and complexity unmeasured", analysis)
+	}
+	if !analysis.StructuredLogging {
+		t.Error("StructuredLogging = false, want true for structlog")
+	}
+
+	if AnalyzeSource(dir, []string{"util.py"}, DefaultMaxComplexity).StructuredLogging {
+		t.Error("StructuredLogging = true for a file without a structured logger")
+	}
+}
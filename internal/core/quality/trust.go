@@ -140,12 +140,12 @@ type Diagnostic struct {
 
 // DiagnosticSnapshot captures diagnostic counts at a point in time.
 type DiagnosticSnapshot struct {
-	Errors           int
-	Warnings         int
-	TypeErrors       int
-	LintErrors       int
-	SecurityWarnings int
-	Timestamp        time.Time
+	Errors           int       `json:"errors"`
+	Warnings         int       `json:"warnings"`
+	TypeErrors       int       `json:"type_errors"`
+	LintErrors       int       `json:"lint_errors"`
+	SecurityWarnings int       `json:"security_warnings"`
+	Timestamp        time.Time `json:"timestamp"`
 }
 
 // ASTMatch represents a structural code pattern match.
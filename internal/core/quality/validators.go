@@ -9,22 +9,43 @@ import (
 // --- TestedValidator ---
 
 // TestedValidator checks the "tested" principle:
-// unit test pass status, LSP type/general errors, and test coverage.
+// unit test pass status, LSP type/general errors, test coverage and,
+// when measured, the mutation score.
 type TestedValidator struct {
 	lsp             LSPClient
 	coverageTarget  int
 	currentCoverage int
+
+	mutationScore     *float64
+	mutationThreshold int
+}
+
+// TestedOption configures optional TestedValidator checks.
+type TestedOption func(*TestedValidator)
+
+// WithMutationScore adds a mutation score check: score is the percentage
+// of mutants the tests killed and threshold the minimum required. Unlike
+// coverage, it shows whether the tests assert on the code they run.
+func WithMutationScore(score float64, threshold int) TestedOption {
+	return func(v *TestedValidator) {
+		v.mutationScore = &score
+		v.mutationThreshold = threshold
+	}
 }
 
 // NewTestedValidator creates a validator for the Tested principle.
 // coverageTarget is the minimum required percentage (e.g., 85).
 // currentCoverage is the current test coverage percentage.
-func NewTestedValidator(lsp LSPClient, coverageTarget, currentCoverage int) *TestedValidator {
-	return &TestedValidator{
+func NewTestedValidator(lsp LSPClient, coverageTarget, currentCoverage int, opts ...TestedOption) *TestedValidator {
+	v := &TestedValidator{
 		lsp:             lsp,
 		coverageTarget:  coverageTarget,
 		currentCoverage: currentCoverage,
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
 // Name returns the principle name.
@@ -77,7 +98,18 @@ func (v *TestedValidator) Validate(ctx context.Context) (*PrincipleResult, error
 		})
 	}
 
-	// Calculate score: 3 checks weighted equally.
+	mutationOK := true
+	if v.mutationScore != nil && *v.mutationScore < float64(v.mutationThreshold) {
+		mutationOK = false
+		result.Issues = append(result.Issues, Issue{
+			Severity: SeverityError,
+			Message: fmt.Sprintf("mutation score %.1f%% is below threshold %d%%",
+				*v.mutationScore, v.mutationThreshold),
+			Rule: "mutation-score",
+		})
+	}
+
+	// Calculate score: 3 checks (4 with a mutation score) weighted equally.
 	var score float64
 	checks := 3.0
 
@@ -94,9 +126,17 @@ func (v *TestedValidator) Validate(ctx context.Context) (*PrincipleResult, error
 	} else {
 		score += 1.0 // No coverage target means this check passes.
 	}
+	if v.mutationScore != nil {
+		checks++
+		if mutationOK {
+			score += 1.0
+		} else {
+			score += math.Max(0, *v.mutationScore/float64(v.mutationThreshold))
+		}
+	}
 
 	result.Score = math.Round((score/checks)*1000) / 1000
-	result.Passed = typeErrors == 0 && generalErrors == 0 &&
+	result.Passed = typeErrors == 0 && generalErrors == 0 && mutationOK &&
 		(v.coverageTarget == 0 || v.currentCoverage >= v.coverageTarget)
 
 	return result, nil
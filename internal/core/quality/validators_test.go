@@ -174,6 +174,30 @@ func TestTestedValidator_LSPError(t *testing.T) {
 	}
 }
 
+func TestTestedValidator_MutationScore(t *testing.T) {
+	lsp := &mockLSPClient{}
+
+	result, err := NewTestedValidator(lsp, 85, 90, WithMutationScore(80, 70)).Validate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Passed || result.Score != 1.0 {
+		t.Errorf("score above threshold: passed=%v score=%v, want passed with 1.0", result.Passed, result.Score)
+	}
+
+	result, err = NewTestedValidator(lsp, 85, 90, WithMutationScore(35, 70)).Validate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Passed || result.Score != 0.875 {
+		t.Errorf("score below threshold: passed=%v score=%v, want failed with 0.875", result.Passed, result.Score)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Rule != "mutation-score" ||
+		!strings.Contains(result.Issues[0].Message, "35.0%") {
+		t.Errorf("issues = %+v, want one mutation-score issue", result.Issues)
+	}
+}
+
 // --- ReadableValidator Tests ---
 
 func TestReadableValidator(t *testing.T) {
@@ -21,6 +21,8 @@ const (
 	MemorySubdir   = "memory"
 	LogsSubdir     = "logs"
 	RankSubdir     = "rank"
+	StateSubdir    = "state"
+	LoopSubdir     = "loop"
 )
 
 // Claude subdirectory segments (relative to ClaudeDir).
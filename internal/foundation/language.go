@@ -104,6 +104,13 @@ type LanguageInfo struct {
 	Extensions      []string          `json:"extensions"`
 	TestPattern     string            `json:"test_pattern"`
 	CoverageCommand string            `json:"coverage_command"`
+	// BuildCommand, TestCommand and LintCommand are the command templates
+	// the Stop quality gate and the Ralph feedback loop run for the
+	// language. {packages}, {files} and {tests} expand to the checked Go
+	// packages, source files and test files. Empty means no such check.
+	BuildCommand string `json:"build_command,omitempty"`
+	TestCommand  string `json:"test_command,omitempty"`
+	LintCommand  string `json:"lint_command,omitempty"`
 	// AstGrepLang is the language identifier used by ast-grep CLI.
 	// If empty, the ID is used as the ast-grep language name.
 	// Some languages need special identifiers (e.g., "typescriptreact" for .tsx files).
@@ -136,6 +143,9 @@ var defaultLanguages = []*LanguageInfo{
 		Extensions:      []string{".go"},
 		TestPattern:     "go test ./...",
 		CoverageCommand: "go test -cover ./...",
+		BuildCommand:    "go build {packages}",
+		TestCommand:     "go test -json -cover {packages}",
+		LintCommand:     "go vet {packages}",
 	},
 	{
 		ID:              LangPython,
@@ -143,6 +153,8 @@ var defaultLanguages = []*LanguageInfo{
 		Extensions:      []string{".py", ".pyi"},
 		TestPattern:     "pytest",
 		CoverageCommand: "pytest --cov",
+		TestCommand:     "pytest -q {tests}",
+		LintCommand:     "ruff check {files}",
 	},
 	{
 		ID:              LangTypeScript,
@@ -150,6 +162,9 @@ var defaultLanguages = []*LanguageInfo{
 		Extensions:      []string{".ts", ".tsx", ".mts", ".cts"},
 		TestPattern:     "vitest",
 		CoverageCommand: "vitest --coverage",
+		BuildCommand:    "tsc --noEmit --pretty false",
+		TestCommand:     "vitest run {tests}",
+		LintCommand:     "eslint --format unix {files}",
 		AstGrepLang: map[string]string{
 			".tsx": "typescriptreact",
 		},
@@ -160,6 +175,8 @@ var defaultLanguages = []*LanguageInfo{
 		Extensions:      []string{".js", ".jsx", ".mjs", ".cjs"},
 		TestPattern:     "vitest",
 		CoverageCommand: "vitest --coverage",
+		TestCommand:     "vitest run {tests}",
+		LintCommand:     "eslint --format unix {files}",
 		AstGrepLang: map[string]string{
 			".jsx": "javascriptreact",
 		},
@@ -177,6 +194,9 @@ var defaultLanguages = []*LanguageInfo{
 		Extensions:      []string{".rs"},
 		TestPattern:     "cargo test",
 		CoverageCommand: "cargo tarpaulin",
+		BuildCommand:    "cargo build --quiet",
+		TestCommand:     "cargo test --quiet",
+		LintCommand:     "cargo clippy --quiet",
 	},
 	{
 		ID:              LangC,
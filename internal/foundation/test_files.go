@@ -0,0 +1,108 @@
+package foundation
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// testConvention returns the language of path if the registry knows a test
+// file naming convention for it, or nil otherwise.
+func (r *LanguageRegistry) testConvention(path string) *LanguageInfo {
+	info, err := r.ByExtension(filepath.Ext(path))
+	if err != nil {
+		return nil
+	}
+	switch info.ID {
+	case LangGo, LangPython, LangTypeScript, LangJavaScript, LangJava:
+		return info
+	default:
+		return nil
+	}
+}
+
+// IsTestFile reports whether path is a test file by the naming convention of
+// its language. Files in languages without a known convention are never
+// test files.
+func (r *LanguageRegistry) IsTestFile(path string) bool {
+	info := r.testConvention(path)
+	if info == nil {
+		return false
+	}
+
+	base := filepath.Base(path)
+	slashed := filepath.ToSlash(path)
+	switch info.ID {
+	case LangGo:
+		return strings.HasSuffix(base, "_test.go")
+	case LangPython:
+		stem := strings.TrimSuffix(base, filepath.Ext(base))
+		return strings.HasPrefix(stem, "test_") || strings.HasSuffix(stem, "_test") || stem == "conftest"
+	case LangTypeScript, LangJavaScript:
+		return strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+			strings.Contains(slashed, "/__tests__/")
+	case LangJava:
+		stem := strings.TrimSuffix(base, filepath.Ext(base))
+		return strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests") ||
+			strings.Contains(slashed, "/src/test/")
+	}
+	return false
+}
+
+// IsProductionSource reports whether path is a non-test source file in a
+// language with a known test file convention.
+func (r *LanguageRegistry) IsProductionSource(path string) bool {
+	return r.testConvention(path) != nil && !r.IsTestFile(path)
+}
+
+// TestFileCandidates returns the conventional locations of the test file for
+// a production source file, most common first. root is the project root,
+// used for top-level test directories; it may be empty.
+//
+//	Go:     foo.go -> foo_test.go
+//	Python: foo.py -> test_foo.py, foo_test.py, tests/test_foo.py
+//	JS/TS:  foo.ts -> foo.test.ts, foo.spec.ts, __tests__/foo.test.ts
+//	Java:   src/main/java/x/Foo.java -> src/test/java/x/FooTest.java
+//
+// Returns nil for languages without a known convention.
+func (r *LanguageRegistry) TestFileCandidates(path, root string) []string {
+	info := r.testConvention(path)
+	if info == nil {
+		return nil
+	}
+
+	dir := filepath.Dir(path)
+	ext := filepath.Ext(path)
+	stem := strings.TrimSuffix(filepath.Base(path), ext)
+
+	switch info.ID {
+	case LangGo:
+		return []string{filepath.Join(dir, stem+"_test.go")}
+	case LangPython:
+		candidates := []string{
+			filepath.Join(dir, "test_"+stem+".py"),
+			filepath.Join(dir, stem+"_test.py"),
+			filepath.Join(dir, "tests", "test_"+stem+".py"),
+		}
+		if root != "" {
+			candidates = append(candidates, filepath.Join(root, "tests", "test_"+stem+".py"))
+		}
+		return candidates
+	case LangTypeScript, LangJavaScript:
+		return []string{
+			filepath.Join(dir, stem+".test"+ext),
+			filepath.Join(dir, stem+".spec"+ext),
+			filepath.Join(dir, "__tests__", stem+".test"+ext),
+		}
+	case LangJava:
+		testDir := dir
+		slashed := filepath.ToSlash(dir) + "/"
+		if i := strings.Index(slashed, "/src/main/"); i >= 0 {
+			testDir = filepath.Clean(filepath.FromSlash(slashed[:i] + "/src/test/" + slashed[i+len("/src/main/"):]))
+		}
+		return []string{
+			filepath.Join(testDir, stem+"Test.java"),
+			filepath.Join(testDir, stem+"Tests.java"),
+		}
+	}
+	return nil
+}
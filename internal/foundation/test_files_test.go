@@ -0,0 +1,83 @@
+package foundation
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistryIsTestFile(t *testing.T) {
+	t.Parallel()
+
+	r := NewLanguageRegistry()
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"pkg/calc.go", false},
+		{"pkg/calc_test.go", true},
+		{"app/service.py", false},
+		{"app/test_service.py", true},
+		{"app/service_test.py", true},
+		{"tests/conftest.py", true},
+		{"src/button.tsx", false},
+		{"src/button.test.tsx", true},
+		{"src/button.spec.ts", true},
+		{"src/__tests__/button.js", true},
+		{"src/main/java/com/acme/Order.java", false},
+		{"src/test/java/com/acme/OrderTest.java", true},
+		{"src/main.rs", false},
+		{"README.md", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+			if got := r.IsTestFile(filepath.FromSlash(tt.path)); got != tt.want {
+				t.Errorf("IsTestFile(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistryIsProductionSource(t *testing.T) {
+	t.Parallel()
+
+	r := NewLanguageRegistry()
+	for path, want := range map[string]bool{
+		"pkg/calc.go":      true,
+		"pkg/calc_test.go": false,
+		"src/main.rs":      false, // no test file convention
+		"docs/guide.md":    false,
+	} {
+		if got := r.IsProductionSource(path); got != want {
+			t.Errorf("IsProductionSource(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestRegistryTestFileCandidates(t *testing.T) {
+	t.Parallel()
+
+	r := NewLanguageRegistry()
+	tests := []struct {
+		path string
+		want string // first candidate
+	}{
+		{"/p/pkg/calc.go", "/p/pkg/calc_test.go"},
+		{"/p/app/service.py", "/p/app/test_service.py"},
+		{"/p/src/button.tsx", "/p/src/button.test.tsx"},
+		{"/p/src/main/java/com/acme/Order.java", "/p/src/test/java/com/acme/OrderTest.java"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+			got := r.TestFileCandidates(filepath.FromSlash(tt.path), filepath.FromSlash("/p"))
+			if len(got) == 0 || got[0] != filepath.FromSlash(tt.want) {
+				t.Errorf("TestFileCandidates(%q) = %v, want first %q", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if got := r.TestFileCandidates("/p/src/main.rs", "/p"); got != nil {
+		t.Errorf("languages without a convention should have no candidates, got %v", got)
+	}
+}
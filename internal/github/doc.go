@@ -3,4 +3,9 @@
 // It wraps the gh CLI binary (https://cli.github.com/) via os/exec to provide
 // pull request creation, review, merge, and CI/CD status checking. All GitHub
 // interactions are abstracted behind testable interfaces.
+//
+// Projects hosted on GitLab (git_strategy.provider: gitlab) get the same
+// interfaces from GitLabClient, which uses the GitLab REST API: merge
+// requests stand in for pull requests and pipeline jobs for checks.
+// NewProvider selects the implementation from the configuration.
 package github
@@ -13,9 +13,19 @@ var (
 	// ErrGHNotAuthenticated indicates gh is not authenticated.
 	ErrGHNotAuthenticated = errors.New("github: gh not authenticated")
 
+	// ErrGitLabNotAuthenticated indicates the GitLab token is missing or rejected.
+	ErrGitLabNotAuthenticated = errors.New("github: GitLab token missing or rejected")
+
+	// ErrUnsupportedProvider indicates git_strategy.provider names an unknown service.
+	ErrUnsupportedProvider = errors.New("github: unsupported git provider")
+
 	// ErrPRNotFound indicates the specified pull request does not exist.
 	ErrPRNotFound = errors.New("github: pull request not found")
 
+	// ErrPRHeadUnavailable indicates the head commit of a pull request cannot
+	// be checked out locally.
+	ErrPRHeadUnavailable = errors.New("github: pull request head commit unavailable")
+
 	// ErrPRAlreadyExists indicates a PR already exists for this branch.
 	ErrPRAlreadyExists = errors.New("github: pull request already exists for branch")
 
@@ -72,6 +72,7 @@ type PRDetails struct {
 	State      string    `json:"state"`
 	Mergeable  string    `json:"mergeable"`
 	HeadBranch string    `json:"headRefName"`
+	HeadSHA    string    `json:"headRefOid"`
 	BaseBranch string    `json:"baseRefName"`
 	URL        string    `json:"url"`
 	CreatedAt  time.Time `json:"createdAt"`
@@ -115,23 +116,42 @@ type GHClient interface {
 // ghClient implements GHClient using the gh CLI binary.
 type ghClient struct {
 	root   string
+	exec   ExecFunc
 	logger *slog.Logger
 }
 
 // Compile-time interface compliance check.
 var _ GHClient = (*ghClient)(nil)
 
+// GHClientOption configures a ghClient.
+type GHClientOption func(*ghClient)
+
+// WithGHExecFunc sets a custom gh execution function (used for testing).
+// A nil fn keeps the gh CLI.
+func WithGHExecFunc(fn ExecFunc) GHClientOption {
+	return func(c *ghClient) {
+		if fn != nil {
+			c.exec = fn
+		}
+	}
+}
+
 // NewGHClient creates a new GitHub CLI client rooted at the given directory.
-func NewGHClient(root string) *ghClient {
-	return &ghClient{
+func NewGHClient(root string, opts ...GHClientOption) *ghClient {
+	c := &ghClient{
 		root:   root,
+		exec:   execGH,
 		logger: slog.Default().With("module", "github"),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // IsAuthenticated checks whether the gh CLI is authenticated.
 func (c *ghClient) IsAuthenticated(ctx context.Context) error {
-	_, err := execGH(ctx, c.root, "auth", "status")
+	_, err := c.exec(ctx, c.root, "auth", "status")
 	if err != nil {
 		return fmt.Errorf("check auth: %w", ErrGHNotAuthenticated)
 	}
@@ -157,7 +177,7 @@ func (c *ghClient) PRCreate(ctx context.Context, opts PRCreateOptions) (int, err
 
 	c.logger.Debug("creating pull request", "title", opts.Title, "base", opts.BaseBranch)
 
-	output, err := execGH(ctx, c.root, args...)
+	output, err := c.exec(ctx, c.root, args...)
 	if err != nil {
 		if strings.Contains(err.Error(), "already exists") {
 			return 0, fmt.Errorf("create PR: %w", ErrPRAlreadyExists)
@@ -177,9 +197,9 @@ func (c *ghClient) PRCreate(ctx context.Context, opts PRCreateOptions) (int, err
 
 // PRView retrieves pull request details by number.
 func (c *ghClient) PRView(ctx context.Context, number int) (*PRDetails, error) {
-	output, err := execGH(ctx, c.root,
+	output, err := c.exec(ctx, c.root,
 		"pr", "view", strconv.Itoa(number),
-		"--json", "number,title,state,mergeable,headRefName,baseRefName,url,createdAt",
+		"--json", "number,title,state,mergeable,headRefName,headRefOid,baseRefName,url,createdAt",
 	)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "Could not resolve") {
@@ -218,7 +238,7 @@ func (c *ghClient) PRMerge(ctx context.Context, number int, method MergeMethod,
 
 	c.logger.Debug("merging pull request", "number", number, "method", method)
 
-	_, err := execGH(ctx, c.root, args...)
+	_, err := c.exec(ctx, c.root, args...)
 	if err != nil {
 		if strings.Contains(err.Error(), "conflict") {
 			return fmt.Errorf("merge PR #%d: %w", number, ErrMergeConflict)
@@ -231,21 +251,40 @@ func (c *ghClient) PRMerge(ctx context.Context, number int, method MergeMethod,
 }
 
 // PRChecks returns the CI/CD check status for a pull request.
+//
+// gh exits non-zero while checks are pending or failing, so the JSON it
+// prints is used whatever the exit status.
 func (c *ghClient) PRChecks(ctx context.Context, number int) (*CheckStatus, error) {
-	output, err := execGH(ctx, c.root,
+	output, err := c.exec(ctx, c.root,
 		"pr", "checks", strconv.Itoa(number),
-		"--json", "name,status,conclusion",
+		"--json", "name,state,bucket",
 	)
-	if err != nil {
+	var raw []struct {
+		Name   string `json:"name"`
+		State  string `json:"state"`
+		Bucket string `json:"bucket"`
+	}
+	if jsonErr := json.Unmarshal([]byte(output), &raw); jsonErr != nil {
+		if err == nil {
+			return nil, fmt.Errorf("parse checks JSON for PR #%d: %w", number, jsonErr)
+		}
+		// Checks of a new push may not be registered yet.
+		if strings.Contains(err.Error(), "no checks reported") {
+			return &CheckStatus{Overall: CheckPending}, nil
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return nil, fmt.Errorf("checks PR #%d: %w", number, ErrPRNotFound)
 		}
 		return nil, fmt.Errorf("checks PR #%d: %w", number, err)
 	}
 
-	var checks []Check
-	if err := json.Unmarshal([]byte(output), &checks); err != nil {
-		return nil, fmt.Errorf("parse checks JSON for PR #%d: %w", number, err)
+	checks := make([]Check, 0, len(raw))
+	for _, r := range raw {
+		check := Check{Name: r.Name, Status: "completed", Conclusion: checkBucketConclusions[r.Bucket]}
+		if r.Bucket == "pending" {
+			check.Status = strings.ToLower(r.State)
+		}
+		checks = append(checks, check)
 	}
 
 	status := &CheckStatus{
@@ -256,6 +295,15 @@ func (c *ghClient) PRChecks(ctx context.Context, number int) (*CheckStatus, erro
 	return status, nil
 }
 
+// checkBucketConclusions maps the gh check buckets to check run
+// conclusions.
+var checkBucketConclusions = map[string]string{
+	"pass":     "success",
+	"fail":     "failure",
+	"cancel":   "cancelled",
+	"skipping": "skipped",
+}
+
 // Push pushes the current branch to the remote repository.
 func (c *ghClient) Push(ctx context.Context, dir string) error {
 	workDir := dir
@@ -264,7 +312,11 @@ func (c *ghClient) Push(ctx context.Context, dir string) error {
 	}
 
 	c.logger.Debug("pushing to remote", "dir", workDir)
+	return gitPush(ctx, workDir)
+}
 
+// gitPush pushes the current branch of dir to origin, setting its upstream.
+func gitPush(ctx context.Context, workDir string) error {
 	gitBinOnce.Do(func() {
 		gitBinPath, gitBinErr = exec.LookPath("git")
 	})
@@ -287,7 +339,8 @@ func (c *ghClient) Push(ctx context.Context, dir string) error {
 	return nil
 }
 
-// execGH runs a gh CLI command and returns its stdout output.
+// execGH runs a gh CLI command and returns its stdout output, which is
+// also returned alongside the error of a failing command.
 func execGH(ctx context.Context, dir string, args ...string) (string, error) {
 	ghBinOnce.Do(func() {
 		ghBinPath, ghBinErr = exec.LookPath("gh")
@@ -308,10 +361,11 @@ func execGH(ctx context.Context, dir string, args ...string) (string, error) {
 		if errMsg == "" {
 			errMsg = err.Error()
 		}
+		output := strings.TrimRight(stdout.String(), "\n\r")
 		if len(args) == 0 {
-			return "", fmt.Errorf("gh: %s: %w", errMsg, err)
+			return output, fmt.Errorf("gh: %s: %w", errMsg, err)
 		}
-		return "", fmt.Errorf("gh %s: %s: %w", args[0], errMsg, err)
+		return output, fmt.Errorf("gh %s: %s: %w", args[0], errMsg, err)
 	}
 
 	return strings.TrimRight(stdout.String(), "\n\r"), nil
@@ -2,6 +2,8 @@ package github
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -230,3 +232,71 @@ func TestNewGHClient(t *testing.T) {
 		t.Errorf("root = %q, want %q", client.root, "/tmp/test-repo")
 	}
 }
+
+func TestGHClientPRChecks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		want    CheckConclusion
+		wantErr bool
+	}{
+		{
+			name:   "passing checks",
+			output: `[{"name":"build","state":"SUCCESS","bucket":"pass"},{"name":"lint","state":"SKIPPED","bucket":"skipping"}]`,
+			want:   CheckPass,
+		},
+		{
+			name:   "pending checks exit non-zero",
+			output: `[{"name":"build","state":"SUCCESS","bucket":"pass"},{"name":"test","state":"IN_PROGRESS","bucket":"pending"}]`,
+			err:    errors.New("gh pr: exit status 8"),
+			want:   CheckPending,
+		},
+		{
+			name:   "failing checks exit non-zero",
+			output: `[{"name":"test","state":"FAILURE","bucket":"fail"}]`,
+			err:    errors.New("gh pr: exit status 1"),
+			want:   CheckFail,
+		},
+		{
+			name: "no checks reported is pending",
+			err:  errors.New("gh pr: no checks reported on the 'feature/x' branch"),
+			want: CheckPending,
+		},
+		{
+			name:    "error without output",
+			err:     errors.New("gh pr: exit status 4"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotArgs []string
+			client := NewGHClient("/tmp/repo", WithGHExecFunc(func(_ context.Context, _ string, args ...string) (string, error) {
+				gotArgs = args
+				return tt.output, tt.err
+			}))
+			status, err := client.PRChecks(context.Background(), 7)
+			if strings.Join(gotArgs, " ") != "pr checks 7 --json name,state,bucket" {
+				t.Errorf("args = %v", gotArgs)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("PRChecks() = %+v, want error", status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PRChecks() error = %v", err)
+			}
+			if status.Overall != tt.want {
+				t.Errorf("Overall = %q, want %q (checks %+v)", status.Overall, tt.want, status.Checks)
+			}
+		})
+	}
+}
@@ -0,0 +1,558 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultGitLabURL is the GitLab instance used when none is configured.
+	DefaultGitLabURL = "https://gitlab.com"
+
+	// GitLabTokenEnv is the environment variable holding the personal
+	// access token of the GitLab API.
+	GitLabTokenEnv = "GITLAB_TOKEN"
+
+	// gitlabMaxResponse caps the size of a GitLab API response body.
+	gitlabMaxResponse = 10 << 20
+)
+
+// GitLabClient implements GHClient and IssueParser with the GitLab REST
+// API (v4). Merge requests stand in for pull requests, and the jobs of the
+// latest merge request pipeline for CI checks.
+type GitLabClient struct {
+	root    string
+	apiURL  string
+	project string
+	token   string
+	http    *http.Client
+	logger  *slog.Logger
+}
+
+// Compile-time interface compliance checks.
+var (
+	_ GHClient    = (*GitLabClient)(nil)
+	_ IssueParser = (*GitLabClient)(nil)
+	_ issueOps    = (*GitLabClient)(nil)
+)
+
+// GitLabOption configures a GitLabClient.
+type GitLabOption func(*GitLabClient)
+
+// WithGitLabProject sets the project path (e.g. "group/project") instead
+// of deriving it from the origin remote.
+func WithGitLabProject(path string) GitLabOption {
+	return func(c *GitLabClient) {
+		c.project = strings.Trim(path, "/")
+	}
+}
+
+// WithGitLabToken sets the access token instead of reading GITLAB_TOKEN.
+func WithGitLabToken(token string) GitLabOption {
+	return func(c *GitLabClient) {
+		c.token = token
+	}
+}
+
+// WithGitLabHTTPClient sets the HTTP client of the API requests.
+func WithGitLabHTTPClient(client *http.Client) GitLabOption {
+	return func(c *GitLabClient) {
+		if client != nil {
+			c.http = client
+		}
+	}
+}
+
+// NewGitLabClient creates a GitLab client for the repository at root on
+// the instance at instanceURL (DefaultGitLabURL when empty). The project
+// path is derived from the origin remote of root unless WithGitLabProject
+// is given.
+func NewGitLabClient(root, instanceURL string, opts ...GitLabOption) (*GitLabClient, error) {
+	instanceURL = strings.TrimRight(strings.TrimSpace(instanceURL), "/")
+	if instanceURL == "" {
+		instanceURL = DefaultGitLabURL
+	}
+	c := &GitLabClient{
+		root:   root,
+		apiURL: instanceURL + "/api/v4",
+		token:  os.Getenv(GitLabTokenEnv),
+		http:   &http.Client{Timeout: 30 * time.Second},
+		logger: slog.Default().With("module", "github.gitlab"),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.project == "" {
+		remote, err := gitOutput(context.Background(), root, "remote", "get-url", "origin")
+		if err != nil {
+			return nil, fmt.Errorf("resolve GitLab project: %w", err)
+		}
+		project, err := gitlabProjectPath(remote, instanceURL)
+		if err != nil {
+			return nil, fmt.Errorf("resolve GitLab project: %w", err)
+		}
+		c.project = project
+	}
+	return c, nil
+}
+
+// IssueCloser returns an IssueCloser closing issues through the GitLab
+// API with the retry behavior of DefaultIssueCloser.
+func (c *GitLabClient) IssueCloser(opts ...IssueCloserOption) *DefaultIssueCloser {
+	closer := NewIssueCloser(c.root, opts...)
+	closer.ops = c
+	return closer
+}
+
+// IsAuthenticated checks that a token is set and accepted by the instance.
+func (c *GitLabClient) IsAuthenticated(ctx context.Context) error {
+	if c.token == "" {
+		return fmt.Errorf("check auth: %w (set %s)", ErrGitLabNotAuthenticated, GitLabTokenEnv)
+	}
+	if err := c.do(ctx, http.MethodGet, "/user", nil, nil); err != nil {
+		if status := gitlabStatus(err); status == http.StatusUnauthorized || status == http.StatusForbidden {
+			return fmt.Errorf("check auth: %w", ErrGitLabNotAuthenticated)
+		}
+		return fmt.Errorf("check auth: %w", err)
+	}
+	return nil
+}
+
+// gitlabMergeRequest is the merge request representation of the API.
+type gitlabMergeRequest struct {
+	IID                 int       `json:"iid"`
+	Title               string    `json:"title"`
+	State               string    `json:"state"`
+	MergeStatus         string    `json:"merge_status"`
+	DetailedMergeStatus string    `json:"detailed_merge_status"`
+	HasConflicts        bool      `json:"has_conflicts"`
+	SourceBranch        string    `json:"source_branch"`
+	SHA                 string    `json:"sha"`
+	TargetBranch        string    `json:"target_branch"`
+	WebURL              string    `json:"web_url"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// details converts a merge request to the PRDetails of a pull request,
+// mapping states and merge status to their gh equivalents.
+func (mr *gitlabMergeRequest) details() *PRDetails {
+	state := strings.ToUpper(mr.State)
+	switch mr.State {
+	case "opened":
+		state = "OPEN"
+	case "locked":
+		state = "CLOSED"
+	}
+
+	mergeable := "UNKNOWN"
+	switch {
+	case mr.HasConflicts || mr.MergeStatus == "cannot_be_merged" || mr.DetailedMergeStatus == "conflict":
+		mergeable = "CONFLICTING"
+	case mr.MergeStatus == "can_be_merged":
+		mergeable = "MERGEABLE"
+	}
+
+	return &PRDetails{
+		Number:     mr.IID,
+		Title:      mr.Title,
+		State:      state,
+		Mergeable:  mergeable,
+		HeadBranch: mr.SourceBranch,
+		HeadSHA:    mr.SHA,
+		BaseBranch: mr.TargetBranch,
+		URL:        mr.WebURL,
+		CreatedAt:  mr.CreatedAt,
+	}
+}
+
+// PRCreate creates a merge request and returns its IID. The head branch
+// defaults to the current branch and the base to the project's default
+// branch.
+func (c *GitLabClient) PRCreate(ctx context.Context, opts PRCreateOptions) (int, error) {
+	head := opts.HeadBranch
+	if head == "" {
+		branch, err := gitOutput(ctx, c.root, "rev-parse", "--abbrev-ref", "HEAD")
+		if err != nil {
+			return 0, fmt.Errorf("create MR: resolve current branch: %w", err)
+		}
+		head = branch
+	}
+	base := opts.BaseBranch
+	if base == "" {
+		var project struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := c.do(ctx, http.MethodGet, c.projectPath(""), nil, &project); err != nil {
+			return 0, fmt.Errorf("create MR: resolve default branch: %w", err)
+		}
+		base = project.DefaultBranch
+	}
+
+	body := map[string]any{
+		"source_branch": head,
+		"target_branch": base,
+		"title":         opts.Title,
+		"description":   opts.Body,
+	}
+	if len(opts.Labels) > 0 {
+		body["labels"] = strings.Join(opts.Labels, ",")
+	}
+
+	c.logger.Debug("creating merge request", "title", opts.Title, "source", head, "target", base)
+
+	var mr gitlabMergeRequest
+	if err := c.do(ctx, http.MethodPost, c.projectPath("/merge_requests"), body, &mr); err != nil {
+		if gitlabStatus(err) == http.StatusConflict {
+			return 0, fmt.Errorf("create MR: %w", ErrPRAlreadyExists)
+		}
+		return 0, fmt.Errorf("create MR: %w", err)
+	}
+
+	c.logger.Info("merge request created", "iid", mr.IID, "title", opts.Title)
+	return mr.IID, nil
+}
+
+// PRView retrieves merge request details by IID.
+func (c *GitLabClient) PRView(ctx context.Context, number int) (*PRDetails, error) {
+	var mr gitlabMergeRequest
+	if err := c.do(ctx, http.MethodGet, c.projectPath(fmt.Sprintf("/merge_requests/%d", number)), nil, &mr); err != nil {
+		if gitlabStatus(err) == http.StatusNotFound {
+			return nil, fmt.Errorf("view MR !%d: %w", number, ErrPRNotFound)
+		}
+		return nil, fmt.Errorf("view MR !%d: %w", number, err)
+	}
+	return mr.details(), nil
+}
+
+// PRMerge merges a merge request. GitLab applies the merge method of the
+// project, so only squashing can be chosen per merge request and
+// MergeMethodRebase is rejected.
+func (c *GitLabClient) PRMerge(ctx context.Context, number int, method MergeMethod, deleteBranch bool) error {
+	var squash bool
+	switch method {
+	case MergeMethodMerge:
+	case MergeMethodSquash:
+		squash = true
+	default:
+		return fmt.Errorf("merge MR !%d: unsupported merge method %q (GitLab uses the project merge method; use merge or squash)", number, method)
+	}
+
+	c.logger.Debug("merging merge request", "iid", number, "method", method)
+
+	body := map[string]any{
+		"squash":                      squash,
+		"should_remove_source_branch": deleteBranch,
+	}
+	if err := c.do(ctx, http.MethodPut, c.projectPath(fmt.Sprintf("/merge_requests/%d/merge", number)), body, nil); err != nil {
+		switch gitlabStatus(err) {
+		case http.StatusNotFound:
+			return fmt.Errorf("merge MR !%d: %w", number, ErrPRNotFound)
+		case http.StatusMethodNotAllowed:
+			return fmt.Errorf("merge MR !%d: %w: %v", number, ErrMergeBlocked, err)
+		case http.StatusNotAcceptable:
+			return fmt.Errorf("merge MR !%d: %w", number, ErrMergeConflict)
+		}
+		return fmt.Errorf("merge MR !%d: %w", number, err)
+	}
+
+	c.logger.Info("merge request merged", "iid", number, "method", method)
+	return nil
+}
+
+// gitlabJob is a pipeline job of the API.
+type gitlabJob struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	AllowFailure bool   `json:"allow_failure"`
+}
+
+// check converts a job to a check run. Failed jobs allowed to fail are
+// neutral, and manual jobs count as skipped.
+func (j gitlabJob) check() Check {
+	check := Check{Name: j.Name, Status: "completed"}
+	switch j.Status {
+	case "success":
+		check.Conclusion = "success"
+	case "failed":
+		check.Conclusion = "failure"
+		if j.AllowFailure {
+			check.Conclusion = "neutral"
+		}
+	case "canceled":
+		check.Conclusion = "cancelled"
+	case "skipped", "manual":
+		check.Conclusion = "skipped"
+	default:
+		check.Status = j.Status
+	}
+	return check
+}
+
+// PRChecks returns the jobs of the latest pipeline of a merge request as
+// its CI checks. Without a pipeline the checks are pending.
+func (c *GitLabClient) PRChecks(ctx context.Context, number int) (*CheckStatus, error) {
+	var pipelines []struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.projectPath(fmt.Sprintf("/merge_requests/%d/pipelines", number)), nil, &pipelines); err != nil {
+		if gitlabStatus(err) == http.StatusNotFound {
+			return nil, fmt.Errorf("checks MR !%d: %w", number, ErrPRNotFound)
+		}
+		return nil, fmt.Errorf("checks MR !%d: %w", number, err)
+	}
+
+	var checks []Check
+	if len(pipelines) > 0 {
+		// Pipelines are listed newest first.
+		var jobs []gitlabJob
+		path := c.projectPath(fmt.Sprintf("/pipelines/%d/jobs?per_page=100", pipelines[0].ID))
+		if err := c.do(ctx, http.MethodGet, path, nil, &jobs); err != nil {
+			return nil, fmt.Errorf("checks MR !%d: pipeline %d: %w", number, pipelines[0].ID, err)
+		}
+		checks = make([]Check, 0, len(jobs))
+		for _, job := range jobs {
+			checks = append(checks, job.check())
+		}
+	}
+
+	return &CheckStatus{
+		Overall: deriveOverallConclusion(checks),
+		Checks:  checks,
+	}, nil
+}
+
+// Push pushes the current branch to the remote repository.
+func (c *GitLabClient) Push(ctx context.Context, dir string) error {
+	workDir := dir
+	if workDir == "" {
+		workDir = c.root
+	}
+
+	c.logger.Debug("pushing to remote", "dir", workDir)
+	return gitPush(ctx, workDir)
+}
+
+// ParseIssue fetches a GitLab issue by IID together with its user notes.
+func (c *GitLabClient) ParseIssue(ctx context.Context, number int) (*Issue, error) {
+	if number <= 0 {
+		return nil, fmt.Errorf("parse issue: invalid issue number %d", number)
+	}
+
+	var raw struct {
+		IID         int      `json:"iid"`
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Labels      []string `json:"labels"`
+		Author      struct {
+			Username string `json:"username"`
+		} `json:"author"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.projectPath(fmt.Sprintf("/issues/%d", number)), nil, &raw); err != nil {
+		if gitlabStatus(err) == http.StatusNotFound {
+			return nil, fmt.Errorf("parse issue #%d: %w", number, ErrIssueNotFound)
+		}
+		return nil, fmt.Errorf("parse issue #%d: %w", number, err)
+	}
+	if raw.Title == "" {
+		return nil, fmt.Errorf("parse issue #%d: empty title", number)
+	}
+
+	var notes []struct {
+		Body   string `json:"body"`
+		System bool   `json:"system"`
+		Author struct {
+			Username string `json:"username"`
+		} `json:"author"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	notesPath := c.projectPath(fmt.Sprintf("/issues/%d/notes?sort=asc&per_page=100", number))
+	if err := c.do(ctx, http.MethodGet, notesPath, nil, &notes); err != nil {
+		return nil, fmt.Errorf("parse issue #%d: notes: %w", number, err)
+	}
+
+	issue := &Issue{
+		Number: raw.IID,
+		Title:  raw.Title,
+		Body:   raw.Description,
+		Labels: make([]Label, 0, len(raw.Labels)),
+		Author: Author{Login: raw.Author.Username},
+	}
+	for _, name := range raw.Labels {
+		issue.Labels = append(issue.Labels, Label{Name: name})
+	}
+	for _, note := range notes {
+		// System notes record events such as label changes.
+		if note.System {
+			continue
+		}
+		issue.Comments = append(issue.Comments, Comment{
+			Body:      note.Body,
+			Author:    Author{Login: note.Author.Username},
+			CreatedAt: note.CreatedAt,
+		})
+	}
+	return issue, nil
+}
+
+func (c *GitLabClient) comment(ctx context.Context, number int, body string) error {
+	return c.do(ctx, http.MethodPost, c.projectPath(fmt.Sprintf("/issues/%d/notes", number)), map[string]any{"body": body}, nil)
+}
+
+func (c *GitLabClient) addLabel(ctx context.Context, number int, label string) error {
+	return c.do(ctx, http.MethodPut, c.projectPath(fmt.Sprintf("/issues/%d", number)), map[string]any{"add_labels": label}, nil)
+}
+
+func (c *GitLabClient) closeIssue(ctx context.Context, number int) error {
+	return c.do(ctx, http.MethodPut, c.projectPath(fmt.Sprintf("/issues/%d", number)), map[string]any{"state_event": "close"}, nil)
+}
+
+// projectPath returns the API path of suffix within the project.
+func (c *GitLabClient) projectPath(suffix string) string {
+	return "/projects/" + url.PathEscape(c.project) + suffix
+}
+
+// gitlabAPIError is a non-success response of the GitLab API.
+type gitlabAPIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error returns the status code and message of the response.
+func (e *gitlabAPIError) Error() string {
+	return fmt.Sprintf("gitlab API: %d %s", e.StatusCode, e.Message)
+}
+
+// gitlabStatus returns the HTTP status of a gitlabAPIError in err, or zero.
+func gitlabStatus(err error) int {
+	var apiErr *gitlabAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// do sends an API request with body encoded as JSON and decodes the JSON
+// response into out when it is not nil.
+func (c *GitLabClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("gitlab API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, gitlabMaxResponse))
+	if err != nil {
+		return fmt.Errorf("gitlab API: read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &gitlabAPIError{StatusCode: resp.StatusCode, Message: gitlabErrorMessage(resp.StatusCode, data)}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("gitlab API: parse %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// gitlabErrorMessage extracts the message or error field of an API error
+// response, falling back to the status text.
+func gitlabErrorMessage(status int, data []byte) string {
+	var payload struct {
+		Message any    `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(data, &payload) == nil {
+		switch {
+		case payload.Message != nil:
+			if msg, ok := payload.Message.(string); ok {
+				return msg
+			}
+			encoded, _ := json.Marshal(payload.Message)
+			return string(encoded)
+		case payload.Error != "":
+			return payload.Error
+		}
+	}
+	return http.StatusText(status)
+}
+
+// gitlabProjectPath extracts the project path ("group/project") from a git
+// remote URL: an HTTP(S) URL below instanceURL, an ssh:// URL or an
+// scp-like git@host:group/project address.
+func gitlabProjectPath(remote, instanceURL string) (string, error) {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), ".git")
+
+	var path string
+	if prefix := strings.TrimRight(instanceURL, "/") + "/"; strings.HasPrefix(remote, prefix) {
+		path = strings.TrimPrefix(remote, prefix)
+	} else if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		path = u.Path
+	} else if _, after, ok := strings.Cut(remote, ":"); ok {
+		path = after
+	}
+
+	path = strings.Trim(path, "/")
+	if !strings.Contains(path, "/") {
+		return "", fmt.Errorf("no GitLab project path in remote %q", remote)
+	}
+	return path, nil
+}
+
+// gitOutput runs a git command in dir and returns its trimmed output.
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	gitBinOnce.Do(func() {
+		gitBinPath, gitBinErr = exec.LookPath("git")
+	})
+	if gitBinErr != nil {
+		return "", fmt.Errorf("git not found: %w", gitBinErr)
+	}
+
+	cmd := exec.CommandContext(ctx, gitBinPath, args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s: %w", args[0], errMsg, err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -39,6 +39,7 @@ type IssueCloser interface {
 type DefaultIssueCloser struct {
 	root       string
 	exec       ExecFunc
+	ops        issueOps
 	maxRetries int
 	retryDelay time.Duration
 	logger     *slog.Logger
@@ -117,12 +118,14 @@ func (c *DefaultIssueCloser) Close(ctx context.Context, issueNumber int, comment
 		IssueNumber: issueNumber,
 	}
 
-	num := strconv.Itoa(issueNumber)
+	ops := c.ops
+	if ops == nil {
+		ops = &ghIssueOps{root: c.root, exec: c.exec}
+	}
 
 	// Step 1: Post success comment (critical).
 	if err := c.retryOp(ctx, "post comment", func() error {
-		_, execErr := c.exec(ctx, c.root, "issue", "comment", num, "--body", comment)
-		return execErr
+		return ops.comment(ctx, issueNumber, comment)
 	}); err != nil {
 		return result, fmt.Errorf("close issue #%d: %w", issueNumber, err)
 	}
@@ -130,8 +133,7 @@ func (c *DefaultIssueCloser) Close(ctx context.Context, issueNumber int, comment
 
 	// Step 2: Add "resolved" label (non-critical).
 	if err := c.retryOp(ctx, "add label", func() error {
-		_, execErr := c.exec(ctx, c.root, "issue", "edit", num, "--add-label", "resolved")
-		return execErr
+		return ops.addLabel(ctx, issueNumber, "resolved")
 	}); err != nil {
 		c.logger.Warn("failed to add resolved label",
 			"issue", issueNumber,
@@ -144,8 +146,7 @@ func (c *DefaultIssueCloser) Close(ctx context.Context, issueNumber int, comment
 
 	// Step 3: Close issue (critical).
 	if err := c.retryOp(ctx, "close issue", func() error {
-		_, execErr := c.exec(ctx, c.root, "issue", "close", num)
-		return execErr
+		return ops.closeIssue(ctx, issueNumber)
 	}); err != nil {
 		return result, fmt.Errorf("close issue #%d: %w", issueNumber, err)
 	}
@@ -160,6 +161,35 @@ func (c *DefaultIssueCloser) Close(ctx context.Context, issueNumber int, comment
 	return result, nil
 }
 
+// issueOps performs the single steps of an issue closure on the hosting
+// service.
+type issueOps interface {
+	comment(ctx context.Context, number int, body string) error
+	addLabel(ctx context.Context, number int, label string) error
+	closeIssue(ctx context.Context, number int) error
+}
+
+// ghIssueOps implements issueOps with gh commands.
+type ghIssueOps struct {
+	root string
+	exec ExecFunc
+}
+
+func (o *ghIssueOps) comment(ctx context.Context, number int, body string) error {
+	_, err := o.exec(ctx, o.root, "issue", "comment", strconv.Itoa(number), "--body", body)
+	return err
+}
+
+func (o *ghIssueOps) addLabel(ctx context.Context, number int, label string) error {
+	_, err := o.exec(ctx, o.root, "issue", "edit", strconv.Itoa(number), "--add-label", label)
+	return err
+}
+
+func (o *ghIssueOps) closeIssue(ctx context.Context, number int) error {
+	_, err := o.exec(ctx, o.root, "issue", "close", strconv.Itoa(number))
+	return err
+}
+
 // retryOp executes an operation with exponential backoff retry.
 // Returns ErrMaxRetriesExceeded (wrapped in RetryError) if all attempts fail.
 func (c *DefaultIssueCloser) retryOp(ctx context.Context, operation string, fn func() error) error {
@@ -52,26 +52,27 @@ type IssueParser interface {
 // ghIssueParser implements IssueParser using the gh CLI.
 type ghIssueParser struct {
 	root string
+	exec ExecFunc
 }
 
 // NewIssueParser returns an IssueParser that uses the gh CLI.
 // The root parameter sets the working directory for gh commands,
 // which must be inside the target GitHub repository.
 func NewIssueParser(root string) IssueParser {
-	return &ghIssueParser{root: root}
+	return &ghIssueParser{root: root, exec: execGH}
 }
 
 // issueFields is the comma-separated list of fields to request from gh.
 const issueFields = "number,title,body,labels,author,comments"
 
 // ParseIssue fetches and parses a GitHub issue by number using the gh CLI.
-// Reuses the package-level execGH helper for consistent command execution.
+// Runs gh through the parser's ExecFunc, execGH unless a provider sets one.
 func (p *ghIssueParser) ParseIssue(ctx context.Context, number int) (*Issue, error) {
 	if number <= 0 {
 		return nil, fmt.Errorf("parse issue: invalid issue number %d", number)
 	}
 
-	output, err := execGH(ctx, p.root, "issue", "view",
+	output, err := p.exec(ctx, p.root, "issue", "view",
 		strconv.Itoa(number), "--json", issueFields)
 	if err != nil {
 		return nil, fmt.Errorf("parse issue #%d: %w", number, err)
@@ -0,0 +1,78 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// PRHeadCheckout is a directory whose working tree is the head commit of a
+// pull request.
+type PRHeadCheckout struct {
+	// Dir is the checked out directory.
+	Dir string
+
+	// Temporary reports whether Dir is a detached worktree created for the
+	// checkout and removed by Close.
+	Temporary bool
+
+	root string
+}
+
+// Close removes the temporary worktree of the checkout, if any.
+func (c *PRHeadCheckout) Close() error {
+	if !c.Temporary {
+		return nil
+	}
+	_, err := gitOutput(context.Background(), c.root, "worktree", "remove", "--force", c.Dir)
+	if rmErr := os.RemoveAll(c.Dir); err == nil && rmErr != nil {
+		err = rmErr
+	}
+	return err
+}
+
+// CheckoutPRHead returns a directory at the head commit of the pull request
+// described by details in the repository at root. The first candidate
+// directory whose HEAD is that commit is used as is; otherwise the commit
+// is fetched from origin under the provider's HeadRef when it is missing
+// and checked out in a temporary detached worktree. The returned checkout
+// must be closed.
+func (p *Provider) CheckoutPRHead(ctx context.Context, root string, details *PRDetails, candidates ...string) (*PRHeadCheckout, error) {
+	sha := details.HeadSHA
+	if sha == "" {
+		return nil, fmt.Errorf("%s: head commit not reported: %w", p.RequestRef(details.Number), ErrPRHeadUnavailable)
+	}
+
+	for _, dir := range candidates {
+		if head, err := gitOutput(ctx, dir, "rev-parse", "HEAD"); err == nil && head == sha {
+			return &PRHeadCheckout{Dir: dir, root: root}, nil
+		}
+	}
+
+	if _, err := gitOutput(ctx, root, "cat-file", "-e", sha+"^{commit}"); err != nil {
+		if _, err := gitOutput(ctx, root, "fetch", "--quiet", "origin", p.HeadRef(details.Number)); err != nil {
+			return nil, fmt.Errorf("%s: fetch head %s: %v: %w", p.RequestRef(details.Number), shortSHA(sha), err, ErrPRHeadUnavailable)
+		}
+		if _, err := gitOutput(ctx, root, "cat-file", "-e", sha+"^{commit}"); err != nil {
+			return nil, fmt.Errorf("%s: head %s not found after fetch: %w", p.RequestRef(details.Number), shortSHA(sha), ErrPRHeadUnavailable)
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "moai-pr-head-*")
+	if err != nil {
+		return nil, fmt.Errorf("create worktree directory: %w", err)
+	}
+	if _, err := gitOutput(ctx, root, "worktree", "add", "--detach", dir, sha); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("%s: check out head %s: %v: %w", p.RequestRef(details.Number), shortSHA(sha), err, ErrPRHeadUnavailable)
+	}
+	return &PRHeadCheckout{Dir: dir, Temporary: true, root: root}, nil
+}
+
+// shortSHA abbreviates a commit hash for messages.
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
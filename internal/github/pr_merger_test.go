@@ -160,6 +160,36 @@ func TestMerge_ReviewNotApproved(t *testing.T) {
 	}
 }
 
+func TestMerge_NoChecksReported(t *testing.T) {
+	t.Parallel()
+
+	gh := &mockGHClient{
+		prViewResult: &PRDetails{
+			Number:    104,
+			State:     "OPEN",
+			Mergeable: "MERGEABLE",
+		},
+		prChecksResult: &CheckStatus{Overall: CheckPending},
+	}
+	rev := &mockPRReviewer{
+		report: &ReviewReport{
+			PRNumber:      104,
+			Decision:      ReviewApprove,
+			QualityReport: &quality.Report{Passed: true, Score: 1.0},
+		},
+	}
+	merger := mustNewPRMerger(t, gh, rev, nil)
+
+	_, err := merger.Merge(context.Background(), 104, MergeOptions{
+		AutoMerge:     true,
+		RequireReview: true,
+		RequireChecks: true,
+	})
+	if !errors.Is(err, ErrMergeBlocked) {
+		t.Errorf("error = %v, want ErrMergeBlocked while no checks are reported", err)
+	}
+}
+
 func TestMerge_CIFailed(t *testing.T) {
 	t.Parallel()
 
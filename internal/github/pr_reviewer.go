@@ -163,7 +163,9 @@ func (r *prReviewer) Review(ctx context.Context, prNumber int, specID string, in
 				}
 			}
 		case CheckPending:
-			if report.Decision == ReviewApprove {
+			// Without any reported checks there is nothing to review;
+			// PRMerger still holds the merge while RequireChecks is set.
+			if report.Decision == ReviewApprove && len(checkStatus.Checks) > 0 {
 				report.Decision = ReviewComment
 				report.Issues = append(report.Issues, "CI/CD checks still pending")
 			}
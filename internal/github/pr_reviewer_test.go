@@ -203,6 +203,27 @@ func TestReview_CIPending_Comment(t *testing.T) {
 	}
 }
 
+func TestReview_NoChecksReported_Approve(t *testing.T) {
+	t.Parallel()
+
+	gh := &mockGHClient{
+		prViewResult:   &PRDetails{Number: 46, State: "OPEN"},
+		prChecksResult: &CheckStatus{Overall: CheckPending},
+	}
+	gate := &mockQualityGate{
+		report: &quality.Report{Passed: true, Score: 1.0},
+	}
+	r := mustNewPRReviewer(t, gh, gate, nil)
+
+	report, err := r.Review(context.Background(), 46, "SPEC-ISSUE-46", nil)
+	if err != nil {
+		t.Fatalf("Review() error = %v", err)
+	}
+	if report.Decision != ReviewApprove {
+		t.Errorf("Decision = %q, want %q without reported checks", report.Decision, ReviewApprove)
+	}
+}
+
 func TestReview_PRNotFound(t *testing.T) {
 	t.Parallel()
 
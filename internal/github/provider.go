@@ -0,0 +1,119 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+)
+
+// Provider names accepted in git_strategy.provider.
+const (
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+)
+
+// Provider bundles the clients of the Git hosting service of a project:
+// pull (or merge) requests with their CI checks, issue parsing and issue
+// closing.
+type Provider struct {
+	// Name is the provider name, ProviderGitHub or ProviderGitLab.
+	Name string
+
+	// Client manages pull requests and their checks.
+	Client GHClient
+
+	// Issues parses issues.
+	Issues IssueParser
+
+	newCloser func(opts ...IssueCloserOption) IssueCloser
+}
+
+// IssueCloser returns an IssueCloser of the provider configured by opts.
+func (p *Provider) IssueCloser(opts ...IssueCloserOption) IssueCloser {
+	return p.newCloser(opts...)
+}
+
+// RequestRef returns how the provider refers to a pull request: "PR #42"
+// on GitHub, "MR !42" (a merge request) on GitLab.
+func (p *Provider) RequestRef(number int) string {
+	if p.Name == ProviderGitLab {
+		return fmt.Sprintf("MR !%d", number)
+	}
+	return fmt.Sprintf("PR #%d", number)
+}
+
+// HeadRef returns the ref under which the provider publishes the head of a
+// pull request: refs/pull/<n>/head on GitHub, refs/merge-requests/<n>/head
+// on GitLab.
+func (p *Provider) HeadRef(number int) string {
+	if p.Name == ProviderGitLab {
+		return fmt.Sprintf("refs/merge-requests/%d/head", number)
+	}
+	return fmt.Sprintf("refs/pull/%d/head", number)
+}
+
+// providerOptions holds the settings of NewProvider.
+type providerOptions struct {
+	exec   ExecFunc
+	gitlab []GitLabOption
+}
+
+// ProviderOption configures NewProvider.
+type ProviderOption func(*providerOptions)
+
+// WithProviderExecFunc sets the gh execution function of the GitHub
+// provider (used for testing). A nil fn keeps the gh CLI.
+func WithProviderExecFunc(fn ExecFunc) ProviderOption {
+	return func(o *providerOptions) {
+		o.exec = fn
+	}
+}
+
+// WithGitLabOptions passes options to the client of the GitLab provider.
+func WithGitLabOptions(opts ...GitLabOption) ProviderOption {
+	return func(o *providerOptions) {
+		o.gitlab = append(o.gitlab, opts...)
+	}
+}
+
+// NewProvider returns the provider selected by gs.Provider for the
+// repository at root: GitHub through the gh CLI (the default), or GitLab
+// through the REST API of gs.GitLabInstanceURL.
+func NewProvider(root string, gs config.GitStrategyConfig, opts ...ProviderOption) (*Provider, error) {
+	o := &providerOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(gs.Provider)) {
+	case "", ProviderGitHub:
+		exec := o.exec
+		if exec == nil {
+			exec = execGH
+		}
+		return &Provider{
+			Name:   ProviderGitHub,
+			Client: NewGHClient(root, WithGHExecFunc(exec)),
+			Issues: &ghIssueParser{root: root, exec: exec},
+			newCloser: func(opts ...IssueCloserOption) IssueCloser {
+				return NewIssueCloser(root, append([]IssueCloserOption{WithExecFunc(exec)}, opts...)...)
+			},
+		}, nil
+	case ProviderGitLab:
+		client, err := NewGitLabClient(root, gs.GitLabInstanceURL, o.gitlab...)
+		if err != nil {
+			return nil, err
+		}
+		return &Provider{
+			Name:   ProviderGitLab,
+			Client: client,
+			Issues: client,
+			newCloser: func(opts ...IssueCloserOption) IssueCloser {
+				return client.IssueCloser(opts...)
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w %q (use %s or %s)", ErrUnsupportedProvider, gs.Provider, ProviderGitHub, ProviderGitLab)
+	}
+}
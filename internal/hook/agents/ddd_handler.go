@@ -2,7 +2,11 @@ package agents
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
+	"os"
 
+	"github.com/modu-ai/moai-adk/internal/foundation"
 	"github.com/modu-ai/moai-adk/internal/hook"
 )
 
@@ -32,27 +36,37 @@ func NewDDDHandler(action string) hook.Handler {
 }
 
 // Handle processes DDD workflow hooks.
-// For now, this logs and allows all actions. Implement specific logic as needed.
+// For pre-transformation (PRESERVE phase), a Write/Edit to an existing
+// production source file is denied until characterization tests for it
+// exist. New files are not transformations and are allowed.
+// Other actions allow the tool call.
 func (h *dddHandler) Handle(ctx context.Context, input *hook.HookInput) (*hook.HookOutput, error) {
-	// Log the DDD action for debugging
-	// TODO: Implement specific DDD workflow logic
-	// - pre-transformation: Check if characterization tests exist before modifying code
-	// - post-transformation: Verify behavior preservation after transformation
-	// - completion: Report DDD workflow completion
-
-	switch h.action {
-	case "pre-transformation":
-		// DDD ANALYZE phase: Check if we understand existing behavior
-		return hook.NewAllowOutput(), nil
-	case "post-transformation":
-		// DDD IMPROVE phase: Verify behavior preservation
+	if h.action != "pre-transformation" {
+		return h.baseHandler.Handle(ctx, input)
+	}
+
+	path := hook.EditedFile(input)
+	if path == "" || !foundation.DefaultRegistry.IsProductionSource(path) {
 		return hook.NewAllowOutput(), nil
-	case "completion":
-		// DDD workflow completed
+	}
+	if _, err := os.Stat(path); err != nil {
 		return hook.NewAllowOutput(), nil
-	default:
+	}
+
+	root := projectRoot(input)
+	if existingTestFile(path, root) != "" {
 		return hook.NewAllowOutput(), nil
 	}
+
+	candidates := foundation.DefaultRegistry.TestFileCandidates(path, root)
+	slog.Info("ddd: transformation without characterization tests",
+		"file", path,
+		"expected_test", candidates[0],
+	)
+	return hook.NewDenyOutput(fmt.Sprintf(
+		"DDD PRESERVE phase: add characterization tests in %s that record the current behavior of %s before transforming it.",
+		hook.DisplayPath(root, candidates[0]), hook.DisplayPath(root, path),
+	)), nil
 }
 
 func (h *dddHandler) EventType() hook.EventType {
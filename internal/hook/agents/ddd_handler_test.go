@@ -0,0 +1,47 @@
+package agents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/hook"
+)
+
+func TestDDDHandler_PreTransformation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		setup    map[string]string
+		file     string
+		wantDeny bool
+	}{
+		{"existing file without characterization tests", map[string]string{"order.go": "package order\n"}, "order.go", true},
+		{"existing file with tests", map[string]string{"order.go": "package order\n", "order_test.go": "package order\n"}, "order.go", false},
+		{"new file is not a transformation", nil, "order.go", false},
+		{"java with mirrored test tree", map[string]string{
+			"src/main/java/acme/Order.java":     "class Order {}",
+			"src/test/java/acme/OrderTest.java": "class OrderTest {}",
+		}, "src/main/java/acme/Order.java", false},
+		{"editing a test file", map[string]string{"order_test.go": "package order\n"}, "order_test.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			for file, content := range tt.setup {
+				writeFile(t, dir, file, content)
+			}
+
+			h := NewDDDHandler("pre-transformation")
+			out, err := h.Handle(context.Background(), writeToolInput(t, "Edit", dir, tt.file))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			denied := out.HookSpecificOutput != nil && out.HookSpecificOutput.PermissionDecision == hook.DecisionDeny
+			if denied != tt.wantDeny {
+				t.Errorf("denied = %v, want %v (%+v)", denied, tt.wantDeny, out.HookSpecificOutput)
+			}
+		})
+	}
+}
@@ -1,7 +1,15 @@
 package agents
 
 import (
+	"bufio"
 	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/modu-ai/moai-adk/internal/hook"
 )
@@ -29,12 +37,131 @@ func NewDocsHandler(action string) hook.Handler {
 }
 
 // Handle processes documentation hooks.
+// For verification, a written markdown file is checked for relative links
+// and images that do not resolve to a file, and the tool result is blocked
+// with the list of broken links so they get fixed.
+// Other actions allow the tool call.
 func (h *docsHandler) Handle(ctx context.Context, input *hook.HookInput) (*hook.HookOutput, error) {
-	// TODO: Implement documentation-specific logic
-	// - verification: Verify generated documentation quality
-	// - completion: Report documentation generation completion
+	if h.action != "verification" {
+		return h.baseHandler.Handle(ctx, input)
+	}
+
+	path := hook.EditedFile(input)
+	if path == "" || !isMarkdownFile(path) {
+		return hook.NewPostToolOutput(""), nil
+	}
+
+	root := projectRoot(input)
+	broken, err := brokenMarkdownLinks(path, root)
+	if err != nil {
+		slog.Warn("docs: cannot verify markdown links", "file", path, "error", err)
+		return hook.NewPostToolOutput(""), nil
+	}
+	if len(broken) == 0 {
+		return hook.NewPostToolOutput(""), nil
+	}
+
+	const maxListed = 10
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s has %d broken link(s):", hook.DisplayPath(root, path), len(broken))
+	for i, link := range broken {
+		if i == maxListed {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(broken)-maxListed)
+			break
+		}
+		fmt.Fprintf(&b, "\n  line %d: %s", link.line, link.target)
+	}
+	b.WriteString("\nFix or remove these links.")
+	return hook.NewPostToolBlockOutput(b.String(), ""), nil
+}
+
+// markdownLinkPattern matches inline links and images: [text](target "title").
+var markdownLinkPattern = regexp.MustCompile(`!?\[[^\]]*\]\(\s*(<[^>]*>|[^)\s]+)(?:\s+["'(][^)]*)?\)`)
+
+// brokenLink is a markdown link whose target does not exist.
+type brokenLink struct {
+	line   int
+	target string
+}
+
+// isMarkdownFile reports whether path has a markdown extension.
+func isMarkdownFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".mdx", ".markdown":
+		return true
+	default:
+		return false
+	}
+}
 
-	return hook.NewAllowOutput(), nil
+// brokenMarkdownLinks returns the local links in the markdown file at path
+// whose targets do not exist. Links inside fenced code blocks, external URLs
+// and same-page anchors are skipped. Root-relative targets ("/docs/x.md")
+// resolve against root; others resolve against the file's directory.
+func brokenMarkdownLinks(path, root string) ([]brokenLink, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var broken []brokenLink
+	inFence := false
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		for _, m := range markdownLinkPattern.FindAllStringSubmatch(line, -1) {
+			target := strings.TrimSuffix(strings.TrimPrefix(m[1], "<"), ">")
+			resolved, ok := localLinkTarget(target, filepath.Dir(path), root)
+			if !ok {
+				continue
+			}
+			if _, err := os.Stat(resolved); err != nil {
+				broken = append(broken, brokenLink{line: lineNo, target: target})
+			}
+		}
+	}
+	return broken, scanner.Err()
+}
+
+// localLinkTarget resolves a link target to a file system path. It returns
+// false for targets that are not local files: URLs with a scheme, protocol-
+// relative URLs and same-page anchors.
+func localLinkTarget(target, dir, root string) (string, bool) {
+	if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "//") {
+		return "", false
+	}
+	if u, err := url.Parse(target); err != nil || u.Scheme != "" {
+		return "", false
+	}
+
+	if i := strings.IndexAny(target, "#?"); i >= 0 {
+		target = target[:i]
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	if target == "" {
+		return "", false
+	}
+
+	if strings.HasPrefix(target, "/") {
+		if root == "" {
+			return "", false
+		}
+		return filepath.Join(root, filepath.FromSlash(target)), true
+	}
+	return filepath.Join(dir, filepath.FromSlash(target)), true
 }
 
 func (h *docsHandler) EventType() hook.EventType {
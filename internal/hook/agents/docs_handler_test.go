@@ -0,0 +1,70 @@
+package agents
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/hook"
+)
+
+func TestDocsHandler_Verification(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "docs/guide.md", "# Guide\n")
+	writeFile(t, dir, "docs/img/logo.png", "")
+	writeFile(t, dir, "docs/index.md", strings.Join([]string{
+		"# Index",
+		"See the [guide](guide.md#install) and ![logo](img/logo.png).",
+		"Root link to [readme](/README.md) and [site](https://example.com).",
+		"Jump to [top](#index) or [mail](mailto:a@example.com).",
+		"Missing [setup](setup.md) page.",
+		"```",
+		"[ignored](not-there.md)",
+		"```",
+		"Spaces [work](<my%20notes.md>) too.",
+	}, "\n"))
+
+	h := NewDocsHandler("verification")
+	if h.EventType() != hook.EventPostToolUse {
+		t.Fatalf("EventType() = %s, want PostToolUse", h.EventType())
+	}
+
+	out, err := h.Handle(context.Background(), writeToolInput(t, "Write", dir, "docs/index.md"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Decision != hook.DecisionBlock {
+		t.Fatalf("expected block for broken links, got %+v", out)
+	}
+	for _, want := range []string{"line 3: /README.md", "line 5: setup.md", "line 9: my%20notes.md"} {
+		if !strings.Contains(out.Reason, want) {
+			t.Errorf("reason should contain %q:\n%s", want, out.Reason)
+		}
+	}
+	for _, unwanted := range []string{"guide.md", "logo.png", "example.com", "not-there.md", "#index"} {
+		if strings.Contains(out.Reason, unwanted) {
+			t.Errorf("reason should not contain %q:\n%s", unwanted, out.Reason)
+		}
+	}
+}
+
+func TestDocsHandler_VerificationPasses(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "README.md", "# Project\n")
+	writeFile(t, dir, "docs/a.md", "Back to [readme](../README.md).\n")
+
+	h := NewDocsHandler("verification")
+	for _, file := range []string{"docs/a.md", "main.go"} {
+		out, err := h.Handle(context.Background(), writeToolInput(t, "Edit", dir, file))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out.Decision == hook.DecisionBlock {
+			t.Errorf("%s: unexpected block: %s", file, out.Reason)
+		}
+	}
+}
@@ -3,17 +3,21 @@ package agents
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 
 	"github.com/modu-ai/moai-adk/internal/hook"
 )
 
 // Factory creates agent-specific hook handlers based on action.
-type Factory struct{}
+type Factory struct {
+	cfg hook.ConfigProvider
+}
 
-// NewFactory creates a new agent handler factory.
-func NewFactory() *Factory {
-	return &Factory{}
+// NewFactory creates a new agent handler factory. cfg supplies the
+// development mode to the TDD handlers; it may be nil.
+func NewFactory(cfg hook.ConfigProvider) *Factory {
+	return &Factory{cfg: cfg}
 }
 
 // CreateHandler creates a handler for the given agent action.
@@ -32,7 +36,7 @@ func (f *Factory) CreateHandler(action string) (hook.Handler, error) {
 	case "ddd":
 		return NewDDDHandler(act), nil
 	case "tdd":
-		return NewTDDHandler(act), nil
+		return NewTDDHandler(act, hook.NewTDDCycleTracker(f.cfg)), nil
 	case "backend":
 		return NewBackendHandler(act), nil
 	case "frontend":
@@ -61,13 +65,15 @@ type baseHandler struct {
 	agent  string
 }
 
-// Handle logs the action and allows it by default.
-// Subclasses should override this method to provide specific behavior.
+// Handle logs the action and allows it.
+// Agent handlers embed baseHandler and fall back to this for actions
+// without specific behavior.
 func (h *baseHandler) Handle(ctx context.Context, input *hook.HookInput) (*hook.HookOutput, error) {
-	// Log the agent hook action for debugging
-	// In production, this would dispatch to the actual handler logic
-
-	// For now, allow all actions by default
+	slog.Debug("agent hook action",
+		"agent", h.agent,
+		"action", h.action,
+		"tool_name", input.ToolName,
+	)
 	return hook.NewAllowOutput(), nil
 }
 
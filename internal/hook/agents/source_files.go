@@ -0,0 +1,31 @@
+package agents
+
+import (
+	"os"
+
+	"github.com/modu-ai/moai-adk/internal/foundation"
+	"github.com/modu-ai/moai-adk/internal/hook"
+)
+
+// projectRoot returns the project directory for a hook input.
+// It prefers the explicit project_dir, then $CLAUDE_PROJECT_DIR, then cwd.
+func projectRoot(input *hook.HookInput) string {
+	if input.ProjectDir != "" {
+		return input.ProjectDir
+	}
+	if dir := os.Getenv("CLAUDE_PROJECT_DIR"); dir != "" {
+		return dir
+	}
+	return input.CWD
+}
+
+// existingTestFile returns the first test file candidate for path that
+// exists on disk, or "" if there is none.
+func existingTestFile(path, root string) string {
+	for _, candidate := range foundation.DefaultRegistry.TestFileCandidates(path, root) {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
+}
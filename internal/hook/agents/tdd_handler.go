@@ -2,6 +2,7 @@ package agents
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/modu-ai/moai-adk/internal/hook"
 )
@@ -9,11 +10,13 @@ import (
 // tddHandler handles TDD (Test-Driven Development) workflow hooks.
 type tddHandler struct {
 	baseHandler
+	tracker *hook.TDDCycleTracker
 }
 
-// NewTDDHandler creates a new TDD handler for the given action.
+// NewTDDHandler creates a new TDD handler for the given action. The
+// pre-implementation action enforces the RED phase recorded by tracker.
 // Actions: pre-implementation, post-implementation, completion
-func NewTDDHandler(action string) hook.Handler {
+func NewTDDHandler(action string, tracker *hook.TDDCycleTracker) hook.Handler {
 	event := hook.EventPreToolUse
 	switch action {
 	case "post-implementation":
@@ -28,16 +31,28 @@ func NewTDDHandler(action string) hook.Handler {
 			event:  event,
 			agent:  "tdd",
 		},
+		tracker: tracker,
 	}
 }
 
 // Handle processes TDD workflow hooks.
+// For pre-implementation (RED phase), a Write/Edit to a production source
+// file is denied while the SPEC's TDD cycle is in RED without a recorded
+// failing test run, so the test is written and seen failing first.
+// Other actions allow the tool call.
 func (h *tddHandler) Handle(ctx context.Context, input *hook.HookInput) (*hook.HookOutput, error) {
-	// TODO: Implement TDD workflow logic
-	// - pre-implementation: RED phase - ensure test exists before implementation
-	// - post-implementation: GREEN/REFACTOR phase - verify tests pass
-	// - completion: Report TDD workflow completion
+	if h.action != "pre-implementation" || h.tracker == nil {
+		return h.baseHandler.Handle(ctx, input)
+	}
 
+	path := hook.EditedFile(input)
+	if path == "" {
+		return hook.NewAllowOutput(), nil
+	}
+	if reason := h.tracker.CheckEdit(input, path); reason != "" {
+		slog.Info("tdd: production edit before a failing test", "file", path)
+		return hook.NewDenyOutput(reason), nil
+	}
 	return hook.NewAllowOutput(), nil
 }
 
@@ -0,0 +1,139 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/hook"
+	"github.com/modu-ai/moai-adk/pkg/models"
+)
+
+// writeToolInput builds a HookInput for a tool call on file in projectDir.
+func writeToolInput(t *testing.T, toolName, projectDir, file string) *hook.HookInput {
+	t.Helper()
+	raw, err := json.Marshal(map[string]string{"file_path": filepath.Join(projectDir, file)})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	return &hook.HookInput{ToolName: toolName, ToolInput: raw, CWD: projectDir, ProjectDir: projectDir}
+}
+
+// writeFile creates file under dir with content, creating parent directories.
+func writeFile(t *testing.T, dir, file, content string) {
+	t.Helper()
+	path := filepath.Join(dir, file)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// staticConfig is a hook.ConfigProvider returning a fixed configuration.
+type staticConfig struct {
+	cfg *config.Config
+}
+
+func (c staticConfig) Get() *config.Config { return c.cfg }
+
+// newTDDProject returns a MoAI project whose directory names the active
+// SPEC and a cycle tracker for it in TDD mode.
+func newTDDProject(t *testing.T) (string, *hook.TDDCycleTracker) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "SPEC-CALC-001")
+	if err := os.MkdirAll(filepath.Join(dir, ".moai"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewDefaultConfig()
+	cfg.Quality.DevelopmentMode = models.ModeTDD
+	return dir, hook.NewTDDCycleTracker(staticConfig{cfg: cfg})
+}
+
+func TestTDDHandler_PreImplementation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		setup    map[string]string
+		toolName string
+		file     string
+		wantDeny bool
+	}{
+		{"new production file without test", nil, "Write", "calc.go", true},
+		{"edit production file without test", map[string]string{"calc.go": "package calc\n"}, "Edit", "calc.go", true},
+		{"existing test that never failed", map[string]string{"calc_test.go": "package calc\n"}, "Write", "calc.go", true},
+		{"writing the test itself", nil, "Write", "calc_test.go", false},
+		{"package documentation", nil, "Write", "doc.go", false},
+		{"type declarations", nil, "Write", "types.go", false},
+		{"generated file", map[string]string{"api.go": "// Code generated by oapi-codegen. DO NOT EDIT.\n\npackage calc\n"}, "Edit", "api.go", false},
+		{"non-source file", nil, "Write", "README.md", false},
+		{"non-write tool", nil, "Read", "calc.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir, tracker := newTDDProject(t)
+			for file, content := range tt.setup {
+				writeFile(t, dir, file, content)
+			}
+
+			h := NewTDDHandler("pre-implementation", tracker)
+			out, err := h.Handle(context.Background(), writeToolInput(t, tt.toolName, dir, tt.file))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			denied := out.HookSpecificOutput != nil && out.HookSpecificOutput.PermissionDecision == hook.DecisionDeny
+			if denied != tt.wantDeny {
+				t.Errorf("denied = %v, want %v (%+v)", denied, tt.wantDeny, out.HookSpecificOutput)
+			}
+			if denied && !strings.Contains(out.HookSpecificOutput.PermissionDecisionReason, "TDD RED") {
+				t.Errorf("reason should mention the RED phase: %s", out.HookSpecificOutput.PermissionDecisionReason)
+			}
+		})
+	}
+}
+
+func TestTDDHandler_AllowsAfterFailingTestRun(t *testing.T) {
+	t.Parallel()
+
+	dir, tracker := newTDDProject(t)
+	h := NewTDDHandler("pre-implementation", tracker)
+	post := hook.NewTDDPostToolHandler(tracker)
+	ctx := context.Background()
+
+	writeFile(t, dir, "calc_test.go", "package calc\n")
+	_, _ = post.Handle(ctx, writeToolInput(t, "Write", dir, "calc_test.go"))
+	raw, _ := json.Marshal(map[string]string{"command": "go test ./..."})
+	resp, _ := json.Marshal(map[string]any{"stdout": "--- FAIL: TestAdd (0.00s)\nFAIL\n", "stderr": ""})
+	_, _ = post.Handle(ctx, &hook.HookInput{ToolName: "Bash", ToolInput: raw, ToolResponse: resp, CWD: dir, ProjectDir: dir})
+
+	out, err := h.Handle(ctx, writeToolInput(t, "Write", dir, "calc.go"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.HookSpecificOutput.PermissionDecision != hook.DecisionAllow {
+		t.Errorf("production edit after a failing test run should be allowed: %+v", out.HookSpecificOutput)
+	}
+}
+
+func TestTDDHandler_OtherActionsAllow(t *testing.T) {
+	t.Parallel()
+
+	h := NewTDDHandler("post-implementation", nil)
+	if h.EventType() != hook.EventPostToolUse {
+		t.Errorf("EventType() = %s, want PostToolUse", h.EventType())
+	}
+	out, err := h.Handle(context.Background(), writeToolInput(t, "Write", t.TempDir(), "calc.go"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.HookSpecificOutput.PermissionDecision != hook.DecisionAllow {
+		t.Errorf("expected allow, got %q", out.HookSpecificOutput.PermissionDecision)
+	}
+}
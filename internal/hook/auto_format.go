@@ -0,0 +1,140 @@
+package hook
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	hookquality "github.com/modu-ai/moai-adk/internal/hook/quality"
+	"github.com/modu-ai/moai-adk/pkg/models"
+)
+
+// autoFormatSteps is the number of tool runs per file: format, lint fix
+// and lint.
+const autoFormatSteps = 3
+
+// autoFormatHandler formats files written by Write, Edit and MultiEdit,
+// applies linter auto-fixes and reports the remaining lint issues to Claude
+// as additional context. Files whose content has not changed since they
+// were last formatted in the session are skipped.
+type autoFormatHandler struct {
+	cfg       ConfigProvider
+	processor *hookquality.FileProcessor
+}
+
+// NewAutoFormatHandler creates a PostToolUse handler configured by the
+// quality.auto_format section of cfg. A nil processor uses the default
+// formatters and linters.
+func NewAutoFormatHandler(cfg ConfigProvider, processor *hookquality.FileProcessor) Handler {
+	if processor == nil {
+		processor = hookquality.NewFileProcessor(nil)
+	}
+	return &autoFormatHandler{cfg: cfg, processor: processor}
+}
+
+// EventType returns EventPostToolUse.
+func (h *autoFormatHandler) EventType() EventType {
+	return EventPostToolUse
+}
+
+// Handle formats and lint-fixes the edited file. It never blocks; tool
+// failures are logged.
+func (h *autoFormatHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
+	path := EditedFile(input)
+	if path == "" || h.cfg == nil {
+		return NewPostToolOutput(""), nil
+	}
+	cfg := h.cfg.Get()
+	if cfg == nil || !cfg.Quality.AutoFormat.Enabled || !h.processor.Supports(path) {
+		return NewPostToolOutput(""), nil
+	}
+	settings := cfg.Quality.AutoFormat
+	override := settings.Languages[h.processor.Language(path)]
+	if override.Disabled {
+		return NewPostToolOutput(""), nil
+	}
+	timeout := settings.TimeoutSeconds
+	if override.TimeoutSeconds > 0 {
+		timeout = override.TimeoutSeconds
+	}
+
+	hash, err := h.processor.Hash(path)
+	if err != nil {
+		slog.Debug("auto-format: cannot hash file", "file_path", path, "error", err)
+		return NewPostToolOutput(""), nil
+	}
+
+	// The state is only available inside a MoAI project; elsewhere every
+	// edit is processed.
+	projectDir := moaiProjectDir(input)
+	rel := path
+	var state *SessionState
+	if projectDir != "" {
+		rel = DisplayPath(projectDir, path)
+		if state, err = loadSessionState(projectDir, input.SessionID); err != nil {
+			slog.Warn("auto-format: cannot load session state", "error", err)
+		}
+	}
+	if state != nil && state.FormattedHashes[rel] == hex.EncodeToString(hash) {
+		slog.Debug("auto-format: file unchanged since last format", "file_path", rel)
+		return NewPostToolOutput(""), nil
+	}
+
+	result, err := h.processor.Process(ctx, path, hookquality.ProcessOptions{
+		Format:         true,
+		LintFix:        settings.LintFix,
+		TimeoutSeconds: timeout,
+	})
+	if err != nil {
+		slog.Warn("auto-format failed", "file_path", rel, "error", err)
+		return NewPostToolOutput(""), nil
+	}
+
+	if state != nil {
+		if hash, err = h.processor.Hash(path); err == nil {
+			if state.FormattedHashes == nil {
+				state.FormattedHashes = make(map[string]string)
+			}
+			state.FormattedHashes[rel] = hex.EncodeToString(hash)
+			if err := saveSessionState(projectDir, state); err != nil {
+				slog.Warn("auto-format: cannot save session state", "error", err)
+			}
+		}
+	}
+
+	return NewPostToolOutput(autoFormatContext(rel, result)), nil
+}
+
+// autoFormatContext tells Claude what auto-format changed in the file at
+// rel and which lint issues remain, or returns "" if there is neither.
+func autoFormatContext(rel string, result *hookquality.ProcessResult) string {
+	var parts []string
+	if result.Modified() {
+		var tools []string
+		for _, r := range []*hookquality.ToolResult{result.Format, result.Fix} {
+			if r != nil && r.FileModified {
+				tools = append(tools, r.ToolName)
+			}
+		}
+		parts = append(parts, fmt.Sprintf("MoAI auto-format rewrote %s with %s. Re-read the file before editing it again.",
+			rel, strings.Join(tools, " and ")))
+	}
+	if result.Remaining != "" {
+		parts = append(parts, fmt.Sprintf("%s reports issues in %s that could not be fixed automatically. Fix them in your next edit:\n%s",
+			result.Lint.ToolName, rel, strings.TrimSpace(result.Remaining)))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// autoFormatTimeout returns the longest time auto-format may spend on one
+// file under settings.
+func autoFormatTimeout(settings models.AutoFormat) time.Duration {
+	seconds := settings.TimeoutSeconds
+	for _, l := range settings.Languages {
+		seconds = max(seconds, l.TimeoutSeconds)
+	}
+	return time.Duration(autoFormatSteps*seconds) * time.Second
+}
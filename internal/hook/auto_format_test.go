@@ -0,0 +1,201 @@
+package hook
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	hookquality "github.com/modu-ai/moai-adk/internal/hook/quality"
+	"github.com/modu-ai/moai-adk/pkg/models"
+)
+
+// newTestAutoFormat returns an auto-format handler for a temporary MoAI
+// project whose ".fmttest" files are upper-cased by a fake formatter and
+// linted by a fake linter that reports each TODO line. The returned
+// counter file gets one line per formatter run.
+func newTestAutoFormat(t *testing.T, settings models.AutoFormat) (Handler, string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	projectDir := t.TempDir()
+	writeGateFile(t, projectDir, ".moai/config/.keep", "")
+	counter := filepath.Join(projectDir, "format-runs")
+
+	registry := hookquality.NewToolRegistry()
+	registry.RegisterTool(hookquality.ToolConfig{
+		Name: "fake-format", Command: "sh", Extensions: []string{".fmttest"}, ToolType: hookquality.ToolTypeFormatter,
+		Args: []string{"-c", `echo run >> "$1"; tr a-z A-Z < "$0" > "$0.tmp" && mv "$0.tmp" "$0"`, "{file}", counter},
+	})
+	registry.RegisterTool(hookquality.ToolConfig{
+		Name: "fake-lint", Command: "sh", Extensions: []string{".fmttest"}, ToolType: hookquality.ToolTypeLinter,
+		Args: []string{"-c", `grep -n TODO "$0" | while IFS=: read -r n rest; do echo "$0:$n: warning: TODO left"; done; ! grep -q TODO "$0"`, "{file}"},
+	})
+
+	cfg := config.NewDefaultConfig()
+	cfg.Quality.AutoFormat = settings
+	h := NewAutoFormatHandler(&mockConfigProvider{cfg: cfg}, hookquality.NewFileProcessor(registry))
+	return h, projectDir, counter
+}
+
+// defaultAutoFormat returns the default auto-format settings with
+// formatting enabled, as the generated quality.yaml does.
+func defaultAutoFormat() models.AutoFormat {
+	a := config.NewDefaultAutoFormat()
+	a.Enabled = true
+	return a
+}
+
+func formatRuns(t *testing.T, counter string) int {
+	t.Helper()
+	data, err := os.ReadFile(counter)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "run")
+}
+
+func autoFormatEdit(t *testing.T, h Handler, projectDir, file string) string {
+	t.Helper()
+	input := editInput(projectDir, "Edit", file)
+	input.SessionID = "sess-format"
+	out, err := h.Handle(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if out.Decision == DecisionBlock {
+		t.Fatalf("Handle() blocked: %+v", out)
+	}
+	if out.HookSpecificOutput == nil {
+		return ""
+	}
+	return out.HookSpecificOutput.AdditionalContext
+}
+
+func TestAutoFormatHandler_FormatsAndReportsLint(t *testing.T) {
+	t.Parallel()
+
+	h, projectDir, counter := newTestAutoFormat(t, defaultAutoFormat())
+	writeGateFile(t, projectDir, "src/a.fmttest", "hello\ntodo: later\n")
+
+	ctx := autoFormatEdit(t, h, projectDir, "src/a.fmttest")
+
+	if got, _ := os.ReadFile(filepath.Join(projectDir, "src/a.fmttest")); string(got) != "HELLO\nTODO: LATER\n" {
+		t.Errorf("file content = %q, want formatted", got)
+	}
+	for _, want := range []string{"rewrote src/a.fmttest with fake-format", "Re-read the file", "fake-lint reports issues", "TODO left"} {
+		if !strings.Contains(ctx, want) {
+			t.Errorf("additionalContext missing %q:\n%s", want, ctx)
+		}
+	}
+	if n := formatRuns(t, counter); n != 1 {
+		t.Errorf("formatter runs = %d, want 1", n)
+	}
+
+	state, err := loadSessionState(projectDir, "sess-format")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.FormattedHashes["src/a.fmttest"] == "" {
+		t.Errorf("FormattedHashes = %v, want entry for src/a.fmttest", state.FormattedHashes)
+	}
+}
+
+func TestAutoFormatHandler_SkipsUnchangedFile(t *testing.T) {
+	t.Parallel()
+
+	h, projectDir, counter := newTestAutoFormat(t, defaultAutoFormat())
+	writeGateFile(t, projectDir, "a.fmttest", "hello\n")
+
+	autoFormatEdit(t, h, projectDir, "a.fmttest")
+	if ctx := autoFormatEdit(t, h, projectDir, "a.fmttest"); ctx != "" {
+		t.Errorf("second run additionalContext = %q, want none", ctx)
+	}
+	if n := formatRuns(t, counter); n != 1 {
+		t.Errorf("formatter runs = %d, want 1 for an unchanged file", n)
+	}
+
+	writeGateFile(t, projectDir, "a.fmttest", "HELLO\nworld\n")
+	autoFormatEdit(t, h, projectDir, "a.fmttest")
+	if n := formatRuns(t, counter); n != 2 {
+		t.Errorf("formatter runs = %d, want 2 after a change", n)
+	}
+}
+
+func TestAutoFormatHandler_Settings(t *testing.T) {
+	t.Parallel()
+
+	disabled := defaultAutoFormat()
+	disabled.Enabled = false
+	languageOff := defaultAutoFormat()
+	languageOff.Languages = map[string]models.AutoFormatLanguage{"fmttest": {Disabled: true}}
+
+	tests := []struct {
+		name     string
+		settings models.AutoFormat
+		file     string
+	}{
+		{"auto-format disabled", disabled, "a.fmttest"},
+		{"language disabled", languageOff, "a.fmttest"},
+		{"unsupported file", defaultAutoFormat(), "notes.unknown"},
+		{"vendored file", defaultAutoFormat(), "vendor/a.fmttest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h, projectDir, counter := newTestAutoFormat(t, tt.settings)
+			writeGateFile(t, projectDir, tt.file, "hello\n")
+
+			if ctx := autoFormatEdit(t, h, projectDir, tt.file); ctx != "" {
+				t.Errorf("additionalContext = %q, want none", ctx)
+			}
+			if n := formatRuns(t, counter); n != 0 {
+				t.Errorf("formatter runs = %d, want 0", n)
+			}
+			if got, _ := os.ReadFile(filepath.Join(projectDir, tt.file)); string(got) != "hello\n" {
+				t.Errorf("file content = %q, want unchanged", got)
+			}
+		})
+	}
+}
+
+func TestAutoFormatHandler_IgnoresOtherTools(t *testing.T) {
+	t.Parallel()
+
+	h, projectDir, counter := newTestAutoFormat(t, defaultAutoFormat())
+	writeGateFile(t, projectDir, "a.fmttest", "hello\n")
+	input := editInput(projectDir, "Read", "a.fmttest")
+
+	out, err := h.Handle(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if out.HookSpecificOutput != nil && out.HookSpecificOutput.AdditionalContext != "" {
+		t.Errorf("additionalContext = %q, want none", out.HookSpecificOutput.AdditionalContext)
+	}
+	if n := formatRuns(t, counter); n != 0 {
+		t.Errorf("formatter runs = %d, want 0", n)
+	}
+}
+
+func TestAutoFormatTimeout(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultAutoFormat()
+	if got := autoFormatTimeout(settings); got != 30*time.Second {
+		t.Errorf("autoFormatTimeout(defaults) = %v, want 30s", got)
+	}
+	settings.Languages = map[string]models.AutoFormatLanguage{"rust": {TimeoutSeconds: 60}}
+	if got := autoFormatTimeout(settings); got != 180*time.Second {
+		t.Errorf("autoFormatTimeout(rust 60s) = %v, want 180s", got)
+	}
+}
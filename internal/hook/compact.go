@@ -3,17 +3,33 @@ package hook
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/hook/lifecycle"
 )
 
 // compactHandler processes PreCompact events.
 // It captures context information and creates session state snapshots
 // for post-compaction recovery (REQ-HOOK-036). Always returns "allow".
-type compactHandler struct{}
+// The snapshot is written to .moai/memory/compact/<session-id>.json and
+// restored by the SessionStart handler after compaction or resume.
+type compactHandler struct {
+	resolveSpec func(projectDir string) string
+	gitStatus   func(ctx context.Context, projectDir string) (files []string, total int)
+}
 
 // NewCompactHandler creates a new PreCompact event handler.
 func NewCompactHandler() Handler {
-	return &compactHandler{}
+	return &compactHandler{
+		resolveSpec: activeSpecID,
+		gitStatus:   modifiedFiles,
+	}
 }
 
 // EventType returns EventPreCompact.
@@ -30,10 +46,23 @@ func (h *compactHandler) Handle(ctx context.Context, input *HookInput) (*HookOut
 		"project_dir", input.ProjectDir,
 	)
 
+	created := false
+	if projectDir := moaiProjectDir(input); projectDir != "" {
+		state := h.snapshot(ctx, input, projectDir)
+		store := lifecycle.NewWorkState(lifecycle.WorkStateConfig{
+			StoragePath: compactSnapshotPath(projectDir, input.SessionID),
+		})
+		if err := store.Save(state); err != nil {
+			slog.Warn("failed to save compact snapshot", "error", err.Error())
+		} else {
+			created = true
+		}
+	}
+
 	data := map[string]any{
 		"session_id":       input.SessionID,
 		"status":           "preserved",
-		"snapshot_created": true,
+		"snapshot_created": created,
 	}
 
 	jsonData, err := json.Marshal(data)
@@ -46,3 +75,98 @@ func (h *compactHandler) Handle(ctx context.Context, input *HookInput) (*HookOut
 
 	return &HookOutput{Data: jsonData}, nil
 }
+
+// snapshot collects the work state of the session: the active SPEC and its
+// TDD phase, the files git reports as modified, and the open todos and last
+// failing test output from the transcript.
+func (h *compactHandler) snapshot(ctx context.Context, input *HookInput, projectDir string) *lifecycle.WorkStateData {
+	state := &lifecycle.WorkStateData{
+		SessionID:           input.SessionID,
+		CompactInstructions: input.CustomInstructions,
+		Trigger:             input.Trigger,
+	}
+	state.ActiveFiles, state.ModifiedCount = h.gitStatus(ctx, projectDir)
+	if specID := h.resolveSpec(projectDir); specID != "" {
+		state.SpecID = specID
+		state.Phase = tddPhase(projectDir, specID)
+	}
+	if input.TranscriptPath != "" {
+		digest, err := readTranscriptDigest(input.TranscriptPath)
+		if err != nil {
+			slog.Warn("failed to read transcript for compact snapshot", "error", err.Error())
+		}
+		state.OpenTodos = digest.OpenTodos
+		state.LastTestFailure = digest.LastTestFailure
+	}
+	return state
+}
+
+// moaiProjectDir returns the project directory of input, or "" if it is
+// not a MoAI project.
+func moaiProjectDir(input *HookInput) string {
+	dir := input.ProjectDir
+	if dir == "" {
+		dir = os.Getenv("CLAUDE_PROJECT_DIR")
+	}
+	if dir == "" {
+		dir = input.CWD
+	}
+	if dir == "" {
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(dir, defs.MoAIDir)); err != nil {
+		return ""
+	}
+	return dir
+}
+
+// compactSnapshotPath returns the PreCompact snapshot file of a session.
+func compactSnapshotPath(projectDir, sessionID string) string {
+	return filepath.Join(projectDir, filepath.FromSlash(lifecycle.CompactSnapshotDir), sessionFileName(sessionID)+".json")
+}
+
+// tddPhase returns the recorded TDD phase of a SPEC, or "" if none.
+func tddPhase(projectDir, specID string) string {
+	data, err := os.ReadFile(tddScope{projectDir: projectDir, specID: specID}.statePath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Debug("cannot read tdd state", "error", err)
+		}
+		return ""
+	}
+	var state TDDCycleState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return ""
+	}
+	return string(state.Phase)
+}
+
+// maxSnapshotFiles limits the modified files kept in a snapshot.
+const maxSnapshotFiles = 50
+
+// modifiedFiles returns the first maxSnapshotFiles paths git status reports
+// as changed or untracked in projectDir, and the number of such paths. It
+// returns nil and 0 outside a git work tree.
+func modifiedFiles(ctx context.Context, projectDir string) (files []string, total int) {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
+	cmd.Dir = projectDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, 0
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		total++
+		if len(files) == maxSnapshotFiles {
+			continue
+		}
+		path := line[3:]
+		if _, renamed, ok := strings.Cut(path, " -> "); ok {
+			path = renamed
+		}
+		files = append(files, strings.Trim(path, `"`))
+	}
+	return files, total
+}
@@ -3,9 +3,13 @@ package hook
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/hook/lifecycle"
 )
 
 func TestCompactHandler_EventType(t *testing.T) {
@@ -93,3 +97,103 @@ func TestCompactHandler_Handle(t *testing.T) {
 		})
 	}
 }
+
+func TestCompactHandler_WritesSnapshot(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectDir, ".moai", "state"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tddState := `{"spec_id":"SPEC-AUTH-001","phase":"green"}`
+	if err := os.WriteFile(filepath.Join(projectDir, ".moai", "state", "tdd-SPEC-AUTH-001.json"), []byte(tddState), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := &compactHandler{
+		resolveSpec: func(string) string { return "SPEC-AUTH-001" },
+		gitStatus: func(context.Context, string) ([]string, int) {
+			return []string{"auth/login.go", "auth/login_test.go"}, 2
+		},
+	}
+	input := &HookInput{
+		SessionID:          "sess-compact-snap",
+		ProjectDir:         projectDir,
+		TranscriptPath:     writeTranscript(t, transcriptTodos, transcriptTestRun, transcriptFailure),
+		Trigger:            "auto",
+		CustomInstructions: "keep the API decisions",
+	}
+
+	got, err := h.Handle(context.Background(), input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var data map[string]any
+	if err := json.Unmarshal(got.Data, &data); err != nil || data["snapshot_created"] != true {
+		t.Errorf("Data = %s, want snapshot_created true", got.Data)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(projectDir, ".moai", "memory", "compact", "sess-compact-snap.json"))
+	if err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+	var snap lifecycle.WorkStateData
+	if err := json.Unmarshal(raw, &snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.SpecID != "SPEC-AUTH-001" || snap.Phase != "green" {
+		t.Errorf("SPEC = %q/%q, want SPEC-AUTH-001/green", snap.SpecID, snap.Phase)
+	}
+	if len(snap.ActiveFiles) != 2 || snap.ModifiedCount != 2 || len(snap.OpenTodos) != 2 {
+		t.Errorf("ActiveFiles = %v, OpenTodos = %v", snap.ActiveFiles, snap.OpenTodos)
+	}
+	if snap.LastTestFailure == "" || snap.CompactInstructions != "keep the API decisions" || snap.Trigger != "auto" {
+		t.Errorf("snapshot = %+v", snap)
+	}
+}
+
+func TestModifiedFiles(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "-C", dir, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.go"), []byte("package x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, total := modifiedFiles(context.Background(), dir)
+	if len(got) != 1 || got[0] != "new.go" || total != 1 {
+		t.Errorf("modifiedFiles() = %v, %d, want [new.go], 1", got, total)
+	}
+	if got, total := modifiedFiles(context.Background(), t.TempDir()); got != nil || total != 0 {
+		t.Errorf("modifiedFiles() outside git = %v, %d, want nil, 0", got, total)
+	}
+}
+
+func TestModifiedFiles_CountsBeyondLimit(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "-C", dir, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	const n = maxSnapshotFiles + 25
+	for i := range n {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%03d.go", i)), []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, total := modifiedFiles(context.Background(), dir)
+	if len(got) != maxSnapshotFiles || total != n {
+		t.Errorf("modifiedFiles() kept %d of %d files, want %d of %d", len(got), total, maxSnapshotFiles, n)
+	}
+}
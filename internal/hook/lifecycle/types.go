@@ -69,10 +69,30 @@ type WorkState interface {
 
 // WorkStateData represents work session state.
 // Simplified to focus on crash recovery rather than file position tracking.
+// PreCompact snapshots additionally record what the session was working on
+// so it can be restored after compaction.
 type WorkStateData struct {
 	ActiveFiles    []string  `json:"activeFiles,omitempty"`
 	ContextSummary string    `json:"contextSummary,omitempty"`
 	Timestamp      time.Time `json:"timestamp"`
+
+	SessionID string `json:"sessionId,omitempty"`
+	SpecID    string `json:"specId,omitempty"`
+	Phase     string `json:"phase,omitempty"`
+
+	// ModifiedCount is the number of files git reported as modified. It
+	// exceeds len(ActiveFiles) when the snapshot kept only the first ones.
+	ModifiedCount int `json:"modifiedCount,omitempty"`
+
+	// LastTestFailure is the tail of the most recent failing test output.
+	LastTestFailure string `json:"lastTestFailure,omitempty"`
+
+	// OpenTodos lists the unfinished items of the latest todo list.
+	OpenTodos []string `json:"openTodos,omitempty"`
+
+	// CompactInstructions are the user's custom /compact instructions.
+	CompactInstructions string `json:"compactInstructions,omitempty"`
+	Trigger             string `json:"trigger,omitempty"`
 }
 
 // WorkStateConfig configures work state persistence.
@@ -81,6 +101,10 @@ type WorkStateConfig struct {
 	StoragePath string
 }
 
+// CompactSnapshotDir is the directory of PreCompact snapshots, relative to
+// the project root. Each session has one <session-id>.json file.
+const CompactSnapshotDir = defs.MoAIDir + "/" + defs.MemorySubdir + "/compact"
+
 // DefaultWorkStateConfig returns the default work state configuration.
 func DefaultWorkStateConfig() WorkStateConfig {
 	return WorkStateConfig{
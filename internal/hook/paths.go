@@ -0,0 +1,19 @@
+package hook
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DisplayPath returns path relative to root with forward slashes for
+// messages, or path unchanged when root is empty or path lies outside it.
+func DisplayPath(root, path string) string {
+	if root == "" {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
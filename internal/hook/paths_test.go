@@ -0,0 +1,24 @@
+package hook
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDisplayPath(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.Join(string(filepath.Separator), "work", "app")
+	tests := []struct {
+		root, path, want string
+	}{
+		{root, filepath.Join(root, "pkg", "calc.go"), "pkg/calc.go"},
+		{root, filepath.Join(string(filepath.Separator), "tmp", "x.go"), filepath.Join(string(filepath.Separator), "tmp", "x.go")},
+		{"", "calc.go", "calc.go"},
+	}
+	for _, tt := range tests {
+		if got := DisplayPath(tt.root, tt.path); got != tt.want {
+			t.Errorf("DisplayPath(%q, %q) = %q, want %q", tt.root, tt.path, got, tt.want)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package hook
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"os"
 	"path/filepath"
 
 	lsphook "github.com/modu-ai/moai-adk/internal/lsp/hook"
@@ -11,10 +13,15 @@ import (
 
 // postToolHandler processes PostToolUse events.
 // It collects tool execution metrics and prepares statusline data
-// (REQ-HOOK-033). This handler is observation-only and always returns "allow".
-// Optionally integrates with LSP diagnostics for Write/Edit operations.
+// (REQ-HOOK-033). Optionally integrates with LSP diagnostics for Write/Edit
+// operations, and with a SecurityPolicy whose redact rules flag Read and
+// Grep results that contain the content of sensitive files. With a
+// RegressionGate, edits that add diagnostics errors are blocked.
 type postToolHandler struct {
 	diagnostics lsphook.LSPDiagnosticsCollector
+	policy      *SecurityPolicy
+	regression  *RegressionGate
+	projectDir  string
 }
 
 // NewPostToolHandler creates a new PostToolUse event handler.
@@ -28,6 +35,18 @@ func NewPostToolHandlerWithDiagnostics(diagnostics lsphook.LSPDiagnosticsCollect
 	return &postToolHandler{diagnostics: diagnostics}
 }
 
+// NewPostToolHandlerWithRegressionGate creates a PostToolUse handler with
+// LSP diagnostics and read redaction from policy that also checks the
+// diagnostics of edited files against their baseline with gate. Any argument
+// may be nil.
+func NewPostToolHandlerWithRegressionGate(diagnostics lsphook.LSPDiagnosticsCollector, policy *SecurityPolicy, gate *RegressionGate) Handler {
+	projectDir := os.Getenv("CLAUDE_PROJECT_DIR")
+	if projectDir == "" {
+		projectDir, _ = os.Getwd()
+	}
+	return &postToolHandler{diagnostics: diagnostics, policy: policy, regression: gate, projectDir: projectDir}
+}
+
 // EventType returns EventPostToolUse.
 func (h *postToolHandler) EventType() EventType {
 	return EventPostToolUse
@@ -35,8 +54,10 @@ func (h *postToolHandler) EventType() EventType {
 
 // Handle processes a PostToolUse event. It collects metrics about the tool
 // execution (tool name, output size) and returns them in the Data field.
-// For Write/Edit tools, also collects LSP diagnostics per REQ-HOOK-150.
-// Always returns Decision "allow" (observation only).
+// For Write/Edit tools, also collects LSP diagnostics per REQ-HOOK-150
+// and blocks when the regression gate finds new errors.
+// Read and Grep results containing files matched by a redact rule are
+// blocked with a system message telling the user what was withheld.
 func (h *postToolHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
 	slog.Debug("collecting post-tool metrics",
 		"tool_name", input.ToolName,
@@ -58,9 +79,28 @@ func (h *postToolHandler) Handle(ctx context.Context, input *HookInput) (*HookOu
 		metrics["input_size"] = len(input.ToolInput)
 	}
 
+	// Withhold content of files matched by redact rules
+	if isFileReadTool(input.ToolName) && h.policy != nil {
+		if rule := redactedRead(h.policy, h.projectDir, input); rule != nil {
+			slog.Warn("tool response redacted",
+				"tool_name", input.ToolName,
+				"rule", rule.ID,
+			)
+			reason := ruleReason(rule, "This tool result contains file content protected by the security policy. "+
+				"Do not use, quote or reconstruct any of it; ask the user if you need the values.")
+			output := NewPostToolBlockOutput(reason, "")
+			output.SystemMessage = fmt.Sprintf("MoAI withheld sensitive file content from the %s result [rule: %s]", input.ToolName, rule.ID)
+			return output, nil
+		}
+	}
+
 	// Collect LSP diagnostics for Write/Edit operations (REQ-HOOK-150, REQ-HOOK-153)
+	var regression string
 	if (input.ToolName == "Write" || input.ToolName == "Edit") && h.diagnostics != nil {
-		h.collectDiagnostics(ctx, input, metrics)
+		regression = h.collectDiagnostics(ctx, input, metrics)
+	}
+	if regression != "" {
+		return NewPostToolBlockOutput(regression, ""), nil
 	}
 
 	jsonData, err := json.Marshal(metrics)
@@ -80,18 +120,19 @@ func (h *postToolHandler) Handle(ctx context.Context, input *HookInput) (*HookOu
 }
 
 // collectDiagnostics collects LSP diagnostics for the modified file.
-// This is observation-only and MUST NOT block per REQ-HOOK-153.
-func (h *postToolHandler) collectDiagnostics(ctx context.Context, input *HookInput, metrics map[string]any) {
+// Collection is observation-only per REQ-HOOK-153; the only block comes
+// from the regression gate, whose reason is returned.
+func (h *postToolHandler) collectDiagnostics(ctx context.Context, input *HookInput, metrics map[string]any) string {
 	// Extract file path from tool input
 	var parsed map[string]any
 	if err := json.Unmarshal(input.ToolInput, &parsed); err != nil {
 		slog.Debug("failed to parse tool input for diagnostics", "error", err)
-		return
+		return ""
 	}
 
 	filePath, ok := parsed["file_path"].(string)
 	if !ok || filePath == "" {
-		return
+		return ""
 	}
 
 	// Get diagnostics (observation only, never block)
@@ -101,7 +142,7 @@ func (h *postToolHandler) collectDiagnostics(ctx context.Context, input *HookInp
 			"file_path", filePath,
 			"error", err,
 		)
-		return
+		return ""
 	}
 
 	// Calculate severity counts
@@ -131,4 +172,13 @@ func (h *postToolHandler) collectDiagnostics(ctx context.Context, input *HookInp
 			"file_path", filepath.Base(filePath),
 		)
 	}
+
+	if h.regression == nil {
+		return ""
+	}
+	path := filePath
+	if !filepath.IsAbs(path) && input.CWD != "" {
+		path = filepath.Join(input.CWD, path)
+	}
+	return h.regression.check(input, filepath.Clean(path), diagnostics, counts)
 }
@@ -7,219 +7,13 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/modu-ai/moai-adk/internal/hook/security"
+	"github.com/modu-ai/moai-adk/internal/shell"
 	"golang.org/x/text/unicode/norm"
 )
 
-// SecurityPolicy defines tool access control rules for PreToolUse events.
-type SecurityPolicy struct {
-	// BlockedTools is a list of tool names that are always blocked.
-	BlockedTools []string
-
-	// DenyPatterns are regex patterns for files that should NEVER be modified.
-	DenyPatterns []*regexp.Regexp
-
-	// AskPatterns are regex patterns for files that require user confirmation.
-	AskPatterns []*regexp.Regexp
-
-	// DangerousBashPatterns are regex patterns for dangerous Bash commands.
-	DangerousBashPatterns []*regexp.Regexp
-
-	// AskBashPatterns are regex patterns for Bash commands requiring confirmation.
-	AskBashPatterns []*regexp.Regexp
-
-	// SensitiveContentPatterns are regex patterns for sensitive data in content.
-	SensitiveContentPatterns []*regexp.Regexp
-}
-
-// compilePatterns compiles a list of pattern strings into regexp objects.
-func compilePatterns(patterns []string) []*regexp.Regexp {
-	result := make([]*regexp.Regexp, 0, len(patterns))
-	for _, p := range patterns {
-		re, err := regexp.Compile("(?i)" + p) // Case-insensitive
-		if err != nil {
-			slog.Warn("failed to compile security pattern", "pattern", p, "error", err)
-			continue
-		}
-		result = append(result, re)
-	}
-	return result
-}
-
-// DefaultSecurityPolicy returns a SecurityPolicy with comprehensive security patterns
-// ported from the Python pre_tool__security_guard.py implementation.
-func DefaultSecurityPolicy() *SecurityPolicy {
-	// Files that should NEVER be modified
-	denyPatterns := []string{
-		// Secrets and credentials
-		`secrets?\.(json|ya?ml|toml)$`,
-		`credentials?\.(json|ya?ml|toml)$`,
-		`\.secrets/.*`,
-		`secrets/.*`,
-		// SSH and certificates
-		`\.ssh/.*`,
-		`id_rsa.*`,
-		`id_ed25519.*`,
-		`\.pem$`,
-		`\.key$`,
-		`\.crt$`,
-		// Git internals
-		`\.git/.*`,
-		// Cloud credentials
-		`\.aws/.*`,
-		`\.gcloud/.*`,
-		`\.azure/.*`,
-		`\.kube/.*`,
-		// Token files
-		`\.token$`,
-		`\.tokens/.*`,
-		`auth\.json$`,
-	}
-
-	// Files that require user confirmation
-	askPatterns := []string{
-		// Lock files
-		`package-lock\.json$`,
-		`yarn\.lock$`,
-		`pnpm-lock\.ya?ml$`,
-		`Gemfile\.lock$`,
-		`Cargo\.lock$`,
-		`poetry\.lock$`,
-		`composer\.lock$`,
-		`Pipfile\.lock$`,
-		`uv\.lock$`,
-		// Critical configs
-		`tsconfig\.json$`,
-		`pyproject\.toml$`,
-		`Cargo\.toml$`,
-		`package\.json$`,
-		`docker-compose\.ya?ml$`,
-		`Dockerfile$`,
-		`\.dockerignore$`,
-		// CI/CD configs
-		`\.github/workflows/.*\.ya?ml$`,
-		`\.gitlab-ci\.ya?ml$`,
-		`\.circleci/.*`,
-		`Jenkinsfile$`,
-		// Infrastructure
-		`terraform/.*\.tf$`,
-		`\.terraform/.*`,
-		`kubernetes/.*\.ya?ml$`,
-		`k8s/.*\.ya?ml$`,
-	}
-
-	// Dangerous Bash commands that should NEVER be executed
-	dangerousBashPatterns := []string{
-		// Database deletion commands - Supabase
-		`supabase\s+db\s+reset`,
-		`supabase\s+projects?\s+delete`,
-		`supabase\s+functions?\s+delete`,
-		// Database deletion commands - Neon
-		`neon\s+database\s+delete`,
-		`neon\s+projects?\s+delete`,
-		`neon\s+branch\s+delete`,
-		// Database deletion commands - PlanetScale
-		`pscale\s+database\s+delete`,
-		`pscale\s+branch\s+delete`,
-		// Database deletion commands - Railway
-		`railway\s+delete`,
-		`railway\s+environment\s+delete`,
-		// Database deletion commands - Vercel
-		`vercel\s+env\s+rm`,
-		`vercel\s+projects?\s+rm`,
-		// SQL dangerous commands
-		`DROP\s+DATABASE`,
-		`DROP\s+SCHEMA`,
-		`TRUNCATE\s+TABLE`,
-		// Unix dangerous file operations
-		`rm\s+-rf\s+/`,
-		`rm\s+-rf\s+~`,
-		`rm\s+-rf\s+\*`,
-		`rm\s+-rf\s+\.\*`,
-		`rm\s+-rf\s+\.git\b`,
-		`rm\s+-rf\s+node_modules\s*$`,
-		// Windows dangerous file operations (CMD)
-		`rd\s+/s\s+/q\s+[A-Za-z]:\\`,
-		`rmdir\s+/s\s+/q\s+[A-Za-z]:\\`,
-		`del\s+/f\s+/q\s+[A-Za-z]:\\`,
-		`rd\s+/s\s+/q\s+\\\\`,
-		`rd\s+/s\s+/q\s+\.git\b`,
-		`del\s+/s\s+/q\s+\*\.\*`,
-		`format\s+[A-Za-z]:`,
-		// Windows dangerous file operations (PowerShell)
-		`Remove-Item\s+.*-Recurse\s+.*-Force\s+[A-Za-z]:\\`,
-		`Remove-Item\s+.*-Recurse\s+.*-Force\s+~`,
-		`Remove-Item\s+.*-Recurse\s+.*-Force\s+\$env:`,
-		`Remove-Item\s+.*-Recurse\s+.*-Force\s+\.git\b`,
-		`Clear-Content\s+.*-Force`,
-		// Git dangerous commands
-		`git\s+push\s+.*--force\s+origin\s+(main|master)`,
-		`git\s+branch\s+-D\s+(main|master)`,
-		// Cloud infrastructure deletion
-		`terraform\s+destroy`,
-		`pulumi\s+destroy`,
-		`aws\s+.*\s+delete-`,
-		`gcloud\s+.*\s+delete\b`,
-		// Azure CLI dangerous commands
-		`az\s+group\s+delete`,
-		`az\s+storage\s+account\s+delete`,
-		`az\s+sql\s+server\s+delete`,
-		// Docker dangerous commands
-		`docker\s+system\s+prune\s+(-a|--all)`,
-		`docker\s+image\s+prune\s+(-a|--all)`,
-		`docker\s+container\s+prune`,
-		`docker\s+volume\s+prune`,
-		`docker\s+network\s+prune`,
-		`docker\s+builder\s+prune\s+(-a|--all)`,
-		// Classic dangerous patterns
-		`:\(\)\{\s*:\|:&\s*\};:`, // Fork bomb
-		`mkfs\.`,
-		`>\s*/dev/sda`,
-		`dd\s+if=/dev/zero\s+of=/dev/sda`,
-	}
-
-	// Bash commands that require user confirmation
-	askBashPatterns := []string{
-		// Database reset/migration
-		`prisma\s+migrate\s+reset`,
-		`prisma\s+db\s+push\s+--force`,
-		`drizzle-kit\s+push`,
-		// Git force operations (non-main branches)
-		`git\s+push\s+.*--force`,
-		`git\s+reset\s+--hard`,
-		`git\s+clean\s+-fd`,
-		// Package manager cache clear
-		`npm\s+cache\s+clean`,
-		`yarn\s+cache\s+clean`,
-		`pnpm\s+store\s+prune`,
-	}
-
-	// Content patterns that indicate sensitive data
-	sensitiveContentPatterns := []string{
-		`-----BEGIN\s+(RSA\s+)?PRIVATE\s+KEY-----`,
-		`-----BEGIN\s+CERTIFICATE-----`,
-		`sk-[a-zA-Z0-9]{32,}`,       // OpenAI API keys
-		`ghp_[a-zA-Z0-9]{36}`,       // GitHub tokens
-		`gho_[a-zA-Z0-9]{36}`,       // GitHub OAuth tokens
-		`glpat-[a-zA-Z0-9\-]{20}`,   // GitLab tokens
-		`xox[baprs]-[a-zA-Z0-9\-]+`, // Slack tokens
-		`AKIA[0-9A-Z]{16}`,          // AWS access keys
-		`ya29\.[a-zA-Z0-9_\-]+`,     // Google OAuth tokens
-	}
-
-	return &SecurityPolicy{
-		BlockedTools:             []string{},
-		DenyPatterns:             compilePatterns(denyPatterns),
-		AskPatterns:              compilePatterns(askPatterns),
-		DangerousBashPatterns:    compilePatterns(dangerousBashPatterns),
-		AskBashPatterns:          compilePatterns(askBashPatterns),
-		SensitiveContentPatterns: compilePatterns(sensitiveContentPatterns),
-	}
-}
-
 // preToolHandler processes PreToolUse events.
 // It enforces security policies by checking tool names against blocklists
 // and scanning tool input for dangerous patterns (REQ-HOOK-031, REQ-HOOK-032).
@@ -296,7 +90,7 @@ func (h *preToolHandler) Handle(ctx context.Context, input *HookInput) (*HookOut
 
 	// Handle Bash commands
 	if input.ToolName == "Bash" && len(input.ToolInput) > 0 {
-		decision, reason := h.checkBashCommand(input.ToolInput)
+		decision, reason := h.checkBashCommand(input.ToolInput, input.CWD)
 		if decision != "" {
 			slog.Warn("bash command security check",
 				"tool_name", input.ToolName,
@@ -312,8 +106,26 @@ func (h *preToolHandler) Handle(ctx context.Context, input *HookInput) (*HookOut
 		}
 	}
 
-	// Handle Write and Edit tools
-	if (input.ToolName == "Write" || input.ToolName == "Edit") && len(input.ToolInput) > 0 {
+	// Handle file-reading tools
+	if isFileReadTool(input.ToolName) && len(input.ToolInput) > 0 {
+		decision, reason := h.checkReadAccess(input.ToolInput, input.ToolName, input.CWD)
+		if decision != "" {
+			slog.Warn("file read security check",
+				"tool_name", input.ToolName,
+				"decision", decision,
+				"reason", reason,
+			)
+			if decision == DecisionDeny {
+				return NewDenyOutput(reason), nil
+			}
+			if decision == DecisionAsk {
+				return NewAskOutput(reason), nil
+			}
+		}
+	}
+
+	// Handle file-writing tools
+	if isFileWriteTool(input.ToolName) && len(input.ToolInput) > 0 {
 		decision, reason := h.checkFileAccess(input.ToolInput, input.ToolName)
 		if decision != "" {
 			slog.Warn("file access security check",
@@ -419,9 +231,17 @@ func (h *preToolHandler) scanWriteContent(ctx context.Context, toolInput json.Ra
 	return "", ""
 }
 
-// checkBashCommand checks a Bash command against dangerous and ask patterns.
+// checkBashCommand checks a Bash command against the policy's Bash rules.
+// The command is parsed into the simple commands it would run (following
+// chains, pipelines, subshells, substitutions, "bash -c", eval and wrappers
+// like sudo or env) and each effective command is checked on its own, so the
+// reason names the sub-command that triggered the rule. Rules are then checked
+// against the whole command line with quoted strings and heredoc bodies
+// removed to catch structural patterns. Unparseable commands, and commands
+// piping an inert command's output into an interpreter, are matched raw.
+// cwd is the working directory used to match allowlist path exceptions.
 // Returns (decision, reason) where decision is "deny", "ask", or "" for allow.
-func (h *preToolHandler) checkBashCommand(toolInput json.RawMessage) (string, string) {
+func (h *preToolHandler) checkBashCommand(toolInput json.RawMessage, cwd string) (string, string) {
 	var parsed map[string]any
 	if err := json.Unmarshal(toolInput, &parsed); err != nil {
 		return "", ""
@@ -432,23 +252,101 @@ func (h *preToolHandler) checkBashCommand(toolInput json.RawMessage) (string, st
 		return "", ""
 	}
 
-	// Check dangerous patterns (deny)
-	for _, pattern := range h.policy.DangerousBashPatterns {
-		if pattern.MatchString(command) {
-			return DecisionDeny, fmt.Sprintf("Dangerous command blocked: %s", pattern.String())
+	if cwd == "" {
+		cwd = h.projectDir
+	}
+
+	commands, sanitized, err := shell.ParseCommands(command)
+	if err != nil {
+		slog.Debug("bash command not parseable, matching raw command", "error", err)
+		commands, sanitized = nil, command
+	}
+
+	var ask *SecurityRule
+	var askCommand string
+	matched := make(map[string]bool)
+	for _, sc := range commands {
+		// Inert output piped into an interpreter may run anything in the
+		// line, so the structural check matches the raw command instead.
+		if sc.Piped {
+			sanitized = command
+		}
+		text := sc.MatchText()
+		for _, r := range h.policy.BashRules {
+			if r.Pattern != nil && r.Pattern.MatchString(text) {
+				matched[r.ID] = true
+			}
+		}
+
+		dir := sc.Dir
+		if dir != "" && !filepath.IsAbs(dir) {
+			dir = filepath.Join(cwd, dir)
+		} else if dir == "" {
+			dir = cwd
+		}
+		target := matchTarget{path: h.relativeDir(dir), command: sc.String()}
+
+		rule := h.policy.evaluate(h.policy.BashRules, target, text)
+		if rule == nil {
+			continue
+		}
+		if rule.Severity == SeverityDeny {
+			return DecisionDeny, bashRuleReason(rule, sc.String())
+		}
+		if ask == nil {
+			ask, askCommand = rule, sc.String()
 		}
 	}
 
-	// Check ask patterns (require confirmation)
-	for _, pattern := range h.policy.AskBashPatterns {
-		if pattern.MatchString(command) {
-			return DecisionAsk, "This command may have significant effects. Please confirm."
+	// Structural check: only rules not already decided per sub-command.
+	structural := make([]SecurityRule, 0, len(h.policy.BashRules))
+	for _, r := range h.policy.BashRules {
+		if !matched[r.ID] {
+			structural = append(structural, r)
+		}
+	}
+	target := matchTarget{path: h.relativeDir(cwd), command: command}
+	if rule := h.policy.evaluate(structural, target, sanitized); rule != nil {
+		if rule.Severity == SeverityDeny {
+			return DecisionDeny, bashRuleReason(rule, command)
+		}
+		if ask == nil {
+			ask, askCommand = rule, command
 		}
 	}
 
+	if ask != nil {
+		return DecisionAsk, bashRuleReason(ask, askCommand)
+	}
 	return "", ""
 }
 
+// describeShellCommand shortens a command for display in decision reasons.
+func describeShellCommand(s string) string {
+	const maxLen = 120
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > maxLen {
+		return fmt.Sprintf("%s...", s[:maxLen])
+	}
+	return s
+}
+
+// bashRuleReason returns the decision reason for a Bash rule that matched
+// the given (sub-)command.
+func bashRuleReason(rule *SecurityRule, command string) string {
+	command = describeShellCommand(command)
+	var msg string
+	switch {
+	case rule.Message != "":
+		msg = fmt.Sprintf("%s: %s", rule.Message, command)
+	case rule.Severity == SeverityDeny:
+		msg = fmt.Sprintf("Dangerous command blocked: %s (pattern: %s)", command, rule.Pattern.String())
+	default:
+		msg = fmt.Sprintf("This command may have significant effects. Please confirm: %s", command)
+	}
+	return fmt.Sprintf("%s [rule: %s]", msg, rule.ID)
+}
+
 // checkFileAccess checks file path and content against security patterns.
 // Returns (decision, reason) where decision is "deny", "ask", or "" for allow.
 func (h *preToolHandler) checkFileAccess(toolInput json.RawMessage, toolName string) (string, string) {
@@ -458,67 +356,127 @@ func (h *preToolHandler) checkFileAccess(toolInput json.RawMessage, toolName str
 	}
 
 	filePath, ok := parsed["file_path"].(string)
+	if toolName == "NotebookEdit" {
+		filePath, ok = parsed["notebook_path"].(string)
+	}
 	if !ok || filePath == "" {
 		return "", ""
 	}
 
 	// Resolve path to prevent path traversal attacks
-	resolvedPath, err := filepath.Abs(filePath)
+	resolvedPath, inside, err := resolveProjectPath(h.projectDir, filePath)
 	if err != nil {
 		return DecisionDeny, "Invalid file path: cannot resolve"
 	}
-
-	// Check if path is within project directory
-	if h.projectDir != "" {
-		projectAbs, absErr := filepath.Abs(h.projectDir)
-		if absErr != nil {
-			// Cannot resolve project directory, skip boundary check
-			slog.Debug("cannot resolve project directory", "error", absErr)
-		} else {
-			// Normalize both paths to Unicode NFC before comparison.
-			// macOS HFS+/APFS stores paths in NFD form, but tools like
-			// Claude Code may send paths in NFC form. Without normalization,
-			// filepath.Rel produces ".." prefixed results for paths containing
-			// non-ASCII characters (e.g., Korean), causing false path traversal errors.
-			nfcProject := norm.NFC.String(projectAbs)
-			nfcResolved := norm.NFC.String(resolvedPath)
-
-			rel, relErr := filepath.Rel(nfcProject, nfcResolved)
-			if relErr != nil || strings.HasPrefix(rel, "..") {
-				return DecisionDeny, "Path traversal detected: file is outside project directory"
-			}
-		}
+	if !inside {
+		return DecisionDeny, "Path traversal detected: file is outside project directory"
 	}
 
 	// Normalize path for pattern matching
 	normalizedPath := strings.ReplaceAll(filePath, "\\", "/")
 	normalizedResolved := strings.ReplaceAll(resolvedPath, "\\", "/")
+	target := matchTarget{path: projectRelativePath(h.projectDir, resolvedPath)}
 
-	// Check deny patterns
-	for _, pattern := range h.policy.DenyPatterns {
-		if pattern.MatchString(normalizedPath) || pattern.MatchString(normalizedResolved) {
-			return DecisionDeny, "Protected file: access denied for security reasons"
+	// Check path rules (deny takes precedence over ask)
+	if rule := h.policy.evaluate(h.policy.PathRules, target, normalizedPath, normalizedResolved); rule != nil {
+		if rule.Severity == SeverityDeny {
+			return DecisionDeny, ruleReason(rule, "Protected file: access denied for security reasons")
 		}
+		return DecisionAsk, ruleReason(rule, fmt.Sprintf("Critical config file: %s", filepath.Base(filePath)))
 	}
 
-	// Check ask patterns
-	for _, pattern := range h.policy.AskPatterns {
-		if pattern.MatchString(normalizedPath) || pattern.MatchString(normalizedResolved) {
-			return DecisionAsk, fmt.Sprintf("Critical config file: %s", filepath.Base(filePath))
+	// Check every payload the tool writes for secrets
+	for _, payload := range contentPayloads(toolName, parsed) {
+		rule := h.policy.evaluate(h.policy.ContentRules, target, payload.text)
+		if rule == nil {
+			continue
 		}
-	}
-
-	// For Write operations, check content for secrets
-	if toolName == "Write" {
-		content, ok := parsed["content"].(string)
-		if ok && content != "" {
-			for _, pattern := range h.policy.SensitiveContentPatterns {
-				if pattern.MatchString(content) {
-					return DecisionDeny, "Content contains sensitive data (credentials, API keys, or certificates)"
-				}
-			}
+		if rule.Severity == SeverityDeny {
+			return DecisionDeny, contentRuleReason(rule, payload, "Content contains sensitive data (credentials, API keys, or certificates)")
 		}
+		return DecisionAsk, contentRuleReason(rule, payload, "Content may contain sensitive data. Please confirm.")
 	}
 
 	return "", ""
 }
+
+// contentRuleReason returns the decision reason for a content rule match,
+// naming the tool input field and a redacted preview of the matched text so
+// the secret itself is never echoed back.
+func contentRuleReason(rule *SecurityRule, payload contentPayload, fallback string) string {
+	msg := fallback
+	if rule.Message != "" {
+		msg = rule.Message
+	}
+	return fmt.Sprintf("%s (%s: %s) [rule: %s]",
+		strings.TrimSpace(msg), payload.field, redactSecret(rule.find(payload.text)), rule.ID)
+}
+
+// isFileWriteTool reports whether toolName writes file content.
+func isFileWriteTool(toolName string) bool {
+	switch toolName {
+	case "Write", "Edit", "MultiEdit", "NotebookEdit":
+		return true
+	default:
+		return false
+	}
+}
+
+// resolveProjectPath resolves filePath to an absolute path and reports
+// whether it lies inside projectDir. Paths are compared in Unicode NFC form:
+// macOS HFS+/APFS stores paths in NFD form, but tools like Claude Code may
+// send paths in NFC form. Without normalization, filepath.Rel produces ".."
+// prefixed results for paths containing non-ASCII characters (e.g., Korean),
+// causing false path traversal errors. When projectDir is empty or cannot be
+// resolved, the boundary check is skipped and inside is true.
+func resolveProjectPath(projectDir, filePath string) (string, bool, error) {
+	resolvedPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", false, err
+	}
+	if projectDir == "" {
+		return resolvedPath, true, nil
+	}
+	projectAbs, err := filepath.Abs(projectDir)
+	if err != nil {
+		slog.Debug("cannot resolve project directory", "error", err)
+		return resolvedPath, true, nil
+	}
+	rel, err := filepath.Rel(norm.NFC.String(projectAbs), norm.NFC.String(resolvedPath))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return resolvedPath, false, nil
+	}
+	return resolvedPath, true, nil
+}
+
+// projectRelativePath returns path relative to projectDir using forward
+// slashes, for matching allowlist exceptions. Paths outside the project (or
+// when the project directory is unknown) are returned normalized as-is.
+func projectRelativePath(projectDir, path string) string {
+	normalized := strings.ReplaceAll(path, "\\", "/")
+	if projectDir == "" {
+		return normalized
+	}
+	projectAbs, err := filepath.Abs(projectDir)
+	if err != nil {
+		return normalized
+	}
+	rel, err := filepath.Rel(norm.NFC.String(projectAbs), norm.NFC.String(path))
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return normalized
+	}
+	return filepath.ToSlash(rel)
+}
+
+// relativeDir returns dir relative to the project directory with a trailing
+// slash, so allowlist patterns like "^sandbox/" match commands run inside it.
+func (h *preToolHandler) relativeDir(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(projectRelativePath(h.projectDir, abs), "/") + "/"
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"golang.org/x/text/unicode/norm"
@@ -355,16 +356,85 @@ func TestDefaultSecurityPolicy(t *testing.T) {
 	if policy == nil {
 		t.Fatal("DefaultSecurityPolicy() returned nil")
 	}
-	if len(policy.DangerousBashPatterns) == 0 {
-		t.Error("DangerousBashPatterns should not be empty")
+	if len(policy.BashRules) == 0 {
+		t.Error("BashRules should not be empty")
 	}
-	if len(policy.DenyPatterns) == 0 {
-		t.Error("DenyPatterns should not be empty")
+	if len(policy.PathRules) == 0 {
+		t.Error("PathRules should not be empty")
 	}
-	if len(policy.AskPatterns) == 0 {
-		t.Error("AskPatterns should not be empty")
+	if len(policy.ContentRules) == 0 {
+		t.Error("ContentRules should not be empty")
 	}
-	if len(policy.SensitiveContentPatterns) == 0 {
-		t.Error("SensitiveContentPatterns should not be empty")
+
+	// Every built-in rule must have a unique ID so security.yaml can reference it.
+	seen := make(map[string]bool)
+	for _, rules := range [][]SecurityRule{policy.PathRules, policy.BashRules, policy.ContentRules} {
+		for _, r := range rules {
+			if r.ID == "" {
+				t.Errorf("rule with pattern %q has empty ID", r.Pattern)
+			}
+			if seen[r.ID] {
+				t.Errorf("duplicate rule ID %q", r.ID)
+			}
+			seen[r.ID] = true
+		}
+	}
+}
+
+func TestPreToolHandler_ShellAwareBash(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		command      string
+		wantDecision string
+		wantInReason string
+	}{
+		{"rm -rf / behind bash -c", `bash -c "cd / && rm -rf /"`, DecisionDeny, "rm -rf /"},
+		{"rm -rf / behind eval", `eval 'rm -rf /'`, DecisionDeny, "rm -rf /"},
+		{"rm -rf / via variable", `R=rm; $R -rf /`, DecisionDeny, "rm -rf /"},
+		{"rm -rf / via xargs", `echo / | xargs rm -rf`, DecisionDeny, "rm -rf /"},
+		{"rm -rf / via inert substitution", `$(echo rm) -rf /`, DecisionDeny, "rm -rf /"},
+		{"chained dangerous command names sub-command", "npm test && sudo docker volume prune -f", DecisionDeny, "docker volume prune -f"},
+		{"command substitution", "echo $(terraform destroy -auto-approve)", DecisionDeny, "terraform destroy -auto-approve"},
+		{"env prefix", "env TF_LOG=1 terraform destroy", DecisionDeny, "terraform destroy"},
+		{"quoted commit message is allowed", `git commit -m "docs: explain why rm -rf / is blocked"`, DecisionAllow, ""},
+		{"echo of dangerous text is allowed", `echo "terraform destroy is dangerous"`, DecisionAllow, ""},
+		{"grep for pattern is allowed", `grep -rn "DROP DATABASE" migrations/`, DecisionAllow, ""},
+		{"heredoc body to cat is allowed", "cat <<EOF > README.md\nNever run rm -rf / or DROP DATABASE.\nEOF", DecisionAllow, ""},
+		{"heredoc body to psql is checked", "psql <<SQL\nDROP DATABASE prod;\nSQL", DecisionDeny, "psql"},
+		{"heredoc body to bash is parsed", "bash <<'EOF'\nrm -rf ~\nEOF", DecisionDeny, "rm -rf ~"},
+		{"ask rule names sub-command", "go build ./... && git reset --hard HEAD~1", DecisionAsk, "git reset --hard HEAD~1"},
+		{"fork bomb is structural", ":(){ :|:& };:", DecisionDeny, "bash.fork-bomb"},
+		{"unparseable command falls back to raw match", `rm -rf / "`, DecisionDeny, "rm"},
+		{"echo piped into sh is a script", `echo "rm -rf /" | sh`, DecisionDeny, "rm -rf /"},
+		{"printf piped into psql is checked", `printf 'DROP DATABASE prod' | psql`, DecisionDeny, "DROP DATABASE prod"},
+		{"heredoc through cat into psql is checked", "cat <<EOF | psql -h db\nDROP DATABASE prod;\nEOF", DecisionDeny, "bash.sql-drop-database"},
+		{"echo piped into xargs sh -c is a script", `echo "terraform destroy" | xargs sh -c`, DecisionDeny, "terraform destroy"},
+		{"echo piped into an unknown command matches raw", `echo "terraform destroy" | tee plan.txt`, DecisionDeny, "terraform destroy"},
+		{"echo piped into grep is allowed", `echo "rm -rf /" | grep rm`, DecisionAllow, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &preToolHandler{policy: DefaultSecurityPolicy(), projectDir: t.TempDir()}
+			raw, err := json.Marshal(map[string]string{"command": tt.command})
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			got, err := h.Handle(context.Background(), &HookInput{ToolName: "Bash", ToolInput: raw})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			out := got.HookSpecificOutput
+			if out.PermissionDecision != tt.wantDecision {
+				t.Errorf("PermissionDecision = %q, want %q (reason: %s)", out.PermissionDecision, tt.wantDecision, out.PermissionDecisionReason)
+			}
+			if tt.wantInReason != "" && !strings.Contains(out.PermissionDecisionReason, tt.wantInReason) {
+				t.Errorf("reason %q should contain %q", out.PermissionDecisionReason, tt.wantInReason)
+			}
+		})
 	}
 }
@@ -0,0 +1,350 @@
+package hook
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/github"
+)
+
+// Limits on what a single prompt can pull into the injected context.
+const (
+	maxPromptSpecs         = 3
+	maxPromptIssues        = 3
+	maxPromptFiles         = 3
+	maxAcceptanceCriteria  = 12
+	maxOutlineSymbols      = 40
+	charsPerToken          = 4
+	truncatedContextSuffix = "\n... (truncated to the prompt token budget)"
+)
+
+var (
+	// issueRefPattern matches "#123" issue references that are not part of
+	// a word, URL fragment or HTML entity.
+	issueRefPattern = regexp.MustCompile(`(?:^|[^\w&/#])#(\d+)\b`)
+
+	// pathMentionPattern matches file paths with an extension, optionally
+	// quoted or prefixed with "./".
+	pathMentionPattern = regexp.MustCompile(`(?:^|[\s"'` + "`" + `(\[])((?:\./)?[\w.-]+(?:/[\w.-]+)*\.[A-Za-z0-9]+)`)
+
+	// outlinePattern matches top-level declarations in languages without a
+	// dedicated outline parser.
+	outlinePattern = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:pub(?:\(crate\))?\s+)?(?:async\s+)?(?:abstract\s+)?(def|class|function|interface|type|enum|struct|trait|fn|impl)\s+([A-Za-z_$][\w$]*)`)
+)
+
+// promptContext resolves the SPEC IDs, issue references and file paths
+// mentioned in a prompt into additional context for Claude.
+type promptContext struct {
+	projectDir string
+	linker     func(projectRoot string) (github.SpecLinker, error)
+}
+
+// build returns the context for prompt, capped at budget tokens, or "" if
+// the prompt mentions nothing that resolves.
+func (c promptContext) build(prompt string, budget int) string {
+	var sections []string
+
+	specs := uniqueMatches(specIDPattern.FindAllString(prompt, -1), maxPromptSpecs)
+	for _, specID := range specs {
+		if s := c.specSummary(specID); s != "" {
+			sections = append(sections, s)
+		}
+	}
+
+	var issues []string
+	for _, m := range issueRefPattern.FindAllStringSubmatch(prompt, -1) {
+		issues = append(issues, m[1])
+	}
+	for _, number := range uniqueMatches(issues, maxPromptIssues) {
+		if s := c.issueSummary(number, specs); s != "" {
+			sections = append(sections, s)
+		}
+	}
+
+	var paths []string
+	for _, m := range pathMentionPattern.FindAllStringSubmatch(prompt, -1) {
+		paths = append(paths, m[1])
+	}
+	files := 0
+	for _, path := range uniqueMatches(paths, len(paths)) {
+		if files == maxPromptFiles {
+			break
+		}
+		if s := c.fileOutline(path); s != "" {
+			sections = append(sections, s)
+			files++
+		}
+	}
+
+	if len(sections) == 0 {
+		return ""
+	}
+	return truncateToBudget("MoAI context for this prompt:\n\n"+strings.Join(sections, "\n\n"), budget)
+}
+
+// specSummary returns the status and acceptance criteria of a SPEC, or ""
+// if the project has no such SPEC.
+func (c promptContext) specSummary(specID string) string {
+	specDir := filepath.Join(c.projectDir, defs.MoAIDir, defs.SpecsSubdir, specID)
+	data, err := os.ReadFile(filepath.Join(specDir, "spec.md"))
+	if err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	meta := parseFrontmatter(data)
+	b.WriteString(specID)
+	if title := meta["title"]; title != "" {
+		fmt.Fprintf(&b, ": %s", title)
+	}
+	var attrs []string
+	for _, key := range []string{"status", "priority", "version"} {
+		if v := meta[key]; v != "" {
+			attrs = append(attrs, key+": "+v)
+		}
+	}
+	if len(attrs) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(attrs, ", "))
+	}
+
+	if acceptance, err := os.ReadFile(filepath.Join(specDir, "acceptance.md")); err == nil {
+		if criteria := acceptanceCriteria(acceptance); len(criteria) > 0 {
+			b.WriteString("\nAcceptance criteria:")
+			for _, ac := range criteria {
+				b.WriteString("\n- " + ac)
+			}
+		}
+	}
+	return b.String()
+}
+
+// issueSummary returns the SPEC linked to an issue. The SPEC summary is
+// included unless the prompt already mentions that SPEC.
+func (c promptContext) issueSummary(number string, mentioned []string) string {
+	if c.linker == nil {
+		return ""
+	}
+	issueNum, err := strconv.Atoi(number)
+	if err != nil {
+		return ""
+	}
+	linker, err := c.linker(c.projectDir)
+	if err != nil {
+		slog.Debug("cannot load spec linker", "error", err)
+		return ""
+	}
+	specID, err := linker.GetLinkedSpec(issueNum)
+	if err != nil || specID == "" {
+		return ""
+	}
+
+	line := fmt.Sprintf("Issue #%d is linked to %s.", issueNum, specID)
+	for _, m := range mentioned {
+		if m == specID {
+			return line
+		}
+	}
+	if s := c.specSummary(specID); s != "" {
+		return line + "\n" + s
+	}
+	return line
+}
+
+// fileOutline returns a symbol outline of a file mentioned in the prompt,
+// or "" if it is not a regular file inside the project.
+func (c promptContext) fileOutline(path string) string {
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(c.projectDir, filepath.FromSlash(path))
+	}
+	abs, inProject, err := resolveProjectPath(c.projectDir, abs)
+	if err != nil || !inProject {
+		return ""
+	}
+	info, err := os.Stat(abs)
+	if err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		return ""
+	}
+
+	var symbols []string
+	if filepath.Ext(abs) == ".go" {
+		symbols = goOutline(data)
+	} else {
+		symbols = genericOutline(data)
+	}
+	if len(symbols) == 0 {
+		return ""
+	}
+	if len(symbols) > maxOutlineSymbols {
+		symbols = append(symbols[:maxOutlineSymbols], fmt.Sprintf("... %d more", len(symbols)-maxOutlineSymbols))
+	}
+	return fmt.Sprintf("Outline of %s:\n  %s", DisplayPath(c.projectDir, abs), strings.Join(symbols, "\n  "))
+}
+
+// parseFrontmatter returns the scalar fields of the YAML frontmatter of a
+// markdown document.
+func parseFrontmatter(data []byte) map[string]string {
+	rest, ok := bytes.CutPrefix(bytes.TrimLeft(data, "\ufeff"), []byte("---\n"))
+	if !ok {
+		return nil
+	}
+	end := bytes.Index(rest, []byte("\n---"))
+	if end < 0 {
+		return nil
+	}
+	var raw map[string]any
+	if err := yaml.Unmarshal(rest[:end], &raw); err != nil {
+		return nil
+	}
+	meta := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v.(type) {
+		case map[string]any, []any, nil:
+			continue
+		}
+		meta[k] = fmt.Sprint(v)
+	}
+	return meta
+}
+
+// acceptanceCriteria returns the criteria headings (level-three headings)
+// and unchecked checklist items of an acceptance.md document.
+func acceptanceCriteria(data []byte) []string {
+	var criteria []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		var item string
+		switch {
+		case strings.HasPrefix(line, "### "):
+			item = strings.TrimPrefix(line, "### ")
+		case strings.HasPrefix(line, "- [ ] "):
+			item = strings.TrimPrefix(line, "- [ ] ")
+		default:
+			continue
+		}
+		criteria = append(criteria, strings.TrimSpace(item))
+		if len(criteria) == maxAcceptanceCriteria {
+			break
+		}
+	}
+	return criteria
+}
+
+// goOutline returns the top-level functions, methods and types of a Go
+// source file.
+func goOutline(src []byte) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+	var symbols []string
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			name := "func " + d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				name = fmt.Sprintf("func (%s) %s", receiverType(d.Recv.List[0].Type), d.Name.Name)
+			}
+			symbols = append(symbols, name)
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				kind := "type"
+				switch ts.Type.(type) {
+				case *ast.StructType:
+					kind = "struct"
+				case *ast.InterfaceType:
+					kind = "interface"
+				}
+				symbols = append(symbols, kind+" "+ts.Name.Name)
+			}
+		}
+	}
+	return symbols
+}
+
+// receiverType returns the type name of a method receiver.
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return "?"
+	}
+}
+
+// genericOutline returns the declarations outlinePattern finds in src.
+func genericOutline(src []byte) []string {
+	var symbols []string
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if m := outlinePattern.FindStringSubmatch(scanner.Text()); m != nil {
+			symbols = append(symbols, m[1]+" "+m[2])
+		}
+	}
+	return symbols
+}
+
+// uniqueMatches returns the first limit distinct values of matches.
+func uniqueMatches(matches []string, limit int) []string {
+	seen := make(map[string]bool, len(matches))
+	var unique []string
+	for _, m := range matches {
+		if seen[m] {
+			continue
+		}
+		seen[m] = true
+		unique = append(unique, m)
+		if len(unique) == limit {
+			break
+		}
+	}
+	return unique
+}
+
+// truncateToBudget cuts text to about budget tokens on a line boundary, or
+// on a rune boundary when the first line is longer than the budget.
+func truncateToBudget(text string, budget int) string {
+	limit := budget * charsPerToken
+	if len(text) <= limit {
+		return text
+	}
+	n := max(limit-len(truncatedContextSuffix), 0)
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	cut := text[:n]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + truncatedContextSuffix
+}
@@ -89,6 +89,18 @@ func (f *Formatter) FormatFile(ctx context.Context, filePath string) (*ToolResul
 
 // ShouldFormat determines if a file should be formatted per REQ-HOOK-073, REQ-HOOK-074.
 func (f *Formatter) ShouldFormat(filePath string) bool {
+	if isSkippedPath(filePath) {
+		return false
+	}
+
+	// Only format known code files
+	tools := f.registry.GetToolsForFile(filePath, ToolTypeFormatter)
+	return len(tools) > 0
+}
+
+// isSkippedPath reports whether filePath is a generated, vendored or
+// binary file that must not be formatted or linted.
+func isSkippedPath(filePath string) bool {
 	// Convert to slashes for consistent path handling
 	path := filepath.ToSlash(filePath)
 	baseName := strings.ToLower(filepath.Base(filePath))
@@ -96,14 +108,14 @@ func (f *Formatter) ShouldFormat(filePath string) bool {
 	// Check for skipped directories
 	for _, skipDir := range skipDirectories {
 		if strings.HasPrefix(path, skipDir+"/") || strings.Contains(path, "/"+skipDir+"/") {
-			return false
+			return true
 		}
 	}
 
 	// Check for compound extensions like .min.js, .min.css
 	for _, skipExt := range skipExtensions {
 		if strings.HasSuffix(baseName, skipExt) {
-			return false
+			return true
 		}
 	}
 
@@ -111,13 +123,10 @@ func (f *Formatter) ShouldFormat(filePath string) bool {
 	ext := strings.ToLower(filepath.Ext(filePath))
 	for _, skipExt := range skipExtensions {
 		if ext == skipExt {
-			return false
+			return true
 		}
 	}
-
-	// Only format known code files
-	tools := f.registry.GetToolsForFile(filePath, ToolTypeFormatter)
-	return len(tools) > 0
+	return false
 }
 
 // skipExtensions lists file extensions to skip per REQ-HOOK-073.
@@ -0,0 +1,130 @@
+package quality
+
+import (
+	"context"
+	"os"
+)
+
+// ProcessOptions controls which steps FileProcessor.Process runs.
+type ProcessOptions struct {
+	// Format runs the file's formatter.
+	Format bool
+
+	// LintFix runs the linter's auto-fix before the final lint.
+	LintFix bool
+
+	// TimeoutSeconds overrides the timeout of each tool run; zero keeps
+	// the registered timeouts.
+	TimeoutSeconds int
+}
+
+// ProcessResult holds the results of the steps run on a file. Steps that
+// were skipped or had no available tool leave their result nil.
+type ProcessResult struct {
+	Language string
+	Format   *ToolResult
+	Fix      *ToolResult
+	Lint     *ToolResult
+
+	// Remaining is the linter summary of issues left after formatting and
+	// auto-fixing, or "" if there are none.
+	Remaining string
+}
+
+// Modified reports whether the formatter or the auto-fix changed the file.
+func (r *ProcessResult) Modified() bool {
+	return (r.Format != nil && r.Format.FileModified) || (r.Fix != nil && r.Fix.FileModified)
+}
+
+// FileProcessor formats a file, applies linter auto-fixes and lints the
+// result, using the formatters and linters of one tool registry.
+type FileProcessor struct {
+	registry  *toolRegistry
+	detector  *ChangeDetector
+	formatter *Formatter
+	linter    *Linter
+}
+
+// NewFileProcessor creates a FileProcessor. A nil registry uses the
+// default tools.
+func NewFileProcessor(registry *toolRegistry) *FileProcessor {
+	if registry == nil {
+		registry = NewToolRegistry()
+	}
+	detector := NewChangeDetector()
+	return &FileProcessor{
+		registry:  registry,
+		detector:  detector,
+		formatter: NewFormatterWithRegistry(registry, detector),
+		linter:    NewLinter(registry),
+	}
+}
+
+// Language returns the language the registry associates with filePath.
+func (p *FileProcessor) Language(filePath string) string {
+	return languageFromExtension(extensionFromPath(filePath))
+}
+
+// Supports reports whether filePath is a source file with a registered
+// formatter or linter.
+func (p *FileProcessor) Supports(filePath string) bool {
+	if isSkippedPath(filePath) {
+		return false
+	}
+	return len(p.registry.GetToolsForFile(filePath, ToolTypeFormatter)) > 0 ||
+		len(p.registry.GetToolsForFile(filePath, ToolTypeLinter)) > 0
+}
+
+// Hash returns the SHA-256 hash of the file at filePath.
+func (p *FileProcessor) Hash(filePath string) ([]byte, error) {
+	return p.detector.ComputeHash(filePath)
+}
+
+// Process formats filePath, auto-fixes lint issues and lints the result as
+// selected by opts. Tool failures are reported in the step results; only
+// a missing file is returned as an error.
+func (p *FileProcessor) Process(ctx context.Context, filePath string, opts ProcessOptions) (*ProcessResult, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, err
+	}
+	language := p.Language(filePath)
+	if opts.TimeoutSeconds > 0 {
+		p.registry.SetLanguageTimeout(language, opts.TimeoutSeconds)
+	}
+	result := &ProcessResult{Language: language}
+
+	if opts.Format {
+		formatted, err := p.formatter.FormatFile(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+		result.Format = formatted
+	}
+
+	if opts.LintFix {
+		before, err := p.detector.ComputeHash(filePath)
+		if err != nil {
+			return nil, err
+		}
+		fixed, err := p.linter.AutoFix(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+		if fixed != nil {
+			fixed.FileModified, _ = p.detector.HasChanged(filePath, before)
+		}
+		result.Fix = fixed
+	}
+
+	lint, err := p.linter.LintFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if lint.ToolName != "" {
+		result.Lint = lint
+		if lint.IssuesFound > 0 {
+			result.Remaining = p.linter.GenerateSummary(lint)
+		}
+	}
+	return result, nil
+}
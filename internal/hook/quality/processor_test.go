@@ -0,0 +1,150 @@
+package quality
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Shell scripts standing in for a formatter and a linter of ".fmttest"
+// files. The formatter upper-cases the file, the linter's fix removes FIXME
+// lines and the lint reports one warning per TODO line.
+const (
+	fakeFormatScript = `tr a-z A-Z < "$0" > "$0.tmp" && mv "$0.tmp" "$0"`
+	fakeLintScript   = `if [ "$1" = --fix ]; then grep -v FIXME "$0" > "$0.tmp"; mv "$0.tmp" "$0"; echo "1 fixed"; exit 0; fi
+grep -n TODO "$0" | while IFS=: read -r n rest; do echo "$0:$n: warning: TODO left"; done
+! grep -q TODO "$0"`
+)
+
+// newFakeToolRegistry returns a registry with the fake formatter and linter.
+func newFakeToolRegistry(t *testing.T) *toolRegistry {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	r := NewToolRegistry()
+	r.RegisterTool(ToolConfig{Name: "fake-format", Command: "sh", Args: []string{"-c", fakeFormatScript, "{file}"}, Extensions: []string{".fmttest"}, ToolType: ToolTypeFormatter, Priority: 1})
+	r.RegisterTool(ToolConfig{Name: "fake-lint", Command: "sh", Args: []string{"-c", fakeLintScript, "{file}"}, Extensions: []string{".fmttest"}, ToolType: ToolTypeLinter, Priority: 1, FixArgs: []string{"--fix"}})
+	return r
+}
+
+func writeProcessFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sample.fmttest")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFileProcessor_FormatFixAndLint(t *testing.T) {
+	t.Parallel()
+
+	p := NewFileProcessor(newFakeToolRegistry(t))
+	path := writeProcessFile(t, "hello\nFIXME drop\nTODO keep\n")
+
+	result, err := p.Process(context.Background(), path, ProcessOptions{Format: true, LintFix: true})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+
+	got, _ := os.ReadFile(path)
+	if string(got) != "HELLO\nTODO KEEP\n" {
+		t.Errorf("file content = %q, want formatted and fixed", got)
+	}
+	if result.Language != "fmttest" {
+		t.Errorf("Language = %q, want fmttest", result.Language)
+	}
+	if result.Format == nil || !result.Format.FileModified || result.Format.ToolName != "fake-format" {
+		t.Errorf("Format = %+v, want modified by fake-format", result.Format)
+	}
+	if result.Fix == nil || !result.Fix.FileModified || result.Fix.IssuesFixed != 1 {
+		t.Errorf("Fix = %+v, want one fix that modified the file", result.Fix)
+	}
+	if !result.Modified() {
+		t.Error("Modified() = false, want true")
+	}
+	if result.Lint == nil || result.Lint.IssuesFound != 1 {
+		t.Errorf("Lint = %+v, want one remaining issue", result.Lint)
+	}
+	if !strings.Contains(result.Remaining, "TODO left") {
+		t.Errorf("Remaining = %q, want the TODO warning", result.Remaining)
+	}
+}
+
+func TestFileProcessor_LintOnly(t *testing.T) {
+	t.Parallel()
+
+	p := NewFileProcessor(newFakeToolRegistry(t))
+	path := writeProcessFile(t, "clean\n")
+
+	result, err := p.Process(context.Background(), path, ProcessOptions{})
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if result.Format != nil || result.Fix != nil {
+		t.Errorf("Format/Fix = %+v/%+v, want skipped", result.Format, result.Fix)
+	}
+	if result.Modified() {
+		t.Error("Modified() = true for a lint-only run")
+	}
+	if result.Remaining != "" {
+		t.Errorf("Remaining = %q, want none", result.Remaining)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "clean\n" {
+		t.Errorf("file content = %q, want unchanged", got)
+	}
+}
+
+func TestFileProcessor_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	p := NewFileProcessor(newFakeToolRegistry(t))
+	if _, err := p.Process(context.Background(), filepath.Join(t.TempDir(), "gone.fmttest"), ProcessOptions{Format: true}); err == nil {
+		t.Error("Process() on a missing file: want error")
+	}
+}
+
+func TestFileProcessor_Supports(t *testing.T) {
+	t.Parallel()
+
+	p := NewFileProcessor(newFakeToolRegistry(t))
+	tests := map[string]bool{
+		"a.fmttest":          true,
+		"main.go":            true,
+		"config.yaml":        false,
+		"notes.unknown":      false,
+		"vendor/lib.fmttest": false,
+	}
+	for path, want := range tests {
+		if got := p.Supports(path); got != want {
+			t.Errorf("Supports(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestFileProcessor_TimeoutOverride(t *testing.T) {
+	t.Parallel()
+
+	r := newFakeToolRegistry(t)
+	p := NewFileProcessor(r)
+	path := writeProcessFile(t, "x\n")
+	if _, err := p.Process(context.Background(), path, ProcessOptions{TimeoutSeconds: 7}); err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	for _, toolType := range []ToolType{ToolTypeFormatter, ToolTypeLinter} {
+		for _, tool := range r.GetToolsForLanguage("fmttest", toolType) {
+			if tool.TimeoutSeconds != 7 {
+				t.Errorf("%s timeout = %d, want 7", tool.Name, tool.TimeoutSeconds)
+			}
+		}
+	}
+	for _, tool := range r.GetToolsForLanguage("go", ToolTypeFormatter) {
+		if tool.TimeoutSeconds != 30 {
+			t.Errorf("%s timeout = %d, want default 30", tool.Name, tool.TimeoutSeconds)
+		}
+	}
+}
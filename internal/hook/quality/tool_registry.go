@@ -73,6 +73,27 @@ func (r *toolRegistry) RegisterTool(tool ToolConfig) {
 	r.tools[tool.ToolType] = append(r.tools[tool.ToolType], tool)
 }
 
+// SetLanguageTimeout sets the timeout of every registered tool for language.
+// Non-positive values are ignored.
+func (r *toolRegistry) SetLanguageTimeout(language string, seconds int) {
+	if seconds <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, tools := range r.tools {
+		for i := range tools {
+			for _, ext := range tools[i].Extensions {
+				if languageFromExtension(ext) == language {
+					tools[i].TimeoutSeconds = seconds
+					break
+				}
+			}
+		}
+	}
+}
+
 // GetToolsForLanguage returns tools for a language sorted by priority per REQ-HOOK-052.
 func (r *toolRegistry) GetToolsForLanguage(language string, toolType ToolType) []ToolConfig {
 	r.mu.RLock()
@@ -12,26 +12,45 @@ import (
 	"time"
 
 	"github.com/modu-ai/moai-adk/internal/rank"
+	"github.com/modu-ai/moai-adk/internal/resilience"
 )
 
+// rankOutboxStartTimeout bounds the outbox flush on SessionStart, which
+// delays the start of the session.
+const rankOutboxStartTimeout = 5 * time.Second
+
 // rankSessionHandler processes SessionEnd events and submits metrics to MoAI Rank API.
-// It checks exclusion patterns and submits session data non-blocking.
+// It checks exclusion patterns and queues the session in the rank outbox
+// before submitting, so sessions that end offline are submitted later.
 // Errors are logged but don't break the hook chain (per REQ-HOOK-034).
 type rankSessionHandler struct {
 	patternStore *rank.PatternStore
 	credStore    rank.CredentialStore
+	outbox       *rank.Outbox
+	newClient    func(apiKey string) rank.Client
 }
 
 // NewRankSessionHandler creates a new rank session handler.
 // The patternStore is used to check if a project should be excluded from metrics.
 // The credStore is used to load API credentials for submission.
 func NewRankSessionHandler(patternStore *rank.PatternStore, credStore rank.CredentialStore) Handler {
+	outbox, err := rank.NewOutbox("")
+	if err != nil {
+		slog.Warn("rank: outbox unavailable, sessions are submitted directly", "error", err)
+	}
 	return &rankSessionHandler{
 		patternStore: patternStore,
 		credStore:    credStore,
+		outbox:       outbox,
+		newClient:    newRankClient,
 	}
 }
 
+// newRankClient creates the MoAI Rank API client for apiKey.
+func newRankClient(apiKey string) rank.Client {
+	return rank.NewClient(apiKey)
+}
+
 // EventType returns EventSessionEnd.
 func (h *rankSessionHandler) EventType() EventType {
 	return EventSessionEnd
@@ -69,8 +88,22 @@ func (h *rankSessionHandler) Handle(ctx context.Context, input *HookInput) (*Hoo
 		return &HookOutput{}, nil
 	}
 
-	// Create rank client and submit session
-	client := rank.NewClient(apiKey)
+	client := h.newClient(apiKey)
+	transcriptPath := rank.FindTranscriptForSession(input.SessionID)
+
+	// Queue the session first so that it survives a failed submission,
+	// then submit everything queued, including earlier failures
+	if h.outbox != nil {
+		_, err := h.outbox.Enqueue(submission, transcriptPath)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(ctx, EnvRankTimeout())
+			defer cancel()
+			flushRankOutbox(ctx, client, h.outbox)
+			return &HookOutput{}, nil
+		}
+		slog.Warn("rank: failed to queue session, submitting directly", "error", err)
+	}
+
 	if err := client.SubmitSession(ctx, submission); err != nil {
 		slog.Warn("rank: failed to submit session", "error", err)
 		return &HookOutput{}, nil
@@ -82,7 +115,6 @@ func (h *rankSessionHandler) Handle(ctx context.Context, input *HookInput) (*Hoo
 	)
 
 	// Mark session in sync state to prevent re-submission during sync
-	transcriptPath := rank.FindTranscriptForSession(input.SessionID)
 	if transcriptPath != "" {
 		if syncState, syncErr := rank.NewSyncState(""); syncErr == nil {
 			_ = syncState.MarkSynced(transcriptPath)
@@ -94,6 +126,67 @@ func (h *rankSessionHandler) Handle(ctx context.Context, input *HookInput) (*Hoo
 	return &HookOutput{}, nil
 }
 
+// flushRankOutbox submits the sessions queued in outbox and marks their
+// transcripts as synced. Failures are logged; the sessions stay queued.
+func flushRankOutbox(ctx context.Context, client rank.Client, outbox *rank.Outbox, opts ...rank.FlushOption) {
+	syncState, err := rank.NewSyncState("")
+	if err == nil {
+		opts = append(opts, rank.WithSyncState(syncState))
+	}
+
+	result, err := outbox.Flush(ctx, client, opts...)
+	if result != nil && result.Submitted > 0 {
+		slog.Info("rank: queued sessions submitted", "submitted", result.Submitted, "pending", result.Pending)
+		if syncState != nil {
+			_ = syncState.Save()
+		}
+	}
+	if err != nil {
+		slog.Warn("rank: sessions remain queued", "error", err)
+	}
+}
+
+// rankOutboxFlushHandler submits the sessions left in the rank outbox on
+// SessionStart, so sessions that ended offline reach MoAI Rank once the
+// network is back.
+type rankOutboxFlushHandler struct {
+	credStore rank.CredentialStore
+	outbox    *rank.Outbox
+	newClient func(apiKey string) rank.Client
+}
+
+// NewRankOutboxFlushHandler creates a SessionStart handler that flushes
+// outbox with the credentials of credStore.
+func NewRankOutboxFlushHandler(credStore rank.CredentialStore, outbox *rank.Outbox) Handler {
+	return &rankOutboxFlushHandler{
+		credStore: credStore,
+		outbox:    outbox,
+		newClient: newRankClient,
+	}
+}
+
+// EventType returns EventSessionStart.
+func (h *rankOutboxFlushHandler) EventType() EventType {
+	return EventSessionStart
+}
+
+// Handle flushes the outbox with a single attempt per batch, since it
+// delays the session start. It never blocks.
+func (h *rankOutboxFlushHandler) Handle(ctx context.Context, _ *HookInput) (*HookOutput, error) {
+	if h.outbox == nil || h.outbox.Len() == 0 {
+		return &HookOutput{}, nil
+	}
+	apiKey, err := h.credStore.GetAPIKey()
+	if err != nil || apiKey == "" {
+		return &HookOutput{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rankOutboxStartTimeout)
+	defer cancel()
+	flushRankOutbox(ctx, h.newClient(apiKey), h.outbox, rank.WithRetryPolicy(resilience.RetryPolicy{}))
+	return &HookOutput{}, nil
+}
+
 // buildSessionSubmission creates a SessionSubmission from HookInput.
 // It attempts to parse the transcript file for actual token usage.
 func (h *rankSessionHandler) buildSessionSubmission(input *HookInput) (*rank.SessionSubmission, error) {
@@ -0,0 +1,86 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/rank"
+)
+
+// fakeRankCredentials is a rank.CredentialStore holding a fixed API key.
+type fakeRankCredentials struct {
+	rank.CredentialStore
+	apiKey string
+}
+
+func (f fakeRankCredentials) GetAPIKey() (string, error) {
+	return f.apiKey, nil
+}
+
+// TestRankSession_QueuesOfflineAndFlushesOnSessionStart verifies that a
+// session ending without network stays in the outbox and is submitted by
+// the next SessionStart.
+func TestRankSession_QueuesOfflineAndFlushesOnSessionStart(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	var mu sync.Mutex
+	online := false
+	var received []*rank.SessionSubmission
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !online {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var payload struct {
+			Sessions []*rank.SessionSubmission `json:"sessions"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		received = append(received, payload.Sessions...)
+		_, _ = w.Write([]byte(`{"success":true,"processed":1,"succeeded":1,"failed":0}`))
+	}))
+	defer srv.Close()
+
+	outbox, err := rank.NewOutbox(filepath.Join(t.TempDir(), "outbox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newClient := func(apiKey string) rank.Client {
+		return rank.NewClient(apiKey, rank.WithBaseURL(srv.URL))
+	}
+	creds := fakeRankCredentials{apiKey: "key"}
+
+	end := &rankSessionHandler{credStore: creds, outbox: outbox, newClient: newClient}
+	input := &HookInput{SessionID: "session-1", ProjectDir: t.TempDir(), Model: "claude-sonnet-4"}
+	if _, err := end.Handle(context.Background(), input); err != nil {
+		t.Fatalf("SessionEnd: %v", err)
+	}
+	if n := outbox.Len(); n != 1 {
+		t.Fatalf("expected the offline session to be queued, outbox has %d", n)
+	}
+
+	mu.Lock()
+	online = true
+	mu.Unlock()
+
+	start := &rankOutboxFlushHandler{credStore: creds, outbox: outbox, newClient: newClient}
+	if start.EventType() != EventSessionStart {
+		t.Errorf("EventType = %s, want SessionStart", start.EventType())
+	}
+	if _, err := start.Handle(context.Background(), &HookInput{}); err != nil {
+		t.Fatalf("SessionStart: %v", err)
+	}
+
+	if n := outbox.Len(); n != 0 {
+		t.Errorf("expected the outbox to be flushed, %d left", n)
+	}
+	if len(received) != 1 || received[0].ModelName != "claude-sonnet-4" {
+		t.Errorf("received sessions = %+v", received)
+	}
+}
@@ -0,0 +1,343 @@
+package hook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// isFileReadTool reports whether toolName reads or lists files.
+func isFileReadTool(toolName string) bool {
+	switch toolName {
+	case "Read", "Grep", "Glob":
+		return true
+	default:
+		return false
+	}
+}
+
+// readPaths returns the file system paths a read tool call touches:
+// Read file_path, Grep path (cwd when absent) and glob filter, Glob path
+// joined with pattern.
+func readPaths(toolName string, parsed map[string]any, cwd string) []string {
+	str := func(key string) string {
+		v, _ := parsed[key].(string)
+		return v
+	}
+
+	switch toolName {
+	case "Read":
+		if p := str("file_path"); p != "" {
+			return []string{p}
+		}
+	case "Grep":
+		var paths []string
+		p := str("path")
+		if p == "" {
+			p = cwd
+		}
+		if p != "" {
+			paths = append(paths, p)
+			if g := str("glob"); g != "" {
+				paths = append(paths, filepath.Join(p, g))
+			}
+		} else if g := str("glob"); g != "" {
+			paths = append(paths, g)
+		}
+		return paths
+	case "Glob":
+		pattern := str("pattern")
+		if p := str("path"); p != "" {
+			if pattern == "" {
+				return []string{p}
+			}
+			return []string{p, filepath.Join(p, pattern)}
+		}
+		if pattern != "" {
+			return []string{pattern}
+		}
+	}
+	return nil
+}
+
+// readSubjects returns the strings read rules are matched against for path:
+// the path as given and its absolute form (with "~/" expanded), both NFC
+// normalized with forward slashes. Directories get a trailing slash so that rules such as
+// `\.ssh/.*` also match a Grep or Glob rooted at the directory itself.
+func readSubjects(path string) (resolved string, subjects []string) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	resolved, err := filepath.Abs(path)
+	if err != nil {
+		resolved = path
+	}
+
+	subjects = []string{
+		norm.NFC.String(strings.ReplaceAll(path, "\\", "/")),
+		norm.NFC.String(strings.ReplaceAll(resolved, "\\", "/")),
+	}
+	if info, err := os.Stat(resolved); err == nil && info.IsDir() {
+		for i, s := range subjects {
+			subjects[i] = strings.TrimSuffix(s, "/") + "/"
+		}
+	}
+	return resolved, subjects
+}
+
+// checkReadAccess checks the paths read by Read, Grep and Glob against the
+// policy's read rules. Reads outside the project directory are allowed, but
+// still matched against the rules, so "~/.aws/credentials" is caught, and a
+// path that uses ".." to climb out of the project is denied as it is for
+// writes. A Grep
+// without a path searches cwd, which defaults to the project directory.
+//
+// A content-mode Grep rooted at a directory would print matching lines from
+// every file below it, so the files it would search are checked as well
+// (see checkGrepTree).
+//
+// Redact rules are not checked here; they are enforced on the tool result
+// by redactedRead in PostToolUse.
+// Returns (decision, reason) where decision is "deny", "ask", or "" for allow.
+func (h *preToolHandler) checkReadAccess(toolInput json.RawMessage, toolName, cwd string) (string, string) {
+	var parsed map[string]any
+	if err := json.Unmarshal(toolInput, &parsed); err != nil {
+		return "", ""
+	}
+	if cwd == "" {
+		cwd = h.projectDir
+	}
+
+	var ask string
+	for _, path := range readPaths(toolName, parsed, cwd) {
+		if escapesProject(h.projectDir, path) {
+			return DecisionDeny, "Path traversal detected: file is outside project directory"
+		}
+		resolved, subjects := readSubjects(path)
+		target := matchTarget{path: projectRelativePath(h.projectDir, resolved)}
+
+		rule := h.policy.evaluate(h.policy.ReadRules, target, subjects...)
+		if rule == nil {
+			continue
+		}
+		if rule.Severity == SeverityDeny {
+			return DecisionDeny, ruleReason(rule, fmt.Sprintf("Protected file: reading %s is blocked because it may contain credentials", path))
+		}
+		if ask == "" {
+			ask = ruleReason(rule, fmt.Sprintf("Sensitive file: %s may contain credentials. Please confirm.", path))
+		}
+	}
+	if toolName == "Grep" && returnsContent(toolName, parsed) {
+		if paths := readPaths(toolName, parsed, cwd); len(paths) > 0 {
+			glob, _ := parsed["glob"].(string)
+			decision, reason := h.checkGrepTree(paths[0], glob)
+			if decision == DecisionDeny {
+				return decision, reason
+			}
+			if decision == DecisionAsk && ask == "" {
+				ask = reason
+			}
+		}
+	}
+	if ask != "" {
+		return DecisionAsk, ask
+	}
+	return "", ""
+}
+
+// maxGrepTreeEntries bounds the walk of checkGrepTree. Larger trees are not
+// checked file by file; the search asks for confirmation instead.
+const maxGrepTreeEntries = 50000
+
+// grepTreeSkipDirs are directories the walk of checkGrepTree does not enter.
+var grepTreeSkipDirs = map[string]bool{".git": true, "node_modules": true}
+
+// checkGrepTree checks the files below root that a content-mode Grep would
+// search against the read rules, so that a search of the project root cannot
+// print the lines of .env. Files excluded by the Grep glob filter are
+// skipped. Returns ("", "") when root is not a directory.
+func (h *preToolHandler) checkGrepTree(root, glob string) (string, string) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", ""
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return "", ""
+	}
+
+	var deny, ask *SecurityRule
+	var denyPath, askPath string
+	entries := 0
+	tooLarge := false
+	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entries++; entries > maxGrepTreeEntries {
+			tooLarge = true
+			return filepath.SkipAll
+		}
+		if d.IsDir() {
+			if path != root && grepTreeSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || globExcludes(glob, filepath.ToSlash(rel)) {
+			return nil
+		}
+		subject := norm.NFC.String(filepath.ToSlash(path))
+		target := matchTarget{path: projectRelativePath(h.projectDir, path)}
+		rule := h.policy.evaluate(h.policy.ReadRules, target, subject)
+		switch {
+		case rule == nil:
+		case rule.Severity == SeverityDeny:
+			deny, denyPath = rule, path
+			return filepath.SkipAll
+		case ask == nil:
+			ask, askPath = rule, path
+		}
+		return nil
+	})
+
+	switch {
+	case deny != nil:
+		return DecisionDeny, ruleReason(deny, fmt.Sprintf(
+			"Protected file: a content search of %s would print lines of %s. Narrow the path or add a glob that excludes it",
+			root, DisplayPath(root, denyPath)))
+	case tooLarge:
+		return DecisionAsk, fmt.Sprintf("Content search of %s covers more than %d files that could not all be checked for protected files. Please confirm.",
+			root, maxGrepTreeEntries)
+	case ask != nil:
+		return DecisionAsk, ruleReason(ask, fmt.Sprintf("Sensitive file: a content search of %s would print lines of %s. Please confirm.",
+			root, DisplayPath(root, askPath)))
+	}
+	return "", ""
+}
+
+// globExcludes reports whether the Grep glob filter excludes the file at
+// rel, a slash-separated path relative to the search root. Like ripgrep, a
+// glob without "/" matches the base name. Brace alternatives are expanded
+// and a leading "**/" is dropped; anything else that cannot be decided is
+// treated as not excluded.
+func globExcludes(glob, rel string) bool {
+	if glob == "" || strings.HasPrefix(glob, "!") {
+		return false
+	}
+	for _, g := range expandBraces(glob) {
+		g = strings.TrimPrefix(g, "**/")
+		name := rel
+		if !strings.Contains(g, "/") {
+			name = path.Base(rel)
+		} else if strings.Contains(g, "**") {
+			return false
+		}
+		matched, err := path.Match(g, name)
+		if err != nil || matched {
+			return false
+		}
+	}
+	return true
+}
+
+// expandBraces expands the first "{a,b}" group of glob, recursively, so
+// "*.{go,md}" yields "*.go" and "*.md".
+func expandBraces(glob string) []string {
+	start := strings.Index(glob, "{")
+	if start < 0 {
+		return []string{glob}
+	}
+	end := strings.Index(glob[start:], "}")
+	if end < 0 {
+		return []string{glob}
+	}
+	end += start
+	var out []string
+	for alt := range strings.SplitSeq(glob[start+1:end], ",") {
+		out = append(out, expandBraces(glob[:start]+alt+glob[end+1:])...)
+	}
+	return out
+}
+
+// returnsContent reports whether a read tool call returns file content
+// rather than file names: Read, and Grep in "content" output mode.
+func returnsContent(toolName string, parsed map[string]any) bool {
+	switch toolName {
+	case "Read":
+		return true
+	case "Grep":
+		mode, _ := parsed["output_mode"].(string)
+		return mode == "content"
+	default:
+		return false
+	}
+}
+
+// escapesProject reports whether path contains a ".." element and resolves
+// outside projectDir. Absolute paths elsewhere are not traversal, so reading
+// ~/.gitconfig stays allowed while "../../.gitconfig" is not.
+func escapesProject(projectDir, path string) bool {
+	if projectDir == "" || !slices.Contains(strings.Split(filepath.ToSlash(path), "/"), "..") {
+		return false
+	}
+	_, inside, err := resolveProjectPath(projectDir, path)
+	return err != nil || !inside
+}
+
+// redactedRead returns the redact rule matched by a file whose content is in
+// the result of a Read or content-mode Grep, or nil. Grep files are taken
+// from the search path, the "filenames" of the response and the path prefix
+// of each "path:line:text" content line. Hooks cannot rewrite the result of
+// built-in tools, so the caller blocks it instead: Claude is told not to use
+// the content and the user is told what was withheld.
+func redactedRead(policy *SecurityPolicy, projectDir string, input *HookInput) *SecurityRule {
+	var parsed map[string]any
+	if err := json.Unmarshal(input.ToolInput, &parsed); err != nil {
+		return nil
+	}
+	if !returnsContent(input.ToolName, parsed) {
+		return nil
+	}
+
+	candidates := readPaths(input.ToolName, parsed, input.CWD)
+	if input.ToolName == "Grep" {
+		var response struct {
+			Filenames []string `json:"filenames"`
+			Content   string   `json:"content"`
+		}
+		if err := json.Unmarshal(input.ToolResponse, &response); err == nil {
+			candidates = append(candidates, response.Filenames...)
+			for line := range strings.SplitSeq(response.Content, "\n") {
+				if name, _, ok := strings.Cut(line, ":"); ok && name != "" {
+					candidates = append(candidates, name)
+				}
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, path := range candidates {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if !filepath.IsAbs(path) && input.CWD != "" && !strings.HasPrefix(path, "~/") {
+			path = filepath.Join(input.CWD, path)
+		}
+		resolved, subjects := readSubjects(path)
+		target := matchTarget{path: projectRelativePath(projectDir, resolved)}
+		if rule := policy.redaction(policy.ReadRules, target, subjects...); rule != nil {
+			return rule
+		}
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestReadPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		toolName string
+		input    map[string]any
+		want     []string
+	}{
+		{"Read", "Read", map[string]any{"file_path": "/p/.env"}, []string{"/p/.env"}},
+		{"Grep with path and glob", "Grep", map[string]any{"pattern": "x", "path": "/p", "glob": "*.pem"}, []string{"/p", "/p/*.pem"}},
+		{"Grep without path searches cwd", "Grep", map[string]any{"pattern": "x"}, []string{"/cwd"}},
+		{"Grep with glob only", "Grep", map[string]any{"pattern": "x", "glob": "*.pem"}, []string{"/cwd", "/cwd/*.pem"}},
+		{"Glob with path", "Glob", map[string]any{"pattern": "**/*.key", "path": "/p"}, []string{"/p", "/p/**/*.key"}},
+		{"Glob pattern only", "Glob", map[string]any{"pattern": "**/id_rsa"}, []string{"**/id_rsa"}},
+		{"other tool", "Write", map[string]any{"file_path": "/p/.env"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := readPaths(tt.toolName, tt.input, "/cwd")
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("readPaths() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreToolHandler_ReadAccess(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".ssh"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Korean directory name exercises NFC path normalization.
+	projectDir := filepath.Join(dir, "프로젝트")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		toolName     string
+		input        map[string]any
+		cwd          string
+		wantDecision string
+		wantInReason string
+	}{
+		{"Read .env is denied", "Read", map[string]any{"file_path": filepath.Join(projectDir, ".env")}, "", DecisionDeny, "read.env-file"},
+		{"Read .env.production is denied", "Read", map[string]any{"file_path": filepath.Join(projectDir, ".env.production")}, "", DecisionDeny, "read.env-file"},
+		{"Read .env.example is allowed", "Read", map[string]any{"file_path": filepath.Join(projectDir, ".env.example")}, "", DecisionAllow, ""},
+		{"Read .envrc is allowed", "Read", map[string]any{"file_path": filepath.Join(projectDir, ".envrc")}, "", DecisionAllow, ""},
+		{"Read aws credentials outside project is denied", "Read", map[string]any{"file_path": "/home/dev/.aws/credentials"}, "", DecisionDeny, "read.credentials-file"},
+		{"Read ssh key is denied", "Read", map[string]any{"file_path": "/home/dev/.ssh/id_ed25519"}, "", DecisionDeny, "read.ssh-dir"},
+		{"Read public key elsewhere is allowed", "Read", map[string]any{"file_path": filepath.Join(projectDir, "deploy", "id_rsa.pub")}, "", DecisionAllow, ""},
+		{"Read source outside project is allowed", "Read", map[string]any{"file_path": "/usr/local/go/src/fmt/print.go"}, "", DecisionAllow, ""},
+		{"Read npmrc asks", "Read", map[string]any{"file_path": filepath.Join(projectDir, ".npmrc")}, "", DecisionAsk, "read.npmrc"},
+		{"Read source file is allowed", "Read", map[string]any{"file_path": filepath.Join(projectDir, "main.go")}, "", DecisionAllow, ""},
+		{"Read climbing out of project is denied", "Read", map[string]any{"file_path": projectDir + "/../../etc/hosts"}, "", DecisionDeny, "Path traversal detected"},
+		{"Read with dot-dot inside project is allowed", "Read", map[string]any{"file_path": projectDir + "/src/../main.go"}, "", DecisionAllow, ""},
+		{"Grep in ssh directory is denied", "Grep", map[string]any{"pattern": "BEGIN", "path": filepath.Join(dir, ".ssh")}, "", DecisionDeny, "read.ssh-dir"},
+		{"Grep with pem glob is denied", "Grep", map[string]any{"pattern": "BEGIN", "path": projectDir, "glob": "*.pem"}, "", DecisionDeny, "read.pem"},
+		{"Grep in project is allowed", "Grep", map[string]any{"pattern": "func main", "path": projectDir}, "", DecisionAllow, ""},
+		{"Glob for env files is denied", "Glob", map[string]any{"pattern": "**/.env"}, "", DecisionDeny, "read.env-file"},
+		{"Glob for go files is allowed", "Glob", map[string]any{"pattern": "**/*.go", "path": projectDir}, "", DecisionAllow, ""},
+		{"Grep without path in ssh directory is denied", "Grep", map[string]any{"pattern": "BEGIN"}, filepath.Join(dir, ".ssh"), DecisionDeny, "read.ssh-dir"},
+		{"Grep without path uses the glob under cwd", "Grep", map[string]any{"pattern": "BEGIN", "glob": "*.pem"}, projectDir, DecisionDeny, "read.pem"},
+		{"Grep without path in project is allowed", "Grep", map[string]any{"pattern": "func main"}, "", DecisionAllow, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &preToolHandler{policy: DefaultSecurityPolicy(), projectDir: projectDir}
+			raw, err := json.Marshal(tt.input)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			got, err := h.Handle(context.Background(), &HookInput{ToolName: tt.toolName, ToolInput: raw, CWD: tt.cwd})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			out := got.HookSpecificOutput
+			if out.PermissionDecision != tt.wantDecision {
+				t.Errorf("PermissionDecision = %q, want %q (reason: %s)", out.PermissionDecision, tt.wantDecision, out.PermissionDecisionReason)
+			}
+			if !strings.Contains(out.PermissionDecisionReason, tt.wantInReason) {
+				t.Errorf("reason %q should contain %q", out.PermissionDecisionReason, tt.wantInReason)
+			}
+		})
+	}
+}
+
+func TestPreToolHandler_GrepContentOfDirectory(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	for name, content := range map[string]string{
+		"main.go":        "package main\n",
+		".env":           "AWS_SECRET=abc\n",
+		"docs/readme.md": "AWS_SECRET is read from .env\n",
+	} {
+		path := filepath.Join(projectDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		input        map[string]any
+		wantDecision string
+	}{
+		{"pathless content search cannot print .env", map[string]any{"pattern": "AWS_SECRET", "output_mode": "content"}, DecisionDeny},
+		{"content search of project root cannot print .env", map[string]any{"pattern": "AWS_SECRET", "path": projectDir, "output_mode": "content"}, DecisionDeny},
+		{"glob excluding .env is allowed", map[string]any{"pattern": "AWS_SECRET", "glob": "*.{go,md}", "output_mode": "content"}, DecisionAllow},
+		{"subdirectory without protected files is allowed", map[string]any{"pattern": "AWS_SECRET", "path": filepath.Join(projectDir, "docs"), "output_mode": "content"}, DecisionAllow},
+		{"file list of project root is allowed", map[string]any{"pattern": "AWS_SECRET"}, DecisionAllow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			raw, _ := json.Marshal(tt.input)
+			h := &preToolHandler{policy: DefaultSecurityPolicy(), projectDir: projectDir}
+			got, err := h.Handle(context.Background(), &HookInput{ToolName: "Grep", ToolInput: raw, CWD: projectDir})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			out := got.HookSpecificOutput
+			if out.PermissionDecision != tt.wantDecision {
+				t.Errorf("PermissionDecision = %q, want %q (reason: %s)", out.PermissionDecision, tt.wantDecision, out.PermissionDecisionReason)
+			}
+			if tt.wantDecision == DecisionDeny && !strings.Contains(out.PermissionDecisionReason, "read.env-file") {
+				t.Errorf("reason %q should name read.env-file", out.PermissionDecisionReason)
+			}
+		})
+	}
+}
+
+func TestGlobExcludes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		glob string
+		rel  string
+		want bool
+	}{
+		{"", ".env", false},
+		{"*.go", ".env", true},
+		{"*.go", "cmd/main.go", false},
+		{"*.{go,md}", "docs/readme.md", false},
+		{"*.{go,md}", "config/.env", true},
+		{"**/*.go", "config/.env", true},
+		{"src/*.go", ".env", true},
+		{"src/**/*.go", ".env", false},
+		{"!*.env", ".env", false},
+	}
+	for _, tt := range tests {
+		if got := globExcludes(tt.glob, tt.rel); got != tt.want {
+			t.Errorf("globExcludes(%q, %q) = %v, want %v", tt.glob, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestResolveProjectPath(t *testing.T) {
+	t.Parallel()
+
+	project := filepath.Join(t.TempDir(), "프로젝트")
+	tests := []struct {
+		name       string
+		path       string
+		wantInside bool
+	}{
+		{"inside", filepath.Join(project, "a", "b.go"), true},
+		{"project root", project, true},
+		{"traversal", filepath.Join(project, "..", "other", "x.go"), false},
+		{"sibling with shared prefix", project + "-other/x.go", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, inside, err := resolveProjectPath(project, tt.path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inside != tt.wantInside {
+				t.Errorf("resolveProjectPath(%q) inside = %v, want %v", tt.path, inside, tt.wantInside)
+			}
+		})
+	}
+}
+
+func TestPreToolHandler_RedactRulesAllowRead(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewSecurityPolicy(config.SecurityConfig{
+		Rules: []config.SecurityRuleConfig{{ID: "read.env-file", Severity: "redact"}},
+	})
+	if err != nil {
+		t.Fatalf("NewSecurityPolicy() error: %v", err)
+	}
+	projectDir := t.TempDir()
+
+	raw, _ := json.Marshal(map[string]any{"file_path": filepath.Join(projectDir, ".env")})
+	h := &preToolHandler{policy: policy, projectDir: projectDir}
+	got, err := h.Handle(context.Background(), &HookInput{ToolName: "Read", ToolInput: raw})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := got.HookSpecificOutput.PermissionDecision; d != DecisionAllow {
+		t.Errorf("PermissionDecision = %q, want %q; redact is enforced in PostToolUse", d, DecisionAllow)
+	}
+}
+
+func TestPostToolHandler_RedactRules(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewSecurityPolicy(config.SecurityConfig{
+		Rules: []config.SecurityRuleConfig{
+			{ID: "read.env-file", Severity: "redact"},
+			{ID: "custom.settings", Kind: "read", Pattern: `settings\.local\.json$`, Severity: "redact"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSecurityPolicy() error: %v", err)
+	}
+	projectDir := t.TempDir()
+	envPath := filepath.Join(projectDir, ".env")
+
+	tests := []struct {
+		name      string
+		toolName  string
+		input     map[string]any
+		response  map[string]any
+		wantBlock bool
+		wantRule  string
+	}{
+		{
+			name:      "Read of redacted file is blocked",
+			toolName:  "Read",
+			input:     map[string]any{"file_path": envPath},
+			response:  map[string]any{"type": "text", "file": map[string]any{"filePath": envPath, "content": "API_KEY=abc"}},
+			wantBlock: true,
+			wantRule:  "read.env-file",
+		},
+		{
+			name:      "Read of custom redacted file is blocked",
+			toolName:  "Read",
+			input:     map[string]any{"file_path": filepath.Join(projectDir, "settings.local.json")},
+			response:  map[string]any{"type": "text", "file": map[string]any{"content": "{}"}},
+			wantBlock: true,
+			wantRule:  "custom.settings",
+		},
+		{
+			name:      "Grep content of project root matching redacted file is blocked",
+			toolName:  "Grep",
+			input:     map[string]any{"pattern": "API_KEY", "output_mode": "content"},
+			response:  map[string]any{"mode": "content", "content": "main.go:3:// API_KEY is read from env\n.env:1:API_KEY=abc"},
+			wantBlock: true,
+			wantRule:  "read.env-file",
+		},
+		{
+			name:     "Grep content without redacted files is allowed",
+			toolName: "Grep",
+			input:    map[string]any{"pattern": "func main", "output_mode": "content"},
+			response: map[string]any{"mode": "content", "content": "main.go:5:func main() {"},
+		},
+		{
+			name:     "Grep file list naming redacted file is allowed",
+			toolName: "Grep",
+			input:    map[string]any{"pattern": "API_KEY"},
+			response: map[string]any{"mode": "files_with_matches", "filenames": []string{envPath}},
+		},
+		{
+			name:     "Glob for redacted file is allowed",
+			toolName: "Glob",
+			input:    map[string]any{"pattern": "**/.env"},
+			response: map[string]any{"filenames": []string{envPath}},
+		},
+		{
+			name:     "Read of ordinary file is allowed",
+			toolName: "Read",
+			input:    map[string]any{"file_path": filepath.Join(projectDir, "main.go")},
+			response: map[string]any{"type": "text", "file": map[string]any{"content": "package main"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rawInput, _ := json.Marshal(tt.input)
+			rawResponse, _ := json.Marshal(tt.response)
+			h := NewPostToolHandlerWithRegressionGate(nil, policy, nil)
+			got, err := h.Handle(context.Background(), &HookInput{
+				ToolName:     tt.toolName,
+				ToolInput:    rawInput,
+				ToolResponse: rawResponse,
+				CWD:          projectDir,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if blocked := got.Decision == DecisionBlock; blocked != tt.wantBlock {
+				t.Fatalf("blocked = %v, want %v (reason: %s)", blocked, tt.wantBlock, got.Reason)
+			}
+			if !tt.wantBlock {
+				return
+			}
+			if !strings.Contains(got.Reason, "[rule: "+tt.wantRule+"]") {
+				t.Errorf("reason %q should name rule %s", got.Reason, tt.wantRule)
+			}
+			if !strings.Contains(got.SystemMessage, tt.wantRule) {
+				t.Errorf("system message %q should tell the user which rule withheld content", got.SystemMessage)
+			}
+		})
+	}
+}
+
+func TestNewSecurityPolicy_RedactRequiresReadKind(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewSecurityPolicy(config.SecurityConfig{
+		Rules: []config.SecurityRuleConfig{{ID: "path.pem", Severity: "redact"}},
+	})
+	if err == nil {
+		t.Fatal("expected error for redact severity on a path rule")
+	}
+}
+
+func TestReadSubjects_NFC(t *testing.T) {
+	t.Parallel()
+
+	decomposed := norm.NFD.String("/home/dev/프로젝트/.env")
+	_, subjects := readSubjects(decomposed)
+	for _, s := range subjects {
+		if !norm.NFC.IsNormalString(s) {
+			t.Errorf("subject %q is not NFC normalized", s)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 )
 
@@ -48,7 +49,9 @@ func (r *registry) Register(handler Handler) {
 // Handlers are executed sequentially within a timeout context. If any handler
 // returns Decision "block", remaining handlers are skipped and the block result
 // is returned immediately (REQ-HOOK-003). If all handlers succeed, Decision
-// "allow" is returned (REQ-HOOK-004).
+// "allow" is returned (REQ-HOOK-004), carrying the system messages and
+// additional context of the handlers, if any, so they still reach the user
+// and Claude.
 //
 // Note: Stop and SessionEnd events should NOT include hookSpecificOutput per
 // Claude Code protocol. These events return empty JSON {} instead.
@@ -60,9 +63,11 @@ func (r *registry) Dispatch(ctx context.Context, event EventType, input *HookInp
 	}
 
 	// Apply timeout from registry configuration
-	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	timeout := EventTimeout(r.cfg, event, r.timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
+	var messages, contexts []string
 	for i, h := range handlers {
 		slog.Debug("dispatching handler",
 			"event", string(event),
@@ -77,7 +82,7 @@ func (r *registry) Dispatch(ctx context.Context, event EventType, input *HookInp
 			slog.Error("hook execution timed out",
 				"event", string(event),
 				"handler_index", i,
-				"timeout", r.timeout.String(),
+				"timeout", timeout.String(),
 			)
 			return nil, fmt.Errorf("%w: %v", ErrHookTimeout, ctx.Err())
 		}
@@ -104,9 +109,26 @@ func (r *registry) Dispatch(ctx context.Context, event EventType, input *HookInp
 			)
 			return output, nil
 		}
+
+		if output != nil && output.SystemMessage != "" {
+			messages = append(messages, output.SystemMessage)
+		}
+		if output != nil && output.HookSpecificOutput != nil && output.HookSpecificOutput.AdditionalContext != "" {
+			contexts = append(contexts, output.HookSpecificOutput.AdditionalContext)
+		}
 	}
 
-	return r.defaultOutputForEvent(event), nil
+	result := r.defaultOutputForEvent(event)
+	if len(messages) > 0 {
+		result.SystemMessage = strings.Join(messages, "\n")
+	}
+	if len(contexts) > 0 {
+		if result.HookSpecificOutput == nil {
+			result.HookSpecificOutput = &HookSpecificOutput{HookEventName: string(event)}
+		}
+		result.HookSpecificOutput.AdditionalContext = strings.Join(contexts, "\n\n")
+	}
+	return result, nil
 }
 
 // isBlockDecision checks if the output represents a blocking decision.
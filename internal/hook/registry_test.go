@@ -317,6 +317,45 @@ func verifyDecision(t *testing.T, got *HookOutput, event EventType, wantDecision
 	}
 }
 
+func TestRegistryDispatchKeepsSystemMessages(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry(&mockConfigProvider{cfg: newTestConfig()})
+	reg.Register(&mockHandler{event: EventStop, output: &HookOutput{SystemMessage: "first"}})
+	reg.Register(&mockHandler{event: EventStop, output: &HookOutput{}})
+	reg.Register(&mockHandler{event: EventStop, output: &HookOutput{SystemMessage: "second"}})
+
+	got, err := reg.Dispatch(context.Background(), EventStop, &HookInput{SessionID: "s", CWD: "/tmp"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.SystemMessage != "first\nsecond" {
+		t.Errorf("SystemMessage = %q, want %q", got.SystemMessage, "first\nsecond")
+	}
+	if got.Decision != "" {
+		t.Errorf("Decision = %q, want empty", got.Decision)
+	}
+}
+
+func TestRegistryDispatchKeepsAdditionalContext(t *testing.T) {
+	t.Parallel()
+
+	reg := NewRegistry(&mockConfigProvider{cfg: newTestConfig()})
+	reg.Register(&mockHandler{event: EventSessionStart, output: NewSessionStartOutput("restored state")})
+	reg.Register(&mockHandler{event: EventSessionStart, output: &HookOutput{}})
+
+	got, err := reg.Dispatch(context.Background(), EventSessionStart, &HookInput{SessionID: "s", CWD: "/tmp"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.HookSpecificOutput == nil || got.HookSpecificOutput.AdditionalContext != "restored state" {
+		t.Fatalf("HookSpecificOutput = %+v, want additional context kept", got.HookSpecificOutput)
+	}
+	if got.HookSpecificOutput.HookEventName != string(EventSessionStart) {
+		t.Errorf("HookEventName = %q, want %q", got.HookSpecificOutput.HookEventName, EventSessionStart)
+	}
+}
+
 func TestRegistryDispatchTimeout(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,358 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/defs"
+	lsphook "github.com/modu-ai/moai-adk/internal/lsp/hook"
+)
+
+// baselineRefreshTimeout bounds the baseline refresh on SessionStart.
+const baselineRefreshTimeout = 10 * time.Second
+
+// RegressionGate compares the diagnostics of edited files with a per-file
+// baseline kept in .moai/memory and blocks edits that introduce new errors
+// while quality.lsp_quality_gates.run.allow_regression is false. It also
+// keeps session statistics, written to .moai/reports when the session ends.
+//
+// Baselines are refreshed on SessionStart, so errors that existed before
+// the session never count as regressions. A file without a baseline gets
+// one in PreToolUse, from its diagnostics before the edit (none for a new
+// file); edits are compared with it, and the baseline follows each edit
+// that adds no errors.
+type RegressionGate struct {
+	cfg         ConfigProvider
+	diagnostics lsphook.LSPDiagnosticsCollector
+}
+
+// NewRegressionGate creates a gate configured by the
+// quality.lsp_quality_gates section of cfg that collects baseline
+// diagnostics with diagnostics.
+func NewRegressionGate(cfg ConfigProvider, diagnostics lsphook.LSPDiagnosticsCollector) *RegressionGate {
+	return &RegressionGate{cfg: cfg, diagnostics: diagnostics}
+}
+
+// enabled reports whether LSP quality gates are on, and whether
+// regressions are allowed.
+func (g *RegressionGate) enabled() (enabled, allowRegression bool) {
+	if g.cfg == nil {
+		return false, false
+	}
+	cfg := g.cfg.Get()
+	if cfg == nil || !cfg.Quality.LSPQualityGates.Enabled {
+		return false, false
+	}
+	return true, cfg.Quality.LSPQualityGates.Run.AllowRegression
+}
+
+// tracker returns the baseline tracker of projectDir.
+func (g *RegressionGate) tracker(projectDir string) lsphook.RegressionTracker {
+	return lsphook.NewRegressionTracker(filepath.Join(projectDir, defs.MoAIDir, defs.MemorySubdir))
+}
+
+// startSession starts the session statistics and refreshes the baselines
+// of all tracked files. Baselines of deleted files are removed.
+func (g *RegressionGate) startSession(ctx context.Context, input *HookInput) {
+	if ok, _ := g.enabled(); !ok {
+		return
+	}
+	projectDir := moaiProjectDir(input)
+	if projectDir == "" {
+		return
+	}
+
+	state, err := loadSessionState(projectDir, input.SessionID)
+	if err != nil {
+		slog.Warn("regression gate: cannot load session state", "error", err)
+		return
+	}
+	sessions := lsphook.NewSessionTracker()
+	_ = sessions.StartSession()
+	snapshot := sessions.Snapshot()
+	state.Diagnostics = &snapshot
+	if err := saveSessionState(projectDir, state); err != nil {
+		slog.Warn("regression gate: cannot save session state", "error", err)
+	}
+
+	if g.diagnostics == nil {
+		return
+	}
+	tracker := g.tracker(projectDir)
+	files, err := tracker.TrackedFiles()
+	if err != nil {
+		slog.Warn("regression gate: cannot read baseline", "error", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, baselineRefreshTimeout)
+	defer cancel()
+	for _, rel := range files {
+		if ctx.Err() != nil {
+			slog.Info("regression gate: baseline refresh incomplete", "files", len(files))
+			return
+		}
+		path := filepath.Join(projectDir, filepath.FromSlash(rel))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			_ = tracker.ClearBaseline(rel)
+			continue
+		}
+		diagnostics, err := g.diagnostics.GetDiagnostics(ctx, path)
+		if err != nil {
+			slog.Debug("regression gate: no baseline diagnostics", "file_path", rel, "error", err)
+			continue
+		}
+		if err := tracker.SaveBaseline(rel, diagnostics); err != nil {
+			slog.Warn("regression gate: cannot save baseline", "file_path", rel, "error", err)
+		}
+	}
+}
+
+// captureBaseline saves the diagnostics of the file at path as its
+// baseline before it is edited, unless it already has one. A file that
+// does not exist yet starts from an empty baseline.
+func (g *RegressionGate) captureBaseline(ctx context.Context, input *HookInput, path string) {
+	if ok, _ := g.enabled(); !ok || g.diagnostics == nil {
+		return
+	}
+	projectDir := moaiProjectDir(input)
+	if projectDir == "" {
+		return
+	}
+	rel := DisplayPath(projectDir, path)
+	if filepath.IsAbs(rel) {
+		return
+	}
+	tracker := g.tracker(projectDir)
+	if _, err := tracker.GetBaseline(rel); err == nil {
+		return
+	}
+
+	var diagnostics []lsphook.Diagnostic
+	if _, err := os.Stat(path); err == nil {
+		ctx, cancel := context.WithTimeout(ctx, baselineRefreshTimeout)
+		defer cancel()
+		diagnostics, err = g.diagnostics.GetDiagnostics(ctx, path)
+		if err != nil {
+			slog.Debug("regression gate: no baseline diagnostics", "file_path", rel, "error", err)
+			return
+		}
+	}
+	if err := tracker.SaveBaseline(rel, diagnostics); err != nil {
+		slog.Warn("regression gate: cannot save baseline", "file_path", rel, "error", err)
+	}
+}
+
+// check records the diagnostics of the edited file at path, with severity
+// counts counts, in the session statistics and compares them with its
+// baseline. It returns a block reason when the edit introduced new errors
+// that the configuration does not allow, and "" otherwise.
+func (g *RegressionGate) check(input *HookInput, path string, diagnostics []lsphook.Diagnostic, counts lsphook.SeverityCounts) string {
+	ok, allowRegression := g.enabled()
+	if !ok {
+		return ""
+	}
+	projectDir := moaiProjectDir(input)
+	if projectDir == "" {
+		return ""
+	}
+	rel := DisplayPath(projectDir, path)
+	if filepath.IsAbs(rel) {
+		return ""
+	}
+	g.record(projectDir, input.SessionID, rel, diagnostics)
+
+	tracker := g.tracker(projectDir)
+	report, err := tracker.CompareWithBaseline(rel, diagnostics)
+	if err != nil {
+		// PreToolUse could not capture a baseline (e.g. no diagnostics
+		// before the edit), so there is nothing to compare with yet
+		slog.Debug("regression gate: no baseline before the edit", "file_path", rel)
+	}
+	if err != nil || !report.HasRegression {
+		if err := tracker.SaveBaseline(rel, diagnostics); err != nil {
+			slog.Warn("regression gate: cannot save baseline", "file_path", rel, "error", err)
+		}
+		return ""
+	}
+
+	if allowRegression {
+		slog.Info("diagnostic regression allowed", "file_path", rel, "new_errors", report.NewErrors)
+		return ""
+	}
+	_, gate, err := lsphook.NewQualityGateEnforcer(projectDir).CheckWithConfig(counts)
+	if err != nil {
+		slog.Warn("regression gate: invalid quality.yaml, using defaults", "error", err)
+	}
+
+	var errs []lsphook.Diagnostic
+	for _, d := range diagnostics {
+		if d.Severity == lsphook.SeverityError && len(errs) < maxGateDetails {
+			errs = append(errs, d)
+		}
+	}
+	slog.Info("diagnostic regression blocked", "file_path", rel, "new_errors", report.NewErrors)
+	return fmt.Sprintf("The edit to %s introduced %d new error(s) compared to the diagnostics baseline "+
+		"(quality.lsp_quality_gates.run.allow_regression is false).\n%s\n%s\nFix the new errors before continuing.",
+		rel, report.NewErrors, lsphook.FormatDiagnostics(rel, errs), strings.TrimSpace(lsphook.FormatGateResult(counts, gate)))
+}
+
+// record adds diagnostics for rel to the session statistics.
+func (g *RegressionGate) record(projectDir, sessionID, rel string, diagnostics []lsphook.Diagnostic) {
+	state, err := loadSessionState(projectDir, sessionID)
+	if err != nil {
+		slog.Warn("regression gate: cannot load session state", "error", err)
+		return
+	}
+	sessions := sessionTrackerFor(state)
+	sessions.RecordDiagnostics(rel, diagnostics)
+	snapshot := sessions.Snapshot()
+	state.Diagnostics = &snapshot
+	if err := saveSessionState(projectDir, state); err != nil {
+		slog.Warn("regression gate: cannot save session state", "error", err)
+	}
+}
+
+// sessionTrackerFor restores the session statistics of state, starting
+// them if SessionStart did not.
+func sessionTrackerFor(state *SessionState) lsphook.SessionTracker {
+	if state.Diagnostics != nil {
+		return lsphook.RestoreSessionTracker(*state.Diagnostics)
+	}
+	sessions := lsphook.NewSessionTracker()
+	_ = sessions.StartSession()
+	return sessions
+}
+
+// diagnosticsReport is the session summary written on SessionEnd.
+type diagnosticsReport struct {
+	SessionID string                            `json:"session_id"`
+	EndedAt   time.Time                         `json:"ended_at"`
+	Stats     lsphook.SessionStats              `json:"stats"`
+	Files     map[string]lsphook.SeverityCounts `json:"files"`
+}
+
+// endSession writes the session statistics to
+// .moai/reports/diagnostics-<session-id>.json and returns its path, or ""
+// if no diagnostics were recorded.
+func (g *RegressionGate) endSession(input *HookInput) string {
+	projectDir := moaiProjectDir(input)
+	if projectDir == "" {
+		return ""
+	}
+	state, err := loadSessionState(projectDir, input.SessionID)
+	if err != nil {
+		slog.Warn("regression gate: cannot load session state", "error", err)
+		return ""
+	}
+	if state.Diagnostics == nil || state.Diagnostics.Stats.FilesAnalyzed == 0 {
+		return ""
+	}
+
+	sessions := lsphook.RestoreSessionTracker(*state.Diagnostics)
+	stats, _ := sessions.EndSession()
+	report := diagnosticsReport{
+		SessionID: input.SessionID,
+		EndedAt:   time.Now(),
+		Stats:     stats,
+		Files:     make(map[string]lsphook.SeverityCounts, len(state.Diagnostics.Files)),
+	}
+	for path, fs := range state.Diagnostics.Files {
+		if n := len(fs.DiagnosticHistory); n > 0 {
+			report.Files[path] = fs.DiagnosticHistory[n-1]
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		slog.Warn("regression gate: cannot marshal report", "error", err)
+		return ""
+	}
+	path := filepath.Join(projectDir, defs.MoAIDir, defs.ReportsSubdir, "diagnostics-"+sessionFileName(input.SessionID)+".json")
+	if err := os.MkdirAll(filepath.Dir(path), defs.DirPerm); err != nil {
+		slog.Warn("regression gate: cannot create reports directory", "error", err)
+		return ""
+	}
+	if err := os.WriteFile(path, data, defs.FilePerm); err != nil {
+		slog.Warn("regression gate: cannot write report", "error", err)
+		return ""
+	}
+	return path
+}
+
+// regressionPreToolHandler captures diagnostics baselines before edits.
+type regressionPreToolHandler struct {
+	gate *RegressionGate
+}
+
+// NewRegressionPreToolHandler creates a PreToolUse handler that saves the
+// diagnostics of a file about to be written or edited as its baseline when
+// it has none, so the first edit of a file is compared with its state
+// before the edit.
+func NewRegressionPreToolHandler(gate *RegressionGate) Handler {
+	return &regressionPreToolHandler{gate: gate}
+}
+
+// EventType returns EventPreToolUse.
+func (h *regressionPreToolHandler) EventType() EventType {
+	return EventPreToolUse
+}
+
+// Handle captures the baseline. It never denies.
+func (h *regressionPreToolHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
+	if path := EditedFile(input); path != "" {
+		h.gate.captureBaseline(ctx, input, path)
+	}
+	return NewAllowOutput(), nil
+}
+
+// regressionSessionStartHandler refreshes diagnostics baselines on SessionStart.
+type regressionSessionStartHandler struct {
+	gate *RegressionGate
+}
+
+// NewRegressionSessionStartHandler creates a SessionStart handler that
+// starts the session statistics and refreshes the baselines of gate.
+func NewRegressionSessionStartHandler(gate *RegressionGate) Handler {
+	return &regressionSessionStartHandler{gate: gate}
+}
+
+// EventType returns EventSessionStart.
+func (h *regressionSessionStartHandler) EventType() EventType {
+	return EventSessionStart
+}
+
+// Handle refreshes the baselines. It never blocks.
+func (h *regressionSessionStartHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
+	h.gate.startSession(ctx, input)
+	return &HookOutput{}, nil
+}
+
+// regressionSessionEndHandler writes the diagnostics summary on SessionEnd.
+type regressionSessionEndHandler struct {
+	gate *RegressionGate
+}
+
+// NewRegressionSessionEndHandler creates a SessionEnd handler that writes
+// the session statistics of gate to .moai/reports.
+func NewRegressionSessionEndHandler(gate *RegressionGate) Handler {
+	return &regressionSessionEndHandler{gate: gate}
+}
+
+// EventType returns EventSessionEnd.
+func (h *regressionSessionEndHandler) EventType() EventType {
+	return EventSessionEnd
+}
+
+// Handle writes the report. SessionEnd output is always empty.
+func (h *regressionSessionEndHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
+	if path := h.gate.endSession(input); path != "" {
+		slog.Info("diagnostics report written", "path", path)
+	}
+	return &HookOutput{}, nil
+}
@@ -0,0 +1,277 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	lsphook "github.com/modu-ai/moai-adk/internal/lsp/hook"
+)
+
+// fakeDiagnostics returns the diagnostics set for each absolute path.
+type fakeDiagnostics struct {
+	byPath map[string][]lsphook.Diagnostic
+}
+
+func (f *fakeDiagnostics) GetDiagnostics(_ context.Context, filePath string) ([]lsphook.Diagnostic, error) {
+	return f.byPath[filePath], nil
+}
+
+func (f *fakeDiagnostics) GetSeverityCounts(diagnostics []lsphook.Diagnostic) lsphook.SeverityCounts {
+	var counts lsphook.SeverityCounts
+	for _, d := range diagnostics {
+		switch d.Severity {
+		case lsphook.SeverityError:
+			counts.Errors++
+		case lsphook.SeverityWarning:
+			counts.Warnings++
+		}
+	}
+	return counts
+}
+
+func regressionErrors(n int) []lsphook.Diagnostic {
+	diags := make([]lsphook.Diagnostic, n)
+	for i := range diags {
+		diags[i] = lsphook.Diagnostic{Severity: lsphook.SeverityError, Message: "undefined: x", Source: "fake"}
+	}
+	return diags
+}
+
+// newTestRegressionGate returns a gate for a temporary MoAI project with
+// the file calc.go, and its fake diagnostics.
+func newTestRegressionGate(t *testing.T, allowRegression bool) (*RegressionGate, *fakeDiagnostics, string) {
+	t.Helper()
+	projectDir := t.TempDir()
+	writeGateFile(t, projectDir, ".moai/config/.keep", "")
+	writeGateFile(t, projectDir, "calc.go", "package calc\n")
+
+	cfg := config.NewDefaultConfig()
+	cfg.Quality.LSPQualityGates.Enabled = true
+	cfg.Quality.LSPQualityGates.Run.AllowRegression = allowRegression
+
+	fake := &fakeDiagnostics{byPath: map[string][]lsphook.Diagnostic{}}
+	return NewRegressionGate(&mockConfigProvider{cfg: cfg}, fake), fake, projectDir
+}
+
+func regressionEdit(t *testing.T, h Handler, projectDir, file string) *HookOutput {
+	t.Helper()
+	input := editInput(projectDir, "Edit", file)
+	input.SessionID = "sess-regress"
+	out, err := h.Handle(context.Background(), input)
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	return out
+}
+
+func TestRegressionGate_BlocksNewErrors(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestRegressionGate(t, false)
+	h := NewPostToolHandlerWithRegressionGate(fake, nil, gate)
+	path := filepath.Join(projectDir, "calc.go")
+
+	if out := regressionEdit(t, h, projectDir, "calc.go"); out.Decision == DecisionBlock {
+		t.Fatalf("first edit blocked: %s", out.Reason)
+	}
+
+	fake.byPath[path] = regressionErrors(2)
+	out := regressionEdit(t, h, projectDir, "calc.go")
+	if out.Decision != DecisionBlock {
+		t.Fatal("edit adding errors was not blocked")
+	}
+	for _, want := range []string{"calc.go introduced 2 new error(s)", "allow_regression", "undefined: x", "Errors: 2 (max: 0)"} {
+		if !strings.Contains(out.Reason, want) {
+			t.Errorf("reason missing %q:\n%s", want, out.Reason)
+		}
+	}
+
+	// The baseline keeps the clean state until the errors are fixed
+	if out := regressionEdit(t, h, projectDir, "calc.go"); out.Decision != DecisionBlock {
+		t.Error("unfixed errors were not blocked again")
+	}
+	fake.byPath[path] = nil
+	if out := regressionEdit(t, h, projectDir, "calc.go"); out.Decision == DecisionBlock {
+		t.Errorf("fixed file blocked: %s", out.Reason)
+	}
+}
+
+func TestRegressionGate_FirstEditComparedWithPreEditState(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestRegressionGate(t, false)
+	pre := NewRegressionPreToolHandler(gate)
+	post := NewPostToolHandlerWithRegressionGate(fake, nil, gate)
+	path := filepath.Join(projectDir, "calc.go")
+
+	// PreToolUse captures the clean file; the edit then adds errors
+	if out := regressionEdit(t, pre, projectDir, "calc.go"); isDenied(out) {
+		t.Fatalf("PreToolUse denied the edit: %+v", out.HookSpecificOutput)
+	}
+	fake.byPath[path] = regressionErrors(1)
+	if out := regressionEdit(t, post, projectDir, "calc.go"); out.Decision != DecisionBlock {
+		t.Error("first edit adding errors was not blocked")
+	}
+
+	// Later PreToolUse calls keep the clean baseline
+	regressionEdit(t, pre, projectDir, "calc.go")
+	baseline, err := gate.tracker(projectDir).GetBaseline("calc.go")
+	if err != nil || len(baseline.Diagnostics) != 0 {
+		t.Errorf("baseline = %+v, %v; want the clean pre-edit state", baseline, err)
+	}
+
+	// A new file starts from an empty baseline
+	regressionEdit(t, pre, projectDir, "new.go")
+	fake.byPath[filepath.Join(projectDir, "new.go")] = regressionErrors(1)
+	if out := regressionEdit(t, post, projectDir, "new.go"); out.Decision != DecisionBlock {
+		t.Error("new file with errors was not blocked")
+	}
+}
+
+func TestRegressionGate_BlocksWithinGateThresholds(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestRegressionGate(t, false)
+	writeGateFile(t, projectDir, ".moai/config/sections/quality.yaml", `constitution:
+  lsp_quality_gates:
+    enabled: true
+    run:
+      max_errors: 10
+      allow_regression: false
+`)
+	h := NewPostToolHandlerWithRegressionGate(fake, nil, gate)
+
+	regressionEdit(t, h, projectDir, "calc.go")
+	fake.byPath[filepath.Join(projectDir, "calc.go")] = regressionErrors(1)
+	if out := regressionEdit(t, h, projectDir, "calc.go"); out.Decision != DecisionBlock {
+		t.Error("regression within max_errors was not blocked")
+	}
+}
+
+func TestRegressionGate_AllowRegression(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestRegressionGate(t, true)
+	h := NewPostToolHandlerWithRegressionGate(fake, nil, gate)
+
+	regressionEdit(t, h, projectDir, "calc.go")
+	fake.byPath[filepath.Join(projectDir, "calc.go")] = regressionErrors(1)
+	if out := regressionEdit(t, h, projectDir, "calc.go"); out.Decision == DecisionBlock {
+		t.Errorf("allowed regression blocked: %s", out.Reason)
+	}
+}
+
+func TestRegressionGate_Disabled(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestRegressionGate(t, false)
+	gate.cfg.Get().Quality.LSPQualityGates.Enabled = false
+	h := NewPostToolHandlerWithRegressionGate(fake, nil, gate)
+
+	regressionEdit(t, h, projectDir, "calc.go")
+	fake.byPath[filepath.Join(projectDir, "calc.go")] = regressionErrors(1)
+	if out := regressionEdit(t, h, projectDir, "calc.go"); out.Decision == DecisionBlock {
+		t.Errorf("disabled gate blocked: %s", out.Reason)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".moai", "memory", lsphook.BaselineFileName)); !os.IsNotExist(err) {
+		t.Errorf("disabled gate wrote a baseline: %v", err)
+	}
+}
+
+func TestRegressionGate_SessionStartRefreshesBaselines(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestRegressionGate(t, false)
+	writeGateFile(t, projectDir, "old.go", "package calc\n")
+	tracker := gate.tracker(projectDir)
+	for _, rel := range []string{"calc.go", "old.go"} {
+		if err := tracker.SaveBaseline(rel, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Remove(filepath.Join(projectDir, "old.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	// calc.go gained errors outside the session
+	fake.byPath[filepath.Join(projectDir, "calc.go")] = regressionErrors(1)
+	start := NewRegressionSessionStartHandler(gate)
+	input := &HookInput{SessionID: "sess-regress", ProjectDir: projectDir, CWD: projectDir}
+	if _, err := start.Handle(context.Background(), input); err != nil {
+		t.Fatalf("SessionStart Handle() error = %v", err)
+	}
+
+	files, err := gate.tracker(projectDir).TrackedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != "calc.go" {
+		t.Errorf("TrackedFiles = %v, want [calc.go]", files)
+	}
+
+	h := NewPostToolHandlerWithRegressionGate(fake, nil, gate)
+	if out := regressionEdit(t, h, projectDir, "calc.go"); out.Decision == DecisionBlock {
+		t.Errorf("pre-existing error blocked: %s", out.Reason)
+	}
+}
+
+func TestRegressionGate_SessionEndWritesReport(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestRegressionGate(t, true)
+	writeGateFile(t, projectDir, "util.go", "package calc\n")
+	input := &HookInput{SessionID: "sess-regress", ProjectDir: projectDir, CWD: projectDir}
+	if _, err := NewRegressionSessionStartHandler(gate).Handle(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+
+	h := NewPostToolHandlerWithRegressionGate(fake, nil, gate)
+	fake.byPath[filepath.Join(projectDir, "calc.go")] = regressionErrors(1)
+	regressionEdit(t, h, projectDir, "calc.go")
+	regressionEdit(t, h, projectDir, "util.go")
+
+	end := NewRegressionSessionEndHandler(gate)
+	out, err := end.Handle(context.Background(), input)
+	if err != nil {
+		t.Fatalf("SessionEnd Handle() error = %v", err)
+	}
+	if out.HookSpecificOutput != nil {
+		t.Error("SessionEnd output must not use hookSpecificOutput")
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectDir, ".moai", "reports", "diagnostics-sess-regress.json"))
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var report diagnosticsReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.SessionID != "sess-regress" || report.Stats.FilesAnalyzed != 2 || report.Stats.TotalErrors != 1 {
+		t.Errorf("report = %+v, want 2 files and 1 error", report)
+	}
+	if report.Stats.StartedAt.IsZero() {
+		t.Error("report StartedAt is zero, want the SessionStart time")
+	}
+	if report.Files["calc.go"].Errors != 1 || report.Files["util.go"].Errors != 0 {
+		t.Errorf("report files = %+v", report.Files)
+	}
+}
+
+func TestRegressionGate_SessionEndWithoutDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	gate, _, projectDir := newTestRegressionGate(t, false)
+	input := &HookInput{SessionID: "sess-empty", ProjectDir: projectDir, CWD: projectDir}
+	if _, err := NewRegressionSessionEndHandler(gate).Handle(context.Background(), input); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".moai", "reports")); !os.IsNotExist(err) {
+		t.Errorf("report written for a session without diagnostics: %v", err)
+	}
+}
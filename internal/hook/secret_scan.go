@@ -0,0 +1,89 @@
+package hook
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// contentPayload is a piece of text a file tool is about to write,
+// labelled with the tool input field it came from.
+type contentPayload struct {
+	field string
+	text  string
+}
+
+// contentPayloads extracts the text each content-bearing tool writes:
+// Write content, Edit new_string, every MultiEdit edits[].new_string and
+// NotebookEdit new_source. Old strings are not scanned since they are
+// already on disk.
+func contentPayloads(toolName string, parsed map[string]any) []contentPayload {
+	var fields []string
+	switch toolName {
+	case "Write":
+		fields = []string{"content"}
+	case "Edit":
+		fields = []string{"new_string"}
+	case "NotebookEdit":
+		fields = []string{"new_source"}
+	case "MultiEdit":
+		edits, _ := parsed["edits"].([]any)
+		payloads := make([]contentPayload, 0, len(edits))
+		for i, e := range edits {
+			edit, ok := e.(map[string]any)
+			if !ok {
+				continue
+			}
+			if text, ok := edit["new_string"].(string); ok && text != "" {
+				payloads = append(payloads, contentPayload{
+					field: fmt.Sprintf("edits[%d].new_string", i),
+					text:  text,
+				})
+			}
+		}
+		return payloads
+	}
+
+	var payloads []contentPayload
+	for _, f := range fields {
+		if text, ok := parsed[f].(string); ok && text != "" {
+			payloads = append(payloads, contentPayload{field: f, text: text})
+		}
+	}
+	return payloads
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	n := utf8.RuneCountInString(s)
+	if n == 0 {
+		return 0
+	}
+	counts := make(map[rune]int, n)
+	for _, r := range s {
+		counts[r]++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// redactSecret returns a preview of a matched secret that is safe to show
+// in a decision reason: a short prefix (enough to recognize the token type),
+// masked remainder and the length. Short matches are fully masked.
+func redactSecret(secret string) string {
+	runes := []rune(strings.TrimSpace(secret))
+	n := len(runes)
+	keep := 0
+	switch {
+	case n >= 16:
+		keep = 4
+	case n >= 8:
+		keep = 2
+	}
+	return fmt.Sprintf("%s%s (%d chars)", string(runes[:keep]), strings.Repeat("*", min(n-keep, 8)), n)
+}
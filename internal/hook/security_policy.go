@@ -0,0 +1,622 @@
+package hook
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+)
+
+// RuleKind identifies what a SecurityRule is matched against.
+type RuleKind string
+
+const (
+	// RuleKindPath rules match file paths of Write/Edit tool inputs.
+	RuleKindPath RuleKind = "path"
+
+	// RuleKindBash rules match Bash tool commands.
+	RuleKindBash RuleKind = "bash"
+
+	// RuleKindContent rules match content written by file tools.
+	RuleKindContent RuleKind = "content"
+
+	// RuleKindRead rules match file paths of Read/Grep/Glob tool inputs.
+	RuleKindRead RuleKind = "read"
+)
+
+// RuleSeverity is the action taken when a SecurityRule matches.
+type RuleSeverity string
+
+const (
+	// SeverityDeny blocks the tool call.
+	SeverityDeny RuleSeverity = "deny"
+
+	// SeverityAsk requires user confirmation.
+	SeverityAsk RuleSeverity = "ask"
+
+	// SeverityLog records the match but allows the tool call.
+	SeverityLog RuleSeverity = "log"
+
+	// SeverityRedact allows a read but blocks a Read or Grep content result
+	// that contains the file in PostToolUse, telling the user what was
+	// withheld. Only valid for read rules.
+	SeverityRedact RuleSeverity = "redact"
+)
+
+// SecurityRule is a single identified pattern in a SecurityPolicy.
+type SecurityRule struct {
+	// ID uniquely identifies the rule, e.g. "bash.terraform-destroy".
+	ID string
+
+	// Pattern is the case-insensitive regex the rule matches.
+	Pattern *regexp.Regexp
+
+	// Severity is the action taken on match.
+	Severity RuleSeverity
+
+	// Message overrides the default decision reason when non-empty.
+	Message string
+
+	// MinEntropy, when positive, only counts matches whose Shannon entropy
+	// in bits per character is at least this value. If Pattern has a
+	// capture group, the entropy of the first group is measured instead of
+	// the whole match.
+	MinEntropy float64
+}
+
+// find returns the first match of the rule in s, or "" if there is none.
+// Matches below MinEntropy are ignored.
+func (r *SecurityRule) find(s string) string {
+	if r.Pattern == nil || s == "" {
+		return ""
+	}
+	if r.MinEntropy <= 0 {
+		return r.Pattern.FindString(s)
+	}
+	for _, m := range r.Pattern.FindAllStringSubmatch(s, -1) {
+		candidate := m[0]
+		if len(m) > 1 && m[1] != "" {
+			candidate = m[1]
+		}
+		if shannonEntropy(candidate) >= r.MinEntropy {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// SecurityException exempts matching tool calls from selected rules.
+// An exception applies when every non-empty list has at least one match.
+type SecurityException struct {
+	// RuleIDs lists the rules this exception covers; empty covers all rules.
+	RuleIDs []string
+
+	// Paths match the project-relative file path, or the working directory
+	// (with a trailing slash) for Bash commands.
+	Paths []*regexp.Regexp
+
+	// Commands match the Bash command.
+	Commands []*regexp.Regexp
+
+	// Reason documents why the exception exists.
+	Reason string
+}
+
+// SecurityPolicy defines tool access control rules for PreToolUse events.
+type SecurityPolicy struct {
+	// BlockedTools is a list of tool names that are always blocked.
+	BlockedTools []string
+
+	// PathRules match file paths. Deny rules protect files that should never
+	// be modified; ask rules protect critical configuration files.
+	PathRules []SecurityRule
+
+	// BashRules match Bash commands.
+	BashRules []SecurityRule
+
+	// ContentRules match sensitive data in written content.
+	ContentRules []SecurityRule
+
+	// ReadRules match file paths read by Read, Grep and Glob. They protect
+	// credentials from being loaded into the conversation.
+	ReadRules []SecurityRule
+
+	// Exceptions exempt specific paths or commands from rules.
+	Exceptions []SecurityException
+}
+
+// ruleSpec is an uncompiled built-in rule definition.
+type ruleSpec struct {
+	id      string
+	pattern string
+}
+
+// compilePatterns compiles a list of pattern strings into regexp objects.
+func compilePatterns(patterns []string) []*regexp.Regexp {
+	result := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile("(?i)" + p) // Case-insensitive
+		if err != nil {
+			slog.Warn("failed to compile security pattern", "pattern", p, "error", err)
+			continue
+		}
+		result = append(result, re)
+	}
+	return result
+}
+
+// compileRules compiles built-in rule specs with the given severity.
+func compileRules(specs []ruleSpec, severity RuleSeverity) []SecurityRule {
+	result := make([]SecurityRule, 0, len(specs))
+	for _, spec := range specs {
+		re, err := regexp.Compile("(?i)" + spec.pattern) // Case-insensitive
+		if err != nil {
+			slog.Warn("failed to compile security pattern", "rule", spec.id, "pattern", spec.pattern, "error", err)
+			continue
+		}
+		result = append(result, SecurityRule{ID: spec.id, Pattern: re, Severity: severity})
+	}
+	return result
+}
+
+// DefaultSecurityPolicy returns a SecurityPolicy with comprehensive security patterns
+// ported from the Python pre_tool__security_guard.py implementation.
+func DefaultSecurityPolicy() *SecurityPolicy {
+	// Files that should NEVER be modified
+	denyPaths := []ruleSpec{
+		// Secrets and credentials
+		{"path.secrets-file", `secrets?\.(json|ya?ml|toml)$`},
+		{"path.credentials-file", `credentials?\.(json|ya?ml|toml)$`},
+		{"path.dot-secrets-dir", `\.secrets/.*`},
+		{"path.secrets-dir", `secrets/.*`},
+		// SSH and certificates
+		{"path.ssh-dir", `\.ssh/.*`},
+		{"path.id-rsa", `id_rsa.*`},
+		{"path.id-ed25519", `id_ed25519.*`},
+		{"path.pem", `\.pem$`},
+		{"path.key", `\.key$`},
+		{"path.crt", `\.crt$`},
+		// Git internals
+		{"path.git-dir", `\.git/.*`},
+		// Cloud credentials
+		{"path.aws-dir", `\.aws/.*`},
+		{"path.gcloud-dir", `\.gcloud/.*`},
+		{"path.azure-dir", `\.azure/.*`},
+		{"path.kube-dir", `\.kube/.*`},
+		// Token files
+		{"path.token-file", `\.token$`},
+		{"path.tokens-dir", `\.tokens/.*`},
+		{"path.auth-json", `auth\.json$`},
+	}
+
+	// Files that require user confirmation
+	askPaths := []ruleSpec{
+		// Lock files
+		{"path.package-lock", `package-lock\.json$`},
+		{"path.yarn-lock", `yarn\.lock$`},
+		{"path.pnpm-lock", `pnpm-lock\.ya?ml$`},
+		{"path.gemfile-lock", `Gemfile\.lock$`},
+		{"path.cargo-lock", `Cargo\.lock$`},
+		{"path.poetry-lock", `poetry\.lock$`},
+		{"path.composer-lock", `composer\.lock$`},
+		{"path.pipfile-lock", `Pipfile\.lock$`},
+		{"path.uv-lock", `uv\.lock$`},
+		// Critical configs
+		{"path.tsconfig", `tsconfig\.json$`},
+		{"path.pyproject", `pyproject\.toml$`},
+		{"path.cargo-toml", `Cargo\.toml$`},
+		{"path.package-json", `package\.json$`},
+		{"path.docker-compose", `docker-compose\.ya?ml$`},
+		{"path.dockerfile", `Dockerfile$`},
+		{"path.dockerignore", `\.dockerignore$`},
+		// CI/CD configs
+		{"path.github-workflows", `\.github/workflows/.*\.ya?ml$`},
+		{"path.gitlab-ci", `\.gitlab-ci\.ya?ml$`},
+		{"path.circleci", `\.circleci/.*`},
+		{"path.jenkinsfile", `Jenkinsfile$`},
+		// Infrastructure
+		{"path.terraform", `terraform/.*\.tf$`},
+		{"path.terraform-state", `\.terraform/.*`},
+		{"path.kubernetes", `kubernetes/.*\.ya?ml$`},
+		{"path.k8s", `k8s/.*\.ya?ml$`},
+	}
+
+	// Dangerous Bash commands that should NEVER be executed
+	denyBash := []ruleSpec{
+		// Database deletion commands - Supabase
+		{"bash.supabase-db-reset", `supabase\s+db\s+reset`},
+		{"bash.supabase-project-delete", `supabase\s+projects?\s+delete`},
+		{"bash.supabase-function-delete", `supabase\s+functions?\s+delete`},
+		// Database deletion commands - Neon
+		{"bash.neon-database-delete", `neon\s+database\s+delete`},
+		{"bash.neon-project-delete", `neon\s+projects?\s+delete`},
+		{"bash.neon-branch-delete", `neon\s+branch\s+delete`},
+		// Database deletion commands - PlanetScale
+		{"bash.pscale-database-delete", `pscale\s+database\s+delete`},
+		{"bash.pscale-branch-delete", `pscale\s+branch\s+delete`},
+		// Database deletion commands - Railway
+		{"bash.railway-delete", `railway\s+delete`},
+		{"bash.railway-environment-delete", `railway\s+environment\s+delete`},
+		// Database deletion commands - Vercel
+		{"bash.vercel-env-rm", `vercel\s+env\s+rm`},
+		{"bash.vercel-project-rm", `vercel\s+projects?\s+rm`},
+		// SQL dangerous commands
+		{"bash.sql-drop-database", `DROP\s+DATABASE`},
+		{"bash.sql-drop-schema", `DROP\s+SCHEMA`},
+		{"bash.sql-truncate-table", `TRUNCATE\s+TABLE`},
+		// Unix dangerous file operations
+		{"bash.rm-rf-root", `rm\s+-rf\s+/`},
+		{"bash.rm-rf-home", `rm\s+-rf\s+~`},
+		{"bash.rm-rf-glob", `rm\s+-rf\s+\*`},
+		{"bash.rm-rf-dotfiles", `rm\s+-rf\s+\.\*`},
+		{"bash.rm-rf-git", `rm\s+-rf\s+\.git\b`},
+		{"bash.rm-rf-node-modules", `rm\s+-rf\s+node_modules\s*$`},
+		// Windows dangerous file operations (CMD)
+		{"bash.win-rd-drive", `rd\s+/s\s+/q\s+[A-Za-z]:\\`},
+		{"bash.win-rmdir-drive", `rmdir\s+/s\s+/q\s+[A-Za-z]:\\`},
+		{"bash.win-del-drive", `del\s+/f\s+/q\s+[A-Za-z]:\\`},
+		{"bash.win-rd-unc", `rd\s+/s\s+/q\s+\\\\`},
+		{"bash.win-rd-git", `rd\s+/s\s+/q\s+\.git\b`},
+		{"bash.win-del-all", `del\s+/s\s+/q\s+\*\.\*`},
+		{"bash.win-format", `format\s+[A-Za-z]:`},
+		// Windows dangerous file operations (PowerShell)
+		{"bash.ps-remove-drive", `Remove-Item\s+.*-Recurse\s+.*-Force\s+[A-Za-z]:\\`},
+		{"bash.ps-remove-home", `Remove-Item\s+.*-Recurse\s+.*-Force\s+~`},
+		{"bash.ps-remove-env", `Remove-Item\s+.*-Recurse\s+.*-Force\s+\$env:`},
+		{"bash.ps-remove-git", `Remove-Item\s+.*-Recurse\s+.*-Force\s+\.git\b`},
+		{"bash.ps-clear-content", `Clear-Content\s+.*-Force`},
+		// Git dangerous commands
+		{"bash.git-force-push-main", `git\s+push\s+.*--force\s+origin\s+(main|master)`},
+		{"bash.git-delete-main", `git\s+branch\s+-D\s+(main|master)`},
+		// Cloud infrastructure deletion
+		{"bash.terraform-destroy", `terraform\s+destroy`},
+		{"bash.pulumi-destroy", `pulumi\s+destroy`},
+		{"bash.aws-delete", `aws\s+.*\s+delete-`},
+		{"bash.gcloud-delete", `gcloud\s+.*\s+delete\b`},
+		// Azure CLI dangerous commands
+		{"bash.az-group-delete", `az\s+group\s+delete`},
+		{"bash.az-storage-delete", `az\s+storage\s+account\s+delete`},
+		{"bash.az-sql-delete", `az\s+sql\s+server\s+delete`},
+		// Docker dangerous commands
+		{"bash.docker-system-prune", `docker\s+system\s+prune\s+(-a|--all)`},
+		{"bash.docker-image-prune", `docker\s+image\s+prune\s+(-a|--all)`},
+		{"bash.docker-container-prune", `docker\s+container\s+prune`},
+		{"bash.docker-volume-prune", `docker\s+volume\s+prune`},
+		{"bash.docker-network-prune", `docker\s+network\s+prune`},
+		{"bash.docker-builder-prune", `docker\s+builder\s+prune\s+(-a|--all)`},
+		// Classic dangerous patterns
+		{"bash.fork-bomb", `:\(\)\{\s*:\|:&\s*\};:`},
+		{"bash.mkfs", `mkfs\.`},
+		{"bash.overwrite-disk", `>\s*/dev/sda`},
+		{"bash.dd-zero-disk", `dd\s+if=/dev/zero\s+of=/dev/sda`},
+	}
+
+	// Bash commands that require user confirmation
+	askBash := []ruleSpec{
+		// Database reset/migration
+		{"bash.prisma-migrate-reset", `prisma\s+migrate\s+reset`},
+		{"bash.prisma-db-push-force", `prisma\s+db\s+push\s+--force`},
+		{"bash.drizzle-push", `drizzle-kit\s+push`},
+		// Git force operations (non-main branches)
+		{"bash.git-force-push", `git\s+push\s+.*--force`},
+		{"bash.git-reset-hard", `git\s+reset\s+--hard`},
+		{"bash.git-clean", `git\s+clean\s+-fd`},
+		// Package manager cache clear
+		{"bash.npm-cache-clean", `npm\s+cache\s+clean`},
+		{"bash.yarn-cache-clean", `yarn\s+cache\s+clean`},
+		{"bash.pnpm-store-prune", `pnpm\s+store\s+prune`},
+	}
+
+	// Content patterns that indicate sensitive data
+	sensitiveContent := []ruleSpec{
+		{"content.private-key", `-----BEGIN\s+(RSA\s+)?PRIVATE\s+KEY-----`},
+		{"content.certificate", `-----BEGIN\s+CERTIFICATE-----`},
+		{"content.openai-key", `sk-[a-zA-Z0-9]{32,}`},
+		{"content.github-token", `ghp_[a-zA-Z0-9]{36}`},
+		{"content.github-oauth-token", `gho_[a-zA-Z0-9]{36}`},
+		{"content.gitlab-token", `glpat-[a-zA-Z0-9\-]{20}`},
+		{"content.slack-token", `xox[baprs]-[a-zA-Z0-9\-]+`},
+		{"content.aws-access-key", `AKIA[0-9A-Z]{16}`},
+		{"content.google-oauth-token", `ya29\.[a-zA-Z0-9_\-]+`},
+	}
+
+	// Files whose contents must never be read into the conversation
+	denyReads := []ruleSpec{
+		{"read.env-file", `(^|/)\.env(\.[\w.-]+)?$`},
+		{"read.secrets-file", `secrets?\.(json|ya?ml|toml)$`},
+		{"read.credentials-file", `credentials?(\.(json|ya?ml|toml))?$`},
+		{"read.dot-secrets-dir", `\.secrets/.*`},
+		{"read.ssh-dir", `\.ssh/.*`},
+		{"read.ssh-private-key", `id_(rsa|dsa|ecdsa|ed25519)$`},
+		{"read.pem", `\.pem$`},
+		{"read.key", `\.key$`},
+		{"read.aws-dir", `\.aws/.*`},
+		{"read.gcloud-dir", `\.(config/)?gcloud/.*`},
+		{"read.azure-dir", `\.azure/.*`},
+		{"read.kube-config", `\.kube/config$`},
+		{"read.docker-config", `\.docker/config\.json$`},
+		{"read.netrc", `\.netrc$`},
+		{"read.pgpass", `\.pgpass$`},
+		{"read.token-file", `\.token$`},
+		{"read.tokens-dir", `\.tokens/.*`},
+	}
+
+	// Files that may embed registry tokens
+	askReads := []ruleSpec{
+		{"read.npmrc", `\.npmrc$`},
+		{"read.pypirc", `\.pypirc$`},
+	}
+
+	// Quoted or assigned random-looking strings catch credentials that have
+	// no recognizable prefix. Hex digests and UUIDs stay below the threshold.
+	highEntropy := SecurityRule{
+		ID:         "content.high-entropy-string",
+		Pattern:    regexp.MustCompile(`(?:["'\x60]|[=:]\s*)([A-Za-z0-9+/_\-]{20,}={0,2})`),
+		Severity:   SeverityAsk,
+		MinEntropy: 4.5,
+	}
+
+	return &SecurityPolicy{
+		BlockedTools: []string{},
+		PathRules:    append(compileRules(denyPaths, SeverityDeny), compileRules(askPaths, SeverityAsk)...),
+		BashRules:    append(compileRules(denyBash, SeverityDeny), compileRules(askBash, SeverityAsk)...),
+		ContentRules: append(compileRules(sensitiveContent, SeverityDeny), highEntropy),
+		ReadRules:    append(compileRules(denyReads, SeverityDeny), compileRules(askReads, SeverityAsk)...),
+		Exceptions: []SecurityException{
+			{
+				// Lockfiles are full of integrity hashes.
+				RuleIDs: []string{highEntropy.ID},
+				Paths:   compilePatterns([]string{`(^|/)(go\.sum|package-lock\.json|yarn\.lock|pnpm-lock\.yaml|bun\.lock|Cargo\.lock|poetry\.lock|uv\.lock|Gemfile\.lock|composer\.lock)$`}),
+				Reason:  "lockfile checksums",
+			},
+			{
+				RuleIDs: []string{"read.env-file"},
+				Paths:   compilePatterns([]string{`\.env\.(example|sample|template|dist)$`}),
+				Reason:  "env templates hold placeholders, not secrets",
+			},
+		},
+	}
+}
+
+// NewSecurityPolicy builds a SecurityPolicy from the security.yaml config
+// section. The built-in rules are the starting point unless DisableDefaults
+// is set. Rules whose ID matches a built-in rule override only the fields
+// that are set (or remove it when Disabled); new IDs add custom rules.
+// Returns an error if a rule cannot be resolved or a pattern is invalid.
+func NewSecurityPolicy(sc config.SecurityConfig) (*SecurityPolicy, error) {
+	policy := &SecurityPolicy{BlockedTools: []string{}}
+	if !sc.DisableDefaults {
+		policy = DefaultSecurityPolicy()
+	}
+
+	policy.BlockedTools = append(policy.BlockedTools, sc.BlockedTools...)
+
+	for _, rc := range sc.Rules {
+		if err := policy.applyRuleConfig(rc); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ac := range sc.Allowlist {
+		exc := SecurityException{
+			RuleIDs:  ac.Rules,
+			Paths:    compilePatterns(ac.Paths),
+			Commands: compilePatterns(ac.Commands),
+			Reason:   ac.Reason,
+		}
+		if len(exc.Paths) != len(ac.Paths) || len(exc.Commands) != len(ac.Commands) {
+			return nil, fmt.Errorf("security allowlist %v: invalid pattern", ac.Rules)
+		}
+		policy.Exceptions = append(policy.Exceptions, exc)
+	}
+
+	return policy, nil
+}
+
+// applyRuleConfig merges a single rule entry into the policy.
+func (p *SecurityPolicy) applyRuleConfig(rc config.SecurityRuleConfig) error {
+	kind, idx := p.findRule(rc.ID)
+
+	if rc.Kind != "" && kind != "" && RuleKind(rc.Kind) != kind {
+		return fmt.Errorf("security rule %q: kind %q does not match built-in kind %q", rc.ID, rc.Kind, kind)
+	}
+
+	if rc.Disabled {
+		if kind == "" {
+			slog.Debug("security rule to disable not found", "rule", rc.ID)
+			return nil
+		}
+		rules := p.rulesFor(kind)
+		*rules = slices.Delete(*rules, idx, idx+1)
+		return nil
+	}
+
+	existing := kind != ""
+	var rule SecurityRule
+	if existing {
+		rule = (*p.rulesFor(kind))[idx]
+	} else {
+		if rc.Kind == "" || rc.Pattern == "" {
+			return fmt.Errorf("security rule %q: kind and pattern are required for new rules", rc.ID)
+		}
+		kind = RuleKind(rc.Kind)
+		if p.rulesFor(kind) == nil {
+			return fmt.Errorf("security rule %q: unknown kind %q", rc.ID, rc.Kind)
+		}
+		rule = SecurityRule{ID: rc.ID, Severity: SeverityDeny}
+	}
+
+	if rc.Pattern != "" {
+		re, err := regexp.Compile("(?i)" + rc.Pattern)
+		if err != nil {
+			return fmt.Errorf("security rule %q: compile pattern: %w", rc.ID, err)
+		}
+		rule.Pattern = re
+	}
+	if rc.Severity != "" {
+		rule.Severity = RuleSeverity(rc.Severity)
+	}
+	if rc.Message != "" {
+		rule.Message = rc.Message
+	}
+	if rc.MinEntropy > 0 {
+		rule.MinEntropy = rc.MinEntropy
+	}
+
+	if rule.Severity == SeverityRedact && kind != RuleKindRead {
+		return fmt.Errorf("security rule %q: severity %q is only supported for read rules", rc.ID, rule.Severity)
+	}
+
+	rules := p.rulesFor(kind)
+	if existing {
+		(*rules)[idx] = rule
+	} else {
+		*rules = append(*rules, rule)
+	}
+	return nil
+}
+
+// findRule returns the kind and index of the rule with the given ID,
+// or ("", -1) if no such rule exists.
+func (p *SecurityPolicy) findRule(id string) (RuleKind, int) {
+	for _, kind := range []RuleKind{RuleKindPath, RuleKindBash, RuleKindContent, RuleKindRead} {
+		for i, r := range *p.rulesFor(kind) {
+			if r.ID == id {
+				return kind, i
+			}
+		}
+	}
+	return "", -1
+}
+
+// rulesFor returns a pointer to the rule list for the given kind,
+// or nil if the kind is unknown.
+func (p *SecurityPolicy) rulesFor(kind RuleKind) *[]SecurityRule {
+	switch kind {
+	case RuleKindPath:
+		return &p.PathRules
+	case RuleKindBash:
+		return &p.BashRules
+	case RuleKindContent:
+		return &p.ContentRules
+	case RuleKindRead:
+		return &p.ReadRules
+	default:
+		return nil
+	}
+}
+
+// matchTarget describes the tool call a rule is evaluated against,
+// for allowlist exception matching.
+type matchTarget struct {
+	path    string // project-relative path (or working directory for Bash)
+	command string // Bash command, empty for file tools
+}
+
+// evaluate matches subjects against rules and returns the decisive rule:
+// the first matching deny rule, otherwise the first matching ask rule.
+// Matching log rules are recorded but never decisive. Rules exempted by an
+// allowlist exception for target are skipped. Returns nil if nothing matched.
+func (p *SecurityPolicy) evaluate(rules []SecurityRule, target matchTarget, subjects ...string) *SecurityRule {
+	var ask *SecurityRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.matchesSubject(subjects) {
+			continue
+		}
+		if exc := p.exceptionFor(rule.ID, target); exc != nil {
+			slog.Info("security rule exempted by allowlist",
+				"rule", rule.ID,
+				"path", target.path,
+				"reason", exc.Reason,
+			)
+			continue
+		}
+		switch rule.Severity {
+		case SeverityDeny:
+			return rule
+		case SeverityAsk:
+			if ask == nil {
+				ask = rule
+			}
+		case SeverityRedact:
+			// Enforced on the tool result by PostToolUse.
+		default:
+			slog.Info("security rule matched (log only)",
+				"rule", rule.ID,
+				"path", target.path,
+			)
+		}
+	}
+	return ask
+}
+
+// redaction returns the first redact rule among rules that matches any of
+// the subjects and is not exempted for target, or nil.
+func (p *SecurityPolicy) redaction(rules []SecurityRule, target matchTarget, subjects ...string) *SecurityRule {
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Severity != SeverityRedact || !rule.matchesSubject(subjects) {
+			continue
+		}
+		if p.exceptionFor(rule.ID, target) == nil {
+			return rule
+		}
+	}
+	return nil
+}
+
+// exceptionFor returns the first exception that exempts ruleID for target.
+func (p *SecurityPolicy) exceptionFor(ruleID string, target matchTarget) *SecurityException {
+	for i := range p.Exceptions {
+		exc := &p.Exceptions[i]
+		if len(exc.RuleIDs) > 0 && !slices.Contains(exc.RuleIDs, ruleID) {
+			continue
+		}
+		if len(exc.Paths) > 0 && !matchesAny(exc.Paths, target.path) {
+			continue
+		}
+		if len(exc.Commands) > 0 && !matchesAny(exc.Commands, target.command) {
+			continue
+		}
+		return exc
+	}
+	return nil
+}
+
+// matchesSubject reports whether the rule matches any of the subjects.
+func (r *SecurityRule) matchesSubject(subjects []string) bool {
+	for _, s := range subjects {
+		if r.find(s) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAny reports whether any of the patterns matches s.
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleReason returns the decision reason for a matched rule, using the
+// rule's Message when set and fallback otherwise. The rule ID is appended
+// so it can be referenced from security.yaml.
+func ruleReason(rule *SecurityRule, fallback string) string {
+	msg := fallback
+	if rule.Message != "" {
+		msg = rule.Message
+	}
+	return fmt.Sprintf("%s [rule: %s]", strings.TrimSpace(msg), rule.ID)
+}
@@ -0,0 +1,240 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+)
+
+func TestNewSecurityPolicy_ZeroConfigMatchesDefaults(t *testing.T) {
+	t.Parallel()
+
+	policy, err := NewSecurityPolicy(config.SecurityConfig{})
+	if err != nil {
+		t.Fatalf("NewSecurityPolicy() error: %v", err)
+	}
+	def := DefaultSecurityPolicy()
+
+	if len(policy.PathRules) != len(def.PathRules) ||
+		len(policy.BashRules) != len(def.BashRules) ||
+		len(policy.ContentRules) != len(def.ContentRules) {
+		t.Error("zero SecurityConfig should produce the default rule set")
+	}
+}
+
+func TestNewSecurityPolicy_RuleMerging(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		sc      config.SecurityConfig
+		wantErr bool
+		check   func(t *testing.T, p *SecurityPolicy)
+	}{
+		{
+			name: "disable built-in rule by id",
+			sc: config.SecurityConfig{Rules: []config.SecurityRuleConfig{
+				{ID: "bash.docker-volume-prune", Disabled: true},
+			}},
+			check: func(t *testing.T, p *SecurityPolicy) {
+				if kind, _ := p.findRule("bash.docker-volume-prune"); kind != "" {
+					t.Error("disabled rule should be removed")
+				}
+			},
+		},
+		{
+			name: "override severity keeps pattern",
+			sc: config.SecurityConfig{Rules: []config.SecurityRuleConfig{
+				{ID: "bash.terraform-destroy", Severity: "ask"},
+			}},
+			check: func(t *testing.T, p *SecurityPolicy) {
+				kind, idx := p.findRule("bash.terraform-destroy")
+				if kind != RuleKindBash {
+					t.Fatalf("rule kind = %q, want bash", kind)
+				}
+				r := p.BashRules[idx]
+				if r.Severity != SeverityAsk {
+					t.Errorf("Severity = %q, want ask", r.Severity)
+				}
+				if r.Pattern == nil || !r.Pattern.MatchString("terraform destroy") {
+					t.Error("pattern should be preserved")
+				}
+			},
+		},
+		{
+			name: "add custom rule",
+			sc: config.SecurityConfig{Rules: []config.SecurityRuleConfig{
+				{ID: "custom.prod-db", Kind: "bash", Pattern: `psql\s+.*prod`, Message: "no prod"},
+			}},
+			check: func(t *testing.T, p *SecurityPolicy) {
+				kind, idx := p.findRule("custom.prod-db")
+				if kind != RuleKindBash {
+					t.Fatalf("rule kind = %q, want bash", kind)
+				}
+				if p.BashRules[idx].Severity != SeverityDeny {
+					t.Errorf("custom rule default severity = %q, want deny", p.BashRules[idx].Severity)
+				}
+			},
+		},
+		{
+			name: "disable defaults keeps only custom rules",
+			sc: config.SecurityConfig{
+				DisableDefaults: true,
+				Rules: []config.SecurityRuleConfig{
+					{ID: "custom.x", Kind: "path", Pattern: `\.env$`},
+				},
+			},
+			check: func(t *testing.T, p *SecurityPolicy) {
+				if len(p.PathRules) != 1 || len(p.BashRules) != 0 || len(p.ContentRules) != 0 {
+					t.Errorf("got %d/%d/%d rules, want 1/0/0", len(p.PathRules), len(p.BashRules), len(p.ContentRules))
+				}
+			},
+		},
+		{
+			name: "blocked tools are appended",
+			sc:   config.SecurityConfig{BlockedTools: []string{"WebFetch"}},
+			check: func(t *testing.T, p *SecurityPolicy) {
+				if len(p.BlockedTools) != 1 || p.BlockedTools[0] != "WebFetch" {
+					t.Errorf("BlockedTools = %v, want [WebFetch]", p.BlockedTools)
+				}
+			},
+		},
+		{
+			name: "new rule without pattern fails",
+			sc: config.SecurityConfig{Rules: []config.SecurityRuleConfig{
+				{ID: "custom.nothing", Kind: "bash"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "kind mismatch with built-in fails",
+			sc: config.SecurityConfig{Rules: []config.SecurityRuleConfig{
+				{ID: "bash.terraform-destroy", Kind: "path"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			policy, err := NewSecurityPolicy(tt.sc)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewSecurityPolicy() error: %v", err)
+			}
+			tt.check(t, policy)
+		})
+	}
+}
+
+func TestPreToolHandler_ConfiguredPolicy(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	policy, err := NewSecurityPolicy(config.SecurityConfig{
+		Rules: []config.SecurityRuleConfig{
+			{ID: "bash.docker-volume-prune", Disabled: true},
+			{ID: "bash.pulumi-destroy", Severity: "log"},
+			{ID: "path.package-json", Severity: "deny", Message: "package.json is managed by tooling"},
+		},
+		Allowlist: []config.SecurityAllowConfig{
+			{Rules: []string{"bash.terraform-destroy"}, Paths: []string{`^sandbox/`}},
+			{Rules: []string{"path.secrets-dir"}, Paths: []string{`^fixtures/secrets/`}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSecurityPolicy() error: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		toolName     string
+		toolInput    map[string]string
+		cwd          string
+		wantDecision string
+		wantInReason string
+	}{
+		{
+			name:         "disabled rule allows command",
+			toolName:     "Bash",
+			toolInput:    map[string]string{"command": "docker volume prune -f"},
+			cwd:          projectDir,
+			wantDecision: DecisionAllow,
+		},
+		{
+			name:         "log severity allows command",
+			toolName:     "Bash",
+			toolInput:    map[string]string{"command": "pulumi destroy --yes"},
+			cwd:          projectDir,
+			wantDecision: DecisionAllow,
+		},
+		{
+			name:         "allowlisted directory permits terraform destroy",
+			toolName:     "Bash",
+			toolInput:    map[string]string{"command": "terraform destroy -auto-approve"},
+			cwd:          filepath.Join(projectDir, "sandbox"),
+			wantDecision: DecisionAllow,
+		},
+		{
+			name:         "terraform destroy outside allowlist is denied with rule id",
+			toolName:     "Bash",
+			toolInput:    map[string]string{"command": "terraform destroy -auto-approve"},
+			cwd:          filepath.Join(projectDir, "prod"),
+			wantDecision: DecisionDeny,
+			wantInReason: "bash.terraform-destroy",
+		},
+		{
+			name:         "severity override turns ask into deny with custom message",
+			toolName:     "Write",
+			toolInput:    map[string]string{"file_path": filepath.Join(projectDir, "package.json"), "content": "{}"},
+			wantDecision: DecisionDeny,
+			wantInReason: "package.json is managed by tooling",
+		},
+		{
+			name:         "allowlisted path permits write",
+			toolName:     "Write",
+			toolInput:    map[string]string{"file_path": filepath.Join(projectDir, "fixtures", "secrets", "fake.txt"), "content": "x"},
+			wantDecision: DecisionAllow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &preToolHandler{policy: policy, projectDir: projectDir}
+			raw, err := json.Marshal(tt.toolInput)
+			if err != nil {
+				t.Fatalf("marshal tool input: %v", err)
+			}
+
+			got, err := h.Handle(context.Background(), &HookInput{
+				ToolName:  tt.toolName,
+				ToolInput: raw,
+				CWD:       tt.cwd,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.HookSpecificOutput.PermissionDecision != tt.wantDecision {
+				t.Errorf("PermissionDecision = %q, want %q (reason: %s)",
+					got.HookSpecificOutput.PermissionDecision, tt.wantDecision,
+					got.HookSpecificOutput.PermissionDecisionReason)
+			}
+			if tt.wantInReason != "" && !strings.Contains(got.HookSpecificOutput.PermissionDecisionReason, tt.wantInReason) {
+				t.Errorf("reason %q should contain %q", got.HookSpecificOutput.PermissionDecisionReason, tt.wantInReason)
+			}
+		})
+	}
+}
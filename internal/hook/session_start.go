@@ -3,9 +3,13 @@ package hook
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"os"
+	"strings"
 
 	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/hook/lifecycle"
 )
 
 // sessionStartHandler processes SessionStart events.
@@ -27,6 +31,8 @@ func (h *sessionStartHandler) EventType() EventType {
 
 // Handle processes a SessionStart event. It logs the session ID, loads
 // project configuration, and returns project information in the Data field.
+// After compaction or on resume, the session's PreCompact snapshot is added
+// to the context in condensed form.
 // Errors are non-blocking: the handler logs warnings and returns allow.
 func (h *sessionStartHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
 	slog.Info("session started",
@@ -58,15 +64,24 @@ func (h *sessionStartHandler) Handle(ctx context.Context, input *HookInput) (*Ho
 		)
 	}
 
+	output := &HookOutput{}
+	if input.Source == "compact" || input.Source == "resume" {
+		if restored := restoreCompactSnapshot(input); restored != "" {
+			output = NewSessionStartOutput(restored)
+			data["snapshot_restored"] = true
+		}
+	}
+
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		slog.Error("failed to marshal session data",
 			"error", err.Error(),
 		)
-		return &HookOutput{}, nil
+		return output, nil
 	}
 
-	return &HookOutput{Data: jsonData}, nil
+	output.Data = jsonData
+	return output, nil
 }
 
 // getConfig safely retrieves the configuration, returning nil if unavailable.
@@ -76,3 +91,76 @@ func (h *sessionStartHandler) getConfig() *config.Config {
 	}
 	return h.cfg.Get()
 }
+
+// Limits of the restored snapshot context.
+const (
+	maxRestoredFiles        = 20
+	maxRestoredTodos        = 10
+	maxRestoredFailureLines = 15
+)
+
+// restoreCompactSnapshot returns the condensed PreCompact snapshot of the
+// input's session, or "" if there is none. Snapshots of other sessions are
+// never restored.
+func restoreCompactSnapshot(input *HookInput) string {
+	projectDir := moaiProjectDir(input)
+	if projectDir == "" || input.SessionID == "" {
+		return ""
+	}
+	path := compactSnapshotPath(projectDir, input.SessionID)
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+
+	state, err := lifecycle.NewWorkState(lifecycle.WorkStateConfig{StoragePath: path}).Load()
+	if err != nil || state == nil {
+		return ""
+	}
+	return formatWorkState(state)
+}
+
+// formatWorkState renders a snapshot as additional context for Claude, or
+// "" if it recorded nothing to restore.
+func formatWorkState(state *lifecycle.WorkStateData) string {
+	var b strings.Builder
+	if state.SpecID != "" {
+		if state.Phase != "" {
+			fmt.Fprintf(&b, "- Active SPEC: %s (TDD phase: %s)\n", state.SpecID, state.Phase)
+		} else {
+			fmt.Fprintf(&b, "- Active SPEC: %s\n", state.SpecID)
+		}
+	}
+	if len(state.ActiveFiles) > 0 {
+		n := max(state.ModifiedCount, len(state.ActiveFiles))
+		files := state.ActiveFiles[:min(len(state.ActiveFiles), maxRestoredFiles)]
+		fmt.Fprintf(&b, "- Modified files (%d): %s", n, strings.Join(files, ", "))
+		if n > len(files) {
+			fmt.Fprintf(&b, ", ... and %d more", n-len(files))
+		}
+		b.WriteString("\n")
+	}
+	if len(state.OpenTodos) > 0 {
+		b.WriteString("- Open TODOs:\n")
+		for i, todo := range state.OpenTodos {
+			if i == maxRestoredTodos {
+				fmt.Fprintf(&b, "  - ... and %d more\n", len(state.OpenTodos)-maxRestoredTodos)
+				break
+			}
+			fmt.Fprintf(&b, "  - %s\n", todo)
+		}
+	}
+	if state.LastTestFailure != "" {
+		b.WriteString("- Last failing test output:\n")
+		for _, line := range strings.Split(tailLines(state.LastTestFailure, maxRestoredFailureLines), "\n") {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+	if state.CompactInstructions != "" {
+		fmt.Fprintf(&b, "- Compaction instructions: %s\n", state.CompactInstructions)
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return fmt.Sprintf("MoAI work state before compaction (%s):\n%s",
+		state.Timestamp.Format("2006-01-02 15:04"), strings.TrimRight(b.String(), "\n"))
+}
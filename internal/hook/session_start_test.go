@@ -3,9 +3,12 @@ package hook
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/hook/lifecycle"
 	"github.com/modu-ai/moai-adk/pkg/models"
 )
 
@@ -107,3 +110,92 @@ func TestSessionStartHandler_Handle(t *testing.T) {
 		})
 	}
 }
+
+// writeCompactSnapshot saves a PreCompact snapshot for sessionID in projectDir.
+func writeCompactSnapshot(t *testing.T, projectDir, sessionID string, state *lifecycle.WorkStateData) {
+	t.Helper()
+	store := lifecycle.NewWorkState(lifecycle.WorkStateConfig{
+		StoragePath: compactSnapshotPath(projectDir, sessionID),
+	})
+	if err := store.Save(state); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionStartHandler_RestoresCompactSnapshot(t *testing.T) {
+	t.Parallel()
+
+	projectDir := t.TempDir()
+	writeCompactSnapshot(t, projectDir, "sess-a", &lifecycle.WorkStateData{
+		SpecID:          "SPEC-AUTH-001",
+		Phase:           "red",
+		ActiveFiles:     []string{"auth/login.go"},
+		OpenTodos:       []string{"[pending] Implement login"},
+		LastTestFailure: "--- FAIL: TestLogin",
+	})
+
+	tests := []struct {
+		name      string
+		source    string
+		sessionID string
+		want      bool
+	}{
+		{name: "compact restores own snapshot", source: "compact", sessionID: "sess-a", want: true},
+		{name: "startup does not restore", source: "startup", sessionID: "sess-a", want: false},
+		{name: "resume restores own snapshot", source: "resume", sessionID: "sess-a", want: true},
+		{name: "resume ignores other sessions' snapshots", source: "resume", sessionID: "sess-b", want: false},
+		{name: "compact without own snapshot", source: "compact", sessionID: "sess-b", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := NewSessionStartHandler(&mockConfigProvider{cfg: newTestConfig()})
+			got, err := h.Handle(context.Background(), &HookInput{
+				SessionID:  tt.sessionID,
+				ProjectDir: projectDir,
+				Source:     tt.source,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			restored := got.HookSpecificOutput != nil && got.HookSpecificOutput.AdditionalContext != ""
+			if restored != tt.want {
+				t.Fatalf("restored = %v, want %v (output %+v)", restored, tt.want, got.HookSpecificOutput)
+			}
+			if !tt.want {
+				return
+			}
+			ctx := got.HookSpecificOutput.AdditionalContext
+			for _, want := range []string{"SPEC-AUTH-001 (TDD phase: red)", "auth/login.go", "[pending] Implement login", "--- FAIL: TestLogin"} {
+				if !strings.Contains(ctx, want) {
+					t.Errorf("AdditionalContext missing %q:\n%s", want, ctx)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatWorkState_TruncatedFiles(t *testing.T) {
+	t.Parallel()
+
+	files := make([]string, maxSnapshotFiles)
+	for i := range files {
+		files[i] = fmt.Sprintf("f%03d.go", i)
+	}
+	got := formatWorkState(&lifecycle.WorkStateData{ActiveFiles: files, ModifiedCount: 120})
+	for _, want := range []string{"Modified files (120):", fmt.Sprintf(", ... and %d more", 120-maxRestoredFiles)} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatWorkState() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormatWorkState_Empty(t *testing.T) {
+	t.Parallel()
+
+	if got := formatWorkState(&lifecycle.WorkStateData{SessionID: "s"}); got != "" {
+		t.Errorf("formatWorkState() = %q, want empty", got)
+	}
+}
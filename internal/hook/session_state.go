@@ -0,0 +1,93 @@
+package hook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/defs"
+	lsphook "github.com/modu-ai/moai-adk/internal/lsp/hook"
+)
+
+// SessionState is the persisted per-session state shared by hook handlers.
+// Each hook invocation runs in a new process, so anything a later event
+// needs is kept in .moai/state/session-<session-id>.json.
+type SessionState struct {
+	SessionID string `json:"session_id"`
+
+	// ChangedFiles lists the project-relative paths written in the session.
+	ChangedFiles []string `json:"changed_files,omitempty"`
+
+	// StopBlocks counts the consecutive Stop events blocked by the quality gate.
+	StopBlocks int `json:"stop_blocks,omitempty"`
+
+	// BaselineCoverage is the first coverage percentage measured in the
+	// session; later measurements are reported relative to it.
+	BaselineCoverage *float64 `json:"baseline_coverage,omitempty"`
+
+	// FormattedHashes maps project-relative paths to the SHA-256 hash
+	// (hex) of their content after the last auto-format.
+	FormattedHashes map[string]string `json:"formatted_hashes,omitempty"`
+
+	// Diagnostics holds the diagnostic statistics of the session.
+	Diagnostics *lsphook.SessionSnapshot `json:"diagnostics,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// unsafeSessionIDChars matches characters not allowed in state file names.
+var unsafeSessionIDChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// sessionFileName returns sessionID made safe for use in a file name.
+func sessionFileName(sessionID string) string {
+	name := unsafeSessionIDChars.ReplaceAllString(sessionID, "_")
+	if name == "" {
+		return "default"
+	}
+	return name
+}
+
+// sessionStatePath returns the state file of a session in projectDir.
+func sessionStatePath(projectDir, sessionID string) string {
+	return filepath.Join(projectDir, defs.MoAIDir, defs.StateSubdir, "session-"+sessionFileName(sessionID)+".json")
+}
+
+// loadSessionState returns the persisted state of a session, or an empty
+// state if none has been recorded yet.
+func loadSessionState(projectDir, sessionID string) (*SessionState, error) {
+	path := sessionStatePath(projectDir, sessionID)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &SessionState{SessionID: sessionID}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read session state: %w", err)
+	}
+	var state SessionState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse session state %s: %w", path, err)
+	}
+	state.SessionID = sessionID
+	return &state, nil
+}
+
+// saveSessionState persists state in projectDir.
+func saveSessionState(projectDir string, state *SessionState) error {
+	state.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal session state: %w", err)
+	}
+	path := sessionStatePath(projectDir, state.SessionID)
+	if err := os.MkdirAll(filepath.Dir(path), defs.DirPerm); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, defs.FilePerm); err != nil {
+		return fmt.Errorf("write session state: %w", err)
+	}
+	return nil
+}
@@ -3,18 +3,32 @@ package hook
 import (
 	"context"
 	"log/slog"
+	"strings"
 )
 
 // stopHandler processes Stop events.
 // It performs graceful shutdown, saves in-progress work state, and preserves
-// loop controller (Ralph) state (REQ-HOOK-035). Always returns "allow".
-type stopHandler struct{}
+// loop controller (Ralph) state (REQ-HOOK-035).
+// With a TDDCycleTracker, the TDD phase history of the active SPEC is
+// reported as a system message. With a StopQualityGate, the stop is blocked
+// while the files changed in the session fail the gate.
+type stopHandler struct {
+	tdd  *TDDCycleTracker
+	gate *StopQualityGate
+}
 
 // NewStopHandler creates a new Stop event handler.
 func NewStopHandler() Handler {
 	return &stopHandler{}
 }
 
+// NewStopHandlerWithQualityGate creates a Stop event handler that reports
+// the TDD cycle history recorded by tracker and blocks the stop while gate
+// fails. Either may be nil.
+func NewStopHandlerWithQualityGate(tracker *TDDCycleTracker, gate *StopQualityGate) Handler {
+	return &stopHandler{tdd: tracker, gate: gate}
+}
+
 // EventType returns EventStop.
 func (h *stopHandler) EventType() EventType {
 	return EventStop
@@ -28,6 +42,9 @@ func (h *stopHandler) EventType() EventType {
 // - Return {"decision": "block", "reason": "..."} to keep Claude working
 // - Check stop_hook_active to prevent infinite loops
 //
+// With a quality gate, a stop with stop_hook_active set is checked again
+// until the gate's max_blocks is reached.
+//
 // Errors are non-blocking: the handler logs warnings and returns empty output.
 func (h *stopHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
 	slog.Info("stop requested",
@@ -38,7 +55,8 @@ func (h *stopHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput
 	// IMPORTANT: Prevent infinite loop per Claude Code protocol
 	// If stop_hook_active is true, Claude is already continuing due to a previous
 	// stop hook decision. Allow Claude to stop to prevent infinite loops.
-	if input.StopHookActive {
+	// The quality gate bounds its own blocks with max_blocks.
+	if input.StopHookActive && h.gate == nil {
 		slog.Debug("stop_hook_active is true, allowing Claude to stop")
 		return &HookOutput{}, nil
 	}
@@ -46,5 +64,22 @@ func (h *stopHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput
 	// Stop hooks use top-level decision/reason fields per Claude Code protocol
 	// Return empty JSON {} to allow Claude to stop (default behavior)
 	// To keep Claude working, return: {"decision": "block", "reason": "..."}
-	return &HookOutput{}, nil
+	output := &HookOutput{}
+	var messages []string
+	if h.gate != nil {
+		reason, note := h.gate.Check(ctx, input)
+		if reason != "" {
+			output = NewStopBlockOutput(reason)
+		}
+		if note != "" {
+			messages = append(messages, note)
+		}
+	}
+	if h.tdd != nil && !input.StopHookActive {
+		if report := h.tdd.Report(input); report != "" {
+			messages = append(messages, report)
+		}
+	}
+	output.SystemMessage = strings.Join(messages, "\n")
+	return output, nil
 }
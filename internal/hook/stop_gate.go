@@ -0,0 +1,622 @@
+package hook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/core/checks"
+	"github.com/modu-ai/moai-adk/internal/core/quality"
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/foundation"
+	"github.com/modu-ai/moai-adk/pkg/models"
+)
+
+// maxGateDetails limits the issue lines in a Stop gate summary.
+const maxGateDetails = 10
+
+// StopQualityGate builds, tests and lints the files changed in a session
+// when Claude tries to stop, and evaluates the results with the TRUST 5
+// gate. Changed files, the consecutive block count and the coverage
+// baseline are kept in the session state.
+type StopQualityGate struct {
+	cfg       ConfigProvider
+	languages *foundation.LanguageRegistry
+	run       func(ctx context.Context, dir string, argv []string) (output string, exitCode int, err error)
+	lookPath  func(file string) (string, error)
+}
+
+// NewStopQualityGate creates a gate configured by the quality.stop_gate
+// section of cfg.
+func NewStopQualityGate(cfg ConfigProvider) *StopQualityGate {
+	return &StopQualityGate{
+		cfg:       cfg,
+		languages: foundation.DefaultRegistry,
+		run:       checks.Run,
+		lookPath:  exec.LookPath,
+	}
+}
+
+// settings returns the stop_gate configuration, or false if the gate is off.
+func (g *StopQualityGate) settings() (models.StopGate, bool) {
+	if g.cfg == nil {
+		return models.StopGate{}, false
+	}
+	cfg := g.cfg.Get()
+	if cfg == nil || !cfg.Quality.StopGate.Enabled {
+		return models.StopGate{}, false
+	}
+	return cfg.Quality.StopGate, true
+}
+
+// recordChange adds path to the changed files of the input's session.
+// Errors are logged, never returned: recording must not fail the tool call.
+func (g *StopQualityGate) recordChange(input *HookInput, path string) {
+	if _, ok := g.settings(); !ok {
+		return
+	}
+	projectDir := moaiProjectDir(input)
+	if projectDir == "" {
+		return
+	}
+	rel := DisplayPath(projectDir, path)
+	if filepath.IsAbs(rel) || rel == defs.MoAIDir || strings.HasPrefix(rel, defs.MoAIDir+"/") {
+		return
+	}
+
+	state, err := loadSessionState(projectDir, input.SessionID)
+	if err != nil {
+		slog.Warn("stop gate: cannot load session state", "error", err)
+		return
+	}
+	if slices.Contains(state.ChangedFiles, rel) {
+		return
+	}
+	state.ChangedFiles = append(state.ChangedFiles, rel)
+	if err := saveSessionState(projectDir, state); err != nil {
+		slog.Warn("stop gate: cannot save session state", "error", err)
+	}
+}
+
+// Check runs the gate for the session of input. It returns a block reason
+// while the changed files fail the gate and the session is below
+// max_blocks, and otherwise a note for the user. Both are empty when the
+// gate does not apply or passes without anything to report.
+//
+// The block count resets on every Stop that is not a continuation of a
+// blocked one (stop_hook_active false), so max_blocks bounds each run of
+// consecutive blocks.
+func (g *StopQualityGate) Check(ctx context.Context, input *HookInput) (reason, note string) {
+	settings, ok := g.settings()
+	if !ok {
+		return "", ""
+	}
+	projectDir := moaiProjectDir(input)
+	if projectDir == "" {
+		return "", ""
+	}
+	state, err := loadSessionState(projectDir, input.SessionID)
+	if err != nil {
+		slog.Warn("stop gate: cannot load session state", "error", err)
+		return "", ""
+	}
+	if len(state.ChangedFiles) == 0 {
+		return "", ""
+	}
+
+	if !input.StopHookActive {
+		state.StopBlocks = 0
+	}
+	if input.StopHookActive && settings.MaxBlocks > 0 && state.StopBlocks >= settings.MaxBlocks {
+		g.save(projectDir, state)
+		return "", fmt.Sprintf("Quality gate still failing after %d block(s); allowing stop.", state.StopBlocks)
+	}
+
+	if settings.TimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(settings.TimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+	diagnostics, coverage := g.runChecks(ctx, projectDir, settings, state.ChangedFiles)
+	if ctx.Err() != nil {
+		slog.Warn("stop gate: timed out", "timeout_seconds", settings.TimeoutSeconds)
+		return "", fmt.Sprintf("Quality gate timed out after %ds; changed files were not fully checked.", settings.TimeoutSeconds)
+	}
+
+	var coverageLine string
+	coverageDropped := false
+	if coverage != nil {
+		if state.BaselineCoverage == nil {
+			state.BaselineCoverage = coverage
+		} else if delta := *coverage - *state.BaselineCoverage; delta != 0 {
+			coverageLine = fmt.Sprintf("coverage: %.1f%% -> %.1f%% (%+.1f)", *state.BaselineCoverage, *coverage, delta)
+			coverageDropped = delta < -0.05
+		}
+	}
+
+	report, err := g.evaluate(ctx, diagnostics)
+	if err != nil {
+		slog.Warn("stop gate: validation failed", "error", err)
+		g.save(projectDir, state)
+		return "", ""
+	}
+
+	if !gateFailed(report) && !coverageDropped {
+		state.StopBlocks = 0
+		g.save(projectDir, state)
+		slog.Info("stop gate passed", "session_id", input.SessionID, "files", len(state.ChangedFiles))
+		return "", ""
+	}
+
+	summary := summarizeGateReport(report, coverageLine)
+	if settings.MaxBlocks == 0 {
+		g.save(projectDir, state)
+		return "", fmt.Sprintf("Quality gate failed for %d changed file(s):\n%s", len(state.ChangedFiles), summary)
+	}
+
+	state.StopBlocks++
+	g.save(projectDir, state)
+	slog.Info("stop gate blocked", "session_id", input.SessionID, "blocks", state.StopBlocks)
+	return fmt.Sprintf("Quality gate failed for %d changed file(s) (block %d/%d):\n%s\nFix these before stopping.",
+		len(state.ChangedFiles), state.StopBlocks, settings.MaxBlocks, summary), ""
+}
+
+// EventTimeout returns the dispatch timeout of event: base, extended for
+// Stop by the quality gate's time limit and for PostToolUse by the
+// auto-format tool runs so that their commands can finish.
+func EventTimeout(cfg ConfigProvider, event EventType, base time.Duration) time.Duration {
+	if cfg == nil {
+		return base
+	}
+	c := cfg.Get()
+	if c == nil {
+		return base
+	}
+	switch {
+	case event == EventStop && c.Quality.StopGate.Enabled:
+		return base + time.Duration(c.Quality.StopGate.TimeoutSeconds)*time.Second
+	case event == EventPostToolUse && c.Quality.AutoFormat.Enabled:
+		return base + autoFormatTimeout(c.Quality.AutoFormat)
+	}
+	return base
+}
+
+// save persists state, logging failures.
+func (g *StopQualityGate) save(projectDir string, state *SessionState) {
+	if err := saveSessionState(projectDir, state); err != nil {
+		slog.Warn("stop gate: cannot save session state", "error", err)
+	}
+}
+
+// evaluate validates the collected diagnostics with the TRUST 5 Tested and
+// Readable principles under the run phase thresholds. Coverage is judged by
+// its change in the session rather than the absolute target, because only
+// the changed packages are measured.
+func (g *StopQualityGate) evaluate(ctx context.Context, diagnostics []quality.Diagnostic) (*quality.Report, error) {
+	client := gateDiagnostics(diagnostics)
+	trust := quality.NewTrustGate(g.qualityConfig(), []quality.Validator{
+		quality.NewTestedValidator(client, 0, 0),
+		quality.NewReadableValidator(client),
+	},
+		quality.WithPhase(quality.PhaseRun),
+		quality.WithLSPClient(client),
+		quality.WithLogger(slog.Default()),
+	)
+	return trust.Validate(ctx)
+}
+
+// qualityConfig maps the project's quality settings onto the TRUST 5 gate.
+func (g *StopQualityGate) qualityConfig() quality.QualityConfig {
+	cfg := g.cfg.Get()
+	if cfg == nil {
+		return quality.DefaultQualityConfig()
+	}
+	return quality.ConfigFromModel(cfg.Quality)
+}
+
+// gateFailed reports whether the run phase thresholds were exceeded. The
+// overall TRUST score is not used: only two of the five principles are
+// evaluated on Stop.
+func gateFailed(report *quality.Report) bool {
+	for _, issue := range report.PhaseIssues {
+		if issue.Severity == quality.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// summarizeGateReport renders the failures of report as a short list:
+// counts per kind, the coverage change, and the first issues.
+func summarizeGateReport(report *quality.Report, coverageLine string) string {
+	var buildErrors, testFailures int
+	var details []string
+	for _, issue := range report.Principles[quality.PrincipleTested].Issues {
+		if issue.Rule == "type-error" {
+			buildErrors++
+		} else {
+			testFailures++
+		}
+		details = append(details, formatGateIssue(issue))
+	}
+	lintIssues := report.Principles[quality.PrincipleReadable].Issues
+	for _, issue := range lintIssues {
+		details = append(details, formatGateIssue(issue))
+	}
+
+	var b strings.Builder
+	if buildErrors > 0 {
+		fmt.Fprintf(&b, "- build: %d error(s)\n", buildErrors)
+	}
+	if testFailures > 0 {
+		fmt.Fprintf(&b, "- tests: %d failing\n", testFailures)
+	}
+	if len(lintIssues) > 0 {
+		fmt.Fprintf(&b, "- lint: %d error(s)\n", len(lintIssues))
+	}
+	if coverageLine != "" {
+		fmt.Fprintf(&b, "- %s\n", coverageLine)
+	}
+	for i, line := range details {
+		if i == maxGateDetails {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(details)-maxGateDetails)
+			break
+		}
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatGateIssue renders one issue as "file:line: message".
+func formatGateIssue(issue quality.Issue) string {
+	switch {
+	case issue.File != "" && issue.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", issue.File, issue.Line, issue.Message)
+	case issue.File != "":
+		return issue.File + ": " + issue.Message
+	default:
+		return issue.Message
+	}
+}
+
+// gateDiagnostics serves the diagnostics produced by the gate commands to
+// the TRUST 5 validators in place of a language server.
+type gateDiagnostics []quality.Diagnostic
+
+// CollectDiagnostics returns the collected diagnostics.
+func (d gateDiagnostics) CollectDiagnostics(ctx context.Context) ([]quality.Diagnostic, error) {
+	return d, nil
+}
+
+// RunChecks runs the configured or default build, test and lint commands
+// for files, relative to projectDir, whether or not the Stop gate is
+// enabled. It returns their diagnostics and the measured coverage, if any
+// command reported one.
+func (g *StopQualityGate) RunChecks(ctx context.Context, projectDir string, files []string) ([]quality.Diagnostic, *float64) {
+	var settings models.StopGate
+	if g.cfg != nil {
+		if cfg := g.cfg.Get(); cfg != nil {
+			settings = cfg.Quality.StopGate
+		}
+	}
+	return g.runChecks(ctx, projectDir, settings, files)
+}
+
+// gateCheck is one command run by the gate.
+type gateCheck struct {
+	kind checks.Kind
+	argv []string
+	// configured is set for commands from the configuration, which are
+	// reported when their binary is missing instead of being skipped.
+	configured bool
+}
+
+// runChecks runs the gate commands for the changed files and returns their
+// diagnostics and the measured coverage, if any command reported one.
+func (g *StopQualityGate) runChecks(ctx context.Context, projectDir string, settings models.StopGate, changed []string) ([]quality.Diagnostic, *float64) {
+	var diagnostics []quality.Diagnostic
+	var coverages []float64
+	for _, check := range g.plan(projectDir, settings, changed) {
+		if ctx.Err() != nil {
+			break
+		}
+		command := strings.Join(check.argv, " ")
+		if _, err := g.lookPath(check.argv[0]); err != nil {
+			if check.configured {
+				diagnostics = append(diagnostics, quality.Diagnostic{
+					Severity: quality.SeverityError,
+					Message:  fmt.Sprintf("%s command not found: %s", check.kind, check.argv[0]),
+					Source:   gateSource(check.kind),
+				})
+			}
+			continue
+		}
+
+		output, exitCode, err := g.run(ctx, projectDir, check.argv)
+		if err != nil {
+			slog.Warn("stop gate: cannot run command", "command", command, "error", err)
+			continue
+		}
+		slog.Debug("stop gate command finished", "command", command, "exit_code", exitCode)
+		if check.kind == checks.Test {
+			if c, ok := checks.ParseCoverage(output); ok {
+				coverages = append(coverages, c)
+			}
+			output = checks.TestOutput(output)
+		}
+		if exitCode != 0 {
+			diagnostics = append(diagnostics, parseGateOutput(check.kind, command, output, projectDir)...)
+		}
+	}
+
+	if len(coverages) == 0 {
+		return diagnostics, nil
+	}
+	var sum float64
+	for _, c := range coverages {
+		sum += c
+	}
+	avg := sum / float64(len(coverages))
+	return diagnostics, &avg
+}
+
+// plan expands the command templates for the changed files. Configured
+// commands run once for all files; default commands run per language for
+// that language's files.
+func (g *StopQualityGate) plan(projectDir string, settings models.StopGate, changed []string) []gateCheck {
+	byLang := make(map[foundation.SupportedLanguage][]string)
+	var langs []foundation.SupportedLanguage
+	for _, file := range changed {
+		info, err := g.languages.ByExtension(filepath.Ext(file))
+		if err != nil {
+			continue
+		}
+		if _, ok := byLang[info.ID]; !ok {
+			langs = append(langs, info.ID)
+		}
+		byLang[info.ID] = append(byLang[info.ID], file)
+	}
+
+	var planned []gateCheck
+	for _, kind := range checks.Kinds {
+		if configured := configuredGateCommand(settings, kind); configured != "" {
+			if argv := g.expand(configured, projectDir, changed); argv != nil {
+				planned = append(planned, gateCheck{kind: kind, argv: argv, configured: true})
+			}
+			continue
+		}
+		for _, lang := range langs {
+			template := checks.Template(g.languages, lang, kind)
+			if template == "" {
+				continue
+			}
+			if argv := g.expand(template, projectDir, byLang[lang]); argv != nil {
+				planned = append(planned, gateCheck{kind: kind, argv: argv})
+			}
+		}
+	}
+	return planned
+}
+
+// configuredGateCommand returns the configured command of a kind.
+func configuredGateCommand(settings models.StopGate, kind checks.Kind) string {
+	switch kind {
+	case checks.Build:
+		return settings.BuildCommand
+	case checks.Test:
+		return settings.TestCommand
+	default:
+		return settings.LintCommand
+	}
+}
+
+// expand splits template into arguments with the shell parser and replaces
+// the {files}, {packages} and {tests} placeholders with one argument per
+// entry. A template that needs a shell runs with "sh -c", without
+// placeholders. Deleted files are left out. Returns nil when a placeholder
+// expands to nothing.
+func (g *StopQualityGate) expand(template, projectDir string, files []string) []string {
+	var existing []string
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(projectDir, file)); err == nil {
+			existing = append(existing, file)
+		}
+	}
+
+	words, err := checks.Argv(template)
+	if err != nil {
+		slog.Warn("stop gate: invalid command", "command", template, "error", err)
+		return nil
+	}
+	var argv []string
+	for _, field := range words {
+		var values []string
+		switch field {
+		case checks.FilesPlaceholder:
+			values = existing
+		case checks.PackagesPlaceholder:
+			values = goPackages(files)
+		case checks.TestsPlaceholder:
+			values = g.testFiles(projectDir, existing)
+		default:
+			argv = append(argv, field)
+			continue
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		argv = append(argv, values...)
+	}
+	if len(argv) == 0 {
+		return nil
+	}
+	return argv
+}
+
+// goPackages returns the relative package patterns of the Go files.
+func goPackages(files []string) []string {
+	var packages []string
+	for _, file := range files {
+		if filepath.Ext(file) != ".go" {
+			continue
+		}
+		pkg := "./" + filepath.ToSlash(filepath.Dir(file))
+		if pkg == "./." {
+			pkg = "."
+		}
+		if !slices.Contains(packages, pkg) {
+			packages = append(packages, pkg)
+		}
+	}
+	return packages
+}
+
+// testFiles returns the changed test files and the existing test files of
+// the changed production files.
+func (g *StopQualityGate) testFiles(projectDir string, files []string) []string {
+	var tests []string
+	add := func(rel string) {
+		if !slices.Contains(tests, rel) {
+			tests = append(tests, rel)
+		}
+	}
+	for _, file := range files {
+		if g.languages.IsTestFile(file) {
+			add(file)
+			continue
+		}
+		abs := filepath.Join(projectDir, file)
+		for _, candidate := range g.languages.TestFileCandidates(abs, projectDir) {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				add(DisplayPath(projectDir, candidate))
+			}
+		}
+	}
+	return tests
+}
+
+var (
+	// gateLocationPattern matches "file:line[:col]: message" lines from
+	// compilers and linters (go, ruff, eslint --format unix).
+	gateLocationPattern = regexp.MustCompile(`(?m)^(?:vet: )?(\S+?\.\w+):(\d+)(?::\d+)?:\s+(.+?)\s*$`)
+
+	// gateTSCPattern matches "file(line,col): error TSxxxx: message" lines from tsc.
+	gateTSCPattern = regexp.MustCompile(`(?m)^(\S+?)\((\d+),\d+\):\s+error\s+(.+?)\s*$`)
+
+	// gateFailedTestPatterns capture failed test names: Go, pytest and vitest/jest.
+	gateFailedTestPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^\s*--- FAIL: (\S+)`),
+		regexp.MustCompile(`(?m)^FAILED (\S+)`),
+		regexp.MustCompile(`(?m)^\s*(?:×|✗|FAIL)\s+(\S+\s+>\s+.+?)\s*$`),
+	}
+)
+
+// gateSource maps a command kind to the diagnostic source the TRUST 5
+// validators understand.
+func gateSource(kind checks.Kind) string {
+	switch kind {
+	case checks.Build:
+		return "typecheck"
+	case checks.Lint:
+		return "lint"
+	default:
+		return "test"
+	}
+}
+
+// parseGateOutput converts the output of a failed command into diagnostics.
+// Lint findings are warnings so that only the Readable principle counts them.
+func parseGateOutput(kind checks.Kind, command, output, projectDir string) []quality.Diagnostic {
+	source := gateSource(kind)
+	severity := quality.SeverityError
+	if kind == checks.Lint {
+		severity = quality.SeverityWarning
+	}
+
+	var diagnostics []quality.Diagnostic
+	if kind == checks.Test {
+		for _, pattern := range gateFailedTestPatterns {
+			for _, m := range pattern.FindAllStringSubmatch(output, -1) {
+				diagnostics = append(diagnostics, quality.Diagnostic{
+					Severity: severity,
+					Message:  "test failed: " + m[1],
+					Source:   source,
+					Code:     string(kind),
+				})
+			}
+			if len(diagnostics) > 0 {
+				return diagnostics
+			}
+		}
+	} else {
+		for _, pattern := range []*regexp.Regexp{gateLocationPattern, gateTSCPattern} {
+			for _, m := range pattern.FindAllStringSubmatch(output, -1) {
+				line, _ := strconv.Atoi(m[2])
+				diagnostics = append(diagnostics, quality.Diagnostic{
+					File:     DisplayPath(projectDir, absUnder(projectDir, m[1])),
+					Line:     line,
+					Severity: severity,
+					Message:  m[3],
+					Source:   source,
+					Code:     string(kind),
+				})
+			}
+		}
+		if len(diagnostics) > 0 {
+			return diagnostics
+		}
+	}
+
+	message := fmt.Sprintf("%s failed: %s", command, lastLine(output))
+	if n := countTestFailures(output); kind == checks.Test && n > 0 {
+		message = fmt.Sprintf("%s: %d test(s) failed", command, n)
+	}
+	return []quality.Diagnostic{{Severity: severity, Message: message, Source: source, Code: string(kind)}}
+}
+
+// absUnder resolves a relative path reported by a tool against projectDir.
+func absUnder(projectDir, p string) string {
+	if filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(projectDir, p)
+}
+
+// lastLine returns the last non-empty line of output.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// stopGatePostToolHandler records the files written in a session for the
+// Stop quality gate.
+type stopGatePostToolHandler struct {
+	gate *StopQualityGate
+}
+
+// NewStopGatePostToolHandler creates a PostToolUse handler that records
+// Write, Edit and MultiEdit targets as changed files of the session.
+func NewStopGatePostToolHandler(gate *StopQualityGate) Handler {
+	return &stopGatePostToolHandler{gate: gate}
+}
+
+// EventType returns EventPostToolUse.
+func (h *stopGatePostToolHandler) EventType() EventType {
+	return EventPostToolUse
+}
+
+// Handle records the edited file. It never blocks.
+func (h *stopGatePostToolHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
+	if path := EditedFile(input); path != "" {
+		h.gate.recordChange(input, path)
+	}
+	return NewPostToolOutput(""), nil
+}
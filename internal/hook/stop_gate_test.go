@@ -0,0 +1,404 @@
+package hook
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/core/checks"
+	"github.com/modu-ai/moai-adk/internal/core/quality"
+	"github.com/modu-ai/moai-adk/pkg/models"
+)
+
+// fakeGateRun records gate commands and answers them from outputs, keyed by
+// the command's first two words.
+type fakeGateRun struct {
+	outputs map[string]string
+	exits   map[string]int
+	calls   []string
+}
+
+func (f *fakeGateRun) run(ctx context.Context, dir string, argv []string) (string, int, error) {
+	command := strings.Join(argv, " ")
+	f.calls = append(f.calls, command)
+	key := strings.Join(argv[:min(2, len(argv))], " ")
+	return f.outputs[key], f.exits[key], nil
+}
+
+// newTestStopGate returns a gate for a temporary MoAI project with the Go
+// files calc/calc.go and calc/calc_test.go, and its fake command runner.
+func newTestStopGate(t *testing.T, settings models.StopGate) (*StopQualityGate, *fakeGateRun, string) {
+	t.Helper()
+	projectDir := t.TempDir()
+	writeGateFile(t, projectDir, ".moai/config/.keep", "")
+	writeGateFile(t, projectDir, "calc/calc.go", "package calc\n")
+	writeGateFile(t, projectDir, "calc/calc_test.go", "package calc\n")
+
+	cfg := config.NewDefaultConfig()
+	cfg.Quality.StopGate = settings
+
+	fake := &fakeGateRun{outputs: map[string]string{}, exits: map[string]int{}}
+	gate := NewStopQualityGate(&mockConfigProvider{cfg: cfg})
+	gate.run = fake.run
+	gate.lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	return gate, fake, projectDir
+}
+
+func writeGateFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// defaultStopGate returns the default gate settings with the gate enabled,
+// as the generated quality.yaml does.
+func defaultStopGate() models.StopGate {
+	s := config.NewDefaultStopGate()
+	s.Enabled = true
+	return s
+}
+
+func stopInput(projectDir string, active bool) *HookInput {
+	return &HookInput{SessionID: "sess-gate", ProjectDir: projectDir, CWD: projectDir, StopHookActive: active}
+}
+
+func recordGateEdit(t *testing.T, gate *StopQualityGate, projectDir, file string) {
+	t.Helper()
+	h := NewStopGatePostToolHandler(gate)
+	input := editInput(projectDir, "Write", file)
+	input.SessionID = "sess-gate"
+	if _, err := h.Handle(context.Background(), input); err != nil {
+		t.Fatalf("record %s: %v", file, err)
+	}
+}
+
+func TestStopGate_RecordChange(t *testing.T) {
+	t.Parallel()
+
+	gate, _, projectDir := newTestStopGate(t, defaultStopGate())
+	recordGateEdit(t, gate, projectDir, "calc/calc.go")
+	recordGateEdit(t, gate, projectDir, "calc/calc.go")
+	recordGateEdit(t, gate, projectDir, ".moai/specs/SPEC-X/spec.md")
+
+	outside := editInput(projectDir, "Write", "x")
+	outside.SessionID = "sess-gate"
+	gate.recordChange(outside, "/elsewhere/file.go")
+
+	state, err := loadSessionState(projectDir, "sess-gate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"calc/calc.go"}; !slices.Equal(state.ChangedFiles, want) {
+		t.Errorf("ChangedFiles = %v, want %v", state.ChangedFiles, want)
+	}
+}
+
+func TestStopGate_NoChangesSkipsChecks(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestStopGate(t, defaultStopGate())
+	reason, note := gate.Check(context.Background(), stopInput(projectDir, false))
+	if reason != "" || note != "" {
+		t.Errorf("Check() = %q, %q, want empty", reason, note)
+	}
+	if len(fake.calls) != 0 {
+		t.Errorf("ran %v, want no commands", fake.calls)
+	}
+}
+
+func TestStopGate_PassingChecks(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestStopGate(t, defaultStopGate())
+	recordGateEdit(t, gate, projectDir, "calc/calc.go")
+	fake.outputs["go test"] = "ok  \texample/calc\t0.01s\tcoverage: 80.0% of statements\n"
+
+	reason, note := gate.Check(context.Background(), stopInput(projectDir, false))
+	if reason != "" || note != "" {
+		t.Errorf("Check() = %q, %q, want pass", reason, note)
+	}
+	want := []string{"go build ./calc", "go test -json -cover ./calc", "go vet ./calc"}
+	if !slices.Equal(fake.calls, want) {
+		t.Errorf("commands = %v, want %v", fake.calls, want)
+	}
+
+	state, _ := loadSessionState(projectDir, "sess-gate")
+	if state.BaselineCoverage == nil || *state.BaselineCoverage != 80 {
+		t.Errorf("BaselineCoverage = %v, want 80", state.BaselineCoverage)
+	}
+}
+
+func TestStopGate_BlocksUntilMaxBlocks(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultStopGate()
+	settings.MaxBlocks = 2
+	gate, fake, projectDir := newTestStopGate(t, settings)
+	recordGateEdit(t, gate, projectDir, "calc/calc.go")
+	fake.outputs["go test"] = `{"Action":"run","Package":"example/calc","Test":"TestAdd"}
+{"Action":"output","Package":"example/calc","Test":"TestAdd","Output":"--- FAIL: TestAdd (0.00s)\n"}
+{"Action":"output","Package":"example/calc","Test":"TestAdd","Output":"    calc_test.go:9: got 3\n"}
+{"Action":"fail","Package":"example/calc","Test":"TestAdd"}
+{"Action":"fail","Package":"example/calc"}
+`
+	fake.exits["go test"] = 1
+	fake.outputs["go vet"] = "# example/calc\nvet: calc/calc.go:4:2: unreachable code\n"
+	fake.exits["go vet"] = 1
+
+	reason, _ := gate.Check(context.Background(), stopInput(projectDir, false))
+	for _, want := range []string{"(block 1/2)", "- tests: 1 failing", "test failed: TestAdd", "- lint: 1 error(s)", "calc/calc.go:4: unreachable code"} {
+		if !strings.Contains(reason, want) {
+			t.Errorf("reason missing %q:\n%s", want, reason)
+		}
+	}
+
+	reason, _ = gate.Check(context.Background(), stopInput(projectDir, true))
+	if !strings.Contains(reason, "(block 2/2)") {
+		t.Errorf("second stop: reason = %q, want block 2/2", reason)
+	}
+
+	reason, note := gate.Check(context.Background(), stopInput(projectDir, true))
+	if reason != "" {
+		t.Errorf("third stop: reason = %q, want allow", reason)
+	}
+	if !strings.Contains(note, "after 2 block(s)") {
+		t.Errorf("third stop: note = %q", note)
+	}
+
+	// A fresh stop starts a new series of blocks.
+	reason, _ = gate.Check(context.Background(), stopInput(projectDir, false))
+	if !strings.Contains(reason, "(block 1/2)") {
+		t.Errorf("fresh stop: reason = %q, want block 1/2", reason)
+	}
+}
+
+func TestStopGate_CoverageDrop(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestStopGate(t, defaultStopGate())
+	recordGateEdit(t, gate, projectDir, "calc/calc.go")
+	baseline := 82.5
+	state, _ := loadSessionState(projectDir, "sess-gate")
+	state.BaselineCoverage = &baseline
+	if err := saveSessionState(projectDir, state); err != nil {
+		t.Fatal(err)
+	}
+	fake.outputs["go test"] = "ok  \texample/calc\t0.01s\tcoverage: 70.0% of statements\n"
+
+	reason, _ := gate.Check(context.Background(), stopInput(projectDir, false))
+	if !strings.Contains(reason, "- coverage: 82.5% -> 70.0% (-12.5)") {
+		t.Errorf("reason = %q, want coverage delta", reason)
+	}
+}
+
+func TestStopGate_ReportOnlyWithoutMaxBlocks(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultStopGate()
+	settings.MaxBlocks = 0
+	gate, fake, projectDir := newTestStopGate(t, settings)
+	recordGateEdit(t, gate, projectDir, "calc/calc.go")
+	fake.outputs["go build"] = "# example/calc\ncalc/calc.go:3:9: undefined: sum\n"
+	fake.exits["go build"] = 1
+
+	reason, note := gate.Check(context.Background(), stopInput(projectDir, false))
+	if reason != "" {
+		t.Errorf("reason = %q, want no block", reason)
+	}
+	if !strings.Contains(note, "- build: 1 error(s)") || !strings.Contains(note, "calc/calc.go:3: undefined: sum") {
+		t.Errorf("note = %q", note)
+	}
+}
+
+func TestStopGate_ConfiguredCommands(t *testing.T) {
+	t.Parallel()
+
+	settings := defaultStopGate()
+	settings.TestCommand = "make test"
+	settings.LintCommand = "golangci-lint run {packages}"
+	gate, fake, projectDir := newTestStopGate(t, settings)
+	gate.lookPath = func(file string) (string, error) {
+		if file == "golangci-lint" {
+			return "", os.ErrNotExist
+		}
+		return "/usr/bin/" + file, nil
+	}
+	recordGateEdit(t, gate, projectDir, "calc/calc.go")
+
+	reason, _ := gate.Check(context.Background(), stopInput(projectDir, false))
+	want := []string{"go build ./calc", "make test"}
+	if !slices.Equal(fake.calls, want) {
+		t.Errorf("commands = %v, want %v", fake.calls, want)
+	}
+	if !strings.Contains(reason, "lint command not found: golangci-lint") {
+		t.Errorf("reason = %q, want missing lint command", reason)
+	}
+}
+
+func TestStopGate_Disabled(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestStopGate(t, models.StopGate{})
+	recordGateEdit(t, gate, projectDir, "calc/calc.go")
+
+	reason, note := gate.Check(context.Background(), stopInput(projectDir, false))
+	if reason != "" || note != "" || len(fake.calls) != 0 {
+		t.Errorf("Check() = %q, %q, calls %v; want disabled", reason, note, fake.calls)
+	}
+}
+
+func TestStopGate_RunChecksWhileDisabled(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestStopGate(t, models.StopGate{})
+	fake.outputs["go test"] = "ok  calc  coverage: 72.5% of statements\n"
+	fake.outputs["go vet"] = "calc/calc.go:3:2: unreachable code\n"
+	fake.exits["go vet"] = 1
+
+	diagnostics, coverage := gate.RunChecks(context.Background(), projectDir, []string{"calc/calc.go", "calc/calc_test.go"})
+	want := []string{"go build ./calc", "go test -json -cover ./calc", "go vet ./calc"}
+	if !slices.Equal(fake.calls, want) {
+		t.Errorf("commands = %v, want %v", fake.calls, want)
+	}
+	if coverage == nil || *coverage != 72.5 {
+		t.Errorf("coverage = %v, want 72.5", coverage)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].File != "calc/calc.go" || diagnostics[0].Source != "lint" {
+		t.Errorf("diagnostics = %+v, want one lint finding in calc/calc.go", diagnostics)
+	}
+}
+
+func TestStopGate_ExpandPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	gate, _, projectDir := newTestStopGate(t, defaultStopGate())
+	writeGateFile(t, projectDir, "app/util.py", "")
+	writeGateFile(t, projectDir, "app/test_util.py", "")
+	writeGateFile(t, projectDir, "main.go", "")
+
+	tests := []struct {
+		template string
+		files    []string
+		want     []string
+	}{
+		{"go vet {packages}", []string{"main.go", "calc/calc.go", "calc/calc_test.go"}, []string{"go", "vet", ".", "./calc"}},
+		{"pytest -q {tests}", []string{"app/util.py"}, []string{"pytest", "-q", "app/test_util.py"}},
+		{"ruff check {files}", []string{"app/util.py", "app/deleted.py"}, []string{"ruff", "check", "app/util.py"}},
+		{"ruff check {files}", []string{"app/deleted.py"}, nil},
+		{"tsc --noEmit", nil, []string{"tsc", "--noEmit"}},
+		{`pytest -k "not slow" {tests}`, []string{"app/util.py"}, []string{"pytest", "-k", "not slow", "app/test_util.py"}},
+		{"npm ci && npm test", []string{"app/util.py"}, []string{"sh", "-c", "npm ci && npm test"}},
+	}
+	for _, tt := range tests {
+		got := gate.expand(tt.template, projectDir, tt.files)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("expand(%q, %v) = %v, want %v", tt.template, tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestParseGateOutput(t *testing.T) {
+	t.Parallel()
+
+	projectDir := "/work/proj"
+	tests := []struct {
+		name   string
+		kind   checks.Kind
+		output string
+		want   []quality.Diagnostic
+	}{
+		{
+			name:   "go build",
+			kind:   checks.Build,
+			output: "# example/calc\n./calc/calc.go:3:9: undefined: sum\n",
+			want:   []quality.Diagnostic{{File: "calc/calc.go", Line: 3, Severity: "error", Message: "undefined: sum", Source: "typecheck", Code: "build"}},
+		},
+		{
+			name:   "tsc",
+			kind:   checks.Build,
+			output: "src/a.ts(12,5): error TS2322: Type 'string' is not assignable to type 'number'.\n",
+			want:   []quality.Diagnostic{{File: "src/a.ts", Line: 12, Severity: "error", Message: "TS2322: Type 'string' is not assignable to type 'number'.", Source: "typecheck", Code: "build"}},
+		},
+		{
+			name:   "eslint unix",
+			kind:   checks.Lint,
+			output: "/work/proj/src/a.js:4:1: 'x' is defined but never used. [Error/no-unused-vars]\n\n1 problem\n",
+			want:   []quality.Diagnostic{{File: "src/a.js", Line: 4, Severity: "warning", Message: "'x' is defined but never used. [Error/no-unused-vars]", Source: "lint", Code: "lint"}},
+		},
+		{
+			name:   "pytest",
+			kind:   checks.Test,
+			output: "FAILED tests/test_calc.py::test_add - assert 3 == 4\n1 failed, 2 passed\n",
+			want:   []quality.Diagnostic{{Severity: "error", Message: "test failed: tests/test_calc.py::test_add", Source: "test", Code: "test"}},
+		},
+		{
+			name:   "unparsed test failure",
+			kind:   checks.Test,
+			output: "Tests: 2 failed, 5 passed\n",
+			want:   []quality.Diagnostic{{Severity: "error", Message: "npm test: 2 test(s) failed", Source: "test", Code: "test"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := parseGateOutput(tt.kind, "npm test", tt.output, projectDir)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseGateOutput() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStopHandler_QualityGateBlocks(t *testing.T) {
+	t.Parallel()
+
+	gate, fake, projectDir := newTestStopGate(t, defaultStopGate())
+	recordGateEdit(t, gate, projectDir, "calc/calc.go")
+	fake.outputs["go test"] = "--- FAIL: TestAdd (0.00s)\nFAIL\n"
+	fake.exits["go test"] = 1
+
+	h := NewStopHandlerWithQualityGate(nil, gate)
+	got, err := h.Handle(context.Background(), stopInput(projectDir, false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Decision != DecisionBlock || !strings.Contains(got.Reason, "TestAdd") {
+		t.Errorf("output = %+v, want block naming TestAdd", got)
+	}
+	if got.HookSpecificOutput != nil {
+		t.Error("Stop hook should not set hookSpecificOutput")
+	}
+}
+
+func TestEventTimeout(t *testing.T) {
+	t.Parallel()
+
+	c := config.NewDefaultConfig()
+	c.Quality.StopGate.Enabled = true
+	c.Quality.AutoFormat.Enabled = true
+	cfg := &mockConfigProvider{cfg: c}
+	base := 30 * time.Second
+	if got := EventTimeout(cfg, EventStop, base); got != base+300*time.Second {
+		t.Errorf("Stop timeout = %v, want %v", got, base+300*time.Second)
+	}
+	if got := EventTimeout(cfg, EventPostToolUse, base); got != base+30*time.Second {
+		t.Errorf("PostToolUse timeout = %v, want %v", got, base+30*time.Second)
+	}
+	if got := EventTimeout(cfg, EventPreToolUse, base); got != base {
+		t.Errorf("PreToolUse timeout = %v, want %v", got, base)
+	}
+	if got := EventTimeout(nil, EventStop, base); got != base {
+		t.Errorf("timeout without config = %v, want %v", got, base)
+	}
+}
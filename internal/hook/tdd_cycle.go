@@ -0,0 +1,618 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/foundation"
+	"github.com/modu-ai/moai-adk/internal/shell"
+	"github.com/modu-ai/moai-adk/pkg/models"
+)
+
+// TDDCycleState is the persisted RED-GREEN-REFACTOR state of one SPEC.
+type TDDCycleState struct {
+	SpecID string           `json:"spec_id"`
+	Phase  foundation.Phase `json:"phase"`
+
+	// NewTests lists test files written since the cycle entered RED.
+	NewTests []string `json:"new_tests,omitempty"`
+
+	// RedConfirmed is set once a test run fails after NewTests were written.
+	RedConfirmed bool `json:"red_confirmed,omitempty"`
+
+	// LastFailures is the failure count of the most recent test run.
+	LastFailures int `json:"last_failures"`
+
+	History   []TDDTransition `json:"history,omitempty"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// TDDTransition records one phase change of a TDD cycle.
+type TDDTransition struct {
+	From    foundation.Phase `json:"from"`
+	To      foundation.Phase `json:"to"`
+	Trigger string           `json:"trigger"`
+	At      time.Time        `json:"at"`
+
+	// Skipped marks a step that broke the cycle: a transition that
+	// foundation.TDDCycle rejects, or RED to GREEN without a failing test.
+	Skipped bool   `json:"skipped,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+// TDDCycleTracker enforces and records the RED-GREEN-REFACTOR cycle of the
+// active SPEC. Each hook invocation runs in a new process, so the phase is
+// kept in .moai/state/tdd-<SPEC-ID>.json. Tracking is off when there is no
+// active SPEC or the project uses the DDD development mode.
+type TDDCycleTracker struct {
+	cfg         ConfigProvider
+	languages   *foundation.LanguageRegistry
+	cycle       *foundation.TDDCycle
+	resolveSpec func(projectDir string) string
+	now         func() time.Time
+}
+
+// NewTDDCycleTracker creates a tracker that reads the development mode from
+// cfg and takes the active SPEC from the current git branch or worktree.
+func NewTDDCycleTracker(cfg ConfigProvider) *TDDCycleTracker {
+	return &TDDCycleTracker{
+		cfg:         cfg,
+		languages:   foundation.DefaultRegistry,
+		cycle:       &foundation.TDDCycle{},
+		resolveSpec: activeSpecID,
+		now:         time.Now,
+	}
+}
+
+// tddScope identifies the project and SPEC a tool call belongs to.
+type tddScope struct {
+	projectDir string
+	specID     string
+}
+
+// scope returns the tracking scope for input, or false if the cycle is not
+// tracked for it.
+func (t *TDDCycleTracker) scope(input *HookInput) (tddScope, bool) {
+	if t.mode() == models.ModeDDD {
+		return tddScope{}, false
+	}
+	projectDir := input.ProjectDir
+	if projectDir == "" {
+		projectDir = os.Getenv("CLAUDE_PROJECT_DIR")
+	}
+	if projectDir == "" {
+		projectDir = input.CWD
+	}
+	if projectDir == "" {
+		return tddScope{}, false
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, defs.MoAIDir)); err != nil {
+		return tddScope{}, false
+	}
+	specID := t.resolveSpec(projectDir)
+	if specID == "" {
+		return tddScope{}, false
+	}
+	return tddScope{projectDir: projectDir, specID: specID}, true
+}
+
+// mode returns the configured development mode, hybrid when unset.
+func (t *TDDCycleTracker) mode() models.DevelopmentMode {
+	if t.cfg != nil {
+		if cfg := t.cfg.Get(); cfg != nil && cfg.Quality.DevelopmentMode != "" {
+			return cfg.Quality.DevelopmentMode
+		}
+	}
+	return models.ModeHybrid
+}
+
+// enforced reports whether test-first is required for path. In hybrid mode
+// only new files follow TDD; existing files follow DDD.
+func (t *TDDCycleTracker) enforced(path string) bool {
+	switch t.mode() {
+	case models.ModeTDD:
+		return true
+	case models.ModeHybrid:
+		_, err := os.Stat(path)
+		strategy := &foundation.HybridStrategy{}
+		return strategy.ClassifyCode(errors.Is(err, os.ErrNotExist)) == foundation.CycleTDD
+	default:
+		return false
+	}
+}
+
+// statePath returns the state file of a SPEC.
+func (s tddScope) statePath() string {
+	return filepath.Join(s.projectDir, defs.MoAIDir, defs.StateSubdir, "tdd-"+s.specID+".json")
+}
+
+// load returns the persisted state of the SPEC, or a fresh cycle in RED if
+// none has been recorded yet.
+func (t *TDDCycleTracker) load(s tddScope) (*TDDCycleState, error) {
+	data, err := os.ReadFile(s.statePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return &TDDCycleState{SpecID: s.specID, Phase: foundation.PhaseRed}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read tdd state: %w", err)
+	}
+	var state TDDCycleState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parse tdd state %s: %w", s.statePath(), err)
+	}
+	if !state.Phase.IsValid() {
+		state.Phase = foundation.PhaseRed
+	}
+	return &state, nil
+}
+
+// save persists state for the SPEC.
+func (t *TDDCycleTracker) save(s tddScope, state *TDDCycleState) error {
+	state.UpdatedAt = t.now()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal tdd state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.statePath()), defs.DirPerm); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	if err := os.WriteFile(s.statePath(), data, defs.FilePerm); err != nil {
+		return fmt.Errorf("write tdd state: %w", err)
+	}
+	return nil
+}
+
+// transition moves state to phase to and appends it to the history. Steps
+// the TDD cycle does not allow, or that come with a skipReason, are recorded
+// as skipped rather than refused: the tracker records what happened, the
+// PreToolUse guard is what prevents it.
+func (t *TDDCycleTracker) transition(state *TDDCycleState, to foundation.Phase, trigger, skipReason string) {
+	step := TDDTransition{From: state.Phase, To: to, Trigger: trigger, At: t.now()}
+	if err := t.cycle.ValidateTransition(state.Phase, to); err != nil {
+		step.Skipped = true
+		step.Note = err.Error()
+	} else if skipReason != "" {
+		step.Skipped = true
+		step.Note = skipReason
+	}
+	state.History = append(state.History, step)
+	state.Phase = to
+	state.NewTests = nil
+	state.RedConfirmed = false
+
+	slog.Info("tdd phase transition",
+		"spec_id", state.SpecID,
+		"from", string(step.From),
+		"to", string(step.To),
+		"skipped", step.Skipped,
+	)
+}
+
+// CheckEdit returns a deny reason when a Write/Edit of the production file
+// path would skip RED: no new test has been seen failing in this cycle.
+// Package docs, type declarations and generated files are exempt.
+func (t *TDDCycleTracker) CheckEdit(input *HookInput, path string) string {
+	if !t.languages.IsProductionSource(path) || testFirstExempt(path) {
+		return ""
+	}
+	s, ok := t.scope(input)
+	if !ok || !t.enforced(path) {
+		return ""
+	}
+	state, err := t.load(s)
+	if err != nil {
+		slog.Warn("tdd: cannot load cycle state", "error", err)
+		return ""
+	}
+	if state.Phase != foundation.PhaseRed || state.RedConfirmed {
+		return ""
+	}
+
+	rel := DisplayPath(s.projectDir, path)
+	if len(state.NewTests) == 0 {
+		test := "a test"
+		if candidates := t.languages.TestFileCandidates(path, s.projectDir); len(candidates) > 0 {
+			test = DisplayPath(s.projectDir, candidates[0])
+		}
+		return fmt.Sprintf("TDD RED phase (%s): write a failing test in %s before changing %s.", s.specID, test, rel)
+	}
+
+	testCmd := "the tests"
+	if info, err := t.languages.ByExtension(filepath.Ext(path)); err == nil && info.TestPattern != "" {
+		testCmd = info.TestPattern
+	}
+	return fmt.Sprintf("TDD RED phase (%s): run %s and confirm the new test in %s fails before changing %s.",
+		s.specID, testCmd, DisplayPath(s.projectDir, state.NewTests[len(state.NewTests)-1]), rel)
+}
+
+// recordEdit updates the cycle after a Write/Edit of path. Writing a test
+// after GREEN or REFACTOR starts a new cycle; changing production code
+// after GREEN is refactoring.
+func (t *TDDCycleTracker) recordEdit(input *HookInput, path string) {
+	isTest := t.languages.IsTestFile(path)
+	if !isTest && !t.languages.IsProductionSource(path) {
+		return
+	}
+	t.update(input, func(s tddScope, state *TDDCycleState) bool {
+		rel := DisplayPath(s.projectDir, path)
+		if !isTest {
+			if state.Phase != foundation.PhaseGreen {
+				return false
+			}
+			t.transition(state, foundation.PhaseRefactor, "edited "+rel, "")
+			return true
+		}
+
+		if state.Phase != foundation.PhaseRed {
+			t.transition(state, foundation.PhaseRed, "edited "+rel, "")
+		}
+		for _, existing := range state.NewTests {
+			if existing == path {
+				return true
+			}
+		}
+		state.NewTests = append(state.NewTests, path)
+		return true
+	})
+}
+
+// recordTestRun updates the cycle after a test command finished with the
+// given number of failures. A failure after new tests confirms RED; zero
+// failures afterwards completes RED to GREEN.
+func (t *TDDCycleTracker) recordTestRun(input *HookInput, command string, failures int) {
+	t.update(input, func(_ tddScope, state *TDDCycleState) bool {
+		state.LastFailures = failures
+		if state.Phase != foundation.PhaseRed || len(state.NewTests) == 0 {
+			return true
+		}
+		if failures > 0 {
+			state.RedConfirmed = true
+			return true
+		}
+		skipReason := ""
+		if !state.RedConfirmed {
+			skipReason = "new tests passed without failing first"
+		}
+		t.transition(state, foundation.PhaseGreen, describeShellCommand(command), skipReason)
+		return true
+	})
+}
+
+// update loads the state of the input's SPEC, applies fn and saves the
+// result if fn reports a change. Errors are logged, never returned: tracking
+// must not fail the tool call.
+func (t *TDDCycleTracker) update(input *HookInput, fn func(tddScope, *TDDCycleState) bool) {
+	s, ok := t.scope(input)
+	if !ok {
+		return
+	}
+	state, err := t.load(s)
+	if err != nil {
+		slog.Warn("tdd: cannot load cycle state", "error", err)
+		return
+	}
+	if !fn(s, state) {
+		return
+	}
+	if err := t.save(s, state); err != nil {
+		slog.Warn("tdd: cannot save cycle state", "error", err)
+	}
+}
+
+// maxReportedTransitions limits the history lines in the Stop report.
+const maxReportedTransitions = 10
+
+// Report summarizes the phase history of the input's SPEC for the Stop
+// hook, flagging skipped steps. Returns "" when nothing was recorded.
+func (t *TDDCycleTracker) Report(input *HookInput) string {
+	s, ok := t.scope(input)
+	if !ok {
+		return ""
+	}
+	state, err := t.load(s)
+	if err != nil || len(state.History) == 0 {
+		return ""
+	}
+
+	skipped := 0
+	for _, step := range state.History {
+		if step.Skipped {
+			skipped++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "TDD cycle %s: phase %s, %d transition(s)", state.SpecID, state.Phase, len(state.History))
+	if skipped > 0 {
+		fmt.Fprintf(&b, ", %d skipped", skipped)
+	}
+
+	history := state.History
+	if len(history) > maxReportedTransitions {
+		fmt.Fprintf(&b, "\n  ... %d earlier transition(s)", len(history)-maxReportedTransitions)
+		history = history[len(history)-maxReportedTransitions:]
+	}
+	for _, step := range history {
+		fmt.Fprintf(&b, "\n  %s %s -> %s (%s)", step.At.Local().Format("15:04"), step.From, step.To, step.Trigger)
+		if step.Skipped {
+			fmt.Fprintf(&b, " SKIPPED: %s", step.Note)
+		}
+	}
+	return b.String()
+}
+
+// tddPreToolHandler denies production edits that would skip RED.
+type tddPreToolHandler struct {
+	tracker *TDDCycleTracker
+}
+
+// NewTDDPreToolHandler creates a PreToolUse handler that denies Write/Edit
+// on production files while the SPEC's cycle is in RED with no failing test.
+func NewTDDPreToolHandler(tracker *TDDCycleTracker) Handler {
+	return &tddPreToolHandler{tracker: tracker}
+}
+
+// EventType returns EventPreToolUse.
+func (h *tddPreToolHandler) EventType() EventType {
+	return EventPreToolUse
+}
+
+// Handle checks Write, Edit and MultiEdit calls against the TDD cycle.
+func (h *tddPreToolHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
+	path := EditedFile(input)
+	if path == "" {
+		return NewAllowOutput(), nil
+	}
+	if reason := h.tracker.CheckEdit(input, path); reason != "" {
+		slog.Info("tdd: production edit denied", "file", path)
+		return NewDenyOutput(reason), nil
+	}
+	return NewAllowOutput(), nil
+}
+
+// tddPostToolHandler records edits and test runs in the TDD cycle.
+type tddPostToolHandler struct {
+	tracker *TDDCycleTracker
+	event   EventType
+}
+
+// NewTDDPostToolHandler creates a PostToolUse handler that records test
+// edits, production edits and test command results in the SPEC's cycle.
+func NewTDDPostToolHandler(tracker *TDDCycleTracker) Handler {
+	return &tddPostToolHandler{tracker: tracker, event: EventPostToolUse}
+}
+
+// NewTDDPostToolFailureHandler creates a PostToolUseFailure handler that
+// records failed test commands in the SPEC's cycle.
+func NewTDDPostToolFailureHandler(tracker *TDDCycleTracker) Handler {
+	return &tddPostToolHandler{tracker: tracker, event: EventPostToolUseFailure}
+}
+
+// EventType returns EventPostToolUse or EventPostToolUseFailure.
+func (h *tddPostToolHandler) EventType() EventType {
+	return h.event
+}
+
+// Handle records the tool call. It never blocks.
+func (h *tddPostToolHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
+	if h.event == EventPostToolUse {
+		if path := EditedFile(input); path != "" {
+			h.tracker.recordEdit(input, path)
+		}
+	}
+
+	if input.ToolName == "Bash" {
+		var params struct {
+			Command string `json:"command"`
+		}
+		if err := json.Unmarshal(input.ToolInput, &params); err == nil && isTestCommand(params.Command) {
+			var failures int
+			if h.event == EventPostToolUseFailure {
+				failures = max(countTestFailures(input.Error), 1)
+			} else {
+				failures = countTestFailures(bashOutput(input.ToolResponse))
+			}
+			h.tracker.recordTestRun(input, params.Command, failures)
+		}
+	}
+
+	if h.event == EventPostToolUse {
+		return NewPostToolOutput(""), nil
+	}
+	return &HookOutput{}, nil
+}
+
+// EditedFile returns the absolute path written by a Write, Edit or
+// MultiEdit call, resolving relative paths against cwd, or "".
+func EditedFile(input *HookInput) string {
+	switch input.ToolName {
+	case "Write", "Edit", "MultiEdit":
+	default:
+		return ""
+	}
+	var params struct {
+		FilePath string `json:"file_path"`
+	}
+	if err := json.Unmarshal(input.ToolInput, &params); err != nil || params.FilePath == "" {
+		return ""
+	}
+	p := params.FilePath
+	if !filepath.IsAbs(p) && input.CWD != "" {
+		p = filepath.Join(input.CWD, p)
+	}
+	return filepath.Clean(p)
+}
+
+// generatedSuffixes are file name endings used by code generators.
+var generatedSuffixes = []string{".pb.go", "_gen.go", ".gen.go", "_generated.go", "_pb2.py", ".generated.ts", ".d.ts"}
+
+// testFirstExempt reports whether path holds no behavior to test first:
+// package documentation, type declarations or generated code.
+func testFirstExempt(p string) bool {
+	base := filepath.Base(p)
+	if base == "doc.go" || base == "types.go" {
+		return true
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+	return hasGeneratedHeader(p)
+}
+
+// hasGeneratedHeader reports whether the existing file p starts with a
+// generator marker such as "Code generated ... DO NOT EDIT." or
+// "@generated".
+func hasGeneratedHeader(p string) bool {
+	f, err := os.Open(p)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = f.Close() }()
+	buf := make([]byte, 1024)
+	n, _ := f.Read(buf)
+	header := strings.ToLower(string(buf[:n]))
+	return strings.Contains(header, "@generated") ||
+		(strings.Contains(header, "generated") && strings.Contains(header, "do not edit"))
+}
+
+// extraTestCommands lists test runners besides each language's TestPattern.
+var extraTestCommands = [][]string{
+	{"jest"},
+	{"npm", "test"}, {"npm", "run", "test"}, {"npm", "t"},
+	{"pnpm", "test"}, {"pnpm", "run", "test"},
+	{"yarn", "test"}, {"bun", "test"},
+	{"npx", "vitest"}, {"npx", "jest"},
+	{"python", "-m", "pytest"}, {"python3", "-m", "pytest"},
+	{"uv", "run", "pytest"},
+	{"gradlew", "test"},
+}
+
+// isTestCommand reports whether a Bash command line runs a test suite: the
+// TestPattern of a registered language (e.g. "go test", "pytest") or one of
+// the common JavaScript and Python runners.
+func isTestCommand(command string) bool {
+	if command == "" {
+		return false
+	}
+	commands, _, err := shell.ParseCommands(command)
+	if err != nil {
+		return false
+	}
+
+	prefixes := append([][]string(nil), extraTestCommands...)
+	for _, info := range foundation.DefaultRegistry.All() {
+		var words []string
+		for _, w := range strings.Fields(info.TestPattern) {
+			if strings.HasPrefix(w, ".") || strings.HasPrefix(w, "-") {
+				break
+			}
+			words = append(words, w)
+		}
+		if len(words) > 0 {
+			prefixes = append(prefixes, words)
+		}
+	}
+
+	for _, c := range commands {
+		if len(c.Argv) == 0 {
+			continue
+		}
+		argv := append([]string{path.Base(c.Argv[0])}, c.Argv[1:]...)
+		for _, prefix := range prefixes {
+			if len(argv) >= len(prefix) && slices.Equal(argv[:len(prefix)], prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bashOutput extracts stdout and stderr from a Bash tool response.
+func bashOutput(response json.RawMessage) string {
+	var parsed struct {
+		Stdout string `json:"stdout"`
+		Stderr string `json:"stderr"`
+	}
+	if err := json.Unmarshal(response, &parsed); err == nil {
+		return parsed.Stdout + "\n" + parsed.Stderr
+	}
+	var text string
+	if err := json.Unmarshal(response, &text); err == nil {
+		return text
+	}
+	return string(response)
+}
+
+var (
+	// goTestFailPattern matches one failed Go test or subtest.
+	goTestFailPattern = regexp.MustCompile(`(?m)^\s*--- FAIL: `)
+
+	// goPackageFailPattern matches a failed Go package, including build failures.
+	goPackageFailPattern = regexp.MustCompile(`(?m)^FAIL\s`)
+
+	// failureCountPatterns capture failure counts from runner summaries:
+	// pytest and jest/vitest "N failed", pytest collection errors, and JUnit
+	// (Maven/Gradle) "Failures: N".
+	failureCountPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(\d+) failed`),
+		regexp.MustCompile(`(?m)^=+ .*?\b(\d+) errors?\b`),
+		regexp.MustCompile(`Failures: (\d+)`),
+		regexp.MustCompile(`Errors: (\d+)`),
+	}
+)
+
+// countTestFailures returns the number of failed tests reported in output,
+// or 0 if it reports none.
+func countTestFailures(output string) int {
+	if n := len(goTestFailPattern.FindAllString(output, -1)); n > 0 {
+		return n
+	}
+
+	most := 0
+	for _, re := range failureCountPatterns {
+		for _, m := range re.FindAllStringSubmatch(output, -1) {
+			if n, err := strconv.Atoi(m[1]); err == nil && n > most {
+				most = n
+			}
+		}
+	}
+	if most == 0 && goPackageFailPattern.MatchString(output) {
+		return 1
+	}
+	return most
+}
+
+// specIDPattern matches SPEC identifiers such as SPEC-AUTH-001.
+var specIDPattern = regexp.MustCompile(`SPEC-[A-Z0-9]+(?:-[A-Z0-9]+)+`)
+
+// activeSpecID returns the SPEC being worked on in projectDir: from a
+// ".moai/worktrees/SPEC-..." worktree path or a "feature/SPEC-..." branch.
+// Returns "" when neither names a SPEC.
+func activeSpecID(projectDir string) string {
+	if id := specIDPattern.FindString(filepath.ToSlash(projectDir)); id != "" {
+		return id
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), foundation.DefaultGitTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "git", "-C", projectDir, "symbolic-ref", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return specIDPattern.FindString(strings.TrimSpace(string(out)))
+}
@@ -0,0 +1,314 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/foundation"
+	"github.com/modu-ai/moai-adk/pkg/models"
+)
+
+// newTestTDDTracker returns a tracker for a temporary MoAI project whose
+// active SPEC is SPEC-CALC-001.
+func newTestTDDTracker(t *testing.T, mode models.DevelopmentMode) (*TDDCycleTracker, string) {
+	t.Helper()
+	projectDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectDir, ".moai"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.NewDefaultConfig()
+	cfg.Quality.DevelopmentMode = mode
+
+	tracker := NewTDDCycleTracker(&mockConfigProvider{cfg: cfg})
+	tracker.resolveSpec = func(string) string { return "SPEC-CALC-001" }
+	tracker.now = func() time.Time { return time.Date(2026, 1, 2, 10, 30, 0, 0, time.Local) }
+	return tracker, projectDir
+}
+
+// editInput builds a tool input writing file in projectDir.
+func editInput(projectDir, toolName, file string) *HookInput {
+	raw, _ := json.Marshal(map[string]string{"file_path": filepath.Join(projectDir, file)})
+	return &HookInput{ToolName: toolName, ToolInput: raw, CWD: projectDir, ProjectDir: projectDir}
+}
+
+// bashInput builds a Bash tool input with the given command and output.
+func bashInput(projectDir, command, stdout string) *HookInput {
+	raw, _ := json.Marshal(map[string]string{"command": command})
+	resp, _ := json.Marshal(map[string]any{"stdout": stdout, "stderr": "", "interrupted": false})
+	return &HookInput{ToolName: "Bash", ToolInput: raw, ToolResponse: resp, CWD: projectDir, ProjectDir: projectDir}
+}
+
+func isDenied(out *HookOutput) bool {
+	return out.HookSpecificOutput != nil && out.HookSpecificOutput.PermissionDecision == DecisionDeny
+}
+
+func TestTDDCycle_RedGreenRefactor(t *testing.T) {
+	t.Parallel()
+
+	tracker, dir := newTestTDDTracker(t, models.ModeTDD)
+	pre := NewTDDPreToolHandler(tracker)
+	post := NewTDDPostToolHandler(tracker)
+	ctx := context.Background()
+
+	// RED without a test: production edit is denied.
+	out, _ := pre.Handle(ctx, editInput(dir, "Write", "calc.go"))
+	if !isDenied(out) {
+		t.Fatal("production edit before any test should be denied")
+	}
+	if reason := out.HookSpecificOutput.PermissionDecisionReason; !strings.Contains(reason, "calc_test.go") {
+		t.Errorf("reason should name the test file: %s", reason)
+	}
+
+	// Test written but not yet seen failing: still denied.
+	_, _ = post.Handle(ctx, editInput(dir, "Write", "calc_test.go"))
+	out, _ = pre.Handle(ctx, editInput(dir, "Write", "calc.go"))
+	if !isDenied(out) || !strings.Contains(out.HookSpecificOutput.PermissionDecisionReason, "go test") {
+		t.Fatalf("production edit before the test fails should be denied with the test command, got %+v", out.HookSpecificOutput)
+	}
+
+	// Failing run confirms RED: production edit allowed.
+	_, _ = post.Handle(ctx, bashInput(dir, "go test ./...", "--- FAIL: TestAdd (0.00s)\nFAIL\nFAIL\tcalc\t0.01s\n"))
+	out, _ = pre.Handle(ctx, editInput(dir, "Write", "calc.go"))
+	if isDenied(out) {
+		t.Fatalf("production edit after a failing test should be allowed: %s", out.HookSpecificOutput.PermissionDecisionReason)
+	}
+
+	// Passing run: RED -> GREEN. Production edit afterwards: GREEN -> REFACTOR.
+	_, _ = post.Handle(ctx, bashInput(dir, "go test ./...", "ok  \tcalc\t0.01s\n"))
+	_, _ = post.Handle(ctx, editInput(dir, "Edit", "calc.go"))
+	// New test: REFACTOR -> RED.
+	_, _ = post.Handle(ctx, editInput(dir, "Edit", "calc_test.go"))
+
+	s, _ := tracker.scope(editInput(dir, "Write", "calc.go"))
+	state, err := tracker.load(s)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := []foundation.Phase{foundation.PhaseGreen, foundation.PhaseRefactor, foundation.PhaseRed}
+	if len(state.History) != len(want) {
+		t.Fatalf("history = %+v, want %d transitions", state.History, len(want))
+	}
+	for i, step := range state.History {
+		if step.To != want[i] || step.Skipped {
+			t.Errorf("transition %d = %s -> %s (skipped %v), want -> %s", i, step.From, step.To, step.Skipped, want[i])
+		}
+	}
+	if state.Phase != foundation.PhaseRed || len(state.NewTests) != 1 {
+		t.Errorf("state = %s with %d new tests, want red with 1", state.Phase, len(state.NewTests))
+	}
+}
+
+func TestTDDCycle_SkippedSteps(t *testing.T) {
+	t.Parallel()
+
+	tracker, dir := newTestTDDTracker(t, models.ModeTDD)
+	post := NewTDDPostToolHandler(tracker)
+	ctx := context.Background()
+
+	// A new test that passes straight away skips RED.
+	_, _ = post.Handle(ctx, editInput(dir, "Write", "calc_test.go"))
+	_, _ = post.Handle(ctx, bashInput(dir, "cd sub && go test ./...", "ok  \tcalc\t0.01s\n"))
+	// Writing the next test from GREEN skips REFACTOR.
+	_, _ = post.Handle(ctx, editInput(dir, "Write", "calc_test.go"))
+
+	report := tracker.Report(editInput(dir, "Write", "calc.go"))
+	for _, want := range []string{
+		"TDD cycle SPEC-CALC-001: phase red, 2 transition(s), 2 skipped",
+		"red -> green (cd sub && go test ./...) SKIPPED: new tests passed without failing first",
+		"green -> red",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report should contain %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestTDDCycle_FailureEvent(t *testing.T) {
+	t.Parallel()
+
+	tracker, dir := newTestTDDTracker(t, models.ModeTDD)
+	post := NewTDDPostToolHandler(tracker)
+	failure := NewTDDPostToolFailureHandler(tracker)
+	ctx := context.Background()
+
+	_, _ = post.Handle(ctx, editInput(dir, "Write", "calc_test.go"))
+	in := bashInput(dir, "go test ./...", "")
+	in.ToolResponse = nil
+	in.Error = "Exit code 1"
+	if _, err := failure.Handle(ctx, in); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out, _ := NewTDDPreToolHandler(tracker).Handle(ctx, editInput(dir, "Write", "calc.go"))
+	if isDenied(out) {
+		t.Errorf("a failed test command should confirm RED: %s", out.HookSpecificOutput.PermissionDecisionReason)
+	}
+}
+
+func TestTDDCycle_ModeAndScope(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("ddd mode is not tracked", func(t *testing.T) {
+		t.Parallel()
+		tracker, dir := newTestTDDTracker(t, models.ModeDDD)
+		out, _ := NewTDDPreToolHandler(tracker).Handle(ctx, editInput(dir, "Write", "calc.go"))
+		if isDenied(out) {
+			t.Error("ddd mode should not enforce test-first")
+		}
+	})
+
+	t.Run("hybrid enforces new files only", func(t *testing.T) {
+		t.Parallel()
+		tracker, dir := newTestTDDTracker(t, models.ModeHybrid)
+		if err := os.WriteFile(filepath.Join(dir, "legacy.go"), []byte("package calc\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		pre := NewTDDPreToolHandler(tracker)
+		if out, _ := pre.Handle(ctx, editInput(dir, "Edit", "legacy.go")); isDenied(out) {
+			t.Error("existing file should follow DDD in hybrid mode")
+		}
+		if out, _ := pre.Handle(ctx, editInput(dir, "Write", "calc.go")); !isDenied(out) {
+			t.Error("new file should follow TDD in hybrid mode")
+		}
+	})
+
+	t.Run("no active SPEC", func(t *testing.T) {
+		t.Parallel()
+		tracker, dir := newTestTDDTracker(t, models.ModeTDD)
+		tracker.resolveSpec = func(string) string { return "" }
+		if out, _ := NewTDDPreToolHandler(tracker).Handle(ctx, editInput(dir, "Write", "calc.go")); isDenied(out) {
+			t.Error("edits outside a SPEC should not be tracked")
+		}
+	})
+
+	t.Run("non-source files", func(t *testing.T) {
+		t.Parallel()
+		tracker, dir := newTestTDDTracker(t, models.ModeTDD)
+		if out, _ := NewTDDPreToolHandler(tracker).Handle(ctx, editInput(dir, "Write", "README.md")); isDenied(out) {
+			t.Error("documentation should not be guarded")
+		}
+	})
+}
+
+func TestTestFirstExempt(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	generated := filepath.Join(dir, "api.go")
+	if err := os.WriteFile(generated, []byte("// Code generated by oapi-codegen. DO NOT EDIT.\n\npackage calc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	handwritten := filepath.Join(dir, "calc.go")
+	if err := os.WriteFile(handwritten, []byte("package calc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{filepath.Join(dir, "doc.go"), true},
+		{filepath.Join(dir, "types.go"), true},
+		{filepath.Join(dir, "calc.pb.go"), true},
+		{filepath.Join(dir, "zz_generated.go"), true},
+		{generated, true},
+		{handwritten, false},
+		{filepath.Join(dir, "service.py"), false},
+	}
+	for _, tt := range tests {
+		if got := testFirstExempt(tt.path); got != tt.want {
+			t.Errorf("testFirstExempt(%s) = %v, want %v", filepath.Base(tt.path), got, tt.want)
+		}
+	}
+}
+
+func TestIsTestCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"go test ./...", true},
+		{"cd internal && go test -run TestAdd .", true},
+		{"GOFLAGS=-count=1 go test ./...", true},
+		{"pytest -q tests/", true},
+		{"python3 -m pytest", true},
+		{"npx vitest run", true},
+		{"npm test", true},
+		{"cargo test", true},
+		{"go build ./...", false},
+		{"echo go test", false},
+		{"git commit -m 'go test'", false},
+	}
+	for _, tt := range tests {
+		if got := isTestCommand(tt.command); got != tt.want {
+			t.Errorf("isTestCommand(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestCountTestFailures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		output string
+		want   int
+	}{
+		{"go pass", "ok  \tcalc\t0.01s\n", 0},
+		{"go failures", "--- FAIL: TestA (0.00s)\n    --- FAIL: TestA/sub (0.00s)\nFAIL\n", 2},
+		{"go build failure", "# calc\n./calc.go:3:1: syntax error\nFAIL\tcalc [build failed]\n", 1},
+		{"pytest", "==== 3 failed, 10 passed in 0.5s ====", 3},
+		{"pytest collection error", "==== 1 error in 0.2s ====", 1},
+		{"vitest", " Tests  2 failed | 8 passed (10)", 2},
+		{"junit", "Tests run: 5, Failures: 1, Errors: 0, Skipped: 0", 1},
+		{"cargo pass", "test result: ok. 4 passed; 0 failed; 0 ignored", 0},
+	}
+	for _, tt := range tests {
+		if got := countTestFailures(tt.output); got != tt.want {
+			t.Errorf("%s: countTestFailures() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestActiveSpecID_WorktreePath(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), ".moai", "worktrees", "SPEC-AUTH-001")
+	if got := activeSpecID(dir); got != "SPEC-AUTH-001" {
+		t.Errorf("activeSpecID() = %q, want SPEC-AUTH-001", got)
+	}
+}
+
+func TestStopHandler_ReportsTDDHistory(t *testing.T) {
+	t.Parallel()
+
+	tracker, dir := newTestTDDTracker(t, models.ModeTDD)
+	post := NewTDDPostToolHandler(tracker)
+	ctx := context.Background()
+	_, _ = post.Handle(ctx, editInput(dir, "Write", "calc_test.go"))
+	_, _ = post.Handle(ctx, bashInput(dir, "go test ./...", "--- FAIL: TestAdd (0.00s)\n"))
+	_, _ = post.Handle(ctx, bashInput(dir, "go test ./...", "ok  \tcalc\n"))
+
+	out, err := NewStopHandlerWithQualityGate(tracker, nil).Handle(ctx, &HookInput{SessionID: "s", CWD: dir, ProjectDir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out.SystemMessage, "10:30 red -> green (go test ./...)") {
+		t.Errorf("SystemMessage should report the transition:\n%s", out.SystemMessage)
+	}
+	if strings.Contains(out.SystemMessage, "SKIPPED") {
+		t.Errorf("a proper cycle should not be flagged as skipped:\n%s", out.SystemMessage)
+	}
+	if out.Decision != "" {
+		t.Errorf("Stop report must not block, got decision %q", out.Decision)
+	}
+}
@@ -0,0 +1,163 @@
+package hook
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxFailureLines limits the test output kept from the transcript.
+const maxFailureLines = 30
+
+// transcriptDigest is what a PreCompact snapshot keeps from the transcript.
+type transcriptDigest struct {
+	// OpenTodos lists the unfinished items of the latest TodoWrite call as
+	// "[status] content".
+	OpenTodos []string
+
+	// LastTestFailure is the tail of the output of the latest test command,
+	// or "" if it passed.
+	LastTestFailure string
+}
+
+// transcriptEntry is the part of a transcript line the digest reads.
+type transcriptEntry struct {
+	Message struct {
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+// transcriptBlock is one content block of a transcript message.
+type transcriptBlock struct {
+	Type      string          `json:"type"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
+}
+
+// readTranscriptDigest scans the JSONL transcript at path for the latest
+// todo list and the output of the latest test command. Lines that are not
+// valid JSON are skipped.
+func readTranscriptDigest(path string) (transcriptDigest, error) {
+	var digest transcriptDigest
+	f, err := os.Open(path)
+	if err != nil {
+		return digest, fmt.Errorf("open transcript: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	testCommands := make(map[string]bool)
+	reader := bufio.NewReader(f)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			digest.apply(line, testCommands)
+		}
+		if errors.Is(readErr, io.EOF) {
+			return digest, nil
+		}
+		if readErr != nil {
+			return digest, fmt.Errorf("read transcript: %w", readErr)
+		}
+	}
+}
+
+// apply updates the digest with one transcript line. testCommands tracks
+// the IDs of Bash tool calls that ran tests.
+func (d *transcriptDigest) apply(line []byte, testCommands map[string]bool) {
+	var entry transcriptEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return
+	}
+	var blocks []transcriptBlock
+	if err := json.Unmarshal(entry.Message.Content, &blocks); err != nil {
+		return
+	}
+
+	for _, block := range blocks {
+		switch block.Type {
+		case "tool_use":
+			switch block.Name {
+			case "TodoWrite":
+				d.OpenTodos = openTodos(block.Input)
+			case "Bash":
+				var params struct {
+					Command string `json:"command"`
+				}
+				if json.Unmarshal(block.Input, &params) == nil && isTestCommand(params.Command) {
+					testCommands[block.ID] = true
+				}
+			}
+		case "tool_result":
+			if !testCommands[block.ToolUseID] {
+				continue
+			}
+			output := toolResultText(block.Content)
+			if block.IsError || countTestFailures(output) > 0 {
+				d.LastTestFailure = tailLines(output, maxFailureLines)
+			} else {
+				d.LastTestFailure = ""
+			}
+		}
+	}
+}
+
+// openTodos returns the items of a TodoWrite input that are not completed.
+func openTodos(input json.RawMessage) []string {
+	var params struct {
+		Todos []struct {
+			Content string `json:"content"`
+			Status  string `json:"status"`
+		} `json:"todos"`
+	}
+	if err := json.Unmarshal(input, &params); err != nil {
+		return nil
+	}
+	var todos []string
+	for _, todo := range params.Todos {
+		if todo.Status == "completed" || todo.Content == "" {
+			continue
+		}
+		todos = append(todos, fmt.Sprintf("[%s] %s", todo.Status, todo.Content))
+	}
+	return todos
+}
+
+// toolResultText returns the text of a tool_result content, which is
+// either a string or a list of text blocks.
+func toolResultText(content json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return text
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &blocks); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Type == "text" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// tailLines returns the last n lines of text.
+func tailLines(text string, n int) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,68 @@
+package hook
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeTranscript writes JSONL transcript lines to a temporary file.
+func writeTranscript(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "transcript.jsonl")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const (
+	transcriptTodos   = `{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"t1","name":"TodoWrite","input":{"todos":[{"content":"Write parser tests","status":"completed"},{"content":"Implement parser","status":"in_progress"},{"content":"Update docs","status":"pending"}]}}]}}`
+	transcriptTestRun = `{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"b1","name":"Bash","input":{"command":"go test ./parser/..."}}]}}`
+	transcriptFailure = `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"b1","is_error":true,"content":"--- FAIL: TestParse (0.00s)\n    parse_test.go:12: got nil\nFAIL"}]}}`
+	transcriptLsRun   = `{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"b2","name":"Bash","input":{"command":"ls"}}]}}`
+	transcriptLsOut   = `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"b2","content":[{"type":"text","text":"FAIL everything 3 failed"}]}]}}`
+	transcriptRerun   = `{"type":"assistant","message":{"role":"assistant","content":[{"type":"tool_use","id":"b3","name":"Bash","input":{"command":"go test ./parser/..."}}]}}`
+	transcriptPass    = `{"type":"user","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"b3","content":[{"type":"text","text":"ok  \tparser\t0.01s"}]}]}}`
+)
+
+func TestReadTranscriptDigest(t *testing.T) {
+	t.Parallel()
+
+	path := writeTranscript(t, transcriptTodos, "not json", transcriptTestRun, transcriptFailure, transcriptLsRun, transcriptLsOut)
+	digest, err := readTranscriptDigest(path)
+	if err != nil {
+		t.Fatalf("readTranscriptDigest() error = %v", err)
+	}
+
+	wantTodos := []string{"[in_progress] Implement parser", "[pending] Update docs"}
+	if !slices.Equal(digest.OpenTodos, wantTodos) {
+		t.Errorf("OpenTodos = %v, want %v", digest.OpenTodos, wantTodos)
+	}
+	if !strings.Contains(digest.LastTestFailure, "--- FAIL: TestParse") {
+		t.Errorf("LastTestFailure = %q, want the go test failure", digest.LastTestFailure)
+	}
+}
+
+func TestReadTranscriptDigest_PassingRunClearsFailure(t *testing.T) {
+	t.Parallel()
+
+	path := writeTranscript(t, transcriptTestRun, transcriptFailure, transcriptRerun, transcriptPass)
+	digest, err := readTranscriptDigest(path)
+	if err != nil {
+		t.Fatalf("readTranscriptDigest() error = %v", err)
+	}
+	if digest.LastTestFailure != "" {
+		t.Errorf("LastTestFailure = %q, want empty after a passing run", digest.LastTestFailure)
+	}
+}
+
+func TestReadTranscriptDigest_MissingFile(t *testing.T) {
+	t.Parallel()
+
+	if _, err := readTranscriptDigest(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("readTranscriptDigest() error = nil, want error for a missing transcript")
+	}
+}
@@ -258,6 +258,28 @@ func NewSessionOutput(continueSession bool, message string) *HookOutput {
 	}
 }
 
+// NewSessionStartOutput creates a HookOutput that adds context to the
+// session for SessionStart events.
+func NewSessionStartOutput(additionalContext string) *HookOutput {
+	return &HookOutput{
+		HookSpecificOutput: &HookSpecificOutput{
+			HookEventName:     "SessionStart",
+			AdditionalContext: additionalContext,
+		},
+	}
+}
+
+// NewUserPromptSubmitOutput creates a HookOutput that adds context to a
+// user prompt for UserPromptSubmit events.
+func NewUserPromptSubmitOutput(additionalContext string) *HookOutput {
+	return &HookOutput{
+		HookSpecificOutput: &HookSpecificOutput{
+			HookEventName:     "UserPromptSubmit",
+			AdditionalContext: additionalContext,
+		},
+	}
+}
+
 // NewPostToolOutput creates a HookOutput with additionalContext for PostToolUse.
 func NewPostToolOutput(context string) *HookOutput {
 	return &HookOutput{
@@ -2,24 +2,55 @@ package hook
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/github"
 )
 
 // userPromptSubmitHandler processes UserPromptSubmit events.
-// It logs user prompt submissions for auditing.
-type userPromptSubmitHandler struct{}
+// It logs user prompt submissions for auditing. When created with a config,
+// it also rejects prompts containing secrets or blocklisted text, and
+// injects context for the SPECs, issues and files the prompt mentions.
+type userPromptSubmitHandler struct {
+	cfg    ConfigProvider
+	policy *SecurityPolicy
+	linker func(projectRoot string) (github.SpecLinker, error)
+}
 
-// NewUserPromptSubmitHandler creates a new UserPromptSubmit event handler.
+// NewUserPromptSubmitHandler creates a new UserPromptSubmit event handler
+// that only logs prompts.
 func NewUserPromptSubmitHandler() Handler {
 	return &userPromptSubmitHandler{}
 }
 
+// NewUserPromptSubmitHandlerWithContext creates a UserPromptSubmit handler
+// that applies the prompt section of cfg. Secrets are detected with the
+// content rules of policy; a nil policy uses the built-in rules.
+func NewUserPromptSubmitHandlerWithContext(cfg ConfigProvider, policy *SecurityPolicy) Handler {
+	if policy == nil {
+		policy = DefaultSecurityPolicy()
+	}
+	return &userPromptSubmitHandler{
+		cfg:    cfg,
+		policy: policy,
+		linker: github.NewSpecLinker,
+	}
+}
+
 // EventType returns EventUserPromptSubmit.
 func (h *userPromptSubmitHandler) EventType() EventType {
 	return EventUserPromptSubmit
 }
 
-// Handle processes a UserPromptSubmit event. It logs the prompt submission.
+// Handle processes a UserPromptSubmit event. It logs the prompt submission,
+// blocks prompts that match a deny secret rule or the blocklist, and returns
+// a warning for ask secret rules and the resolved prompt context as
+// additional context.
 // The prompt is truncated to 100 characters for privacy.
 func (h *userPromptSubmitHandler) Handle(ctx context.Context, input *HookInput) (*HookOutput, error) {
 	prompt := input.Prompt
@@ -30,5 +61,99 @@ func (h *userPromptSubmitHandler) Handle(ctx context.Context, input *HookInput)
 		"session_id", input.SessionID,
 		"prompt_preview", prompt,
 	)
+
+	settings, ok := h.settings()
+	if !ok {
+		return &HookOutput{}, nil
+	}
+
+	reason, warning := h.blockReason(settings, input.Prompt)
+	if reason != "" {
+		slog.Warn("user prompt blocked", "session_id", input.SessionID, "reason", reason)
+		return NewUserPromptBlockOutput(reason), nil
+	}
+	if warning != "" {
+		slog.Warn("user prompt may contain sensitive data", "session_id", input.SessionID, "reason", warning)
+	}
+
+	additional := h.context(settings, input)
+	if warning != "" {
+		additional = strings.TrimSpace(warning + "\n\n" + additional)
+	}
+	if additional != "" {
+		return NewUserPromptSubmitOutput(additional), nil
+	}
 	return &HookOutput{}, nil
 }
+
+// context returns the resolved prompt context, or "" if context injection
+// is disabled or the prompt is outside a MoAI project.
+func (h *userPromptSubmitHandler) context(settings config.PromptConfig, input *HookInput) string {
+	if !settings.ContextInjection {
+		return ""
+	}
+	projectDir := moaiProjectDir(input)
+	if projectDir == "" {
+		return ""
+	}
+	budget := settings.TokenBudget
+	if budget <= 0 {
+		budget = config.DefaultPromptTokenBudget
+	}
+	pc := promptContext{projectDir: projectDir, linker: h.linker}
+	return pc.build(input.Prompt, budget)
+}
+
+// settings returns the prompt configuration, or false if the handler was
+// created without one.
+func (h *userPromptSubmitHandler) settings() (config.PromptConfig, bool) {
+	if h.cfg == nil {
+		return config.PromptConfig{}, false
+	}
+	cfg := h.cfg.Get()
+	if cfg == nil {
+		return config.NewDefaultPromptConfig(), true
+	}
+	return cfg.Prompt, true
+}
+
+// publicContentRules are the content rules that do not apply to prompts.
+// They flag material that is sensitive in files but public by nature, like
+// a certificate, which users paste into prompts to ask about it.
+var publicContentRules = []string{"content.certificate"}
+
+// blockReason returns why prompt must be rejected, or "" if it may proceed.
+// Only deny content rules for private keys and credentials, and the
+// blocklist block. An ask content rule, such
+// as a high-entropy string, cannot be confirmed for a prompt and may be a
+// false positive, so it is returned as warning instead.
+func (h *userPromptSubmitHandler) blockReason(settings config.PromptConfig, prompt string) (reason, warning string) {
+	if prompt == "" {
+		return "", ""
+	}
+	if settings.BlockSecrets && h.policy != nil {
+		rules := slices.DeleteFunc(slices.Clone(h.policy.ContentRules), func(r SecurityRule) bool {
+			return slices.Contains(publicContentRules, r.ID)
+		})
+		if rule := h.policy.evaluate(rules, matchTarget{}, prompt); rule != nil {
+			payload := contentPayload{field: "prompt", text: prompt}
+			if rule.Severity == SeverityDeny {
+				return contentRuleReason(rule, payload,
+					"Prompt contains sensitive data (private keys, credentials, or API keys). Remove it and resubmit"), ""
+			}
+			warning = contentRuleReason(rule, payload,
+				"Prompt may contain sensitive data. Do not copy it into files, commands or output")
+		}
+	}
+	for _, pattern := range settings.Blocklist {
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			slog.Warn("invalid prompt blocklist pattern", "pattern", pattern, "error", err)
+			continue
+		}
+		if re.MatchString(prompt) {
+			return fmt.Sprintf("Prompt matches the blocklist pattern %q in prompt.yaml", pattern), ""
+		}
+	}
+	return "", warning
+}
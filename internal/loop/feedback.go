@@ -27,13 +27,19 @@ func IsStagnant(prev, curr *Feedback) bool {
 // criteria: zero test failures, zero lint errors, build success,
 // and coverage at or above DefaultCoverageTarget (85%).
 func MeetsQualityGate(fb *Feedback) bool {
+	return MeetsQualityGateAt(fb, DefaultCoverageTarget)
+}
+
+// MeetsQualityGateAt is MeetsQualityGate with coverageTarget as the
+// minimum coverage percentage.
+func MeetsQualityGateAt(fb *Feedback, coverageTarget float64) bool {
 	if fb == nil {
 		return false
 	}
 	return fb.TestsFailed == 0 &&
 		fb.LintErrors == 0 &&
 		fb.BuildSuccess &&
-		fb.Coverage >= DefaultCoverageTarget
+		fb.Coverage >= coverageTarget
 }
 
 // FindPreviousReviewFeedback searches the feedback history for the most
@@ -210,6 +210,18 @@ func TestMeetsQualityGate(t *testing.T) {
 	}
 }
 
+func TestMeetsQualityGateAt(t *testing.T) {
+	t.Parallel()
+
+	fb := &Feedback{BuildSuccess: true, Coverage: 72.0}
+	if !MeetsQualityGateAt(fb, 70) {
+		t.Error("MeetsQualityGateAt(72%, 70) = false, want true")
+	}
+	if MeetsQualityGateAt(fb, 80) {
+		t.Error("MeetsQualityGateAt(72%, 80) = true, want false")
+	}
+}
+
 func TestFindPreviousReviewFeedback(t *testing.T) {
 	t.Parallel()
 
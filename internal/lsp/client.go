@@ -3,8 +3,11 @@ package lsp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 )
 
 // Initializer handles LSP server lifecycle management.
@@ -46,6 +49,56 @@ type HoverProvider interface {
 type SymbolsProvider interface {
 	// Symbols returns the document symbols for the given document URI.
 	Symbols(ctx context.Context, uri string) ([]DocumentSymbol, error)
+
+	// WorkspaceSymbols searches the symbols of the workspace matching query.
+	WorkspaceSymbols(ctx context.Context, query string) ([]SymbolInformation, error)
+}
+
+// RefactoringProvider provides edits computed by the server.
+// Use this interface when you only need rename, code actions or formatting.
+// The returned edits are not applied; see PreviewWorkspaceEdit.
+type RefactoringProvider interface {
+	// Rename returns the edits renaming the symbol at the given position.
+	Rename(ctx context.Context, uri string, pos Position, newName string) (*WorkspaceEdit, error)
+
+	// CodeActions returns the fixes and refactorings offered for rng,
+	// given the diagnostics the server published for it.
+	CodeActions(ctx context.Context, uri string, rng Range) ([]CodeAction, error)
+
+	// ResolveCodeAction fills in the edit of an action offered for the
+	// document uri that the server resolves lazily. Actions that need no
+	// resolving are returned unchanged.
+	ResolveCodeAction(ctx context.Context, uri string, action CodeAction) (*CodeAction, error)
+
+	// Format returns the edits formatting the given document.
+	Format(ctx context.Context, uri string, opts FormattingOptions) ([]TextEdit, error)
+}
+
+// CodeIntelligence composes the navigation and refactoring features that
+// both a Client and the LSP daemon (see DaemonClient.Intelligence) serve.
+type CodeIntelligence interface {
+	NavigationProvider
+	HoverProvider
+	SymbolsProvider
+	RefactoringProvider
+}
+
+// DocumentSyncer keeps the documents seen by the server in sync with the
+// client. Requests about a document open it from disk on their own; use
+// this interface to send unsaved content or to close documents.
+type DocumentSyncer interface {
+	// OpenDocument opens the file of uri on the server, or sends its
+	// content as a new version when it changed since it was last sent.
+	OpenDocument(ctx context.Context, uri string) error
+
+	// ChangeDocument sends text as the content of uri, opening it when needed.
+	ChangeDocument(ctx context.Context, uri, text string) error
+
+	// SaveDocument notifies the server that the open document uri was saved.
+	SaveDocument(ctx context.Context, uri string) error
+
+	// CloseDocument closes uri on the server.
+	CloseDocument(ctx context.Context, uri string) error
 }
 
 // Client composes all LSP capabilities and communicates with a single
@@ -58,7 +111,9 @@ type SymbolsProvider interface {
 //   - DiagnosticsProvider: Diagnostic retrieval
 //   - NavigationProvider: References and Definition
 //   - HoverProvider: Hover information
-//   - SymbolsProvider: Document symbols
+//   - SymbolsProvider: Document and workspace symbols
+//   - RefactoringProvider: Rename, code actions and formatting
+//   - DocumentSyncer: Document synchronization
 //
 // Example usage:
 //
@@ -72,6 +127,8 @@ type Client interface {
 	NavigationProvider
 	HoverProvider
 	SymbolsProvider
+	RefactoringProvider
+	DocumentSyncer
 }
 
 // lspClient implements the Client interface using a Conn for JSON-RPC communication.
@@ -79,6 +136,15 @@ type lspClient struct {
 	conn         Conn
 	initialized  bool
 	capabilities json.RawMessage
+	caps         serverCapabilities
+	rootURI      string
+
+	settings           map[string]any
+	diagnosticsSettle  time.Duration
+	diagnosticsTimeout time.Duration
+
+	docs        documentStore
+	diagnostics *diagnosticsCache
 }
 
 // Compile-time interface compliance checks.
@@ -89,20 +155,87 @@ var (
 	_ NavigationProvider  = (*lspClient)(nil)
 	_ HoverProvider       = (*lspClient)(nil)
 	_ SymbolsProvider     = (*lspClient)(nil)
+	_ RefactoringProvider = (*lspClient)(nil)
+	_ CodeIntelligence    = (*lspClient)(nil)
+	_ DocumentSyncer      = (*lspClient)(nil)
 	_ Client              = (*lspClient)(nil)
 )
 
-// NewClient creates a new LSP Client that communicates over the given connection.
-func NewClient(conn Conn) Client {
-	return &lspClient{conn: conn}
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*lspClient)
+
+// WithDiagnosticsSettle sets how long pushed diagnostics must stay
+// unchanged before Diagnostics returns them (default DefaultDiagnosticsSettle).
+func WithDiagnosticsSettle(d time.Duration) ClientOption {
+	return func(c *lspClient) {
+		c.diagnosticsSettle = d
+	}
+}
+
+// WithDiagnosticsTimeout bounds how long Diagnostics waits for pushed
+// diagnostics (default DefaultDiagnosticsTimeout).
+func WithDiagnosticsTimeout(d time.Duration) ClientOption {
+	return func(c *lspClient) {
+		c.diagnosticsTimeout = d
+	}
+}
+
+// WithSettings sets the answers to workspace/configuration requests, keyed
+// by configuration section (e.g. "gopls"). Unknown sections get null.
+func WithSettings(settings map[string]any) ClientOption {
+	return func(c *lspClient) {
+		c.settings = settings
+	}
+}
+
+// NewClient creates a new LSP Client that communicates over the given
+// connection. It registers the handlers for the notifications and requests
+// the server sends to the client, such as published diagnostics.
+func NewClient(conn Conn, opts ...ClientOption) Client {
+	c := &lspClient{
+		conn:               conn,
+		diagnosticsSettle:  DefaultDiagnosticsSettle,
+		diagnosticsTimeout: DefaultDiagnosticsTimeout,
+		docs:               documentStore{docs: make(map[string]*openDocument)},
+		diagnostics:        newDiagnosticsCache(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	conn.OnNotification("textDocument/publishDiagnostics", c.diagnostics.publish)
+	conn.OnRequest("workspace/configuration", c.configuration)
+	conn.OnRequest("workspace/workspaceFolders", c.workspaceFolders)
+	for _, method := range []string{
+		"window/workDoneProgress/create",
+		"window/showMessageRequest",
+		"client/registerCapability",
+		"client/unregisterCapability",
+		"workspace/diagnostic/refresh",
+	} {
+		conn.OnRequest(method, acknowledge)
+	}
+	return c
 }
 
 // --- LSP parameter types (internal, not exported) ---
 
 type initializeParams struct {
-	ProcessID    int            `json:"processId"`
-	RootURI      string         `json:"rootUri"`
-	Capabilities map[string]any `json:"capabilities"`
+	ProcessID        int               `json:"processId"`
+	RootURI          string            `json:"rootUri"`
+	Capabilities     map[string]any    `json:"capabilities"`
+	WorkspaceFolders []workspaceFolder `json:"workspaceFolders,omitempty"`
+}
+
+type workspaceFolder struct {
+	URI  string `json:"uri"`
+	Name string `json:"name"`
+}
+
+type configurationParams struct {
+	Items []struct {
+		Section string `json:"section"`
+	} `json:"items"`
 }
 
 type textDocumentIdentifier struct {
@@ -128,12 +261,115 @@ type documentParams struct {
 	TextDocument textDocumentIdentifier `json:"textDocument"`
 }
 
+type workspaceSymbolParams struct {
+	Query string `json:"query"`
+}
+
+type renameParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+	NewName      string                 `json:"newName"`
+}
+
+type codeActionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Context      codeActionContext      `json:"context"`
+}
+
+type codeActionContext struct {
+	Diagnostics []json.RawMessage `json:"diagnostics"`
+	TriggerKind int               `json:"triggerKind"`
+}
+
+type formattingParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Options      FormattingOptions      `json:"options"`
+}
+
 // --- LSP response types (internal, not exported) ---
 
 type initializeResponse struct {
 	Capabilities json.RawMessage `json:"capabilities"`
 }
 
+// serverCapabilities holds the server capabilities the client acts on.
+type serverCapabilities struct {
+	TextDocumentSync   json.RawMessage `json:"textDocumentSync"`
+	DiagnosticProvider json.RawMessage `json:"diagnosticProvider"`
+	CodeActionProvider json.RawMessage `json:"codeActionProvider"`
+}
+
+// resolveCodeActions reports whether the server resolves code actions
+// lazily through codeAction/resolve.
+func (s serverCapabilities) resolveCodeActions() bool {
+	var options struct {
+		ResolveProvider bool `json:"resolveProvider"`
+	}
+	return json.Unmarshal(s.CodeActionProvider, &options) == nil && options.ResolveProvider
+}
+
+// saveSupport reports whether the server wants didSave notifications and
+// whether they should include the document text.
+func (s serverCapabilities) saveSupport() (send, includeText bool) {
+	var sync struct {
+		Save json.RawMessage `json:"save"`
+	}
+	if json.Unmarshal(s.TextDocumentSync, &sync) != nil || len(sync.Save) == 0 {
+		return false, false
+	}
+	var enabled bool
+	if json.Unmarshal(sync.Save, &enabled) == nil {
+		return enabled, false
+	}
+	var options struct {
+		IncludeText bool `json:"includeText"`
+	}
+	if json.Unmarshal(sync.Save, &options) != nil {
+		return false, false
+	}
+	return true, options.IncludeText
+}
+
+// pullDiagnostics reports whether the server advertises pull diagnostics.
+func (s serverCapabilities) pullDiagnostics() bool {
+	return len(s.DiagnosticProvider) > 0 && string(s.DiagnosticProvider) != "null" &&
+		string(s.DiagnosticProvider) != "false"
+}
+
+// clientCapabilities are the capabilities declared in the initialize request.
+var clientCapabilities = map[string]any{
+	"textDocument": map[string]any{
+		"synchronization":    map[string]any{"didSave": true},
+		"publishDiagnostics": map[string]any{"versionSupport": true},
+		"diagnostic":         map[string]any{},
+		"hover":              map[string]any{"contentFormat": []string{"markdown", "plaintext"}},
+		"documentSymbol":     map[string]any{"hierarchicalDocumentSymbolSupport": true},
+		"rename":             map[string]any{},
+		"formatting":         map[string]any{},
+		"codeAction": map[string]any{
+			"codeActionLiteralSupport": map[string]any{
+				"codeActionKind": map[string]any{"valueSet": []string{
+					"quickfix", "refactor", "refactor.extract", "refactor.inline",
+					"refactor.rewrite", "source", "source.organizeImports", "source.fixAll",
+				}},
+			},
+			"isPreferredSupport": true,
+			"dataSupport":        true,
+			"resolveSupport":     map[string]any{"properties": []string{"edit"}},
+		},
+	},
+	"workspace": map[string]any{
+		"configuration":    true,
+		"workspaceFolders": true,
+		"symbol":           map[string]any{},
+		"workspaceEdit":    map[string]any{"documentChanges": true},
+	},
+	"window": map[string]any{
+		"workDoneProgress": true,
+	},
+}
+
 type diagnosticReport struct {
 	Kind  string       `json:"kind"`
 	Items []Diagnostic `json:"items"`
@@ -148,10 +384,12 @@ type hoverResponse struct {
 
 // Initialize performs the LSP initialize handshake.
 func (c *lspClient) Initialize(ctx context.Context, rootURI string) error {
+	c.rootURI = rootURI
 	params := initializeParams{
-		ProcessID:    os.Getpid(),
-		RootURI:      rootURI,
-		Capabilities: map[string]any{},
+		ProcessID:        os.Getpid(),
+		RootURI:          rootURI,
+		Capabilities:     clientCapabilities,
+		WorkspaceFolders: c.folders(),
 	}
 
 	var result initializeResponse
@@ -160,6 +398,9 @@ func (c *lspClient) Initialize(ctx context.Context, rootURI string) error {
 	}
 
 	c.capabilities = result.Capabilities
+	if len(result.Capabilities) > 0 {
+		_ = json.Unmarshal(result.Capabilities, &c.caps)
+	}
 	c.initialized = true
 
 	// Send initialized notification.
@@ -170,8 +411,29 @@ func (c *lspClient) Initialize(ctx context.Context, rootURI string) error {
 	return nil
 }
 
-// Diagnostics retrieves diagnostics for the given document URI using pull diagnostics.
+// Diagnostics retrieves diagnostics for the given document URI after
+// syncing it from disk. Servers advertising pull diagnostics are asked
+// with textDocument/diagnostic; for the others, and those answering it with
+// MethodNotFound, Diagnostics waits for the diagnostics the server
+// publishes for the synced version to settle.
 func (c *lspClient) Diagnostics(ctx context.Context, uri string) ([]Diagnostic, error) {
+	doc, err := c.ensureOpen(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.initialized || c.caps.pullDiagnostics() {
+		diagnostics, err := c.pullDiagnostics(ctx, uri)
+		var rpcErr *JSONRPCError
+		if err == nil || !errors.As(err, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+			return diagnostics, err
+		}
+	}
+	return c.diagnostics.wait(ctx, uri, doc.version, doc.mark, c.diagnosticsSettle, c.diagnosticsTimeout)
+}
+
+// pullDiagnostics requests the diagnostics of uri with textDocument/diagnostic.
+func (c *lspClient) pullDiagnostics(ctx context.Context, uri string) ([]Diagnostic, error) {
 	params := documentParams{
 		TextDocument: textDocumentIdentifier{URI: uri},
 	}
@@ -189,6 +451,10 @@ func (c *lspClient) Diagnostics(ctx context.Context, uri string) ([]Diagnostic,
 
 // References returns all reference locations for the symbol at the given position.
 func (c *lspClient) References(ctx context.Context, uri string, pos Position) ([]Location, error) {
+	if _, err := c.ensureOpen(ctx, uri); err != nil {
+		return nil, err
+	}
+
 	params := referenceParams{
 		TextDocument: textDocumentIdentifier{URI: uri},
 		Position:     pos,
@@ -208,6 +474,10 @@ func (c *lspClient) References(ctx context.Context, uri string, pos Position) ([
 
 // Hover returns hover information for the symbol at the given position.
 func (c *lspClient) Hover(ctx context.Context, uri string, pos Position) (*HoverResult, error) {
+	if _, err := c.ensureOpen(ctx, uri); err != nil {
+		return nil, err
+	}
+
 	params := textDocumentPositionParams{
 		TextDocument: textDocumentIdentifier{URI: uri},
 		Position:     pos,
@@ -228,6 +498,10 @@ func (c *lspClient) Hover(ctx context.Context, uri string, pos Position) (*Hover
 
 // Definition returns the definition location(s) for the symbol at the given position.
 func (c *lspClient) Definition(ctx context.Context, uri string, pos Position) ([]Location, error) {
+	if _, err := c.ensureOpen(ctx, uri); err != nil {
+		return nil, err
+	}
+
 	params := textDocumentPositionParams{
 		TextDocument: textDocumentIdentifier{URI: uri},
 		Position:     pos,
@@ -261,21 +535,145 @@ func (c *lspClient) Definition(ctx context.Context, uri string, pos Position) ([
 
 // Symbols returns the document symbols for the given document URI.
 func (c *lspClient) Symbols(ctx context.Context, uri string) ([]DocumentSymbol, error) {
+	if _, err := c.ensureOpen(ctx, uri); err != nil {
+		return nil, err
+	}
+
 	params := documentParams{
 		TextDocument: textDocumentIdentifier{URI: uri},
 	}
 
-	var symbols []DocumentSymbol
-	if err := c.conn.Call(ctx, "textDocument/documentSymbol", params, &symbols); err != nil {
+	// Servers without hierarchical support answer with SymbolInformation[],
+	// which carry a location instead of ranges.
+	var raw []struct {
+		DocumentSymbol
+		Location *Location `json:"location"`
+	}
+	if err := c.conn.Call(ctx, "textDocument/documentSymbol", params, &raw); err != nil {
 		return nil, fmt.Errorf("textDocument/documentSymbol: %w", err)
 	}
 
+	symbols := make([]DocumentSymbol, len(raw))
+	for i, s := range raw {
+		symbols[i] = s.DocumentSymbol
+		if s.Location != nil {
+			symbols[i].Range = s.Location.Range
+			symbols[i].SelectionRange = s.Location.Range
+		}
+	}
+	return symbols, nil
+}
+
+// WorkspaceSymbols searches the symbols of the workspace matching query.
+func (c *lspClient) WorkspaceSymbols(ctx context.Context, query string) ([]SymbolInformation, error) {
+	var symbols []SymbolInformation
+	if err := c.conn.Call(ctx, "workspace/symbol", workspaceSymbolParams{Query: query}, &symbols); err != nil {
+		return nil, fmt.Errorf("workspace/symbol: %w", err)
+	}
+
 	if symbols == nil {
-		return []DocumentSymbol{}, nil
+		return []SymbolInformation{}, nil
 	}
 	return symbols, nil
 }
 
+// Rename returns the edits renaming the symbol at the given position. A
+// position the server cannot rename yields an edit without changes.
+func (c *lspClient) Rename(ctx context.Context, uri string, pos Position, newName string) (*WorkspaceEdit, error) {
+	if _, err := c.ensureOpen(ctx, uri); err != nil {
+		return nil, err
+	}
+
+	params := renameParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Position:     pos,
+		NewName:      newName,
+	}
+	var edit *WorkspaceEdit
+	if err := c.conn.Call(ctx, "textDocument/rename", params, &edit); err != nil {
+		return nil, fmt.Errorf("textDocument/rename: %w", err)
+	}
+
+	if edit == nil {
+		return &WorkspaceEdit{Changes: map[string][]TextEdit{}}, nil
+	}
+	return edit, nil
+}
+
+// CodeActions returns the fixes and refactorings offered for rng. The
+// diagnostics published for rng are passed along so that servers offer
+// their quick fixes.
+func (c *lspClient) CodeActions(ctx context.Context, uri string, rng Range) ([]CodeAction, error) {
+	if _, err := c.ensureOpen(ctx, uri); err != nil {
+		return nil, err
+	}
+
+	params := codeActionParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Range:        rng,
+		Context: codeActionContext{
+			Diagnostics: c.diagnostics.overlapping(uri, rng),
+			TriggerKind: 1, // Invoked
+		},
+	}
+	var raw []json.RawMessage
+	if err := c.conn.Call(ctx, "textDocument/codeAction", params, &raw); err != nil {
+		return nil, fmt.Errorf("textDocument/codeAction: %w", err)
+	}
+
+	// Each item is a CodeAction or a bare Command, whose command field is
+	// a string.
+	actions := make([]CodeAction, 0, len(raw))
+	for _, item := range raw {
+		var command Command
+		if err := json.Unmarshal(item, &command); err == nil && command.Command != "" {
+			actions = append(actions, CodeAction{Title: command.Title, Command: &command})
+			continue
+		}
+		var action CodeAction
+		if err := json.Unmarshal(item, &action); err != nil {
+			return nil, fmt.Errorf("textDocument/codeAction: %w", err)
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// ResolveCodeAction resolves action through codeAction/resolve when it has
+// no edit yet and the server supports resolving.
+func (c *lspClient) ResolveCodeAction(ctx context.Context, _ string, action CodeAction) (*CodeAction, error) {
+	if action.Edit != nil || !c.caps.resolveCodeActions() {
+		return &action, nil
+	}
+
+	var resolved CodeAction
+	if err := c.conn.Call(ctx, "codeAction/resolve", action, &resolved); err != nil {
+		return nil, fmt.Errorf("codeAction/resolve: %w", err)
+	}
+	return &resolved, nil
+}
+
+// Format returns the edits formatting the given document.
+func (c *lspClient) Format(ctx context.Context, uri string, opts FormattingOptions) ([]TextEdit, error) {
+	if _, err := c.ensureOpen(ctx, uri); err != nil {
+		return nil, err
+	}
+
+	params := formattingParams{
+		TextDocument: textDocumentIdentifier{URI: uri},
+		Options:      opts,
+	}
+	var edits []TextEdit
+	if err := c.conn.Call(ctx, "textDocument/formatting", params, &edits); err != nil {
+		return nil, fmt.Errorf("textDocument/formatting: %w", err)
+	}
+
+	if edits == nil {
+		return []TextEdit{}, nil
+	}
+	return edits, nil
+}
+
 // Shutdown sends the LSP shutdown request followed by an exit notification.
 func (c *lspClient) Shutdown(ctx context.Context) error {
 	if err := c.conn.Call(ctx, "shutdown", nil, nil); err != nil {
@@ -289,6 +687,39 @@ func (c *lspClient) Shutdown(ctx context.Context) error {
 	return c.conn.Close()
 }
 
+// configuration answers workspace/configuration with the settings of each
+// requested section, null for unknown ones.
+func (c *lspClient) configuration(_ context.Context, params json.RawMessage) (any, error) {
+	var p configurationParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &JSONRPCError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	result := make([]any, len(p.Items))
+	for i, item := range p.Items {
+		result[i] = c.settings[item.Section]
+	}
+	return result, nil
+}
+
+// workspaceFolders answers workspace/workspaceFolders with the root passed
+// to Initialize.
+func (c *lspClient) workspaceFolders(_ context.Context, _ json.RawMessage) (any, error) {
+	return c.folders(), nil
+}
+
+// folders returns the workspace folder of the root URI, nil before Initialize.
+func (c *lspClient) folders() []workspaceFolder {
+	if c.rootURI == "" {
+		return nil
+	}
+	return []workspaceFolder{{URI: c.rootURI, Name: filepath.Base(URIToPath(c.rootURI))}}
+}
+
+// acknowledge answers server requests that need no more than a null result.
+func acknowledge(context.Context, json.RawMessage) (any, error) {
+	return nil, nil
+}
+
 // parseHoverContents extracts a string from various LSP hover content formats.
 func parseHoverContents(raw json.RawMessage) string {
 	// Try MarkupContent: {"kind":"markdown","value":"..."}
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 // mockConn implements Conn for testing the Client.
@@ -17,6 +21,8 @@ type mockConn struct {
 	mu       sync.Mutex
 	calls    []mockCall
 	notifies []mockNotify
+	handlers map[string]NotificationHandler
+	requests map[string]RequestHandler
 }
 
 type mockCall struct {
@@ -26,6 +32,7 @@ type mockCall struct {
 
 type mockNotify struct {
 	Method string
+	Params json.RawMessage
 }
 
 func (m *mockConn) Call(ctx context.Context, method string, params any, result any) error {
@@ -42,10 +49,11 @@ func (m *mockConn) Call(ctx context.Context, method string, params any, result a
 }
 
 func (m *mockConn) Notify(ctx context.Context, method string, params any) error {
+	p, _ := json.Marshal(params) //nolint:errcheck // test helper, marshal always succeeds for test data
 	func() {
 		m.mu.Lock()
 		defer m.mu.Unlock()
-		m.notifies = append(m.notifies, mockNotify{Method: method})
+		m.notifies = append(m.notifies, mockNotify{Method: method, Params: p})
 	}()
 	if m.notifyFn != nil {
 		return m.notifyFn(ctx, method, params)
@@ -53,6 +61,47 @@ func (m *mockConn) Notify(ctx context.Context, method string, params any) error
 	return nil
 }
 
+func (m *mockConn) OnNotification(method string, handler NotificationHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.handlers == nil {
+		m.handlers = make(map[string]NotificationHandler)
+	}
+	m.handlers[method] = handler
+}
+
+func (m *mockConn) OnRequest(method string, handler RequestHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.requests == nil {
+		m.requests = make(map[string]RequestHandler)
+	}
+	m.requests[method] = handler
+}
+
+// serverNotify delivers a notification from the server to the registered handler.
+func (m *mockConn) serverNotify(t *testing.T, method, params string) {
+	t.Helper()
+	m.mu.Lock()
+	handler := m.handlers[method]
+	m.mu.Unlock()
+	if handler == nil {
+		t.Fatalf("no handler registered for %s", method)
+	}
+	handler(context.Background(), json.RawMessage(params))
+}
+
+// notified returns the methods notified so far.
+func (m *mockConn) notified() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	methods := make([]string, len(m.notifies))
+	for i, n := range m.notifies {
+		methods[i] = n.Method
+	}
+	return methods
+}
+
 func (m *mockConn) Close() error {
 	if m.closeFn != nil {
 		return m.closeFn()
@@ -653,6 +702,342 @@ func TestClientMethodsCallCorrectLSPMethod(t *testing.T) {
 	}
 }
 
+// pushServerConn returns a mockConn initialized as a server that pushes
+// diagnostics and asks for didSave notifications.
+func pushServerConn() *mockConn {
+	return &mockConn{
+		callFn: func(_ context.Context, method string, _ any, result any) error {
+			switch method {
+			case "initialize":
+				data := []byte(`{"capabilities":{"textDocumentSync":{"openClose":true,"change":1,"save":{"includeText":false}}}}`)
+				return json.Unmarshal(data, result)
+			case "textDocument/diagnostic":
+				return &JSONRPCError{Code: CodeMethodNotFound, Message: "method not found"}
+			}
+			return nil
+		},
+	}
+}
+
+// writeSource writes a source file in a temp dir and returns its URI.
+func writeSource(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return FileURI(path)
+}
+
+// waitNotified waits until n notifications were sent.
+func waitNotified(t *testing.T, mock *mockConn, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		got := mock.notified()
+		if len(got) >= n {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("notifications = %v, want %d", got, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClientDiagnosticsPush(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	uri := writeSource(t, dir, "package main\n")
+	mock := pushServerConn()
+	client := NewClient(mock, WithDiagnosticsSettle(20*time.Millisecond), WithDiagnosticsTimeout(5*time.Second))
+	ctx := context.Background()
+	if err := client.Initialize(ctx, FileURI(dir)); err != nil {
+		t.Fatalf("Initialize error: %v", err)
+	}
+
+	type result struct {
+		diagnostics []Diagnostic
+		err         error
+	}
+	diagnose := func() chan result {
+		ch := make(chan result, 1)
+		go func() {
+			diagnostics, err := client.Diagnostics(ctx, uri)
+			ch <- result{diagnostics, err}
+		}()
+		return ch
+	}
+
+	ch := diagnose()
+	got := waitNotified(t, mock, 2)
+	if got[1] != "textDocument/didOpen" {
+		t.Fatalf("notifications = %v, want didOpen after initialized", got)
+	}
+	mock.serverNotify(t, "textDocument/publishDiagnostics",
+		`{"uri":"`+uri+`","version":1,"diagnostics":[{"severity":1,"code":1001,"message":"first"}]}`)
+	res := <-ch
+	if res.err != nil || len(res.diagnostics) != 1 || res.diagnostics[0].Message != "first" || res.diagnostics[0].Code != "1001" {
+		t.Fatalf("Diagnostics = %+v, %v", res.diagnostics, res.err)
+	}
+
+	// A changed file is sent as version 2; diagnostics still published
+	// for version 1 are not returned for it.
+	writeSource(t, dir, "package main\n\nfunc main() {}\n")
+	ch = diagnose()
+	got = waitNotified(t, mock, 4)
+	if got[2] != "textDocument/didChange" || got[3] != "textDocument/didSave" {
+		t.Fatalf("notifications = %v, want didChange and didSave", got)
+	}
+	mock.serverNotify(t, "textDocument/publishDiagnostics", `{"uri":"`+uri+`","version":1,"diagnostics":[]}`)
+	mock.serverNotify(t, "textDocument/publishDiagnostics",
+		`{"uri":"`+uri+`","version":2,"diagnostics":[{"severity":2,"message":"second"}]}`)
+	res = <-ch
+	if res.err != nil || len(res.diagnostics) != 1 || res.diagnostics[0].Message != "second" {
+		t.Fatalf("Diagnostics after change = %+v, %v", res.diagnostics, res.err)
+	}
+
+	var change didChangeTextDocumentParams
+	mock.mu.Lock()
+	_ = json.Unmarshal(mock.notifies[2].Params, &change)
+	mock.mu.Unlock()
+	if change.TextDocument.Version != 2 || len(change.ContentChanges) != 1 || !strings.Contains(change.ContentChanges[0].Text, "func main") {
+		t.Errorf("didChange = %+v, want the full text as version 2", change)
+	}
+
+	// An unchanged file is not sent again.
+	if _, err := client.Diagnostics(ctx, uri); err != nil {
+		t.Fatalf("Diagnostics unchanged error: %v", err)
+	}
+	if got := mock.notified(); len(got) != 4 {
+		t.Errorf("notifications = %v, want no new ones", got)
+	}
+}
+
+func TestClientDiagnosticsPushTimeout(t *testing.T) {
+	t.Parallel()
+
+	uri := writeSource(t, t.TempDir(), "package main\n")
+	client := NewClient(pushServerConn(), WithDiagnosticsTimeout(30*time.Millisecond))
+	if err := client.Initialize(context.Background(), "file:///project"); err != nil {
+		t.Fatalf("Initialize error: %v", err)
+	}
+
+	_, err := client.Diagnostics(context.Background(), uri)
+	if !errors.Is(err, ErrDiagnosticsTimeout) {
+		t.Errorf("error = %v, want ErrDiagnosticsTimeout", err)
+	}
+}
+
+func TestClientDiagnosticsPushStale(t *testing.T) {
+	t.Parallel()
+
+	uri := writeSource(t, t.TempDir(), "package main\n")
+	mock := pushServerConn()
+	client := NewClient(mock, WithDiagnosticsTimeout(30*time.Millisecond))
+	if err := client.Initialize(context.Background(), "file:///project"); err != nil {
+		t.Fatalf("Initialize error: %v", err)
+	}
+	mock.serverNotify(t, "textDocument/publishDiagnostics",
+		`{"uri":"`+uri+`","version":0,"diagnostics":[{"severity":1,"message":"old"}]}`)
+
+	diagnostics, err := client.Diagnostics(context.Background(), uri)
+	if !errors.Is(err, ErrStaleDiagnostics) || diagnostics != nil {
+		t.Errorf("Diagnostics = %+v, %v; want ErrStaleDiagnostics", diagnostics, err)
+	}
+}
+
+func TestClientDiagnosticsPullFallback(t *testing.T) {
+	t.Parallel()
+
+	mock := pushServerConn()
+	client := NewClient(mock, WithDiagnosticsSettle(0), WithDiagnosticsTimeout(time.Second))
+	mock.serverNotify(t, "textDocument/publishDiagnostics",
+		`{"uri":"file:///project/my%20file.go","diagnostics":[{"severity":1,"message":"pushed"}]}`)
+
+	diagnostics, err := client.Diagnostics(context.Background(), "file:///project/my file.go")
+	if err != nil || len(diagnostics) != 1 || diagnostics[0].Message != "pushed" {
+		t.Errorf("Diagnostics = %+v, %v, want the pushed diagnostic", diagnostics, err)
+	}
+	if len(mock.calls) != 1 || mock.calls[0].Method != "textDocument/diagnostic" {
+		t.Errorf("calls = %+v, want a pull attempt first", mock.calls)
+	}
+}
+
+func TestClientDocumentSync(t *testing.T) {
+	t.Parallel()
+
+	uri := writeSource(t, t.TempDir(), "package main\n")
+	mock := pushServerConn()
+	client := NewClient(mock)
+	ctx := context.Background()
+
+	if err := client.SaveDocument(ctx, uri); err == nil {
+		t.Error("SaveDocument of a closed document succeeded")
+	}
+	if err := client.ChangeDocument(ctx, uri, "package edited\n"); err != nil {
+		t.Fatalf("ChangeDocument error: %v", err)
+	}
+	// Requests keep the unsaved content instead of the file on disk.
+	if _, err := client.Hover(ctx, uri, Position{}); err != nil {
+		t.Fatalf("Hover error: %v", err)
+	}
+	if err := client.OpenDocument(ctx, uri); err != nil {
+		t.Fatalf("OpenDocument error: %v", err)
+	}
+	if err := client.CloseDocument(ctx, uri); err != nil {
+		t.Fatalf("CloseDocument error: %v", err)
+	}
+	if err := client.CloseDocument(ctx, uri); err != nil {
+		t.Fatalf("second CloseDocument error: %v", err)
+	}
+
+	want := []string{"textDocument/didOpen", "textDocument/didChange", "textDocument/didClose"}
+	if got := mock.notified(); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("notifications = %v, want %v", got, want)
+	}
+	var open didOpenTextDocumentParams
+	_ = json.Unmarshal(mock.notifies[0].Params, &open)
+	if open.TextDocument.LanguageID != "go" || open.TextDocument.Version != 1 || open.TextDocument.Text != "package edited\n" {
+		t.Errorf("didOpen = %+v", open.TextDocument)
+	}
+}
+
+func TestClientServerRequests(t *testing.T) {
+	t.Parallel()
+
+	mock := &mockConn{}
+	settings := map[string]any{"gopls": map[string]any{"staticcheck": true}}
+	client := NewClient(mock, WithSettings(settings))
+	if err := client.Initialize(context.Background(), "file:///work/project"); err != nil {
+		t.Fatalf("Initialize error: %v", err)
+	}
+
+	var init initializeParams
+	_ = json.Unmarshal(mock.calls[0].Params, &init)
+	workspace, _ := init.Capabilities["workspace"].(map[string]any)
+	if workspace["configuration"] != true || len(init.WorkspaceFolders) != 1 {
+		t.Errorf("initialize params = %+v, want configuration support and a workspace folder", init)
+	}
+
+	answer := func(method, params string) string {
+		t.Helper()
+		handler := mock.requests[method]
+		if handler == nil {
+			t.Fatalf("no handler for %s", method)
+		}
+		result, err := handler(context.Background(), json.RawMessage(params))
+		if err != nil {
+			t.Fatalf("%s error: %v", method, err)
+		}
+		data, _ := json.Marshal(result)
+		return string(data)
+	}
+
+	if got := answer("workspace/configuration", `{"items":[{"section":"gopls"},{"section":"other"}]}`); got != `[{"staticcheck":true},null]` {
+		t.Errorf("workspace/configuration = %s", got)
+	}
+	if got := answer("workspace/workspaceFolders", `null`); got != `[{"uri":"file:///work/project","name":"project"}]` {
+		t.Errorf("workspace/workspaceFolders = %s", got)
+	}
+	for _, method := range []string{"window/workDoneProgress/create", "client/registerCapability"} {
+		if got := answer(method, `{}`); got != "null" {
+			t.Errorf("%s = %s, want null", method, got)
+		}
+	}
+}
+
+func TestClientRefactoring(t *testing.T) {
+	t.Parallel()
+
+	var actionParams codeActionParams
+	mock := &mockConn{
+		callFn: func(_ context.Context, method string, params any, result any) error {
+			var data string
+			switch method {
+			case "initialize":
+				data = `{"capabilities":{"codeActionProvider":{"resolveProvider":true}}}`
+			case "textDocument/rename":
+				data = `{"changes":{"file:///project/main.go":[{"range":{"start":{"line":1,"character":0},"end":{"line":1,"character":3}},"newText":"bar"}]}}`
+			case "textDocument/codeAction":
+				actionParams = params.(codeActionParams)
+				data = `[{"title":"Organize imports","command":"source.organizeImports","arguments":["x"]},
+					{"title":"Add import","kind":"quickfix","isPreferred":true,"data":{"id":1}}]`
+			case "codeAction/resolve":
+				data = `{"title":"Add import","kind":"quickfix","edit":{"changes":{"file:///project/main.go":[]}}}`
+			case "textDocument/formatting":
+				data = `null`
+			case "workspace/symbol":
+				data = `[{"name":"Client","kind":11,"location":{"uri":"file:///project/client.go"},"containerName":"lsp"}]`
+			case "textDocument/documentSymbol":
+				data = `[{"name":"main","kind":12,"location":{"uri":"file:///project/main.go","range":{"start":{"line":2,"character":5},"end":{"line":2,"character":9}}}}]`
+			default:
+				return nil
+			}
+			return json.Unmarshal([]byte(data), result)
+		},
+	}
+	client := NewClient(mock)
+	ctx := context.Background()
+	uri := "file:///project/main.go"
+	if err := client.Initialize(ctx, "file:///project"); err != nil {
+		t.Fatalf("Initialize error: %v", err)
+	}
+
+	edit, err := client.Rename(ctx, uri, Position{Line: 1}, "bar")
+	if err != nil || len(edit.Changes[uri]) != 1 || edit.Changes[uri][0].NewText != "bar" {
+		t.Errorf("Rename() = %+v, %v", edit, err)
+	}
+
+	mock.serverNotify(t, "textDocument/publishDiagnostics", `{"uri":"`+uri+`","diagnostics":[
+		{"range":{"start":{"line":4,"character":0},"end":{"line":4,"character":5}},"severity":1,"code":2304,"message":"near"},
+		{"range":{"start":{"line":9,"character":0},"end":{"line":9,"character":5}},"severity":1,"message":"far"}]}`)
+	actions, err := client.CodeActions(ctx, uri, Range{Start: Position{4, 2}, End: Position{4, 2}})
+	if err != nil || len(actions) != 2 {
+		t.Fatalf("CodeActions() = %+v, %v", actions, err)
+	}
+	if actions[0].Command == nil || actions[0].Command.Command != "source.organizeImports" || actions[0].Edit != nil {
+		t.Errorf("bare command = %+v, want a command-only action", actions[0])
+	}
+	if diags := actionParams.Context.Diagnostics; len(diags) != 1 || !strings.Contains(string(diags[0]), `"code":2304`) {
+		t.Errorf("code action diagnostics = %s, want the raw overlapping diagnostic", diags)
+	}
+
+	resolved, err := client.ResolveCodeAction(ctx, uri, actions[1])
+	if err != nil || resolved.Edit == nil {
+		t.Errorf("ResolveCodeAction() = %+v, %v, want the resolved edit", resolved, err)
+	}
+	if again, _ := client.ResolveCodeAction(ctx, uri, *resolved); again.Title != resolved.Title {
+		t.Errorf("ResolveCodeAction() of a resolved action = %+v", again)
+	}
+
+	edits, err := client.Format(ctx, uri, FormattingOptions{TabSize: 4})
+	if err != nil || edits == nil || len(edits) != 0 {
+		t.Errorf("Format() = %v, %v, want no edits", edits, err)
+	}
+
+	symbols, err := client.WorkspaceSymbols(ctx, "Client")
+	if err != nil || len(symbols) != 1 || symbols[0].ContainerName != "lsp" || symbols[0].Kind != SymbolKindInterface {
+		t.Errorf("WorkspaceSymbols() = %+v, %v", symbols, err)
+	}
+
+	docSymbols, err := client.Symbols(ctx, uri)
+	if err != nil || len(docSymbols) != 1 || docSymbols[0].Range.Start.Line != 2 || docSymbols[0].SelectionRange.Start.Character != 5 {
+		t.Errorf("Symbols() from SymbolInformation = %+v, %v", docSymbols, err)
+	}
+
+	resolves := 0
+	for _, c := range mock.calls {
+		if c.Method == "codeAction/resolve" {
+			resolves++
+		}
+	}
+	if resolves != 1 {
+		t.Errorf("codeAction/resolve sent %d times, want 1", resolves)
+	}
+}
+
 // containsStr checks if s contains substr.
 func containsStr(s, substr string) bool {
 	return len(s) >= len(substr) && searchStr(s, substr)
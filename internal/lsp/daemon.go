@@ -0,0 +1,609 @@
+package lsp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Methods served by the LSP daemon over its unix socket.
+const (
+	// MethodDaemonDiagnostics returns the diagnostics of a document:
+	// params {"uri": string}, result []Diagnostic.
+	MethodDaemonDiagnostics = "moai/diagnostics"
+
+	// MethodDaemonStatus returns the DaemonStatus.
+	MethodDaemonStatus = "moai/status"
+
+	// MethodDaemonShutdown stops the daemon and its language servers.
+	MethodDaemonShutdown = "moai/shutdown"
+)
+
+// Code intelligence methods served by the LSP daemon. Their params are a
+// daemonCodeParams; results are those of the CodeIntelligence method of
+// the same name, computed by the language server of the document, or of
+// the language for workspace symbols.
+const (
+	MethodDaemonDefinition        = "moai/definition"
+	MethodDaemonReferences        = "moai/references"
+	MethodDaemonHover             = "moai/hover"
+	MethodDaemonSymbols           = "moai/symbols"
+	MethodDaemonWorkspaceSymbols  = "moai/workspaceSymbols"
+	MethodDaemonRename            = "moai/rename"
+	MethodDaemonCodeActions       = "moai/codeActions"
+	MethodDaemonResolveCodeAction = "moai/resolveCodeAction"
+	MethodDaemonFormat            = "moai/format"
+)
+
+// DefaultDaemonIdleTimeout is how long a daemon without requests keeps running.
+const DefaultDaemonIdleTimeout = 30 * time.Minute
+
+var (
+	// ErrDaemonNotRunning indicates no LSP daemon is listening for the project.
+	ErrDaemonNotRunning = errors.New("lsp: daemon not running")
+
+	// ErrDaemonRunning indicates an LSP daemon is already serving the project.
+	ErrDaemonRunning = errors.New("lsp: daemon already running")
+)
+
+// DaemonSocketPath returns the unix socket of the LSP daemon for the
+// project at root. The socket is named after a hash of root, since unix
+// socket paths are limited to about 100 bytes, and lives in the user's
+// private socket directory (see daemonSocketDir).
+func DaemonSocketPath(root string) string {
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(daemonSocketDir(), "lsp-"+hex.EncodeToString(sum[:])[:16]+".sock")
+}
+
+// daemonSocketDir returns the directory of daemon sockets:
+// $XDG_RUNTIME_DIR/moai when set, moai/lsp in the user cache directory
+// otherwise. The daemon creates it accessible to the user only, so other
+// users of a shared machine cannot reach or replace the socket.
+func daemonSocketDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "moai")
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "moai", "lsp")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("moai-lsp-%d", os.Getuid()))
+}
+
+// DaemonStatus describes a running LSP daemon.
+type DaemonStatus struct {
+	PID       int            `json:"pid"`
+	Root      string         `json:"root"`
+	Socket    string         `json:"socket"`
+	StartedAt time.Time      `json:"startedAt"`
+	Servers   []ServerStatus `json:"servers"`
+}
+
+// ServerStatus describes a language server managed by the daemon.
+type ServerStatus struct {
+	Language string `json:"language"`
+	Healthy  bool   `json:"healthy"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DaemonOption configures a Daemon.
+type DaemonOption func(*Daemon)
+
+// WithIdleTimeout sets how long the daemon keeps running without requests.
+// Zero disables the idle shutdown.
+func WithIdleTimeout(d time.Duration) DaemonOption {
+	return func(dm *Daemon) {
+		dm.idleTimeout = d
+	}
+}
+
+// Daemon keeps language servers running for a project and answers
+// requests from short-lived hook processes over a unix socket. Requests use
+// the LSP base protocol framing and JSON-RPC 2.0, so DaemonClient can
+// reuse Conn.
+type Daemon struct {
+	root        string
+	socket      string
+	manager     ServerManager
+	idleTimeout time.Duration
+	startedAt   time.Time
+	lastActive  atomic.Int64
+	stop        chan struct{}
+	stopOnce    sync.Once
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// NewDaemon creates a daemon for the project at root that starts language
+// servers through launcher on demand.
+func NewDaemon(root string, launcher ServerLauncher, opts ...DaemonOption) *Daemon {
+	d := &Daemon{
+		root:        root,
+		socket:      DaemonSocketPath(root),
+		manager:     NewServerManager(launcher),
+		idleTimeout: DefaultDaemonIdleTimeout,
+		stop:        make(chan struct{}),
+		conns:       make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Socket returns the unix socket the daemon listens on.
+func (d *Daemon) Socket() string {
+	return d.socket
+}
+
+// Serve listens on the daemon socket and answers requests until ctx is
+// done, a shutdown request arrives or the daemon has been idle for the
+// idle timeout. Languages are started eagerly; any other language is
+// started on its first request. All language servers are stopped on return.
+func (d *Daemon) Serve(ctx context.Context, languages ...string) error {
+	ln, err := d.listen()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(d.socket) }()
+
+	d.startedAt = time.Now()
+	d.touch()
+	_ = d.manager.StartAll(ctx, languages)
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go d.serveConn(ctx, conn)
+		}
+	}()
+
+	var idle <-chan time.Time
+	if d.idleTimeout > 0 {
+		ticker := time.NewTicker(min(d.idleTimeout/4, time.Minute))
+		defer ticker.Stop()
+		idle = ticker.C
+	}
+wait:
+	for {
+		select {
+		case <-ctx.Done():
+			break wait
+		case <-d.stop:
+			break wait
+		case <-idle:
+			if time.Since(time.Unix(0, d.lastActive.Load())) >= d.idleTimeout {
+				break wait
+			}
+		}
+	}
+
+	_ = ln.Close()
+	d.closeConns()
+	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = d.manager.StopAll(stopCtx)
+	return nil
+}
+
+// Stop makes Serve return.
+func (d *Daemon) Stop() {
+	d.stopOnce.Do(func() { close(d.stop) })
+}
+
+// listen creates the daemon socket, replacing a stale socket left by a
+// daemon that did not exit cleanly.
+func (d *Daemon) listen() (net.Listener, error) {
+	dir := filepath.Dir(d.socket)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create socket directory: %w", err)
+	}
+	// Fails unless the directory belongs to the user.
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("restrict %s: %w", dir, err)
+	}
+	if conn, err := net.DialTimeout("unix", d.socket, time.Second); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%w: %s", ErrDaemonRunning, d.socket)
+	}
+	_ = os.Remove(d.socket)
+
+	ln, err := net.Listen("unix", d.socket)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", d.socket, err)
+	}
+	if err := os.Chmod(d.socket, 0o600); err != nil {
+		_ = ln.Close()
+		return nil, fmt.Errorf("restrict %s: %w", d.socket, err)
+	}
+	return ln, nil
+}
+
+// closeConns closes the open client connections.
+func (d *Daemon) closeConns() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for conn := range d.conns {
+		_ = conn.Close()
+	}
+	clear(d.conns)
+}
+
+// touch records activity for the idle timeout.
+func (d *Daemon) touch() {
+	d.lastActive.Store(time.Now().UnixNano())
+}
+
+// serveConn answers the requests of one client connection until it closes.
+func (d *Daemon) serveConn(ctx context.Context, conn net.Conn) {
+	func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.conns[conn] = struct{}{}
+	}()
+	defer func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		delete(d.conns, conn)
+		_ = conn.Close()
+	}()
+
+	transport := NewStreamTransport(conn, conn, conn)
+
+	for {
+		data, err := transport.ReadMessage(ctx)
+		if err != nil {
+			return
+		}
+		d.touch()
+
+		var req incomingMessage
+		if err := json.Unmarshal(data, &req); err != nil {
+			continue
+		}
+		if len(req.ID) == 0 {
+			// Notifications need no answer.
+			continue
+		}
+
+		resp := jsonrpcResponse{JSONRPC: "2.0", ID: req.ID}
+		resp.Result, resp.Error = d.handle(ctx, req.Method, req.Params)
+		if resp.Error == nil && resp.Result == nil {
+			resp.Result = json.RawMessage("null")
+		}
+		out, err := json.Marshal(resp)
+		if err != nil {
+			return
+		}
+		if err := transport.WriteMessage(ctx, out); err != nil {
+			return
+		}
+		if req.Method == MethodDaemonShutdown {
+			d.Stop()
+			return
+		}
+	}
+}
+
+// handle dispatches a daemon request.
+func (d *Daemon) handle(ctx context.Context, method string, params json.RawMessage) (any, *JSONRPCError) {
+	switch method {
+	case MethodDaemonDiagnostics:
+		var p textDocumentIdentifier
+		if err := json.Unmarshal(params, &p); err != nil || p.URI == "" {
+			return nil, &JSONRPCError{Code: CodeInvalidParams, Message: "uri is required"}
+		}
+		diags, err := d.diagnostics(ctx, p.URI)
+		if err != nil {
+			return nil, &JSONRPCError{Code: CodeInternalError, Message: err.Error()}
+		}
+		return diags, nil
+	case MethodDaemonDefinition, MethodDaemonReferences, MethodDaemonHover, MethodDaemonSymbols,
+		MethodDaemonWorkspaceSymbols, MethodDaemonRename, MethodDaemonCodeActions,
+		MethodDaemonResolveCodeAction, MethodDaemonFormat:
+		return d.handleCode(ctx, method, params)
+	case MethodDaemonStatus:
+		return d.status(ctx), nil
+	case MethodDaemonShutdown:
+		return nil, nil
+	default:
+		return nil, &JSONRPCError{Code: CodeMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+// diagnostics returns the diagnostics of uri from the language server of
+// its language, starting the server if needed.
+func (d *Daemon) diagnostics(ctx context.Context, uri string) ([]Diagnostic, error) {
+	lang := LanguageForPath(uri)
+	if lang == "" {
+		return nil, fmt.Errorf("no language server for %s", uri)
+	}
+	client, err := d.client(ctx, lang)
+	if err != nil {
+		return nil, err
+	}
+	return client.Diagnostics(ctx, uri)
+}
+
+// client returns the client of the language server for lang, starting the
+// server if needed.
+func (d *Daemon) client(ctx context.Context, lang string) (Client, error) {
+	if err := d.manager.StartServer(ctx, lang); err != nil {
+		return nil, err
+	}
+	return d.manager.GetClient(lang)
+}
+
+// daemonCodeParams are the params of the code intelligence methods.
+type daemonCodeParams struct {
+	// Language selects the server of workspace symbol searches.
+	Language string            `json:"language,omitempty"`
+	URI      string            `json:"uri,omitempty"`
+	Position Position          `json:"position"`
+	Range    Range             `json:"range"`
+	Query    string            `json:"query,omitempty"`
+	NewName  string            `json:"newName,omitempty"`
+	Action   *CodeAction       `json:"action,omitempty"`
+	Options  FormattingOptions `json:"options"`
+}
+
+// handleCode answers a code intelligence request with the language server
+// of the document, or of the language for workspace symbols.
+func (d *Daemon) handleCode(ctx context.Context, method string, params json.RawMessage) (any, *JSONRPCError) {
+	var p daemonCodeParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &JSONRPCError{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	lang := p.Language
+	if method != MethodDaemonWorkspaceSymbols {
+		if p.URI == "" {
+			return nil, &JSONRPCError{Code: CodeInvalidParams, Message: "uri is required"}
+		}
+		lang = LanguageForPath(p.URI)
+	}
+	if lang == "" {
+		return nil, &JSONRPCError{Code: CodeInvalidParams, Message: "no language server for " + p.URI + p.Language}
+	}
+	if method == MethodDaemonResolveCodeAction && p.Action == nil {
+		return nil, &JSONRPCError{Code: CodeInvalidParams, Message: "action is required"}
+	}
+
+	client, err := d.client(ctx, lang)
+	if err != nil {
+		return nil, &JSONRPCError{Code: CodeInternalError, Message: err.Error()}
+	}
+	var result any
+	switch method {
+	case MethodDaemonDefinition:
+		result, err = client.Definition(ctx, p.URI, p.Position)
+	case MethodDaemonReferences:
+		result, err = client.References(ctx, p.URI, p.Position)
+	case MethodDaemonHover:
+		result, err = client.Hover(ctx, p.URI, p.Position)
+	case MethodDaemonSymbols:
+		result, err = client.Symbols(ctx, p.URI)
+	case MethodDaemonWorkspaceSymbols:
+		result, err = client.WorkspaceSymbols(ctx, p.Query)
+	case MethodDaemonRename:
+		result, err = client.Rename(ctx, p.URI, p.Position, p.NewName)
+	case MethodDaemonCodeActions:
+		result, err = client.CodeActions(ctx, p.URI, p.Range)
+	case MethodDaemonResolveCodeAction:
+		result, err = client.ResolveCodeAction(ctx, p.URI, *p.Action)
+	case MethodDaemonFormat:
+		result, err = client.Format(ctx, p.URI, p.Options)
+	}
+	if err != nil {
+		return nil, &JSONRPCError{Code: CodeInternalError, Message: err.Error()}
+	}
+	return result, nil
+}
+
+// status reports the daemon and the health of its language servers.
+func (d *Daemon) status(ctx context.Context) *DaemonStatus {
+	status := &DaemonStatus{
+		PID:       os.Getpid(),
+		Root:      d.root,
+		Socket:    d.socket,
+		StartedAt: d.startedAt,
+		Servers:   []ServerStatus{},
+	}
+	for lang, err := range d.manager.HealthCheck(ctx) {
+		s := ServerStatus{Language: lang, Healthy: err == nil}
+		if err != nil {
+			s.Error = err.Error()
+		}
+		status.Servers = append(status.Servers, s)
+	}
+	sort.Slice(status.Servers, func(i, j int) bool {
+		return status.Servers[i].Language < status.Servers[j].Language
+	})
+	return status
+}
+
+// DaemonClient talks to the LSP daemon of a project.
+type DaemonClient struct {
+	conn Conn
+}
+
+// DialDaemon connects to the LSP daemon of the project at root.
+// Returns ErrDaemonNotRunning if no daemon is listening.
+func DialDaemon(ctx context.Context, root string) (*DaemonClient, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", DaemonSocketPath(root))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDaemonNotRunning, err)
+	}
+	return &DaemonClient{conn: NewConn(NewStreamTransport(conn, conn, conn))}, nil
+}
+
+// Diagnostics returns the diagnostics of the document at uri.
+func (c *DaemonClient) Diagnostics(ctx context.Context, uri string) ([]Diagnostic, error) {
+	var diags []Diagnostic
+	if err := c.conn.Call(ctx, MethodDaemonDiagnostics, textDocumentIdentifier{URI: uri}, &diags); err != nil {
+		return nil, fmt.Errorf("daemon diagnostics: %w", err)
+	}
+	if diags == nil {
+		return []Diagnostic{}, nil
+	}
+	return diags, nil
+}
+
+// Status returns the status of the daemon.
+func (c *DaemonClient) Status(ctx context.Context) (*DaemonStatus, error) {
+	var status DaemonStatus
+	if err := c.conn.Call(ctx, MethodDaemonStatus, nil, &status); err != nil {
+		return nil, fmt.Errorf("daemon status: %w", err)
+	}
+	return &status, nil
+}
+
+// Shutdown asks the daemon to stop its language servers and exit.
+func (c *DaemonClient) Shutdown(ctx context.Context) error {
+	if err := c.conn.Call(ctx, MethodDaemonShutdown, nil, nil); err != nil {
+		return fmt.Errorf("daemon shutdown: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection to the daemon.
+func (c *DaemonClient) Close() error {
+	return c.conn.Close()
+}
+
+// Intelligence returns the code intelligence served by the daemon. Requests
+// about a document go to the server of its language; workspace symbol
+// searches go to the server of lang.
+func (c *DaemonClient) Intelligence(lang string) CodeIntelligence {
+	return &daemonIntelligence{conn: c.conn, lang: lang}
+}
+
+// daemonIntelligence implements CodeIntelligence through the daemon.
+type daemonIntelligence struct {
+	conn Conn
+	lang string
+}
+
+// Compile-time interface compliance check.
+var _ CodeIntelligence = (*daemonIntelligence)(nil)
+
+// call sends a code intelligence request to the daemon.
+func (d *daemonIntelligence) call(ctx context.Context, method string, params daemonCodeParams, result any) error {
+	if err := d.conn.Call(ctx, method, params, result); err != nil {
+		return fmt.Errorf("daemon %s: %w", strings.TrimPrefix(method, "moai/"), err)
+	}
+	return nil
+}
+
+// Definition returns the definition location(s) of the symbol at pos.
+func (d *daemonIntelligence) Definition(ctx context.Context, uri string, pos Position) ([]Location, error) {
+	var locations []Location
+	err := d.call(ctx, MethodDaemonDefinition, daemonCodeParams{URI: uri, Position: pos}, &locations)
+	return locations, err
+}
+
+// References returns the reference locations of the symbol at pos.
+func (d *daemonIntelligence) References(ctx context.Context, uri string, pos Position) ([]Location, error) {
+	var locations []Location
+	err := d.call(ctx, MethodDaemonReferences, daemonCodeParams{URI: uri, Position: pos}, &locations)
+	return locations, err
+}
+
+// Hover returns hover information for the symbol at pos.
+func (d *daemonIntelligence) Hover(ctx context.Context, uri string, pos Position) (*HoverResult, error) {
+	var hover *HoverResult
+	err := d.call(ctx, MethodDaemonHover, daemonCodeParams{URI: uri, Position: pos}, &hover)
+	return hover, err
+}
+
+// Symbols returns the document symbols of uri.
+func (d *daemonIntelligence) Symbols(ctx context.Context, uri string) ([]DocumentSymbol, error) {
+	var symbols []DocumentSymbol
+	err := d.call(ctx, MethodDaemonSymbols, daemonCodeParams{URI: uri}, &symbols)
+	return symbols, err
+}
+
+// WorkspaceSymbols searches the workspace symbols matching query with the
+// server of the daemonIntelligence language.
+func (d *daemonIntelligence) WorkspaceSymbols(ctx context.Context, query string) ([]SymbolInformation, error) {
+	var symbols []SymbolInformation
+	err := d.call(ctx, MethodDaemonWorkspaceSymbols, daemonCodeParams{Language: d.lang, Query: query}, &symbols)
+	return symbols, err
+}
+
+// Rename returns the edits renaming the symbol at pos.
+func (d *daemonIntelligence) Rename(ctx context.Context, uri string, pos Position, newName string) (*WorkspaceEdit, error) {
+	var edit WorkspaceEdit
+	if err := d.call(ctx, MethodDaemonRename, daemonCodeParams{URI: uri, Position: pos, NewName: newName}, &edit); err != nil {
+		return nil, err
+	}
+	return &edit, nil
+}
+
+// CodeActions returns the fixes and refactorings offered for rng.
+func (d *daemonIntelligence) CodeActions(ctx context.Context, uri string, rng Range) ([]CodeAction, error) {
+	var actions []CodeAction
+	err := d.call(ctx, MethodDaemonCodeActions, daemonCodeParams{URI: uri, Range: rng}, &actions)
+	return actions, err
+}
+
+// ResolveCodeAction resolves an action offered for uri.
+func (d *daemonIntelligence) ResolveCodeAction(ctx context.Context, uri string, action CodeAction) (*CodeAction, error) {
+	var resolved CodeAction
+	if err := d.call(ctx, MethodDaemonResolveCodeAction, daemonCodeParams{URI: uri, Action: &action}, &resolved); err != nil {
+		return nil, err
+	}
+	return &resolved, nil
+}
+
+// Format returns the edits formatting uri.
+func (d *daemonIntelligence) Format(ctx context.Context, uri string, opts FormattingOptions) ([]TextEdit, error) {
+	var edits []TextEdit
+	err := d.call(ctx, MethodDaemonFormat, daemonCodeParams{URI: uri, Options: opts}, &edits)
+	return edits, err
+}
+
+// daemonDiagnostics implements DiagnosticsProvider by querying the LSP
+// daemon of the project that contains each document.
+type daemonDiagnostics struct {
+	rootFor func(path string) string
+}
+
+// NewDaemonDiagnosticsProvider returns a DiagnosticsProvider backed by the
+// LSP daemon. rootFor maps a file path to its project root, or "" if the
+// file is outside any project. Diagnostics fails with ErrDaemonNotRunning
+// when no daemon serves the project, so callers can fall back to CLI tools.
+func NewDaemonDiagnosticsProvider(rootFor func(path string) string) DiagnosticsProvider {
+	return &daemonDiagnostics{rootFor: rootFor}
+}
+
+// Diagnostics connects to the daemon for uri and returns its diagnostics.
+func (p *daemonDiagnostics) Diagnostics(ctx context.Context, uri string) ([]Diagnostic, error) {
+	root := p.rootFor(URIToPath(uri))
+	if root == "" {
+		return nil, ErrDaemonNotRunning
+	}
+	client, err := DialDaemon(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }()
+	return client.Diagnostics(ctx, uri)
+}
@@ -0,0 +1,223 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startDaemon serves a daemon backed by the fake language server and
+// returns it with a channel that receives the result of Serve.
+func startDaemon(t *testing.T, root string, opts ...DaemonOption) (*Daemon, <-chan error) {
+	t.Helper()
+	d := NewDaemon(root, NewStdioLauncher(root, fakeServerSpecs(t)), opts...)
+	done := make(chan error, 1)
+	finished := make(chan struct{})
+	go func() {
+		done <- d.Serve(context.Background())
+		close(finished)
+	}()
+	t.Cleanup(func() {
+		d.Stop()
+		<-finished
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		client, err := DialDaemon(context.Background(), root)
+		if err == nil {
+			_ = client.Close()
+			return d, done
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("daemon did not start: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDaemon_DiagnosticsAndStatus(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	startDaemon(t, root)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := DialDaemon(ctx, root)
+	if err != nil {
+		t.Fatalf("DialDaemon() error = %v", err)
+	}
+	defer func() { _ = client.Close() }()
+
+	uri := FileURI(root + "/main.go")
+	diags, err := client.Diagnostics(ctx, uri)
+	if err != nil {
+		t.Fatalf("Diagnostics() error = %v", err)
+	}
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, uri) {
+		t.Errorf("Diagnostics() = %+v, want the fake error for %s", diags, uri)
+	}
+
+	if _, err := client.Diagnostics(ctx, FileURI(root+"/notes.txt")); err == nil {
+		t.Error("Diagnostics() for an unsupported file: want error")
+	}
+
+	status, err := client.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if status.PID != os.Getpid() || status.Socket != DaemonSocketPath(root) {
+		t.Errorf("Status() = %+v", status)
+	}
+	if len(status.Servers) != 1 || status.Servers[0].Language != "go" || !status.Servers[0].Healthy {
+		t.Errorf("Status().Servers = %+v, want healthy go server", status.Servers)
+	}
+}
+
+func TestDaemon_CodeIntelligence(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	startDaemon(t, root)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := DialDaemon(ctx, root)
+	if err != nil {
+		t.Fatalf("DialDaemon() error = %v", err)
+	}
+	defer func() { _ = client.Close() }()
+	intel := client.Intelligence("go")
+
+	uri := FileURI(root + "/main.go")
+	locations, err := intel.Definition(ctx, uri, Position{Line: 4, Character: 2})
+	if err != nil || len(locations) != 1 || locations[0].URI != uri {
+		t.Errorf("Definition() = %+v, %v, want the document", locations, err)
+	}
+
+	edit, err := intel.Rename(ctx, uri, Position{Line: 2}, "bar")
+	if err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if edits := edit.Changes[uri]; len(edits) != 1 || edits[0].NewText != "bar" || edits[0].Range.Start.Line != 2 {
+		t.Errorf("Rename() = %+v, want one edit from the document changes", edit)
+	}
+
+	symbols, err := intel.WorkspaceSymbols(ctx, "NewClient")
+	if err != nil || len(symbols) != 1 || symbols[0].Name != "NewClient" {
+		t.Errorf("WorkspaceSymbols() = %+v, %v", symbols, err)
+	}
+	if _, err := client.Intelligence("cobol").WorkspaceSymbols(ctx, "x"); err == nil {
+		t.Error("WorkspaceSymbols() for an unknown language: want error")
+	}
+	if _, err := intel.Hover(ctx, FileURI(root+"/notes.txt"), Position{}); err == nil {
+		t.Error("Hover() for an unsupported file: want error")
+	}
+}
+
+func TestDaemon_PrivateSocketDir(t *testing.T) {
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	root := t.TempDir()
+	d, _ := startDaemon(t, root)
+	if dir := filepath.Dir(d.Socket()); dir != filepath.Join(runtimeDir, "moai") {
+		t.Errorf("socket directory = %s, want %s", dir, filepath.Join(runtimeDir, "moai"))
+	}
+	info, err := os.Stat(filepath.Dir(d.Socket()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o700 {
+		t.Errorf("socket directory mode = %o, want 700", perm)
+	}
+}
+
+func TestDaemon_SecondInstanceRefused(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	startDaemon(t, root)
+
+	err := NewDaemon(root, NewStdioLauncher(root, nil)).Serve(context.Background())
+	if !errors.Is(err, ErrDaemonRunning) {
+		t.Errorf("second Serve() error = %v, want ErrDaemonRunning", err)
+	}
+}
+
+func TestDaemon_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	_, done := startDaemon(t, root)
+
+	client, err := DialDaemon(context.Background(), root)
+	if err != nil {
+		t.Fatalf("DialDaemon() error = %v", err)
+	}
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	_ = client.Close()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("daemon did not stop after shutdown")
+	}
+	if _, err := os.Stat(DaemonSocketPath(root)); !os.IsNotExist(err) {
+		t.Errorf("socket still exists after shutdown: %v", err)
+	}
+	if _, err := DialDaemon(context.Background(), root); !errors.Is(err, ErrDaemonNotRunning) {
+		t.Errorf("DialDaemon() after shutdown error = %v, want ErrDaemonNotRunning", err)
+	}
+}
+
+func TestDaemon_IdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	_, done := startDaemon(t, root, WithIdleTimeout(100*time.Millisecond))
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("idle daemon did not stop")
+	}
+}
+
+func TestDaemonDiagnosticsProvider(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	provider := NewDaemonDiagnosticsProvider(func(path string) string {
+		if strings.HasPrefix(path, root) {
+			return root
+		}
+		return ""
+	})
+
+	if _, err := provider.Diagnostics(context.Background(), "file:///elsewhere/main.go"); !errors.Is(err, ErrDaemonNotRunning) {
+		t.Errorf("outside project: error = %v, want ErrDaemonNotRunning", err)
+	}
+	if _, err := provider.Diagnostics(context.Background(), FileURI(root+"/main.go")); !errors.Is(err, ErrDaemonNotRunning) {
+		t.Errorf("no daemon: error = %v, want ErrDaemonNotRunning", err)
+	}
+
+	startDaemon(t, root)
+	diags, err := provider.Diagnostics(context.Background(), FileURI(root+"/main.go"))
+	if err != nil {
+		t.Fatalf("Diagnostics() error = %v", err)
+	}
+	if len(diags) != 1 {
+		t.Errorf("Diagnostics() = %+v, want one fake diagnostic", diags)
+	}
+}
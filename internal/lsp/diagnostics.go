@@ -0,0 +1,191 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultDiagnosticsSettle is how long a document's diagnostics must
+	// stay unchanged before they are returned.
+	DefaultDiagnosticsSettle = 300 * time.Millisecond
+
+	// DefaultDiagnosticsTimeout bounds the wait for published diagnostics.
+	DefaultDiagnosticsTimeout = 10 * time.Second
+)
+
+// publishDiagnosticsParams is the textDocument/publishDiagnostics payload.
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Version     *int         `json:"version,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// publishedDiagnostics is the latest publication for a document.
+type publishedDiagnostics struct {
+	diagnostics []Diagnostic
+	raw         []json.RawMessage
+	version     *int
+	seq         uint64
+	at          time.Time
+}
+
+// current reports whether the publication reflects the document version,
+// or, when the server or document gives no version, whether it arrived
+// after the publication numbered after.
+func (p *publishedDiagnostics) current(version int, after uint64) bool {
+	if p.version != nil && version > 0 {
+		return *p.version >= version
+	}
+	return p.seq > after
+}
+
+// diagnosticsCache keeps the diagnostics pushed by the server through
+// textDocument/publishDiagnostics. All methods are safe for concurrent use.
+type diagnosticsCache struct {
+	mu      sync.Mutex
+	seq     uint64
+	entries map[string]*publishedDiagnostics
+	changed chan struct{}
+}
+
+// newDiagnosticsCache creates an empty cache.
+func newDiagnosticsCache() *diagnosticsCache {
+	return &diagnosticsCache{
+		entries: make(map[string]*publishedDiagnostics),
+		changed: make(chan struct{}),
+	}
+}
+
+// diagnosticsKey normalizes the percent-encoding of a document URI.
+func diagnosticsKey(uri string) string {
+	if unescaped, err := url.PathUnescape(uri); err == nil {
+		return unescaped
+	}
+	return uri
+}
+
+// publish records a textDocument/publishDiagnostics notification and wakes
+// up waiting requests.
+func (c *diagnosticsCache) publish(_ context.Context, params json.RawMessage) {
+	var p publishDiagnosticsParams
+	if err := json.Unmarshal(params, &p); err != nil || p.URI == "" {
+		return
+	}
+	if p.Diagnostics == nil {
+		p.Diagnostics = []Diagnostic{}
+	}
+	// The raw diagnostics are sent back unchanged as code action context.
+	var raw struct {
+		Diagnostics []json.RawMessage `json:"diagnostics"`
+	}
+	_ = json.Unmarshal(params, &raw)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	c.entries[diagnosticsKey(p.URI)] = &publishedDiagnostics{
+		diagnostics: p.Diagnostics,
+		raw:         raw.Diagnostics,
+		version:     p.Version,
+		seq:         c.seq,
+		at:          time.Now(),
+	}
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// mark returns the sequence number of the latest publication.
+func (c *diagnosticsCache) mark() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seq
+}
+
+// overlapping returns the raw diagnostics of uri whose range overlaps rng,
+// as published by the server.
+func (c *diagnosticsCache) overlapping(uri string, rng Range) []json.RawMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	overlapping := []json.RawMessage{}
+	entry := c.entries[diagnosticsKey(uri)]
+	if entry == nil || len(entry.raw) != len(entry.diagnostics) {
+		return overlapping
+	}
+	for i, d := range entry.diagnostics {
+		if d.Range.Contains(rng.Start) || d.Range.Contains(rng.End) || rng.Contains(d.Range.Start) {
+			overlapping = append(overlapping, entry.raw[i])
+		}
+	}
+	return overlapping
+}
+
+// forget drops the diagnostics of uri.
+func (c *diagnosticsCache) forget(uri string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, diagnosticsKey(uri))
+}
+
+// wait returns the diagnostics of uri once a publication is current for
+// version and after (see publishedDiagnostics.current) and nothing new was
+// published for settle. When timeout expires first, current diagnostics
+// that have not settled yet are returned; otherwise wait fails with
+// ErrStaleDiagnostics when only an earlier version was published, or
+// ErrDiagnosticsTimeout when nothing was.
+func (c *diagnosticsCache) wait(ctx context.Context, uri string, version int, after uint64, settle, timeout time.Duration) ([]Diagnostic, error) {
+	key := diagnosticsKey(uri)
+	var expired <-chan time.Time
+	if timeout > 0 {
+		deadline := time.NewTimer(timeout)
+		defer deadline.Stop()
+		expired = deadline.C
+	}
+
+	for {
+		c.mu.Lock()
+		entry, changed := c.entries[key], c.changed
+		c.mu.Unlock()
+
+		current := entry != nil && entry.current(version, after)
+		var quiet *time.Timer
+		var settled <-chan time.Time
+		if current {
+			remaining := settle - time.Since(entry.at)
+			if remaining <= 0 {
+				return entry.diagnostics, nil
+			}
+			quiet = time.NewTimer(remaining)
+			settled = quiet.C
+		}
+
+		var timedOut, cancelled bool
+		select {
+		case <-changed:
+		case <-settled:
+		case <-ctx.Done():
+			cancelled = true
+		case <-expired:
+			timedOut = true
+		}
+		if quiet != nil {
+			quiet.Stop()
+		}
+
+		switch {
+		case cancelled:
+			return nil, ctx.Err()
+		case timedOut && current:
+			return entry.diagnostics, nil
+		case timedOut && entry != nil:
+			return nil, fmt.Errorf("%w for %s", ErrStaleDiagnostics, uri)
+		case timedOut:
+			return nil, fmt.Errorf("%w for %s", ErrDiagnosticsTimeout, uri)
+		}
+	}
+}
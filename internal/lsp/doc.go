@@ -6,7 +6,11 @@
 //   - models.go: Core LSP data types (Diagnostic, Position, Range, Location, etc.)
 //   - protocol.go: JSON-RPC 2.0 transport and connection management
 //   - client.go: LSP client interface for single-server communication
+//   - documents.go: Document synchronization (didOpen/didChange/didSave/didClose)
+//   - diagnostics.go: Cache of diagnostics pushed by publishDiagnostics
 //   - server.go: ServerManager for multi-server lifecycle management
+//   - launcher.go: StdioLauncher that starts language server processes
+//   - daemon.go: Per-project daemon serving diagnostics over a unix socket
 //
 // Basic usage:
 //
@@ -0,0 +1,225 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// documentLanguageIDs maps file extensions to LSP language identifiers.
+var documentLanguageIDs = map[string]string{
+	".go":  "go",
+	".py":  "python",
+	".pyi": "python",
+	".ts":  "typescript",
+	".tsx": "typescriptreact",
+	".js":  "javascript",
+	".jsx": "javascriptreact",
+	".mjs": "javascript",
+	".cjs": "javascript",
+	".rs":  "rust",
+}
+
+// languageID returns the LSP language identifier of the file at path,
+// falling back to its extension without the dot.
+func languageID(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if id, ok := documentLanguageIDs[ext]; ok {
+		return id
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// openDocument is the state of a document opened on the server.
+type openDocument struct {
+	// version is the version last sent to the server, starting at 1.
+	version int
+
+	// text is the content last sent to the server.
+	text string
+
+	// mark is the diagnostics sequence number when version was sent.
+	mark uint64
+
+	// pinned is set for content given by ChangeDocument, which requests
+	// do not replace with the file on disk.
+	pinned bool
+}
+
+// documentStore tracks the documents opened on the server. mu is held
+// while notifying the server so that versions are sent in order.
+type documentStore struct {
+	mu   sync.Mutex
+	docs map[string]*openDocument
+}
+
+// --- LSP document synchronization types (internal, not exported) ---
+
+type textDocumentItem struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId"`
+	Version    int    `json:"version"`
+	Text       string `json:"text"`
+}
+
+type versionedTextDocumentIdentifier struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+}
+
+type didOpenTextDocumentParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+}
+
+type textDocumentContentChangeEvent struct {
+	Text string `json:"text"`
+}
+
+type didChangeTextDocumentParams struct {
+	TextDocument   versionedTextDocumentIdentifier  `json:"textDocument"`
+	ContentChanges []textDocumentContentChangeEvent `json:"contentChanges"`
+}
+
+type didSaveTextDocumentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Text         *string                `json:"text,omitempty"`
+}
+
+// OpenDocument opens the file of uri on the server, or sends its content
+// as a new version when it changed since it was last sent.
+func (c *lspClient) OpenDocument(ctx context.Context, uri string) error {
+	data, err := os.ReadFile(URIToPath(uri))
+	if err != nil {
+		return fmt.Errorf("open document %s: %w", uri, err)
+	}
+	_, err = c.syncDocument(ctx, uri, string(data), true, false)
+	return err
+}
+
+// ChangeDocument sends text as the content of uri, opening it when
+// needed. Later requests keep this content until OpenDocument reads the
+// file again.
+func (c *lspClient) ChangeDocument(ctx context.Context, uri, text string) error {
+	_, err := c.syncDocument(ctx, uri, text, false, true)
+	return err
+}
+
+// SaveDocument notifies the server that the open document uri was saved.
+func (c *lspClient) SaveDocument(ctx context.Context, uri string) error {
+	c.docs.mu.Lock()
+	defer c.docs.mu.Unlock()
+
+	doc, ok := c.docs.docs[uri]
+	if !ok {
+		return fmt.Errorf("save document %s: not open", uri)
+	}
+	return c.notifySave(ctx, uri, doc.text)
+}
+
+// CloseDocument closes uri on the server and forgets its diagnostics.
+// Closing a document that is not open does nothing.
+func (c *lspClient) CloseDocument(ctx context.Context, uri string) error {
+	c.docs.mu.Lock()
+	defer c.docs.mu.Unlock()
+
+	if _, ok := c.docs.docs[uri]; !ok {
+		return nil
+	}
+	delete(c.docs.docs, uri)
+	c.diagnostics.forget(uri)
+	params := documentParams{TextDocument: textDocumentIdentifier{URI: uri}}
+	if err := c.conn.Notify(ctx, "textDocument/didClose", params); err != nil {
+		return fmt.Errorf("textDocument/didClose: %w", err)
+	}
+	return nil
+}
+
+// ensureOpen syncs the file of uri before a request about it, unless
+// ChangeDocument pinned its content. Files that cannot be read are left
+// to the server, with the zero document state.
+func (c *lspClient) ensureOpen(ctx context.Context, uri string) (openDocument, error) {
+	c.docs.mu.Lock()
+	doc, ok := c.docs.docs[uri]
+	if ok && doc.pinned {
+		state := *doc
+		c.docs.mu.Unlock()
+		return state, nil
+	}
+	c.docs.mu.Unlock()
+
+	data, err := os.ReadFile(URIToPath(uri))
+	if err != nil {
+		c.docs.mu.Lock()
+		defer c.docs.mu.Unlock()
+		if doc, ok := c.docs.docs[uri]; ok {
+			return *doc, nil
+		}
+		return openDocument{}, nil
+	}
+	return c.syncDocument(ctx, uri, string(data), true, false)
+}
+
+// syncDocument sends text as the content of uri: didOpen for a new
+// document and didChange with the next version for changed content,
+// followed by didSave when saved is set and the server asks for it.
+func (c *lspClient) syncDocument(ctx context.Context, uri, text string, saved, pinned bool) (openDocument, error) {
+	c.docs.mu.Lock()
+	defer c.docs.mu.Unlock()
+
+	doc, ok := c.docs.docs[uri]
+	if ok && doc.text == text {
+		doc.pinned = pinned
+		return *doc, nil
+	}
+
+	mark := c.diagnostics.mark()
+	if !ok {
+		params := didOpenTextDocumentParams{TextDocument: textDocumentItem{
+			URI:        uri,
+			LanguageID: languageID(URIToPath(uri)),
+			Version:    1,
+			Text:       text,
+		}}
+		if err := c.conn.Notify(ctx, "textDocument/didOpen", params); err != nil {
+			return openDocument{}, fmt.Errorf("textDocument/didOpen: %w", err)
+		}
+		doc = &openDocument{version: 1}
+		c.docs.docs[uri] = doc
+	} else {
+		params := didChangeTextDocumentParams{
+			TextDocument:   versionedTextDocumentIdentifier{URI: uri, Version: doc.version + 1},
+			ContentChanges: []textDocumentContentChangeEvent{{Text: text}},
+		}
+		if err := c.conn.Notify(ctx, "textDocument/didChange", params); err != nil {
+			return openDocument{}, fmt.Errorf("textDocument/didChange: %w", err)
+		}
+		doc.version++
+		if saved {
+			if err := c.notifySave(ctx, uri, text); err != nil {
+				return openDocument{}, err
+			}
+		}
+	}
+	doc.text, doc.mark, doc.pinned = text, mark, pinned
+	return *doc, nil
+}
+
+// notifySave sends didSave for uri when the server asks for it, with the
+// text when the server wants it included.
+func (c *lspClient) notifySave(ctx context.Context, uri, text string) error {
+	send, includeText := c.caps.saveSupport()
+	if !send {
+		return nil
+	}
+	params := didSaveTextDocumentParams{TextDocument: textDocumentIdentifier{URI: uri}}
+	if includeText {
+		params.Text = &text
+	}
+	if err := c.conn.Notify(ctx, "textDocument/didSave", params); err != nil {
+		return fmt.Errorf("textDocument/didSave: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package lsp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// FileChange is the content of a file before and after a workspace edit.
+type FileChange struct {
+	// Path is the file path.
+	Path string
+
+	// Before is the current content of the file.
+	Before []byte
+
+	// After is the content with the edits applied.
+	After []byte
+}
+
+// PreviewWorkspaceEdit reads the files changed by edit and applies their
+// text edits in memory, sorted by path. Nothing is written.
+func PreviewWorkspaceEdit(edit *WorkspaceEdit) ([]FileChange, error) {
+	if edit == nil {
+		return nil, nil
+	}
+	uris := make([]string, 0, len(edit.Changes))
+	for uri := range edit.Changes {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	changes := make([]FileChange, 0, len(uris))
+	for _, uri := range uris {
+		path := URIToPath(uri)
+		before, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		after, err := ApplyTextEdits(before, edit.Changes[uri])
+		if err != nil {
+			return nil, fmt.Errorf("edit %s: %w", path, err)
+		}
+		changes = append(changes, FileChange{Path: path, Before: before, After: after})
+	}
+	return changes, nil
+}
+
+// ApplyTextEdits applies edits to content. Positions count UTF-16 code
+// units as in LSP; positions past the end of a line or of the content are
+// clamped to it. Edits must not overlap; edits inserting at the same
+// position are applied in order.
+func ApplyTextEdits(content []byte, edits []TextEdit) ([]byte, error) {
+	type span struct {
+		start, end int
+		text       string
+	}
+	spans := make([]span, 0, len(edits))
+	for _, e := range edits {
+		start, err := byteOffset(content, e.Range.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := byteOffset(content, e.Range.End)
+		if err != nil {
+			return nil, err
+		}
+		if end < start {
+			return nil, fmt.Errorf("%w: range end before start", ErrUnsupportedEdit)
+		}
+		spans = append(spans, span{start: start, end: end, text: e.NewText})
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var out bytes.Buffer
+	offset := 0
+	for _, s := range spans {
+		if s.start < offset {
+			return nil, fmt.Errorf("%w: overlapping text edits", ErrUnsupportedEdit)
+		}
+		out.Write(content[offset:s.start])
+		out.WriteString(s.text)
+		offset = s.end
+	}
+	out.Write(content[offset:])
+	return out.Bytes(), nil
+}
+
+// byteOffset converts an LSP position to a byte offset in content.
+func byteOffset(content []byte, pos Position) (int, error) {
+	if pos.Line < 0 || pos.Character < 0 {
+		return 0, fmt.Errorf("%w: negative position", ErrUnsupportedEdit)
+	}
+	offset := 0
+	for range pos.Line {
+		i := bytes.IndexByte(content[offset:], '\n')
+		if i < 0 {
+			return len(content), nil
+		}
+		offset += i + 1
+	}
+	line := lineAt(content, offset)
+	return offset + len(line[:UTF16ToByteColumn(line, pos.Character)]), nil
+}
+
+// lineAt returns the line of content starting at offset, without its
+// line ending.
+func lineAt(content []byte, offset int) []byte {
+	line := content[offset:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	return bytes.TrimSuffix(line, []byte("\r"))
+}
+
+// UTF16ToByteColumn converts a column counted in UTF-16 code units, as in
+// LSP positions, to a byte offset in line, clamped to the line length.
+func UTF16ToByteColumn(line []byte, units int) int {
+	offset, n := 0, 0
+	for offset < len(line) && n < units {
+		r, size := utf8.DecodeRune(line[offset:])
+		n += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
+
+// ByteToUTF16Column converts a byte offset in line to a column counted in
+// UTF-16 code units.
+func ByteToUTF16Column(line []byte, offset int) int {
+	offset = min(offset, len(line))
+	n := 0
+	for i := 0; i < offset; {
+		r, size := utf8.DecodeRune(line[i:])
+		n += utf16.RuneLen(r)
+		i += size
+	}
+	return n
+}
@@ -0,0 +1,144 @@
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyTextEdits(t *testing.T) {
+	t.Parallel()
+
+	edit := func(sl, sc, el, ec int, text string) TextEdit {
+		return TextEdit{Range: Range{Start: Position{sl, sc}, End: Position{el, ec}}, NewText: text}
+	}
+	tests := []struct {
+		name    string
+		content string
+		edits   []TextEdit
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "edits in any order",
+			content: "foo := 1\nbar(foo)\n",
+			edits:   []TextEdit{edit(1, 4, 1, 7, "baz"), edit(0, 0, 0, 3, "baz")},
+			want:    "baz := 1\nbar(baz)\n",
+		},
+		{
+			name:    "inserts at the same position keep their order",
+			content: "x\n",
+			edits:   []TextEdit{edit(0, 0, 0, 0, "a"), edit(0, 0, 0, 0, "b")},
+			want:    "abx\n",
+		},
+		{
+			name:    "columns count UTF-16 code units",
+			content: "s := \"😀é\" + foo\n",
+			edits:   []TextEdit{edit(0, 13, 0, 16, "bar")},
+			want:    "s := \"😀é\" + bar\n",
+		},
+		{
+			name:    "positions past the end are clamped",
+			content: "a\r\nb",
+			edits:   []TextEdit{edit(0, 9, 0, 9, ";"), edit(5, 0, 5, 0, "\n")},
+			want:    "a;\r\nb\n",
+		},
+		{
+			name:    "joining lines",
+			content: "a\nb\n",
+			edits:   []TextEdit{edit(0, 1, 1, 0, " ")},
+			want:    "a b\n",
+		},
+		{
+			name:    "overlapping edits",
+			content: "abcdef",
+			edits:   []TextEdit{edit(0, 0, 0, 4, "x"), edit(0, 2, 0, 5, "y")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ApplyTextEdits([]byte(tt.content), tt.edits)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedEdit) {
+					t.Errorf("error = %v, want ErrUnsupportedEdit", err)
+				}
+				return
+			}
+			if err != nil || string(got) != tt.want {
+				t.Errorf("ApplyTextEdits() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUTF16Columns(t *testing.T) {
+	t.Parallel()
+
+	line := []byte("a😀b")
+	if got := ByteToUTF16Column(line, 5); got != 3 {
+		t.Errorf("ByteToUTF16Column() = %d, want 3", got)
+	}
+	if got := UTF16ToByteColumn(line, 3); got != 5 {
+		t.Errorf("UTF16ToByteColumn() = %d, want 5", got)
+	}
+	if got := UTF16ToByteColumn(line, 99); got != len(line) {
+		t.Errorf("UTF16ToByteColumn() past the end = %d, want %d", got, len(line))
+	}
+}
+
+func TestWorkspaceEditJSON(t *testing.T) {
+	t.Parallel()
+
+	var edit WorkspaceEdit
+	data := `{"changes":{"file:///a.go":[{"range":{"start":{"line":0,"character":0},"end":{"line":0,"character":1}},"newText":"x"}]},
+		"documentChanges":[{"textDocument":{"uri":"file:///b.go","version":3},"edits":[{"range":{"start":{"line":1,"character":0},"end":{"line":1,"character":0}},"newText":"y"}]}]}`
+	if err := json.Unmarshal([]byte(data), &edit); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if len(edit.Changes) != 2 || edit.Changes["file:///b.go"][0].NewText != "y" {
+		t.Errorf("Changes = %+v, want the edits of both forms", edit.Changes)
+	}
+
+	err := json.Unmarshal([]byte(`{"documentChanges":[{"kind":"create","uri":"file:///c.go"}]}`), &edit)
+	if !errors.Is(err, ErrUnsupportedEdit) {
+		t.Errorf("file operation error = %v, want ErrUnsupportedEdit", err)
+	}
+}
+
+func TestPreviewWorkspaceEdit(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	for path, content := range map[string]string{a: "foo()\n", b: "x := foo\n"} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	edit := &WorkspaceEdit{Changes: map[string][]TextEdit{
+		FileURI(b): {{Range: Range{Start: Position{0, 5}, End: Position{0, 8}}, NewText: "bar"}},
+		FileURI(a): {{Range: Range{Start: Position{0, 0}, End: Position{0, 3}}, NewText: "bar"}},
+	}}
+	changes, err := PreviewWorkspaceEdit(edit)
+	if err != nil {
+		t.Fatalf("PreviewWorkspaceEdit() error = %v", err)
+	}
+	if len(changes) != 2 || changes[0].Path != a || string(changes[0].After) != "bar()\n" ||
+		string(changes[1].Before) != "x := foo\n" || string(changes[1].After) != "x := bar\n" {
+		t.Errorf("PreviewWorkspaceEdit() = %+v", changes)
+	}
+	if data, _ := os.ReadFile(a); string(data) != "foo()\n" {
+		t.Errorf("file written during preview: %q", data)
+	}
+
+	edit.Changes[FileURI(filepath.Join(dir, "missing.go"))] = nil
+	if _, err := PreviewWorkspaceEdit(edit); err == nil {
+		t.Error("PreviewWorkspaceEdit() with a missing file: want error")
+	}
+}
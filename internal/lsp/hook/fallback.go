@@ -3,42 +3,91 @@ package hook
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/modu-ai/moai-adk/pkg/models"
 )
 
+const (
+	// FallbackFormatSARIF marks tools that print a SARIF 2.1 log on stdout.
+	FallbackFormatSARIF = "sarif"
+
+	// FallbackFormatRegex marks tools that print one diagnostic per line.
+	FallbackFormatRegex = "regex"
+)
+
+// defaultLinePattern matches the "file:line:col: severity: message [code]"
+// lines printed by gcc-style compilers and linters. The column, severity
+// and code are optional.
+var defaultLinePattern = regexp.MustCompile(`^(?P<file>[^:\s][^:]*):(?P<line>\d+):(?:(?P<col>\d+):)?\s*(?:(?P<severity>(?i:fatal error|fatal|error|warning|warn|note|info|information|hint|style)):\s*)?(?P<message>.*?)(?:\s+\[(?P<code>[^\[\]]+)\])?\s*$`)
+
 // FallbackTool represents a CLI tool configuration for fallback diagnostics.
+// Tools without a Parser are parsed according to Format.
 type FallbackTool struct {
 	Name       string
 	Command    string
 	Args       []string
 	JSONOutput bool
 	Parser     func([]byte) ([]Diagnostic, error)
+
+	// Format is FallbackFormatSARIF or FallbackFormatRegex.
+	Format string
+
+	// Pattern matches the diagnostic lines of FallbackFormatRegex output
+	// with the named groups file, line, col, severity, code and message.
+	// Nil uses defaultLinePattern.
+	Pattern *regexp.Regexp
+
+	// Severity maps the tool's lower-case severity names to diagnostic
+	// severities. The "default" entry applies to unknown names.
+	Severity map[string]DiagnosticSeverity
 }
 
 // fallbackDiagnostics implements FallbackDiagnostics interface.
 // It provides CLI tool fallback when LSP is unavailable per REQ-HOOK-160 through REQ-HOOK-162.
 type fallbackDiagnostics struct {
-	mu        sync.RWMutex
-	tools     map[string][]FallbackTool
-	available map[string]bool
+	mu         sync.RWMutex
+	tools      map[string][]FallbackTool
+	available  map[string]bool
+	configured func() []models.FallbackDiagnosticsTool
+}
+
+// FallbackOption configures the fallback diagnostics provider.
+type FallbackOption func(*fallbackDiagnostics)
+
+// WithConfiguredTools adds the tools declared in the quality configuration
+// (quality.fallback_diagnostics). tools is called on every lookup, so the
+// configuration may be loaded after the provider is created.
+func WithConfiguredTools(tools func() []models.FallbackDiagnosticsTool) FallbackOption {
+	return func(f *fallbackDiagnostics) {
+		f.configured = tools
+	}
 }
 
 // NewFallbackDiagnostics creates a new fallback diagnostics provider.
-func NewFallbackDiagnostics() *fallbackDiagnostics {
+func NewFallbackDiagnostics(opts ...FallbackOption) *fallbackDiagnostics {
 	fb := &fallbackDiagnostics{
 		tools:     make(map[string][]FallbackTool),
 		available: make(map[string]bool),
 	}
 	fb.registerDefaultTools()
+	for _, opt := range opts {
+		opt(fb)
+	}
 	return fb
 }
 
 // registerDefaultTools registers fallback tools for supported languages per REQ-HOOK-160.
+// Tools are tried in order, so richer linters come before the basic ones.
 func (f *fallbackDiagnostics) registerDefaultTools() {
 	// Python: ruff check --output-format=json
 	f.tools["python"] = []FallbackTool{
@@ -73,8 +122,21 @@ func (f *fallbackDiagnostics) registerDefaultTools() {
 		},
 	}
 
-	// Go: go vet
+	// Go: golangci-lint and staticcheck check the package, go vet the file
 	f.tools["go"] = []FallbackTool{
+		{
+			Name:    "golangci-lint",
+			Command: "golangci-lint",
+			Args:    []string{"run", "{dir}"},
+			Format:  FallbackFormatRegex,
+		},
+		{
+			Name:       "staticcheck",
+			Command:    "staticcheck",
+			Args:       []string{"-f", "sarif", "{dir}"},
+			JSONOutput: true,
+			Format:     FallbackFormatSARIF,
+		},
 		{
 			Name:       "go vet",
 			Command:    "go",
@@ -94,12 +156,143 @@ func (f *fallbackDiagnostics) registerDefaultTools() {
 			Parser:     parseClippyOutput,
 		},
 	}
+
+	// Java: pmd check -f sarif
+	f.tools["java"] = []FallbackTool{
+		{
+			Name:       "pmd",
+			Command:    "pmd",
+			Args:       []string{"check", "--no-progress", "-f", "sarif", "-R", "rulesets/java/quickstart.xml", "-d", "{file}"},
+			JSONOutput: true,
+			Format:     FallbackFormatSARIF,
+		},
+	}
+
+	// Kotlin: ktlint --reporter=sarif
+	f.tools["kotlin"] = []FallbackTool{
+		{
+			Name:       "ktlint",
+			Command:    "ktlint",
+			Args:       []string{"--reporter=sarif", "{file}"},
+			JSONOutput: true,
+			Format:     FallbackFormatSARIF,
+		},
+	}
+
+	// Ruby: rubocop --format emacs
+	f.tools["ruby"] = []FallbackTool{
+		{
+			Name:    "rubocop",
+			Command: "rubocop",
+			Args:    []string{"--format", "emacs", "{file}"},
+			Format:  FallbackFormatRegex,
+			Pattern: regexp.MustCompile(`^(?P<file>[^:]+):(?P<line>\d+):(?P<col>\d+): (?P<severity>[CRWEF]): (?:\[Correctable\] )?(?:(?P<code>[A-Z][A-Za-z]*/[A-Za-z0-9]+): )?(?P<message>.+)$`),
+			Severity: map[string]DiagnosticSeverity{
+				"c": SeverityInformation,
+				"r": SeverityInformation,
+				"w": SeverityWarning,
+				"e": SeverityError,
+				"f": SeverityError,
+			},
+		},
+	}
+
+	// PHP: phpstan analyse --error-format=raw
+	f.tools["php"] = []FallbackTool{
+		{
+			Name:     "phpstan",
+			Command:  "phpstan",
+			Args:     []string{"analyse", "--error-format=raw", "--no-progress", "{file}"},
+			Format:   FallbackFormatRegex,
+			Pattern:  regexp.MustCompile(`^(?P<file>[^:]+):(?P<line>\d+):(?P<message>.+)$`),
+			Severity: map[string]DiagnosticSeverity{"default": SeverityError},
+		},
+	}
+
+	// C and C++: clang-tidy
+	clangTidy := FallbackTool{
+		Name:    "clang-tidy",
+		Command: "clang-tidy",
+		Args:    []string{"--quiet", "{file}"},
+		Format:  FallbackFormatRegex,
+	}
+	f.tools["c"] = []FallbackTool{clangTidy}
+	f.tools["cpp"] = []FallbackTool{clangTidy}
+
+	// Swift: swiftlint lint --reporter xcode
+	f.tools["swift"] = []FallbackTool{
+		{
+			Name:    "swiftlint",
+			Command: "swiftlint",
+			Args:    []string{"lint", "--quiet", "--reporter", "xcode", "{file}"},
+			Format:  FallbackFormatRegex,
+		},
+	}
+
+	// Shell: shellcheck -f gcc
+	f.tools["shell"] = []FallbackTool{
+		{
+			Name:    "shellcheck",
+			Command: "shellcheck",
+			Args:    []string{"-f", "gcc", "{file}"},
+			Format:  FallbackFormatRegex,
+		},
+	}
+}
+
+// configuredTools returns the tools declared in the configuration.
+func (f *fallbackDiagnostics) configuredTools() []models.FallbackDiagnosticsTool {
+	if f.configured == nil {
+		return nil
+	}
+	return f.configured()
+}
+
+// newConfiguredTool converts a declared tool into a FallbackTool. Invalid
+// entries, which config validation reports, are skipped.
+func newConfiguredTool(t models.FallbackDiagnosticsTool) (FallbackTool, bool) {
+	if t.Name == "" || t.Command == "" {
+		return FallbackTool{}, false
+	}
+	tool := FallbackTool{
+		Name:       t.Name,
+		Command:    t.Command,
+		Args:       t.Args,
+		Format:     t.Format,
+		JSONOutput: t.Format == FallbackFormatSARIF,
+	}
+	if tool.Format == "" {
+		tool.Format = FallbackFormatRegex
+	}
+	if t.Pattern != "" {
+		pattern, err := regexp.Compile(t.Pattern)
+		if err != nil {
+			return FallbackTool{}, false
+		}
+		tool.Pattern = pattern
+	}
+	if len(t.Severity) > 0 {
+		tool.Severity = make(map[string]DiagnosticSeverity, len(t.Severity))
+		for name, severity := range t.Severity {
+			tool.Severity[strings.ToLower(name)] = DiagnosticSeverity(severity)
+		}
+	}
+	return tool, true
 }
 
 // GetLanguage returns the detected language for a file path.
+// Extensions declared by configured tools take precedence.
 func (f *fallbackDiagnostics) GetLanguage(filePath string) string {
 	ext := strings.ToLower(getExtension(filePath))
 
+	for _, t := range f.configuredTools() {
+		for _, e := range t.Extensions {
+			if strings.ToLower(e) == ext && t.Language != "" {
+				return t.Language
+			}
+		}
+	}
+
 	switch ext {
 	case ".py", ".pyi":
 		return "python"
@@ -111,6 +304,22 @@ func (f *fallbackDiagnostics) GetLanguage(filePath string) string {
 		return "go"
 	case ".rs":
 		return "rust"
+	case ".java":
+		return "java"
+	case ".kt", ".kts":
+		return "kotlin"
+	case ".rb":
+		return "ruby"
+	case ".php":
+		return "php"
+	case ".c", ".h":
+		return "c"
+	case ".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx":
+		return "cpp"
+	case ".swift":
+		return "swift"
+	case ".sh", ".bash":
+		return "shell"
 	default:
 		return "unknown"
 	}
@@ -125,7 +334,7 @@ func (f *fallbackDiagnostics) IsAvailable(language string) bool {
 	}
 	f.mu.RUnlock()
 
-	tools := f.tools[language]
+	tools := f.GetToolsForLanguage(language)
 	if len(tools) == 0 {
 		f.cacheAvailability(language, false)
 		return false
@@ -150,9 +359,30 @@ func (f *fallbackDiagnostics) cacheAvailability(language string, available bool)
 	f.available[language] = available
 }
 
-// GetToolsForLanguage returns configured tools for a language.
+// GetToolsForLanguage returns configured tools for a language: the tools
+// declared in the configuration followed by the built-in tools they do
+// not replace.
 func (f *fallbackDiagnostics) GetToolsForLanguage(language string) []FallbackTool {
-	return f.tools[language]
+	var tools []FallbackTool
+	declared := make(map[string]bool)
+	for _, t := range f.configuredTools() {
+		if t.Language != language {
+			continue
+		}
+		if tool, ok := newConfiguredTool(t); ok {
+			tools = append(tools, tool)
+			declared[tool.Name] = true
+		}
+	}
+	if len(tools) == 0 {
+		return f.tools[language]
+	}
+	for _, tool := range f.tools[language] {
+		if !declared[tool.Name] {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
 }
 
 // RunFallback executes fallback CLI tool for the given file.
@@ -166,7 +396,7 @@ func (f *fallbackDiagnostics) RunFallback(ctx context.Context, filePath string)
 	}
 
 	language := f.GetLanguage(filePath)
-	tools := f.tools[language]
+	tools := f.GetToolsForLanguage(language)
 
 	if len(tools) == 0 {
 		return nil, &ErrDiagnosticsUnavailable{
@@ -196,13 +426,10 @@ func (f *fallbackDiagnostics) RunFallback(ctx context.Context, filePath string)
 
 // runTool executes a single tool and parses its output.
 func (f *fallbackDiagnostics) runTool(ctx context.Context, tool FallbackTool, filePath string) ([]Diagnostic, error) {
+	replacer := strings.NewReplacer("{file}", filePath, "{dir}", filepath.Dir(filePath))
 	args := make([]string, len(tool.Args))
 	for i, arg := range tool.Args {
-		if arg == "{file}" {
-			args[i] = filePath
-		} else {
-			args[i] = arg
-		}
+		args[i] = replacer.Replace(arg)
 	}
 
 	// Set timeout
@@ -212,7 +439,15 @@ func (f *fallbackDiagnostics) runTool(ctx context.Context, tool FallbackTool, fi
 	cmd := exec.CommandContext(ctx, tool.Command, args...)
 	// Linters return non-zero exit code when issues are found, which is expected.
 	// We intentionally ignore the error and parse the output regardless.
-	output, _ := cmd.CombinedOutput() //nolint:errcheck // linters return non-zero on findings
+	// SARIF logs are read from stdout alone so that progress on stderr
+	// does not break the JSON.
+	var output []byte
+	var runErr error
+	if tool.Format == FallbackFormatSARIF {
+		output, runErr = cmd.Output()
+	} else {
+		output, runErr = cmd.CombinedOutput()
+	}
 
 	// For linters, non-zero exit code often means "found issues" not "error"
 	// So we try to parse the output regardless
@@ -221,6 +456,21 @@ func (f *fallbackDiagnostics) runTool(ctx context.Context, tool FallbackTool, fi
 		return tool.Parser(output)
 	}
 
+	switch tool.Format {
+	case FallbackFormatSARIF:
+		return parseSARIFOutput(output, filePath, tool)
+	case FallbackFormatRegex:
+		// A failing run without a single diagnostic line is a tool
+		// error, such as a crash or a broken configuration, not a clean
+		// file, so the next tool is tried.
+		diagnostics, matched := parseLineOutput(string(output), filePath, tool)
+		var exitErr *exec.ExitError
+		if !matched && errors.As(runErr, &exitErr) {
+			return nil, fmt.Errorf("%s exited with code %d without diagnostics", tool.Name, exitErr.ExitCode())
+		}
+		return diagnostics, nil
+	}
+
 	// Special handling for go vet
 	if tool.Command == "go" && len(args) > 0 && args[0] == "vet" {
 		return parseGoVetOutput(string(output), filePath), nil
@@ -229,6 +479,178 @@ func (f *fallbackDiagnostics) runTool(ctx context.Context, tool FallbackTool, fi
 	return []Diagnostic{}, nil
 }
 
+// sarifLog is the subset of a SARIF 2.1 log read for diagnostics.
+type sarifLog struct {
+	Runs []struct {
+		Results []struct {
+			RuleID  string `json:"ruleId"`
+			Level   string `json:"level"`
+			Message struct {
+				Text string `json:"text"`
+			} `json:"message"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI string `json:"uri"`
+					} `json:"artifactLocation"`
+					Region struct {
+						StartLine   int `json:"startLine"`
+						StartColumn int `json:"startColumn"`
+						EndLine     int `json:"endLine"`
+						EndColumn   int `json:"endColumn"`
+					} `json:"region"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+		} `json:"results"`
+	} `json:"runs"`
+}
+
+// parseSARIFOutput parses a SARIF 2.1 log into the diagnostics of filePath.
+// Results located in other files are dropped.
+func parseSARIFOutput(data []byte, filePath string, tool FallbackTool) ([]Diagnostic, error) {
+	result := make([]Diagnostic, 0)
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return result, nil
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("parse %s SARIF output: %w", tool.Name, err)
+	}
+
+	for _, run := range log.Runs {
+		for _, r := range run.Results {
+			var rng Range
+			if len(r.Locations) > 0 {
+				loc := r.Locations[0].PhysicalLocation
+				if !reportsFile(loc.ArtifactLocation.URI, filePath) {
+					continue
+				}
+				rng.Start = fallbackPosition(loc.Region.StartLine, loc.Region.StartColumn)
+				rng.End = rng.Start
+				if loc.Region.EndLine > 0 {
+					rng.End = fallbackPosition(loc.Region.EndLine, loc.Region.EndColumn)
+				} else if loc.Region.EndColumn > 0 {
+					rng.End = fallbackPosition(loc.Region.StartLine, loc.Region.EndColumn)
+				}
+			}
+			result = append(result, Diagnostic{
+				Range:    rng,
+				Severity: toolSeverity(tool, r.Level),
+				Code:     r.RuleID,
+				Source:   tool.Name,
+				Message:  r.Message.Text,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// parseLineOutput parses the diagnostic lines of filePath in output with
+// the tool's pattern. Lines about other files are dropped; matched reports
+// whether any line matched the pattern.
+func parseLineOutput(output string, filePath string, tool FallbackTool) (result []Diagnostic, matched bool) {
+	pattern := tool.Pattern
+	if pattern == nil {
+		pattern = defaultLinePattern
+	}
+
+	result = make([]Diagnostic, 0)
+	for _, line := range strings.Split(output, "\n") {
+		matches := pattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		matched = true
+		group := func(name string) string {
+			if i := pattern.SubexpIndex(name); i >= 0 {
+				return matches[i]
+			}
+			return ""
+		}
+
+		if !reportsFile(group("file"), filePath) {
+			continue
+		}
+		lineNum, err := strconv.Atoi(group("line"))
+		if err != nil {
+			continue
+		}
+		charNum, _ := strconv.Atoi(group("col"))
+		message := strings.TrimSpace(group("message"))
+		if message == "" {
+			continue
+		}
+
+		pos := fallbackPosition(lineNum, charNum)
+		result = append(result, Diagnostic{
+			Range:    Range{Start: pos, End: pos},
+			Severity: toolSeverity(tool, group("severity")),
+			Code:     group("code"),
+			Source:   tool.Name,
+			Message:  message,
+		})
+	}
+
+	return result, matched
+}
+
+// toolSeverity maps a severity name reported by tool to a diagnostic
+// severity: first through the tool's Severity map, then by the common
+// names, and finally to the tool's "default" entry or a warning.
+func toolSeverity(tool FallbackTool, name string) DiagnosticSeverity {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if severity, ok := tool.Severity[name]; ok && name != "" {
+		return severity
+	}
+
+	switch name {
+	case "error", "fatal", "fatal error":
+		return SeverityError
+	case "warning", "warn":
+		return SeverityWarning
+	case "note", "info", "information", "style":
+		return SeverityInformation
+	case "hint", "none":
+		return SeverityHint
+	}
+
+	if severity, ok := tool.Severity["default"]; ok {
+		return severity
+	}
+	return SeverityWarning
+}
+
+// fallbackPosition converts a 1-based line and column to a Position.
+// Missing values map to the start of the file or line.
+func fallbackPosition(line, column int) Position {
+	return Position{Line: max(line-1, 0), Character: max(column-1, 0)}
+}
+
+// reportsFile reports whether a path printed by a tool refers to target.
+// Relative paths match by suffix, since tools print them relative to their
+// working directory or a SARIF base URI. An empty path matches.
+func reportsFile(reported, target string) bool {
+	if reported == "" {
+		return true
+	}
+	if strings.HasPrefix(reported, "file:") {
+		if u, err := url.Parse(reported); err == nil {
+			reported = u.Path
+		}
+	}
+	reported = filepath.Clean(filepath.FromSlash(reported))
+	if abs, err := filepath.Abs(target); err == nil {
+		target = abs
+	}
+
+	if filepath.IsAbs(reported) {
+		return reported == target
+	}
+	return strings.HasSuffix(target, string(filepath.Separator)+reported)
+}
+
 // parseRuffOutput parses ruff JSON output per REQ-HOOK-161.
 func parseRuffOutput(data []byte) ([]Diagnostic, error) {
 	if len(data) == 0 {
@@ -3,9 +3,13 @@ package hook
 import (
 	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/modu-ai/moai-adk/pkg/models"
 )
 
 // TestNewFallbackDiagnostics verifies fallback creation.
@@ -35,6 +39,14 @@ func TestGetLanguage(t *testing.T) {
 		{"javascript file", "/path/to/file.js", "javascript"},
 		{"javascript jsx", "/path/to/file.jsx", "javascript"},
 		{"rust file", "/path/to/file.rs", "rust"},
+		{"java file", "/path/to/File.java", "java"},
+		{"kotlin script", "/path/to/build.gradle.kts", "kotlin"},
+		{"ruby file", "/path/to/file.rb", "ruby"},
+		{"php file", "/path/to/file.php", "php"},
+		{"c header", "/path/to/file.h", "c"},
+		{"cpp file", "/path/to/file.cpp", "cpp"},
+		{"swift file", "/path/to/file.swift", "swift"},
+		{"shell script", "/path/to/file.sh", "shell"},
 		{"unknown file", "/path/to/file.unknown", "unknown"},
 		{"no extension", "/path/to/file", "unknown"},
 	}
@@ -397,7 +409,10 @@ func TestSupportedLanguages(t *testing.T) {
 	fb := NewFallbackDiagnostics()
 
 	// These languages should have fallback tools configured
-	languages := []string{"python", "typescript", "javascript", "go", "rust"}
+	languages := []string{
+		"python", "typescript", "javascript", "go", "rust",
+		"java", "kotlin", "ruby", "php", "c", "cpp", "swift", "shell",
+	}
 
 	for _, lang := range languages {
 		t.Run(lang, func(t *testing.T) {
@@ -411,6 +426,290 @@ func TestSupportedLanguages(t *testing.T) {
 	}
 }
 
+// TestParseSARIFOutput verifies SARIF 2.1 parsing and file filtering.
+func TestParseSARIFOutput(t *testing.T) {
+	t.Parallel()
+
+	output := []byte(`{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "staticcheck"}},
+    "results": [
+      {
+        "ruleId": "SA4006",
+        "level": "error",
+        "message": {"text": "this value of x is never used"},
+        "locations": [{"physicalLocation": {
+          "artifactLocation": {"uri": "file:///src/app/main.go"},
+          "region": {"startLine": 12, "startColumn": 2, "endLine": 12, "endColumn": 7}
+        }}]
+      },
+      {
+        "ruleId": "ST1003",
+        "message": {"text": "should not use underscores"},
+        "locations": [{"physicalLocation": {
+          "artifactLocation": {"uri": "app/main.go", "uriBaseId": "%SRCROOT%"},
+          "region": {"startLine": 3}
+        }}]
+      },
+      {
+        "ruleId": "SA1000",
+        "level": "note",
+        "message": {"text": "other file"},
+        "locations": [{"physicalLocation": {
+          "artifactLocation": {"uri": "file:///src/app/util.go"},
+          "region": {"startLine": 1}
+        }}]
+      }
+    ]
+  }]
+}`)
+
+	tool := FallbackTool{Name: "staticcheck", Format: FallbackFormatSARIF}
+	diagnostics, err := parseSARIFOutput(output, "/src/app/main.go", tool)
+	if err != nil {
+		t.Fatalf("parseSARIFOutput: %v", err)
+	}
+	if len(diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics for main.go, got %d: %+v", len(diagnostics), diagnostics)
+	}
+
+	first := diagnostics[0]
+	if first.Severity != SeverityError || first.Code != "SA4006" || first.Source != "staticcheck" {
+		t.Errorf("first diagnostic = %+v", first)
+	}
+	wantRange := Range{Start: Position{Line: 11, Character: 1}, End: Position{Line: 11, Character: 6}}
+	if first.Range != wantRange {
+		t.Errorf("first range = %+v, want %+v", first.Range, wantRange)
+	}
+
+	// A result without level is a warning and a region without column
+	// starts at the beginning of the line.
+	second := diagnostics[1]
+	if second.Severity != SeverityWarning || second.Range.Start != (Position{Line: 2}) {
+		t.Errorf("second diagnostic = %+v", second)
+	}
+
+	if _, err := parseSARIFOutput([]byte("not json"), "/src/app/main.go", tool); err == nil {
+		t.Error("expected error for invalid SARIF output")
+	}
+	empty, err := parseSARIFOutput(nil, "/src/app/main.go", tool)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("empty output = %v, %v; want no diagnostics", empty, err)
+	}
+}
+
+// TestParseLineOutput verifies the generic line parser with the default
+// pattern and the patterns of the built-in tools.
+func TestParseLineOutput(t *testing.T) {
+	t.Parallel()
+
+	fb := NewFallbackDiagnostics()
+	builtin := func(language string) FallbackTool {
+		return fb.GetToolsForLanguage(language)[0]
+	}
+
+	tests := []struct {
+		name   string
+		tool   FallbackTool
+		file   string
+		output string
+		want   []Diagnostic
+	}{
+		{
+			name:   "shellcheck gcc format",
+			tool:   builtin("shell"),
+			file:   "/work/deploy.sh",
+			output: "/work/deploy.sh:4:6: warning: Double quote to prevent globbing. [SC2086]\n/work/deploy.sh:9:1: note: Consider using pgrep. [SC2009]\n",
+			want: []Diagnostic{
+				{Range: Range{Start: Position{Line: 3, Character: 5}, End: Position{Line: 3, Character: 5}}, Severity: SeverityWarning, Code: "SC2086", Source: "shellcheck", Message: "Double quote to prevent globbing."},
+				{Range: Range{Start: Position{Line: 8}, End: Position{Line: 8}}, Severity: SeverityInformation, Code: "SC2009", Source: "shellcheck", Message: "Consider using pgrep."},
+			},
+		},
+		{
+			name:   "clang-tidy skips other files and summaries",
+			tool:   builtin("cpp"),
+			file:   "/work/src/main.cpp",
+			output: "/work/src/main.cpp:10:3: error: use of undeclared identifier 'x' [clang-diagnostic-error]\n/work/src/util.h:2:1: warning: other file [misc]\n    x = 1;\n1 warning generated.\n",
+			want: []Diagnostic{
+				{Range: Range{Start: Position{Line: 9, Character: 2}, End: Position{Line: 9, Character: 2}}, Severity: SeverityError, Code: "clang-diagnostic-error", Source: "clang-tidy", Message: "use of undeclared identifier 'x'"},
+			},
+		},
+		{
+			name:   "golangci-lint relative paths without severity",
+			tool:   builtin("go"),
+			file:   "/work/pkg/api/server.go",
+			output: "pkg/api/server.go:42:10: Error return value is not checked (errcheck)\n",
+			want: []Diagnostic{
+				{Range: Range{Start: Position{Line: 41, Character: 9}, End: Position{Line: 41, Character: 9}}, Severity: SeverityWarning, Source: "golangci-lint", Message: "Error return value is not checked (errcheck)"},
+			},
+		},
+		{
+			name:   "rubocop emacs format",
+			tool:   builtin("ruby"),
+			file:   "/work/app.rb",
+			output: "/work/app.rb:1:7: C: [Correctable] Style/StringLiterals: Prefer single-quoted strings.\n/work/app.rb:3:1: W: Lint/UselessAssignment: Useless assignment to variable - x.\n",
+			want: []Diagnostic{
+				{Range: Range{Start: Position{Character: 6}, End: Position{Character: 6}}, Severity: SeverityInformation, Code: "Style/StringLiterals", Source: "rubocop", Message: "Prefer single-quoted strings."},
+				{Range: Range{Start: Position{Line: 2}, End: Position{Line: 2}}, Severity: SeverityWarning, Code: "Lint/UselessAssignment", Source: "rubocop", Message: "Useless assignment to variable - x."},
+			},
+		},
+		{
+			name:   "phpstan raw format defaults to error",
+			tool:   builtin("php"),
+			file:   "/work/src/User.php",
+			output: "Note: Using configuration file /work/phpstan.neon.\n/work/src/User.php:17:Call to an undefined method User::nam().\n",
+			want: []Diagnostic{
+				{Range: Range{Start: Position{Line: 16}, End: Position{Line: 16}}, Severity: SeverityError, Source: "phpstan", Message: "Call to an undefined method User::nam()."},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, _ := parseLineOutput(tt.output, tt.file, tt.tool)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d diagnostics, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("diagnostic %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestConfiguredTools verifies that tools declared in the configuration
+// come first, replace built-in tools of the same name and map extensions.
+func TestConfiguredTools(t *testing.T) {
+	t.Parallel()
+
+	fb := NewFallbackDiagnostics(WithConfiguredTools(func() []models.FallbackDiagnosticsTool {
+		return []models.FallbackDiagnosticsTool{
+			{Name: "staticcheck", Language: "go", Command: "staticcheck", Args: []string{"{dir}/..."}, Format: "sarif"},
+			{Name: "revive", Language: "go", Command: "revive", Args: []string{"{file}"}},
+			{Name: "luacheck", Language: "lua", Extensions: []string{".lua"}, Command: "luacheck", Severity: map[string]string{"W": "error"}},
+			{Name: "broken", Language: "go", Command: "broken", Pattern: "("},
+		}
+	}))
+
+	var names []string
+	for _, tool := range fb.GetToolsForLanguage("go") {
+		names = append(names, tool.Name)
+	}
+	want := []string{"staticcheck", "revive", "golangci-lint", "go vet"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("go tools = %v, want %v", names, want)
+	}
+
+	if got := fb.GetLanguage("/path/to/init.LUA"); got != "lua" {
+		t.Errorf("GetLanguage(init.LUA) = %q, want lua", got)
+	}
+	lua := fb.GetToolsForLanguage("lua")
+	if len(lua) != 1 || lua[0].Format != FallbackFormatRegex || lua[0].Severity["w"] != SeverityError {
+		t.Errorf("lua tools = %+v", lua)
+	}
+}
+
+// TestRunFallback_ConfiguredTool runs a declared tool end to end.
+func TestRunFallback_ConfiguredTool(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "main.lua")
+	if err := os.WriteFile(testFile, []byte("x = 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	fb := NewFallbackDiagnostics(WithConfiguredTools(func() []models.FallbackDiagnosticsTool {
+		return []models.FallbackDiagnosticsTool{{
+			Name:       "fake-lint",
+			Language:   "lua",
+			Extensions: []string{".lua"},
+			Command:    "sh",
+			Args:       []string{"-c", `echo "$1:1:1: warning: global x in $2 [W111]"`, "sh", "{file}", "{dir}"},
+		}}
+	}))
+
+	diagnostics, err := fb.RunFallback(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("RunFallback: %v", err)
+	}
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %+v", diagnostics)
+	}
+	d := diagnostics[0]
+	if d.Code != "W111" || d.Source != "fake-lint" || d.Message != "global x in "+tmpDir {
+		t.Errorf("diagnostic = %+v", d)
+	}
+}
+
+// TestRunFallback_FailingRegexTool verifies that a regex tool failing
+// without diagnostic lines falls through to the next tool.
+func TestRunFallback_FailingRegexTool(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "main.lua")
+	if err := os.WriteFile(testFile, []byte("x = 1\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	fb := NewFallbackDiagnostics(WithConfiguredTools(func() []models.FallbackDiagnosticsTool {
+		return []models.FallbackDiagnosticsTool{
+			{Name: "crashing-lint", Language: "lua", Extensions: []string{".lua"}, Command: "sh", Args: []string{"-c", "echo 'config error: no rules'; exit 3"}},
+			{Name: "fake-lint", Language: "lua", Command: "sh", Args: []string{"-c", `echo "$1:2:1: error: undefined y"; exit 1`, "sh", "{file}"}},
+		}
+	}))
+
+	diagnostics, err := fb.RunFallback(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("RunFallback: %v", err)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Source != "fake-lint" {
+		t.Errorf("diagnostics = %+v, want the one of fake-lint", diagnostics)
+	}
+}
+
+// TestToolSeverity verifies severity mapping precedence.
+func TestToolSeverity(t *testing.T) {
+	t.Parallel()
+
+	tool := FallbackTool{Severity: map[string]DiagnosticSeverity{
+		"error":   SeverityWarning,
+		"default": SeverityHint,
+	}}
+	tests := []struct {
+		name string
+		want DiagnosticSeverity
+	}{
+		{"Error", SeverityWarning},
+		{"fatal", SeverityError},
+		{"note", SeverityInformation},
+		{"custom", SeverityHint},
+		{"", SeverityHint},
+	}
+	for _, tt := range tests {
+		if got := toolSeverity(tool, tt.name); got != tt.want {
+			t.Errorf("toolSeverity(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := toolSeverity(FallbackTool{}, "unknown"); got != SeverityWarning {
+		t.Errorf("toolSeverity without map = %q, want warning", got)
+	}
+}
+
 // isErrDiagnosticsUnavailable is a helper to check error type.
 func isErrDiagnosticsUnavailable(err error, target **ErrDiagnosticsUnavailable) bool {
 	if err == nil {
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 )
@@ -109,6 +110,26 @@ func (t *regressionTracker) ClearBaseline(filePath string) error {
 	return t.saveBaselineLocked()
 }
 
+// TrackedFiles returns the paths that have a baseline, sorted.
+func (t *regressionTracker) TrackedFiles() ([]string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.loadBaselineLocked(); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	files := make([]string, 0, len(t.baseline.Files))
+	for path := range t.baseline.Files {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
 // loadBaselineLocked loads the baseline from disk. Caller must hold lock.
 func (t *regressionTracker) loadBaselineLocked() error {
 	if t.baseline != nil {
@@ -295,6 +316,46 @@ func (t *sessionTracker) GetFileStats(filePath string) (*FileStats, error) {
 	return result, nil
 }
 
+// RestoreSessionTracker creates a started session tracker from a snapshot
+// taken with Snapshot.
+func RestoreSessionTracker(snapshot SessionSnapshot) *sessionTracker {
+	t := NewSessionTracker()
+	t.stats = snapshot.Stats
+	for path, fs := range snapshot.Files {
+		history := make([]SeverityCounts, len(fs.DiagnosticHistory))
+		copy(history, fs.DiagnosticHistory)
+		t.fileStats[path] = &FileStats{
+			Path:              path,
+			DiagnosticHistory: history,
+			LastAnalyzed:      fs.LastAnalyzed,
+		}
+		t.filesAdded[path] = true
+	}
+	t.started = true
+	return t
+}
+
+// Snapshot returns the tracker state for RestoreSessionTracker.
+func (t *sessionTracker) Snapshot() SessionSnapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	snapshot := SessionSnapshot{Stats: t.stats}
+	if len(t.fileStats) > 0 {
+		snapshot.Files = make(map[string]FileStats, len(t.fileStats))
+		for path, fs := range t.fileStats {
+			history := make([]SeverityCounts, len(fs.DiagnosticHistory))
+			copy(history, fs.DiagnosticHistory)
+			snapshot.Files[path] = FileStats{
+				Path:              fs.Path,
+				DiagnosticHistory: history,
+				LastAnalyzed:      fs.LastAnalyzed,
+			}
+		}
+	}
+	return snapshot
+}
+
 // EndSession finalizes the session and returns summary per REQ-HOOK-191.
 func (t *sessionTracker) EndSession() (SessionStats, error) {
 	t.mu.Lock()
@@ -288,6 +288,34 @@ func TestClearBaseline(t *testing.T) {
 	}
 }
 
+// TestTrackedFiles verifies listing of files with a baseline.
+func TestTrackedFiles(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewRegressionTracker(t.TempDir())
+
+	files, err := tracker.TrackedFiles()
+	if err != nil {
+		t.Fatalf("TrackedFiles without baseline failed: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("TrackedFiles without baseline = %v, want none", files)
+	}
+
+	for _, path := range []string{"b.go", "a.go"} {
+		if err := tracker.SaveBaseline(path, nil); err != nil {
+			t.Fatalf("SaveBaseline failed: %v", err)
+		}
+	}
+	files, err = tracker.TrackedFiles()
+	if err != nil {
+		t.Fatalf("TrackedFiles failed: %v", err)
+	}
+	if len(files) != 2 || files[0] != "a.go" || files[1] != "b.go" {
+		t.Errorf("TrackedFiles = %v, want [a.go b.go]", files)
+	}
+}
+
 // TestNewSessionTracker verifies session tracker creation.
 func TestNewSessionTracker(t *testing.T) {
 	t.Parallel()
@@ -439,6 +467,40 @@ func TestSessionTracker_EndSession(t *testing.T) {
 	}
 }
 
+// TestSessionTracker_SnapshotRestore verifies that a restored tracker
+// continues the session of its snapshot.
+func TestSessionTracker_SnapshotRestore(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewSessionTracker()
+	if err := tracker.StartSession(); err != nil {
+		t.Fatalf("StartSession failed: %v", err)
+	}
+	tracker.RecordDiagnostics("a.go", []Diagnostic{{Severity: SeverityError}})
+
+	restored := RestoreSessionTracker(tracker.Snapshot())
+	restored.RecordDiagnostics("a.go", []Diagnostic{{Severity: SeverityWarning}})
+	restored.RecordDiagnostics("b.go", nil)
+
+	stats, err := restored.EndSession()
+	if err != nil {
+		t.Fatalf("EndSession failed: %v", err)
+	}
+	if stats.TotalErrors != 1 || stats.TotalWarnings != 1 || stats.FilesAnalyzed != 2 {
+		t.Errorf("stats = %+v, want 1 error, 1 warning, 2 files", stats)
+	}
+	if !stats.StartedAt.Equal(tracker.GetSessionStats().StartedAt) {
+		t.Errorf("StartedAt = %v, want the original session start", stats.StartedAt)
+	}
+	fs, err := restored.GetFileStats("a.go")
+	if err != nil {
+		t.Fatalf("GetFileStats failed: %v", err)
+	}
+	if len(fs.DiagnosticHistory) != 2 {
+		t.Errorf("a.go history = %v, want 2 entries", fs.DiagnosticHistory)
+	}
+}
+
 // TestBaselineStorageFormat verifies storage format per SPEC.
 func TestBaselineStorageFormat(t *testing.T) {
 	t.Parallel()
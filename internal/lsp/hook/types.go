@@ -170,6 +170,17 @@ type FileStats struct {
 	LastAnalyzed time.Time `json:"lastAnalyzed"`
 }
 
+// SessionSnapshot is the serializable state of a SessionTracker. Hook
+// events run in separate processes, so the tracker is restored from a
+// snapshot on every event.
+type SessionSnapshot struct {
+	// Stats are the session totals so far.
+	Stats SessionStats `json:"stats"`
+
+	// Files maps file paths to their diagnostic history.
+	Files map[string]FileStats `json:"files,omitempty"`
+}
+
 // LSPDiagnosticsCollector collects LSP diagnostics.
 // Implementations must be thread-safe.
 type LSPDiagnosticsCollector interface {
@@ -210,6 +221,9 @@ type RegressionTracker interface {
 
 	// ClearBaseline removes the baseline for a file.
 	ClearBaseline(filePath string) error
+
+	// TrackedFiles returns the paths that have a baseline, sorted.
+	TrackedFiles() ([]string, error)
 }
 
 // QualityGateEnforcer enforces quality gate rules.
@@ -242,6 +256,9 @@ type SessionTracker interface {
 
 	// EndSession finalizes the session and returns summary.
 	EndSession() (SessionStats, error)
+
+	// Snapshot returns the tracker state for RestoreSessionTracker.
+	Snapshot() SessionSnapshot
 }
 
 // ErrDiagnosticsUnavailable is returned when no diagnostic tool is available.
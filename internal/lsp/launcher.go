@@ -0,0 +1,183 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ServerSpec describes how to start a language server that speaks LSP over stdio.
+type ServerSpec struct {
+	// Command is the server executable, resolved through PATH.
+	Command string
+
+	// Args are passed to Command.
+	Args []string
+
+	// Env lists additional KEY=value environment variables for the server.
+	Env []string
+}
+
+// DefaultServerSpecs maps languages to their default language servers.
+var DefaultServerSpecs = map[string]ServerSpec{
+	"go":         {Command: "gopls"},
+	"python":     {Command: "pyright-langserver", Args: []string{"--stdio"}},
+	"typescript": {Command: "typescript-language-server", Args: []string{"--stdio"}},
+	"rust":       {Command: "rust-analyzer"},
+}
+
+// languageExtensions maps file extensions to the languages of DefaultServerSpecs.
+var languageExtensions = map[string]string{
+	".go":  "go",
+	".py":  "python",
+	".pyi": "python",
+	".ts":  "typescript",
+	".tsx": "typescript",
+	".js":  "typescript",
+	".jsx": "typescript",
+	".mjs": "typescript",
+	".cjs": "typescript",
+	".rs":  "rust",
+}
+
+// LanguageForPath returns the language whose server handles the file at
+// path (a file path or file:// URI), or "" if no server handles it.
+func LanguageForPath(path string) string {
+	return languageExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// StdioLauncher implements ServerLauncher by starting language server
+// processes and communicating with them over stdin/stdout.
+// All methods are safe for concurrent use.
+type StdioLauncher struct {
+	rootDir string
+	servers map[string]ServerSpec
+}
+
+// Compile-time interface compliance check.
+var _ ServerLauncher = (*StdioLauncher)(nil)
+
+// NewStdioLauncher creates a launcher that starts servers for the project
+// at rootDir. A nil servers map uses DefaultServerSpecs.
+func NewStdioLauncher(rootDir string, servers map[string]ServerSpec) *StdioLauncher {
+	if servers == nil {
+		servers = DefaultServerSpecs
+	}
+	return &StdioLauncher{rootDir: rootDir, servers: servers}
+}
+
+// Launch starts the language server for lang and performs the initialize
+// handshake with the project root as rootUri. The process outlives ctx;
+// it is stopped through the returned client or process handle.
+func (l *StdioLauncher) Launch(ctx context.Context, lang string) (Client, ProcessHandle, error) {
+	spec, ok := l.servers[lang]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: no language server configured for %q", ErrServerStartFailed, lang)
+	}
+	path, err := exec.LookPath(spec.Command)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrServerStartFailed, spec.Command, err)
+	}
+
+	cmd := exec.Command(path, spec.Args...)
+	cmd.Dir = l.rootDir
+	if len(spec.Env) > 0 {
+		cmd.Env = append(os.Environ(), spec.Env...)
+	}
+	cmd.Stderr = io.Discard
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: stdin pipe: %v", ErrServerStartFailed, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: stdout pipe: %v", ErrServerStartFailed, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrServerStartFailed, spec.Command, err)
+	}
+	process := newProcessHandle(cmd)
+
+	client := NewClient(NewConn(NewStreamTransport(stdout, stdin, stdin)))
+	if err := client.Initialize(ctx, FileURI(l.rootDir)); err != nil {
+		_ = process.Kill()
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrInitializeFailed, spec.Command, err)
+	}
+	return client, process, nil
+}
+
+// FileURI converts a file path to a file:// URI, percent-encoding
+// characters such as spaces.
+func FileURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+// URIToPath converts a file:// URI to a file path, decoding
+// percent-encoded characters. Other strings are returned unchanged.
+func URIToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := u.Path
+	// Windows URIs carry a leading slash before the drive letter.
+	if len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+// processHandle implements ProcessHandle for an exec.Cmd.
+type processHandle struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error
+}
+
+// newProcessHandle returns a handle for a started cmd and reaps the
+// process in the background.
+func newProcessHandle(cmd *exec.Cmd) *processHandle {
+	h := &processHandle{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		h.err = cmd.Wait()
+		close(h.done)
+	}()
+	return h
+}
+
+// Kill forcefully terminates the process.
+func (h *processHandle) Kill() error {
+	if err := h.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("kill %s: %w", filepath.Base(h.cmd.Path), err)
+	}
+	return nil
+}
+
+// Wait blocks until the process exits.
+func (h *processHandle) Wait() error {
+	<-h.done
+	return h.err
+}
+
+// IsRunning reports whether the process is still running.
+func (h *processHandle) IsRunning() bool {
+	select {
+	case <-h.done:
+		return false
+	default:
+		return true
+	}
+}
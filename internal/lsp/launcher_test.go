@@ -0,0 +1,213 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServerEnv makes the test binary act as a language server; see TestMain.
+const fakeServerEnv = "MOAI_LSP_FAKE_SERVER"
+
+// TestMain runs the fake language server when the test binary is started
+// by StdioLauncher, so launcher and daemon tests need no real servers.
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeServerEnv) == "1" {
+		runFakeServer()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runFakeServer answers initialize, textDocument/diagnostic, a few code
+// intelligence requests and shutdown over stdio until it receives the exit
+// notification. Every document gets one error diagnostic that names its
+// URI; definitions point at the first line of the document, and renames
+// replace the first three characters of the requested line.
+func runFakeServer() {
+	transport := NewStreamTransport(os.Stdin, os.Stdout, nil)
+	ctx := context.Background()
+	for {
+		data, err := transport.ReadMessage(ctx)
+		if err != nil {
+			return
+		}
+		var msg incomingMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+
+		var result any
+		switch msg.Method {
+		case "exit":
+			return
+		case "initialize":
+			result = map[string]any{"capabilities": map[string]any{"diagnosticProvider": map[string]any{}}}
+		case "textDocument/diagnostic":
+			var p documentParams
+			_ = json.Unmarshal(msg.Params, &p)
+			result = diagnosticReport{Kind: "full", Items: []Diagnostic{{
+				Severity: SeverityError,
+				Source:   "fake",
+				Message:  "fake error in " + p.TextDocument.URI,
+			}}}
+		case "textDocument/definition":
+			var p textDocumentPositionParams
+			_ = json.Unmarshal(msg.Params, &p)
+			result = Location{URI: p.TextDocument.URI}
+		case "textDocument/rename":
+			var p renameParams
+			_ = json.Unmarshal(msg.Params, &p)
+			line := p.Position.Line
+			result = map[string]any{"documentChanges": []any{map[string]any{
+				"textDocument": map[string]any{"uri": p.TextDocument.URI, "version": 1},
+				"edits":        []TextEdit{{Range: Range{Start: Position{line, 0}, End: Position{line, 3}}, NewText: p.NewName}},
+			}}}
+		case "workspace/symbol":
+			var p workspaceSymbolParams
+			_ = json.Unmarshal(msg.Params, &p)
+			result = []SymbolInformation{{Name: p.Query, Kind: SymbolKindFunction, Location: Location{URI: "file:///project/main.go"}}}
+		}
+		if len(msg.ID) == 0 {
+			continue
+		}
+		out, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": result})
+		if err := transport.WriteMessage(ctx, out); err != nil {
+			return
+		}
+	}
+}
+
+// fakeServerSpecs returns server specs that start the fake server for Go.
+func fakeServerSpecs(t *testing.T) map[string]ServerSpec {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable: %v", err)
+	}
+	return map[string]ServerSpec{
+		"go": {Command: exe, Env: []string{fakeServerEnv + "=1"}},
+	}
+}
+
+func TestStdioLauncher_FakeServer(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	launcher := NewStdioLauncher(t.TempDir(), fakeServerSpecs(t))
+	client, process, err := launcher.Launch(ctx, "go")
+	if err != nil {
+		t.Fatalf("Launch() error = %v", err)
+	}
+	if !process.IsRunning() {
+		t.Fatal("IsRunning() = false after launch")
+	}
+
+	diags, err := client.Diagnostics(ctx, "file:///project/main.go")
+	if err != nil {
+		t.Fatalf("Diagnostics() error = %v", err)
+	}
+	if len(diags) != 1 || diags[0].Message != "fake error in file:///project/main.go" {
+		t.Errorf("Diagnostics() = %+v", diags)
+	}
+
+	if err := client.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		_ = process.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		t.Fatal("server process did not exit after shutdown")
+	}
+	if process.IsRunning() {
+		t.Error("IsRunning() = true after exit")
+	}
+	if err := process.Kill(); err != nil {
+		t.Errorf("Kill() after exit error = %v", err)
+	}
+}
+
+func TestStdioLauncher_Errors(t *testing.T) {
+	t.Parallel()
+
+	launcher := NewStdioLauncher(t.TempDir(), map[string]ServerSpec{
+		"go": {Command: "moai-no-such-language-server"},
+	})
+	tests := []struct {
+		name string
+		lang string
+	}{
+		{"unconfigured language", "cobol"},
+		{"missing executable", "go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := launcher.Launch(context.Background(), tt.lang)
+			if !errors.Is(err, ErrServerStartFailed) {
+				t.Errorf("Launch(%q) error = %v, want ErrServerStartFailed", tt.lang, err)
+			}
+		})
+	}
+}
+
+func TestLanguageForPath(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"main.go":                   "go",
+		"file:///src/app.py":        "python",
+		"web/App.TSX":               "typescript",
+		"index.mjs":                 "typescript",
+		"src/lib.rs":                "rust",
+		"README.md":                 "",
+		"Makefile":                  "",
+		"file:///project/types.pyi": "python",
+	}
+	for path, want := range tests {
+		if got := LanguageForPath(path); got != want {
+			t.Errorf("LanguageForPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestFileURIRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	uri := FileURI(dir)
+	if uri[:8] != "file:///" {
+		t.Fatalf("FileURI(%q) = %q, want file:/// prefix", dir, uri)
+	}
+	if got := URIToPath(uri); got != dir {
+		t.Errorf("URIToPath(%q) = %q, want %q", uri, got, dir)
+	}
+	if got := URIToPath("relative/path.go"); got != "relative/path.go" {
+		t.Errorf("URIToPath(non-URI) = %q, want unchanged", got)
+	}
+}
+
+func TestFileURIEscaping(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "my project", "a#b%c.go")
+	uri := FileURI(path)
+	if strings.ContainsAny(uri[len("file://"):], " #") || !strings.Contains(uri, "my%20project") || !strings.Contains(uri, "a%23b%25c.go") {
+		t.Errorf("FileURI(%q) = %q, want percent-encoded", path, uri)
+	}
+	if got := URIToPath(uri); got != path {
+		t.Errorf("URIToPath(%q) = %q, want %q", uri, got, path)
+	}
+}
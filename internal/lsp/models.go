@@ -3,6 +3,7 @@ package lsp
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 )
 
 // Sentinel errors for LSP operations.
@@ -18,6 +19,17 @@ var (
 
 	// ErrConnectionClosed indicates the connection to the language server was closed.
 	ErrConnectionClosed = errors.New("lsp: connection closed")
+
+	// ErrDiagnosticsTimeout indicates the server published no diagnostics
+	// for a document in time.
+	ErrDiagnosticsTimeout = errors.New("lsp: timed out waiting for diagnostics")
+
+	// ErrStaleDiagnostics indicates the server published diagnostics only
+	// for an earlier version of a document before the wait timed out.
+	ErrStaleDiagnostics = errors.New("lsp: timed out with only stale diagnostics")
+
+	// ErrUnsupportedEdit indicates a workspace edit the client cannot apply.
+	ErrUnsupportedEdit = errors.New("lsp: unsupported workspace edit")
 )
 
 // DiagnosticSeverity represents the severity level of a diagnostic.
@@ -118,6 +130,29 @@ type Diagnostic struct {
 	Message string `json:"message"`
 }
 
+// UnmarshalJSON decodes a diagnostic whose code is a string or, as many
+// servers send it, a number.
+func (d *Diagnostic) UnmarshalJSON(data []byte) error {
+	type plain Diagnostic
+	var raw struct {
+		plain
+		Code json.RawMessage `json:"code,omitempty"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*d = Diagnostic(raw.plain)
+	d.Code = ""
+	if len(raw.Code) > 0 && string(raw.Code) != "null" {
+		var code string
+		if json.Unmarshal(raw.Code, &code) != nil {
+			code = string(raw.Code)
+		}
+		d.Code = code
+	}
+	return nil
+}
+
 // IsError reports whether this diagnostic is an error.
 func (d Diagnostic) IsError() bool {
 	return d.Severity == SeverityError
@@ -171,6 +206,44 @@ const (
 	SymbolKindTypeParameter SymbolKind = 26
 )
 
+// symbolKindNames maps symbol kinds to their names.
+var symbolKindNames = map[SymbolKind]string{
+	SymbolKindFile:          "file",
+	SymbolKindModule:        "module",
+	SymbolKindNamespace:     "namespace",
+	SymbolKindPackage:       "package",
+	SymbolKindClass:         "class",
+	SymbolKindMethod:        "method",
+	SymbolKindProperty:      "property",
+	SymbolKindField:         "field",
+	SymbolKindConstructor:   "constructor",
+	SymbolKindEnum:          "enum",
+	SymbolKindInterface:     "interface",
+	SymbolKindFunction:      "function",
+	SymbolKindVariable:      "variable",
+	SymbolKindConstant:      "constant",
+	SymbolKindString:        "string",
+	SymbolKindNumber:        "number",
+	SymbolKindBoolean:       "boolean",
+	SymbolKindArray:         "array",
+	SymbolKindObject:        "object",
+	SymbolKindKey:           "key",
+	SymbolKindNull:          "null",
+	SymbolKindEnumMember:    "enum member",
+	SymbolKindStruct:        "struct",
+	SymbolKindEvent:         "event",
+	SymbolKindOperator:      "operator",
+	SymbolKindTypeParameter: "type parameter",
+}
+
+// String returns the lowercase name of the symbol kind.
+func (k SymbolKind) String() string {
+	if name, ok := symbolKindNames[k]; ok {
+		return name
+	}
+	return "unknown"
+}
+
 // DocumentSymbol represents a programming construct like a variable, class, or function
 // that appears in a document. Symbols can be hierarchical via Children.
 type DocumentSymbol struct {
@@ -183,6 +256,9 @@ type DocumentSymbol struct {
 	// Range is the range enclosing this symbol, not including leading/trailing whitespace.
 	Range Range `json:"range"`
 
+	// SelectionRange is the range of the symbol's name, within Range.
+	SelectionRange Range `json:"selectionRange"`
+
 	// Children contains child symbols (e.g., struct fields, class methods).
 	Children []DocumentSymbol `json:"children,omitempty"`
 }
@@ -192,3 +268,110 @@ type InitializeResult struct {
 	// Capabilities describes the server's capabilities.
 	Capabilities json.RawMessage `json:"capabilities"`
 }
+
+// SymbolInformation describes a symbol found by a workspace symbol search.
+type SymbolInformation struct {
+	// Name is the symbol's name.
+	Name string `json:"name"`
+
+	// Kind is the symbol's kind (function, class, variable, etc.).
+	Kind SymbolKind `json:"kind"`
+
+	// Location is where the symbol is defined. Some servers return only
+	// the URI, leaving the range empty.
+	Location Location `json:"location"`
+
+	// ContainerName is the name of the enclosing symbol, if any.
+	ContainerName string `json:"containerName,omitempty"`
+}
+
+// TextEdit replaces the text of a range with NewText.
+type TextEdit struct {
+	// Range is the range to replace. An empty range inserts NewText.
+	Range Range `json:"range"`
+
+	// NewText is the replacement text.
+	NewText string `json:"newText"`
+}
+
+// WorkspaceEdit holds the text edits of a refactoring across documents.
+type WorkspaceEdit struct {
+	// Changes maps document URIs to their text edits.
+	Changes map[string][]TextEdit `json:"changes"`
+}
+
+// UnmarshalJSON decodes a workspace edit given as changes or as
+// documentChanges, merging the text document edits of the latter into
+// Changes. File create, rename and delete operations are not supported
+// and make decoding fail.
+func (e *WorkspaceEdit) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Changes         map[string][]TextEdit `json:"changes"`
+		DocumentChanges []json.RawMessage     `json:"documentChanges"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	e.Changes = raw.Changes
+	if e.Changes == nil {
+		e.Changes = make(map[string][]TextEdit)
+	}
+	for _, change := range raw.DocumentChanges {
+		var edit struct {
+			Kind         string                 `json:"kind"`
+			TextDocument textDocumentIdentifier `json:"textDocument"`
+			Edits        []TextEdit             `json:"edits"`
+		}
+		if err := json.Unmarshal(change, &edit); err != nil {
+			return err
+		}
+		if edit.Kind != "" {
+			return fmt.Errorf("%w: %s operation", ErrUnsupportedEdit, edit.Kind)
+		}
+		e.Changes[edit.TextDocument.URI] = append(e.Changes[edit.TextDocument.URI], edit.Edits...)
+	}
+	return nil
+}
+
+// Command is a server command, run with workspace/executeCommand.
+type Command struct {
+	// Title is the command's human-readable title.
+	Title string `json:"title"`
+
+	// Command is the identifier of the command.
+	Command string `json:"command"`
+
+	// Arguments are passed to the command.
+	Arguments []json.RawMessage `json:"arguments,omitempty"`
+}
+
+// CodeAction is a fix or refactoring offered by the server for a range.
+type CodeAction struct {
+	// Title is the action's human-readable title.
+	Title string `json:"title"`
+
+	// Kind is the action's kind (e.g. "quickfix", "refactor.extract").
+	Kind string `json:"kind,omitempty"`
+
+	// IsPreferred marks the action the server recommends.
+	IsPreferred bool `json:"isPreferred,omitempty"`
+
+	// Edit is the change the action makes. Servers that resolve actions
+	// lazily leave it nil until codeAction/resolve.
+	Edit *WorkspaceEdit `json:"edit,omitempty"`
+
+	// Command is run after Edit is applied, if set.
+	Command *Command `json:"command,omitempty"`
+
+	// Data is kept by the server for codeAction/resolve.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// FormattingOptions configures document formatting.
+type FormattingOptions struct {
+	// TabSize is the size of a tab in spaces.
+	TabSize int `json:"tabSize"`
+
+	// InsertSpaces prefers spaces over tabs.
+	InsertSpaces bool `json:"insertSpaces"`
+}
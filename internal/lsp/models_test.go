@@ -530,3 +530,40 @@ func TestInitializeResultJSON(t *testing.T) {
 		t.Errorf("hoverProvider = %v, want true", caps["hoverProvider"])
 	}
 }
+
+func TestDiagnosticJSONNumericCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		data string
+		want string
+	}{
+		{`{"severity":1,"code":2304,"message":"Cannot find name"}`, "2304"},
+		{`{"severity":1,"code":"E0425","message":"unresolved name"}`, "E0425"},
+		{`{"severity":1,"code":null,"message":"no code"}`, ""},
+		{`{"severity":1,"message":"no code"}`, ""},
+	}
+	for _, tt := range tests {
+		var got Diagnostic
+		if err := json.Unmarshal([]byte(tt.data), &got); err != nil {
+			t.Fatalf("Unmarshal(%s) error: %v", tt.data, err)
+		}
+		if got.Code != tt.want || got.Severity != SeverityError || got.Message == "" {
+			t.Errorf("Unmarshal(%s) = %+v, want code %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestSymbolKindString(t *testing.T) {
+	t.Parallel()
+
+	if got := SymbolKindFunction.String(); got != "function" {
+		t.Errorf("SymbolKindFunction.String() = %q", got)
+	}
+	if got := SymbolKindTypeParameter.String(); got != "type parameter" {
+		t.Errorf("SymbolKindTypeParameter.String() = %q", got)
+	}
+	if got := SymbolKind(99).String(); got != "unknown" {
+		t.Errorf("SymbolKind(99).String() = %q", got)
+	}
+}
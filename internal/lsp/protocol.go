@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -54,6 +55,14 @@ type jsonrpcNotification struct {
 	Params  json.RawMessage `json:"params,omitempty"`
 }
 
+// jsonrpcResponse is an outgoing JSON-RPC 2.0 response message.
+type jsonrpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *JSONRPCError   `json:"error,omitempty"`
+}
+
 // incomingMessage represents any incoming JSON-RPC 2.0 message from the server.
 type incomingMessage struct {
 	JSONRPC string          `json:"jsonrpc"`
@@ -172,6 +181,16 @@ func TCPTransport(addr string) (*StreamTransport, error) {
 	return NewStreamTransport(conn, conn, conn), nil
 }
 
+// NotificationHandler handles a notification sent by the server. Handlers
+// run on the connection's read loop, in message order, and must not block.
+type NotificationHandler func(ctx context.Context, params json.RawMessage)
+
+// RequestHandler answers a request sent by the server. The result is sent
+// back as the response; an error is sent as an error response, keeping the
+// code of a *JSONRPCError. Handlers run concurrently with the read loop and
+// may call back into the connection.
+type RequestHandler func(ctx context.Context, params json.RawMessage) (any, error)
+
 // Conn represents a JSON-RPC 2.0 connection that can send requests and notifications.
 type Conn interface {
 	// Call sends a JSON-RPC request and unmarshals the response result into result.
@@ -181,33 +200,67 @@ type Conn interface {
 	// Notify sends a JSON-RPC notification (no response expected).
 	Notify(ctx context.Context, method string, params any) error
 
+	// OnNotification registers the handler of server notifications of
+	// method, replacing any previous one. Notifications without a handler
+	// are dropped.
+	OnNotification(method string, handler NotificationHandler)
+
+	// OnRequest registers the handler of server requests of method,
+	// replacing any previous one. Requests without a handler are answered
+	// with a method-not-found error.
+	OnRequest(method string, handler RequestHandler)
+
 	// Close closes the connection and releases resources.
 	Close() error
 }
 
 // connection implements Conn using a MessageTransport.
 type connection struct {
-	transport MessageTransport
-	nextID    atomic.Int64
-	pending   map[int64]chan *incomingMessage
-	mu        sync.Mutex
-	done      chan struct{}
-	closeOnce sync.Once
-	closeErr  error
+	transport     MessageTransport
+	nextID        atomic.Int64
+	pending       map[int64]chan *incomingMessage
+	notifications map[string]NotificationHandler
+	requests      map[string]RequestHandler
+	mu            sync.Mutex
+	ctx           context.Context
+	cancel        context.CancelFunc
+	done          chan struct{}
+	closeOnce     sync.Once
+	closeErr      error
 }
 
 // NewConn creates a new JSON-RPC connection over the given transport.
-// It starts a background goroutine to read and dispatch responses.
+// It starts a background goroutine to read and dispatch responses and
+// server messages.
 func NewConn(transport MessageTransport) Conn {
+	ctx, cancel := context.WithCancel(context.Background())
 	c := &connection{
-		transport: transport,
-		pending:   make(map[int64]chan *incomingMessage),
-		done:      make(chan struct{}),
+		transport:     transport,
+		pending:       make(map[int64]chan *incomingMessage),
+		notifications: make(map[string]NotificationHandler),
+		requests:      make(map[string]RequestHandler),
+		ctx:           ctx,
+		cancel:        cancel,
+		done:          make(chan struct{}),
 	}
 	go c.readLoop()
 	return c
 }
 
+// OnNotification registers the handler of server notifications of method.
+func (c *connection) OnNotification(method string, handler NotificationHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.notifications[method] = handler
+}
+
+// OnRequest registers the handler of server requests of method.
+func (c *connection) OnRequest(method string, handler RequestHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests[method] = handler
+}
+
 // Call sends a JSON-RPC request and waits for the matching response.
 func (c *connection) Call(ctx context.Context, method string, params any, result any) error {
 	select {
@@ -302,13 +355,15 @@ func (c *connection) Notify(ctx context.Context, method string, params any) erro
 func (c *connection) Close() error {
 	c.closeOnce.Do(func() {
 		close(c.done)
+		c.cancel()
 		c.closeErr = c.transport.Close()
 		c.drainPending()
 	})
 	return c.closeErr
 }
 
-// readLoop reads messages from the transport and dispatches responses.
+// readLoop reads messages from the transport and dispatches responses to
+// pending calls and server messages to their handlers.
 func (c *connection) readLoop() {
 	for {
 		msg, err := c.transport.ReadMessage(context.Background())
@@ -342,10 +397,60 @@ func (c *connection) readLoop() {
 			if ch != nil {
 				ch <- &incoming
 			}
+			continue
+		}
+
+		if incoming.Method == "" {
+			continue
+		}
+		if len(incoming.ID) == 0 {
+			c.mu.Lock()
+			handler := c.notifications[incoming.Method]
+			c.mu.Unlock()
+			if handler != nil {
+				handler(c.ctx, incoming.Params)
+			}
+			continue
+		}
+
+		// Server requests are answered concurrently so that handlers can
+		// call back into the connection.
+		c.mu.Lock()
+		handler := c.requests[incoming.Method]
+		c.mu.Unlock()
+		go c.answer(&incoming, handler)
+	}
+}
+
+// answer runs the handler of a server request and writes its response.
+func (c *connection) answer(req *incomingMessage, handler RequestHandler) {
+	resp := jsonrpcResponse{JSONRPC: "2.0", ID: req.ID}
+	if handler == nil {
+		resp.Error = &JSONRPCError{Code: CodeMethodNotFound, Message: "method not found: " + req.Method}
+	} else {
+		result, err := handler(c.ctx, req.Params)
+		var rpcErr *JSONRPCError
+		switch {
+		case errors.As(err, &rpcErr):
+			resp.Error = rpcErr
+		case err != nil:
+			resp.Error = &JSONRPCError{Code: CodeInternalError, Message: err.Error()}
+		case result == nil:
+			resp.Result = json.RawMessage("null")
+		default:
+			resp.Result = result
 		}
-		// Notifications from the server are currently ignored.
-		// Future: dispatch to registered notification handlers.
 	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(jsonrpcResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error:   &JSONRPCError{Code: CodeInternalError, Message: fmt.Sprintf("marshal %s result: %v", req.Method, err)},
+		})
+	}
+	_ = c.transport.WriteMessage(c.ctx, json.RawMessage(data))
 }
 
 // removePending removes a pending request channel by ID.
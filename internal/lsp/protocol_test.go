@@ -401,6 +401,83 @@ func TestConnectionNotify(t *testing.T) {
 	}
 }
 
+func TestConnectionServerMessages(t *testing.T) {
+	t.Parallel()
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	clientTransport := NewStreamTransport(clientReader, clientWriter, &multiCloser{clientReader, clientWriter})
+	serverTransport := NewStreamTransport(serverReader, serverWriter, &multiCloser{serverReader, serverWriter})
+
+	conn := NewConn(clientTransport)
+	defer func() { _ = conn.Close() }()
+
+	notified := make(chan string, 1)
+	conn.OnNotification("window/logMessage", func(_ context.Context, params json.RawMessage) {
+		notified <- string(params)
+	})
+	conn.OnRequest("workspace/configuration", func(_ context.Context, _ json.RawMessage) (any, error) {
+		return []any{map[string]any{"staticcheck": true}}, nil
+	})
+	conn.OnRequest("window/workDoneProgress/create", func(_ context.Context, _ json.RawMessage) (any, error) {
+		return nil, nil
+	})
+	conn.OnRequest("workspace/fail", func(_ context.Context, _ json.RawMessage) (any, error) {
+		return nil, &JSONRPCError{Code: CodeInvalidParams, Message: "bad params"}
+	})
+
+	ctx := context.Background()
+	send := func(msg string) {
+		t.Helper()
+		if err := serverTransport.WriteMessage(ctx, json.RawMessage(msg)); err != nil {
+			t.Fatalf("server WriteMessage error: %v", err)
+		}
+	}
+	type response struct {
+		ID     json.RawMessage `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *JSONRPCError   `json:"error"`
+	}
+	receive := func() response {
+		t.Helper()
+		data, err := serverTransport.ReadMessage(ctx)
+		if err != nil {
+			t.Fatalf("server ReadMessage error: %v", err)
+		}
+		var resp response
+		if err := json.Unmarshal(data, &resp); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		return resp
+	}
+
+	send(`{"jsonrpc":"2.0","method":"window/logMessage","params":{"message":"ready"}}`)
+	if got := <-notified; got != `{"message":"ready"}` {
+		t.Errorf("notification params = %s", got)
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"workspace/configuration","params":{"items":[{"section":"gopls"}]}}`)
+	if resp := receive(); resp.Error != nil || string(resp.Result) != `[{"staticcheck":true}]` {
+		t.Errorf("configuration response = %s, error %v", resp.Result, resp.Error)
+	}
+
+	send(`{"jsonrpc":"2.0","id":"token","method":"window/workDoneProgress/create","params":{"token":"1"}}`)
+	if resp := receive(); resp.Error != nil || string(resp.Result) != "null" || string(resp.ID) != `"token"` {
+		t.Errorf("progress response = %s (id %s), error %v", resp.Result, resp.ID, resp.Error)
+	}
+
+	send(`{"jsonrpc":"2.0","id":3,"method":"workspace/fail"}`)
+	if resp := receive(); resp.Error == nil || resp.Error.Code != CodeInvalidParams {
+		t.Errorf("failed request error = %v, want CodeInvalidParams", resp.Error)
+	}
+
+	send(`{"jsonrpc":"2.0","id":4,"method":"workspace/unknown"}`)
+	if resp := receive(); resp.Error == nil || resp.Error.Code != CodeMethodNotFound {
+		t.Errorf("unknown request error = %v, want CodeMethodNotFound", resp.Error)
+	}
+}
+
 func TestConnectionCallAfterClose(t *testing.T) {
 	t.Parallel()
 
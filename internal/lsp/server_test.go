@@ -101,6 +101,34 @@ func (c *serverTestClient) Symbols(_ context.Context, _ string) ([]DocumentSymbo
 	return []DocumentSymbol{}, nil
 }
 
+func (c *serverTestClient) WorkspaceSymbols(_ context.Context, _ string) ([]SymbolInformation, error) {
+	return []SymbolInformation{}, nil
+}
+
+func (c *serverTestClient) Rename(_ context.Context, _ string, _ Position, _ string) (*WorkspaceEdit, error) {
+	return &WorkspaceEdit{}, nil
+}
+
+func (c *serverTestClient) CodeActions(_ context.Context, _ string, _ Range) ([]CodeAction, error) {
+	return []CodeAction{}, nil
+}
+
+func (c *serverTestClient) ResolveCodeAction(_ context.Context, _ string, action CodeAction) (*CodeAction, error) {
+	return &action, nil
+}
+
+func (c *serverTestClient) Format(_ context.Context, _ string, _ FormattingOptions) ([]TextEdit, error) {
+	return []TextEdit{}, nil
+}
+
+func (c *serverTestClient) OpenDocument(_ context.Context, _ string) error { return nil }
+
+func (c *serverTestClient) ChangeDocument(_ context.Context, _, _ string) error { return nil }
+
+func (c *serverTestClient) SaveDocument(_ context.Context, _ string) error { return nil }
+
+func (c *serverTestClient) CloseDocument(_ context.Context, _ string) error { return nil }
+
 func (c *serverTestClient) Shutdown(ctx context.Context) error {
 	if c.shutdownFn != nil {
 		return c.shutdownFn(ctx)
@@ -1,6 +1,8 @@
 // Package ralph implements the decision engine for the Ralph Feedback Loop.
 // It evaluates loop state and feedback to determine the next action:
-// continue, converge, request human review, or abort.
+// continue, converge, request human review, or abort. The feedback it
+// evaluates comes from the project's build, test, lint and coverage
+// commands, run by CommandFeedbackGenerator.
 package ralph
 
 import (
@@ -13,12 +15,28 @@ import (
 
 // RalphEngine implements loop.DecisionEngine using configurable heuristics.
 type RalphEngine struct {
-	cfg config.RalphConfig
+	cfg            config.RalphConfig
+	coverageTarget float64
+}
+
+// EngineOption configures a RalphEngine.
+type EngineOption func(*RalphEngine)
+
+// WithCoverageTarget sets the minimum coverage percentage of the quality
+// gate. The default is loop.DefaultCoverageTarget.
+func WithCoverageTarget(target float64) EngineOption {
+	return func(e *RalphEngine) {
+		e.coverageTarget = target
+	}
 }
 
 // NewRalphEngine creates a new decision engine with the given configuration.
-func NewRalphEngine(cfg config.RalphConfig) *RalphEngine {
-	return &RalphEngine{cfg: cfg}
+func NewRalphEngine(cfg config.RalphConfig, opts ...EngineOption) *RalphEngine {
+	e := &RalphEngine{cfg: cfg, coverageTarget: loop.DefaultCoverageTarget}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
 }
 
 // Decide evaluates the current loop state and feedback to produce a Decision.
@@ -46,7 +64,7 @@ func (e *RalphEngine) Decide(_ context.Context, state *loop.LoopState, feedback
 	}
 
 	// 2. Perfect success: all quality gates satisfied.
-	if loop.MeetsQualityGate(feedback) {
+	if loop.MeetsQualityGateAt(feedback, e.coverageTarget) {
 		return &loop.Decision{
 			Action:    loop.ActionConverge,
 			NextPhase: loop.PhaseAnalyze,
@@ -256,3 +256,26 @@ func TestRalphEngine_Decide(t *testing.T) {
 		})
 	}
 }
+
+func TestRalphEngine_CoverageTarget(t *testing.T) {
+	t.Parallel()
+
+	state := &loop.LoopState{SpecID: "SPEC-TEST", Phase: loop.PhaseReview, Iteration: 1, MaxIter: 5}
+	fb := &loop.Feedback{BuildSuccess: true, Coverage: 72.0}
+
+	decision, err := NewRalphEngine(config.RalphConfig{}).Decide(context.Background(), state, fb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Converged {
+		t.Errorf("default target: decision = %+v, want 72%% below 85%%", decision)
+	}
+
+	decision, err = NewRalphEngine(config.RalphConfig{}, WithCoverageTarget(70)).Decide(context.Background(), state, fb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Action != loop.ActionConverge {
+		t.Errorf("target 70%%: decision = %+v, want converge", decision)
+	}
+}
@@ -0,0 +1,447 @@
+package ralph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+	"github.com/modu-ai/moai-adk/internal/core/checks"
+	"github.com/modu-ai/moai-adk/internal/core/coverage"
+	"github.com/modu-ai/moai-adk/internal/foundation"
+	"github.com/modu-ai/moai-adk/internal/loop"
+)
+
+// ErrNoCommands indicates that no feedback command was configured or
+// detected for the project.
+var ErrNoCommands = errors.New("ralph: no build, test or lint command for the project")
+
+// skippedDirs are not scanned for source files.
+var skippedDirs = map[string]bool{"vendor": true, "node_modules": true, "target": true, "dist": true, "build": true}
+
+var (
+	// goTestLine matches a top-level "go test -v" result line.
+	goTestLine = regexp.MustCompile(`^--- (PASS|FAIL): \S+`)
+	// rspecSummary matches "10 examples, 2 failures".
+	rspecSummary = regexp.MustCompile(`(\d+) examples?, (\d+) failures?`)
+	// testCount matches the "3 passed" and "1 failed" counts of pytest,
+	// vitest, jest and cargo summaries.
+	testCount = regexp.MustCompile(`(\d+) (passed|failed|errors?)\b`)
+	// lintLocation matches a "file:line:" diagnostic.
+	lintLocation = regexp.MustCompile(`^\S+:\d+(:\d+)?:`)
+)
+
+// DetectCommands returns the feedback commands of the primary language
+// of root, the known language with the most production source files,
+// with the non-empty commands of overrides taking precedence. The build,
+// test and lint commands of a language are its registry templates
+// expanded for the whole project; languages without them fall back to
+// their coverage command.
+func DetectCommands(root string, registry *foundation.LanguageRegistry, overrides config.RalphCommands) config.RalphCommands {
+	if registry == nil {
+		registry = foundation.DefaultRegistry
+	}
+	counts := make(map[foundation.SupportedLanguage]int)
+	_ = walkSources(root, registry, func(rel string, _ fs.FileInfo) {
+		if info, err := registry.ByExtension(filepath.Ext(rel)); err == nil && registry.IsProductionSource(rel) {
+			counts[info.ID]++
+		}
+	})
+
+	langs := make([]foundation.SupportedLanguage, 0, len(counts))
+	for lang := range counts {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if counts[langs[i]] != counts[langs[j]] {
+			return counts[langs[i]] > counts[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+
+	var detected config.RalphCommands
+	for _, lang := range langs {
+		cmds := config.RalphCommands{
+			Build: checks.ProjectCommand(checks.Template(registry, lang, checks.Build)),
+			Test:  checks.ProjectCommand(checks.Template(registry, lang, checks.Test)),
+			Lint:  checks.ProjectCommand(checks.Template(registry, lang, checks.Lint)),
+		}
+		if cmds != (config.RalphCommands{}) {
+			detected = cmds
+			break
+		}
+		if info, err := registry.Get(lang); err == nil && info.CoverageCommand != "" {
+			detected = config.RalphCommands{Coverage: info.CoverageCommand}
+			break
+		}
+	}
+
+	// Detected commands whose tool is not installed are dropped; configured
+	// ones are kept so that a missing tool fails the loop.
+	for _, c := range []struct {
+		dst      *string
+		override string
+	}{
+		{&detected.Build, overrides.Build},
+		{&detected.Test, overrides.Test},
+		{&detected.Lint, overrides.Lint},
+		{&detected.Coverage, overrides.Coverage},
+	} {
+		switch {
+		case c.override != "":
+			*c.dst = c.override
+		case *c.dst != "":
+			if argv, err := checks.Argv(*c.dst); err != nil || !installed(argv[0]) {
+				*c.dst = ""
+			}
+		}
+	}
+	return detected
+}
+
+// installed reports whether the binary of a command is on the PATH.
+func installed(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// CommandFeedbackGenerator implements loop.FeedbackGenerator by running
+// the build, test, lint and coverage commands of a project and parsing
+// their output. Results are reused while the source files are unchanged,
+// so the phases of an iteration run the commands once.
+type CommandFeedbackGenerator struct {
+	root     string
+	commands config.RalphCommands
+	registry *foundation.LanguageRegistry
+	run      func(ctx context.Context, dir string, argv []string) (output string, exitCode int, err error)
+	lookPath func(file string) (string, error)
+
+	mu          sync.Mutex
+	fingerprint string
+	last        *loop.Feedback
+}
+
+// Compile-time interface compliance check.
+var _ loop.FeedbackGenerator = (*CommandFeedbackGenerator)(nil)
+
+// NewCommandFeedbackGenerator creates a generator running commands in root.
+func NewCommandFeedbackGenerator(root string, commands config.RalphCommands) *CommandFeedbackGenerator {
+	return &CommandFeedbackGenerator{
+		root:     root,
+		commands: commands,
+		registry: foundation.DefaultRegistry,
+		run:      checks.Run,
+		lookPath: exec.LookPath,
+	}
+}
+
+// Commands returns the commands the generator runs.
+func (g *CommandFeedbackGenerator) Commands() config.RalphCommands {
+	return g.commands
+}
+
+// Collect runs the commands and returns their feedback. Missing build or
+// lint commands count as success; a failing test command without
+// parseable counts counts as one failed test.
+func (g *CommandFeedbackGenerator) Collect(ctx context.Context) (*loop.Feedback, error) {
+	c := g.commands
+	if c.Build == "" && c.Test == "" && c.Lint == "" && c.Coverage == "" {
+		return nil, ErrNoCommands
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	fingerprint, err := g.sourceFingerprint()
+	if err != nil {
+		return nil, fmt.Errorf("ralph: scan sources: %w", err)
+	}
+	if g.last != nil && fingerprint == g.fingerprint {
+		fb := *g.last
+		return &fb, nil
+	}
+
+	start := time.Now()
+	fb := &loop.Feedback{BuildSuccess: true}
+	var notes []string
+
+	if c.Build != "" {
+		out, code, err := g.exec(ctx, c.Build)
+		if err != nil {
+			return nil, err
+		}
+		fb.BuildSuccess = code == 0
+		if !fb.BuildSuccess {
+			notes = append(notes, fmt.Sprintf("build failed (exit %d)%s", code, firstLine(out)))
+		}
+	}
+
+	var testCoverage float64
+	var testCoverageOK bool
+	if c.Test != "" {
+		out, code, err := g.exec(ctx, c.Test)
+		if err != nil {
+			return nil, err
+		}
+		fb.TestsPassed, fb.TestsFailed = parseTestCounts(out, code)
+		testCoverage, testCoverageOK = checks.ParseCoverage(out)
+	}
+
+	if c.Lint != "" {
+		out, code, err := g.exec(ctx, c.Lint)
+		if err != nil {
+			return nil, err
+		}
+		fb.LintErrors = countLintErrors(out, code)
+	}
+
+	coverageSource := ""
+	if testCoverageOK {
+		fb.Coverage, coverageSource = testCoverage, "test output"
+	}
+	if c.Coverage != "" {
+		out, code, err := g.exec(ctx, c.Coverage)
+		if err != nil {
+			return nil, err
+		}
+		if c.Test == "" {
+			fb.TestsPassed, fb.TestsFailed = parseTestCounts(out, code)
+		}
+		if pct, ok := checks.ParseCoverage(out); ok {
+			fb.Coverage, coverageSource = pct, "coverage output"
+		}
+	}
+	if format, ok := g.applyCoverageReports(ctx, fb, start); ok {
+		coverageSource = fmt.Sprintf("%s report", format)
+	}
+
+	notes = append([]string{fmt.Sprintf("tests %d passed, %d failed; lint errors %d", fb.TestsPassed, fb.TestsFailed, fb.LintErrors)}, notes...)
+	if coverageSource != "" {
+		notes = append(notes, fmt.Sprintf("coverage %.1f%% from %s", fb.Coverage, coverageSource))
+	}
+	fb.Notes = strings.Join(notes, "; ")
+	fb.Duration = time.Since(start)
+
+	g.fingerprint = fingerprint
+	last := *fb
+	g.last = &last
+	return fb, nil
+}
+
+// exec runs command in the project root, split into arguments with the
+// shell parser. A missing binary is reported as a failed run with exit
+// code 127, like a shell would.
+func (g *CommandFeedbackGenerator) exec(ctx context.Context, command string) (string, int, error) {
+	argv, err := checks.Argv(command)
+	if err != nil {
+		return "", 0, fmt.Errorf("ralph: %w", err)
+	}
+	if _, err := g.lookPath(argv[0]); err != nil {
+		return fmt.Sprintf("%s: command not found", argv[0]), 127, nil
+	}
+	out, code, err := g.run(ctx, g.root, argv)
+	if ctx.Err() != nil {
+		return "", 0, ctx.Err()
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("ralph: run %q: %w", command, err)
+	}
+	return out, code, nil
+}
+
+// applyCoverageReports sets the coverage of fb from the coverage reports
+// written since start, returning their format.
+func (g *CommandFeedbackGenerator) applyCoverageReports(ctx context.Context, fb *loop.Feedback, start time.Time) (coverage.Format, bool) {
+	var profile *coverage.Profile
+	for _, path := range coverage.FindReports(g.root) {
+		if info, err := os.Stat(path); err != nil || info.ModTime().Before(start) {
+			continue
+		}
+		p, err := coverage.ParseFile(path, g.root)
+		if err != nil {
+			continue
+		}
+		if profile == nil {
+			profile = p
+		} else {
+			profile.Merge(p)
+		}
+	}
+	if profile == nil {
+		return "", false
+	}
+	result, err := coverage.Analyze(ctx, profile, coverage.Options{Root: g.root})
+	if err != nil {
+		return "", false
+	}
+	result.ApplyToFeedback(fb)
+	return result.Format, true
+}
+
+// sourceFingerprint hashes the path, size and modification time of the
+// source files of the project.
+func (g *CommandFeedbackGenerator) sourceFingerprint() (string, error) {
+	h := sha256.New()
+	err := walkSources(g.root, g.registry, func(rel string, info fs.FileInfo) {
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00%d\n", rel, info.Size(), info.ModTime().UnixNano())
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// walkSources calls fn for every file of a known language under root,
+// outside hidden and dependency directories.
+func walkSources(root string, registry *foundation.LanguageRegistry, fn func(rel string, info fs.FileInfo)) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != root && (strings.HasPrefix(name, ".") || skippedDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, err := registry.ByExtension(filepath.Ext(path)); err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		fn(filepath.ToSlash(rel), info)
+		return nil
+	})
+}
+
+// parseTestCounts returns the passed and failed test counts printed by a
+// test command: "go test -json" events, "go test -v" result lines, an
+// RSpec summary or pytest, vitest, jest and cargo summaries. A failing
+// command without parseable failures counts one failed test.
+func parseTestCounts(output string, exitCode int) (passed, failed int) {
+	passed, failed, ok := parseGoTestJSON(output)
+	if !ok {
+		passed, failed = parseTestSummary(output)
+	}
+	if exitCode != 0 && failed == 0 {
+		failed = 1
+	}
+	return passed, failed
+}
+
+// parseGoTestJSON counts the top-level test results of "go test -json"
+// output. A package failing without a failed test, as on a build error,
+// counts as one failure.
+func parseGoTestJSON(output string) (passed, failed int, ok bool) {
+	failedTests := make(map[string]int)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var ev checks.TestEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil || ev.Action == "" {
+			continue
+		}
+		ok = true
+		if strings.Contains(ev.Test, "/") {
+			continue
+		}
+		switch {
+		case ev.Action == "pass" && ev.Test != "":
+			passed++
+		case ev.Action == "fail" && ev.Test != "":
+			failed++
+			failedTests[ev.Package]++
+		case ev.Action == "fail" && failedTests[ev.Package] == 0:
+			failed++
+		}
+	}
+	return passed, failed, ok
+}
+
+// parseTestSummary counts "go test -v" result lines, or sums the counts
+// of an RSpec summary or of "N passed" and "N failed" summaries. Test file
+// and suite totals of vitest and jest are skipped.
+func parseTestSummary(output string) (passed, failed int) {
+	lines := strings.Split(output, "\n")
+	for _, line := range lines {
+		if m := goTestLine.FindStringSubmatch(line); m != nil {
+			if m[1] == "PASS" {
+				passed++
+			} else {
+				failed++
+			}
+		}
+	}
+	if passed+failed > 0 {
+		return passed, failed
+	}
+
+	if m := rspecSummary.FindStringSubmatch(output); m != nil {
+		examples, _ := strconv.Atoi(m[1])
+		failures, _ := strconv.Atoi(m[2])
+		return examples - failures, failures
+	}
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "Test Files") || strings.HasPrefix(trimmed, "Test Suites") {
+			continue
+		}
+		for _, m := range testCount.FindAllStringSubmatch(line, -1) {
+			n, _ := strconv.Atoi(m[1])
+			if m[2] == "passed" {
+				passed += n
+			} else {
+				failed += n
+			}
+		}
+	}
+	return passed, failed
+}
+
+// countLintErrors counts the "file:line:" diagnostics of a lint command.
+// A failing command without such lines counts one error.
+func countLintErrors(output string, exitCode int) int {
+	count := 0
+	for _, line := range strings.Split(output, "\n") {
+		if lintLocation.MatchString(strings.TrimSpace(line)) {
+			count++
+		}
+	}
+	if exitCode != 0 && count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// firstLine formats the first non-empty line of output for a note.
+func firstLine(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return ": " + line
+		}
+	}
+	return ""
+}
@@ -0,0 +1,298 @@
+package ralph
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/config"
+)
+
+func TestParseTestCounts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		output     string
+		exitCode   int
+		wantPassed int
+		wantFailed int
+	}{
+		{
+			name: "go test -json",
+			output: `{"Action":"run","Package":"a","Test":"TestA"}
+{"Action":"pass","Package":"a","Test":"TestA"}
+{"Action":"pass","Package":"a","Test":"TestB/sub"}
+{"Action":"pass","Package":"a","Test":"TestB"}
+{"Action":"fail","Package":"a","Test":"TestC"}
+{"Action":"fail","Package":"a"}
+{"Action":"fail","Package":"b"}`,
+			exitCode:   1,
+			wantPassed: 2,
+			wantFailed: 2, // TestC, and package b failing to build
+		},
+		{
+			name:       "go test -v",
+			output:     "=== RUN   TestA\n--- PASS: TestA (0.00s)\n--- FAIL: TestB (0.00s)\n    --- PASS: TestB/sub (0.00s)\nFAIL",
+			exitCode:   1,
+			wantPassed: 1,
+			wantFailed: 1,
+		},
+		{
+			name:       "pytest",
+			output:     "..F.\n==== 1 failed, 3 passed, 1 error in 0.12s ====",
+			exitCode:   1,
+			wantPassed: 3,
+			wantFailed: 2,
+		},
+		{
+			name:       "vitest",
+			output:     " Test Files  1 failed | 2 passed (3)\n      Tests  2 failed | 8 passed (10)",
+			exitCode:   1,
+			wantPassed: 8,
+			wantFailed: 2,
+		},
+		{
+			name:       "cargo",
+			output:     "test result: ok. 4 passed; 0 failed; 0 ignored\ntest result: ok. 1 passed; 0 failed; 0 ignored",
+			wantPassed: 5,
+		},
+		{
+			name:       "rspec",
+			output:     "Finished in 0.1 seconds\n10 examples, 2 failures",
+			exitCode:   1,
+			wantPassed: 8,
+			wantFailed: 2,
+		},
+		{
+			name:       "failure without counts",
+			output:     "segmentation fault",
+			exitCode:   139,
+			wantFailed: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			passed, failed := parseTestCounts(tt.output, tt.exitCode)
+			if passed != tt.wantPassed || failed != tt.wantFailed {
+				t.Errorf("parseTestCounts() = %d passed, %d failed; want %d, %d", passed, failed, tt.wantPassed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestCountLintErrors(t *testing.T) {
+	t.Parallel()
+
+	output := "# example.com/a\n./a.go:3:2: unreachable code\nb.py:10:1: F401 unused import\nFound 2 errors."
+	if got := countLintErrors(output, 1); got != 2 {
+		t.Errorf("countLintErrors() = %d, want 2", got)
+	}
+	if got := countLintErrors("error: something went wrong", 1); got != 1 {
+		t.Errorf("countLintErrors() without locations = %d, want 1", got)
+	}
+	if got := countLintErrors("", 0); got != 0 {
+		t.Errorf("countLintErrors() on success = %d, want 0", got)
+	}
+}
+
+// writeSources creates files under a temp dir and returns it.
+func writeSources(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestDetectCommands(t *testing.T) {
+	root := writeSources(t, map[string]string{
+		"main.go":               "package main\n",
+		"util.go":               "package main\n",
+		"util_test.go":          "package main\n",
+		"scripts/tool.py":       "print()\n",
+		"node_modules/x/a.ts":   "export {}\n",
+		"node_modules/x/b.ts":   "export {}\n",
+		"node_modules/x/c.ts":   "export {}\n",
+		".hidden/generated.py":  "print()\n",
+		".hidden/generated2.py": "print()\n",
+	})
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "go"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	got := DetectCommands(root, nil, config.RalphCommands{Lint: "golangci-lint run"})
+	want := config.RalphCommands{
+		Build: "go build ./...",
+		Test:  "go test -json -cover ./...",
+		Lint:  "golangci-lint run",
+	}
+	if got != want {
+		t.Errorf("DetectCommands() = %+v, want %+v", got, want)
+	}
+
+	// Without go installed the detected Go commands are dropped.
+	t.Setenv("PATH", t.TempDir())
+	if got := DetectCommands(root, nil, config.RalphCommands{}); got != (config.RalphCommands{}) {
+		t.Errorf("DetectCommands() without tools = %+v, want none", got)
+	}
+}
+
+// fakeRunner records the commands run and answers them from outputs.
+type fakeRunner struct {
+	outputs map[string]fakeResult
+	calls   []string
+}
+
+type fakeResult struct {
+	output string
+	code   int
+}
+
+func (f *fakeRunner) run(_ context.Context, _ string, argv []string) (string, int, error) {
+	command := strings.Join(argv, " ")
+	f.calls = append(f.calls, command)
+	r := f.outputs[argv[0]]
+	return r.output, r.code, nil
+}
+
+func newFakeGenerator(root string, commands config.RalphCommands, runner *fakeRunner) *CommandFeedbackGenerator {
+	g := NewCommandFeedbackGenerator(root, commands)
+	g.run = runner.run
+	g.lookPath = func(file string) (string, error) {
+		if file == "missing" {
+			return "", errors.New("not found")
+		}
+		return file, nil
+	}
+	return g
+}
+
+func TestCommandFeedbackGenerator_Collect(t *testing.T) {
+	root := writeSources(t, map[string]string{"calc.go": "package calc\n"})
+	runner := &fakeRunner{outputs: map[string]fakeResult{
+		"build": {"", 0},
+		"test":  {"--- PASS: TestA (0.00s)\n--- FAIL: TestB (0.00s)\ncoverage: 72.5% of statements", 1},
+		"lint":  {"calc.go:1:1: missing doc comment", 1},
+	}}
+	g := newFakeGenerator(root, config.RalphCommands{Build: "build ./...", Test: "test ./...", Lint: "lint ./..."}, runner)
+
+	fb, err := g.Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if !fb.BuildSuccess || fb.TestsPassed != 1 || fb.TestsFailed != 1 || fb.LintErrors != 1 || fb.Coverage != 72.5 {
+		t.Errorf("feedback = %+v", fb)
+	}
+	if !strings.Contains(fb.Notes, "tests 1 passed, 1 failed") || !strings.Contains(fb.Notes, "coverage 72.5% from test output") {
+		t.Errorf("Notes = %q", fb.Notes)
+	}
+
+	// Unchanged sources reuse the previous results.
+	if _, err := g.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(runner.calls) != 3 {
+		t.Errorf("commands run = %v, want each once while sources are unchanged", runner.calls)
+	}
+
+	// A changed file runs the commands again.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(root, "calc.go"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(runner.calls) != 6 {
+		t.Errorf("commands run = %v, want a second run after the change", runner.calls)
+	}
+}
+
+func TestCommandFeedbackGenerator_CoverageSources(t *testing.T) {
+	root := writeSources(t, map[string]string{"calc.go": "package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n"})
+	runner := &fakeRunner{outputs: map[string]fakeResult{
+		"test":     {"4 passed", 0},
+		"coverage": {"TOTAL 10 1 90%", 0},
+	}}
+	g := newFakeGenerator(root, config.RalphCommands{Test: "test", Coverage: "coverage", Build: "missing"}, runner)
+
+	fb, err := g.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fb.BuildSuccess || !strings.Contains(fb.Notes, "build failed (exit 127): missing: command not found") {
+		t.Errorf("feedback = %+v, want the missing build tool to fail the build", fb)
+	}
+	if fb.TestsPassed != 4 || fb.Coverage != 90 {
+		t.Errorf("feedback = %+v, want 4 passed tests and the coverage command's 90%%", fb)
+	}
+
+	// A coverage report written during the run takes precedence.
+	runner.outputs["coverage"] = fakeResult{"TOTAL 10 1 90%", 0}
+	g = newFakeGenerator(root, config.RalphCommands{Test: "test"}, runner)
+	g.run = func(ctx context.Context, dir string, argv []string) (string, int, error) {
+		profile := "mode: set\ncalc.go:3.24,5.2 1 0\n"
+		if err := os.WriteFile(filepath.Join(root, "coverage.out"), []byte(profile), 0o644); err != nil {
+			return "", 0, err
+		}
+		return runner.run(ctx, dir, argv)
+	}
+	fb, err = g.Collect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fb.Coverage != 0 || !strings.Contains(fb.Notes, "from go report") {
+		t.Errorf("feedback = %+v, want the report's 0%% coverage", fb)
+	}
+}
+
+func TestCommandFeedbackGenerator_ShellWords(t *testing.T) {
+	root := writeSources(t, map[string]string{"calc.go": "package calc\n"})
+	var argvs [][]string
+	g := newFakeGenerator(root, config.RalphCommands{Build: "make build && make check", Test: `pytest -k "not slow"`}, &fakeRunner{})
+	g.run = func(_ context.Context, _ string, argv []string) (string, int, error) {
+		argvs = append(argvs, argv)
+		return "", 0, nil
+	}
+
+	if _, err := g.Collect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"sh", "-c", "make build && make check"},
+		{"pytest", "-k", "not slow"},
+	}
+	if !reflect.DeepEqual(argvs, want) {
+		t.Errorf("commands run = %q, want %q", argvs, want)
+	}
+
+	g = newFakeGenerator(root, config.RalphCommands{Test: `pytest -k "not slow`}, &fakeRunner{})
+	if _, err := g.Collect(context.Background()); err == nil {
+		t.Error("Collect() with an unterminated quote error = nil")
+	}
+}
+
+func TestCommandFeedbackGenerator_NoCommands(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCommandFeedbackGenerator(t.TempDir(), config.RalphCommands{}).Collect(context.Background())
+	if !errors.Is(err, ErrNoCommands) {
+		t.Errorf("Collect() error = %v, want ErrNoCommands", err)
+	}
+}
@@ -50,6 +50,11 @@ func (e *AuthenticationError) Error() string {
 	return fmt.Sprintf("rank authentication error: %s", e.Message)
 }
 
+// IsClientError reports that authentication failures are not retried.
+func (e *AuthenticationError) IsClientError() bool {
+	return true
+}
+
 // ApiError represents an API response error.
 type ApiError struct {
 	Message    string
@@ -61,6 +66,14 @@ func (e *ApiError) Error() string {
 	return fmt.Sprintf("rank API error (status %d): %s", e.StatusCode, e.Message)
 }
 
+// IsClientError reports whether the server refused the request itself
+// (4xx other than timeouts and rate limiting), which retrying cannot fix.
+func (e *ApiError) IsClientError() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 &&
+		e.StatusCode != http.StatusRequestTimeout &&
+		e.StatusCode != http.StatusTooManyRequests
+}
+
 // --- Data Models ---
 
 // ApiStatus represents the Rank API health status response.
@@ -0,0 +1,393 @@
+// Package rank provides a durable outbox for MoAI Rank session submissions.
+package rank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/defs"
+	"github.com/modu-ai/moai-adk/internal/resilience"
+)
+
+// OutboxSubdir is the directory under ~/.moai/rank holding queued sessions.
+const OutboxSubdir = "outbox"
+
+// DefaultOutboxRetryPolicy retries a failed batch twice with a short
+// backoff, so that a flush at SessionStart or SessionEnd stays quick.
+var DefaultOutboxRetryPolicy = resilience.RetryPolicy{
+	MaxRetries: 2,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   2 * time.Second,
+	UseJitter:  true,
+}
+
+// DefaultOutboxBreakerThreshold is the number of failed submissions after
+// which a flush stops trying the remaining batches.
+const DefaultOutboxBreakerThreshold = 2
+
+// OutboxItem is a session submission waiting in the outbox.
+type OutboxItem struct {
+	Session        *SessionSubmission `json:"session"`
+	TranscriptPath string             `json:"transcriptPath,omitempty"`
+	QueuedAt       time.Time          `json:"queuedAt"`
+	Attempts       int                `json:"attempts"`
+	LastAttemptAt  time.Time          `json:"lastAttemptAt,omitzero"`
+	LastError      string             `json:"lastError,omitempty"`
+}
+
+// FlushResult summarizes a flush of the outbox.
+type FlushResult struct {
+	// Submitted is the number of sessions the server accepted.
+	Submitted int
+
+	// Rejected is the number of sessions the server refused as invalid.
+	// They are removed from the outbox.
+	Rejected int
+
+	// Pending is the number of sessions left in the outbox.
+	Pending int
+}
+
+// Outbox is a durable queue of session submissions under
+// ~/.moai/rank/outbox, one JSON file per session named by its session hash,
+// so that a session is queued at most once.
+type Outbox struct {
+	dir string
+}
+
+// NewOutbox creates an Outbox stored in dir.
+// If dir is empty, uses ~/.moai/rank/outbox.
+func NewOutbox(dir string) (*Outbox, error) {
+	if dir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("get home directory: %w", err)
+		}
+		dir = filepath.Join(homeDir, defs.MoAIDir, defs.RankSubdir, OutboxSubdir)
+	}
+	return &Outbox{dir: dir}, nil
+}
+
+// Dir returns the directory of the outbox.
+func (o *Outbox) Dir() string {
+	return o.dir
+}
+
+// sessionHash returns the deduplication hash of a session, computing it
+// when the submission has none.
+func sessionHash(s *SessionSubmission) string {
+	if s.SessionHash != "" {
+		return s.SessionHash
+	}
+	return ComputeSessionHash(s.EndedAt, s.InputTokens, s.OutputTokens, s.CacheCreationTokens, s.CacheReadTokens, s.ModelName)
+}
+
+// itemPath returns the file of the session with hash.
+func (o *Outbox) itemPath(hash string) string {
+	return filepath.Join(o.dir, hash+".json")
+}
+
+// Enqueue adds a session to the outbox. It reports false when a session
+// with the same hash is already queued.
+func (o *Outbox) Enqueue(session *SessionSubmission, transcriptPath string) (bool, error) {
+	if session == nil {
+		return false, fmt.Errorf("enqueue session: nil submission")
+	}
+	session.SessionHash = sessionHash(session)
+
+	path := o.itemPath(session.SessionHash)
+	if _, err := os.Stat(path); err == nil {
+		return false, nil
+	}
+
+	item := &OutboxItem{
+		Session:        session,
+		TranscriptPath: transcriptPath,
+		QueuedAt:       time.Now(),
+	}
+	if err := o.save(item); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// save writes an item to the outbox atomically.
+func (o *Outbox) save(item *OutboxItem) error {
+	if err := os.MkdirAll(o.dir, 0o700); err != nil {
+		return fmt.Errorf("create outbox directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal outbox item: %w", err)
+	}
+
+	path := o.itemPath(item.Session.SessionHash)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return fmt.Errorf("write outbox item: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write outbox item: %w", err)
+	}
+	return nil
+}
+
+// List returns the queued sessions, oldest first. Unreadable files are
+// skipped.
+func (o *Outbox) List() ([]*OutboxItem, error) {
+	entries, err := os.ReadDir(o.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+
+	var items []*OutboxItem
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(o.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var item OutboxItem
+		if err := json.Unmarshal(data, &item); err != nil || item.Session == nil {
+			continue
+		}
+		item.Session.SessionHash = strings.TrimSuffix(entry.Name(), ".json")
+		items = append(items, &item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].QueuedAt.Before(items[j].QueuedAt)
+	})
+	return items, nil
+}
+
+// Len returns the number of queued sessions.
+func (o *Outbox) Len() int {
+	items, _ := o.List()
+	return len(items)
+}
+
+// Remove deletes the session with hash from the outbox. Removing a session
+// that is not queued does nothing.
+func (o *Outbox) Remove(hash string) error {
+	if err := os.Remove(o.itemPath(hash)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove outbox item: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes all queued sessions and returns how many were removed.
+func (o *Outbox) Purge() (int, error) {
+	items, err := o.List()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, item := range items {
+		if err := o.Remove(item.Session.SessionHash); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// --- Flushing ---
+
+// FlushOption configures Outbox.Flush.
+type FlushOption func(*flushConfig)
+
+type flushConfig struct {
+	policy    resilience.RetryPolicy
+	breaker   *resilience.CircuitBreaker
+	syncState *SyncState
+}
+
+// WithRetryPolicy sets the retry policy of each batch submission.
+func WithRetryPolicy(policy resilience.RetryPolicy) FlushOption {
+	return func(c *flushConfig) {
+		c.policy = policy
+	}
+}
+
+// WithCircuitBreaker sets the circuit breaker around the rank client.
+// Sharing a breaker across flushes stops them while the service is down.
+func WithCircuitBreaker(breaker *resilience.CircuitBreaker) FlushOption {
+	return func(c *flushConfig) {
+		c.breaker = breaker
+	}
+}
+
+// WithSyncState marks the transcripts of submitted sessions as synced in
+// state. The caller saves the state.
+func WithSyncState(state *SyncState) FlushOption {
+	return func(c *flushConfig) {
+		c.syncState = state
+	}
+}
+
+// Flush submits the queued sessions in batches of up to MaxBatchSize and
+// removes the submitted ones. Each batch is retried with backoff inside a
+// circuit breaker; once the breaker opens, or the server rejects the
+// credentials, the remaining sessions stay queued for the next flush. A
+// batch the server refuses as invalid is resubmitted session by session
+// so that one bad session does not hold back the others.
+//
+// The returned error is the last submission failure, if any.
+func (o *Outbox) Flush(ctx context.Context, client Client, opts ...FlushOption) (*FlushResult, error) {
+	cfg := flushConfig{policy: DefaultOutboxRetryPolicy}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.breaker == nil {
+		cfg.breaker = resilience.NewCircuitBreaker(resilience.CircuitBreakerConfig{
+			Threshold: DefaultOutboxBreakerThreshold,
+		})
+	}
+
+	items, err := o.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FlushResult{}
+	var lastErr error
+	for start := 0; start < len(items); start += MaxBatchSize {
+		batch := items[start:min(start+MaxBatchSize, len(items))]
+		sessions := make([]*SessionSubmission, len(batch))
+		for i, item := range batch {
+			sessions[i] = item.Session
+		}
+
+		err := cfg.call(ctx, func() error {
+			return resilience.Retry(ctx, cfg.policy, func() error {
+				_, err := client.SubmitSessionsBatch(ctx, sessions)
+				return err
+			})
+		})
+		switch {
+		case err == nil:
+			o.delivered(batch, &cfg)
+			result.Submitted += len(batch)
+		case isRejection(err):
+			lastErr = o.submitEach(ctx, client, batch, &cfg, result)
+		default:
+			lastErr = err
+			o.failed(batch, err)
+		}
+
+		if lastErr != nil && stopsFlush(lastErr) {
+			break
+		}
+	}
+
+	result.Pending = o.Len()
+	return result, lastErr
+}
+
+// call runs fn inside the circuit breaker. Rejections pass through
+// without counting as failures, since the service answered.
+func (c *flushConfig) call(ctx context.Context, fn func() error) error {
+	var rejected error
+	err := c.breaker.Call(ctx, func() error {
+		err := fn()
+		if isRejection(err) {
+			rejected = err
+			return nil
+		}
+		return err
+	})
+	if err == nil {
+		return rejected
+	}
+	return err
+}
+
+// submitEach submits the sessions of a rejected batch one at a time,
+// dropping those the server refuses as invalid.
+func (o *Outbox) submitEach(ctx context.Context, client Client, batch []*OutboxItem, cfg *flushConfig, result *FlushResult) error {
+	var lastErr error
+	for _, item := range batch {
+		err := cfg.call(ctx, func() error {
+			return client.SubmitSession(ctx, item.Session)
+		})
+		switch {
+		case err == nil:
+			o.delivered([]*OutboxItem{item}, cfg)
+			result.Submitted++
+		case isRejection(err):
+			_ = o.Remove(item.Session.SessionHash)
+			result.Rejected++
+		default:
+			lastErr = err
+			o.failed([]*OutboxItem{item}, err)
+			if stopsFlush(err) {
+				return lastErr
+			}
+		}
+	}
+	return lastErr
+}
+
+// delivered removes submitted items and marks their transcripts as synced.
+func (o *Outbox) delivered(items []*OutboxItem, cfg *flushConfig) {
+	for _, item := range items {
+		_ = o.Remove(item.Session.SessionHash)
+		if cfg.syncState != nil && item.TranscriptPath != "" {
+			_ = cfg.syncState.MarkSynced(item.TranscriptPath)
+		}
+	}
+}
+
+// failed records a failed attempt on items.
+func (o *Outbox) failed(items []*OutboxItem, err error) {
+	now := time.Now()
+	for _, item := range items {
+		item.Attempts++
+		item.LastAttemptAt = now
+		item.LastError = err.Error()
+		_ = o.save(item)
+	}
+}
+
+// isRejection reports whether the server refused a submission as invalid
+// (malformed, conflicting or unprocessable), so that resubmitting it
+// unchanged cannot succeed. Other client errors, such as a missing
+// endpoint or forbidden request, may be fixed on the server side and keep
+// the sessions queued.
+func isRejection(err error) bool {
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+// stopsFlush reports whether err makes the remaining submissions of a
+// flush pointless.
+func stopsFlush(err error) bool {
+	var authErr *AuthenticationError
+	return errors.Is(err, resilience.ErrCircuitOpen) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.As(err, &authErr)
+}
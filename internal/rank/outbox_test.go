@@ -0,0 +1,325 @@
+package rank
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/resilience"
+)
+
+// fastRetry keeps flush tests quick.
+var fastRetry = resilience.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+func testSession(endedAt string, input int64) *SessionSubmission {
+	return &SessionSubmission{
+		EndedAt:      endedAt,
+		InputTokens:  input,
+		OutputTokens: 10,
+		ModelName:    "claude-sonnet-4",
+	}
+}
+
+// rankServer records the sessions posted to the batch and single endpoints.
+type rankServer struct {
+	mu       sync.Mutex
+	batches  [][]*SessionSubmission
+	singles  []*SessionSubmission
+	handler  func(w http.ResponseWriter, r *http.Request, sessions []*SessionSubmission) bool
+	requests int
+}
+
+func (s *rankServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+
+	var sessions []*SessionSubmission
+	switch r.URL.Path {
+	case "/api/v1/sessions/batch":
+		var payload struct {
+			Sessions []*SessionSubmission `json:"sessions"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		sessions = payload.Sessions
+	case "/api/v1/sessions":
+		var session SessionSubmission
+		_ = json.NewDecoder(r.Body).Decode(&session)
+		sessions = []*SessionSubmission{&session}
+	}
+
+	if s.handler != nil && s.handler(w, r, sessions) {
+		return
+	}
+	if r.URL.Path == "/api/v1/sessions/batch" {
+		s.batches = append(s.batches, sessions)
+	} else {
+		s.singles = append(s.singles, sessions...)
+	}
+	_, _ = w.Write([]byte(`{"success":true,"processed":1,"succeeded":1,"failed":0}`))
+}
+
+func newTestOutbox(t *testing.T) *Outbox {
+	t.Helper()
+	outbox, err := NewOutbox(filepath.Join(t.TempDir(), "outbox"))
+	if err != nil {
+		t.Fatalf("NewOutbox: %v", err)
+	}
+	return outbox
+}
+
+func TestOutbox_EnqueueDeduplicates(t *testing.T) {
+	outbox := newTestOutbox(t)
+
+	added, err := outbox.Enqueue(testSession("2026-01-01T10:00:00Z", 100), "")
+	if err != nil || !added {
+		t.Fatalf("first Enqueue = %v, %v; want true", added, err)
+	}
+	added, err = outbox.Enqueue(testSession("2026-01-01T10:00:00Z", 100), "")
+	if err != nil || added {
+		t.Fatalf("duplicate Enqueue = %v, %v; want false", added, err)
+	}
+	if _, err := outbox.Enqueue(testSession("2026-01-01T11:00:00Z", 200), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := outbox.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 queued sessions, got %d", len(items))
+	}
+	want := ComputeSessionHash("2026-01-01T10:00:00Z", 100, 10, 0, 0, "claude-sonnet-4")
+	if items[0].Session.SessionHash != want {
+		t.Errorf("oldest session hash = %s, want %s", items[0].Session.SessionHash, want)
+	}
+
+	removed, err := outbox.Purge()
+	if err != nil || removed != 2 || outbox.Len() != 0 {
+		t.Errorf("Purge = %d, %v; %d left", removed, err, outbox.Len())
+	}
+}
+
+func TestOutbox_ListSkipsCorruptFiles(t *testing.T) {
+	outbox := newTestOutbox(t)
+	if _, err := outbox.Enqueue(testSession("2026-01-01T10:00:00Z", 1), ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outbox.Dir(), "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := outbox.Len(); n != 1 {
+		t.Errorf("Len = %d, want 1", n)
+	}
+}
+
+func TestOutbox_FlushBatches(t *testing.T) {
+	server := &rankServer{}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	outbox := newTestOutbox(t)
+	transcript := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(transcript, []byte("{}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for i := range MaxBatchSize + 5 {
+		path := ""
+		if i == 0 {
+			path = transcript
+		}
+		if _, err := outbox.Enqueue(testSession("2026-01-01T10:00:00Z", int64(i+1)), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	syncState, err := NewSyncState(filepath.Join(t.TempDir(), "sync-state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient("key", WithBaseURL(srv.URL))
+	result, err := outbox.Flush(context.Background(), client, WithRetryPolicy(fastRetry), WithSyncState(syncState))
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if result.Submitted != MaxBatchSize+5 || result.Pending != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(server.batches) != 2 || len(server.batches[0]) != MaxBatchSize || len(server.batches[1]) != 5 {
+		t.Errorf("batch sizes = %d batches", len(server.batches))
+	}
+	if !syncState.IsSynced(transcript) {
+		t.Error("transcript of a submitted session should be marked synced")
+	}
+}
+
+func TestOutbox_FlushRetriesTransientErrors(t *testing.T) {
+	failures := 2
+	server := &rankServer{handler: func(w http.ResponseWriter, _ *http.Request, _ []*SessionSubmission) bool {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return true
+		}
+		return false
+	}}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	outbox := newTestOutbox(t)
+	if _, err := outbox.Enqueue(testSession("2026-01-01T10:00:00Z", 1), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := outbox.Flush(context.Background(), NewClient("key", WithBaseURL(srv.URL)), WithRetryPolicy(fastRetry))
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if result.Submitted != 1 || server.requests != 3 {
+		t.Errorf("result = %+v after %d requests, want 1 submitted after 3", result, server.requests)
+	}
+}
+
+func TestOutbox_FlushKeepsSessionsWhenOffline(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close() // connections are refused from now on
+
+	outbox := newTestOutbox(t)
+	if _, err := outbox.Enqueue(testSession("2026-01-01T10:00:00Z", 1), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	breaker := resilience.NewCircuitBreaker(resilience.CircuitBreakerConfig{Threshold: 1, Timeout: time.Hour})
+	client := NewClient("key", WithBaseURL(srv.URL))
+	result, err := outbox.Flush(context.Background(), client, WithRetryPolicy(fastRetry), WithCircuitBreaker(breaker))
+	if err == nil {
+		t.Fatal("expected a submission error")
+	}
+	if result.Pending != 1 {
+		t.Errorf("Pending = %d, want 1", result.Pending)
+	}
+	items, _ := outbox.List()
+	if items[0].Attempts != 1 || items[0].LastError == "" {
+		t.Errorf("failed attempt not recorded: %+v", items[0])
+	}
+
+	// The open breaker fails the next flush fast.
+	_, err = outbox.Flush(context.Background(), client, WithRetryPolicy(fastRetry), WithCircuitBreaker(breaker))
+	if !errors.Is(err, resilience.ErrCircuitOpen) {
+		t.Errorf("second flush error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestOutbox_FlushDropsRejectedSessions(t *testing.T) {
+	bad := ComputeSessionHash("2026-01-01T10:00:00Z", 2, 10, 0, 0, "claude-sonnet-4")
+	server := &rankServer{handler: func(w http.ResponseWriter, r *http.Request, sessions []*SessionSubmission) bool {
+		for _, s := range sessions {
+			if s.SessionHash == bad {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"success":false,"error":{"code":"VALIDATION","message":"invalid session"}}`))
+				return true
+			}
+		}
+		return false
+	}}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	outbox := newTestOutbox(t)
+	for i := range 3 {
+		if _, err := outbox.Enqueue(testSession("2026-01-01T10:00:00Z", int64(i+1)), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	result, err := outbox.Flush(context.Background(), NewClient("key", WithBaseURL(srv.URL)), WithRetryPolicy(fastRetry))
+	if err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if result.Submitted != 2 || result.Rejected != 1 || result.Pending != 0 {
+		t.Errorf("result = %+v, want 2 submitted and 1 rejected", result)
+	}
+	if len(server.singles) != 2 {
+		t.Errorf("expected the rejected batch to be resubmitted one by one, got %d singles", len(server.singles))
+	}
+}
+
+func TestOutbox_FlushKeepsSessionsOnOtherClientErrors(t *testing.T) {
+	server := &rankServer{handler: func(w http.ResponseWriter, _ *http.Request, _ []*SessionSubmission) bool {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"success":false,"error":{"code":"NOT_FOUND","message":"no such endpoint"}}`))
+		return true
+	}}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	outbox := newTestOutbox(t)
+	for i := range 2 {
+		if _, err := outbox.Enqueue(testSession("2026-01-01T10:00:00Z", int64(i+1)), ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	breaker := resilience.NewCircuitBreaker(resilience.CircuitBreakerConfig{Threshold: 5, Timeout: time.Hour})
+	result, err := outbox.Flush(context.Background(), NewClient("key", WithBaseURL(srv.URL)), WithRetryPolicy(fastRetry), WithCircuitBreaker(breaker))
+	if err == nil {
+		t.Fatal("expected a submission error")
+	}
+	if result.Rejected != 0 || result.Pending != 2 {
+		t.Errorf("result = %+v, want both sessions kept queued", result)
+	}
+	if failures := breaker.Metrics().FailureCount; failures != 1 {
+		t.Errorf("breaker failures = %d, want 1", failures)
+	}
+}
+
+func TestOutbox_FlushStopsOnAuthenticationError(t *testing.T) {
+	server := &rankServer{handler: func(w http.ResponseWriter, _ *http.Request, _ []*SessionSubmission) bool {
+		w.WriteHeader(http.StatusUnauthorized)
+		return true
+	}}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	outbox := newTestOutbox(t)
+	if _, err := outbox.Enqueue(testSession("2026-01-01T10:00:00Z", 1), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := outbox.Flush(context.Background(), NewClient("key", WithBaseURL(srv.URL)), WithRetryPolicy(fastRetry))
+	var authErr *AuthenticationError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Flush error = %v, want AuthenticationError", err)
+	}
+	if result.Pending != 1 || server.requests != 1 {
+		t.Errorf("result = %+v after %d requests; sessions must stay queued without retries", result, server.requests)
+	}
+}
+
+func TestApiError_IsClientError(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusBadRequest, true},
+		{http.StatusUnprocessableEntity, true},
+		{http.StatusRequestTimeout, false},
+		{http.StatusTooManyRequests, false},
+		{http.StatusInternalServerError, false},
+	}
+	for _, tt := range tests {
+		if got := (&ApiError{StatusCode: tt.status}).IsClientError(); got != tt.want {
+			t.Errorf("IsClientError(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
@@ -175,10 +175,8 @@ func (s *PatternStore) ShouldExclude(path string) bool {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	// Simple prefix matching for now
-	// Can be extended to support glob patterns
 	for _, pattern := range s.patterns.ExcludePatterns {
-		if pattern == path {
+		if matchPattern(pattern, path) {
 			return true
 		}
 	}
@@ -192,7 +190,7 @@ func (s *PatternStore) ShouldInclude(path string) bool {
 	defer s.mu.RUnlock()
 
 	for _, pattern := range s.patterns.IncludePatterns {
-		if pattern == path {
+		if matchPattern(pattern, path) {
 			return true
 		}
 	}
@@ -200,6 +198,29 @@ func (s *PatternStore) ShouldInclude(path string) bool {
 	return false
 }
 
+// Allows reports whether metrics of the project at path may be collected:
+// it must not match an exclusion pattern and, when inclusion patterns are
+// configured, must match one of them.
+func (s *PatternStore) Allows(path string) bool {
+	if s.ShouldExclude(path) {
+		return false
+	}
+	s.mu.RLock()
+	hasIncludes := len(s.patterns.IncludePatterns) > 0
+	s.mu.RUnlock()
+	return !hasIncludes || s.ShouldInclude(path)
+}
+
+// matchPattern reports whether path equals pattern or matches it as a
+// glob pattern.
+func matchPattern(pattern, path string) bool {
+	if pattern == path {
+		return true
+	}
+	matched, err := filepath.Match(pattern, path)
+	return err == nil && matched
+}
+
 // GetConfig returns a copy of the current pattern configuration.
 func (s *PatternStore) GetConfig() PatternConfig {
 	s.mu.RLock()
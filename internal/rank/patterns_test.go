@@ -0,0 +1,42 @@
+package rank
+
+import "testing"
+
+func TestPatternStore_Allows(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude []string
+		include []string
+		path    string
+		want    bool
+	}{
+		{"no patterns", nil, nil, "/work/app", true},
+		{"excluded exactly", []string{"/work/app"}, nil, "/work/app", false},
+		{"excluded by glob", []string{"/work/secret-*"}, nil, "/work/secret-app", false},
+		{"not excluded", []string{"/work/secret-*"}, nil, "/work/app", true},
+		{"included by glob", nil, []string{"/work/*"}, "/work/app", true},
+		{"not included", nil, []string{"/work/*"}, "/home/me/app", false},
+		{"exclusion wins", []string{"/work/app"}, []string{"/work/*"}, "/work/app", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := NewPatternStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range tt.exclude {
+				if err := store.AddExclude(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, p := range tt.include {
+				if err := store.AddInclude(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := store.Allows(tt.path); got != tt.want {
+				t.Errorf("Allows(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ type TranscriptUsage struct {
 	EndedAt             string `json:"ended_at,omitempty"`
 	DurationSeconds     int64  `json:"duration_seconds,omitempty"`
 	TurnCount           int    `json:"turn_count,omitempty"`
+	ProjectPath         string `json:"project_path,omitempty"`
 }
 
 // transcriptMessage represents a single line in the JSONL transcript file.
@@ -31,10 +32,13 @@ type transcriptMessage struct {
 	Type      string        `json:"type"`
 	Message   transcriptMsg `json:"message"`
 	Model     string        `json:"model"`
+	Cwd       string        `json:"cwd"`
+	RequestID string        `json:"requestId"`
 }
 
 // transcriptMsg represents the message content with usage data.
 type transcriptMsg struct {
+	ID    string           `json:"id"`
 	Usage *transcriptUsage `json:"usage"`
 	Model string           `json:"model"`
 }
@@ -49,6 +53,8 @@ type transcriptUsage struct {
 
 // ParseTranscript parses a Claude Code transcript JSONL file and extracts token usage.
 // The transcript file contains one JSON object per line, with token usage in message.usage fields.
+// A response split over several lines repeats its message.id, requestId and usage, so the
+// usage of each message.id and requestId pair is counted once, from its last line.
 func ParseTranscript(transcriptPath string) (*TranscriptUsage, error) {
 	file, err := os.Open(transcriptPath)
 	if err != nil {
@@ -62,6 +68,7 @@ func ParseTranscript(transcriptPath string) (*TranscriptUsage, error) {
 	usage := &TranscriptUsage{}
 	var firstTimestamp, lastTimestamp string
 	turnCount := 0
+	responses := make(map[string]*transcriptUsage)
 
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -89,6 +96,11 @@ func ParseTranscript(transcriptPath string) (*TranscriptUsage, error) {
 			turnCount++
 		}
 
+		// The working directory of the first message is the project
+		if msg.Cwd != "" && usage.ProjectPath == "" {
+			usage.ProjectPath = msg.Cwd
+		}
+
 		// Extract model name
 		model := msg.Model
 		if model != "" && usage.ModelName == "" {
@@ -98,18 +110,22 @@ func ParseTranscript(transcriptPath string) (*TranscriptUsage, error) {
 			usage.ModelName = msg.Message.Model
 		}
 
-		// Extract token usage
+		// Extract token usage, once per response
 		if msg.Message.Usage != nil {
-			usage.InputTokens += msg.Message.Usage.InputTokens
-			usage.OutputTokens += msg.Message.Usage.OutputTokens
-			usage.CacheCreationTokens += msg.Message.Usage.CacheCreationInputTokens
-			usage.CacheReadTokens += msg.Message.Usage.CacheReadInputTokens
+			if msg.Message.ID == "" && msg.RequestID == "" {
+				usage.add(msg.Message.Usage)
+			} else {
+				responses[msg.Message.ID+"\x00"+msg.RequestID] = msg.Message.Usage
+			}
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
 		return nil, fmt.Errorf("scan transcript: %w", err)
 	}
+	for _, u := range responses {
+		usage.add(u)
+	}
 
 	// Set timing metadata
 	usage.StartedAt = firstTimestamp
@@ -130,6 +146,14 @@ func ParseTranscript(transcriptPath string) (*TranscriptUsage, error) {
 	return usage, nil
 }
 
+// add adds the token counts of u to t.
+func (t *TranscriptUsage) add(u *transcriptUsage) {
+	t.InputTokens += u.InputTokens
+	t.OutputTokens += u.OutputTokens
+	t.CacheCreationTokens += u.CacheCreationInputTokens
+	t.CacheReadTokens += u.CacheReadInputTokens
+}
+
 // claudeDesktopConfigDir returns the Claude Desktop (Electron app) configuration directory
 // based on the platform.
 func claudeDesktopConfigDir() (string, error) {
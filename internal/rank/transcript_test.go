@@ -357,3 +357,22 @@ func TestParseTranscript_FileNotFound(t *testing.T) {
 		t.Fatal("ParseTranscript() should return error for missing file")
 	}
 }
+
+func TestParseTranscript_ProjectPath(t *testing.T) {
+	transcriptFile := filepath.Join(t.TempDir(), "session.jsonl")
+	content := `{"timestamp":"2026-01-01T10:00:00Z","type":"summary"}
+{"timestamp":"2026-01-01T10:00:01Z","type":"user","cwd":"/work/app","message":{}}
+{"timestamp":"2026-01-01T10:00:05Z","type":"user","cwd":"/work/app/sub","message":{}}
+`
+	if err := os.WriteFile(transcriptFile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := ParseTranscript(transcriptFile)
+	if err != nil {
+		t.Fatalf("ParseTranscript() returned error: %v", err)
+	}
+	if usage.ProjectPath != "/work/app" {
+		t.Errorf("ProjectPath = %q, want %q", usage.ProjectPath, "/work/app")
+	}
+}
@@ -0,0 +1,177 @@
+package rank
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// UsageGrouping is the dimension a usage report aggregates sessions by.
+type UsageGrouping string
+
+// Supported usage report groupings.
+const (
+	UsageByProject UsageGrouping = "project"
+	UsageByDay     UsageGrouping = "day"
+	UsageByModel   UsageGrouping = "model"
+	UsageBySession UsageGrouping = "session"
+)
+
+// UsageGroupings lists the supported groupings in display order.
+var UsageGroupings = []UsageGrouping{UsageByProject, UsageByDay, UsageByModel, UsageBySession}
+
+// unknownUsageKey labels sessions whose project or model is not recorded.
+const unknownUsageKey = "unknown"
+
+// SessionUsage is the token usage and estimated cost of one transcript.
+type SessionUsage struct {
+	SessionID           string
+	ProjectPath         string
+	ModelName           string
+	StartedAt           time.Time
+	EndedAt             time.Time
+	DurationSeconds     int64
+	InputTokens         int64
+	OutputTokens        int64
+	CacheCreationTokens int64
+	CacheReadTokens     int64
+	CostUSD             float64
+
+	// Priced is false when the model has no pricing, so CostUSD is zero.
+	Priced bool
+}
+
+// NewSessionUsage builds the usage of the transcript at transcriptPath from
+// its parsed usage. The project falls back to the transcript's project
+// directory under ~/.claude/projects when the transcript records no
+// working directory.
+func NewSessionUsage(transcriptPath string, usage *TranscriptUsage) *SessionUsage {
+	sessionID := strings.TrimSuffix(filepath.Base(transcriptPath), filepath.Ext(transcriptPath))
+
+	project := usage.ProjectPath
+	if project == "" {
+		dir := filepath.Dir(transcriptPath)
+		if filepath.Base(filepath.Dir(dir)) == "projects" {
+			project = filepath.Base(dir)
+		}
+	}
+
+	s := &SessionUsage{
+		SessionID:           sessionID,
+		ProjectPath:         project,
+		ModelName:           usage.ModelName,
+		DurationSeconds:     usage.DurationSeconds,
+		InputTokens:         usage.InputTokens,
+		OutputTokens:        usage.OutputTokens,
+		CacheCreationTokens: usage.CacheCreationTokens,
+		CacheReadTokens:     usage.CacheReadTokens,
+		Priced:              HasPricing(usage.ModelName),
+	}
+	s.StartedAt, _ = time.Parse(time.RFC3339Nano, usage.StartedAt)
+	s.EndedAt, _ = time.Parse(time.RFC3339Nano, usage.EndedAt)
+	s.CostUSD = CalculateCost(s.InputTokens, s.OutputTokens, s.CacheCreationTokens, s.CacheReadTokens, GetModelPricing(s.ModelName))
+	return s
+}
+
+// key returns the group of the session under grouping.
+func (s *SessionUsage) key(by UsageGrouping) string {
+	var key string
+	switch by {
+	case UsageByDay:
+		if !s.EndedAt.IsZero() {
+			key = s.EndedAt.Local().Format(time.DateOnly)
+		}
+	case UsageByModel:
+		key = s.ModelName
+	case UsageBySession:
+		key = s.SessionID
+	default:
+		key = s.ProjectPath
+	}
+	if key == "" {
+		return unknownUsageKey
+	}
+	return key
+}
+
+// UsageGroup aggregates the usage of the sessions sharing a key.
+type UsageGroup struct {
+	Key                 string  `json:"key"`
+	Sessions            int     `json:"sessions"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	CostUSD             float64 `json:"cost_usd"`
+	DurationSeconds     int64   `json:"duration_seconds"`
+	UnpricedSessions    int     `json:"unpriced_sessions,omitempty"`
+
+	// CacheHitRatio is the share of prompt tokens read from the cache.
+	CacheHitRatio float64 `json:"cache_hit_ratio"`
+
+	// AvgDurationSeconds is the mean session duration.
+	AvgDurationSeconds int64 `json:"avg_duration_seconds"`
+}
+
+// add accumulates s into the group.
+func (g *UsageGroup) add(s *SessionUsage) {
+	g.Sessions++
+	g.InputTokens += s.InputTokens
+	g.OutputTokens += s.OutputTokens
+	g.CacheCreationTokens += s.CacheCreationTokens
+	g.CacheReadTokens += s.CacheReadTokens
+	g.CostUSD += s.CostUSD
+	g.DurationSeconds += s.DurationSeconds
+	if !s.Priced {
+		g.UnpricedSessions++
+	}
+}
+
+// finish computes the derived ratios once all sessions are added.
+func (g *UsageGroup) finish() {
+	if prompt := g.InputTokens + g.CacheCreationTokens + g.CacheReadTokens; prompt > 0 {
+		g.CacheHitRatio = float64(g.CacheReadTokens) / float64(prompt)
+	}
+	if g.Sessions > 0 {
+		g.AvgDurationSeconds = g.DurationSeconds / int64(g.Sessions)
+	}
+}
+
+// UsageReport is the usage of a set of sessions grouped by one dimension.
+type UsageReport struct {
+	By     UsageGrouping `json:"by"`
+	Groups []*UsageGroup `json:"groups"`
+	Total  *UsageGroup   `json:"total"`
+}
+
+// AggregateUsage groups sessions by grouping. Days are listed in
+// chronological order, other groupings by descending cost.
+func AggregateUsage(sessions []*SessionUsage, by UsageGrouping) *UsageReport {
+	report := &UsageReport{By: by, Groups: []*UsageGroup{}, Total: &UsageGroup{Key: "total"}}
+	index := make(map[string]*UsageGroup)
+	for _, s := range sessions {
+		key := s.key(by)
+		group, ok := index[key]
+		if !ok {
+			group = &UsageGroup{Key: key}
+			index[key] = group
+			report.Groups = append(report.Groups, group)
+		}
+		group.add(s)
+		report.Total.add(s)
+	}
+	for _, group := range report.Groups {
+		group.finish()
+	}
+	report.Total.finish()
+
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if by == UsageByDay || a.CostUSD == b.CostUSD {
+			return a.Key < b.Key
+		}
+		return a.CostUSD > b.CostUSD
+	})
+	return report
+}
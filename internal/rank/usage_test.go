@@ -0,0 +1,95 @@
+package rank
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewSessionUsage(t *testing.T) {
+	usage := &TranscriptUsage{
+		InputTokens:     1_000_000,
+		OutputTokens:    100_000,
+		CacheReadTokens: 1_000_000,
+		ModelName:       "claude-sonnet-4-20250514",
+		EndedAt:         "2026-01-02T10:00:00Z",
+		DurationSeconds: 600,
+	}
+	transcript := filepath.Join("home", ".claude", "projects", "-work-app", "abc-123.jsonl")
+
+	s := NewSessionUsage(transcript, usage)
+	if s.SessionID != "abc-123" {
+		t.Errorf("SessionID = %q, want abc-123", s.SessionID)
+	}
+	if s.ProjectPath != "-work-app" {
+		t.Errorf("ProjectPath = %q, want the transcript project directory", s.ProjectPath)
+	}
+	if !s.Priced || s.CostUSD <= 0 {
+		t.Errorf("expected a priced session with a cost, got %+v", s)
+	}
+
+	usage.ProjectPath = "/work/app"
+	if s := NewSessionUsage(transcript, usage); s.ProjectPath != "/work/app" {
+		t.Errorf("ProjectPath = %q, want the recorded working directory", s.ProjectPath)
+	}
+}
+
+func TestNewSessionUsage_RepeatedMessageLines(t *testing.T) {
+	// Claude Code writes one line per content block of a response, each
+	// repeating the message id, request id and usage of the response.
+	transcript := filepath.Join(t.TempDir(), "abc-123.jsonl")
+	lines := `{"type":"user","timestamp":"2026-01-02T10:00:00Z","cwd":"/work/app","message":{"role":"user","content":"hi"}}
+{"type":"assistant","timestamp":"2026-01-02T10:00:01Z","requestId":"req_1","message":{"id":"msg_1","model":"claude-sonnet-4-20250514","usage":{"input_tokens":100,"output_tokens":5,"cache_read_input_tokens":1000}}}
+{"type":"assistant","timestamp":"2026-01-02T10:00:02Z","requestId":"req_1","message":{"id":"msg_1","model":"claude-sonnet-4-20250514","usage":{"input_tokens":100,"output_tokens":40,"cache_read_input_tokens":1000}}}
+{"type":"assistant","timestamp":"2026-01-02T10:00:03Z","requestId":"req_1","message":{"id":"msg_1","model":"claude-sonnet-4-20250514","usage":{"input_tokens":100,"output_tokens":40,"cache_read_input_tokens":1000}}}
+{"type":"assistant","timestamp":"2026-01-02T10:00:04Z","requestId":"req_2","message":{"id":"msg_2","model":"claude-sonnet-4-20250514","usage":{"input_tokens":10,"output_tokens":20,"cache_creation_input_tokens":300}}}
+{"type":"assistant","timestamp":"2026-01-02T10:00:05Z","requestId":"req_2","message":{"id":"msg_2","model":"claude-sonnet-4-20250514","usage":{"input_tokens":10,"output_tokens":20,"cache_creation_input_tokens":300}}}
+`
+	if err := os.WriteFile(transcript, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := ParseTranscript(transcript)
+	if err != nil {
+		t.Fatalf("ParseTranscript: %v", err)
+	}
+	s := NewSessionUsage(transcript, usage)
+	if s.InputTokens != 110 || s.OutputTokens != 60 || s.CacheCreationTokens != 300 || s.CacheReadTokens != 1000 {
+		t.Errorf("tokens = in %d, out %d, cache write %d, cache read %d; want 110, 60, 300, 1000",
+			s.InputTokens, s.OutputTokens, s.CacheCreationTokens, s.CacheReadTokens)
+	}
+}
+
+func TestAggregateUsage(t *testing.T) {
+	sessions := []*SessionUsage{
+		NewSessionUsage("a.jsonl", &TranscriptUsage{ProjectPath: "/work/a", ModelName: "claude-sonnet-4-20250514", InputTokens: 100, CacheReadTokens: 300, EndedAt: "2026-01-02T12:00:00Z", DurationSeconds: 60}),
+		NewSessionUsage("b.jsonl", &TranscriptUsage{ProjectPath: "/work/a", ModelName: "claude-opus-4-20250514", InputTokens: 1_000_000, EndedAt: "2026-01-01T12:00:00Z", DurationSeconds: 120}),
+		NewSessionUsage("c.jsonl", &TranscriptUsage{ProjectPath: "/work/b", ModelName: "local-model", InputTokens: 50, EndedAt: "2026-01-01T12:00:00Z"}),
+	}
+
+	report := AggregateUsage(sessions, UsageByProject)
+	if len(report.Groups) != 2 || report.Groups[0].Key != "/work/a" {
+		t.Fatalf("groups = %+v, want /work/a first", report.Groups)
+	}
+	a := report.Groups[0]
+	if a.Sessions != 2 || a.InputTokens != 1_000_100 || a.AvgDurationSeconds != 90 {
+		t.Errorf("/work/a = %+v", a)
+	}
+	if got := report.Total.CacheHitRatio; math.Abs(got-300.0/1_000_450) > 1e-9 {
+		t.Errorf("total cache hit ratio = %v", got)
+	}
+	if report.Total.Sessions != 3 || report.Total.UnpricedSessions != 1 {
+		t.Errorf("total = %+v", report.Total)
+	}
+
+	days := AggregateUsage(sessions, UsageByDay)
+	if len(days.Groups) != 2 || days.Groups[0].Key > days.Groups[1].Key {
+		t.Errorf("days should be chronological, got %+v", days.Groups)
+	}
+
+	empty := AggregateUsage(nil, UsageByModel)
+	if empty.Groups == nil || empty.Total.Sessions != 0 {
+		t.Errorf("empty report = %+v", empty)
+	}
+}
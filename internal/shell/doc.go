@@ -12,4 +12,8 @@
 // This ensures that environment variables like CLAUDE_DISABLE_PATH_WARNING
 // are available in IDE contexts (VS Code, Cursor) where non-interactive
 // shells are used.
+//
+// It also parses Bash command lines: ParseCommands extracts the simple
+// commands a line would run for the PreToolUse security checks, and Split
+// turns a simple command into arguments for running it without a shell.
 package shell
@@ -14,4 +14,16 @@ var (
 
 	// ErrAlreadyConfigured is returned when the configuration already exists.
 	ErrAlreadyConfigured = errors.New("shell: already configured")
+
+	// ErrUnterminated is returned by the command parser for an unterminated
+	// quote or substitution.
+	ErrUnterminated = errors.New("shell: unterminated quote or substitution")
+
+	// ErrTooDeep is returned by the command parser when subshells,
+	// substitutions or nested scripts exceed the nesting limit.
+	ErrTooDeep = errors.New("shell: nesting too deep")
+
+	// ErrNotSimple is returned by Split for a command that needs a shell to
+	// run.
+	ErrNotSimple = errors.New("shell: not a simple command")
 )
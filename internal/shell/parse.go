@@ -0,0 +1,1085 @@
+package shell
+
+import (
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+// maxShellDepth bounds recursion into subshells, command substitutions,
+// "bash -c" scripts and eval so a crafted command cannot exhaust the stack.
+const maxShellDepth = 16
+
+// Command is a single simple command extracted from a Bash command line
+// after alias-like prefixes (sudo, env, command, ...) have been unwrapped.
+type Command struct {
+	// Argv is the effective argument vector.
+	Argv []string
+
+	// Redirects lists redirections such as "> /dev/sda".
+	Redirects []string
+
+	// Stdin is the heredoc or here-string body fed to the command.
+	Stdin string
+
+	// Dir is the directory the command runs in, relative to the starting
+	// working directory, as changed by preceding "cd" commands.
+	Dir string
+
+	// Piped is set when the output of an inert command is piped into a
+	// command that may execute it, such as "echo ... | sh". Its arguments
+	// and stdin are then matched like a script.
+	Piped bool
+}
+
+// String returns the command line as shown in deny reasons.
+func (c Command) String() string {
+	parts := make([]string, 0, len(c.Argv)+len(c.Redirects))
+	parts = append(parts, c.Argv...)
+	parts = append(parts, c.Redirects...)
+	return strings.Join(parts, " ")
+}
+
+// MatchText returns the text security rules are matched against.
+// Arguments of commands that never execute them (echo, grep, ...) and
+// commit messages are left out so quoted prose does not trigger rules.
+func (c Command) MatchText() string {
+	if len(c.Argv) == 0 {
+		return strings.Join(c.Redirects, " ")
+	}
+
+	argv := c.Argv
+	name := path.Base(argv[0])
+	inert := inertCommands[name] && !c.Piped
+	switch {
+	case inert:
+		argv = argv[:1]
+	case name == "git":
+		argv = stripGitMessages(argv)
+	}
+
+	parts := make([]string, 0, len(argv)+len(c.Redirects)+1)
+	parts = append(parts, argv...)
+	parts = append(parts, c.Redirects...)
+	if c.Stdin != "" && !inert {
+		parts = append(parts, c.Stdin)
+	}
+	return strings.Join(parts, " ")
+}
+
+// inertCommands never execute or interpret their arguments as commands.
+var inertCommands = map[string]bool{
+	"echo": true, "printf": true, "cat": true, "less": true, "more": true,
+	"head": true, "tail": true, "wc": true, "grep": true, "egrep": true,
+	"fgrep": true, "rg": true, "ag": true, "ack": true, "jq": true, "yq": true,
+	"true": true, "false": true, ":": true, "test": true, "[": true, "[[": true,
+	"man": true, "which": true, "type": true, "help": true,
+}
+
+// pipedScript returns the text an inert command writes to a pipe: its
+// arguments and its heredoc or here-string body.
+func (c Command) pipedScript() string {
+	parts := make([]string, 0, len(c.Argv))
+	for _, a := range c.Argv[1:] {
+		if !strings.HasPrefix(a, "-") {
+			parts = append(parts, a)
+		}
+	}
+	if c.Stdin != "" {
+		parts = append(parts, c.Stdin)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// stripGitMessages removes commit/tag message values from a git argv.
+func stripGitMessages(argv []string) []string {
+	result := make([]string, 0, len(argv))
+	for i := 0; i < len(argv); i++ {
+		a := argv[i]
+		switch {
+		case a == "-m" || a == "--message" || a == "-F" || a == "--file":
+			i++ // skip the value
+		case strings.HasPrefix(a, "--message=") || strings.HasPrefix(a, "--file="):
+		case strings.HasPrefix(a, "-m") && len(a) > 2:
+		default:
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// ParseCommands splits a Bash command line into the simple commands it
+// would run. It follows pipelines, "&&"/"||"/";" chains, subshells, command
+// and process substitutions, "bash -c" scripts, eval, xargs, heredocs fed
+// to a shell and inert commands piped into an interpreter, and expands
+// variables assigned earlier in the same line.
+// It also returns the command line with quoted strings and heredoc bodies
+// blanked out, for rules that match shell structure rather than one command.
+func ParseCommands(command string) ([]Command, string, error) {
+	p := &shellParser{vars: make(map[string]string), pipeFrom: -1}
+	sanitized, err := p.parse(command, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	return p.commands, sanitized, nil
+}
+
+// Split splits a simple command line into its words after quote removal,
+// so that it can run without a shell. It returns ErrNotSimple when the
+// command needs a shell: control operators, redirections, "$" expansions,
+// command substitutions, globs or a leading tilde.
+func Split(command string) ([]string, error) {
+	tokens, _, err := lexShell(strings.TrimSpace(command))
+	if err != nil {
+		return nil, err
+	}
+	argv := make([]string, 0, len(tokens))
+	for _, tok := range tokens {
+		if tok.kind != tokWord || tok.expands || len(tok.subs) > 0 {
+			return nil, ErrNotSimple
+		}
+		argv = append(argv, literalWord(tok))
+	}
+	return argv, nil
+}
+
+// tokenKind classifies lexer tokens.
+type tokenKind int
+
+const (
+	tokWord     tokenKind = iota // a word, possibly with quoted parts
+	tokOp                        // control operator: ; & && || | |& ( ) newline
+	tokRedirect                  // redirection operator; the next word is its target
+)
+
+// wordPart is a segment of a word: literal text, a variable reference or
+// a command substitution.
+type wordPart struct {
+	text   string
+	varRef string // variable name for $NAME / ${NAME}
+	subst  string // script of a $(...) or `...` command substitution
+	quoted bool   // inside quotes: not subject to field splitting
+}
+
+// shellToken is a lexer token.
+type shellToken struct {
+	kind  tokenKind
+	op    string
+	parts []wordPart
+	subs  []string // command/process substitution scripts inside the word
+	body  *string  // heredoc body for "<<" redirects
+
+	// expands is set for words the shell would expand: "$" expansions,
+	// substitutions, unquoted globs and a leading unquoted tilde.
+	expands bool
+}
+
+// shellLexer tokenizes a POSIX shell script.
+type shellLexer struct {
+	src       string
+	pos       int
+	tokens    []*shellToken
+	sanitized strings.Builder
+	heredocs  []pendingHeredoc
+	awaitTerm *shellToken // "<<" redirect waiting for its delimiter word
+}
+
+// pendingHeredoc is a heredoc whose body starts after the next newline.
+type pendingHeredoc struct {
+	delim     string
+	stripTabs bool
+	body      *string
+}
+
+// lexShell tokenizes src and returns the tokens and the sanitized source.
+func lexShell(src string) ([]*shellToken, string, error) {
+	l := &shellLexer{src: src}
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			l.sanitized.WriteByte(c)
+			l.pos++
+		case c == '\\' && l.peek(1) == '\n':
+			l.pos += 2
+		case c == '\n':
+			l.emitOp("\n")
+			l.pos++
+			l.readHeredocs()
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case (c == '<' || c == '>') && l.peek(1) == '(':
+			if err := l.readWord(); err != nil {
+				return nil, "", err
+			}
+		case c == '<' || c == '>' || (c == '&' && l.peek(1) == '>'):
+			l.readRedirect("")
+		case c >= '0' && c <= '9' && l.fdRedirectAhead():
+			start := l.pos
+			for l.src[l.pos] >= '0' && l.src[l.pos] <= '9' {
+				l.pos++
+			}
+			l.readRedirect(l.src[start:l.pos])
+		case strings.IndexByte(";&|()", c) >= 0:
+			l.readOp()
+		default:
+			if err := l.readWord(); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	return l.tokens, l.sanitized.String(), nil
+}
+
+// peek returns the byte at pos+n, or 0 past the end.
+func (l *shellLexer) peek(n int) byte {
+	if l.pos+n < len(l.src) {
+		return l.src[l.pos+n]
+	}
+	return 0
+}
+
+// fdRedirectAhead reports whether digits at pos are a redirect fd (2>, 1<&).
+func (l *shellLexer) fdRedirectAhead() bool {
+	i := l.pos
+	for i < len(l.src) && l.src[i] >= '0' && l.src[i] <= '9' {
+		i++
+	}
+	return i < len(l.src) && (l.src[i] == '<' || l.src[i] == '>') && (i+1 >= len(l.src) || l.src[i+1] != '(')
+}
+
+// emitOp appends a control operator token.
+func (l *shellLexer) emitOp(op string) {
+	l.tokens = append(l.tokens, &shellToken{kind: tokOp, op: op})
+	l.sanitized.WriteString(op)
+}
+
+// readOp reads a control operator at pos.
+func (l *shellLexer) readOp() {
+	for _, op := range []string{"&&", "||", "|&", ";;", ";", "&", "|", "(", ")"} {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			l.emitOp(op)
+			return
+		}
+	}
+}
+
+// readRedirect reads a redirection operator at pos, prefixed by fd digits.
+func (l *shellLexer) readRedirect(fd string) {
+	for _, op := range []string{"&>>", "&>", "<<<", "<<-", "<<", "<>", "<&", ">>", ">&", ">|", "<", ">"} {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			l.pos += len(op)
+			tok := &shellToken{kind: tokRedirect, op: fd + op}
+			l.tokens = append(l.tokens, tok)
+			l.sanitized.WriteString(fd + op)
+			if op == "<<" || op == "<<-" {
+				tok.body = new(string)
+				l.awaitTerm = tok
+			}
+			return
+		}
+	}
+}
+
+// readHeredocs consumes the bodies of heredocs pending at a newline.
+func (l *shellLexer) readHeredocs() {
+	for _, hd := range l.heredocs {
+		var body strings.Builder
+		for l.pos < len(l.src) {
+			end := strings.IndexByte(l.src[l.pos:], '\n')
+			var line string
+			if end < 0 {
+				line = l.src[l.pos:]
+				l.pos = len(l.src)
+			} else {
+				line = l.src[l.pos : l.pos+end]
+				l.pos += end + 1
+			}
+			check := line
+			if hd.stripTabs {
+				check = strings.TrimLeft(line, "\t")
+			}
+			if check == hd.delim {
+				break
+			}
+			body.WriteString(line)
+			body.WriteByte('\n')
+		}
+		*hd.body = body.String()
+	}
+	l.heredocs = nil
+}
+
+// isWordBreak reports whether the byte at i ends an unquoted word.
+func (l *shellLexer) isWordBreak(i int) bool {
+	c := l.src[i]
+	switch c {
+	case ' ', '\t', '\r', '\n', ';', '&', '|', '(', ')':
+		return true
+	case '<', '>':
+		return i+1 >= len(l.src) || l.src[i+1] != '('
+	}
+	return false
+}
+
+// readWord reads a word at pos, handling quotes, escapes and expansions.
+func (l *shellLexer) readWord() error {
+	tok := &shellToken{kind: tokWord}
+	lit := func(s string, quoted bool) {
+		tok.parts = append(tok.parts, wordPart{text: s, quoted: quoted})
+	}
+
+	for l.pos < len(l.src) && !l.isWordBreak(l.pos) {
+		c := l.src[l.pos]
+		switch c {
+		case '\\':
+			if l.pos+1 < len(l.src) {
+				if l.src[l.pos+1] != '\n' {
+					lit(l.src[l.pos+1:l.pos+2], true)
+					l.sanitized.WriteString(l.src[l.pos : l.pos+2])
+				}
+				l.pos += 2
+			} else {
+				l.pos++
+			}
+		case '\'':
+			end := strings.IndexByte(l.src[l.pos+1:], '\'')
+			if end < 0 {
+				return ErrUnterminated
+			}
+			lit(l.src[l.pos+1:l.pos+1+end], true)
+			l.sanitized.WriteString("''")
+			l.pos += end + 2
+		case '"':
+			if err := l.readDoubleQuoted(tok); err != nil {
+				return err
+			}
+			l.sanitized.WriteString(`""`)
+		case '`':
+			start := l.pos
+			inner, err := l.readBacktick()
+			if err != nil {
+				return err
+			}
+			tok.subs = append(tok.subs, inner)
+			tok.parts = append(tok.parts, wordPart{text: l.src[start:l.pos], subst: inner})
+			l.sanitized.WriteString(l.src[start:l.pos])
+		case '$':
+			start := l.pos
+			if err := l.readDollar(tok, false); err != nil {
+				return err
+			}
+			l.sanitized.WriteString(l.src[start:l.pos])
+		case '<', '>':
+			// Process substitution: <(...) or >(...)
+			tok.expands = true
+			start := l.pos
+			end, err := scanBalanced(l.src, l.pos+1)
+			if err != nil {
+				return err
+			}
+			tok.subs = append(tok.subs, l.src[l.pos+2:end])
+			l.pos = end + 1
+			lit(l.src[start:l.pos], true)
+			l.sanitized.WriteString(l.src[start:l.pos])
+		default:
+			if strings.IndexByte("*?[", c) >= 0 || (c == '~' && len(tok.parts) == 0) {
+				tok.expands = true
+			}
+			lit(string(c), false)
+			l.sanitized.WriteByte(c)
+			l.pos++
+		}
+	}
+
+	l.tokens = append(l.tokens, tok)
+
+	if l.awaitTerm != nil {
+		delim := literalWord(tok)
+		l.heredocs = append(l.heredocs, pendingHeredoc{
+			delim:     delim,
+			stripTabs: strings.HasSuffix(l.awaitTerm.op, "<<-"),
+			body:      l.awaitTerm.body,
+		})
+		l.awaitTerm = nil
+	}
+	return nil
+}
+
+// readDoubleQuoted reads a double-quoted string at pos into tok.
+func (l *shellLexer) readDoubleQuoted(tok *shellToken) error {
+	l.pos++ // opening quote
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return nil
+		case '\\':
+			if l.pos+1 < len(l.src) && strings.IndexByte("$`\"\\\n", l.src[l.pos+1]) >= 0 {
+				if l.src[l.pos+1] != '\n' {
+					tok.parts = append(tok.parts, wordPart{text: l.src[l.pos+1 : l.pos+2], quoted: true})
+				}
+				l.pos += 2
+			} else {
+				tok.parts = append(tok.parts, wordPart{text: "\\", quoted: true})
+				l.pos++
+			}
+		case '`':
+			start := l.pos
+			inner, err := l.readBacktick()
+			if err != nil {
+				return err
+			}
+			tok.subs = append(tok.subs, inner)
+			tok.parts = append(tok.parts, wordPart{text: l.src[start:l.pos], subst: inner, quoted: true})
+		case '$':
+			if err := l.readDollar(tok, true); err != nil {
+				return err
+			}
+		default:
+			tok.parts = append(tok.parts, wordPart{text: string(c), quoted: true})
+			l.pos++
+		}
+	}
+	return ErrUnterminated
+}
+
+// readBacktick reads a `...` substitution at pos and returns its script.
+func (l *shellLexer) readBacktick() (string, error) {
+	var inner strings.Builder
+	i := l.pos + 1
+	for i < len(l.src) {
+		c := l.src[i]
+		if c == '\\' && i+1 < len(l.src) {
+			inner.WriteByte(l.src[i+1])
+			i += 2
+			continue
+		}
+		if c == '`' {
+			l.pos = i + 1
+			return inner.String(), nil
+		}
+		inner.WriteByte(c)
+		i++
+	}
+	return "", ErrUnterminated
+}
+
+// readDollar reads a $-expansion at pos into tok.
+func (l *shellLexer) readDollar(tok *shellToken, quoted bool) error {
+	start := l.pos
+	tok.expands = true
+	switch next := l.peek(1); {
+	case next == '(' && l.peek(2) == '(':
+		// Arithmetic expansion: kept literal
+		end, err := scanBalanced(l.src, l.pos+1)
+		if err != nil {
+			return err
+		}
+		l.pos = end + 1
+	case next == '(':
+		end, err := scanBalanced(l.src, l.pos+1)
+		if err != nil {
+			return err
+		}
+		script := l.src[l.pos+2 : end]
+		tok.subs = append(tok.subs, script)
+		l.pos = end + 1
+		tok.parts = append(tok.parts, wordPart{text: l.src[start:l.pos], subst: script, quoted: quoted})
+		return nil
+	case next == '{':
+		end := strings.IndexByte(l.src[l.pos+2:], '}')
+		if end < 0 {
+			return ErrUnterminated
+		}
+		name := l.src[l.pos+2 : l.pos+2+end]
+		l.pos += end + 3
+		if isShellName(name) {
+			tok.parts = append(tok.parts, wordPart{varRef: name, quoted: quoted})
+			return nil
+		}
+	case next == '_' || (next >= 'A' && next <= 'Z') || (next >= 'a' && next <= 'z'):
+		i := l.pos + 1
+		for i < len(l.src) && isShellNameByte(l.src[i]) {
+			i++
+		}
+		name := l.src[l.pos+1 : i]
+		l.pos = i
+		tok.parts = append(tok.parts, wordPart{varRef: name, quoted: quoted})
+		return nil
+	default:
+		l.pos++
+	}
+	tok.parts = append(tok.parts, wordPart{text: l.src[start:l.pos], quoted: true})
+	return nil
+}
+
+// scanBalanced returns the index of the ")" matching the "(" at open,
+// skipping quoted strings and escapes.
+func scanBalanced(src string, open int) (int, error) {
+	depth := 0
+	for i := open; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '\'':
+			end := strings.IndexByte(src[i+1:], '\'')
+			if end < 0 {
+				return 0, ErrUnterminated
+			}
+			i += end + 1
+		case '"':
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(src) {
+				return 0, ErrUnterminated
+			}
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, ErrUnterminated
+}
+
+// literalWord returns a word's text with quotes removed and variable
+// references left unexpanded.
+func literalWord(tok *shellToken) string {
+	var b strings.Builder
+	for _, p := range tok.parts {
+		if p.varRef != "" {
+			b.WriteString("$" + p.varRef)
+		} else {
+			b.WriteString(p.text)
+		}
+	}
+	return b.String()
+}
+
+// isShellName reports whether s is a valid shell variable name.
+func isShellName(s string) bool {
+	if s == "" || (s[0] >= '0' && s[0] <= '9') {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isShellNameByte(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isShellNameByte reports whether c may appear in a shell variable name.
+func isShellNameByte(c byte) bool {
+	return c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
+
+// shellParser turns tokens into simple commands, tracking variable
+// assignments and "cd" across the command line.
+type shellParser struct {
+	vars     map[string]string
+	dir      string
+	commands []Command
+
+	// pipeFrom is the index in commands of the command whose output is
+	// piped into the next simple command, or -1.
+	pipeFrom int
+}
+
+// parse tokenizes and parses script at the given nesting depth and returns
+// its sanitized form.
+func (p *shellParser) parse(script string, depth int) (string, error) {
+	if depth > maxShellDepth {
+		return "", ErrTooDeep
+	}
+
+	tokens, sanitized, err := lexShell(script)
+	if err != nil {
+		return "", err
+	}
+
+	var current []*shellToken
+	pipeFrom := -1
+	for _, tok := range tokens {
+		if tok.kind != tokOp {
+			current = append(current, tok)
+			continue
+		}
+		n := len(p.commands)
+		p.pipeFrom = pipeFrom
+		if err := p.simpleCommand(current, depth); err != nil {
+			return "", err
+		}
+		pipeFrom = -1
+		if (tok.op == "|" || tok.op == "|&") && len(p.commands) > n {
+			pipeFrom = len(p.commands) - 1
+		}
+		current = nil
+	}
+	p.pipeFrom = pipeFrom
+	if err := p.simpleCommand(current, depth); err != nil {
+		return "", err
+	}
+	return sanitized, nil
+}
+
+// shellReservedWords are skipped at the start of a simple command.
+var shellReservedWords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"do": true, "done": true, "while": true, "until": true, "!": true,
+	"{": true, "}": true, "esac": true,
+}
+
+// simpleCommand processes the tokens of one simple command.
+func (p *shellParser) simpleCommand(tokens []*shellToken, depth int) error {
+	pipeFrom := p.pipeFrom
+	p.pipeFrom = -1
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	var cmd Command
+	var words []*shellToken
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		for _, sub := range tok.subs {
+			if _, err := p.parse(sub, depth+1); err != nil {
+				return err
+			}
+		}
+		if tok.kind != tokRedirect {
+			words = append(words, tok)
+			continue
+		}
+		target := ""
+		if i+1 < len(tokens) && tokens[i+1].kind == tokWord {
+			i++
+			for _, sub := range tokens[i].subs {
+				if _, err := p.parse(sub, depth+1); err != nil {
+					return err
+				}
+			}
+			target = p.expandJoined(tokens[i])
+		}
+		switch {
+		case tok.body != nil:
+			cmd.Stdin = *tok.body
+			cmd.Redirects = append(cmd.Redirects, tok.op+" "+target)
+		case strings.HasSuffix(tok.op, "<<<"):
+			cmd.Stdin = target
+		default:
+			cmd.Redirects = append(cmd.Redirects, tok.op+" "+target)
+		}
+	}
+
+	var argv []string
+	for _, w := range words {
+		argv = append(argv, p.expand(w)...)
+	}
+	for len(argv) > 0 && shellReservedWords[argv[0]] {
+		argv = argv[1:]
+	}
+
+	// A command made only of assignments sets shell variables.
+	if len(argv) > 0 && allAssignments(argv) {
+		for _, a := range argv {
+			name, value, _ := strings.Cut(a, "=")
+			p.vars[name] = value
+		}
+		return nil
+	}
+	if len(argv) > 0 {
+		switch argv[0] {
+		case "export", "declare", "local", "readonly", "typeset":
+			for _, a := range argv[1:] {
+				if isAssignment(a) {
+					name, value, _ := strings.Cut(a, "=")
+					p.vars[name] = value
+				}
+			}
+			return nil
+		}
+	}
+
+	effective, script, isScript := unwrapCommand(argv)
+	if pipeFrom >= 0 {
+		var err error
+		if effective, err = p.pipeInto(pipeFrom, argv, effective, depth); err != nil {
+			return err
+		}
+	}
+	if isScript {
+		_, err := p.parse(script, depth+1)
+		return err
+	}
+	if len(effective) == 0 && len(cmd.Redirects) == 0 {
+		return nil
+	}
+
+	if len(effective) > 0 {
+		name := path.Base(effective[0])
+		if name == "cd" {
+			p.changeDir(effective[1:])
+		}
+		// A heredoc or here-string fed to a shell is a script.
+		if shellInterpreters[name] && cmd.Stdin != "" {
+			if _, err := p.parse(cmd.Stdin, depth+1); err != nil {
+				return err
+			}
+			cmd.Stdin = ""
+		}
+	}
+
+	cmd.Argv = effective
+	cmd.Dir = p.dir
+	p.commands = append(p.commands, cmd)
+	return nil
+}
+
+// pipeInto handles the output of commands[from] piped into the command
+// with the given argv and returns the receiver's effective argv. Output of
+// an inert command fed to anything but another inert command is matched in
+// full, added to the arguments of the command run by xargs, and parsed as a
+// script when the receiver is a shell.
+func (p *shellParser) pipeInto(from int, argv, effective []string, depth int) ([]string, error) {
+	src := &p.commands[from]
+	if len(src.Argv) == 0 || !inertCommands[path.Base(src.Argv[0])] {
+		return effective, nil
+	}
+	receiver := ""
+	for _, a := range argv {
+		if !isAssignment(a) {
+			receiver = path.Base(a)
+			break
+		}
+	}
+	if len(effective) > 0 && receiver != "xargs" {
+		receiver = path.Base(effective[0])
+	}
+	if receiver == "" || inertCommands[receiver] {
+		return effective, nil
+	}
+
+	src.Piped = true
+	if len(effective) > 0 && len(effective) <= len(argv) {
+		effective = xargsArgs(argv[:len(argv)-len(effective)], effective, src.pipedScript())
+	}
+	if !shellInterpreters[receiver] && !(len(effective) > 0 && shellInterpreters[path.Base(effective[0])]) {
+		return effective, nil
+	}
+	// Quoted prose may not parse as shell; its text is still matched.
+	sub := &shellParser{vars: p.vars, dir: p.dir, pipeFrom: -1}
+	if _, err := sub.parse(src.pipedScript(), depth+1); err == nil {
+		p.commands = append(p.commands, sub.commands...)
+	}
+	return effective, nil
+}
+
+// xargsArgs returns effective with the input piped to xargs applied when
+// prefix, the wrapper words before effective, runs it through xargs: the
+// input replaces the -I/-i replacement string, or is appended otherwise.
+func xargsArgs(prefix, effective []string, input string) []string {
+	i := slices.IndexFunc(prefix, func(w string) bool { return path.Base(w) == "xargs" })
+	if i < 0 {
+		return effective
+	}
+	items := strings.Fields(input)
+	if len(items) == 0 {
+		return effective
+	}
+
+	replace := ""
+	for opts := prefix[i+1:]; len(opts) > 0; opts = opts[1:] {
+		switch o := opts[0]; {
+		case o == "-I" && len(opts) > 1:
+			replace = opts[1]
+		case o == "-i" || o == "--replace":
+			replace = "{}"
+		case strings.HasPrefix(o, "--replace="):
+			replace = strings.TrimPrefix(o, "--replace=")
+		case strings.HasPrefix(o, "-I") || strings.HasPrefix(o, "-i"):
+			replace = o[2:]
+		}
+	}
+	if replace == "" {
+		return append(slices.Clone(effective), items...)
+	}
+	joined := strings.Join(items, " ")
+	result := make([]string, len(effective))
+	for j, a := range effective {
+		result[j] = strings.ReplaceAll(a, replace, joined)
+	}
+	return result
+}
+
+// changeDir applies a "cd" to the tracked directory.
+func (p *shellParser) changeDir(args []string) {
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0] == "-" || strings.HasPrefix(args[0], "~") {
+		return
+	}
+	if path.IsAbs(args[0]) {
+		p.dir = args[0]
+		return
+	}
+	p.dir = path.Join(p.dir, args[0])
+}
+
+// expand expands a word into fields, substituting known variables and the
+// output of inert command substitutions, and splitting unquoted expansions
+// on whitespace.
+func (p *shellParser) expand(tok *shellToken) []string {
+	var fields []string
+	var cur strings.Builder
+	hasCur := false
+	for _, part := range tok.parts {
+		var value string
+		var known bool
+		switch {
+		case part.subst != "":
+			value, known = p.substOutput(part.subst)
+		case part.varRef != "":
+			value, known = p.vars[part.varRef]
+		default:
+			cur.WriteString(part.text)
+			hasCur = hasCur || part.text != "" || part.quoted
+			continue
+		}
+		if !known {
+			if part.subst != "" {
+				cur.WriteString(part.text)
+			} else {
+				cur.WriteString("$" + part.varRef)
+			}
+			hasCur = true
+			continue
+		}
+		if part.quoted {
+			cur.WriteString(value)
+			hasCur = true
+			continue
+		}
+		pieces := strings.Fields(value)
+		for i, piece := range pieces {
+			if i > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+			cur.WriteString(piece)
+			hasCur = true
+		}
+	}
+	if hasCur {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+// substOutput returns the output of a command substitution whose script is
+// a single echo or printf, so that "$(echo rm) -rf /" is matched as
+// "rm -rf /". Other substitutions are not resolved.
+func (p *shellParser) substOutput(script string) (string, bool) {
+	sub := &shellParser{vars: maps.Clone(p.vars), dir: p.dir, pipeFrom: -1}
+	if _, err := sub.parse(script, 0); err != nil || len(sub.commands) != 1 {
+		return "", false
+	}
+	cmd := sub.commands[0]
+	if len(cmd.Argv) == 0 || len(cmd.Redirects) > 0 {
+		return "", false
+	}
+	args := cmd.Argv[1:]
+	switch path.Base(cmd.Argv[0]) {
+	case "echo":
+		for len(args) > 0 && (args[0] == "-n" || args[0] == "-e" || args[0] == "-E") {
+			args = args[1:]
+		}
+	case "printf":
+		if len(args) == 1 {
+			return strings.TrimSpace(strings.ReplaceAll(args[0], `\n`, "\n")), true
+		}
+		if len(args) > 0 {
+			args = args[1:] // format
+		}
+	default:
+		return "", false
+	}
+	return strings.TrimSpace(strings.Join(args, " ")), true
+}
+
+// expandJoined expands a word into a single string without field splitting.
+func (p *shellParser) expandJoined(tok *shellToken) string {
+	return strings.Join(p.expand(tok), " ")
+}
+
+// isAssignment reports whether word has the form NAME=value.
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	return ok && isShellName(name)
+}
+
+// allAssignments reports whether every word is an assignment.
+func allAssignments(words []string) bool {
+	for _, w := range words {
+		if !isAssignment(w) {
+			return false
+		}
+	}
+	return true
+}
+
+// shellInterpreters run their "-c" argument or stdin as a shell script.
+var shellInterpreters = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "ash": true,
+}
+
+// Option flags that consume the following argument, per wrapper command.
+var (
+	sudoValueFlags = map[string]bool{
+		"-u": true, "--user": true, "-g": true, "--group": true, "-C": true,
+		"--close-from": true, "-D": true, "--chdir": true, "-h": true, "--host": true,
+		"-p": true, "--prompt": true, "-r": true, "--role": true, "-t": true,
+		"--type": true, "-U": true, "--other-user": true, "-T": true, "--command-timeout": true,
+	}
+	envValueFlags = map[string]bool{
+		"-u": true, "--unset": true, "-C": true, "--chdir": true,
+	}
+	niceValueFlags = map[string]bool{
+		"-n": true, "--adjustment": true,
+	}
+	timeoutValueFlags = map[string]bool{
+		"-s": true, "--signal": true, "-k": true, "--kill-after": true,
+	}
+	xargsValueFlags = map[string]bool{
+		"-I": true, "-L": true, "-n": true, "-P": true, "-s": true, "-d": true,
+		"-E": true, "-a": true, "--max-args": true, "--max-procs": true,
+		"--max-lines": true, "--delimiter": true, "--arg-file": true, "--eof": true,
+	}
+)
+
+// unwrapCommand strips prefixes that run another command (sudo, env,
+// command, nohup, xargs, ...) and returns the effective argv. When the
+// command runs an inline script ("bash -c", eval) the script is returned
+// with isScript set instead.
+func unwrapCommand(argv []string) (effective []string, script string, isScript bool) {
+	for len(argv) > 0 {
+		if isAssignment(argv[0]) {
+			argv = argv[1:] // FOO=1 cmd
+			continue
+		}
+
+		switch name := path.Base(argv[0]); name {
+		case "sudo", "doas":
+			argv = skipOptions(argv[1:], sudoValueFlags)
+		case "env":
+			argv = skipEnvOptions(argv[1:])
+		case "command", "builtin", "exec", "nohup", "time", "stdbuf", "unbuffer", "setsid":
+			argv = skipOptions(argv[1:], nil)
+		case "nice", "ionice":
+			argv = skipOptions(argv[1:], niceValueFlags)
+		case "timeout":
+			argv = skipOptions(argv[1:], timeoutValueFlags)
+			if len(argv) > 0 {
+				argv = argv[1:] // duration
+			}
+		case "xargs":
+			argv = skipOptions(argv[1:], xargsValueFlags)
+		case "eval":
+			return nil, strings.Join(argv[1:], " "), true
+		default:
+			if shellInterpreters[name] {
+				if s, ok := shellScriptArg(argv[1:]); ok {
+					return nil, s, true
+				}
+			}
+			return argv, "", false
+		}
+	}
+	return argv, "", false
+}
+
+// skipOptions drops leading option arguments, including the values of
+// options listed in valueFlags, and a terminating "--".
+func skipOptions(args []string, valueFlags map[string]bool) []string {
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") && args[0] != "-" {
+		if args[0] == "--" {
+			return args[1:]
+		}
+		if valueFlags[args[0]] && len(args) > 1 {
+			args = args[2:]
+			continue
+		}
+		args = args[1:]
+	}
+	return args
+}
+
+// skipEnvOptions drops env(1) options and NAME=value assignments.
+// "env -S 'cmd args'" splits its argument into the command.
+func skipEnvOptions(args []string) []string {
+	for len(args) > 0 {
+		a := args[0]
+		switch {
+		case a == "-S" || a == "--split-string":
+			if len(args) < 2 {
+				return nil
+			}
+			return append(strings.Fields(args[1]), args[2:]...)
+		case strings.HasPrefix(a, "--split-string="):
+			return append(strings.Fields(strings.TrimPrefix(a, "--split-string=")), args[1:]...)
+		case a == "--":
+			args = args[1:]
+		case envValueFlags[a] && len(args) > 1:
+			args = args[2:]
+		case strings.HasPrefix(a, "-") && a != "-":
+			args = args[1:]
+		case isAssignment(a):
+			args = args[1:]
+		default:
+			return args
+		}
+	}
+	return args
+}
+
+// shellScriptArg returns the "-c" script argument of a shell invocation.
+func shellScriptArg(args []string) (string, bool) {
+	sawC := false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			if sawC && i+1 < len(args) {
+				return args[i+1], true
+			}
+			return "", false
+		}
+		if a == "-o" || a == "+o" {
+			i++
+			continue
+		}
+		if (strings.HasPrefix(a, "-") || strings.HasPrefix(a, "+")) && len(a) > 1 {
+			if !strings.HasPrefix(a, "--") && strings.ContainsRune(a[1:], 'c') {
+				sawC = true
+			}
+			continue
+		}
+		if sawC {
+			return a, true
+		}
+		return "", false
+	}
+	return "", false
+}
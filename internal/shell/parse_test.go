@@ -0,0 +1,150 @@
+package shell
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseCommands(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		command string
+		want    []string // String() of each effective command
+	}{
+		{"simple", "go test ./...", []string{"go test ./..."}},
+		{"and chain", "cd web && npm ci", []string{"cd web", "npm ci"}},
+		{"semicolon and or", "make build; make test || echo failed", []string{"make build", "make test", "echo failed"}},
+		{"pipeline", "ls -la | grep foo | wc -l", []string{"ls -la", "grep foo", "wc -l"}},
+		{"subshell", "(cd /tmp && rm -rf build)", []string{"cd /tmp", "rm -rf build"}},
+		{"bash -c", `bash -c "rm -rf /"`, []string{"rm -rf /"}},
+		{"sh -lc", `sh -lc 'echo hi; terraform destroy'`, []string{"echo hi", "terraform destroy"}},
+		{"eval", `eval "rm -rf ~"`, []string{"rm -rf ~"}},
+		{"sudo with user", "sudo -u root rm -rf /var/lib", []string{"rm -rf /var/lib"}},
+		{"env with assignments", "env FOO=1 BAR=2 docker volume prune", []string{"docker volume prune"}},
+		{"command builtin", "command rm -rf /", []string{"rm -rf /"}},
+		{"prefix assignment", "FOO=1 terraform destroy", []string{"terraform destroy"}},
+		{"xargs", "find . -name '*.tmp' | xargs -n 1 rm -rf", []string{"find . -name *.tmp", "rm -rf"}},
+		{"command substitution", "echo $(rm -rf /)", []string{"rm -rf /", "echo $(rm -rf /)"}},
+		{"backticks", "echo `git reset --hard`", []string{"git reset --hard", "echo `git reset --hard`"}},
+		{"inert substitution in command position", "$(echo rm) -rf /", []string{"echo rm", "rm -rf /"}},
+		{"inert backtick substitution", "`printf rm` -rf /", []string{"printf rm", "rm -rf /"}},
+		{"echo piped into xargs", "echo / | xargs rm -rf", []string{"echo /", "rm -rf /"}},
+		{"xargs replacement string", "printf '/' | xargs -I{} rm -rf {}", []string{"printf /", "rm -rf /"}},
+		{"heredoc through cat into xargs", "cat <<EOF | xargs rm -rf\n/\nEOF", []string{"cat << EOF", "rm -rf /"}},
+		{"variable indirection", `X="rm -rf"; $X /`, []string{"rm -rf /"}},
+		{"braced variable", `CMD=terraform; ${CMD} destroy`, []string{"terraform destroy"}},
+		{"quoted variable keeps one field", `X="a b"; printf "$X"`, []string{"printf a b"}},
+		{"redirection", "echo x > /dev/sda", []string{"echo x > /dev/sda"}},
+		{"fd redirection", "make 2>&1 | tee log", []string{"make 2>& 1", "tee log"}},
+		{"heredoc to cat", "cat <<EOF > notes.md\nrm -rf /\nEOF", []string{"cat << EOF > notes.md"}},
+		{"heredoc to bash", "bash <<'EOF'\nrm -rf /\nEOF", []string{"rm -rf /", "bash << EOF"}},
+		{"comment", "ls # rm -rf /", []string{"ls"}},
+		{"nested bash -c", `bash -c "sudo bash -c 'rm -rf /'"`, []string{"rm -rf /"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmds, _, err := ParseCommands(tt.command)
+			if err != nil {
+				t.Fatalf("ParseCommands(%q) error: %v", tt.command, err)
+			}
+			got := make([]string, len(cmds))
+			for i, c := range cmds {
+				got[i] = c.String()
+			}
+			if strings.Join(got, " | ") != strings.Join(tt.want, " | ") {
+				t.Errorf("ParseCommands(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCommands_TracksDirectory(t *testing.T) {
+	t.Parallel()
+
+	cmds, _, err := ParseCommands("cd infra/sandbox && terraform destroy; cd .. && ls")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dirs := map[string]string{}
+	for _, c := range cmds {
+		dirs[c.String()] = c.Dir
+	}
+	if dirs["terraform destroy"] != "infra/sandbox" {
+		t.Errorf("terraform destroy Dir = %q, want infra/sandbox", dirs["terraform destroy"])
+	}
+	if dirs["ls"] != "infra" {
+		t.Errorf("ls Dir = %q, want infra", dirs["ls"])
+	}
+}
+
+func TestParseCommands_Sanitized(t *testing.T) {
+	t.Parallel()
+
+	_, sanitized, err := ParseCommands("git commit -m \"rm -rf /\" && cat <<EOF\nDROP DATABASE x\nEOF")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(sanitized, "rm -rf") || strings.Contains(sanitized, "DROP DATABASE") {
+		t.Errorf("sanitized command should not contain quoted or heredoc text: %q", sanitized)
+	}
+}
+
+func TestParseCommands_Errors(t *testing.T) {
+	t.Parallel()
+
+	for _, command := range []string{`echo "unterminated`, `echo 'x`, "echo $(ls", "echo `ls"} {
+		if _, _, err := ParseCommands(command); err == nil {
+			t.Errorf("ParseCommands(%q) expected error", command)
+		}
+	}
+
+	deep := strings.Repeat("echo $(", maxShellDepth+2) + "ls" + strings.Repeat(")", maxShellDepth+2)
+	if _, _, err := ParseCommands(deep); err == nil {
+		t.Error("expected error for deeply nested command")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"go test -json ./...", []string{"go", "test", "-json", "./..."}},
+		{`pytest -q -k "not slow"`, []string{"pytest", "-q", "-k", "not slow"}},
+		{`eslint --ext '.ts,.tsx' src\ dir`, []string{"eslint", "--ext", ".ts,.tsx", "src dir"}},
+		{"go vet {packages}", []string{"go", "vet", "{packages}"}},
+		{"  cargo build  ", []string{"cargo", "build"}},
+	}
+	for _, tt := range tests {
+		got, err := Split(tt.command)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("Split(%q) = %q, %v; want %q", tt.command, got, err, tt.want)
+		}
+	}
+
+	for _, command := range []string{
+		"npm ci && npm test",
+		"go test ./... | tee test.log",
+		"pytest > out.txt",
+		"go test $PKGS",
+		"echo $(ls)",
+		"ruff check *.py",
+		"ls ~/src",
+	} {
+		if _, err := Split(command); !errors.Is(err, ErrNotSimple) {
+			t.Errorf("Split(%q) error = %v, want ErrNotSimple", command, err)
+		}
+	}
+	if _, err := Split(`pytest "unterminated`); !errors.Is(err, ErrUnterminated) {
+		t.Errorf("unterminated quote error = %v, want ErrUnterminated", err)
+	}
+}
@@ -0,0 +1,471 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Phase names a step of the Plan-Run-Sync workflow.
+type Phase string
+
+const (
+	// PhasePlan creates the SPEC document ("/moai plan").
+	PhasePlan Phase = "plan"
+
+	// PhaseRun implements the SPEC ("/moai run").
+	PhaseRun Phase = "run"
+
+	// PhaseSync documents the result and prepares the PR ("/moai sync").
+	PhaseSync Phase = "sync"
+)
+
+// claudeWaitDelay bounds how long a stopped claude process may keep its
+// output pipes open before they are closed.
+const claudeWaitDelay = 5 * time.Second
+
+// stderrTailSize is the amount of claude's stderr kept for error messages.
+const stderrTailSize = 2048
+
+// phaseLogIdle is how long a phase log without a result event may go
+// unwritten before its phase is considered stopped rather than running.
+const phaseLogIdle = 10 * time.Minute
+
+// LogSubdir holds the per-phase claude logs under .moai/logs.
+const LogSubdir = "workflow"
+
+// TokenBudget caps the tokens claude may use in each phase. Zero means
+// no limit.
+type TokenBudget struct {
+	Plan int
+	Run  int
+	Sync int
+}
+
+// For returns the budget of phase.
+func (b TokenBudget) For(phase Phase) int {
+	switch phase {
+	case PhasePlan:
+		return b.Plan
+	case PhaseRun:
+		return b.Run
+	case PhaseSync:
+		return b.Sync
+	}
+	return 0
+}
+
+// PhaseResult records one headless claude invocation.
+type PhaseResult struct {
+	SpecID      string
+	Phase       Phase
+	Status      WorkflowPhaseStatus
+	ExitCode    int
+	Tokens      int
+	Budget      int
+	CostUSD     float64
+	LogPath     string
+	StartedAt   time.Time
+	CompletedAt time.Time
+}
+
+// ClaudeOption configures a claudeExecutor.
+type ClaudeOption func(*claudeExecutor)
+
+// WithClaudeBinary sets the claude executable (default "claude" on PATH).
+func WithClaudeBinary(path string) ClaudeOption {
+	return func(e *claudeExecutor) {
+		e.binary = path
+	}
+}
+
+// WithPhaseTimeout bounds the duration of each phase. Zero means no limit.
+func WithPhaseTimeout(d time.Duration) ClaudeOption {
+	return func(e *claudeExecutor) {
+		e.timeout = d
+	}
+}
+
+// WithClaudeArgs appends extra arguments to every claude invocation,
+// such as "--permission-mode" "acceptEdits".
+func WithClaudeArgs(args ...string) ClaudeOption {
+	return func(e *claudeExecutor) {
+		e.args = append(e.args, args...)
+	}
+}
+
+// WithExecutorLogger sets the logger of the executor.
+func WithExecutorLogger(logger *slog.Logger) ClaudeOption {
+	return func(e *claudeExecutor) {
+		if logger != nil {
+			e.logger = logger
+		}
+	}
+}
+
+// claudeExecutor implements PhaseExecutor by running Claude Code headless
+// ("claude -p /moai <phase> <SPEC>") inside the worktree.
+type claudeExecutor struct {
+	binary  string
+	args    []string
+	budget  TokenBudget
+	timeout time.Duration
+	logDir  string
+	logger  *slog.Logger
+
+	mu   sync.Mutex
+	runs []PhaseResult
+}
+
+// Compile-time interface compliance check.
+var _ PhaseExecutor = (*claudeExecutor)(nil)
+
+// NewClaudeExecutor creates a PhaseExecutor that runs each phase with the
+// claude CLI. The stream-json output of every phase is written to
+// logDir/<SPEC>-<phase>.log, and a phase is stopped once it exceeds its
+// token budget.
+func NewClaudeExecutor(logDir string, budget TokenBudget, opts ...ClaudeOption) *claudeExecutor {
+	e := &claudeExecutor{
+		binary: "claude",
+		budget: budget,
+		logDir: logDir,
+		logger: slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.logger = e.logger.With("module", "claude-executor")
+	return e
+}
+
+// ExecutePlan runs "/moai plan" for specID in workDir.
+func (e *claudeExecutor) ExecutePlan(ctx context.Context, specID, workDir string) error {
+	return e.execute(ctx, PhasePlan, specID, workDir)
+}
+
+// ExecuteRun runs "/moai run" for specID in workDir.
+func (e *claudeExecutor) ExecuteRun(ctx context.Context, specID, workDir string) error {
+	return e.execute(ctx, PhaseRun, specID, workDir)
+}
+
+// ExecuteSync runs "/moai sync" for specID in workDir.
+func (e *claudeExecutor) ExecuteSync(ctx context.Context, specID, workDir string) error {
+	return e.execute(ctx, PhaseSync, specID, workDir)
+}
+
+// Runs returns the phases executed so far, in order.
+func (e *claudeExecutor) Runs() []PhaseResult {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]PhaseResult(nil), e.runs...)
+}
+
+// execute runs one phase and records its outcome.
+func (e *claudeExecutor) execute(ctx context.Context, phase Phase, specID, workDir string) error {
+	run := PhaseResult{
+		SpecID:    specID,
+		Phase:     phase,
+		Status:    PhaseStatusRunning,
+		ExitCode:  -1,
+		Budget:    e.budget.For(phase),
+		LogPath:   filepath.Join(e.logDir, fmt.Sprintf("%s-%s.log", specID, phase)),
+		StartedAt: time.Now(),
+	}
+	err := e.runClaude(ctx, &run, workDir)
+	run.CompletedAt = time.Now()
+	run.Status = phaseStatus(run.ExitCode, err)
+
+	e.mu.Lock()
+	e.runs = append(e.runs, run)
+	e.mu.Unlock()
+
+	e.logger.Info("phase finished",
+		"spec_id", specID,
+		"phase", string(phase),
+		"status", string(run.Status),
+		"exit_code", run.ExitCode,
+		"tokens", run.Tokens,
+		"log", run.LogPath,
+	)
+	if err != nil {
+		return fmt.Errorf("%s %s (log %s): %w", phase, specID, run.LogPath, err)
+	}
+	return nil
+}
+
+// runClaude starts claude for run.Phase, streams its output to the phase
+// log while counting tokens, and waits for it to exit.
+func (e *claudeExecutor) runClaude(ctx context.Context, run *PhaseResult, workDir string) error {
+	if err := os.MkdirAll(e.logDir, 0o755); err != nil {
+		return fmt.Errorf("create log directory: %w", err)
+	}
+	logFile, err := os.Create(run.LogPath)
+	if err != nil {
+		return fmt.Errorf("create phase log: %w", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	prompt := fmt.Sprintf("/moai %s %s", run.Phase, run.SpecID)
+	args := append([]string{"-p", prompt, "--output-format", "stream-json", "--verbose"}, e.args...)
+	cmd := exec.CommandContext(ctx, e.binary, args...)
+	cmd.Dir = workDir
+	cmd.WaitDelay = claudeWaitDelay
+
+	stream := &streamCounter{log: logFile, budget: run.Budget, stop: stop}
+	stderr := &tailBuffer{limit: stderrTailSize}
+	cmd.Stdout = stream
+	cmd.Stderr = stderr
+
+	e.logger.Debug("starting claude", "spec_id", run.SpecID, "phase", string(run.Phase), "dir", workDir)
+	waitErr := cmd.Run()
+	stream.flush()
+	if cmd.ProcessState != nil {
+		run.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	run.Tokens = stream.tokens()
+	run.CostUSD = stream.cost
+	if tail := strings.TrimSpace(stderr.String()); tail != "" {
+		_, _ = fmt.Fprintf(logFile, "\n--- stderr ---\n%s\n", tail)
+	}
+
+	switch {
+	case errors.Is(context.Cause(ctx), ErrTokenBudgetExceeded):
+		return fmt.Errorf("%w: used %d of %d tokens", ErrTokenBudgetExceeded, run.Tokens, run.Budget)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%w after %s", ErrPhaseTimeout, e.timeout)
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case waitErr != nil:
+		var exitErr *exec.ExitError
+		if !errors.As(waitErr, &exitErr) {
+			return fmt.Errorf("start %s: %w", e.binary, waitErr)
+		}
+		if stream.failed != "" {
+			return fmt.Errorf("%w: exit status %d: %s", ErrClaudeFailed, run.ExitCode, stream.failed)
+		}
+		return fmt.Errorf("%w: exit status %d%s", ErrClaudeFailed, run.ExitCode, stderrSuffix(stderr.String()))
+	case stream.failed != "":
+		return fmt.Errorf("%w: %s", ErrClaudeFailed, stream.failed)
+	}
+	return nil
+}
+
+// phaseStatus maps the exit code and error of a claude run to the status
+// of its phase.
+func phaseStatus(exitCode int, err error) WorkflowPhaseStatus {
+	if err == nil && exitCode == 0 {
+		return PhaseStatusCompleted
+	}
+	return PhaseStatusFailed
+}
+
+// stderrSuffix formats the last line of stderr for an error message.
+func stderrSuffix(stderr string) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return ": " + last
+	}
+	return ""
+}
+
+// ReadPhaseLogs reads the phase logs of specID written to logDir by a
+// claude executor, possibly in another process, and returns the phases
+// found in workflow order. A phase whose log has no result event is
+// running while the log keeps being written and failed once it goes
+// idle.
+func ReadPhaseLogs(logDir, specID string) ([]PhaseResult, error) {
+	var runs []PhaseResult
+	for _, phase := range []Phase{PhasePlan, PhaseRun, PhaseSync} {
+		path := filepath.Join(logDir, fmt.Sprintf("%s-%s.log", specID, phase))
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return runs, fmt.Errorf("read %s log: %w", phase, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return runs, fmt.Errorf("read %s log: %w", phase, err)
+		}
+
+		stream := &streamCounter{log: io.Discard}
+		_, _ = stream.Write(data)
+		stream.flush()
+
+		run := PhaseResult{
+			SpecID:  specID,
+			Phase:   phase,
+			Status:  PhaseStatusCompleted,
+			Tokens:  stream.tokens(),
+			CostUSD: stream.cost,
+			LogPath: path,
+		}
+		switch {
+		case stream.failed != "":
+			run.Status = PhaseStatusFailed
+		case !stream.finished && time.Since(info.ModTime()) < phaseLogIdle:
+			run.Status = PhaseStatusRunning
+		case !stream.finished:
+			run.Status = PhaseStatusFailed
+		}
+		if run.Status != PhaseStatusRunning {
+			run.CompletedAt = info.ModTime()
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// streamEvent is the part of a claude stream-json event the executor reads.
+type streamEvent struct {
+	Type         string       `json:"type"`
+	Subtype      string       `json:"subtype"`
+	IsError      bool         `json:"is_error"`
+	Result       string       `json:"result"`
+	TotalCostUSD float64      `json:"total_cost_usd"`
+	Usage        *streamUsage `json:"usage"`
+	Message      *struct {
+		ID    string       `json:"id"`
+		Usage *streamUsage `json:"usage"`
+	} `json:"message"`
+}
+
+// streamUsage is the token usage of a message or a whole session.
+type streamUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+}
+
+// total counts the tokens processed for a request. Cache reads are left
+// out: they repeat context already paid for.
+func (u *streamUsage) total() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.OutputTokens
+}
+
+// streamCounter copies claude's stream-json output to the phase log and
+// sums the token usage of its assistant messages, stopping the run when
+// the budget is exceeded.
+type streamCounter struct {
+	log    io.Writer
+	budget int
+	stop   context.CancelCauseFunc
+
+	partial  []byte
+	messages map[string]int
+	used     int
+	final    int
+	cost     float64
+	failed   string
+	finished bool
+}
+
+// Write implements io.Writer. Complete lines are parsed as events.
+func (s *streamCounter) Write(p []byte) (int, error) {
+	if _, err := s.log.Write(p); err != nil {
+		return 0, err
+	}
+	s.partial = append(s.partial, p...)
+	for {
+		i := bytes.IndexByte(s.partial, '\n')
+		if i < 0 {
+			break
+		}
+		s.handle(s.partial[:i])
+		s.partial = s.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// flush parses a last event not terminated by a newline.
+func (s *streamCounter) flush() {
+	if len(s.partial) > 0 {
+		s.handle(s.partial)
+		s.partial = nil
+	}
+}
+
+// tokens returns the tokens used, preferring the session total of the
+// result event when it is larger than the sum of the messages seen.
+func (s *streamCounter) tokens() int {
+	return max(s.used, s.final)
+}
+
+// handle accounts for one stream-json line. Lines that are not JSON are
+// only logged.
+func (s *streamCounter) handle(line []byte) {
+	var ev streamEvent
+	if err := json.Unmarshal(bytes.TrimSpace(line), &ev); err != nil {
+		return
+	}
+	switch ev.Type {
+	case "assistant":
+		if ev.Message == nil || ev.Message.Usage == nil {
+			return
+		}
+		// A message is streamed as one event per content block, each
+		// repeating its usage, so only the largest is counted.
+		if s.messages == nil {
+			s.messages = make(map[string]int)
+		}
+		n := ev.Message.Usage.total()
+		if prev := s.messages[ev.Message.ID]; n > prev {
+			s.used += n - prev
+			s.messages[ev.Message.ID] = n
+		}
+	case "result":
+		s.finished = true
+		if ev.Usage != nil {
+			s.final = ev.Usage.total()
+		}
+		s.cost = ev.TotalCostUSD
+		if ev.IsError {
+			s.failed = ev.Subtype
+			if ev.Result != "" {
+				s.failed += ": " + ev.Result
+			}
+		}
+	}
+	if s.budget > 0 && s.tokens() > s.budget {
+		s.stop(ErrTokenBudgetExceeded)
+	}
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	limit int
+	buf   []byte
+}
+
+// Write implements io.Writer.
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.limit; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+// String returns the kept bytes.
+func (t *tailBuffer) String() string {
+	return string(t.buf)
+}
@@ -0,0 +1,209 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modu-ai/moai-adk/internal/core/git"
+	"github.com/modu-ai/moai-adk/internal/core/quality"
+)
+
+// fakeClaude is a claude stand-in driven by FAKE_CLAUDE_MODE. It records
+// its arguments and working directory in args.txt and prints stream-json
+// events.
+const fakeClaude = `#!/bin/sh
+printf '%s\n' "$*" > args.txt
+pwd >> args.txt
+echo '{"type":"system","subtype":"init"}'
+echo '{"type":"assistant","message":{"id":"m1","usage":{"input_tokens":100,"cache_read_input_tokens":5000,"output_tokens":20}}}'
+echo '{"type":"assistant","message":{"id":"m1","usage":{"input_tokens":100,"cache_read_input_tokens":5000,"output_tokens":50}}}'
+case "$FAKE_CLAUDE_MODE" in
+fail)
+	echo "authentication required" >&2
+	exit 2 ;;
+error-result)
+	echo '{"type":"result","subtype":"error_max_turns","is_error":true,"usage":{"input_tokens":100,"output_tokens":50}}'
+	exit 0 ;;
+budget)
+	echo '{"type":"assistant","message":{"id":"m2","usage":{"input_tokens":900,"output_tokens":100}}}'
+	exec sleep 10 ;;
+hang)
+	exec sleep 10 ;;
+esac
+printf '{"type":"result","subtype":"success","is_error":false,"total_cost_usd":0.25,"usage":{"input_tokens":180,"output_tokens":70}}'
+`
+
+// installFakeClaude puts fakeClaude first on PATH in the given mode.
+func installFakeClaude(t *testing.T, mode string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake claude is a shell script")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "claude"), []byte(fakeClaude), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_CLAUDE_MODE", mode)
+}
+
+func TestClaudeExecutor_Success(t *testing.T) {
+	installFakeClaude(t, "success")
+	workDir, logDir := t.TempDir(), t.TempDir()
+	executor := NewClaudeExecutor(logDir, TokenBudget{Plan: 1000}, WithClaudeArgs("--permission-mode", "acceptEdits"))
+
+	if err := executor.ExecutePlan(context.Background(), "SPEC-ISSUE-7", workDir); err != nil {
+		t.Fatalf("ExecutePlan() error = %v", err)
+	}
+
+	args, err := os.ReadFile(filepath.Join(workDir, "args.txt"))
+	if err != nil {
+		t.Fatalf("claude did not run in the worktree: %v", err)
+	}
+	for _, want := range []string{"-p /moai plan SPEC-ISSUE-7 --output-format stream-json --verbose --permission-mode acceptEdits", workDir} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("claude invocation missing %q:\n%s", want, args)
+		}
+	}
+
+	runs := executor.Runs()
+	if len(runs) != 1 {
+		t.Fatalf("Runs() = %+v, want one", runs)
+	}
+	run := runs[0]
+	// The result total (250) exceeds the deduplicated message usage (150).
+	if run.Status != PhaseStatusCompleted || run.ExitCode != 0 || run.Tokens != 250 || run.CostUSD != 0.25 || run.Budget != 1000 {
+		t.Errorf("run = %+v, want completed with 250 tokens", run)
+	}
+	if run.LogPath != filepath.Join(logDir, "SPEC-ISSUE-7-plan.log") {
+		t.Errorf("LogPath = %q", run.LogPath)
+	}
+	log, err := os.ReadFile(run.LogPath)
+	if err != nil || !strings.Contains(string(log), `"subtype":"success"`) {
+		t.Errorf("phase log = %q, %v; want the stream-json output", log, err)
+	}
+}
+
+func TestClaudeExecutor_Failures(t *testing.T) {
+	tests := []struct {
+		mode    string
+		budget  int
+		timeout time.Duration
+		wantErr error
+		wantMsg string
+	}{
+		{mode: "fail", wantErr: ErrClaudeFailed, wantMsg: "exit status 2: authentication required"},
+		{mode: "error-result", wantErr: ErrClaudeFailed, wantMsg: "error_max_turns"},
+		{mode: "budget", budget: 500, wantErr: ErrTokenBudgetExceeded, wantMsg: "used 1150 of 500 tokens"},
+		{mode: "hang", timeout: 200 * time.Millisecond, wantErr: ErrPhaseTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			installFakeClaude(t, tt.mode)
+			executor := NewClaudeExecutor(t.TempDir(), TokenBudget{Run: tt.budget}, WithPhaseTimeout(tt.timeout))
+
+			start := time.Now()
+			err := executor.ExecuteRun(context.Background(), "SPEC-ISSUE-7", t.TempDir())
+			if !errors.Is(err, tt.wantErr) || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Fatalf("ExecuteRun() error = %v, want %v containing %q", err, tt.wantErr, tt.wantMsg)
+			}
+			if elapsed := time.Since(start); elapsed > 8*time.Second {
+				t.Errorf("claude was not stopped: took %s", elapsed)
+			}
+			if runs := executor.Runs(); len(runs) != 1 || runs[0].Status != PhaseStatusFailed {
+				t.Errorf("Runs() = %+v, want one failed phase", runs)
+			}
+		})
+	}
+}
+
+func TestClaudeExecutor_MissingBinary(t *testing.T) {
+	executor := NewClaudeExecutor(t.TempDir(), TokenBudget{}, WithClaudeBinary(filepath.Join(t.TempDir(), "claude")))
+	err := executor.ExecuteSync(context.Background(), "SPEC-ISSUE-7", t.TempDir())
+	if err == nil || errors.Is(err, ErrClaudeFailed) {
+		t.Fatalf("ExecuteSync() error = %v, want a start error", err)
+	}
+	if runs := executor.Runs(); len(runs) != 1 || runs[0].Status != PhaseStatusFailed || runs[0].ExitCode != -1 {
+		t.Errorf("Runs() = %+v, want one failed phase without exit code", runs)
+	}
+}
+
+func TestClaudeExecutor_WithOrchestrator(t *testing.T) {
+	installFakeClaude(t, "success")
+	specID := "SPEC-ISSUE-42"
+	wtDir := setupWorktree(t, specID)
+	wm := &mockWorktreeManager{worktrees: []git.Worktree{{Path: wtDir, Branch: "feat/issue-42"}}}
+	wv := &mockWorktreeValidator{report: &quality.Report{Passed: true, Score: 1.0}}
+	executor := NewClaudeExecutor(t.TempDir(), TokenBudget{})
+
+	orch := mustNewWorktreeOrchestrator(t, wm, wv, executor, nil)
+	result, err := orch.ExecuteWorkflow(context.Background(), specID)
+	if err != nil {
+		t.Fatalf("ExecuteWorkflow() error = %v", err)
+	}
+	if result.PlanStatus != PhaseStatusCompleted || result.RunStatus != PhaseStatusCompleted || result.SyncStatus != PhaseStatusCompleted {
+		t.Errorf("result = %+v, want all phases completed", result)
+	}
+	var phases []Phase
+	for _, run := range executor.Runs() {
+		phases = append(phases, run.Phase)
+	}
+	if len(phases) != 3 || phases[0] != PhasePlan || phases[1] != PhaseRun || phases[2] != PhaseSync {
+		t.Errorf("phases = %v, want plan, run, sync", phases)
+	}
+}
+
+func TestReadPhaseLogs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	logs := map[Phase]string{
+		PhasePlan: `{"type":"assistant","message":{"id":"m1","usage":{"input_tokens":100,"output_tokens":20}}}
+{"type":"result","subtype":"success","is_error":false,"total_cost_usd":0.5,"usage":{"input_tokens":150,"output_tokens":30}}
+`,
+		PhaseRun: `{"type":"assistant","message":{"id":"m2","usage":{"input_tokens":400,"output_tokens":100}}}
+`,
+	}
+	for phase, content := range logs {
+		if err := os.WriteFile(filepath.Join(dir, "SPEC-ISSUE-1-"+string(phase)+".log"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := ReadPhaseLogs(dir, "SPEC-ISSUE-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("runs = %+v, want plan and run", runs)
+	}
+	if plan := runs[0]; plan.Phase != PhasePlan || plan.Status != PhaseStatusCompleted || plan.Tokens != 180 || plan.CostUSD != 0.5 {
+		t.Errorf("plan = %+v, want completed with the result totals", plan)
+	}
+	if run := runs[1]; run.Phase != PhaseRun || run.Status != PhaseStatusRunning || run.Tokens != 500 || !run.CompletedAt.IsZero() {
+		t.Errorf("run = %+v, want running", run)
+	}
+
+	// A log without a result that stopped being written is a failed phase.
+	idle := time.Now().Add(-2 * phaseLogIdle)
+	if err := os.Chtimes(filepath.Join(dir, "SPEC-ISSUE-1-run.log"), idle, idle); err != nil {
+		t.Fatal(err)
+	}
+	runs, err = ReadPhaseLogs(dir, "SPEC-ISSUE-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs[1].Status != PhaseStatusFailed {
+		t.Errorf("idle run status = %s, want failed", runs[1].Status)
+	}
+
+	if runs, err := ReadPhaseLogs(dir, "SPEC-ISSUE-2"); err != nil || len(runs) != 0 {
+		t.Errorf("ReadPhaseLogs() without logs = %+v, %v", runs, err)
+	}
+}
@@ -28,6 +28,16 @@ var (
 	// ErrSyncPhaseFailed indicates the Sync phase failed.
 	ErrSyncPhaseFailed = errors.New("workflow: sync phase failed")
 
+	// ErrClaudeFailed indicates claude exited with an error or reported one.
+	ErrClaudeFailed = errors.New("workflow: claude failed")
+
+	// ErrTokenBudgetExceeded indicates a phase used more tokens than its
+	// workflow budget and was stopped.
+	ErrTokenBudgetExceeded = errors.New("workflow: token budget exceeded")
+
+	// ErrPhaseTimeout indicates a phase ran longer than its timeout.
+	ErrPhaseTimeout = errors.New("workflow: phase timed out")
+
 	// ErrNilWorktreeManager indicates a nil WorktreeManager was provided.
 	ErrNilWorktreeManager = errors.New("workflow: WorktreeManager must not be nil")
 
@@ -234,7 +234,7 @@ func (o *worktreeOrchestrator) ExecuteWorkflow(ctx context.Context, specID strin
 		result.RunStatus = PhaseStatusSkipped
 		result.SyncStatus = PhaseStatusSkipped
 		result.CompletedAt = time.Now()
-		return result, fmt.Errorf("plan phase for %s: %w", specID, ErrPlanPhaseFailed)
+		return result, fmt.Errorf("plan phase for %s: %w: %w", specID, ErrPlanPhaseFailed, err)
 	}
 	result.PlanStatus = PhaseStatusCompleted
 
@@ -244,7 +244,7 @@ func (o *worktreeOrchestrator) ExecuteWorkflow(ctx context.Context, specID strin
 		result.RunStatus = PhaseStatusFailed
 		result.SyncStatus = PhaseStatusSkipped
 		result.CompletedAt = time.Now()
-		return result, fmt.Errorf("run phase for %s: %w", specID, ErrRunPhaseFailed)
+		return result, fmt.Errorf("run phase for %s: %w: %w", specID, ErrRunPhaseFailed, err)
 	}
 	result.RunStatus = PhaseStatusCompleted
 
@@ -260,7 +260,7 @@ func (o *worktreeOrchestrator) ExecuteWorkflow(ctx context.Context, specID strin
 	if err := o.executor.ExecuteSync(ctx, specID, wtCtx.WorktreeDir); err != nil {
 		result.SyncStatus = PhaseStatusFailed
 		result.CompletedAt = time.Now()
-		return result, fmt.Errorf("sync phase for %s: %w", specID, ErrSyncPhaseFailed)
+		return result, fmt.Errorf("sync phase for %s: %w: %w", specID, ErrSyncPhaseFailed, err)
 	}
 	result.SyncStatus = PhaseStatusCompleted
 
@@ -49,17 +49,75 @@ type LanguageConfig struct {
 
 // QualityConfig represents the quality configuration section.
 type QualityConfig struct {
-	DevelopmentMode    DevelopmentMode    `yaml:"development_mode"`
-	EnforceQuality     bool               `yaml:"enforce_quality"`
-	TestCoverageTarget int                `yaml:"test_coverage_target"`
-	DDDSettings        DDDSettings        `yaml:"ddd_settings"`
-	TDDSettings        TDDSettings        `yaml:"tdd_settings"`
-	HybridSettings     HybridSettings     `yaml:"hybrid_settings"`
-	CoverageExemptions CoverageExemptions `yaml:"coverage_exemptions"`
-	TestQuality        TestQuality        `yaml:"test_quality"`
-	LSPQualityGates    LSPQualityGates    `yaml:"lsp_quality_gates"`
-	Principles         Principles         `yaml:"principles"`
-	LSPIntegration     LSPIntegration     `yaml:"lsp_integration"`
+	DevelopmentMode     DevelopmentMode     `yaml:"development_mode"`
+	EnforceQuality      bool                `yaml:"enforce_quality"`
+	TestCoverageTarget  int                 `yaml:"test_coverage_target"`
+	DDDSettings         DDDSettings         `yaml:"ddd_settings"`
+	TDDSettings         TDDSettings         `yaml:"tdd_settings"`
+	HybridSettings      HybridSettings      `yaml:"hybrid_settings"`
+	CoverageExemptions  CoverageExemptions  `yaml:"coverage_exemptions"`
+	TestQuality         TestQuality         `yaml:"test_quality"`
+	LSPQualityGates     LSPQualityGates     `yaml:"lsp_quality_gates"`
+	Principles          Principles          `yaml:"principles"`
+	LSPIntegration      LSPIntegration      `yaml:"lsp_integration"`
+	StopGate            StopGate            `yaml:"stop_gate"`
+	AutoFormat          AutoFormat          `yaml:"auto_format"`
+	FallbackDiagnostics FallbackDiagnostics `yaml:"fallback_diagnostics"`
+}
+
+// FallbackDiagnostics declares the CLI tools that report diagnostics for
+// edited files when no language server is available. Declared tools are
+// tried before the built-in tools of their language, and a tool named
+// like a built-in one replaces it.
+type FallbackDiagnostics struct {
+	Tools []FallbackDiagnosticsTool `yaml:"tools"`
+}
+
+// FallbackDiagnosticsTool declares one diagnostics CLI tool. "{file}" and
+// "{dir}" in Args expand to the edited file and its directory. Format is
+// "sarif" for SARIF 2.1 output or "regex" for one diagnostic per output
+// line matched by Pattern, which defaults to "file:line:col: severity:
+// message". Severity maps the tool's severity names to error, warning,
+// information or hint; the "default" key applies to unmatched names.
+type FallbackDiagnosticsTool struct {
+	Name       string            `yaml:"name"`
+	Language   string            `yaml:"language"`
+	Extensions []string          `yaml:"extensions"`
+	Command    string            `yaml:"command"`
+	Args       []string          `yaml:"args"`
+	Format     string            `yaml:"format"`
+	Pattern    string            `yaml:"pattern"`
+	Severity   map[string]string `yaml:"severity"`
+}
+
+// AutoFormat configures the PostToolUse hook that formats each file
+// written by Claude and applies linter auto-fixes. Languages holds
+// per-language overrides keyed by language name (e.g. "go", "python").
+type AutoFormat struct {
+	Enabled        bool                          `yaml:"enabled"`
+	LintFix        bool                          `yaml:"lint_fix"`
+	TimeoutSeconds int                           `yaml:"timeout_seconds"`
+	Languages      map[string]AutoFormatLanguage `yaml:"languages"`
+}
+
+// AutoFormatLanguage overrides the auto-format settings of one language.
+// A zero TimeoutSeconds keeps the global timeout.
+type AutoFormatLanguage struct {
+	Disabled       bool `yaml:"disabled"`
+	TimeoutSeconds int  `yaml:"timeout_seconds"`
+}
+
+// StopGate configures the quality gate the Stop hook runs on the files
+// changed in a session before Claude is allowed to stop. Empty commands
+// fall back to per-language defaults; "{files}", "{packages}" and "{tests}"
+// expand to the changed files, their Go packages and their test files.
+type StopGate struct {
+	Enabled        bool   `yaml:"enabled"`
+	MaxBlocks      int    `yaml:"max_blocks"`
+	BuildCommand   string `yaml:"build_command"`
+	TestCommand    string `yaml:"test_command"`
+	LintCommand    string `yaml:"lint_command"`
+	TimeoutSeconds int    `yaml:"timeout_seconds"`
 }
 
 // TestQuality configures test quality requirements.
@@ -148,6 +206,7 @@ type TDDSettings struct {
 	TestFirstRequired      bool `yaml:"test_first_required"`
 	MinCoveragePerCommit   int  `yaml:"min_coverage_per_commit"`
 	MutationTestingEnabled bool `yaml:"mutation_testing_enabled"`
+	MutationScoreThreshold int  `yaml:"mutation_score_threshold"`
 }
 
 // HybridSettings configures Hybrid mode (TDD for new code, DDD for legacy).